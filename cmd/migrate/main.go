@@ -2,32 +2,320 @@ package main
 
 import (
 	"context"
+	"database/sql"
+	"flag"
 	"fmt"
+	"io/fs"
 	"log"
+	"log/slog"
 	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"ratemylifedecision/internal/config"
 	"ratemylifedecision/internal/database"
+	"ratemylifedecision/internal/logging"
 	"ratemylifedecision/internal/migrate"
+	"ratemylifedecision/migrations"
 )
 
+const usage = "usage: go run ./cmd/migrate [-config file] [-database-url url] [-migrations-dir dir] [-log-level level] up [-force] [-dry-run] | status | down [-confirm] [n] | goto [-confirm] [-force] <version> | create <name>"
+
 func main() {
-	if len(os.Args) < 2 || os.Args[1] != "up" {
-		log.Fatalf("usage: go run ./cmd/migrate up")
+	config.RegisterFlags(flag.CommandLine, config.FlagConfig, config.FlagDatabaseURL, config.FlagMigrationsDir, config.FlagLogLevel)
+	flag.Usage = func() {
+		fmt.Fprintln(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if err := config.ApplyFlags(flag.CommandLine); err != nil {
+		log.Fatal(err)
+	}
+	args := flag.Args()
+	if len(args) < 1 {
+		log.Fatal(usage)
+	}
+
+	switch args[0] {
+	case "up":
+		runUp(args[1:])
+	case "status":
+		if len(args) > 1 {
+			log.Fatal(usage)
+		}
+		runStatus()
+	case "down":
+		runDown(args[1:])
+	case "goto":
+		runGoto(args[1:])
+	case "create":
+		if len(args) != 2 {
+			log.Fatal(usage)
+		}
+		runCreate(args[1])
+	default:
+		log.Fatal(usage)
+	}
+}
+
+// loadConfig reads the configuration, with the flags already applied, and
+// sends the migrator's log lines to stderr at the configured level.
+func loadConfig() config.Config {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("invalid configuration:\n%v", err)
+	}
+	slog.SetDefault(logging.New(os.Stderr, cfg.LogFormat, cfg.LogLevel))
+	// SetDefault routes the log package through slog too; keep the
+	// command's own messages plain.
+	log.SetOutput(os.Stderr)
+	log.SetFlags(log.LstdFlags)
+	return cfg
+}
+
+// runUp applies pending migrations. It refuses to run when an applied
+// migration was edited; -force downgrades that to a warning.
+func runUp(args []string) {
+	flags := flag.NewFlagSet("up", flag.ExitOnError)
+	force := flags.Bool("force", false, "apply even if applied migrations changed on disk")
+	dryRun := flags.Bool("dry-run", false, "print the migrations and SQL that would run, without running them")
+	_ = flags.Parse(args)
+	if flags.NArg() > 0 {
+		log.Fatal(usage)
 	}
 
-	cfg := config.Load()
+	cfg := loadConfig()
 	ctx := context.Background()
 
-	db, err := database.Connect(ctx, cfg.DatabaseURL)
+	pool, err := database.Connect(ctx, cfg.DatabaseURL)
 	if err != nil {
 		log.Fatalf("failed to connect db: %v", err)
 	}
-	defer db.Close()
+	defer pool.Close()
+	db := database.SQL(pool)
+
+	if *dryRun {
+		printUpPlan(ctx, db, migrations.FS(cfg.MigrationsDir), *force)
+		return
+	}
 
-	if err := migrate.Up(ctx, db, "migrations"); err != nil {
+	if err := migrate.Up(ctx, db, migrations.FS(cfg.MigrationsDir), migrate.Options{
+		Force:       *force,
+		LockTimeout: cfg.MigrateLockTimeout,
+	}); err != nil {
 		log.Fatalf("migration failed: %v", err)
 	}
 
 	fmt.Println("migrations applied successfully")
 }
+
+// runStatus prints every known migration version. Pending migrations and
+// applied versions with no file on disk are called out, since either means
+// the binary and the database disagree about the schema.
+func runStatus() {
+	cfg := loadConfig()
+	ctx := context.Background()
+
+	pool, err := database.Connect(ctx, cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("failed to connect db: %v", err)
+	}
+	defer pool.Close()
+	db := database.SQL(pool)
+
+	statuses, err := migrate.Status(ctx, db, migrations.FS(cfg.MigrationsDir))
+	if err != nil {
+		log.Fatalf("migration status failed: %v", err)
+	}
+
+	var pending, missing, drifted int
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED AT\tSTATE")
+	for _, st := range statuses {
+		appliedAt := "-"
+		state := "applied"
+		switch {
+		case st.AppliedAt == nil:
+			state = "pending"
+			pending++
+		case !st.OnDisk:
+			state = "applied, no file on disk"
+			missing++
+		case st.Drifted:
+			state = "applied, file changed since"
+			drifted++
+		}
+		if st.AppliedAt != nil {
+			appliedAt = st.AppliedAt.UTC().Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%06d\t%s\t%s\t%s\n", st.Version, st.Name, appliedAt, state)
+	}
+	_ = tw.Flush()
+
+	fmt.Printf("\n%d migration(s), %d pending, %d applied without a file, %d changed since applied\n", len(statuses), pending, missing, drifted)
+}
+
+// runDown rolls back the last n migrations (default 1). Without -confirm it
+// only prints what would be rolled back, since down migrations drop data.
+func runDown(args []string) {
+	flags := flag.NewFlagSet("down", flag.ExitOnError)
+	confirm := flags.Bool("confirm", false, "actually apply the down migrations")
+	_ = flags.Parse(args)
+	if flags.NArg() > 1 {
+		log.Fatal(usage)
+	}
+
+	n := 1
+	if flags.NArg() == 1 {
+		parsed, err := strconv.Atoi(flags.Arg(0))
+		if err != nil || parsed < 1 {
+			log.Fatalf("n must be a positive integer, got %q", flags.Arg(0))
+		}
+		n = parsed
+	}
+
+	cfg := loadConfig()
+	ctx := context.Background()
+
+	pool, err := database.Connect(ctx, cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("failed to connect db: %v", err)
+	}
+	defer pool.Close()
+	db := database.SQL(pool)
+
+	if !*confirm {
+		planned, err := migrate.PlanDown(ctx, db, migrations.FS(cfg.MigrationsDir), n)
+		if err != nil {
+			log.Fatalf("migration failed: %v", err)
+		}
+		if len(planned) == 0 {
+			fmt.Println("no applied migrations to roll back")
+			return
+		}
+		fmt.Println("would roll back:")
+		printPlan(planned)
+		fmt.Println("re-run with -confirm to apply; this may drop data")
+		os.Exit(1)
+	}
+
+	rolledBack, err := migrate.Down(ctx, db, migrations.FS(cfg.MigrationsDir), n, migrate.Options{LockTimeout: cfg.MigrateLockTimeout})
+	for _, name := range rolledBack {
+		fmt.Println("rolled back " + name)
+	}
+	if err != nil {
+		log.Fatalf("migration failed: %v", err)
+	}
+	if len(rolledBack) == 0 {
+		fmt.Println("no applied migrations to roll back")
+	}
+}
+
+// runGoto migrates to exactly the given version (0 rolls everything back).
+// Like down, it only prints the plan unless -confirm is given whenever the
+// plan includes rollbacks.
+func runGoto(args []string) {
+	flags := flag.NewFlagSet("goto", flag.ExitOnError)
+	confirm := flags.Bool("confirm", false, "apply the plan even if it rolls migrations back")
+	force := flags.Bool("force", false, "apply even if applied migrations changed on disk")
+	_ = flags.Parse(args)
+	if flags.NArg() != 1 {
+		log.Fatal(usage)
+	}
+	target, err := strconv.ParseInt(flags.Arg(0), 10, 64)
+	if err != nil || target < 0 {
+		log.Fatalf("version must be a non-negative integer, got %q", flags.Arg(0))
+	}
+
+	cfg := loadConfig()
+	ctx := context.Background()
+
+	pool, err := database.Connect(ctx, cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("failed to connect db: %v", err)
+	}
+	defer pool.Close()
+	db := database.SQL(pool)
+
+	fsys := migrations.FS(cfg.MigrationsDir)
+	plan, err := migrate.PlanGoto(ctx, db, fsys, target)
+	if err != nil {
+		log.Fatalf("migration failed: %v", err)
+	}
+	if len(plan.Down) == 0 && len(plan.Up) == 0 {
+		fmt.Printf("already at version %d\n", target)
+		return
+	}
+	if len(plan.Down) > 0 && !*confirm {
+		fmt.Println("would roll back:")
+		printPlan(plan.Down)
+		if len(plan.Up) > 0 {
+			fmt.Println("then apply:")
+			printPlan(plan.Up)
+		}
+		fmt.Println("re-run with -confirm to apply; this may drop data")
+		os.Exit(1)
+	}
+
+	rolledBack, applied, err := migrate.Goto(ctx, db, fsys, target, migrate.Options{
+		Force:       *force,
+		LockTimeout: cfg.MigrateLockTimeout,
+	})
+	for _, name := range rolledBack {
+		fmt.Println("rolled back " + name)
+	}
+	for _, name := range applied {
+		fmt.Println("applied " + name)
+	}
+	if err != nil {
+		log.Fatalf("migration failed: %v", err)
+	}
+	fmt.Printf("now at version %d\n", target)
+}
+
+func runCreate(name string) {
+	upPath, downPath, err := migrate.Create("migrations", name, time.Now())
+	if err != nil {
+		log.Fatalf("create migration failed: %v", err)
+	}
+	fmt.Println("created " + upPath)
+	fmt.Println("created " + downPath)
+}
+
+// printUpPlan is migrate up -dry-run. It reports drift the same way a real
+// run would, so the preview fails exactly when the run would.
+func printUpPlan(ctx context.Context, db *sql.DB, fsys fs.FS, force bool) {
+	drifted, err := migrate.Drifted(ctx, db, fsys)
+	if err != nil {
+		log.Fatalf("migration failed: %v", err)
+	}
+	if len(drifted) > 0 {
+		if !force {
+			log.Fatalf("migration failed: %v", &migrate.DriftError{Names: drifted})
+		}
+		for _, name := range drifted {
+			fmt.Println("warning: applied migration changed on disk: " + name)
+		}
+	}
+
+	planned, err := migrate.PlanUp(ctx, db, fsys)
+	if err != nil {
+		log.Fatalf("migration failed: %v", err)
+	}
+	if len(planned) == 0 {
+		fmt.Println("no pending migrations")
+		return
+	}
+	fmt.Println("would apply:")
+	printPlan(planned)
+	fmt.Println("dry run: nothing was executed")
+}
+
+func printPlan(planned []migrate.PlannedMigration) {
+	for _, p := range planned {
+		fmt.Printf("\n-- ===== %s =====\n%s\n", p.Name, strings.TrimRight(p.SQL, "\n"))
+	}
+	fmt.Println()
+}
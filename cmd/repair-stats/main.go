@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/config"
+	"ratemylifedecision/internal/database"
+	"ratemylifedecision/internal/stats"
+)
+
+func main() {
+	var decisionID *uuid.UUID
+	if len(os.Args) > 2 {
+		log.Fatalf("usage: go run ./cmd/repair-stats [decision-id]")
+	}
+	if len(os.Args) == 2 {
+		id, err := uuid.Parse(os.Args[1])
+		if err != nil {
+			log.Fatalf("invalid decision id: %v", err)
+		}
+		decisionID = &id
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("invalid configuration:\n%v", err)
+	}
+	ctx := context.Background()
+
+	pool, err := database.Connect(ctx, cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("failed to connect db: %v", err)
+	}
+	defer pool.Close()
+	db := database.SQL(pool)
+
+	repaired, err := stats.Repair(ctx, db, decisionID)
+	if err != nil {
+		log.Fatalf("stats repair failed: %v", err)
+	}
+
+	fmt.Printf("repaired stats for %d decision(s)\n", repaired)
+}
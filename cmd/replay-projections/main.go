@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"ratemylifedecision/internal/config"
+	"ratemylifedecision/internal/database"
+	"ratemylifedecision/internal/projections"
+)
+
+func main() {
+	if len(os.Args) > 1 {
+		log.Fatalf("usage: go run ./cmd/replay-projections")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("invalid configuration:\n%v", err)
+	}
+	ctx := context.Background()
+
+	pool, err := database.Connect(ctx, cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("failed to connect db: %v", err)
+	}
+	defer pool.Close()
+	db := database.SQL(pool)
+
+	replayed, err := projections.NewProjector(db).Rebuild(ctx)
+	if err != nil {
+		log.Fatalf("projection replay failed: %v", err)
+	}
+
+	fmt.Printf("rebuilt read models from %d event(s)\n", replayed)
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/config"
+	"ratemylifedecision/internal/database"
+	"ratemylifedecision/internal/projections"
+	"ratemylifedecision/internal/stats"
+)
+
+type emojiChoice struct {
+	emoji  string
+	rating int
+	weight int
+}
+
+// Mirrors the emoji -> rating mapping the API enforces. Weights skew
+// towards the middle the way real responses do.
+var emojiChoices = []emojiChoice{
+	{"🫠", 1, 10},
+	{"😭", 2, 15},
+	{"😬", 3, 25},
+	{"😄", 4, 30},
+	{"🫡", 5, 20},
+}
+
+var categories = []string{"career", "education", "health", "housing", "lifestyle", "money", "relationships", "travel", "other"}
+
+var titles = []string{
+	"Should I quit my job to start a bakery?",
+	"Move across the country for a fresh start?",
+	"Go back to school for a master's degree?",
+	"Adopt a second dog?",
+	"Buy a house now or keep renting?",
+	"Take the remote job with the lower salary?",
+	"Learn to surf at 35?",
+	"Tell my roommate I'm moving out?",
+	"Sell my car and bike everywhere?",
+	"Spend my savings on a six-month trip?",
+	"Switch from engineering to product management?",
+	"Run a marathon this year?",
+	"Propose on our anniversary trip?",
+	"Move in with my parents to pay off debt?",
+	"Start therapy?",
+}
+
+var comments = []string{
+	"Do it. You'll regret **not** trying more than failing.",
+	"Honestly this sounds risky, I'd wait a year.",
+	"Have you talked to anyone who has done this?",
+	"Make a spreadsheet first. Then decide.",
+	"Life is short!",
+	"I did something similar and it was the best decision I ever made.",
+	"Worst case you learn something.",
+	"Not worth the stress right now imo",
+	"- pros: freedom\n- cons: money\n\nI'd still go for it",
+	"Sleep on it for a week and see how you feel.",
+	"The fact you're asking means you already know the answer 😄",
+	"Only if you have 6 months of savings.",
+}
+
+func main() {
+	decisions := flag.Int("decisions", 25, "number of decisions to create")
+	responses := flag.Int("responses", 12, "average responses per decision")
+	votes := flag.Int("votes", 8, "average post votes per decision")
+	seed := flag.Uint64("seed", uint64(time.Now().UnixNano()), "random seed, for reproducible data sets")
+	flag.Parse()
+	if flag.NArg() > 0 || *decisions < 1 || *responses < 0 || *votes < 0 {
+		log.Fatalf("usage: go run ./cmd/seed [-decisions n] [-responses n] [-votes n] [-seed n]")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("invalid configuration:\n%v", err)
+	}
+	ctx := context.Background()
+
+	pool, err := database.Connect(ctx, cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("failed to connect db: %v", err)
+	}
+	defer pool.Close()
+	db := database.SQL(pool)
+
+	s := &seeder{db: db, rng: rand.New(rand.NewPCG(*seed, *seed))}
+	var totalResponses, totalVotes int
+	for i := 0; i < *decisions; i++ {
+		r, v, err := s.seedDecision(ctx, s.around(*responses), s.around(*votes))
+		if err != nil {
+			log.Fatalf("seed failed: %v", err)
+		}
+		totalResponses += r
+		totalVotes += v
+	}
+
+	fmt.Printf("seeded %d decision(s), %d response(s), %d vote(s) with seed %d\n", *decisions, totalResponses, totalVotes, *seed)
+}
+
+type seeder struct {
+	db  *sql.DB
+	rng *rand.Rand
+}
+
+// seedDecision writes one decision with its responses and votes the way the
+// API would, in one transaction: stats rows and outbox events included, so
+// the decision page and insights look exactly as if real users had posted.
+func (s *seeder) seedDecision(ctx context.Context, responseCount, voteCount int) (int, int, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	decisionID := uuid.New()
+	title := titles[s.rng.IntN(len(titles))]
+	category := categories[s.rng.IntN(len(categories))]
+	createdAt := time.Now().Add(-time.Duration(s.rng.Int64N(int64(30 * 24 * time.Hour))))
+	var closesAt *time.Time
+	if s.rng.IntN(10) < 3 {
+		t := createdAt.Add(time.Duration(1+s.rng.IntN(14)) * 24 * time.Hour)
+		closesAt = &t
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO decisions (id, slug, title, closes_at, created_at, category)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, decisionID, "demo-"+strings.ReplaceAll(decisionID.String(), "-", "")[:10], title, closesAt, createdAt, category); err != nil {
+		return 0, 0, fmt.Errorf("insert decision: %w", err)
+	}
+	if err := projections.Append(ctx, tx, decisionID, projections.KindDecisionCreated, projections.DecisionCreated{Category: &category}); err != nil {
+		return 0, 0, err
+	}
+
+	for i := 0; i < responseCount; i++ {
+		choice := s.pickEmoji()
+		suggestion := s.suggestionFor(choice.rating)
+		var comment *string
+		if s.rng.IntN(10) < 4 {
+			c := comments[s.rng.IntN(len(comments))]
+			comment = &c
+		}
+		respondedAt := createdAt.Add(time.Duration(s.rng.Int64N(int64(72 * time.Hour))))
+
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO responses (id, decision_id, viewer_id, rating, suggestion, emoji, comment, created_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		`, uuid.New(), decisionID, uuid.New(), choice.rating, suggestion, choice.emoji, comment, respondedAt); err != nil {
+			return 0, 0, fmt.Errorf("insert response: %w", err)
+		}
+		if err := stats.ApplyResponse(ctx, tx, decisionID, choice.rating, suggestion, choice.emoji); err != nil {
+			return 0, 0, err
+		}
+		if err := projections.Append(ctx, tx, decisionID, projections.KindResponseCreated, projections.ResponseCreated{
+			Rating:     choice.rating,
+			Suggestion: suggestion,
+		}); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	voteSum := 0
+	for i := 0; i < voteCount; i++ {
+		value := 1
+		if s.rng.IntN(10) < 3 {
+			value = -1
+		}
+		voteSum += value
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO decision_votes (id, decision_id, voter_viewer_id, value)
+			VALUES ($1, $2, $3, $4)
+		`, uuid.New(), decisionID, uuid.New(), value); err != nil {
+			return 0, 0, fmt.Errorf("insert vote: %w", err)
+		}
+	}
+	if voteCount > 0 {
+		if err := stats.RefreshVotes(ctx, tx, decisionID); err != nil {
+			return 0, 0, err
+		}
+		if err := projections.Append(ctx, tx, decisionID, projections.KindVoteChanged, projections.VoteChanged{
+			VoteSum:   voteSum,
+			VoteCount: voteCount,
+		}); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	return responseCount, voteCount, tx.Commit()
+}
+
+func (s *seeder) pickEmoji() emojiChoice {
+	total := 0
+	for _, c := range emojiChoices {
+		total += c.weight
+	}
+	n := s.rng.IntN(total)
+	for _, c := range emojiChoices {
+		if n < c.weight {
+			return c
+		}
+		n -= c.weight
+	}
+	return emojiChoices[len(emojiChoices)-1]
+}
+
+// suggestionFor mostly follows the rating (1-2 -> 1, 3 -> 2, 4-5 -> 3) but
+// disagrees a fifth of the time, as real responders do.
+func (s *seeder) suggestionFor(rating int) int {
+	if s.rng.IntN(5) == 0 {
+		return 1 + s.rng.IntN(3)
+	}
+	switch {
+	case rating <= 2:
+		return 1
+	case rating == 3:
+		return 2
+	default:
+		return 3
+	}
+}
+
+// around returns a count spread between half and one and a half times avg.
+func (s *seeder) around(avg int) int {
+	if avg == 0 {
+		return 0
+	}
+	return avg/2 + s.rng.IntN(avg+1)
+}
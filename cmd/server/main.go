@@ -2,33 +2,131 @@ package main
 
 import (
 	"context"
-	"log"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
+	"google.golang.org/grpc"
+
 	"ratemylifedecision/internal/config"
 	"ratemylifedecision/internal/database"
 	"ratemylifedecision/internal/httpapi"
+	"ratemylifedecision/internal/logging"
+	"ratemylifedecision/internal/migrate"
+	"ratemylifedecision/migrations"
 )
 
 func main() {
-	cfg := config.Load()
-	ctx := context.Background()
+	if err := run(); err != nil {
+		slog.Error("server stopped with error", "error", err)
+		os.Exit(1)
+	}
+}
 
-	db, err := database.Connect(ctx, cfg.DatabaseURL)
+func run() error {
+	config.RegisterFlags(flag.CommandLine, config.FlagConfig, config.FlagPort, config.FlagDatabaseURL, config.FlagMigrationsDir, config.FlagLogLevel)
+	flag.Parse()
+	if flag.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments %q", flag.Args())
+	}
+	if err := config.ApplyFlags(flag.CommandLine); err != nil {
+		return err
+	}
+	cfg, err := config.Load()
 	if err != nil {
-		log.Fatalf("database connection failed: %v", err)
+		return fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	slog.SetDefault(logging.New(os.Stdout, cfg.LogFormat, cfg.LogLevel))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	pool, err := database.Connect(ctx, cfg.DatabaseURL)
+	if err != nil {
+		return err
+	}
+	defer pool.Close()
+
+	if cfg.AutoMigrate {
+		if err := migrate.Up(ctx, database.SQL(pool), migrations.FS(cfg.MigrationsDir), migrate.Options{LockTimeout: cfg.MigrateLockTimeout}); err != nil {
+			return fmt.Errorf("auto-migrate: %w", err)
+		}
+		slog.Info("migrations applied")
 	}
-	defer db.Close()
 
+	api, err := httpapi.New(pool, cfg)
+	if err != nil {
+		return err
+	}
 	srv := &http.Server{
 		Addr:              ":" + cfg.Port,
-		Handler:           httpapi.New(db),
+		Handler:           api,
 		ReadHeaderTimeout: 5 * time.Second,
+		ErrorLog:          slog.NewLogLogger(slog.Default().Handler(), slog.LevelWarn),
+	}
+	srv.RegisterOnShutdown(api.BeginShutdown)
+
+	serveErr := make(chan error, 2)
+	go func() {
+		slog.Info("server listening", "addr", srv.Addr)
+		serveErr <- srv.ListenAndServe()
+	}()
+
+	var grpcSrv *grpc.Server
+	if cfg.GRPCPort != "" {
+		lis, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+		if err != nil {
+			return fmt.Errorf("grpc listen: %w", err)
+		}
+		grpcSrv = api.GRPCServer()
+		go func() {
+			slog.Info("grpc server listening", "addr", lis.Addr().String())
+			serveErr <- grpcSrv.Serve(lis)
+		}()
 	}
 
-	log.Printf("server listening on :%s", cfg.Port)
-	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		log.Fatalf("server stopped with error: %v", err)
+	select {
+	case err := <-serveErr:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	case <-ctx.Done():
+	}
+	stop()
+
+	slog.Info("shutting down", "drain_timeout", cfg.ShutdownTimeout.String())
+	drainCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(drainCtx); err != nil {
+		slog.Warn("http drain incomplete", "error", err)
+	}
+	if grpcSrv != nil {
+		// GracefulStop waits for in-flight calls with no deadline of its
+		// own, so cut them off once the drain timeout is spent.
+		stopped := make(chan struct{})
+		go func() {
+			grpcSrv.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-drainCtx.Done():
+			slog.Warn("grpc drain incomplete", "error", drainCtx.Err())
+			grpcSrv.Stop()
+		}
+	}
+	if err := api.Shutdown(drainCtx); err != nil {
+		slog.Warn("background work did not finish", "error", err)
 	}
+	slog.Info("server stopped")
+	return nil
 }
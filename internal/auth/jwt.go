@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const sessionIssuer = "ratemylifedecision"
+
+var ErrInvalidSession = errors.New("session token is invalid or expired")
+
+// Session is who a session token speaks for: a viewer, an account, or
+// both when a signed-in account is acting as one of its viewers.
+type Session struct {
+	ViewerID  *uuid.UUID
+	AccountID *uuid.UUID
+	// AuthTime is when the viewer or account token behind the session was
+	// last presented. Refreshing a session keeps it, so a chain of
+	// refreshes can be cut off however live each link is.
+	AuthTime  time.Time
+	ExpiresAt time.Time
+}
+
+type sessionClaims struct {
+	Issuer    string     `json:"iss"`
+	Subject   string     `json:"sub"`
+	IssuedAt  int64      `json:"iat"`
+	ExpiresAt int64      `json:"exp"`
+	AuthTime  int64      `json:"auth_time"`
+	ViewerID  *uuid.UUID `json:"vid,omitempty"`
+	AccountID *uuid.UUID `json:"aid,omitempty"`
+}
+
+// jwtHeader is the only header session tokens are issued or accepted with.
+var jwtHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// SignSession issues an HS256 JWT for session that expires after ttl. A
+// zero AuthTime means the tokens behind it were presented just now.
+func (s *Signer) SignSession(session Session, ttl time.Duration) (string, time.Time, error) {
+	now := time.Now()
+	authTime := session.AuthTime
+	if authTime.IsZero() {
+		authTime = now
+	}
+	claims := sessionClaims{
+		Issuer:    sessionIssuer,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(ttl).Unix(),
+		AuthTime:  authTime.Unix(),
+		ViewerID:  session.ViewerID,
+		AccountID: session.AccountID,
+	}
+	switch {
+	case session.AccountID != nil:
+		claims.Subject = "account:" + session.AccountID.String()
+	case session.ViewerID != nil:
+		claims.Subject = "viewer:" + session.ViewerID.String()
+	default:
+		return "", time.Time{}, errors.New("session has no viewer or account")
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	signed := jwtHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return signed + "." + s.jwtMAC(signed), time.Unix(claims.ExpiresAt, 0), nil
+}
+
+// VerifySession checks a token from SignSession: the header must be
+// exactly the one issued, so no other algorithm is ever considered.
+func (s *Signer) VerifySession(token string) (Session, error) {
+	signed, sig, ok := cutLast(strings.TrimSpace(token), ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(s.jwtMAC(signed))) {
+		return Session{}, ErrInvalidSession
+	}
+	header, payload, ok := strings.Cut(signed, ".")
+	if !ok || header != jwtHeader {
+		return Session{}, ErrInvalidSession
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return Session{}, ErrInvalidSession
+	}
+	var claims sessionClaims
+	if err := json.Unmarshal(raw, &claims); err != nil {
+		return Session{}, ErrInvalidSession
+	}
+	if claims.Issuer != sessionIssuer || time.Now().Unix() >= claims.ExpiresAt {
+		return Session{}, ErrInvalidSession
+	}
+	if claims.ViewerID == nil && claims.AccountID == nil {
+		return Session{}, ErrInvalidSession
+	}
+	if claims.AuthTime == 0 || claims.AuthTime > claims.IssuedAt {
+		return Session{}, ErrInvalidSession
+	}
+	return Session{
+		ViewerID:  claims.ViewerID,
+		AccountID: claims.AccountID,
+		AuthTime:  time.Unix(claims.AuthTime, 0),
+		ExpiresAt: time.Unix(claims.ExpiresAt, 0),
+	}, nil
+}
+
+func (s *Signer) jwtMAC(signed string) string {
+	h := hmac.New(sha256.New, s.key)
+	h.Write([]byte(signed))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
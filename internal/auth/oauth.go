@@ -0,0 +1,211 @@
+// Package auth signs people in with Google or GitHub through the OAuth 2.0
+// authorization code flow, so an account can hold viewer identities and
+// decisions across devices without the service storing passwords. Only the
+// provider's stable user ID is kept; provider access tokens are used once
+// to read it and then dropped.
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"ratemylifedecision/internal/logging"
+)
+
+const exchangeTimeout = 10 * time.Second
+
+// ErrRejected means the provider answered and refused the sign-in: a bad,
+// expired or already used code.
+var ErrRejected = errors.New("sign-in rejected")
+
+// Identity is who the provider says signed in. Subject is the provider's
+// stable user ID; Email is informational and may be empty.
+type Identity struct {
+	Provider string
+	Subject  string
+	Email    string
+}
+
+type Provider struct {
+	Name         string
+	AuthURL      string
+	TokenURL     string
+	UserURL      string
+	Scopes       []string
+	ClientID     string
+	ClientSecret string
+	Client       *http.Client
+	// parseUser reads the Identity out of the UserURL response.
+	parseUser func(body []byte) (Identity, error)
+}
+
+// ProvidersFromEnv returns the providers with credentials set, keyed by
+// name: google with OAUTH_GOOGLE_CLIENT_ID and OAUTH_GOOGLE_CLIENT_SECRET,
+// github with OAUTH_GITHUB_CLIENT_ID and OAUTH_GITHUB_CLIENT_SECRET. None
+// configured disables sign-in.
+func ProvidersFromEnv() (map[string]*Provider, error) {
+	providers := make(map[string]*Provider)
+	for _, p := range []*Provider{google(), github()} {
+		prefix := "OAUTH_" + strings.ToUpper(p.Name) + "_"
+		p.ClientID = strings.TrimSpace(os.Getenv(prefix + "CLIENT_ID"))
+		p.ClientSecret = strings.TrimSpace(os.Getenv(prefix + "CLIENT_SECRET"))
+		switch {
+		case p.ClientID == "" && p.ClientSecret == "":
+			continue
+		case p.ClientID == "" || p.ClientSecret == "":
+			return nil, fmt.Errorf("%sCLIENT_ID and %sCLIENT_SECRET must be set together", prefix, prefix)
+		}
+		p.Client = &http.Client{Timeout: exchangeTimeout, Transport: logging.Transport{}}
+		providers[p.Name] = p
+	}
+	return providers, nil
+}
+
+func google() *Provider {
+	return &Provider{
+		Name:     "google",
+		AuthURL:  "https://accounts.google.com/o/oauth2/v2/auth",
+		TokenURL: "https://oauth2.googleapis.com/token",
+		UserURL:  "https://openidconnect.googleapis.com/v1/userinfo",
+		Scopes:   []string{"openid", "email"},
+		parseUser: func(body []byte) (Identity, error) {
+			var u struct {
+				Sub   string `json:"sub"`
+				Email string `json:"email"`
+			}
+			if err := json.Unmarshal(body, &u); err != nil {
+				return Identity{}, err
+			}
+			return Identity{Subject: u.Sub, Email: u.Email}, nil
+		},
+	}
+}
+
+func github() *Provider {
+	return &Provider{
+		Name:     "github",
+		AuthURL:  "https://github.com/login/oauth/authorize",
+		TokenURL: "https://github.com/login/oauth/access_token",
+		UserURL:  "https://api.github.com/user",
+		Scopes:   []string{"read:user"},
+		parseUser: func(body []byte) (Identity, error) {
+			var u struct {
+				ID    int64  `json:"id"`
+				Email string `json:"email"`
+			}
+			if err := json.Unmarshal(body, &u); err != nil {
+				return Identity{}, err
+			}
+			if u.ID == 0 {
+				return Identity{}, nil
+			}
+			return Identity{Subject: strconv.FormatInt(u.ID, 10), Email: u.Email}, nil
+		},
+	}
+}
+
+// AuthCodeURL is where to send the browser to sign in. The provider sends
+// it back to redirectURI with a code and state unchanged.
+func (p *Provider) AuthCodeURL(state, redirectURI string) string {
+	q := url.Values{
+		"client_id":     {p.ClientID},
+		"redirect_uri":  {redirectURI},
+		"response_type": {"code"},
+		"scope":         {strings.Join(p.Scopes, " ")},
+		"state":         {state},
+	}
+	return p.AuthURL + "?" + q.Encode()
+}
+
+// Exchange trades the code from the callback for the signed-in Identity.
+// It returns ErrRejected when the provider turns the code down and any
+// other error when the provider could not be asked.
+func (p *Provider) Exchange(ctx context.Context, code, redirectURI string) (Identity, error) {
+	if strings.TrimSpace(code) == "" {
+		return Identity{}, fmt.Errorf("%w: missing code", ErrRejected)
+	}
+	ctx, cancel := context.WithTimeout(ctx, exchangeTimeout)
+	defer cancel()
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"client_id":     {p.ClientID},
+		"client_secret": {p.ClientSecret},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Identity{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	body, status, err := p.do(req)
+	if err != nil {
+		return Identity{}, err
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+		Error       string `json:"error"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return Identity{}, fmt.Errorf("decode %s token response: %w", p.Name, err)
+	}
+	// GitHub reports a bad code with 200 and an error field; Google with
+	// 400.
+	if token.Error != "" || token.AccessToken == "" {
+		if status >= http.StatusInternalServerError {
+			return Identity{}, fmt.Errorf("%s token endpoint returned status %d", p.Name, status)
+		}
+		return Identity{}, fmt.Errorf("%w: %s", ErrRejected, token.Error)
+	}
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, p.UserURL, nil)
+	if err != nil {
+		return Identity{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	req.Header.Set("Accept", "application/json")
+	body, status, err = p.do(req)
+	if err != nil {
+		return Identity{}, err
+	}
+	if status != http.StatusOK {
+		return Identity{}, fmt.Errorf("%s user endpoint returned status %d", p.Name, status)
+	}
+	identity, err := p.parseUser(body)
+	if err != nil {
+		return Identity{}, fmt.Errorf("decode %s user: %w", p.Name, err)
+	}
+	if identity.Subject == "" {
+		return Identity{}, fmt.Errorf("%s user has no id", p.Name)
+	}
+	identity.Provider = p.Name
+	return identity, nil
+}
+
+func (p *Provider) do(req *http.Request) ([]byte, int, error) {
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return nil, 0, err
+	}
+	return body, resp.StatusCode, nil
+}
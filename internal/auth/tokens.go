@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidAccountToken = errors.New("account token is invalid or expired")
+	ErrInvalidState        = errors.New("sign-in state is invalid or expired")
+	ErrNoSecret            = errors.New("AUTH_TOKEN_SECRET is not set")
+)
+
+// Signer issues and checks the stateless tokens sign-in needs: account
+// tokens of the form "<account uuid>.<issued unix>.<base64url HMAC>", and
+// the OAuth state that ties a callback to the login that started it.
+type Signer struct {
+	key []byte
+}
+
+// SignerFromEnv reads AUTH_TOKEN_SECRET. Without it, and only if
+// allowRandomKey is set, a random per-process key is used, which only works
+// for a single instance and signs every account out on restart; that is
+// fine for development and nowhere else.
+func SignerFromEnv(allowRandomKey bool) (*Signer, error) {
+	secret := strings.TrimSpace(os.Getenv("AUTH_TOKEN_SECRET"))
+	if secret != "" {
+		return &Signer{key: []byte(secret)}, nil
+	}
+	if !allowRandomKey {
+		return nil, ErrNoSecret
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	slog.Warn("AUTH_TOKEN_SECRET is not set; account tokens will not survive a restart or work across instances")
+	return &Signer{key: key}, nil
+}
+
+// SignAccount issues an account token stamped with the time it was issued,
+// which VerifyAccount holds against its maxAge.
+func (s *Signer) SignAccount(accountID uuid.UUID) string {
+	payload := accountID.String() + "." + strconv.FormatInt(time.Now().Unix(), 10)
+	return payload + "." + s.mac("account", payload)
+}
+
+// VerifyAccount checks token and returns its account and when it was
+// issued. A token older than maxAge is refused, so a leaked one stops
+// working and shortening maxAge signs out everyone past it.
+func (s *Signer) VerifyAccount(token string, maxAge time.Duration) (uuid.UUID, time.Time, error) {
+	payload, sig, ok := cutLast(strings.TrimSpace(token), ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(s.mac("account", payload))) {
+		return uuid.Nil, time.Time{}, ErrInvalidAccountToken
+	}
+	idText, issuedText, ok := strings.Cut(payload, ".")
+	if !ok {
+		return uuid.Nil, time.Time{}, ErrInvalidAccountToken
+	}
+	accountID, err := uuid.Parse(idText)
+	if err != nil {
+		return uuid.Nil, time.Time{}, ErrInvalidAccountToken
+	}
+	issued, err := strconv.ParseInt(issuedText, 10, 64)
+	if err != nil {
+		return uuid.Nil, time.Time{}, ErrInvalidAccountToken
+	}
+	issuedAt := time.Unix(issued, 0)
+	if time.Since(issuedAt) > maxAge {
+		return uuid.Nil, time.Time{}, ErrInvalidAccountToken
+	}
+	return accountID, issuedAt, nil
+}
+
+// SignState binds a login to provider and to nonce, which the caller also
+// keeps in a cookie so the callback can only finish in the browser that
+// started it. It expires after ttl.
+func (s *Signer) SignState(provider, nonce string, ttl time.Duration) string {
+	payload := provider + "." + nonce + "." + strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+	return payload + "." + s.mac("state", payload)
+}
+
+// VerifyState checks state against provider and returns its nonce.
+func (s *Signer) VerifyState(state, provider string) (string, error) {
+	payload, sig, ok := cutLast(state, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(s.mac("state", payload))) {
+		return "", ErrInvalidState
+	}
+	parts := strings.Split(payload, ".")
+	if len(parts) != 3 || parts[0] != provider {
+		return "", ErrInvalidState
+	}
+	expires, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return "", ErrInvalidState
+	}
+	return parts[1], nil
+}
+
+func (s *Signer) mac(kind, payload string) string {
+	h := hmac.New(sha256.New, s.key)
+	h.Write([]byte(kind + ":"))
+	h.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
@@ -0,0 +1,112 @@
+// Package captcha verifies Cloudflare Turnstile and hCaptcha challenge
+// tokens. Both providers share the same siteverify contract.
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"ratemylifedecision/internal/logging"
+)
+
+const verifyTimeout = 5 * time.Second
+
+var providerURLs = map[string]string{
+	"turnstile": "https://challenges.cloudflare.com/turnstile/v0/siteverify",
+	"hcaptcha":  "https://api.hcaptcha.com/siteverify",
+}
+
+// ErrRejected means the provider answered and the token is not valid:
+// missing, expired, already used or solved by a bot.
+var ErrRejected = errors.New("captcha rejected")
+
+type Verifier struct {
+	Provider  string
+	VerifyURL string
+	Secret    string
+	Client    *http.Client
+}
+
+// FromEnv returns nil when CAPTCHA_PROVIDER is unset, which disables
+// verification.
+func FromEnv() (*Verifier, error) {
+	provider := strings.ToLower(strings.TrimSpace(os.Getenv("CAPTCHA_PROVIDER")))
+	if provider == "" {
+		return nil, nil
+	}
+	verifyURL, ok := providerURLs[provider]
+	if !ok {
+		return nil, fmt.Errorf("CAPTCHA_PROVIDER must be turnstile or hcaptcha, got %q", provider)
+	}
+	secret := strings.TrimSpace(os.Getenv("CAPTCHA_SECRET"))
+	if secret == "" {
+		return nil, errors.New("CAPTCHA_SECRET is required when CAPTCHA_PROVIDER is set")
+	}
+	return &Verifier{
+		Provider:  provider,
+		VerifyURL: verifyURL,
+		Secret:    secret,
+		Client:    &http.Client{Timeout: verifyTimeout, Transport: logging.Transport{}},
+	}, nil
+}
+
+// Verify checks token with the provider. It returns ErrRejected for a bad
+// token and any other error when the provider could not be asked.
+func (v *Verifier) Verify(ctx context.Context, token, remoteIP string) error {
+	if strings.TrimSpace(token) == "" {
+		return fmt.Errorf("%w: missing token", ErrRejected)
+	}
+
+	form := url.Values{"secret": {v.Secret}, "response": {token}}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, verifyTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.VerifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	client := v.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s siteverify returned status %d", v.Provider, resp.StatusCode)
+	}
+	var out struct {
+		Success    bool     `json:"success"`
+		ErrorCodes []string `json:"error-codes"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&out); err != nil {
+		return fmt.Errorf("decode %s response: %w", v.Provider, err)
+	}
+	if !out.Success {
+		// A bad secret is our misconfiguration, not the visitor's fault.
+		for _, code := range out.ErrorCodes {
+			if code == "invalid-input-secret" || code == "missing-input-secret" {
+				return fmt.Errorf("%s: %s", v.Provider, code)
+			}
+		}
+		return fmt.Errorf("%w: %s", ErrRejected, strings.Join(out.ErrorCodes, ", "))
+	}
+	return nil
+}
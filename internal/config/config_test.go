@@ -0,0 +1,172 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// fileVars are the variables the cases below touch. Each case starts with
+// them cleared, and t.Setenv puts back whatever applyFile set.
+var fileVars = []string{
+	"PORT", "LOG_FORMAT", "SHUTDOWN_TIMEOUT", "FRONTEND_BASE_URL",
+	"CORS_ALLOWED_ORIGINS", "RECOMMENDATION_MIN_RESPONSES", "LEGACY_API_SUNSET",
+}
+
+func TestLoadLayersFileUnderEnvironment(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		env     map[string]string
+		check   func(t *testing.T, cfg Config)
+		wantErr string
+	}{
+		{
+			name: "file fills unset variables",
+			file: `port = 9000`,
+			check: func(t *testing.T, cfg Config) {
+				if cfg.Port != "9000" {
+					t.Errorf("Port = %q, want 9000", cfg.Port)
+				}
+			},
+		},
+		{
+			name: "environment wins over the file",
+			file: `port = 9000`,
+			env:  map[string]string{"PORT": "7000"},
+			check: func(t *testing.T, cfg Config) {
+				if cfg.Port != "7000" {
+					t.Errorf("Port = %q, want 7000", cfg.Port)
+				}
+			},
+		},
+		{
+			name: "tables prefix their keys",
+			file: "[recommendation]\nmin_responses = 7\n",
+			check: func(t *testing.T, cfg Config) {
+				if cfg.Recommendation.MinResponses != 7 {
+					t.Errorf("MinResponses = %d, want 7", cfg.Recommendation.MinResponses)
+				}
+			},
+		},
+		{
+			name: "inline tables prefix their keys",
+			file: `recommendation = { min_responses = 4 }`,
+			check: func(t *testing.T, cfg Config) {
+				if cfg.Recommendation.MinResponses != 4 {
+					t.Errorf("MinResponses = %d, want 4", cfg.Recommendation.MinResponses)
+				}
+			},
+		},
+		{
+			name: "arrays span lines and join with commas",
+			file: "cors_allowed_origins = [\n  \"https://a.example\", # first\n  'https://b.example',\n]\n",
+			check: func(t *testing.T, cfg Config) {
+				want := []string{"https://a.example", "https://b.example"}
+				if !slices.Equal(cfg.CORS.AllowedOrigins, want) {
+					t.Errorf("AllowedOrigins = %q, want %q", cfg.CORS.AllowedOrigins, want)
+				}
+			},
+		},
+		{
+			name: "strings keep # and decode escapes",
+			file: `frontend_base_url = "https://x.example/\u0061#b" # comment` + "\nlog_format = 'text'\n",
+			check: func(t *testing.T, cfg Config) {
+				if cfg.HTTP.FrontendBaseURL != "https://x.example/a#b" {
+					t.Errorf("FrontendBaseURL = %q", cfg.HTTP.FrontendBaseURL)
+				}
+				if cfg.LogFormat != "text" {
+					t.Errorf("LogFormat = %q, want text", cfg.LogFormat)
+				}
+			},
+		},
+		{
+			name: "bare dates become dates",
+			file: `legacy_api_sunset = 2028-01-31`,
+			check: func(t *testing.T, cfg Config) {
+				if got := cfg.HTTP.LegacyAPISunset.Format("2006-01-02"); got != "2028-01-31" {
+					t.Errorf("LegacyAPISunset = %s, want 2028-01-31", got)
+				}
+			},
+		},
+		{
+			name:    "file values are validated like variables",
+			file:    `shutdown_timeout = "soon"`,
+			wantErr: "SHUTDOWN_TIMEOUT",
+		},
+		{
+			name:    "a key reached twice is refused",
+			file:    "recommendation_min_responses = 1\n[recommendation]\nmin_responses = 2\n",
+			wantErr: "set twice",
+		},
+		{
+			name:    "malformed TOML is refused",
+			file:    `port = `,
+			wantErr: "CONFIG_FILE",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range fileVars {
+				t.Setenv(key, "")
+			}
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			path := filepath.Join(t.TempDir(), "config.toml")
+			if err := os.WriteFile(path, []byte(tt.file), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			t.Setenv("CONFIG_FILE", path)
+
+			cfg, err := Load()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Load() error = %v, want one mentioning %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			tt.check(t, cfg)
+		})
+	}
+}
+
+func TestExampleFileLoads(t *testing.T) {
+	for _, key := range fileVars {
+		t.Setenv(key, "")
+	}
+	for _, key := range []string{"APP_ENV", "LOG_FORMAT", "RATE_LIMITS", "RATE_LIMITER", "RECOMMENDATION_WEIGHTS", "DECISION_CLOSE_INTERVAL", "DECISION_RETENTION_MODE"} {
+		t.Setenv(key, "")
+	}
+	t.Setenv("CONFIG_FILE", filepath.Join("..", "..", "config.example.toml"))
+	if _, err := Load(); err != nil {
+		t.Fatalf("config.example.toml: %v", err)
+	}
+}
+
+func TestLoadKeys(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("ADMIN_API_KEY", " admin-key ")
+	t.Setenv("WRITE_API_KEYS", "one, two:admin:2030-01-01T00:00:00Z,,")
+	t.Setenv("GRPC_API_KEYS", "")
+	t.Setenv("VIEWER_TOKEN_SECRET", "viewer-secret")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Keys{
+		AdminAPIKey:       "admin-key",
+		WriteAPIKeys:      []string{"one", "two:admin:2030-01-01T00:00:00Z"},
+		ViewerTokenSecret: "viewer-secret",
+	}
+	if cfg.Keys.AdminAPIKey != want.AdminAPIKey || !slices.Equal(cfg.Keys.WriteAPIKeys, want.WriteAPIKeys) ||
+		cfg.Keys.GRPCAPIKeys != nil || cfg.Keys.ViewerTokenSecret != want.ViewerTokenSecret {
+		t.Fatalf("Keys = %+v, want %+v", cfg.Keys, want)
+	}
+}
@@ -0,0 +1,264 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// envReader reads typed values from the environment and keeps every
+// malformed one it meets, so Load can report them together. A failed read
+// returns the fallback to keep going.
+type envReader struct {
+	errs []error
+}
+
+func (e *envReader) fail(key string, err error) {
+	e.errs = append(e.errs, fmt.Errorf("%s: %w", key, err))
+}
+
+func (e *envReader) err() error {
+	return errors.Join(e.errs...)
+}
+
+func (e *envReader) lookup(key string) (string, bool) {
+	value := strings.TrimSpace(os.Getenv(key))
+	return value, value != ""
+}
+
+func (e *envReader) str(key, fallback string) string {
+	if value, ok := e.lookup(key); ok {
+		return value
+	}
+	return fallback
+}
+
+func (e *envReader) oneOf(key, fallback string, allowed ...string) string {
+	value, ok := e.lookup(key)
+	if !ok {
+		return fallback
+	}
+	value = strings.ToLower(value)
+	for _, a := range allowed {
+		if value == a {
+			return value
+		}
+	}
+	e.fail(key, fmt.Errorf("%q is not one of %s", value, strings.Join(allowed, ", ")))
+	return fallback
+}
+
+func (e *envReader) boolean(key string, fallback bool) bool {
+	value, ok := e.lookup(key)
+	if !ok {
+		return fallback
+	}
+	switch strings.ToLower(value) {
+	case "1", "true", "yes", "on":
+		return true
+	case "0", "false", "no", "off":
+		return false
+	}
+	e.fail(key, fmt.Errorf("%q is not a boolean", value))
+	return fallback
+}
+
+func (e *envReader) integer(key string, fallback, min int) int {
+	value, ok := e.lookup(key)
+	if !ok {
+		return fallback
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		e.fail(key, fmt.Errorf("%q is not an integer", value))
+		return fallback
+	}
+	if n < min {
+		e.fail(key, fmt.Errorf("must be at least %d", min))
+		return fallback
+	}
+	return n
+}
+
+func (e *envReader) duration(key string, fallback, min time.Duration) time.Duration {
+	value, ok := e.lookup(key)
+	if !ok {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		e.fail(key, fmt.Errorf("%q is not a duration such as 30s or 5m", value))
+		return fallback
+	}
+	if d < min {
+		if min == 0 {
+			e.fail(key, errors.New("must not be negative"))
+		} else {
+			e.fail(key, fmt.Errorf("must be at least %s", min))
+		}
+		return fallback
+	}
+	return d
+}
+
+// list reads a comma-separated list, leaving out empty entries.
+func (e *envReader) list(key string) []string {
+	value, ok := e.lookup(key)
+	if !ok {
+		return nil
+	}
+	var items []string
+	for _, part := range strings.Split(value, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			items = append(items, part)
+		}
+	}
+	return items
+}
+
+// logLevel accepts debug, info, warn or error, optionally with an offset
+// such as debug-2.
+func (e *envReader) logLevel(key string, fallback slog.Level) slog.Level {
+	value, ok := e.lookup(key)
+	if !ok {
+		return fallback
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(value)); err != nil {
+		e.fail(key, fmt.Errorf("%q is not debug, info, warn or error", value))
+		return fallback
+	}
+	return level
+}
+
+// date accepts a date (2027-04-15) or an RFC 3339 time.
+func (e *envReader) date(key string, fallback time.Time) time.Time {
+	value, ok := e.lookup(key)
+	if !ok {
+		return fallback
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t
+	}
+	e.fail(key, fmt.Errorf("%q is not a date or RFC 3339 time", value))
+	return fallback
+}
+
+// origins reads a comma-separated list of origins, or "*" for any.
+func (e *envReader) origins(key, fallback string) ([]string, bool) {
+	raw := e.str(key, fallback)
+	if raw == "*" {
+		return nil, true
+	}
+	var origins []string
+	for _, part := range strings.Split(raw, ",") {
+		origin := strings.TrimSpace(part)
+		if origin == "" {
+			continue
+		}
+		if origin == "*" {
+			e.fail(key, errors.New("* must be the only entry"))
+			continue
+		}
+		if !strings.Contains(origin, "://") {
+			e.fail(key, fmt.Errorf("%q is not an origin such as https://example.com", origin))
+			continue
+		}
+		origins = append(origins, strings.TrimRight(origin, "/"))
+	}
+	return origins, false
+}
+
+// rateLimits reads a comma-separated list of name=count/window such as
+// "read=300/m,create_decision=10/h". Windows are s, m, h or any Go duration
+// ("20/15m"). Which names exist is up to the caller.
+func (e *envReader) rateLimits(key string) map[string]RateLimit {
+	value, ok := e.lookup(key)
+	if !ok {
+		return nil
+	}
+	limits := make(map[string]RateLimit)
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, spec, _ := strings.Cut(entry, "=")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			e.fail(key, fmt.Errorf("%q has no name", entry))
+			continue
+		}
+		limit, err := parseRateSpec(spec)
+		if err != nil {
+			e.fail(key, fmt.Errorf("%q: %w", entry, err))
+			continue
+		}
+		limits[name] = limit
+	}
+	return limits
+}
+
+func parseRateSpec(spec string) (RateLimit, error) {
+	countText, windowText, ok := strings.Cut(strings.TrimSpace(spec), "/")
+	if !ok {
+		return RateLimit{}, errors.New("expected count/window")
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(countText))
+	if err != nil || count < 0 {
+		return RateLimit{}, errors.New("count must be a non-negative integer")
+	}
+	windowText = strings.TrimSpace(windowText)
+	switch windowText {
+	case "s", "m", "h":
+		windowText = "1" + windowText
+	}
+	window, err := time.ParseDuration(windowText)
+	if err != nil || window <= 0 {
+		return RateLimit{}, errors.New("window must be s, m, h or a positive duration")
+	}
+	return RateLimit{Count: count, Window: window}, nil
+}
+
+// weights reads name=weight pairs such as
+// "suggestion=0.4,rating=0.3,comment_sentiment=0.2,post_vote=0.1". Names
+// left out keep their defaults.
+func (e *envReader) weights(key string, fallback RecommendationWeights) RecommendationWeights {
+	value, ok := e.lookup(key)
+	if !ok {
+		return fallback
+	}
+	w := fallback
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, raw, _ := strings.Cut(entry, "=")
+		weight, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			e.fail(key, fmt.Errorf("%q is not a number", entry))
+			continue
+		}
+		switch strings.TrimSpace(name) {
+		case "suggestion":
+			w.Suggestion = weight
+		case "rating":
+			w.Rating = weight
+		case "comment_sentiment":
+			w.CommentSentiment = weight
+		case "post_vote":
+			w.PostVote = weight
+		default:
+			e.fail(key, fmt.Errorf("unknown weight %q", name))
+		}
+	}
+	return w
+}
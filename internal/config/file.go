@@ -0,0 +1,125 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// applyFile reads the settings file at path and sets every variable it
+// names that the environment does not already set, so the file sits under
+// the environment: a deploy can keep its knobs in a versioned file per
+// environment and still override one with a variable. Going through the
+// environment means packages that read their own settings (API keys, mail,
+// OAuth) see the file too.
+func applyFile(path string) error {
+	values, err := readFile(path)
+	if err != nil {
+		return err
+	}
+	for key, value := range values {
+		if strings.TrimSpace(os.Getenv(key)) != "" {
+			continue
+		}
+		if err := os.Setenv(key, value); err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// readFile parses the TOML settings file at path into environment
+// variables. A key names the variable it sets, upper-cased and prefixed by
+// the tables it sits in, so
+//
+//	[jobs]
+//	reminder_interval = "10m"
+//
+// is JOBS_REMINDER_INTERVAL, and a top-level port = 8080 is PORT. Arrays are
+// joined with commas, the form list variables take.
+func readFile(path string) (map[string]string, error) {
+	var doc map[string]any
+	if _, err := toml.DecodeFile(path, &doc); err != nil {
+		return nil, err
+	}
+	values := make(map[string]string)
+	if err := flattenTable(values, "", doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return values, nil
+}
+
+func flattenTable(values map[string]string, prefix string, table map[string]any) error {
+	names := make([]string, 0, len(table))
+	for name := range table {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		key := strings.ToUpper(name)
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+		var err error
+		switch v := table[name].(type) {
+		case map[string]any:
+			err = flattenTable(values, key, v)
+		case []any:
+			parts := make([]string, 0, len(v))
+			for _, item := range v {
+				s, ok := scalarString(item)
+				if !ok {
+					return fmt.Errorf("%s: arrays may only hold strings, numbers and booleans", key)
+				}
+				parts = append(parts, s)
+			}
+			err = setValue(values, key, strings.Join(parts, ","))
+		default:
+			s, ok := scalarString(v)
+			if !ok {
+				return fmt.Errorf("%s: unsupported value %T", key, v)
+			}
+			err = setValue(values, key, s)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// setValue refuses a key reached twice, e.g. both jobs_x at the top level
+// and x under [jobs].
+func setValue(values map[string]string, key, value string) error {
+	if _, dup := values[key]; dup {
+		return fmt.Errorf("%s is set twice", key)
+	}
+	values[key] = value
+	return nil
+}
+
+func scalarString(v any) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(v), true
+	case time.Time:
+		// A bare date such as 2027-04-15 decodes into its own zone.
+		if v.Location().String() == "date-local" {
+			return v.Format(time.DateOnly), true
+		}
+		return v.Format(time.RFC3339), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,52 @@
+package config
+
+import (
+	"flag"
+	"os"
+)
+
+// Flag names shared by the binaries.
+const (
+	FlagConfig        = "config"
+	FlagPort          = "port"
+	FlagDatabaseURL   = "database-url"
+	FlagMigrationsDir = "migrations-dir"
+	FlagLogLevel      = "log-level"
+)
+
+var flagVars = map[string]struct{ env, usage string }{
+	FlagConfig:        {"CONFIG_FILE", "settings file layered under the environment"},
+	FlagPort:          {"PORT", "HTTP port to listen on"},
+	FlagDatabaseURL:   {"DATABASE_URL", "Postgres connection URL"},
+	FlagMigrationsDir: {"MIGRATIONS_DIR", "read migrations from this directory instead of the embedded copy"},
+	FlagLogLevel:      {"LOG_LEVEL", "debug, info, warn or error"},
+}
+
+// RegisterFlags adds the named flags to fs. Each stands in for an
+// environment variable and, once ApplyFlags has run, takes precedence over
+// it, so a local run can pass -database-url rather than export
+// DATABASE_URL.
+func RegisterFlags(fs *flag.FlagSet, names ...string) {
+	for _, name := range names {
+		v, ok := flagVars[name]
+		if !ok {
+			panic("config: unknown flag " + name)
+		}
+		fs.String(name, "", v.usage+" (overrides "+v.env+")")
+	}
+}
+
+// ApplyFlags copies the flags registered by RegisterFlags that were given
+// on the command line into the environment, for Load to pick up. Call it
+// after fs.Parse.
+func ApplyFlags(fs *flag.FlagSet) error {
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		v, ok := flagVars[f.Name]
+		if !ok || err != nil {
+			return
+		}
+		err = os.Setenv(v.env, f.Value.String())
+	})
+	return err
+}
@@ -0,0 +1,133 @@
+// Package contentfilter screens user-written text against a blocklist of
+// words. Matching is whole-word and case-insensitive.
+package contentfilter
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Mode is what happens to text containing a blocked word.
+type Mode string
+
+const (
+	// ModeReject refuses the text.
+	ModeReject Mode = "reject"
+	// ModeMask replaces each letter of a blocked word with '*'.
+	ModeMask Mode = "mask"
+	// ModeFlag accepts the text unchanged and reports it for review.
+	ModeFlag Mode = "flag"
+)
+
+// ErrBlocked is returned by Apply in reject mode.
+var ErrBlocked = errors.New("contains blocked language")
+
+type Filter struct {
+	Mode  Mode
+	words map[string]struct{}
+}
+
+// New builds a filter for words. Blank words are ignored.
+func New(mode Mode, words []string) *Filter {
+	f := &Filter{Mode: mode, words: make(map[string]struct{}, len(words))}
+	for _, w := range words {
+		w = strings.ToLower(strings.TrimSpace(w))
+		if w != "" {
+			f.words[w] = struct{}{}
+		}
+	}
+	return f
+}
+
+// FromEnv reads the blocklist from CONTENT_FILTER_WORDS (comma-separated)
+// and CONTENT_FILTER_WORDS_FILE (one word per line, # starts a comment),
+// and the mode from CONTENT_FILTER_MODE, which defaults to reject. It
+// returns nil when no words are configured, which disables filtering.
+func FromEnv() (*Filter, error) {
+	mode := Mode(strings.ToLower(strings.TrimSpace(os.Getenv("CONTENT_FILTER_MODE"))))
+	switch mode {
+	case "":
+		mode = ModeReject
+	case ModeReject, ModeMask, ModeFlag:
+	default:
+		return nil, fmt.Errorf("CONTENT_FILTER_MODE must be reject, mask or flag, got %q", mode)
+	}
+
+	words := strings.Split(os.Getenv("CONTENT_FILTER_WORDS"), ",")
+	if path := strings.TrimSpace(os.Getenv("CONTENT_FILTER_WORDS_FILE")); path != "" {
+		fromFile, err := readWordFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read CONTENT_FILTER_WORDS_FILE: %w", err)
+		}
+		words = append(words, fromFile...)
+	}
+
+	f := New(mode, words)
+	if len(f.words) == 0 {
+		return nil, nil
+	}
+	return f, nil
+}
+
+func readWordFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var words []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		words = append(words, line)
+	}
+	return words, scanner.Err()
+}
+
+// Apply screens text. In reject mode a match returns ErrBlocked; in mask
+// mode the returned text has blocked words starred out; in flag mode the
+// text comes back unchanged. matched reports whether any blocked word was
+// found. A nil filter passes everything through.
+func (f *Filter) Apply(text string) (out string, matched bool, err error) {
+	if f == nil || len(f.words) == 0 {
+		return text, false, nil
+	}
+
+	runes := []rune(text)
+	for start := 0; start < len(runes); {
+		if !isWordRune(runes[start]) {
+			start++
+			continue
+		}
+		end := start
+		for end < len(runes) && isWordRune(runes[end]) {
+			end++
+		}
+		if _, blocked := f.words[strings.ToLower(string(runes[start:end]))]; blocked {
+			matched = true
+			if f.Mode == ModeReject {
+				return "", true, ErrBlocked
+			}
+			if f.Mode == ModeMask {
+				for i := start; i < end; i++ {
+					runes[i] = '*'
+				}
+			}
+		}
+		start = end
+	}
+
+	if f.Mode == ModeMask && matched {
+		return string(runes), true, nil
+	}
+	return text, matched, nil
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsNumber(r) || r == '\''
+}
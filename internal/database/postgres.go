@@ -5,26 +5,70 @@ import (
 	"database/sql"
 	"time"
 
-	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
 )
 
-func Connect(ctx context.Context, databaseURL string) (*sql.DB, error) {
-	db, err := sql.Open("pgx", databaseURL)
+func Connect(ctx context.Context, databaseURL string) (*pgxpool.Pool, error) {
+	cfg, err := pgxpool.ParseConfig(databaseURL)
 	if err != nil {
 		return nil, err
 	}
 
-	db.SetMaxOpenConns(20)
-	db.SetMaxIdleConns(5)
-	db.SetConnMaxLifetime(30 * time.Minute)
+	cfg.MaxConns = 20
+	cfg.MaxConnLifetime = 30 * time.Minute
+
+	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
 
 	pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	if err := db.PingContext(pingCtx); err != nil {
-		_ = db.Close()
+	if err := pool.Ping(pingCtx); err != nil {
+		pool.Close()
 		return nil, err
 	}
 
-	return db, nil
+	return pool, nil
+}
+
+// SQL exposes the pool to code that still uses database/sql. Connections
+// are borrowed from the pool per use, so both share one set of limits,
+// statement caches and stats. Closing the pool closes them all.
+func SQL(pool *pgxpool.Pool) *sql.DB {
+	return stdlib.OpenDBFromPool(pool)
+}
+
+type PoolStats struct {
+	TotalConns           int32   `json:"total_conns"`
+	AcquiredConns        int32   `json:"acquired_conns"`
+	IdleConns            int32   `json:"idle_conns"`
+	ConstructingConns    int32   `json:"constructing_conns"`
+	MaxConns             int32   `json:"max_conns"`
+	AcquireCount         int64   `json:"acquire_count"`
+	EmptyAcquireCount    int64   `json:"empty_acquire_count"`
+	CanceledAcquireCount int64   `json:"canceled_acquire_count"`
+	AcquireDurationMS    float64 `json:"acquire_duration_ms"`
+	NewConnsCount        int64   `json:"new_conns_count"`
+}
+
+// Stats snapshots the pool counters. EmptyAcquireCount climbing while
+// AcquiredConns sits at MaxConns means requests are queueing for a
+// connection.
+func Stats(pool *pgxpool.Pool) PoolStats {
+	st := pool.Stat()
+	return PoolStats{
+		TotalConns:           st.TotalConns(),
+		AcquiredConns:        st.AcquiredConns(),
+		IdleConns:            st.IdleConns(),
+		ConstructingConns:    st.ConstructingConns(),
+		MaxConns:             st.MaxConns(),
+		AcquireCount:         st.AcquireCount(),
+		EmptyAcquireCount:    st.EmptyAcquireCount(),
+		CanceledAcquireCount: st.CanceledAcquireCount(),
+		AcquireDurationMS:    float64(st.AcquireDuration()) / float64(time.Millisecond),
+		NewConnsCount:        st.NewConnsCount(),
+	}
 }
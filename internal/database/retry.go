@@ -0,0 +1,98 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	retryAttempts    = 4
+	retryBaseBackoff = 25 * time.Millisecond
+	retryMaxBackoff  = 500 * time.Millisecond
+)
+
+// commitError marks a failed COMMIT. After anything but a serialization
+// failure or deadlock the transaction may or may not have been applied, so
+// it must not be replayed.
+type commitError struct{ err error }
+
+func (e commitError) Error() string { return e.err.Error() }
+func (e commitError) Unwrap() error { return e.err }
+
+// IsTransient reports whether err is worth retrying: serialization failures
+// and deadlocks, and connection failures such as a reset socket or a
+// primary that is restarting or failing over.
+func IsTransient(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "40001", "40P01", "57P01", "57P02", "57P03":
+			return true
+		}
+		// Class 08: connection exceptions.
+		return len(pgErr.Code) == 5 && pgErr.Code[:2] == "08"
+	}
+	return errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		pgconn.SafeToRetry(err)
+}
+
+func retryable(err error) bool {
+	var ce commitError
+	if errors.As(err, &ce) {
+		var pgErr *pgconn.PgError
+		return errors.As(err, &pgErr) && (pgErr.Code == "40001" || pgErr.Code == "40P01")
+	}
+	return IsTransient(err)
+}
+
+// RetryTx runs fn in a transaction and commits it. Transient failures roll
+// the transaction back and run it again from the start, with jittered
+// backoff, up to retryAttempts times. fn must not have side effects outside
+// tx. The last error is returned once attempts or ctx run out.
+func RetryTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	for attempt := 1; ; attempt++ {
+		err := runTx(ctx, db, fn)
+		if err == nil || attempt == retryAttempts || !retryable(err) || ctx.Err() != nil {
+			return err
+		}
+
+		backoff := min(retryBaseBackoff<<(attempt-1), retryMaxBackoff)
+		backoff = backoff/2 + rand.N(backoff/2+1)
+		slog.Warn("retrying transient database error", "attempt", attempt, "backoff", backoff.String(), "error", err)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+func runTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return commitError{err}
+	}
+	return nil
+}
@@ -0,0 +1,1046 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.5
+// 	protoc        (unknown)
+// source: ratemylifedecision/v1/decisions.proto
+
+package ratemylifedecisionv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type CreateDecisionRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Title       string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	Description *string                `protobuf:"bytes,2,opt,name=description,proto3,oneof" json:"description,omitempty"`
+	// Unix seconds; unset means the decision never closes.
+	ClosesAt      *int64  `protobuf:"varint,3,opt,name=closes_at,json=closesAt,proto3,oneof" json:"closes_at,omitempty"`
+	Category      *string `protobuf:"bytes,4,opt,name=category,proto3,oneof" json:"category,omitempty"`
+	AggregateOnly bool    `protobuf:"varint,5,opt,name=aggregate_only,json=aggregateOnly,proto3" json:"aggregate_only,omitempty"`
+	// Responses needed before stats are shown; 0 shows them right away.
+	Quorum int32 `protobuf:"varint,6,opt,name=quorum,proto3" json:"quorum,omitempty"`
+	// "public" (the default), "unlisted", or "private".
+	Visibility *string `protobuf:"bytes,7,opt,name=visibility,proto3,oneof" json:"visibility,omitempty"`
+	// Private decisions only; made up when unset.
+	AccessCode *string `protobuf:"bytes,8,opt,name=access_code,json=accessCode,proto3,oneof" json:"access_code,omitempty"`
+	// A vanity slug; the generated one is used if it is taken.
+	Slug          *string `protobuf:"bytes,9,opt,name=slug,proto3,oneof" json:"slug,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateDecisionRequest) Reset() {
+	*x = CreateDecisionRequest{}
+	mi := &file_ratemylifedecision_v1_decisions_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateDecisionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateDecisionRequest) ProtoMessage() {}
+
+func (x *CreateDecisionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ratemylifedecision_v1_decisions_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateDecisionRequest.ProtoReflect.Descriptor instead.
+func (*CreateDecisionRequest) Descriptor() ([]byte, []int) {
+	return file_ratemylifedecision_v1_decisions_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *CreateDecisionRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *CreateDecisionRequest) GetDescription() string {
+	if x != nil && x.Description != nil {
+		return *x.Description
+	}
+	return ""
+}
+
+func (x *CreateDecisionRequest) GetClosesAt() int64 {
+	if x != nil && x.ClosesAt != nil {
+		return *x.ClosesAt
+	}
+	return 0
+}
+
+func (x *CreateDecisionRequest) GetCategory() string {
+	if x != nil && x.Category != nil {
+		return *x.Category
+	}
+	return ""
+}
+
+func (x *CreateDecisionRequest) GetAggregateOnly() bool {
+	if x != nil {
+		return x.AggregateOnly
+	}
+	return false
+}
+
+func (x *CreateDecisionRequest) GetQuorum() int32 {
+	if x != nil {
+		return x.Quorum
+	}
+	return 0
+}
+
+func (x *CreateDecisionRequest) GetVisibility() string {
+	if x != nil && x.Visibility != nil {
+		return *x.Visibility
+	}
+	return ""
+}
+
+func (x *CreateDecisionRequest) GetAccessCode() string {
+	if x != nil && x.AccessCode != nil {
+		return *x.AccessCode
+	}
+	return ""
+}
+
+func (x *CreateDecisionRequest) GetSlug() string {
+	if x != nil && x.Slug != nil {
+		return *x.Slug
+	}
+	return ""
+}
+
+type CreateDecisionResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Slug  string                 `protobuf:"bytes,2,opt,name=slug,proto3" json:"slug,omitempty"`
+	// Shown once; required to delete the decision later.
+	CreatorToken string `protobuf:"bytes,3,opt,name=creator_token,json=creatorToken,proto3" json:"creator_token,omitempty"`
+	// Shown once, for private decisions only.
+	AccessCode    string `protobuf:"bytes,4,opt,name=access_code,json=accessCode,proto3" json:"access_code,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateDecisionResponse) Reset() {
+	*x = CreateDecisionResponse{}
+	mi := &file_ratemylifedecision_v1_decisions_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateDecisionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateDecisionResponse) ProtoMessage() {}
+
+func (x *CreateDecisionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ratemylifedecision_v1_decisions_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateDecisionResponse.ProtoReflect.Descriptor instead.
+func (*CreateDecisionResponse) Descriptor() ([]byte, []int) {
+	return file_ratemylifedecision_v1_decisions_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateDecisionResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CreateDecisionResponse) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *CreateDecisionResponse) GetCreatorToken() string {
+	if x != nil {
+		return x.CreatorToken
+	}
+	return ""
+}
+
+func (x *CreateDecisionResponse) GetAccessCode() string {
+	if x != nil {
+		return x.AccessCode
+	}
+	return ""
+}
+
+type GetDecisionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Slug          string                 `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDecisionRequest) Reset() {
+	*x = GetDecisionRequest{}
+	mi := &file_ratemylifedecision_v1_decisions_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDecisionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDecisionRequest) ProtoMessage() {}
+
+func (x *GetDecisionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ratemylifedecision_v1_decisions_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDecisionRequest.ProtoReflect.Descriptor instead.
+func (*GetDecisionRequest) Descriptor() ([]byte, []int) {
+	return file_ratemylifedecision_v1_decisions_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetDecisionRequest) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+type Decision struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Slug          string                 `protobuf:"bytes,2,opt,name=slug,proto3" json:"slug,omitempty"`
+	Title         string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+	Description   *string                `protobuf:"bytes,4,opt,name=description,proto3,oneof" json:"description,omitempty"`
+	ClosesAt      *int64                 `protobuf:"varint,5,opt,name=closes_at,json=closesAt,proto3,oneof" json:"closes_at,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Category      *string                `protobuf:"bytes,7,opt,name=category,proto3,oneof" json:"category,omitempty"`
+	PanelOnly     bool                   `protobuf:"varint,8,opt,name=panel_only,json=panelOnly,proto3" json:"panel_only,omitempty"`
+	AggregateOnly bool                   `protobuf:"varint,9,opt,name=aggregate_only,json=aggregateOnly,proto3" json:"aggregate_only,omitempty"`
+	Quorum        int32                  `protobuf:"varint,10,opt,name=quorum,proto3" json:"quorum,omitempty"`
+	Visibility    string                 `protobuf:"bytes,11,opt,name=visibility,proto3" json:"visibility,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Decision) Reset() {
+	*x = Decision{}
+	mi := &file_ratemylifedecision_v1_decisions_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Decision) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Decision) ProtoMessage() {}
+
+func (x *Decision) ProtoReflect() protoreflect.Message {
+	mi := &file_ratemylifedecision_v1_decisions_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Decision.ProtoReflect.Descriptor instead.
+func (*Decision) Descriptor() ([]byte, []int) {
+	return file_ratemylifedecision_v1_decisions_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Decision) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Decision) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *Decision) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Decision) GetDescription() string {
+	if x != nil && x.Description != nil {
+		return *x.Description
+	}
+	return ""
+}
+
+func (x *Decision) GetClosesAt() int64 {
+	if x != nil && x.ClosesAt != nil {
+		return *x.ClosesAt
+	}
+	return 0
+}
+
+func (x *Decision) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *Decision) GetCategory() string {
+	if x != nil && x.Category != nil {
+		return *x.Category
+	}
+	return ""
+}
+
+func (x *Decision) GetPanelOnly() bool {
+	if x != nil {
+		return x.PanelOnly
+	}
+	return false
+}
+
+func (x *Decision) GetAggregateOnly() bool {
+	if x != nil {
+		return x.AggregateOnly
+	}
+	return false
+}
+
+func (x *Decision) GetQuorum() int32 {
+	if x != nil {
+		return x.Quorum
+	}
+	return 0
+}
+
+func (x *Decision) GetVisibility() string {
+	if x != nil {
+		return x.Visibility
+	}
+	return ""
+}
+
+type DeleteDecisionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Slug          string                 `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	CreatorToken  string                 `protobuf:"bytes,2,opt,name=creator_token,json=creatorToken,proto3" json:"creator_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteDecisionRequest) Reset() {
+	*x = DeleteDecisionRequest{}
+	mi := &file_ratemylifedecision_v1_decisions_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteDecisionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteDecisionRequest) ProtoMessage() {}
+
+func (x *DeleteDecisionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ratemylifedecision_v1_decisions_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteDecisionRequest.ProtoReflect.Descriptor instead.
+func (*DeleteDecisionRequest) Descriptor() ([]byte, []int) {
+	return file_ratemylifedecision_v1_decisions_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DeleteDecisionRequest) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+func (x *DeleteDecisionRequest) GetCreatorToken() string {
+	if x != nil {
+		return x.CreatorToken
+	}
+	return ""
+}
+
+type DeleteDecisionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteDecisionResponse) Reset() {
+	*x = DeleteDecisionResponse{}
+	mi := &file_ratemylifedecision_v1_decisions_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteDecisionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteDecisionResponse) ProtoMessage() {}
+
+func (x *DeleteDecisionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_ratemylifedecision_v1_decisions_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteDecisionResponse.ProtoReflect.Descriptor instead.
+func (*DeleteDecisionResponse) Descriptor() ([]byte, []int) {
+	return file_ratemylifedecision_v1_decisions_proto_rawDescGZIP(), []int{5}
+}
+
+type GetDecisionStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Slug          string                 `protobuf:"bytes,1,opt,name=slug,proto3" json:"slug,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetDecisionStatsRequest) Reset() {
+	*x = GetDecisionStatsRequest{}
+	mi := &file_ratemylifedecision_v1_decisions_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetDecisionStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDecisionStatsRequest) ProtoMessage() {}
+
+func (x *GetDecisionStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_ratemylifedecision_v1_decisions_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDecisionStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetDecisionStatsRequest) Descriptor() ([]byte, []int) {
+	return file_ratemylifedecision_v1_decisions_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetDecisionStatsRequest) GetSlug() string {
+	if x != nil {
+		return x.Slug
+	}
+	return ""
+}
+
+type DecisionStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ResponseCount int32                  `protobuf:"varint,1,opt,name=response_count,json=responseCount,proto3" json:"response_count,omitempty"`
+	// Counts for ratings 1 through 5, in order.
+	RatingCounts   []int32         `protobuf:"varint,2,rep,packed,name=rating_counts,json=ratingCounts,proto3" json:"rating_counts,omitempty"`
+	AvgRating      float64         `protobuf:"fixed64,3,opt,name=avg_rating,json=avgRating,proto3" json:"avg_rating,omitempty"`
+	NetSentiment   float64         `protobuf:"fixed64,4,opt,name=net_sentiment,json=netSentiment,proto3" json:"net_sentiment,omitempty"`
+	DoIt           int32           `protobuf:"varint,5,opt,name=do_it,json=doIt,proto3" json:"do_it,omitempty"`
+	DontDoIt       int32           `protobuf:"varint,6,opt,name=dont_do_it,json=dontDoIt,proto3" json:"dont_do_it,omitempty"`
+	Mixed          int32           `protobuf:"varint,7,opt,name=mixed,proto3" json:"mixed,omitempty"`
+	EmojiCounts    []*EmojiCount   `protobuf:"bytes,8,rep,name=emoji_counts,json=emojiCounts,proto3" json:"emoji_counts,omitempty"`
+	TopEmoji       string          `protobuf:"bytes,9,opt,name=top_emoji,json=topEmoji,proto3" json:"top_emoji,omitempty"`
+	Recommendation *Recommendation `protobuf:"bytes,10,opt,name=recommendation,proto3" json:"recommendation,omitempty"`
+	VoteScore      int32           `protobuf:"varint,11,opt,name=vote_score,json=voteScore,proto3" json:"vote_score,omitempty"`
+	Upvotes        int32           `protobuf:"varint,12,opt,name=upvotes,proto3" json:"upvotes,omitempty"`
+	Downvotes      int32           `protobuf:"varint,13,opt,name=downvotes,proto3" json:"downvotes,omitempty"`
+	// Set while the quorum has not been met; everything but response_count
+	// is then left at its zero value.
+	CollectingResponses bool `protobuf:"varint,14,opt,name=collecting_responses,json=collectingResponses,proto3" json:"collecting_responses,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *DecisionStats) Reset() {
+	*x = DecisionStats{}
+	mi := &file_ratemylifedecision_v1_decisions_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DecisionStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DecisionStats) ProtoMessage() {}
+
+func (x *DecisionStats) ProtoReflect() protoreflect.Message {
+	mi := &file_ratemylifedecision_v1_decisions_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DecisionStats.ProtoReflect.Descriptor instead.
+func (*DecisionStats) Descriptor() ([]byte, []int) {
+	return file_ratemylifedecision_v1_decisions_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DecisionStats) GetResponseCount() int32 {
+	if x != nil {
+		return x.ResponseCount
+	}
+	return 0
+}
+
+func (x *DecisionStats) GetRatingCounts() []int32 {
+	if x != nil {
+		return x.RatingCounts
+	}
+	return nil
+}
+
+func (x *DecisionStats) GetAvgRating() float64 {
+	if x != nil {
+		return x.AvgRating
+	}
+	return 0
+}
+
+func (x *DecisionStats) GetNetSentiment() float64 {
+	if x != nil {
+		return x.NetSentiment
+	}
+	return 0
+}
+
+func (x *DecisionStats) GetDoIt() int32 {
+	if x != nil {
+		return x.DoIt
+	}
+	return 0
+}
+
+func (x *DecisionStats) GetDontDoIt() int32 {
+	if x != nil {
+		return x.DontDoIt
+	}
+	return 0
+}
+
+func (x *DecisionStats) GetMixed() int32 {
+	if x != nil {
+		return x.Mixed
+	}
+	return 0
+}
+
+func (x *DecisionStats) GetEmojiCounts() []*EmojiCount {
+	if x != nil {
+		return x.EmojiCounts
+	}
+	return nil
+}
+
+func (x *DecisionStats) GetTopEmoji() string {
+	if x != nil {
+		return x.TopEmoji
+	}
+	return ""
+}
+
+func (x *DecisionStats) GetRecommendation() *Recommendation {
+	if x != nil {
+		return x.Recommendation
+	}
+	return nil
+}
+
+func (x *DecisionStats) GetVoteScore() int32 {
+	if x != nil {
+		return x.VoteScore
+	}
+	return 0
+}
+
+func (x *DecisionStats) GetUpvotes() int32 {
+	if x != nil {
+		return x.Upvotes
+	}
+	return 0
+}
+
+func (x *DecisionStats) GetDownvotes() int32 {
+	if x != nil {
+		return x.Downvotes
+	}
+	return 0
+}
+
+func (x *DecisionStats) GetCollectingResponses() bool {
+	if x != nil {
+		return x.CollectingResponses
+	}
+	return false
+}
+
+type EmojiCount struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Emoji         string                 `protobuf:"bytes,1,opt,name=emoji,proto3" json:"emoji,omitempty"`
+	Count         int32                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EmojiCount) Reset() {
+	*x = EmojiCount{}
+	mi := &file_ratemylifedecision_v1_decisions_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EmojiCount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EmojiCount) ProtoMessage() {}
+
+func (x *EmojiCount) ProtoReflect() protoreflect.Message {
+	mi := &file_ratemylifedecision_v1_decisions_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EmojiCount.ProtoReflect.Descriptor instead.
+func (*EmojiCount) Descriptor() ([]byte, []int) {
+	return file_ratemylifedecision_v1_decisions_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *EmojiCount) GetEmoji() string {
+	if x != nil {
+		return x.Emoji
+	}
+	return ""
+}
+
+func (x *EmojiCount) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type Recommendation struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// "yes", "no", or "undecided" when there are too few responses.
+	Decision         string  `protobuf:"bytes,1,opt,name=decision,proto3" json:"decision,omitempty"`
+	Score            float64 `protobuf:"fixed64,2,opt,name=score,proto3" json:"score,omitempty"`
+	SuggestionScore  float64 `protobuf:"fixed64,3,opt,name=suggestion_score,json=suggestionScore,proto3" json:"suggestion_score,omitempty"`
+	RatingScore      float64 `protobuf:"fixed64,4,opt,name=rating_score,json=ratingScore,proto3" json:"rating_score,omitempty"`
+	CommentSentiment float64 `protobuf:"fixed64,5,opt,name=comment_sentiment,json=commentSentiment,proto3" json:"comment_sentiment,omitempty"`
+	PostVoteScore    float64 `protobuf:"fixed64,6,opt,name=post_vote_score,json=postVoteScore,proto3" json:"post_vote_score,omitempty"`
+	SampleSize       int32   `protobuf:"varint,7,opt,name=sample_size,json=sampleSize,proto3" json:"sample_size,omitempty"`
+	Confidence       float64 `protobuf:"fixed64,8,opt,name=confidence,proto3" json:"confidence,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Recommendation) Reset() {
+	*x = Recommendation{}
+	mi := &file_ratemylifedecision_v1_decisions_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Recommendation) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Recommendation) ProtoMessage() {}
+
+func (x *Recommendation) ProtoReflect() protoreflect.Message {
+	mi := &file_ratemylifedecision_v1_decisions_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Recommendation.ProtoReflect.Descriptor instead.
+func (*Recommendation) Descriptor() ([]byte, []int) {
+	return file_ratemylifedecision_v1_decisions_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Recommendation) GetDecision() string {
+	if x != nil {
+		return x.Decision
+	}
+	return ""
+}
+
+func (x *Recommendation) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *Recommendation) GetSuggestionScore() float64 {
+	if x != nil {
+		return x.SuggestionScore
+	}
+	return 0
+}
+
+func (x *Recommendation) GetRatingScore() float64 {
+	if x != nil {
+		return x.RatingScore
+	}
+	return 0
+}
+
+func (x *Recommendation) GetCommentSentiment() float64 {
+	if x != nil {
+		return x.CommentSentiment
+	}
+	return 0
+}
+
+func (x *Recommendation) GetPostVoteScore() float64 {
+	if x != nil {
+		return x.PostVoteScore
+	}
+	return 0
+}
+
+func (x *Recommendation) GetSampleSize() int32 {
+	if x != nil {
+		return x.SampleSize
+	}
+	return 0
+}
+
+func (x *Recommendation) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+var File_ratemylifedecision_v1_decisions_proto protoreflect.FileDescriptor
+
+var file_ratemylifedecision_v1_decisions_proto_rawDesc = string([]byte{
+	0x0a, 0x25, 0x72, 0x61, 0x74, 0x65, 0x6d, 0x79, 0x6c, 0x69, 0x66, 0x65, 0x64, 0x65, 0x63, 0x69,
+	0x73, 0x69, 0x6f, 0x6e, 0x2f, 0x76, 0x31, 0x2f, 0x64, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e,
+	0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x15, 0x72, 0x61, 0x74, 0x65, 0x6d, 0x79, 0x6c,
+	0x69, 0x66, 0x65, 0x64, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x22, 0x8d,
+	0x03, 0x0a, 0x15, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f,
+	0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x74, 0x69, 0x74, 0x6c,
+	0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x12, 0x25,
+	0x0a, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69,
+	0x6f, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x20, 0x0a, 0x09, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x73, 0x5f,
+	0x61, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x48, 0x01, 0x52, 0x08, 0x63, 0x6c, 0x6f, 0x73,
+	0x65, 0x73, 0x41, 0x74, 0x88, 0x01, 0x01, 0x12, 0x1f, 0x0a, 0x08, 0x63, 0x61, 0x74, 0x65, 0x67,
+	0x6f, 0x72, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x48, 0x02, 0x52, 0x08, 0x63, 0x61, 0x74,
+	0x65, 0x67, 0x6f, 0x72, 0x79, 0x88, 0x01, 0x01, 0x12, 0x25, 0x0a, 0x0e, 0x61, 0x67, 0x67, 0x72,
+	0x65, 0x67, 0x61, 0x74, 0x65, 0x5f, 0x6f, 0x6e, 0x6c, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x0d, 0x61, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x65, 0x4f, 0x6e, 0x6c, 0x79, 0x12,
+	0x16, 0x0a, 0x06, 0x71, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x06, 0x71, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x12, 0x23, 0x0a, 0x0a, 0x76, 0x69, 0x73, 0x69, 0x62,
+	0x69, 0x6c, 0x69, 0x74, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x48, 0x03, 0x52, 0x0a, 0x76,
+	0x69, 0x73, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x88, 0x01, 0x01, 0x12, 0x24, 0x0a, 0x0b,
+	0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x5f, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x09, 0x48, 0x04, 0x52, 0x0a, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x43, 0x6f, 0x64, 0x65, 0x88,
+	0x01, 0x01, 0x12, 0x17, 0x0a, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09,
+	0x48, 0x05, 0x52, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x88, 0x01, 0x01, 0x42, 0x0e, 0x0a, 0x0c, 0x5f,
+	0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x42, 0x0c, 0x0a, 0x0a, 0x5f,
+	0x63, 0x6c, 0x6f, 0x73, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x63, 0x61,
+	0x74, 0x65, 0x67, 0x6f, 0x72, 0x79, 0x42, 0x0d, 0x0a, 0x0b, 0x5f, 0x76, 0x69, 0x73, 0x69, 0x62,
+	0x69, 0x6c, 0x69, 0x74, 0x79, 0x42, 0x0e, 0x0a, 0x0c, 0x5f, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73,
+	0x5f, 0x63, 0x6f, 0x64, 0x65, 0x42, 0x07, 0x0a, 0x05, 0x5f, 0x73, 0x6c, 0x75, 0x67, 0x22, 0x82,
+	0x01, 0x0a, 0x16, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f,
+	0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6c, 0x75,
+	0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x12, 0x23, 0x0a,
+	0x0d, 0x63, 0x72, 0x65, 0x61, 0x74, 0x6f, 0x72, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x63, 0x72, 0x65, 0x61, 0x74, 0x6f, 0x72, 0x54, 0x6f, 0x6b,
+	0x65, 0x6e, 0x12, 0x1f, 0x0a, 0x0b, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x5f, 0x63, 0x6f, 0x64,
+	0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x61, 0x63, 0x63, 0x65, 0x73, 0x73, 0x43,
+	0x6f, 0x64, 0x65, 0x22, 0x28, 0x0a, 0x12, 0x47, 0x65, 0x74, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69,
+	0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6c, 0x75,
+	0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x22, 0xf6, 0x02,
+	0x0a, 0x08, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x6c,
+	0x75, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x12, 0x14,
+	0x0a, 0x05, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x74,
+	0x69, 0x74, 0x6c, 0x65, 0x12, 0x25, 0x0a, 0x0b, 0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74,
+	0x69, 0x6f, 0x6e, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x48, 0x00, 0x52, 0x0b, 0x64, 0x65, 0x73,
+	0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x88, 0x01, 0x01, 0x12, 0x20, 0x0a, 0x09, 0x63,
+	0x6c, 0x6f, 0x73, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x03, 0x48, 0x01,
+	0x52, 0x08, 0x63, 0x6c, 0x6f, 0x73, 0x65, 0x73, 0x41, 0x74, 0x88, 0x01, 0x01, 0x12, 0x1d, 0x0a,
+	0x0a, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28,
+	0x03, 0x52, 0x09, 0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x1f, 0x0a, 0x08,
+	0x63, 0x61, 0x74, 0x65, 0x67, 0x6f, 0x72, 0x79, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x48, 0x02,
+	0x52, 0x08, 0x63, 0x61, 0x74, 0x65, 0x67, 0x6f, 0x72, 0x79, 0x88, 0x01, 0x01, 0x12, 0x1d, 0x0a,
+	0x0a, 0x70, 0x61, 0x6e, 0x65, 0x6c, 0x5f, 0x6f, 0x6e, 0x6c, 0x79, 0x18, 0x08, 0x20, 0x01, 0x28,
+	0x08, 0x52, 0x09, 0x70, 0x61, 0x6e, 0x65, 0x6c, 0x4f, 0x6e, 0x6c, 0x79, 0x12, 0x25, 0x0a, 0x0e,
+	0x61, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x65, 0x5f, 0x6f, 0x6e, 0x6c, 0x79, 0x18, 0x09,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x0d, 0x61, 0x67, 0x67, 0x72, 0x65, 0x67, 0x61, 0x74, 0x65, 0x4f,
+	0x6e, 0x6c, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x71, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x18, 0x0a, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x06, 0x71, 0x75, 0x6f, 0x72, 0x75, 0x6d, 0x12, 0x1e, 0x0a, 0x0a, 0x76,
+	0x69, 0x73, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0a, 0x76, 0x69, 0x73, 0x69, 0x62, 0x69, 0x6c, 0x69, 0x74, 0x79, 0x42, 0x0e, 0x0a, 0x0c, 0x5f,
+	0x64, 0x65, 0x73, 0x63, 0x72, 0x69, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x42, 0x0c, 0x0a, 0x0a, 0x5f,
+	0x63, 0x6c, 0x6f, 0x73, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x42, 0x0b, 0x0a, 0x09, 0x5f, 0x63, 0x61,
+	0x74, 0x65, 0x67, 0x6f, 0x72, 0x79, 0x22, 0x50, 0x0a, 0x15, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65,
+	0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x12, 0x0a, 0x04, 0x73, 0x6c, 0x75, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73,
+	0x6c, 0x75, 0x67, 0x12, 0x23, 0x0a, 0x0d, 0x63, 0x72, 0x65, 0x61, 0x74, 0x6f, 0x72, 0x5f, 0x74,
+	0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0c, 0x63, 0x72, 0x65, 0x61,
+	0x74, 0x6f, 0x72, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x22, 0x18, 0x0a, 0x16, 0x44, 0x65, 0x6c, 0x65,
+	0x74, 0x65, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x22, 0x2d, 0x0a, 0x17, 0x47, 0x65, 0x74, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f,
+	0x6e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x12, 0x0a,
+	0x04, 0x73, 0x6c, 0x75, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x73, 0x6c, 0x75,
+	0x67, 0x22, 0xa4, 0x04, 0x0a, 0x0d, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74,
+	0x61, 0x74, 0x73, 0x12, 0x25, 0x0a, 0x0e, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x5f,
+	0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0d, 0x72, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x23, 0x0a, 0x0d, 0x72, 0x61,
+	0x74, 0x69, 0x6e, 0x67, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x18, 0x02, 0x20, 0x03, 0x28,
+	0x05, 0x52, 0x0c, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x12,
+	0x1d, 0x0a, 0x0a, 0x61, 0x76, 0x67, 0x5f, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x09, 0x61, 0x76, 0x67, 0x52, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x12, 0x23,
+	0x0a, 0x0d, 0x6e, 0x65, 0x74, 0x5f, 0x73, 0x65, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x6e, 0x74, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0c, 0x6e, 0x65, 0x74, 0x53, 0x65, 0x6e, 0x74, 0x69, 0x6d,
+	0x65, 0x6e, 0x74, 0x12, 0x13, 0x0a, 0x05, 0x64, 0x6f, 0x5f, 0x69, 0x74, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x04, 0x64, 0x6f, 0x49, 0x74, 0x12, 0x1c, 0x0a, 0x0a, 0x64, 0x6f, 0x6e, 0x74,
+	0x5f, 0x64, 0x6f, 0x5f, 0x69, 0x74, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x64, 0x6f,
+	0x6e, 0x74, 0x44, 0x6f, 0x49, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x6d, 0x69, 0x78, 0x65, 0x64, 0x18,
+	0x07, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6d, 0x69, 0x78, 0x65, 0x64, 0x12, 0x44, 0x0a, 0x0c,
+	0x65, 0x6d, 0x6f, 0x6a, 0x69, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x73, 0x18, 0x08, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x21, 0x2e, 0x72, 0x61, 0x74, 0x65, 0x6d, 0x79, 0x6c, 0x69, 0x66, 0x65, 0x64,
+	0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x6d, 0x6f, 0x6a, 0x69,
+	0x43, 0x6f, 0x75, 0x6e, 0x74, 0x52, 0x0b, 0x65, 0x6d, 0x6f, 0x6a, 0x69, 0x43, 0x6f, 0x75, 0x6e,
+	0x74, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x74, 0x6f, 0x70, 0x5f, 0x65, 0x6d, 0x6f, 0x6a, 0x69, 0x18,
+	0x09, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x74, 0x6f, 0x70, 0x45, 0x6d, 0x6f, 0x6a, 0x69, 0x12,
+	0x4d, 0x0a, 0x0e, 0x72, 0x65, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x64, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x72, 0x61, 0x74, 0x65, 0x6d, 0x79,
+	0x6c, 0x69, 0x66, 0x65, 0x64, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e,
+	0x52, 0x65, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x64, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x52, 0x0e,
+	0x72, 0x65, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x64, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1d,
+	0x0a, 0x0a, 0x76, 0x6f, 0x74, 0x65, 0x5f, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x0b, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x09, 0x76, 0x6f, 0x74, 0x65, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x12, 0x18, 0x0a,
+	0x07, 0x75, 0x70, 0x76, 0x6f, 0x74, 0x65, 0x73, 0x18, 0x0c, 0x20, 0x01, 0x28, 0x05, 0x52, 0x07,
+	0x75, 0x70, 0x76, 0x6f, 0x74, 0x65, 0x73, 0x12, 0x1c, 0x0a, 0x09, 0x64, 0x6f, 0x77, 0x6e, 0x76,
+	0x6f, 0x74, 0x65, 0x73, 0x18, 0x0d, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x64, 0x6f, 0x77, 0x6e,
+	0x76, 0x6f, 0x74, 0x65, 0x73, 0x12, 0x31, 0x0a, 0x14, 0x63, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74,
+	0x69, 0x6e, 0x67, 0x5f, 0x72, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x73, 0x18, 0x0e, 0x20,
+	0x01, 0x28, 0x08, 0x52, 0x13, 0x63, 0x6f, 0x6c, 0x6c, 0x65, 0x63, 0x74, 0x69, 0x6e, 0x67, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x73, 0x22, 0x38, 0x0a, 0x0a, 0x45, 0x6d, 0x6f, 0x6a,
+	0x69, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x6d, 0x6f, 0x6a, 0x69, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x65, 0x6d, 0x6f, 0x6a, 0x69, 0x12, 0x14, 0x0a, 0x05,
+	0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x63, 0x6f, 0x75,
+	0x6e, 0x74, 0x22, 0xa6, 0x02, 0x0a, 0x0e, 0x52, 0x65, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x64,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08, 0x64, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f,
+	0x6e, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x64, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f,
+	0x6e, 0x12, 0x14, 0x0a, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x05, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x12, 0x29, 0x0a, 0x10, 0x73, 0x75, 0x67, 0x67, 0x65,
+	0x73, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x73, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x01, 0x52, 0x0f, 0x73, 0x75, 0x67, 0x67, 0x65, 0x73, 0x74, 0x69, 0x6f, 0x6e, 0x53, 0x63, 0x6f,
+	0x72, 0x65, 0x12, 0x21, 0x0a, 0x0c, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67, 0x5f, 0x73, 0x63, 0x6f,
+	0x72, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0b, 0x72, 0x61, 0x74, 0x69, 0x6e, 0x67,
+	0x53, 0x63, 0x6f, 0x72, 0x65, 0x12, 0x2b, 0x0a, 0x11, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74,
+	0x5f, 0x73, 0x65, 0x6e, 0x74, 0x69, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x01,
+	0x52, 0x10, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x53, 0x65, 0x6e, 0x74, 0x69, 0x6d, 0x65,
+	0x6e, 0x74, 0x12, 0x26, 0x0a, 0x0f, 0x70, 0x6f, 0x73, 0x74, 0x5f, 0x76, 0x6f, 0x74, 0x65, 0x5f,
+	0x73, 0x63, 0x6f, 0x72, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0d, 0x70, 0x6f, 0x73,
+	0x74, 0x56, 0x6f, 0x74, 0x65, 0x53, 0x63, 0x6f, 0x72, 0x65, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x61,
+	0x6d, 0x70, 0x6c, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x05, 0x52,
+	0x0a, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x63,
+	0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x18, 0x08, 0x20, 0x01, 0x28, 0x01, 0x52,
+	0x0a, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x64, 0x65, 0x6e, 0x63, 0x65, 0x32, 0xb4, 0x03, 0x0a, 0x0f,
+	0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12,
+	0x6d, 0x0a, 0x0e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f,
+	0x6e, 0x12, 0x2c, 0x2e, 0x72, 0x61, 0x74, 0x65, 0x6d, 0x79, 0x6c, 0x69, 0x66, 0x65, 0x64, 0x65,
+	0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65,
+	0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x2d, 0x2e, 0x72, 0x61, 0x74, 0x65, 0x6d, 0x79, 0x6c, 0x69, 0x66, 0x65, 0x64, 0x65, 0x63, 0x69,
+	0x73, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x44, 0x65,
+	0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x59,
+	0x0a, 0x0b, 0x47, 0x65, 0x74, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x29, 0x2e,
+	0x72, 0x61, 0x74, 0x65, 0x6d, 0x79, 0x6c, 0x69, 0x66, 0x65, 0x64, 0x65, 0x63, 0x69, 0x73, 0x69,
+	0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f,
+	0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x72, 0x61, 0x74, 0x65, 0x6d,
+	0x79, 0x6c, 0x69, 0x66, 0x65, 0x64, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x2e, 0x76, 0x31,
+	0x2e, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x6d, 0x0a, 0x0e, 0x44, 0x65, 0x6c,
+	0x65, 0x74, 0x65, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x12, 0x2c, 0x2e, 0x72, 0x61,
+	0x74, 0x65, 0x6d, 0x79, 0x6c, 0x69, 0x66, 0x65, 0x64, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e,
+	0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69,
+	0x6f, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2d, 0x2e, 0x72, 0x61, 0x74, 0x65,
+	0x6d, 0x79, 0x6c, 0x69, 0x66, 0x65, 0x64, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x2e, 0x76,
+	0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x68, 0x0a, 0x10, 0x47, 0x65, 0x74, 0x44,
+	0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x2e, 0x2e, 0x72,
+	0x61, 0x74, 0x65, 0x6d, 0x79, 0x6c, 0x69, 0x66, 0x65, 0x64, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f,
+	0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e,
+	0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x72,
+	0x61, 0x74, 0x65, 0x6d, 0x79, 0x6c, 0x69, 0x66, 0x65, 0x64, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f,
+	0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x53, 0x74, 0x61,
+	0x74, 0x73, 0x42, 0x4c, 0x5a, 0x4a, 0x72, 0x61, 0x74, 0x65, 0x6d, 0x79, 0x6c, 0x69, 0x66, 0x65,
+	0x64, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61,
+	0x6c, 0x2f, 0x67, 0x65, 0x6e, 0x2f, 0x72, 0x61, 0x74, 0x65, 0x6d, 0x79, 0x6c, 0x69, 0x66, 0x65,
+	0x64, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x2f, 0x76, 0x31, 0x3b, 0x72, 0x61, 0x74, 0x65,
+	0x6d, 0x79, 0x6c, 0x69, 0x66, 0x65, 0x64, 0x65, 0x63, 0x69, 0x73, 0x69, 0x6f, 0x6e, 0x76, 0x31,
+	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+})
+
+var (
+	file_ratemylifedecision_v1_decisions_proto_rawDescOnce sync.Once
+	file_ratemylifedecision_v1_decisions_proto_rawDescData []byte
+)
+
+func file_ratemylifedecision_v1_decisions_proto_rawDescGZIP() []byte {
+	file_ratemylifedecision_v1_decisions_proto_rawDescOnce.Do(func() {
+		file_ratemylifedecision_v1_decisions_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_ratemylifedecision_v1_decisions_proto_rawDesc), len(file_ratemylifedecision_v1_decisions_proto_rawDesc)))
+	})
+	return file_ratemylifedecision_v1_decisions_proto_rawDescData
+}
+
+var file_ratemylifedecision_v1_decisions_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_ratemylifedecision_v1_decisions_proto_goTypes = []any{
+	(*CreateDecisionRequest)(nil),   // 0: ratemylifedecision.v1.CreateDecisionRequest
+	(*CreateDecisionResponse)(nil),  // 1: ratemylifedecision.v1.CreateDecisionResponse
+	(*GetDecisionRequest)(nil),      // 2: ratemylifedecision.v1.GetDecisionRequest
+	(*Decision)(nil),                // 3: ratemylifedecision.v1.Decision
+	(*DeleteDecisionRequest)(nil),   // 4: ratemylifedecision.v1.DeleteDecisionRequest
+	(*DeleteDecisionResponse)(nil),  // 5: ratemylifedecision.v1.DeleteDecisionResponse
+	(*GetDecisionStatsRequest)(nil), // 6: ratemylifedecision.v1.GetDecisionStatsRequest
+	(*DecisionStats)(nil),           // 7: ratemylifedecision.v1.DecisionStats
+	(*EmojiCount)(nil),              // 8: ratemylifedecision.v1.EmojiCount
+	(*Recommendation)(nil),          // 9: ratemylifedecision.v1.Recommendation
+}
+var file_ratemylifedecision_v1_decisions_proto_depIdxs = []int32{
+	8, // 0: ratemylifedecision.v1.DecisionStats.emoji_counts:type_name -> ratemylifedecision.v1.EmojiCount
+	9, // 1: ratemylifedecision.v1.DecisionStats.recommendation:type_name -> ratemylifedecision.v1.Recommendation
+	0, // 2: ratemylifedecision.v1.DecisionService.CreateDecision:input_type -> ratemylifedecision.v1.CreateDecisionRequest
+	2, // 3: ratemylifedecision.v1.DecisionService.GetDecision:input_type -> ratemylifedecision.v1.GetDecisionRequest
+	4, // 4: ratemylifedecision.v1.DecisionService.DeleteDecision:input_type -> ratemylifedecision.v1.DeleteDecisionRequest
+	6, // 5: ratemylifedecision.v1.DecisionService.GetDecisionStats:input_type -> ratemylifedecision.v1.GetDecisionStatsRequest
+	1, // 6: ratemylifedecision.v1.DecisionService.CreateDecision:output_type -> ratemylifedecision.v1.CreateDecisionResponse
+	3, // 7: ratemylifedecision.v1.DecisionService.GetDecision:output_type -> ratemylifedecision.v1.Decision
+	5, // 8: ratemylifedecision.v1.DecisionService.DeleteDecision:output_type -> ratemylifedecision.v1.DeleteDecisionResponse
+	7, // 9: ratemylifedecision.v1.DecisionService.GetDecisionStats:output_type -> ratemylifedecision.v1.DecisionStats
+	6, // [6:10] is the sub-list for method output_type
+	2, // [2:6] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_ratemylifedecision_v1_decisions_proto_init() }
+func file_ratemylifedecision_v1_decisions_proto_init() {
+	if File_ratemylifedecision_v1_decisions_proto != nil {
+		return
+	}
+	file_ratemylifedecision_v1_decisions_proto_msgTypes[0].OneofWrappers = []any{}
+	file_ratemylifedecision_v1_decisions_proto_msgTypes[3].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_ratemylifedecision_v1_decisions_proto_rawDesc), len(file_ratemylifedecision_v1_decisions_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_ratemylifedecision_v1_decisions_proto_goTypes,
+		DependencyIndexes: file_ratemylifedecision_v1_decisions_proto_depIdxs,
+		MessageInfos:      file_ratemylifedecision_v1_decisions_proto_msgTypes,
+	}.Build()
+	File_ratemylifedecision_v1_decisions_proto = out.File
+	file_ratemylifedecision_v1_decisions_proto_goTypes = nil
+	file_ratemylifedecision_v1_decisions_proto_depIdxs = nil
+}
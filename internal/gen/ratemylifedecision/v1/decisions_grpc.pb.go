@@ -0,0 +1,247 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: ratemylifedecision/v1/decisions.proto
+
+package ratemylifedecisionv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	DecisionService_CreateDecision_FullMethodName   = "/ratemylifedecision.v1.DecisionService/CreateDecision"
+	DecisionService_GetDecision_FullMethodName      = "/ratemylifedecision.v1.DecisionService/GetDecision"
+	DecisionService_DeleteDecision_FullMethodName   = "/ratemylifedecision.v1.DecisionService/DeleteDecision"
+	DecisionService_GetDecisionStats_FullMethodName = "/ratemylifedecision.v1.DecisionService/GetDecisionStats"
+)
+
+// DecisionServiceClient is the client API for DecisionService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// DecisionService is the internal API for services that need decisions
+// without going through the public JSON API. It is served on GRPC_PORT.
+// Calls must carry "authorization: Bearer <key>" metadata when
+// GRPC_API_KEYS is set. Reading a private decision also takes its code in
+// "x-access-code" metadata.
+type DecisionServiceClient interface {
+	CreateDecision(ctx context.Context, in *CreateDecisionRequest, opts ...grpc.CallOption) (*CreateDecisionResponse, error)
+	GetDecision(ctx context.Context, in *GetDecisionRequest, opts ...grpc.CallOption) (*Decision, error)
+	DeleteDecision(ctx context.Context, in *DeleteDecisionRequest, opts ...grpc.CallOption) (*DeleteDecisionResponse, error)
+	GetDecisionStats(ctx context.Context, in *GetDecisionStatsRequest, opts ...grpc.CallOption) (*DecisionStats, error)
+}
+
+type decisionServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewDecisionServiceClient(cc grpc.ClientConnInterface) DecisionServiceClient {
+	return &decisionServiceClient{cc}
+}
+
+func (c *decisionServiceClient) CreateDecision(ctx context.Context, in *CreateDecisionRequest, opts ...grpc.CallOption) (*CreateDecisionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CreateDecisionResponse)
+	err := c.cc.Invoke(ctx, DecisionService_CreateDecision_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *decisionServiceClient) GetDecision(ctx context.Context, in *GetDecisionRequest, opts ...grpc.CallOption) (*Decision, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Decision)
+	err := c.cc.Invoke(ctx, DecisionService_GetDecision_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *decisionServiceClient) DeleteDecision(ctx context.Context, in *DeleteDecisionRequest, opts ...grpc.CallOption) (*DeleteDecisionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteDecisionResponse)
+	err := c.cc.Invoke(ctx, DecisionService_DeleteDecision_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *decisionServiceClient) GetDecisionStats(ctx context.Context, in *GetDecisionStatsRequest, opts ...grpc.CallOption) (*DecisionStats, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DecisionStats)
+	err := c.cc.Invoke(ctx, DecisionService_GetDecisionStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// DecisionServiceServer is the server API for DecisionService service.
+// All implementations must embed UnimplementedDecisionServiceServer
+// for forward compatibility.
+//
+// DecisionService is the internal API for services that need decisions
+// without going through the public JSON API. It is served on GRPC_PORT.
+// Calls must carry "authorization: Bearer <key>" metadata when
+// GRPC_API_KEYS is set. Reading a private decision also takes its code in
+// "x-access-code" metadata.
+type DecisionServiceServer interface {
+	CreateDecision(context.Context, *CreateDecisionRequest) (*CreateDecisionResponse, error)
+	GetDecision(context.Context, *GetDecisionRequest) (*Decision, error)
+	DeleteDecision(context.Context, *DeleteDecisionRequest) (*DeleteDecisionResponse, error)
+	GetDecisionStats(context.Context, *GetDecisionStatsRequest) (*DecisionStats, error)
+	mustEmbedUnimplementedDecisionServiceServer()
+}
+
+// UnimplementedDecisionServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedDecisionServiceServer struct{}
+
+func (UnimplementedDecisionServiceServer) CreateDecision(context.Context, *CreateDecisionRequest) (*CreateDecisionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateDecision not implemented")
+}
+func (UnimplementedDecisionServiceServer) GetDecision(context.Context, *GetDecisionRequest) (*Decision, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDecision not implemented")
+}
+func (UnimplementedDecisionServiceServer) DeleteDecision(context.Context, *DeleteDecisionRequest) (*DeleteDecisionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteDecision not implemented")
+}
+func (UnimplementedDecisionServiceServer) GetDecisionStats(context.Context, *GetDecisionStatsRequest) (*DecisionStats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDecisionStats not implemented")
+}
+func (UnimplementedDecisionServiceServer) mustEmbedUnimplementedDecisionServiceServer() {}
+func (UnimplementedDecisionServiceServer) testEmbeddedByValue()                         {}
+
+// UnsafeDecisionServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to DecisionServiceServer will
+// result in compilation errors.
+type UnsafeDecisionServiceServer interface {
+	mustEmbedUnimplementedDecisionServiceServer()
+}
+
+func RegisterDecisionServiceServer(s grpc.ServiceRegistrar, srv DecisionServiceServer) {
+	// If the following call pancis, it indicates UnimplementedDecisionServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&DecisionService_ServiceDesc, srv)
+}
+
+func _DecisionService_CreateDecision_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateDecisionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DecisionServiceServer).CreateDecision(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DecisionService_CreateDecision_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DecisionServiceServer).CreateDecision(ctx, req.(*CreateDecisionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DecisionService_GetDecision_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDecisionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DecisionServiceServer).GetDecision(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DecisionService_GetDecision_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DecisionServiceServer).GetDecision(ctx, req.(*GetDecisionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DecisionService_DeleteDecision_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteDecisionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DecisionServiceServer).DeleteDecision(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DecisionService_DeleteDecision_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DecisionServiceServer).DeleteDecision(ctx, req.(*DeleteDecisionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _DecisionService_GetDecisionStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDecisionStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(DecisionServiceServer).GetDecisionStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: DecisionService_GetDecisionStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(DecisionServiceServer).GetDecisionStats(ctx, req.(*GetDecisionStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// DecisionService_ServiceDesc is the grpc.ServiceDesc for DecisionService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var DecisionService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "ratemylifedecision.v1.DecisionService",
+	HandlerType: (*DecisionServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "CreateDecision",
+			Handler:    _DecisionService_CreateDecision_Handler,
+		},
+		{
+			MethodName: "GetDecision",
+			Handler:    _DecisionService_GetDecision_Handler,
+		},
+		{
+			MethodName: "DeleteDecision",
+			Handler:    _DecisionService_DeleteDecision_Handler,
+		},
+		{
+			MethodName: "GetDecisionStats",
+			Handler:    _DecisionService_GetDecisionStats_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "ratemylifedecision/v1/decisions.proto",
+}
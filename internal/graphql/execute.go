@@ -0,0 +1,469 @@
+// Package graphql executes GraphQL queries and mutations against a schema
+// of Go resolver functions. It covers what a web client needs day to day:
+// operations, variables, aliases, fragments and @include/@skip. There is
+// no introspection and no input type checking; resolvers validate their own
+// arguments.
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Schema is the root of a GraphQL API. Mutation may be nil.
+type Schema struct {
+	Query    *Object
+	Mutation *Object
+}
+
+// Object is a GraphQL object type.
+type Object struct {
+	Name   string
+	Fields map[string]*Field
+}
+
+// Field resolves one field of an Object. A field whose Type is set returns a
+// source value (or a slice of them) for that object type; otherwise it
+// returns a scalar, which is encoded as JSON.
+type Field struct {
+	Type    *Object
+	Resolve Resolver
+}
+
+// Resolver computes a field from the parent value and the field's
+// arguments, with variables already substituted.
+type Resolver func(ctx context.Context, source any, args map[string]any) (any, error)
+
+// Request is the standard GraphQL-over-HTTP request body.
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+	// Extensions is accepted so clients that send it are not rejected, but
+	// nothing reads it.
+	Extensions map[string]any `json:"extensions"`
+}
+
+type Response struct {
+	Data   any     `json:"data,omitempty"`
+	Errors []Error `json:"errors,omitempty"`
+}
+
+type Error struct {
+	Message    string         `json:"message"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// CodedError is implemented by resolver errors that carry a machine-readable
+// code; it is reported as extensions.code.
+type CodedError interface {
+	error
+	ErrorCode() string
+}
+
+// Execute runs the selected operation of req. Problems with the document
+// itself are reported without data; a failing resolver nulls its field and
+// adds an error, and the rest of the result is still returned. Mutation
+// fields run one after another in document order.
+func (s *Schema) Execute(ctx context.Context, req Request) Response {
+	doc, err := Parse(req.Query)
+	if err != nil {
+		return Response{Errors: []Error{{Message: err.Error()}}}
+	}
+
+	op, err := selectOperation(doc, req.OperationName)
+	if err != nil {
+		return Response{Errors: []Error{{Message: err.Error()}}}
+	}
+	root := s.Query
+	if op.Kind == "mutation" {
+		root = s.Mutation
+	}
+	if root == nil {
+		return Response{Errors: []Error{{Message: fmt.Sprintf("schema does not support %ss", op.Kind)}}}
+	}
+
+	vars, err := coerceVariables(op, req.Variables)
+	if err != nil {
+		return Response{Errors: []Error{{Message: err.Error()}}}
+	}
+	if err := validate(doc, root, op.Selections); err != nil {
+		return Response{Errors: []Error{{Message: err.Error()}}}
+	}
+
+	e := &executor{doc: doc, vars: vars}
+	data := e.selectionSet(ctx, root, nil, op.Selections, nil)
+	return Response{Data: data, Errors: e.errors}
+}
+
+func selectOperation(doc *Document, name string) (*Operation, error) {
+	if name == "" {
+		if len(doc.Operations) > 1 {
+			return nil, fmt.Errorf("operationName is required when the document has several operations")
+		}
+		return doc.Operations[0], nil
+	}
+	for _, op := range doc.Operations {
+		if op.Name == name {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown operation %q", name)
+}
+
+func coerceVariables(op *Operation, given map[string]any) (map[string]any, error) {
+	vars := make(map[string]any, len(op.Variables))
+	for _, def := range op.Variables {
+		v, ok := given[def.Name]
+		if !ok {
+			v = def.Default
+		}
+		if v == nil && def.NonNull {
+			return nil, fmt.Errorf("variable $%s is required", def.Name)
+		}
+		vars[def.Name] = v
+	}
+	return vars, nil
+}
+
+// maxSelectionDepth bounds how deeply fields nest, counting the fields
+// fragments pull in. The deepest the API goes is four levels.
+const maxSelectionDepth = 10
+
+// validate checks every field against the schema before anything runs, so
+// a typo cannot leave a mutation half applied. Both branches of
+// @include/@skip are checked.
+func validate(doc *Document, root *Object, sels []Selection) error {
+	if err := checkFragmentCycles(doc); err != nil {
+		return err
+	}
+	v := &validator{doc: doc, depths: make(map[string]int)}
+	_, err := v.selections(root, sels)
+	return err
+}
+
+type validator struct {
+	doc *Document
+	// depths holds how deeply each fragment already checked nests, so a
+	// fragment spread many times is only walked once. A fragment is only
+	// checked against the type named by its type condition, so its name is
+	// key enough.
+	depths map[string]int
+}
+
+// selections checks sels against obj and returns how many levels of fields
+// they nest.
+func (v *validator) selections(obj *Object, sels []Selection) (int, error) {
+	depth := 0
+	for _, sel := range sels {
+		var (
+			d   int
+			err error
+		)
+		switch sel := sel.(type) {
+		case *FieldSelection:
+			d, err = v.field(obj, sel)
+		case *FragmentSpread:
+			frag, ok := v.doc.Fragments[sel.Name]
+			if !ok {
+				return 0, fmt.Errorf("unknown fragment %q", sel.Name)
+			}
+			if frag.TypeCondition != obj.Name {
+				continue
+			}
+			d, ok = v.depths[frag.Name]
+			if !ok {
+				d, err = v.selections(obj, frag.Selections)
+				v.depths[frag.Name] = d
+			}
+		case *InlineFragment:
+			if sel.TypeCondition != "" && sel.TypeCondition != obj.Name {
+				continue
+			}
+			d, err = v.selections(obj, sel.Selections)
+		}
+		if err != nil {
+			return 0, err
+		}
+		depth = max(depth, d)
+	}
+	if depth > maxSelectionDepth {
+		return 0, fmt.Errorf("selections are nested more than %d levels deep", maxSelectionDepth)
+	}
+	return depth, nil
+}
+
+func (v *validator) field(obj *Object, sel *FieldSelection) (int, error) {
+	if sel.Name == "__typename" {
+		if sel.Selections != nil {
+			return 0, fmt.Errorf("field \"__typename\" cannot have a selection set")
+		}
+		return 1, nil
+	}
+	field, ok := obj.Fields[sel.Name]
+	if !ok {
+		return 0, fmt.Errorf("cannot query field %q on type %q", sel.Name, obj.Name)
+	}
+	if field.Type == nil {
+		if sel.Selections != nil {
+			return 0, fmt.Errorf("field %q on type %q is a scalar and cannot have a selection set", sel.Name, obj.Name)
+		}
+		return 1, nil
+	}
+	if sel.Selections == nil {
+		return 0, fmt.Errorf("field %q on type %q needs a selection set", sel.Name, obj.Name)
+	}
+	d, err := v.selections(field.Type, sel.Selections)
+	return d + 1, err
+}
+
+// checkFragmentCycles refuses a fragment that spreads itself, directly or
+// through others, wherever it is spread.
+func checkFragmentCycles(doc *Document) error {
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int, len(doc.Fragments))
+	var visit func(name string) error
+	var walk func(sels []Selection) error
+	walk = func(sels []Selection) error {
+		for _, sel := range sels {
+			var err error
+			switch sel := sel.(type) {
+			case *FieldSelection:
+				err = walk(sel.Selections)
+			case *InlineFragment:
+				err = walk(sel.Selections)
+			case *FragmentSpread:
+				err = visit(sel.Name)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	visit = func(name string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("fragment %q spreads itself", name)
+		case done:
+			return nil
+		}
+		frag, ok := doc.Fragments[name]
+		if !ok {
+			// validate reports it if it is reached.
+			return nil
+		}
+		state[name] = visiting
+		if err := walk(frag.Selections); err != nil {
+			return err
+		}
+		state[name] = done
+		return nil
+	}
+	names := make([]string, 0, len(doc.Fragments))
+	for name := range doc.Fragments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := visit(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type executor struct {
+	doc    *Document
+	vars   map[string]any
+	errors []Error
+}
+
+func (e *executor) selectionSet(ctx context.Context, obj *Object, source any, sels []Selection, path []any) *orderedMap {
+	out := &orderedMap{values: make(map[string]any)}
+	for _, field := range e.collectFields(obj, sels, nil, make(map[string]bool)) {
+		key := field.ResponseKey()
+		fieldPath := append(append([]any(nil), path...), key)
+		out.set(key, e.field(ctx, obj, source, field, fieldPath))
+	}
+	return out
+}
+
+// collectFields flattens fragments and applies @include/@skip, merging the
+// sub-selections of fields requested more than once under the same key.
+// Each fragment is flattened once per selection set, however many times it
+// is spread.
+func (e *executor) collectFields(obj *Object, sels []Selection, fields []*FieldSelection, visited map[string]bool) []*FieldSelection {
+	for _, sel := range sels {
+		switch sel := sel.(type) {
+		case *FieldSelection:
+			if !e.included(sel.Directives) {
+				continue
+			}
+			merged := false
+			for i, existing := range fields {
+				if existing.ResponseKey() == sel.ResponseKey() {
+					copied := *existing
+					copied.Selections = append(append([]Selection(nil), existing.Selections...), sel.Selections...)
+					fields[i] = &copied
+					merged = true
+					break
+				}
+			}
+			if !merged {
+				fields = append(fields, sel)
+			}
+		case *FragmentSpread:
+			frag := e.doc.Fragments[sel.Name]
+			if visited[sel.Name] || !e.included(sel.Directives) || frag.TypeCondition != obj.Name {
+				continue
+			}
+			visited[sel.Name] = true
+			fields = e.collectFields(obj, frag.Selections, fields, visited)
+		case *InlineFragment:
+			if !e.included(sel.Directives) || (sel.TypeCondition != "" && sel.TypeCondition != obj.Name) {
+				continue
+			}
+			fields = e.collectFields(obj, sel.Selections, fields, visited)
+		}
+	}
+	return fields
+}
+
+func (e *executor) included(dirs []Directive) bool {
+	for _, dir := range dirs {
+		cond, _ := e.resolveValue(dir.Arguments["if"]).(bool)
+		switch dir.Name {
+		case "include":
+			if !cond {
+				return false
+			}
+		case "skip":
+			if cond {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (e *executor) field(ctx context.Context, obj *Object, source any, sel *FieldSelection, path []any) any {
+	if sel.Name == "__typename" {
+		return obj.Name
+	}
+
+	field := obj.Fields[sel.Name]
+	args := make(map[string]any, len(sel.Arguments))
+	for name, v := range sel.Arguments {
+		args[name] = e.resolveValue(v)
+	}
+
+	value, err := field.Resolve(ctx, source, args)
+	if err != nil {
+		gqlErr := Error{Message: err.Error(), Path: path}
+		var coded CodedError
+		if errors.As(err, &coded) {
+			gqlErr.Extensions = map[string]any{"code": coded.ErrorCode()}
+		}
+		e.errors = append(e.errors, gqlErr)
+		return nil
+	}
+	if field.Type == nil || isNil(value) {
+		return value
+	}
+
+	rv := reflect.ValueOf(value)
+	if rv.Kind() != reflect.Slice {
+		return e.selectionSet(ctx, field.Type, value, sel.Selections, path)
+	}
+	list := make([]any, rv.Len())
+	for i := range list {
+		item := rv.Index(i).Interface()
+		if isNil(item) {
+			continue
+		}
+		itemPath := append(append([]any(nil), path...), i)
+		list[i] = e.selectionSet(ctx, field.Type, item, sel.Selections, itemPath)
+	}
+	return list
+}
+
+func (e *executor) resolveValue(v any) any {
+	switch v := v.(type) {
+	case Variable:
+		return e.vars[string(v)]
+	case Enum:
+		return string(v)
+	case []any:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = e.resolveValue(item)
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for name, item := range v {
+			out[name] = e.resolveValue(item)
+		}
+		return out
+	}
+	return v
+}
+
+func isNil(v any) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		return rv.IsNil()
+	}
+	return false
+}
+
+// orderedMap keeps fields in the order they were requested, as the spec
+// requires of the response.
+type orderedMap struct {
+	keys   []string
+	values map[string]any
+}
+
+func (m *orderedMap) set(key string, value any) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+func (m *orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,275 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testDecision struct {
+	Slug    string
+	Ratings []int
+	Parent  *testDecision
+	Failing bool
+}
+
+type notFoundError struct{}
+
+func (notFoundError) Error() string     { return "decision not found" }
+func (notFoundError) ErrorCode() string { return "NOT_FOUND" }
+
+// testSchema is a small schema in the shape of the real one: decisions with
+// scalar fields, a list, a self-reference and a field that can fail.
+func testSchema() (*Schema, *[]string) {
+	var mutations []string
+	decision := &Object{Name: "Decision"}
+	rating := &Object{Name: "Rating", Fields: map[string]*Field{
+		"value": {Resolve: func(_ context.Context, source any, _ map[string]any) (any, error) {
+			return source.(int), nil
+		}},
+	}}
+	decision.Fields = map[string]*Field{
+		"slug": {Resolve: func(_ context.Context, source any, _ map[string]any) (any, error) {
+			return source.(*testDecision).Slug, nil
+		}},
+		"ratings": {Type: rating, Resolve: func(_ context.Context, source any, args map[string]any) (any, error) {
+			ratings := source.(*testDecision).Ratings
+			if limit, ok := args["limit"].(int); ok && limit < len(ratings) {
+				ratings = ratings[:limit]
+			}
+			return ratings, nil
+		}},
+		"parent": {Type: decision, Resolve: func(_ context.Context, source any, _ map[string]any) (any, error) {
+			return source.(*testDecision).Parent, nil
+		}},
+		"summary": {Resolve: func(_ context.Context, source any, _ map[string]any) (any, error) {
+			if source.(*testDecision).Failing {
+				return nil, errors.New("summary unavailable")
+			}
+			return "fine", nil
+		}},
+	}
+	root := &testDecision{Slug: "root"}
+	decisions := map[string]*testDecision{
+		"move-abroad": {Slug: "move-abroad", Ratings: []int{5, 3, 4}, Parent: root},
+		"broken":      {Slug: "broken", Failing: true},
+	}
+
+	query := &Object{Name: "Query", Fields: map[string]*Field{
+		"decision": {Type: decision, Resolve: func(_ context.Context, _ any, args map[string]any) (any, error) {
+			d, ok := decisions[fmt.Sprint(args["slug"])]
+			if !ok {
+				return nil, notFoundError{}
+			}
+			return d, nil
+		}},
+		"echo": {Resolve: func(_ context.Context, _ any, args map[string]any) (any, error) {
+			return args["value"], nil
+		}},
+	}}
+	record := func(name string) *Field {
+		return &Field{Resolve: func(_ context.Context, _ any, _ map[string]any) (any, error) {
+			mutations = append(mutations, name)
+			return len(mutations), nil
+		}}
+	}
+	mutation := &Object{Name: "Mutation", Fields: map[string]*Field{
+		"first":  record("first"),
+		"second": record("second"),
+	}}
+	return &Schema{Query: query, Mutation: mutation}, &mutations
+}
+
+func execute(t *testing.T, schema *Schema, req Request) string {
+	t.Helper()
+	body, err := json.Marshal(schema.Execute(context.Background(), req))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body)
+}
+
+func TestExecute(t *testing.T) {
+	schema, _ := testSchema()
+	tests := []struct {
+		name string
+		req  Request
+		want string
+	}{
+		{
+			name: "fields in request order with aliases",
+			req:  Request{Query: `{ decision(slug: "move-abroad") { __typename s: slug ratings(limit: 2) { value } parent { slug } } }`},
+			want: `{"data":{"decision":{"__typename":"Decision","s":"move-abroad","ratings":[{"value":5},{"value":3}],"parent":{"slug":"root"}}}}`,
+		},
+		{
+			name: "null object",
+			req:  Request{Query: `{ decision(slug: "broken") { parent { slug } } }`},
+			want: `{"data":{"decision":{"parent":null}}}`,
+		},
+		{
+			name: "variables and defaults",
+			req: Request{
+				Query:     `query ($slug: String!, $limit: Int = 1) { decision(slug: $slug) { ratings(limit: $limit) { value } } }`,
+				Variables: map[string]any{"slug": "move-abroad"},
+			},
+			want: `{"data":{"decision":{"ratings":[{"value":5}]}}}`,
+		},
+		{
+			name: "variables inside lists and objects, and enums",
+			req: Request{
+				Query:     `query ($v: Int) { echo(value: {list: [$v, RED], n: null}) }`,
+				Variables: map[string]any{"v": 7},
+			},
+			want: `{"data":{"echo":{"list":[7,"RED"],"n":null}}}`,
+		},
+		{
+			name: "fragments merge with fields of the same key",
+			req: Request{Query: `
+				{ decision(slug: "move-abroad") { slug ...Ratings ... on Decision { ratings(limit: 1) { value } } ... on Query { echo } } }
+				fragment Ratings on Decision { ratings(limit: 1) { __typename } }`},
+			want: `{"data":{"decision":{"slug":"move-abroad","ratings":[{"__typename":"Rating","value":5}]}}}`,
+		},
+		{
+			name: "include and skip",
+			req: Request{
+				Query:     `query ($yes: Boolean!) { decision(slug: "move-abroad") { a: slug @include(if: $yes) b: slug @skip(if: $yes) c: slug @include(if: false) ...F @skip(if: false) } } fragment F on Decision { d: slug }`,
+				Variables: map[string]any{"yes": true},
+			},
+			want: `{"data":{"decision":{"a":"move-abroad","d":"move-abroad"}}}`,
+		},
+		{
+			name: "selected operation",
+			req: Request{
+				Query:         `query A { echo(value: "a") } query B { echo(value: "b") }`,
+				OperationName: "B",
+			},
+			want: `{"data":{"echo":"b"}}`,
+		},
+		{
+			name: "failing resolver nulls its field only",
+			req:  Request{Query: `{ broken: decision(slug: "broken") { slug summary } missing: decision(slug: "nope") { slug } }`},
+			want: `{"data":{"broken":{"slug":"broken","summary":null},"missing":null},"errors":[` +
+				`{"message":"summary unavailable","path":["broken","summary"]},` +
+				`{"message":"decision not found","path":["missing"],"extensions":{"code":"NOT_FOUND"}}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := execute(t, schema, tt.req); got != tt.want {
+				t.Fatalf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteMutationsRunInOrder(t *testing.T) {
+	schema, mutations := testSchema()
+	got := execute(t, schema, Request{Query: `mutation { b: second a: first c: second }`})
+	if want := `{"data":{"b":1,"a":2,"c":3}}`; got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+	if want := "second,first,second"; strings.Join(*mutations, ",") != want {
+		t.Fatalf("ran %v, want %s", *mutations, want)
+	}
+}
+
+func TestExecuteRejectsInvalidDocuments(t *testing.T) {
+	schema, mutations := testSchema()
+	tests := []struct {
+		req  Request
+		want string
+	}{
+		{Request{Query: `{ echo(`}, "syntax error"},
+		{Request{Query: `query A { echo } query B { echo }`}, "operationName is required"},
+		{Request{Query: `{ echo }`, OperationName: "C"}, `unknown operation "C"`},
+		{Request{Query: `query ($s: String!) { decision(slug: $s) { slug } }`}, "variable $s is required"},
+		{Request{Query: `{ nope }`}, `cannot query field "nope" on type "Query"`},
+		{Request{Query: `{ decision { slug { x } } }`}, "is a scalar"},
+		{Request{Query: `{ decision }`}, "needs a selection set"},
+		{Request{Query: `{ __typename { x } }`}, "cannot have a selection set"},
+		{Request{Query: `{ decision { ...Missing } }`}, `unknown fragment "Missing"`},
+		// A typo in either branch of a directive is caught, whichever runs.
+		{Request{Query: `{ decision { slug @skip(if: true) nope @skip(if: true) } }`}, `cannot query field "nope"`},
+		// Nothing runs when any field is wrong.
+		{Request{Query: `mutation { first nope }`}, `cannot query field "nope" on type "Mutation"`},
+	}
+	for _, tt := range tests {
+		resp := schema.Execute(context.Background(), tt.req)
+		if resp.Data != nil || len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, tt.want) {
+			t.Errorf("%s: got %+v, want only an error mentioning %q", tt.req.Query, resp, tt.want)
+		}
+	}
+	if len(*mutations) != 0 {
+		t.Fatalf("ran %v despite the invalid document", *mutations)
+	}
+
+	schema.Mutation = nil
+	if resp := schema.Execute(context.Background(), Request{Query: `mutation { first }`}); len(resp.Errors) != 1 || resp.Errors[0].Message != "schema does not support mutations" {
+		t.Fatalf("got %+v", resp)
+	}
+}
+
+func TestExecuteRejectsFragmentCycles(t *testing.T) {
+	schema, _ := testSchema()
+	for _, query := range []string{
+		`{ decision { ...A } } fragment A on Decision { ...A }`,
+		`{ decision { ...A } } fragment A on Decision { parent { ...B } } fragment B on Decision { ... on Decision { ...A } }`,
+		// Cycles are refused even in fragments that are never spread.
+		`{ echo } fragment A on Decision { ...B } fragment B on Decision { ...A }`,
+	} {
+		resp := schema.Execute(context.Background(), Request{Query: query})
+		if len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, "spreads itself") {
+			t.Errorf("%s: got %+v, want a fragment cycle error", query, resp)
+		}
+	}
+}
+
+// Each fragment spreads the next one twice, so walking every spread visits
+// the last fragment 2^30 times.
+func TestExecuteFragmentFanOutIsLinear(t *testing.T) {
+	schema, _ := testSchema()
+	var query strings.Builder
+	query.WriteString(`{ decision(slug: "move-abroad") { ...F0 } }`)
+	const n = 30
+	for i := range n {
+		fmt.Fprintf(&query, "\nfragment F%d on Decision { slug ...F%d ...F%d }", i, i+1, i+1)
+	}
+	fmt.Fprintf(&query, "\nfragment F%d on Decision { slug }", n)
+
+	start := time.Now()
+	got := execute(t, schema, Request{Query: query.String()})
+	if want := `{"data":{"decision":{"slug":"move-abroad"}}}`; got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("took %v", elapsed)
+	}
+}
+
+func TestExecuteLimitsDepth(t *testing.T) {
+	schema, _ := testSchema()
+	nested := func(levels int) string {
+		return `{ decision(slug: "move-abroad") ` + strings.Repeat(`{ parent `, levels-2) + `{ slug }` + strings.Repeat(` }`, levels-2) + ` }`
+	}
+
+	if resp := schema.Execute(context.Background(), Request{Query: nested(maxSelectionDepth)}); len(resp.Errors) != 0 {
+		t.Fatalf("%d levels: %+v", maxSelectionDepth, resp.Errors)
+	}
+	resp := schema.Execute(context.Background(), Request{Query: nested(maxSelectionDepth + 1)})
+	if len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, "nested more than") {
+		t.Fatalf("%d levels: got %+v, want a depth error", maxSelectionDepth+1, resp)
+	}
+
+	// Depth through fragments counts too, including a memoized fragment
+	// spread again further down.
+	query := `{ decision(slug: "move-abroad") { ...P parent { parent { ...P } } } }
+		fragment P on Decision { parent { parent { parent { parent { parent { parent { parent { slug } } } } } } } }`
+	resp = schema.Execute(context.Background(), Request{Query: query})
+	if len(resp.Errors) != 1 || !strings.Contains(resp.Errors[0].Message, "nested more than") {
+		t.Fatalf("got %+v, want a depth error", resp)
+	}
+}
@@ -0,0 +1,657 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Document is a parsed request: its operations and the fragments they may
+// spread.
+type Document struct {
+	Operations []*Operation
+	Fragments  map[string]*Fragment
+}
+
+type Operation struct {
+	// Kind is "query" or "mutation".
+	Kind       string
+	Name       string
+	Variables  []VariableDefinition
+	Selections []Selection
+}
+
+type VariableDefinition struct {
+	Name    string
+	NonNull bool
+	Default any
+}
+
+type Fragment struct {
+	Name          string
+	TypeCondition string
+	Selections    []Selection
+}
+
+// Selection is a *FieldSelection, *FragmentSpread or *InlineFragment.
+type Selection interface {
+	selection()
+}
+
+type FieldSelection struct {
+	Alias      string
+	Name       string
+	Arguments  map[string]any
+	Directives []Directive
+	Selections []Selection
+}
+
+type FragmentSpread struct {
+	Name       string
+	Directives []Directive
+}
+
+type InlineFragment struct {
+	TypeCondition string
+	Directives    []Directive
+	Selections    []Selection
+}
+
+type Directive struct {
+	Name      string
+	Arguments map[string]any
+}
+
+func (*FieldSelection) selection() {}
+func (*FragmentSpread) selection() {}
+func (*InlineFragment) selection() {}
+
+// ResponseKey is the name the field is returned under.
+func (f *FieldSelection) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+// Literal values in a document are Go values: string, int, float64, bool,
+// nil, []any and map[string]any, plus these two.
+type (
+	Variable string
+	Enum     string
+)
+
+// Parse reads an executable document. Type system definitions and
+// subscriptions are not supported.
+func Parse(source string) (*Document, error) {
+	p := &parser{lex: lexer{src: source}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	doc := &Document{Fragments: make(map[string]*Fragment)}
+	for p.tok.kind != tokEOF {
+		switch {
+		case p.tok.is(tokPunct, "{"):
+			sels, err := p.selectionSet()
+			if err != nil {
+				return nil, err
+			}
+			doc.Operations = append(doc.Operations, &Operation{Kind: "query", Selections: sels})
+		case p.tok.is(tokName, "query"), p.tok.is(tokName, "mutation"):
+			op, err := p.operation()
+			if err != nil {
+				return nil, err
+			}
+			doc.Operations = append(doc.Operations, op)
+		case p.tok.is(tokName, "fragment"):
+			frag, err := p.fragment()
+			if err != nil {
+				return nil, err
+			}
+			if _, dup := doc.Fragments[frag.Name]; dup {
+				return nil, fmt.Errorf("fragment %q is defined more than once", frag.Name)
+			}
+			doc.Fragments[frag.Name] = frag
+		default:
+			return nil, p.unexpected()
+		}
+	}
+	if len(doc.Operations) == 0 {
+		return nil, fmt.Errorf("document has no operations")
+	}
+	return doc, nil
+}
+
+type parser struct {
+	lex lexer
+	tok token
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) unexpected() error {
+	if p.tok.kind == tokEOF {
+		return fmt.Errorf("syntax error: unexpected end of document")
+	}
+	return fmt.Errorf("syntax error at offset %d: unexpected %q", p.tok.pos, p.tok.text)
+}
+
+func (p *parser) expectPunct(text string) error {
+	if !p.tok.is(tokPunct, text) {
+		return p.unexpected()
+	}
+	return p.advance()
+}
+
+func (p *parser) name() (string, error) {
+	if p.tok.kind != tokName {
+		return "", p.unexpected()
+	}
+	name := p.tok.text
+	return name, p.advance()
+}
+
+func (p *parser) operation() (*Operation, error) {
+	op := &Operation{Kind: p.tok.text}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokName {
+		op.Name = p.tok.text
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	if p.tok.is(tokPunct, "(") {
+		vars, err := p.variableDefinitions()
+		if err != nil {
+			return nil, err
+		}
+		op.Variables = vars
+	}
+	if _, err := p.directives(); err != nil {
+		return nil, err
+	}
+	sels, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	op.Selections = sels
+	return op, nil
+}
+
+func (p *parser) variableDefinitions() ([]VariableDefinition, error) {
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	var defs []VariableDefinition
+	for !p.tok.is(tokPunct, ")") {
+		if err := p.expectPunct("$"); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectPunct(":"); err != nil {
+			return nil, err
+		}
+		nonNull, err := p.typeRef()
+		if err != nil {
+			return nil, err
+		}
+		def := VariableDefinition{Name: name, NonNull: nonNull}
+		if p.tok.is(tokPunct, "=") {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			if def.Default, err = p.value(true); err != nil {
+				return nil, err
+			}
+		}
+		defs = append(defs, def)
+	}
+	return defs, p.advance()
+}
+
+// typeRef skips over a type such as [String!]! and reports whether the
+// outermost type is non-null. Values are coerced by the resolvers, not here.
+func (p *parser) typeRef() (bool, error) {
+	if p.tok.is(tokPunct, "[") {
+		if err := p.advance(); err != nil {
+			return false, err
+		}
+		if _, err := p.typeRef(); err != nil {
+			return false, err
+		}
+		if err := p.expectPunct("]"); err != nil {
+			return false, err
+		}
+	} else if _, err := p.name(); err != nil {
+		return false, err
+	}
+	if p.tok.is(tokPunct, "!") {
+		return true, p.advance()
+	}
+	return false, nil
+}
+
+func (p *parser) fragment() (*Fragment, error) {
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if name == "on" {
+		return nil, fmt.Errorf("syntax error: fragment needs a name")
+	}
+	if !p.tok.is(tokName, "on") {
+		return nil, p.unexpected()
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	typeCondition, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.directives(); err != nil {
+		return nil, err
+	}
+	sels, err := p.selectionSet()
+	if err != nil {
+		return nil, err
+	}
+	return &Fragment{Name: name, TypeCondition: typeCondition, Selections: sels}, nil
+}
+
+func (p *parser) selectionSet() ([]Selection, error) {
+	if err := p.expectPunct("{"); err != nil {
+		return nil, err
+	}
+	var sels []Selection
+	for !p.tok.is(tokPunct, "}") {
+		sel, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		sels = append(sels, sel)
+	}
+	if len(sels) == 0 {
+		return nil, fmt.Errorf("syntax error: empty selection set")
+	}
+	return sels, p.advance()
+}
+
+func (p *parser) selection() (Selection, error) {
+	if p.tok.is(tokPunct, "...") {
+		return p.fragmentSelection()
+	}
+
+	name, err := p.name()
+	if err != nil {
+		return nil, err
+	}
+	field := &FieldSelection{Name: name}
+	if p.tok.is(tokPunct, ":") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		field.Alias = name
+		if field.Name, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	if p.tok.is(tokPunct, "(") {
+		if field.Arguments, err = p.arguments(); err != nil {
+			return nil, err
+		}
+	}
+	if field.Directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	if p.tok.is(tokPunct, "{") {
+		if field.Selections, err = p.selectionSet(); err != nil {
+			return nil, err
+		}
+	}
+	return field, nil
+}
+
+func (p *parser) fragmentSelection() (Selection, error) {
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if p.tok.kind == tokName && p.tok.text != "on" {
+		spread := &FragmentSpread{Name: p.tok.text}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		var err error
+		spread.Directives, err = p.directives()
+		return spread, err
+	}
+
+	inline := &InlineFragment{}
+	if p.tok.is(tokName, "on") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		var err error
+		if inline.TypeCondition, err = p.name(); err != nil {
+			return nil, err
+		}
+	}
+	var err error
+	if inline.Directives, err = p.directives(); err != nil {
+		return nil, err
+	}
+	inline.Selections, err = p.selectionSet()
+	return inline, err
+}
+
+func (p *parser) arguments() (map[string]any, error) {
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	args := make(map[string]any)
+	for !p.tok.is(tokPunct, ")") {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expectPunct(":"); err != nil {
+			return nil, err
+		}
+		if args[name], err = p.value(false); err != nil {
+			return nil, err
+		}
+	}
+	return args, p.advance()
+}
+
+func (p *parser) directives() ([]Directive, error) {
+	var dirs []Directive
+	for p.tok.is(tokPunct, "@") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		dir := Directive{Name: name}
+		if p.tok.is(tokPunct, "(") {
+			if dir.Arguments, err = p.arguments(); err != nil {
+				return nil, err
+			}
+		}
+		dirs = append(dirs, dir)
+	}
+	return dirs, nil
+}
+
+// value parses a literal. Variables are not allowed in constant positions
+// such as variable defaults.
+func (p *parser) value(constant bool) (any, error) {
+	tok := p.tok
+	switch {
+	case tok.is(tokPunct, "$") && !constant:
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name, err := p.name()
+		return Variable(name), err
+	case tok.is(tokPunct, "["):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		list := []any{}
+		for !p.tok.is(tokPunct, "]") {
+			v, err := p.value(constant)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, p.advance()
+	case tok.is(tokPunct, "{"):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		obj := map[string]any{}
+		for !p.tok.is(tokPunct, "}") {
+			name, err := p.name()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expectPunct(":"); err != nil {
+				return nil, err
+			}
+			if obj[name], err = p.value(constant); err != nil {
+				return nil, err
+			}
+		}
+		return obj, p.advance()
+	case tok.kind == tokInt:
+		n, err := strconv.Atoi(tok.text)
+		if err != nil {
+			return nil, fmt.Errorf("integer %s is out of range", tok.text)
+		}
+		return n, p.advance()
+	case tok.kind == tokFloat:
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s", tok.text)
+		}
+		return f, p.advance()
+	case tok.kind == tokString:
+		return tok.text, p.advance()
+	case tok.kind == tokName:
+		var v any
+		switch tok.text {
+		case "true":
+			v = true
+		case "false":
+			v = false
+		case "null":
+			v = nil
+		default:
+			v = Enum(tok.text)
+		}
+		return v, p.advance()
+	}
+	return nil, p.unexpected()
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokPunct
+	tokName
+	tokInt
+	tokFloat
+	tokString
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) is(kind tokenKind, text string) bool {
+	return t.kind == kind && t.text == text
+}
+
+type lexer struct {
+	src string
+	pos int
+}
+
+func (l *lexer) next() (token, error) {
+	l.skipIgnored()
+	if l.pos >= len(l.src) {
+		return token{kind: tokEOF, pos: l.pos}, nil
+	}
+
+	start := l.pos
+	c := l.src[l.pos]
+	switch {
+	case strings.HasPrefix(l.src[l.pos:], "..."):
+		l.pos += 3
+		return token{kind: tokPunct, text: "...", pos: start}, nil
+	case strings.IndexByte("!$():=@[]{}|&", c) >= 0:
+		l.pos++
+		return token{kind: tokPunct, text: string(c), pos: start}, nil
+	case c == '_' || isLetter(c):
+		for l.pos < len(l.src) && (l.src[l.pos] == '_' || isLetter(l.src[l.pos]) || isDigit(l.src[l.pos])) {
+			l.pos++
+		}
+		return token{kind: tokName, text: l.src[start:l.pos], pos: start}, nil
+	case c == '-' || isDigit(c):
+		return l.number()
+	case c == '"':
+		return l.string()
+	}
+	return token{}, fmt.Errorf("syntax error at offset %d: unexpected character %q", start, c)
+}
+
+func (l *lexer) skipIgnored() {
+	for l.pos < len(l.src) {
+		switch c := l.src[l.pos]; c {
+		case ' ', '\t', '\n', '\r', ',':
+			l.pos++
+		case '#':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' && l.src[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			if strings.HasPrefix(l.src[l.pos:], "\ufeff") {
+				l.pos += len("\ufeff")
+				continue
+			}
+			return
+		}
+	}
+}
+
+func (l *lexer) number() (token, error) {
+	start := l.pos
+	kind := tokInt
+	if l.src[l.pos] == '-' {
+		l.pos++
+	}
+	digits := func() int {
+		n := 0
+		for l.pos < len(l.src) && isDigit(l.src[l.pos]) {
+			l.pos++
+			n++
+		}
+		return n
+	}
+	if digits() == 0 {
+		return token{}, fmt.Errorf("syntax error at offset %d: invalid number", start)
+	}
+	if l.pos < len(l.src) && l.src[l.pos] == '.' {
+		kind = tokFloat
+		l.pos++
+		if digits() == 0 {
+			return token{}, fmt.Errorf("syntax error at offset %d: invalid number", start)
+		}
+	}
+	if l.pos < len(l.src) && (l.src[l.pos] == 'e' || l.src[l.pos] == 'E') {
+		kind = tokFloat
+		l.pos++
+		if l.pos < len(l.src) && (l.src[l.pos] == '+' || l.src[l.pos] == '-') {
+			l.pos++
+		}
+		if digits() == 0 {
+			return token{}, fmt.Errorf("syntax error at offset %d: invalid number", start)
+		}
+	}
+	return token{kind: kind, text: l.src[start:l.pos], pos: start}, nil
+}
+
+func (l *lexer) string() (token, error) {
+	start := l.pos
+	if strings.HasPrefix(l.src[l.pos:], `"""`) {
+		end := strings.Index(l.src[l.pos+3:], `"""`)
+		if end < 0 {
+			return token{}, fmt.Errorf("syntax error at offset %d: unterminated string", start)
+		}
+		text := l.src[l.pos+3 : l.pos+3+end]
+		l.pos += 3 + end + 3
+		return token{kind: tokString, text: strings.TrimSpace(text), pos: start}, nil
+	}
+
+	l.pos++
+	var b strings.Builder
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '"':
+			l.pos++
+			return token{kind: tokString, text: b.String(), pos: start}, nil
+		case c == '\n' || c == '\r':
+			return token{}, fmt.Errorf("syntax error at offset %d: unterminated string", start)
+		case c == '\\':
+			if l.pos+1 >= len(l.src) {
+				return token{}, fmt.Errorf("syntax error at offset %d: unterminated string", start)
+			}
+			esc := l.src[l.pos+1]
+			l.pos += 2
+			switch esc {
+			case '"', '\\', '/':
+				b.WriteByte(esc)
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if l.pos+4 > len(l.src) {
+					return token{}, fmt.Errorf("syntax error at offset %d: invalid unicode escape", l.pos)
+				}
+				n, err := strconv.ParseUint(l.src[l.pos:l.pos+4], 16, 32)
+				if err != nil {
+					return token{}, fmt.Errorf("syntax error at offset %d: invalid unicode escape", l.pos)
+				}
+				b.WriteRune(rune(n))
+				l.pos += 4
+			default:
+				return token{}, fmt.Errorf("syntax error at offset %d: invalid escape \\%c", l.pos-1, esc)
+			}
+		default:
+			r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+			b.WriteRune(r)
+			l.pos += size
+		}
+	}
+	return token{}, fmt.Errorf("syntax error at offset %d: unterminated string", start)
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
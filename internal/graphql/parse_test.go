@@ -0,0 +1,160 @@
+package graphql
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseOperation(t *testing.T) {
+	doc, err := Parse("\ufeff" + `
+		query Decision($slug: String!, $limit: Int = 10, $tags: [String]) @cached {
+			d: decision(slug: $slug) {
+				title,
+				responses(limit: $limit, order: NEWEST, filter: {rating: 5, ids: [1, 2.5, "x", null, true]}) @include(if: true) {
+					... on Response { comment }
+					...ResponseFields
+				}
+			}
+		}
+
+		fragment ResponseFields on Response { rating }
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(doc.Operations) != 1 {
+		t.Fatalf("got %d operations, want 1", len(doc.Operations))
+	}
+	op := doc.Operations[0]
+	if op.Kind != "query" || op.Name != "Decision" {
+		t.Fatalf("operation = %s %q", op.Kind, op.Name)
+	}
+	wantVars := []VariableDefinition{
+		{Name: "slug", NonNull: true},
+		{Name: "limit", Default: 10},
+		{Name: "tags"},
+	}
+	if !reflect.DeepEqual(op.Variables, wantVars) {
+		t.Fatalf("variables = %+v, want %+v", op.Variables, wantVars)
+	}
+
+	d := op.Selections[0].(*FieldSelection)
+	if d.Alias != "d" || d.Name != "decision" || d.ResponseKey() != "d" {
+		t.Fatalf("field = %+v", d)
+	}
+	if d.Arguments["slug"] != Variable("slug") {
+		t.Fatalf("slug argument = %#v", d.Arguments["slug"])
+	}
+	if len(d.Selections) != 2 {
+		t.Fatalf("got %d selections under decision, want 2", len(d.Selections))
+	}
+
+	responses := d.Selections[1].(*FieldSelection)
+	wantArgs := map[string]any{
+		"limit":  Variable("limit"),
+		"order":  Enum("NEWEST"),
+		"filter": map[string]any{"rating": 5, "ids": []any{1, 2.5, "x", nil, true}},
+	}
+	if !reflect.DeepEqual(responses.Arguments, wantArgs) {
+		t.Fatalf("arguments = %#v, want %#v", responses.Arguments, wantArgs)
+	}
+	wantDirs := []Directive{{Name: "include", Arguments: map[string]any{"if": true}}}
+	if !reflect.DeepEqual(responses.Directives, wantDirs) {
+		t.Fatalf("directives = %+v, want %+v", responses.Directives, wantDirs)
+	}
+	if inline, ok := responses.Selections[0].(*InlineFragment); !ok || inline.TypeCondition != "Response" {
+		t.Fatalf("first selection = %#v, want an inline fragment on Response", responses.Selections[0])
+	}
+	if spread, ok := responses.Selections[1].(*FragmentSpread); !ok || spread.Name != "ResponseFields" {
+		t.Fatalf("second selection = %#v, want a spread of ResponseFields", responses.Selections[1])
+	}
+
+	frag := doc.Fragments["ResponseFields"]
+	if frag == nil || frag.TypeCondition != "Response" || len(frag.Selections) != 1 {
+		t.Fatalf("fragment = %+v", frag)
+	}
+}
+
+func TestParseShorthandAndSeveralOperations(t *testing.T) {
+	doc, err := Parse(`{ a } mutation M { b } # trailing comment`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Operations) != 2 {
+		t.Fatalf("got %d operations, want 2", len(doc.Operations))
+	}
+	if op := doc.Operations[0]; op.Kind != "query" || op.Name != "" {
+		t.Fatalf("first operation = %s %q, want an anonymous query", op.Kind, op.Name)
+	}
+	if op := doc.Operations[1]; op.Kind != "mutation" || op.Name != "M" {
+		t.Fatalf("second operation = %s %q, want mutation M", op.Kind, op.Name)
+	}
+}
+
+func TestParseStrings(t *testing.T) {
+	tests := []struct {
+		literal string
+		want    string
+	}{
+		{`"plain"`, "plain"},
+		{`"quote \" slash \\ \/ tab \t newline \n"`, "quote \" slash \\ / tab \t newline \n"},
+		{`"été"`, "été"},
+		{`"naïve ✓"`, "naïve ✓"},
+		{`"""
+			block "quoted" \n kept
+		"""`, `block "quoted" \n kept`},
+	}
+	for _, tt := range tests {
+		doc, err := Parse(`{ f(s: ` + tt.literal + `) }`)
+		if err != nil {
+			t.Errorf("%s: %v", tt.literal, err)
+			continue
+		}
+		got := doc.Operations[0].Selections[0].(*FieldSelection).Arguments["s"]
+		if got != tt.want {
+			t.Errorf("%s = %q, want %q", tt.literal, got, tt.want)
+		}
+	}
+}
+
+func TestParseNumbers(t *testing.T) {
+	doc, err := Parse(`{ f(a: 0, b: -12, c: 1.5e3, d: -0.25) }`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := doc.Operations[0].Selections[0].(*FieldSelection).Arguments
+	want := map[string]any{"a": 0, "b": -12, "c": 1500.0, "d": -0.25}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("arguments = %#v, want %#v", got, want)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{``, "document has no operations"},
+		{`fragment F on Query { a }`, "document has no operations"},
+		{`{ }`, "empty selection set"},
+		{`{ a `, "unexpected end of document"},
+		{`{ a(x: "open) }`, "unterminated string"},
+		{`{ a(x: "line
+break") }`, "unterminated string"},
+		{`{ a(x: "\q") }`, "invalid escape"},
+		{`{ a(x: "\u12") }`, "invalid unicode escape"},
+		{`{ a(x: 99999999999999999999) }`, "out of range"},
+		{`{ a } fragment F on Query { a } fragment F on Query { b }`, `fragment "F" is defined more than once`},
+		{`{ a } fragment on on Query { a }`, "fragment needs a name"},
+		{`subscription { a }`, "unexpected"},
+		{`query ($x: Int = $y) { a }`, "unexpected"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.source)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Parse(%q) error = %v, want it to mention %q", tt.source, err, tt.want)
+		}
+	}
+}
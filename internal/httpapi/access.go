@@ -0,0 +1,123 @@
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"strings"
+
+	"ratemylifedecision/internal/store"
+)
+
+const (
+	visibilityPublic   = "public"
+	visibilityUnlisted = "unlisted"
+	visibilityPrivate  = "private"
+
+	accessCodeMinLength = 6
+	accessCodeMaxLength = 64
+	// generatedAccessCodeLength is the length of the code made up for a
+	// private decision created without one: short enough to read out to a
+	// friend.
+	generatedAccessCodeLength = 8
+
+	errorCodeAccessCodeRequired = "access_code_required"
+	errorCodeAccessCodeInvalid  = "access_code_invalid"
+	errorCodeDecisionPrivate    = "decision_private"
+)
+
+var (
+	errAccessCodeRequired = errors.New("this decision is private; an access code is required")
+	errAccessCodeInvalid  = errors.New("invalid access code")
+)
+
+func normalizeVisibility(raw *string) (string, error) {
+	if raw == nil {
+		return visibilityPublic, nil
+	}
+	switch visibility := strings.ToLower(strings.TrimSpace(*raw)); visibility {
+	case "":
+		return visibilityPublic, nil
+	case visibilityPublic, visibilityUnlisted, visibilityPrivate:
+		return visibility, nil
+	default:
+		return "", errors.New("visibility must be public, unlisted, or private")
+	}
+}
+
+// normalizeAccessCode returns the access code for a decision with the
+// given visibility, making one up for a private decision created without
+// one. Only private decisions have a code.
+func normalizeAccessCode(raw *string, visibility string) (string, error) {
+	code := ""
+	if raw != nil {
+		code = strings.TrimSpace(*raw)
+	}
+	if visibility != visibilityPrivate {
+		if code != "" {
+			return "", errors.New("access_code is only used by private decisions")
+		}
+		return "", nil
+	}
+	if code == "" {
+		return randSuffix(generatedAccessCodeLength), nil
+	}
+	if n := len([]rune(code)); n < accessCodeMinLength || n > accessCodeMaxLength {
+		return "", fmt.Errorf("access_code must be between %d and %d characters", accessCodeMinLength, accessCodeMaxLength)
+	}
+	return code, nil
+}
+
+// requestAccessCode reads the access code from the X-Access-Code header,
+// or the access_code query parameter for links and clients that cannot
+// set headers, like EventSource.
+func requestAccessCode(r *nethttp.Request) string {
+	if code := strings.TrimSpace(r.Header.Get("X-Access-Code")); code != "" {
+		return code
+	}
+	return strings.TrimSpace(r.URL.Query().Get("access_code"))
+}
+
+// decisionAccessError reports why a request may not see decision, or nil
+// when it may. Anything but a private decision is open to anyone with the
+// link; a private one needs its access code or the creator token.
+func decisionAccessError(decision store.Decision, accessCode, creatorToken string) error {
+	if decision.Visibility != visibilityPrivate {
+		return nil
+	}
+	if creatorToken != "" && decision.CreatorTokenHash != nil && tokenMatchesHash(creatorToken, *decision.CreatorTokenHash) {
+		return nil
+	}
+	if accessCode == "" {
+		return errAccessCodeRequired
+	}
+	if decision.AccessCodeHash == nil || !tokenMatchesHash(accessCode, *decision.AccessCodeHash) {
+		return errAccessCodeInvalid
+	}
+	return nil
+}
+
+// requestAccessError is decisionAccessError for the access code and
+// creator token r carries. A nil r carries neither.
+func requestAccessError(r *nethttp.Request, decision store.Decision) error {
+	if r == nil {
+		return decisionAccessError(decision, "", "")
+	}
+	return decisionAccessError(decision, requestAccessCode(r), strings.TrimSpace(r.Header.Get("X-Creator-Token")))
+}
+
+// requireDecisionAccess checks the request's access code against decision.
+// It writes the error response itself and reports whether the caller may
+// proceed.
+func requireDecisionAccess(w nethttp.ResponseWriter, r *nethttp.Request, decision store.Decision) bool {
+	err := requestAccessError(r, decision)
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, errAccessCodeRequired):
+		writeProblem(w, nethttp.StatusUnauthorized, errorCodeAccessCodeRequired, err.Error())
+	default:
+		writeProblem(w, nethttp.StatusForbidden, errorCodeAccessCodeInvalid, err.Error())
+	}
+	return false
+}
@@ -0,0 +1,371 @@
+package httpapi
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	nethttp "net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/auth"
+	"ratemylifedecision/internal/database"
+	"ratemylifedecision/internal/store"
+)
+
+const (
+	errorCodeAccountTokenRequired  = "account_token_required"
+	errorCodeProviderNotConfigured = "provider_not_configured"
+	errorCodeLinkedElsewhere       = "linked_to_another_account"
+)
+
+const (
+	// oauthLoginTTL is how long a sign-in can take at the provider.
+	oauthLoginTTL    = 10 * time.Minute
+	oauthNonceCookie = "oauth_nonce"
+)
+
+type accountView struct {
+	AccountID  string                `json:"account_id"`
+	Identities []accountIdentityView `json:"identities"`
+	// Viewers come with fresh viewer tokens, so a new device can carry on
+	// as any of them.
+	Viewers   []createViewerResponse `json:"viewers"`
+	Decisions []accountDecisionView  `json:"decisions"`
+}
+
+type accountIdentityView struct {
+	Provider string  `json:"provider"`
+	Email    *string `json:"email"`
+}
+
+type accountDecisionView struct {
+	Slug      string    `json:"slug"`
+	Title     string    `json:"title"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type linkViewerRequest struct {
+	ViewerToken string `json:"viewer_token"`
+}
+
+// loadOAuthRedirectBaseURL reads OAUTH_REDIRECT_BASE_URL, the public URL of
+// this API that providers send the browser back to.
+func loadOAuthRedirectBaseURL() string {
+	return strings.TrimRight(strings.TrimSpace(os.Getenv("OAUTH_REDIRECT_BASE_URL")), "/")
+}
+
+func (s *Server) oauthRedirectURI(provider string) string {
+	return s.oauthRedirectBaseURL + "/v1/auth/" + provider + "/callback"
+}
+
+func (s *Server) oauthProvider(w nethttp.ResponseWriter, r *nethttp.Request) (*auth.Provider, bool) {
+	provider, ok := s.oauthProviders[chi.URLParam(r, "provider")]
+	if !ok || s.oauthRedirectBaseURL == "" {
+		writeProblem(w, nethttp.StatusNotFound, errorCodeProviderNotConfigured, "sign-in with this provider is not available")
+		return nil, false
+	}
+	return provider, true
+}
+
+// handleOAuthLogin starts a sign-in: it sends the browser to the provider
+// with a signed state and keeps the state's nonce in a cookie, so only this
+// browser can finish it.
+func (s *Server) handleOAuthLogin(w nethttp.ResponseWriter, r *nethttp.Request) {
+	provider, ok := s.oauthProvider(w, r)
+	if !ok {
+		return
+	}
+	buf := make([]byte, 18)
+	if _, err := rand.Read(buf); err != nil {
+		s.writeServerError(w, err, "failed to start sign-in")
+		return
+	}
+	nonce := base64.RawURLEncoding.EncodeToString(buf)
+	nethttp.SetCookie(w, &nethttp.Cookie{
+		Name:     oauthNonceCookie,
+		Value:    nonce,
+		Path:     "/",
+		MaxAge:   int(oauthLoginTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(s.oauthRedirectBaseURL, "https://"),
+		SameSite: nethttp.SameSiteLaxMode,
+	})
+	state := s.authTokens.SignState(provider.Name, nonce, oauthLoginTTL)
+	nethttp.Redirect(w, r, provider.AuthCodeURL(state, s.oauthRedirectURI(provider.Name)), nethttp.StatusFound)
+}
+
+// handleOAuthCallback finishes a sign-in and sends the browser on to the
+// frontend's /auth/complete with the account token in the fragment, which
+// never reaches a server log. Failures go there too, as #error=.
+func (s *Server) handleOAuthCallback(w nethttp.ResponseWriter, r *nethttp.Request) {
+	provider, ok := s.oauthProvider(w, r)
+	if !ok {
+		return
+	}
+	nethttp.SetCookie(w, &nethttp.Cookie{Name: oauthNonceCookie, Path: "/", MaxAge: -1})
+	finish := func(fragment url.Values) {
+		nethttp.Redirect(w, r, s.frontendBaseURL+"/auth/complete#"+fragment.Encode(), nethttp.StatusFound)
+	}
+
+	query := r.URL.Query()
+	if providerError := query.Get("error"); providerError != "" {
+		finish(url.Values{"error": {"access_denied"}})
+		return
+	}
+	nonce, err := s.authTokens.VerifyState(query.Get("state"), provider.Name)
+	cookie, cookieErr := r.Cookie(oauthNonceCookie)
+	if err != nil || cookieErr != nil || cookie.Value != nonce {
+		finish(url.Values{"error": {"invalid_state"}})
+		return
+	}
+
+	identity, err := provider.Exchange(r.Context(), query.Get("code"), s.oauthRedirectURI(provider.Name))
+	if err != nil {
+		if errors.Is(err, auth.ErrRejected) {
+			finish(url.Values{"error": {"access_denied"}})
+			return
+		}
+		slog.Error("oauth exchange failed", "provider", provider.Name, "error", err)
+		finish(url.Values{"error": {"server_error"}})
+		return
+	}
+	accountID, err := s.findOrCreateAccount(r.Context(), identity)
+	if err != nil {
+		slog.Error("failed to sign in", "provider", provider.Name, "error", err)
+		finish(url.Values{"error": {"server_error"}})
+		return
+	}
+	finish(url.Values{"account_token": {s.authTokens.SignAccount(accountID)}})
+}
+
+// findOrCreateAccount returns the account signed in as identity, making
+// one the first time. The email is refreshed on every sign-in.
+func (s *Server) findOrCreateAccount(ctx context.Context, identity auth.Identity) (uuid.UUID, error) {
+	ctx, cancel := withBudget(ctx, writeQueryBudget)
+	defer cancel()
+
+	var email *string
+	if identity.Email != "" {
+		email = &identity.Email
+	}
+	var accountID uuid.UUID
+	err := database.RetryTx(ctx, s.db, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, `
+			UPDATE account_identities SET email = $3
+			WHERE provider = $1 AND subject = $2
+			RETURNING account_id
+		`, identity.Provider, identity.Subject, email).Scan(&accountID)
+		if !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		accountID = uuid.New()
+		if _, err := tx.ExecContext(ctx, `INSERT INTO accounts (id) VALUES ($1)`, accountID); err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO account_identities (provider, subject, account_id, email)
+			VALUES ($1, $2, $3, $4)
+		`, identity.Provider, identity.Subject, accountID, email)
+		return err
+	})
+	return accountID, err
+}
+
+// requireAccount checks the X-Account-Token header, falling back to the
+// session's account.
+func (s *Server) requireAccount(w nethttp.ResponseWriter, r *nethttp.Request) (uuid.UUID, bool) {
+	token := strings.TrimSpace(r.Header.Get("X-Account-Token"))
+	if token == "" {
+		if session, ok := sessionFromContext(r.Context()); ok && session.AccountID != nil {
+			auditFromContext(r.Context()).setActor(auditActorAccount, session.AccountID.String())
+			return *session.AccountID, true
+		}
+		writeProblem(w, nethttp.StatusUnauthorized, errorCodeAccountTokenRequired, "missing account token")
+		return uuid.Nil, false
+	}
+	accountID, _, err := s.authTokens.VerifyAccount(token, s.cfg.HTTP.AccountTokenTTL)
+	if err != nil {
+		writeProblem(w, nethttp.StatusUnauthorized, errorCodeAccountTokenRequired, err.Error())
+		return uuid.Nil, false
+	}
+	auditFromContext(r.Context()).setActor(auditActorAccount, accountID.String())
+	return accountID, true
+}
+
+// requireAccountDecision lets an account token stand in for the creator
+// token on decisions linked to the account.
+func (s *Server) requireAccountDecision(w nethttp.ResponseWriter, r *nethttp.Request, decision store.Decision) bool {
+	accountID, ok := s.requireAccount(w, r)
+	if !ok {
+		return false
+	}
+	var linked bool
+	if err := s.db.QueryRowContext(r.Context(), `
+		SELECT EXISTS (SELECT 1 FROM account_decisions WHERE decision_id = $1 AND account_id = $2)
+	`, decision.ID, accountID).Scan(&linked); err != nil {
+		s.writeServerError(w, err, "failed to load account")
+		return false
+	}
+	if !linked {
+		writeProblem(w, nethttp.StatusForbidden, errorCodeCreatorTokenInvalid, "decision is not linked to this account")
+		return false
+	}
+	return true
+}
+
+func (s *Server) handleGetAccount(w nethttp.ResponseWriter, r *nethttp.Request) {
+	accountID, ok := s.requireAccount(w, r)
+	if !ok {
+		return
+	}
+	ctx, cancel := withBudget(r.Context(), statsQueryBudget)
+	defer cancel()
+
+	out := accountView{
+		AccountID:  accountID.String(),
+		Identities: []accountIdentityView{},
+		Viewers:    []createViewerResponse{},
+		Decisions:  []accountDecisionView{},
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT provider, email FROM account_identities WHERE account_id = $1 ORDER BY created_at
+	`, accountID)
+	if err != nil {
+		s.writeServerError(w, err, "failed to load account")
+		return
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var it accountIdentityView
+		if err := rows.Scan(&it.Provider, &it.Email); err != nil {
+			s.writeServerError(w, err, "failed to load account")
+			return
+		}
+		out.Identities = append(out.Identities, it)
+	}
+	if err := rows.Err(); err != nil {
+		s.writeServerError(w, err, "failed to load account")
+		return
+	}
+	if len(out.Identities) == 0 {
+		writeProblem(w, nethttp.StatusUnauthorized, errorCodeAccountTokenRequired, "account no longer exists")
+		return
+	}
+
+	viewerRows, err := s.db.QueryContext(ctx, `
+		SELECT viewer_id FROM account_viewers WHERE account_id = $1 ORDER BY linked_at
+	`, accountID)
+	if err != nil {
+		s.writeServerError(w, err, "failed to load account")
+		return
+	}
+	defer viewerRows.Close()
+	for viewerRows.Next() {
+		var viewerID uuid.UUID
+		if err := viewerRows.Scan(&viewerID); err != nil {
+			s.writeServerError(w, err, "failed to load account")
+			return
+		}
+		out.Viewers = append(out.Viewers, createViewerResponse{
+			ViewerID:    viewerID.String(),
+			ViewerToken: s.viewerTokens.Sign(viewerID),
+		})
+	}
+	if err := viewerRows.Err(); err != nil {
+		s.writeServerError(w, err, "failed to load account")
+		return
+	}
+
+	decisionRows, err := s.db.QueryContext(ctx, `
+		SELECT d.slug, d.title, d.created_at
+		FROM account_decisions ad
+		JOIN decisions d ON d.id = ad.decision_id
+		WHERE ad.account_id = $1 AND d.hidden_at IS NULL
+		ORDER BY d.created_at DESC
+	`, accountID)
+	if err != nil {
+		s.writeServerError(w, err, "failed to load account")
+		return
+	}
+	defer decisionRows.Close()
+	for decisionRows.Next() {
+		var it accountDecisionView
+		if err := decisionRows.Scan(&it.Slug, &it.Title, &it.CreatedAt); err != nil {
+			s.writeServerError(w, err, "failed to load account")
+			return
+		}
+		out.Decisions = append(out.Decisions, it)
+	}
+	if err := decisionRows.Err(); err != nil {
+		s.writeServerError(w, err, "failed to load account")
+		return
+	}
+	writeJSON(w, nethttp.StatusOK, out)
+}
+
+// handleLinkAccountViewer adds the viewer whose token is in the body to the
+// account. A viewer belongs to one account at most.
+func (s *Server) handleLinkAccountViewer(w nethttp.ResponseWriter, r *nethttp.Request) {
+	accountID, ok := s.requireAccount(w, r)
+	if !ok {
+		return
+	}
+	var req linkViewerRequest
+	if err := decodeJSON(w, r, maxResponseBodyBytes, &req); err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+	viewer, ok := s.requireViewer(w, r, req.ViewerToken, "")
+	if !ok {
+		return
+	}
+	s.linkToAccount(w, r, `
+		INSERT INTO account_viewers (viewer_id, account_id) VALUES ($1, $2)
+		ON CONFLICT (viewer_id) DO UPDATE SET account_id = account_viewers.account_id
+		RETURNING account_id
+	`, viewer.ID, accountID, "viewer")
+}
+
+// handleLinkAccountDecision adds the decision to the account, proven with
+// its creator token.
+func (s *Server) handleLinkAccountDecision(w nethttp.ResponseWriter, r *nethttp.Request) {
+	accountID, ok := s.requireAccount(w, r)
+	if !ok {
+		return
+	}
+	decision, ok := s.loadCreatorDecision(w, r)
+	if !ok {
+		return
+	}
+	s.linkToAccount(w, r, `
+		INSERT INTO account_decisions (decision_id, account_id) VALUES ($1, $2)
+		ON CONFLICT (decision_id) DO UPDATE SET account_id = account_decisions.account_id
+		RETURNING account_id
+	`, decision.ID, accountID, "decision")
+}
+
+// linkToAccount runs an upsert that returns the owning account, which is
+// someone else's if the row was already linked there.
+func (s *Server) linkToAccount(w nethttp.ResponseWriter, r *nethttp.Request, query string, id, accountID uuid.UUID, what string) {
+	ctx, cancel := withBudget(r.Context(), writeQueryBudget)
+	defer cancel()
+	var owner uuid.UUID
+	if err := s.db.QueryRowContext(ctx, query, id, accountID).Scan(&owner); err != nil {
+		s.writeServerError(w, err, "failed to link "+what)
+		return
+	}
+	if owner != accountID {
+		writeProblem(w, nethttp.StatusConflict, errorCodeLinkedElsewhere, what+" is linked to another account")
+		return
+	}
+	w.WriteHeader(nethttp.StatusNoContent)
+}
@@ -0,0 +1,148 @@
+package httpapi
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	adaptiveTickInterval     = 10 * time.Second
+	adaptiveProbeTimeout     = 2 * time.Second
+	adaptiveLatencyThreshold = 250 * time.Millisecond
+	adaptiveErrorRateLimit   = 0.05
+	adaptiveMinFactor        = 0.2
+	adaptiveTightenFactor    = 0.5
+	adaptiveRelaxStep        = 0.1
+)
+
+// adaptiveLimits is a feedback controller over the rate limiters.
+// Every tick it probes the database and looks at the 5xx rate since the last
+// tick; when either is unhealthy it halves the effective limits (down to
+// adaptiveMinFactor of the base), otherwise it relaxes them step by step back
+// to the configured base.
+type adaptiveLimits struct {
+	mu         sync.Mutex
+	factor     float64
+	state      string
+	reason     string
+	updatedAt  time.Time
+	lastTotals metricsSnapshot
+
+	viewerBase int
+}
+
+type adaptiveLimitsView struct {
+	State                string               `json:"state"`
+	Reason               string               `json:"reason,omitempty"`
+	Factor               float64              `json:"factor"`
+	Routes               []routeRateLimitView `json:"routes"`
+	ViewerLimitPerMinute int                  `json:"viewer_limit_per_minute"`
+	BaseViewerPerMinute  int                  `json:"base_viewer_limit_per_minute"`
+	LatencyThresholdMS   int64                `json:"latency_threshold_ms"`
+	ErrorRateThreshold   float64              `json:"error_rate_threshold"`
+	UpdatedAt            time.Time            `json:"updated_at"`
+}
+
+func newAdaptiveLimits(viewerBase int) *adaptiveLimits {
+	return &adaptiveLimits{
+		factor:     1.0,
+		state:      "normal",
+		updatedAt:  time.Now(),
+		viewerBase: viewerBase,
+	}
+}
+
+func (s *Server) adjustAdaptiveLimits(ctx context.Context) {
+	probeCtx, cancel := context.WithTimeout(ctx, adaptiveProbeTimeout)
+	started := time.Now()
+	var one int
+	err := s.db.QueryRowContext(probeCtx, "SELECT 1").Scan(&one)
+	latency := time.Since(started)
+	cancel()
+	s.metrics.ObserveDBProbe(latency, err)
+
+	current := s.metrics.Snapshot()
+
+	a := s.adaptive
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	requests := current.RequestsTotal - a.lastTotals.RequestsTotal
+	serverErrors := current.ServerErrorsTotal - a.lastTotals.ServerErrorsTotal
+	a.lastTotals = current
+
+	errorRate := 0.0
+	if requests > 0 {
+		errorRate = float64(serverErrors) / float64(requests)
+	}
+
+	reason := ""
+	switch {
+	case err != nil:
+		reason = "database probe failed"
+	case latency > adaptiveLatencyThreshold:
+		reason = "database latency above threshold"
+	case errorRate > adaptiveErrorRateLimit:
+		reason = "server error rate above threshold"
+	}
+
+	if reason != "" {
+		a.factor = max(a.factor*adaptiveTightenFactor, adaptiveMinFactor)
+		a.state = "tightened"
+		a.reason = reason
+	} else if a.factor < 1.0 {
+		a.factor = min(a.factor+adaptiveRelaxStep, 1.0)
+		a.state = "recovering"
+		a.reason = ""
+		if a.factor >= 1.0 {
+			a.state = "normal"
+		}
+	} else {
+		a.state = "normal"
+		a.reason = ""
+	}
+	a.updatedAt = time.Now()
+
+	for _, rl := range s.rateLimits {
+		rl.limiter.SetLimit(scaledLimit(rl.base, a.factor))
+	}
+	s.viewerLimiter.SetLimit(scaledLimit(a.viewerBase, a.factor))
+}
+
+func (s *Server) adaptiveLimitsView() adaptiveLimitsView {
+	a := s.adaptive
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	routes := make([]routeRateLimitView, 0, len(s.rateLimits))
+	for _, rl := range s.rateLimits {
+		routes = append(routes, routeRateLimitView{
+			Name:      rl.name,
+			Limit:     rl.limiter.Limit(),
+			BaseLimit: rl.base,
+			Window:    rl.window.String(),
+		})
+	}
+	sort.Slice(routes, func(i, j int) bool { return routes[i].Name < routes[j].Name })
+
+	return adaptiveLimitsView{
+		State:                a.state,
+		Reason:               a.reason,
+		Factor:               a.factor,
+		Routes:               routes,
+		ViewerLimitPerMinute: s.viewerLimiter.Limit(),
+		BaseViewerPerMinute:  a.viewerBase,
+		LatencyThresholdMS:   adaptiveLatencyThreshold.Milliseconds(),
+		ErrorRateThreshold:   adaptiveErrorRateLimit,
+		UpdatedAt:            a.updatedAt,
+	}
+}
+
+func scaledLimit(base int, factor float64) int {
+	if base <= 0 {
+		return base
+	}
+	return max(int(float64(base)*factor), 1)
+}
@@ -0,0 +1,57 @@
+package httpapi
+
+import (
+	"crypto/subtle"
+	nethttp "net/http"
+	"strings"
+
+	"ratemylifedecision/internal/database"
+	"ratemylifedecision/internal/jobs"
+)
+
+type adminStatusResponse struct {
+	Metrics    metricsSnapshot    `json:"metrics"`
+	RateLimits adaptiveLimitsView `json:"rate_limits"`
+	DBPool     database.PoolStats `json:"db_pool"`
+	Jobs       []jobs.Status      `json:"jobs"`
+}
+
+// requireAdminKeyMiddleware guards operator-only routes with ADMIN_API_KEY,
+// or an admin-scoped API key in X-API-Key. When neither is
+// configured the admin surface is disabled entirely.
+func (s *Server) requireAdminKeyMiddleware(next nethttp.Handler) nethttp.Handler {
+	return nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		if s.adminAPIKey == "" && !s.apiKeys.hasScope(apiScopeAdmin) {
+			writeError(w, nethttp.StatusNotFound, "not found")
+			return
+		}
+		if r.Header.Get("X-API-Key") != "" && r.Header.Get("X-Admin-Key") == "" {
+			if s.checkAPIKey(w, r, apiScopeAdmin) {
+				next.ServeHTTP(w, r)
+			}
+			return
+		}
+
+		key := strings.TrimSpace(r.Header.Get("X-Admin-Key"))
+		if key == "" {
+			writeError(w, nethttp.StatusUnauthorized, "missing admin key")
+			return
+		}
+		if subtle.ConstantTimeCompare([]byte(key), []byte(s.adminAPIKey)) != 1 {
+			writeError(w, nethttp.StatusUnauthorized, "invalid admin key")
+			return
+		}
+		auditFromContext(r.Context()).setActor(auditActorAdmin, "")
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleAdminStatus(w nethttp.ResponseWriter, _ *nethttp.Request) {
+	writeJSON(w, nethttp.StatusOK, adminStatusResponse{
+		Metrics:    s.metrics.Snapshot(),
+		RateLimits: s.adaptiveLimitsView(),
+		DBPool:     database.Stats(s.pool),
+		Jobs:       s.jobs.Statuses(),
+	})
+}
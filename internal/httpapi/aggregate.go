@@ -0,0 +1,44 @@
+package httpapi
+
+import (
+	nethttp "net/http"
+
+	"ratemylifedecision/internal/store"
+)
+
+// visibleResponses returns the response cards that may leave the server for
+// decision. Aggregate-only decisions still load their responses so stats and
+// the recommendation can be computed, but never hand them out.
+func visibleResponses(decision store.Decision, responses []responseCard) []responseCard {
+	if decision.AggregateOnly {
+		return []responseCard{}
+	}
+	return responses
+}
+
+// handleEnableAggregateOnly switches a decision to aggregate-only mode. It is
+// one-way: responders may have written under the promise that their comments
+// stay private, so the creator cannot turn individual responses back on.
+func (s *Server) handleEnableAggregateOnly(w nethttp.ResponseWriter, r *nethttp.Request) {
+	decision, ok := s.loadCreatorDecision(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	if !decision.AggregateOnly {
+		if _, err := s.db.ExecContext(ctx, `
+			UPDATE decisions SET aggregate_only = true WHERE id = $1 AND NOT aggregate_only
+		`, decision.ID); err != nil {
+			s.writeServerError(w, err, "failed to enable aggregate-only mode")
+			return
+		}
+		s.cache.Invalidate(decision.ID)
+	}
+
+	writeJSON(w, nethttp.StatusOK, map[string]any{"aggregate_only": true})
+
+	if !decision.AggregateOnly {
+		s.publishLiveUpdate(ctx, "aggregate_only_enabled", decision.ID, nil)
+	}
+}
@@ -0,0 +1,533 @@
+package httpapi
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	nethttp "net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	errorCodeAPIKeyExpired       = "api_key_expired"
+	errorCodeAPIKeyScopeRequired = "api_key_scope_required"
+)
+
+const (
+	apiKeysChangedEvent = "api_keys_changed"
+	maxAPIKeyBodyBytes  = 1024
+	apiKeyNameMaxLength = 100
+	// apiKeySecretPrefix marks keys issued by this service, so one pasted
+	// somewhere it should not be is easy to recognise.
+	apiKeySecretPrefix = "rmk_"
+	// apiKeyShownPrefix is how much of a secret is kept in the clear.
+	apiKeyShownPrefix = len(apiKeySecretPrefix) + 6
+)
+
+// apiScope is what an API key may do. Scopes are ordered: each includes
+// the ones below it, so a write key can also read and an admin key can do
+// everything.
+type apiScope int
+
+const (
+	apiScopeRead apiScope = iota + 1
+	apiScopeWrite
+	apiScopeAdmin
+)
+
+var apiScopeNames = map[string]apiScope{
+	"read":  apiScopeRead,
+	"write": apiScopeWrite,
+	"admin": apiScopeAdmin,
+}
+
+func (sc apiScope) String() string {
+	for name, v := range apiScopeNames {
+		if v == sc {
+			return name
+		}
+	}
+	return "unknown"
+}
+
+type apiKey struct {
+	// id is set for keys from the api_keys table, whose use is tracked.
+	id    uuid.UUID
+	scope apiScope
+	// expiresAt is zero for keys that never expire.
+	expiresAt time.Time
+}
+
+// apiKeyRing holds the keys API callers may present. Keys come from
+// WRITE_API_KEYS, which are fixed for the life of the process, and from the
+// api_keys table, which is reloaded every API_KEYS_REFRESH and whenever an
+// instance changes it. Stored keys are looked up by the hash of the secret,
+// so the table never holds one in the clear.
+//
+// Until either source has a key the public API stays open. Setting
+// WRITE_API_KEYS to nothing but malformed entries turns every key away
+// rather than opening writes.
+type apiKeyRing struct {
+	fromEnv bool
+	static  map[string]apiKey
+
+	mu     sync.RWMutex
+	stored map[string]apiKey
+	// used is when each stored key was last presented, flushed to
+	// last_used_at on reload.
+	used map[uuid.UUID]time.Time
+}
+
+type apiKeyView struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	Scope        string     `json:"scope"`
+	SecretPrefix string     `json:"secret_prefix"`
+	ExpiresAt    *time.Time `json:"expires_at"`
+	CreatedAt    time.Time  `json:"created_at"`
+	RotatedAt    *time.Time `json:"rotated_at"`
+	RevokedAt    *time.Time `json:"revoked_at"`
+	LastUsedAt   *time.Time `json:"last_used_at"`
+}
+
+// issuedAPIKeyView carries the secret, which is shown only when a key is
+// created or rotated.
+type issuedAPIKeyView struct {
+	apiKeyView
+	Secret string `json:"secret"`
+}
+
+type createAPIKeyRequest struct {
+	Name  string `json:"name"`
+	Scope string `json:"scope"`
+	// TTL is a Go duration such as "720h"; empty means the key never
+	// expires.
+	TTL string `json:"ttl"`
+}
+
+type rotateAPIKeyRequest struct {
+	// Grace is how long the old secret keeps working, as a Go duration;
+	// empty retires it at once.
+	Grace string `json:"grace"`
+}
+
+// newAPIKeyRing parses WRITE_API_KEYS entries of the form
+// key[:scope[:expires]], where scope is read, write or admin (write when
+// omitted, as keys were before scopes) and expires is an RFC 3339 time or
+// a YYYY-MM-DD date, meaning the start of that day in UTC. Malformed
+// entries are reported together in the error.
+func newAPIKeyRing(entries []string) (*apiKeyRing, error) {
+	ring := &apiKeyRing{
+		static:  make(map[string]apiKey, len(entries)),
+		stored:  make(map[string]apiKey),
+		used:    make(map[uuid.UUID]time.Time),
+		fromEnv: len(entries) > 0,
+	}
+	var errs []error
+	for i, part := range entries {
+		fields := strings.Split(part, ":")
+		// An RFC 3339 expiry has colons of its own.
+		if len(fields) > 3 {
+			fields = []string{fields[0], fields[1], strings.Join(fields[2:], ":")}
+		}
+		key := apiKey{scope: apiScopeWrite}
+		if len(fields) > 1 && fields[1] != "" {
+			scope, ok := apiScopeNames[strings.ToLower(fields[1])]
+			if !ok {
+				errs = append(errs, fmt.Errorf("entry %d: unknown scope %q", i+1, fields[1]))
+				continue
+			}
+			key.scope = scope
+		}
+		if len(fields) > 2 && fields[2] != "" {
+			expiresAt, err := parseAPIKeyExpiry(fields[2])
+			if err != nil {
+				errs = append(errs, fmt.Errorf("entry %d: %w", i+1, err))
+				continue
+			}
+			key.expiresAt = expiresAt
+		}
+		ring.static[fields[0]] = key
+	}
+	return ring, errors.Join(errs...)
+}
+
+func parseAPIKeyExpiry(raw string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, raw); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("expiry %q must be an RFC 3339 time or YYYY-MM-DD", raw)
+}
+
+func hashAPIKeySecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func newAPIKeySecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return apiKeySecretPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// enabled reports whether callers are expected to present keys at all.
+func (k *apiKeyRing) enabled() bool {
+	if k.fromEnv {
+		return true
+	}
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return len(k.stored) > 0
+}
+
+// hasScope reports whether any key grants scope.
+func (k *apiKeyRing) hasScope(scope apiScope) bool {
+	for _, key := range k.static {
+		if key.scope >= scope {
+			return true
+		}
+	}
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	for _, key := range k.stored {
+		if key.scope >= scope {
+			return true
+		}
+	}
+	return false
+}
+
+// lookup finds the key for secret and notes its use.
+func (k *apiKeyRing) lookup(secret string, now time.Time) (apiKey, bool) {
+	if key, ok := k.static[secret]; ok {
+		return key, true
+	}
+	hash := hashAPIKeySecret(secret)
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	key, ok := k.stored[hash]
+	if ok {
+		k.used[key.id] = now
+	}
+	return key, ok
+}
+
+// Load records when stored keys were last used, then swaps in the usable
+// rows of api_keys, including the old secrets of keys still in their
+// rotation grace period.
+func (k *apiKeyRing) Load(ctx context.Context, db *sql.DB) error {
+	k.mu.Lock()
+	used := k.used
+	k.used = make(map[uuid.UUID]time.Time, len(used))
+	k.mu.Unlock()
+	for id, at := range used {
+		if _, err := db.ExecContext(ctx, `
+			UPDATE api_keys SET last_used_at = $2
+			WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < $2)
+		`, id, at); err != nil {
+			return err
+		}
+	}
+
+	rows, err := db.QueryContext(ctx, `
+		SELECT id, scope, secret_hash, previous_secret_hash, previous_valid_until, expires_at
+		FROM api_keys
+		WHERE revoked_at IS NULL AND (expires_at IS NULL OR expires_at > now())
+	`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	stored := make(map[string]apiKey)
+	for rows.Next() {
+		var (
+			key                apiKey
+			scope, hash        string
+			previousHash       *string
+			previousValidUntil *time.Time
+			expiresAt          *time.Time
+		)
+		if err := rows.Scan(&key.id, &scope, &hash, &previousHash, &previousValidUntil, &expiresAt); err != nil {
+			return err
+		}
+		key.scope = apiScopeNames[scope]
+		if expiresAt != nil {
+			key.expiresAt = *expiresAt
+		}
+		stored[hash] = key
+		if previousHash != nil && previousValidUntil != nil && time.Now().Before(*previousValidUntil) {
+			previous := key
+			if previous.expiresAt.IsZero() || previousValidUntil.Before(previous.expiresAt) {
+				previous.expiresAt = *previousValidUntil
+			}
+			stored[*previousHash] = previous
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	k.mu.Lock()
+	k.stored = stored
+	k.mu.Unlock()
+	return nil
+}
+
+func (s *Server) reloadAPIKeys(ctx context.Context) {
+	ctx, cancel := withBudget(ctx, statsQueryBudget)
+	defer cancel()
+	if err := s.apiKeys.Load(ctx, s.db); err != nil && ctx.Err() == nil {
+		slog.Warn("api keys reload failed; keeping the previous keys", "error", err)
+	}
+}
+
+// checkAPIKey validates the X-API-Key header against scope, writing the
+// error response itself: 401 for a missing, unknown or expired key and 403
+// for a valid key without the scope.
+// The key is noted as the request's for the audit log.
+func (s *Server) checkAPIKey(w nethttp.ResponseWriter, r *nethttp.Request, scope apiScope) bool {
+	header := strings.TrimSpace(r.Header.Get("X-API-Key"))
+	if header == "" {
+		writeProblem(w, nethttp.StatusUnauthorized, errorCodeAPIKeyRequired, "missing API key")
+		return false
+	}
+	now := time.Now()
+	key, ok := s.apiKeys.lookup(header, now)
+	if !ok {
+		writeProblem(w, nethttp.StatusUnauthorized, errorCodeAPIKeyInvalid, "invalid API key")
+		return false
+	}
+	if !key.expiresAt.IsZero() && !now.Before(key.expiresAt) {
+		writeProblem(w, nethttp.StatusUnauthorized, errorCodeAPIKeyExpired, "API key expired")
+		return false
+	}
+	if key.scope < scope {
+		writeProblem(w, nethttp.StatusForbidden, errorCodeAPIKeyScopeRequired, "API key lacks the "+scope.String()+" scope")
+		return false
+	}
+	auditFromContext(r.Context()).setAPIKey(key)
+	return true
+}
+
+// requireAPIKeyMiddleware enforces scope on a route group once any API keys
+// are configured. Read routes stay open to callers without a key unless
+// REQUIRE_READ_API_KEY is set, but a key that is sent is always checked.
+func (s *Server) requireAPIKeyMiddleware(scope apiScope) func(nethttp.Handler) nethttp.Handler {
+	return func(next nethttp.Handler) nethttp.Handler {
+		return nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+			if !s.apiKeys.enabled() {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if scope == apiScopeRead && !s.requireReadAPIKey && strings.TrimSpace(r.Header.Get("X-API-Key")) == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !s.checkAPIKey(w, r, scope) {
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+const apiKeyColumns = `id, name, scope, secret_prefix, expires_at, created_at, rotated_at, revoked_at, last_used_at`
+
+func scanAPIKeyView(row interface{ Scan(...any) error }) (apiKeyView, error) {
+	var (
+		v  apiKeyView
+		id uuid.UUID
+	)
+	if err := row.Scan(&id, &v.Name, &v.Scope, &v.SecretPrefix, &v.ExpiresAt, &v.CreatedAt, &v.RotatedAt, &v.RevokedAt, &v.LastUsedAt); err != nil {
+		return apiKeyView{}, err
+	}
+	v.ID = id.String()
+	return v, nil
+}
+
+func (s *Server) handleListAPIKeys(w nethttp.ResponseWriter, r *nethttp.Request) {
+	ctx, cancel := withBudget(r.Context(), statsQueryBudget)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys ORDER BY created_at DESC`)
+	if err != nil {
+		s.writeServerError(w, err, "failed to load api keys")
+		return
+	}
+	defer rows.Close()
+
+	items := make([]apiKeyView, 0, 16)
+	for rows.Next() {
+		v, err := scanAPIKeyView(rows)
+		if err != nil {
+			s.writeServerError(w, err, "failed to load api keys")
+			return
+		}
+		items = append(items, v)
+	}
+	if err := rows.Err(); err != nil {
+		s.writeServerError(w, err, "failed to load api keys")
+		return
+	}
+	writeJSON(w, nethttp.StatusOK, map[string]any{"items": items})
+}
+
+// handleCreateAPIKey issues a key and makes it usable on every instance
+// straight away. The secret is in this response and nowhere else.
+func (s *Server) handleCreateAPIKey(w nethttp.ResponseWriter, r *nethttp.Request) {
+	var req createAPIKeyRequest
+	if err := decodeJSON(w, r, maxAPIKeyBodyBytes, &req); err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len([]rune(name)) > apiKeyNameMaxLength {
+		writeError(w, nethttp.StatusBadRequest, fmt.Sprintf("name is required and must be at most %d characters", apiKeyNameMaxLength))
+		return
+	}
+	scope := strings.ToLower(strings.TrimSpace(req.Scope))
+	if _, ok := apiScopeNames[scope]; !ok {
+		writeError(w, nethttp.StatusBadRequest, "scope must be read, write or admin")
+		return
+	}
+	var expiresAt *time.Time
+	if ttl := strings.TrimSpace(req.TTL); ttl != "" {
+		d, err := time.ParseDuration(ttl)
+		if err != nil || d <= 0 {
+			writeError(w, nethttp.StatusBadRequest, "ttl must be a positive duration such as 24h or 720h")
+			return
+		}
+		at := time.Now().Add(d).UTC()
+		expiresAt = &at
+	}
+	secret, err := newAPIKeySecret()
+	if err != nil {
+		s.writeServerError(w, err, "failed to create api key")
+		return
+	}
+
+	ctx := r.Context()
+	v, err := scanAPIKeyView(s.db.QueryRowContext(ctx, `
+		INSERT INTO api_keys (id, name, scope, secret_hash, secret_prefix, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING `+apiKeyColumns,
+		uuid.New(), name, scope, hashAPIKeySecret(secret), secret[:apiKeyShownPrefix], expiresAt))
+	if err != nil {
+		s.writeServerError(w, err, "failed to create api key")
+		return
+	}
+
+	s.reloadAPIKeys(ctx)
+	writeJSON(w, nethttp.StatusCreated, issuedAPIKeyView{apiKeyView: v, Secret: secret})
+
+	s.announceChange(ctx, apiKeysChangedEvent, uuid.Nil, liveChange{})
+}
+
+// handleRotateAPIKey gives a key a new secret. The old one stops working
+// at once, or after the requested grace period so callers can switch over
+// without an outage.
+func (s *Server) handleRotateAPIKey(w nethttp.ResponseWriter, r *nethttp.Request) {
+	id, ok := parseAPIKeyID(w, r)
+	if !ok {
+		return
+	}
+	var req rotateAPIKeyRequest
+	if r.ContentLength != 0 {
+		if err := decodeJSON(w, r, maxAPIKeyBodyBytes, &req); err != nil {
+			writeError(w, nethttp.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	var grace time.Duration
+	if raw := strings.TrimSpace(req.Grace); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil || d < 0 {
+			writeError(w, nethttp.StatusBadRequest, "grace must be a duration such as 10m or 24h")
+			return
+		}
+		grace = d
+	}
+	secret, err := newAPIKeySecret()
+	if err != nil {
+		s.writeServerError(w, err, "failed to rotate api key")
+		return
+	}
+
+	ctx := r.Context()
+	v, err := scanAPIKeyView(s.db.QueryRowContext(ctx, `
+		UPDATE api_keys SET
+			previous_secret_hash = secret_hash,
+			previous_valid_until = now() + make_interval(secs => $4),
+			secret_hash = $2,
+			secret_prefix = $3,
+			rotated_at = now()
+		WHERE id = $1 AND revoked_at IS NULL
+		RETURNING `+apiKeyColumns,
+		id, hashAPIKeySecret(secret), secret[:apiKeyShownPrefix], grace.Seconds()))
+	if errors.Is(err, sql.ErrNoRows) {
+		writeError(w, nethttp.StatusNotFound, "api key not found")
+		return
+	}
+	if err != nil {
+		s.writeServerError(w, err, "failed to rotate api key")
+		return
+	}
+
+	s.reloadAPIKeys(ctx)
+	writeJSON(w, nethttp.StatusOK, issuedAPIKeyView{apiKeyView: v, Secret: secret})
+
+	s.announceChange(ctx, apiKeysChangedEvent, uuid.Nil, liveChange{})
+}
+
+// handleRevokeAPIKey retires a key and any secret still in its rotation
+// grace period. The row is kept so its history stays listed.
+func (s *Server) handleRevokeAPIKey(w nethttp.ResponseWriter, r *nethttp.Request) {
+	id, ok := parseAPIKeyID(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE api_keys SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL
+	`, id)
+	if err != nil {
+		s.writeServerError(w, err, "failed to revoke api key")
+		return
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		writeError(w, nethttp.StatusNotFound, "api key not found")
+		return
+	}
+
+	s.reloadAPIKeys(ctx)
+	w.WriteHeader(nethttp.StatusNoContent)
+
+	s.announceChange(ctx, apiKeysChangedEvent, uuid.Nil, liveChange{})
+}
+
+func parseAPIKeyID(w nethttp.ResponseWriter, r *nethttp.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(strings.TrimSpace(chi.URLParam(r, "id")))
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, "api key id must be a valid UUID")
+		return uuid.Nil, false
+	}
+	return id, true
+}
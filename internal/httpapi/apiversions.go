@@ -0,0 +1,115 @@
+package httpapi
+
+import (
+	nethttp "net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// The public API is versioned by path prefix. Each version is a function
+// mounting its routes on a router, so a /v2 that changes some endpoints
+// (multi-option polls, say) gets its own mountAPIV2 registering new handlers
+// for those and v1's for the rest, and both versions are served side by
+// side. Admin, Slack, GraphQL, the feed and share links are not part of the
+// versioned API.
+
+// mountAPIV1 registers version 1 of the public API.
+func (s *Server) mountAPIV1(r chi.Router) {
+	r.Group(func(r chi.Router) {
+		r.Use(s.ipFilterMiddleware)
+		r.Use(s.rateLimitMiddleware("read"))
+		r.Use(s.requireAPIKeyMiddleware(apiScopeRead))
+		r.Use(s.sessionMiddleware)
+		r.Get("/decisions/random", s.handleRandomDecision)
+		r.Get("/decisions/{slug}", s.handleGetDecision)
+		r.Get("/decisions/{slug}/responses", s.handleListResponses)
+		r.Get("/decisions/{slug}/related", s.handleListRelatedDecisions)
+		r.Get("/decisions/{slug}/ws", s.handleDecisionWebSocket)
+		r.Get("/decisions/{slug}/events", s.handleDecisionEvents)
+		r.Get("/responses/{id}/html", s.handleGetResponseHTML)
+		r.Get("/decisions/{slug}/panel", s.handleGetPanel)
+		r.Get("/decisions/{slug}/export.csv", s.handleExportResponsesCSV)
+		r.Get("/decisions/{slug}/export.json", s.handleExportDecisionArchive)
+		r.Get("/decisions/{slug}/webhooks", s.handleListWebhooks)
+		r.Get("/decisions/{slug}/webhooks/{id}/deliveries", s.handleListWebhookDeliveries)
+		r.Get("/templates", s.handleListTemplates)
+		r.Get("/categories", s.handleListCategories)
+		r.Get("/categories/{category}/decisions", s.handleListCategoryDecisions)
+		r.Get("/compare", s.handleCompareDecisions)
+		r.Get("/me/decisions", s.handleListMyDecisions)
+		r.Get("/viewers/{viewer_id}/activity", s.handleViewerActivity)
+		r.Get("/auth/{provider}/login", s.handleOAuthLogin)
+		r.Get("/auth/{provider}/callback", s.handleOAuthCallback)
+		r.Get("/account", s.handleGetAccount)
+		r.Get("/insights/accuracy", s.handleAccuracyInsights)
+		r.Get("/insights/trending", s.handleTrendingInsights)
+		r.Get("/insights/leaderboard", s.handleLeaderboardInsights)
+		r.Get("/insights/categories", s.handleCategoryInsights)
+		r.Get("/insights/regret", s.handleRegretInsights)
+		r.Get("/decisions/{slug}/qr.png", s.handleDecisionQRCode)
+		r.With(publicCORSMiddleware).Get("/decisions/{slug}/embed", s.handleDecisionEmbed)
+		r.With(publicCORSMiddleware).Get("/oembed", s.handleOEmbed)
+	})
+	r.Group(func(r chi.Router) {
+		r.Use(s.ipFilterMiddleware)
+		r.Use(s.rateLimitMiddleware("write"))
+		r.Use(s.auditMiddleware)
+		// Optional API key auth for write routes supports key rotation:
+		// provide one or more comma-separated keys via WRITE_API_KEYS.
+		r.Use(s.requireAPIKeyMiddleware(apiScopeWrite))
+		r.Use(s.sessionMiddleware)
+		r.With(s.rateLimitMiddleware("create_viewer")).Post("/viewers", s.handleCreateViewer)
+		r.Post("/auth/token", s.handleCreateSessionToken)
+		r.Delete("/viewers/{viewer_id}/data", s.handleDeleteViewerData)
+		r.Post("/account/viewers", s.handleLinkAccountViewer)
+		r.Post("/account/decisions/{slug}", s.handleLinkAccountDecision)
+		r.With(s.rateLimitMiddleware("create_decision"), s.requireCaptchaMiddleware).Post("/decisions", s.handleCreateDecision)
+		r.With(s.requireCaptchaMiddleware).Post("/decisions/{slug}/responses", s.handleCreateResponse)
+		r.Put("/decisions/{slug}/responses/mine", s.handleUpdateMyResponse)
+		r.Delete("/decisions/{slug}/responses/mine", s.handleDeleteMyResponse)
+		r.Patch("/decisions/{slug}", s.handleUpdateDecision)
+		r.Put("/decisions/{slug}/closes-at", s.handleUpdateClosesAt)
+		r.With(s.rateLimitMiddleware("create_decision")).Post("/decisions/{slug}/duplicate", s.handleDuplicateDecision)
+		r.Post("/decisions/{slug}/vote", s.handleDecisionVote)
+		r.Post("/decisions/{slug}/votes", s.handleDecisionVote)
+		r.Post("/decisions/{slug}/report", s.handleReportDecision)
+		r.Post("/responses/{id}/report", s.handleReportResponse)
+		r.Post("/responses/{id}/reactions", s.handleReactToResponse)
+		r.Post("/decisions/{slug}/subscriptions", s.handleCreateSubscription)
+		r.Patch("/subscriptions/{id}", s.handleUpdateSubscription)
+		r.Delete("/subscriptions/{id}", s.handleDeleteSubscription)
+		r.Post("/devices", s.handleRegisterDevice)
+		r.Patch("/devices/{id}", s.handleUpdateDevice)
+		r.Delete("/devices/{id}", s.handleDeleteDevice)
+		r.Put("/decisions/{slug}/panel", s.handleUpdatePanel)
+		r.Post("/decisions/{slug}/panel/members", s.handleAddPanelMember)
+		r.Delete("/decisions/{slug}/panel/members/{memberID}", s.handleRemovePanelMember)
+		r.Put("/decisions/{slug}/outcome", s.handleRecordOutcome)
+		r.Put("/decisions/{slug}/retrospective", s.handleRecordRetrospective)
+		r.Put("/decisions/{slug}/retrospectives/mine", s.handleRecordMyRetrospective)
+		r.Put("/decisions/{slug}/aggregate-only", s.handleEnableAggregateOnly)
+		r.Post("/decisions/{slug}/webhooks", s.handleCreateWebhook)
+		r.Delete("/decisions/{slug}/webhooks/{id}", s.handleDeleteWebhook)
+		r.Post("/creator-email/confirm", s.handleConfirmCreatorEmail)
+	})
+}
+
+// legacyAPIMiddleware marks responses on the unversioned /api paths, which
+// serve v1, as deprecated: Deprecation and Sunset headers, and a Link to the
+// same path under successor.
+func legacyAPIMiddleware(prefix, successor string, sunset time.Time) func(nethttp.Handler) nethttp.Handler {
+	sunsetHeader := sunset.UTC().Format(nethttp.TimeFormat)
+	return func(next nethttp.Handler) nethttp.Handler {
+		return nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+			h := w.Header()
+			h.Set("Deprecation", "true")
+			h.Set("Sunset", sunsetHeader)
+			if rest, ok := strings.CutPrefix(r.URL.Path, prefix); ok {
+				h.Add("Link", "<"+successor+rest+`>; rel="successor-version"`)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
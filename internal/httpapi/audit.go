@@ -0,0 +1,383 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	nethttp "net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/logging"
+)
+
+const (
+	defaultAuditLogLimit = 50
+	maxAuditLogLimit     = 500
+)
+
+const (
+	auditActorAnonymous = "anonymous"
+	auditActorViewer    = "viewer"
+	auditActorAccount   = "account"
+	auditActorCreator   = "creator"
+	auditActorAPIKey    = "api_key"
+	auditActorAdmin     = "admin"
+	auditActorSlack     = "slack"
+)
+
+type auditKey struct{}
+
+// auditEntry collects what is known about a mutating request as it passes
+// through the auth checks and the handler. The first actor to prove itself
+// is recorded: an admin key, or the account, viewer or creator token the
+// handler asks for. Methods are safe on a nil entry, so helpers shared with
+// Slack, GraphQL and gRPC can call them unaudited.
+type auditEntry struct {
+	mu         sync.Mutex
+	actorType  string
+	actorID    string
+	apiKeyID   string
+	targetType string
+	targetID   string
+	before     json.RawMessage
+	after      json.RawMessage
+	// discarded marks a request that changed nothing despite succeeding,
+	// such as a Slack command answered with its usage.
+	discarded bool
+}
+
+type auditLogItem struct {
+	ID         int64           `json:"id"`
+	At         time.Time       `json:"at"`
+	ActorType  string          `json:"actor_type"`
+	ActorID    *string         `json:"actor_id"`
+	APIKeyID   *string         `json:"api_key_id"`
+	IP         *string         `json:"ip"`
+	Method     string          `json:"method"`
+	Route      string          `json:"route"`
+	Status     int             `json:"status"`
+	TargetType *string         `json:"target_type"`
+	TargetID   *string         `json:"target_id"`
+	RequestID  *string         `json:"request_id"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+}
+
+type auditLogPage struct {
+	Items []auditLogItem `json:"items"`
+	// NextBefore pages back through older entries as ?before=; null on the
+	// last page.
+	NextBefore *int64 `json:"next_before"`
+}
+
+func auditFromContext(ctx context.Context) *auditEntry {
+	entry, _ := ctx.Value(auditKey{}).(*auditEntry)
+	return entry
+}
+
+func (e *auditEntry) setActor(actorType, actorID string) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.actorType == "" {
+		e.actorType, e.actorID = actorType, actorID
+	}
+}
+
+func (e *auditEntry) setAPIKey(key apiKey) {
+	if key.id == uuid.Nil {
+		e.setAPIKeyID("env")
+		return
+	}
+	e.setAPIKeyID(key.id.String())
+}
+
+func (e *auditEntry) setAPIKeyID(id string) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.apiKeyID = id
+}
+
+func (e *auditEntry) discard() {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.discarded = true
+}
+
+func (e *auditEntry) setTarget(targetType, targetID string) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.targetType, e.targetID = targetType, targetID
+}
+
+// setBefore and setAfter snapshot v as JSON straight away, so later changes
+// to it do not leak into the record.
+func (e *auditEntry) setBefore(v any) {
+	if e == nil {
+		return
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.before = raw
+}
+
+func (e *auditEntry) setAfter(v any) {
+	if e == nil {
+		return
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.after = raw
+}
+
+// auditMiddleware records each request in the group that succeeds and
+// changes something into audit_log. It goes ahead of the API key and admin
+// checks so they can name the actor. Writing the row is best effort and
+// happens after the response, so a slow or failing insert never fails the
+// request it describes.
+func (s *Server) auditMiddleware(next nethttp.Handler) nethttp.Handler {
+	return nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		switch r.Method {
+		case nethttp.MethodGet, nethttp.MethodHead, nethttp.MethodOptions:
+			next.ServeHTTP(w, r)
+			return
+		}
+		entry := &auditEntry{}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), auditKey{}, entry)))
+
+		status := statusFromWriter(w)
+		if status >= 400 {
+			return
+		}
+		s.writeAuditEntry(r, entry, status)
+	})
+}
+
+func (s *Server) writeAuditEntry(r *nethttp.Request, entry *auditEntry, status int) {
+	route := r.URL.Path
+	rctx := chi.RouteContext(r.Context())
+	if rctx != nil {
+		if pattern := rctx.RoutePattern(); pattern != "" {
+			route = pattern
+		}
+	}
+	// Without a target from the handler, the route's own parameter names
+	// what was changed, e.g. slug for a decision.
+	entry.mu.Lock()
+	if entry.targetType == "" && rctx != nil && len(rctx.URLParams.Keys) > 0 {
+		last := len(rctx.URLParams.Keys) - 1
+		entry.targetType, entry.targetID = rctx.URLParams.Keys[last], rctx.URLParams.Values[last]
+		if entry.targetType == "slug" {
+			entry.targetType = "decision"
+		}
+	}
+	entry.mu.Unlock()
+	s.insertAuditEntry(r.Context(), entry, auditRequest{
+		ip:     s.clientIPFromRequest(r),
+		method: r.Method,
+		route:  route,
+		status: status,
+	})
+}
+
+// auditRequest is how a change came in: over HTTP, or over gRPC with the
+// full method name as the route.
+type auditRequest struct {
+	ip     string
+	method string
+	route  string
+	status int
+}
+
+func (s *Server) insertAuditEntry(ctx context.Context, entry *auditEntry, req auditRequest) {
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+	if entry.discarded {
+		return
+	}
+
+	actorType, actorID := entry.actorType, entry.actorID
+	if actorType == "" {
+		actorType = auditActorAnonymous
+		if entry.apiKeyID != "" {
+			actorType, actorID = auditActorAPIKey, entry.apiKeyID
+		}
+	}
+
+	ctx, cancel := withBudget(context.WithoutCancel(ctx), writeQueryBudget)
+	defer cancel()
+	if _, err := s.db.ExecContext(ctx, `
+		INSERT INTO audit_log (actor_type, actor_id, api_key_id, ip, method, route, status, target_type, target_id, request_id, before, after)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`, actorType, nullIfEmpty(actorID), nullIfEmpty(entry.apiKeyID), nullIfEmpty(req.ip),
+		req.method, req.route, req.status, nullIfEmpty(entry.targetType), nullIfEmpty(entry.targetID),
+		nullIfEmpty(logging.RequestID(ctx)), nullJSON(entry.before), nullJSON(entry.after),
+	); err != nil {
+		slog.WarnContext(ctx, "audit log write failed", "route", req.route, "error", err)
+	}
+}
+
+// auditCreatedDecision records a new decision as the request's target,
+// whichever API created it.
+func (s *Server) auditCreatedDecision(ctx context.Context, out createDecisionResponse) {
+	audit := auditFromContext(ctx)
+	if audit == nil {
+		return
+	}
+	audit.setTarget("decision", out.ID)
+	if created, err := s.decisions.BySlug(ctx, out.Slug); err == nil {
+		audit.setAfter(decisionViewFromStore(created))
+	}
+}
+
+// Audit rows outlive what they describe, so erasing people's data has to
+// reach them too. These clear the client IP and the before/after payloads,
+// which may quote comments and descriptions, and keep the rest of the row
+// so the log still shows that something happened.
+const (
+	// scrubDecisionAuditLog takes a decision ID and covers rows about the
+	// decision (by ID or slug), about its responses, or made with its
+	// creator token.
+	scrubDecisionAuditLog = `UPDATE audit_log SET ip = NULL, before = NULL, after = NULL
+		WHERE (target_type = 'decision' AND target_id IN ($1::text, (SELECT slug FROM decisions WHERE id = $1)))
+			OR (target_type = 'response' AND target_id IN (SELECT id::text FROM responses WHERE decision_id = $1))
+			OR (actor_type = 'creator' AND actor_id = $1::text)`
+	// scrubViewerAuditLog takes a viewer ID and covers rows made by the
+	// viewer or about their responses. Run it before the responses go.
+	scrubViewerAuditLog = `UPDATE audit_log SET ip = NULL, before = NULL, after = NULL
+		WHERE (actor_type = 'viewer' AND actor_id = $1::text)
+			OR (target_type = 'response' AND target_id IN (SELECT id::text FROM responses WHERE viewer_id = $1))`
+)
+
+// statusFromWriter finds the status recorded by requestLogMiddleware,
+// walking the wrapped writers down to the statusRecorder.
+func statusFromWriter(w nethttp.ResponseWriter) int {
+	for w != nil {
+		if rec, ok := w.(*statusRecorder); ok {
+			return rec.status
+		}
+		u, ok := w.(interface{ Unwrap() nethttp.ResponseWriter })
+		if !ok {
+			return nethttp.StatusOK
+		}
+		w = u.Unwrap()
+	}
+	return nethttp.StatusOK
+}
+
+func nullIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+func nullJSON(raw json.RawMessage) *string {
+	if raw == nil {
+		return nil
+	}
+	s := string(raw)
+	return &s
+}
+
+// handleListAuditLog pages back through audit_log, newest first, filtered
+// by any of actor_type, actor_id, api_key_id, ip, target_type and
+// target_id.
+func (s *Server) handleListAuditLog(w nethttp.ResponseWriter, r *nethttp.Request) {
+	limit, err := parseLimitParam(r, defaultAuditLogLimit, maxAuditLogLimit)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+	query := r.URL.Query()
+	var before *int64
+	if raw := query.Get("before"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id <= 0 {
+			writeError(w, nethttp.StatusBadRequest, "before must be an audit log entry id")
+			return
+		}
+		before = &id
+	}
+	filter := func(name string) *string {
+		return nullIfEmpty(strings.TrimSpace(query.Get(name)))
+	}
+
+	ctx, cancel := withBudget(r.Context(), statsQueryBudget)
+	defer cancel()
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, at, actor_type, actor_id, api_key_id, ip, method, route, status,
+			target_type, target_id, request_id, before, after
+		FROM audit_log
+		WHERE ($1::bigint IS NULL OR id < $1)
+		  AND ($2::text IS NULL OR actor_type = $2)
+		  AND ($3::text IS NULL OR actor_id = $3)
+		  AND ($4::text IS NULL OR api_key_id = $4)
+		  AND ($5::text IS NULL OR ip = $5)
+		  AND ($6::text IS NULL OR target_type = $6)
+		  AND ($7::text IS NULL OR target_id = $7)
+		ORDER BY id DESC
+		LIMIT $8
+	`, before, filter("actor_type"), filter("actor_id"), filter("api_key_id"), filter("ip"),
+		filter("target_type"), filter("target_id"), limit+1)
+	if err != nil {
+		s.writeServerError(w, err, "failed to load audit log")
+		return
+	}
+	defer rows.Close()
+
+	page := auditLogPage{Items: make([]auditLogItem, 0, limit)}
+	for rows.Next() {
+		var (
+			it            auditLogItem
+			before, after *[]byte
+		)
+		if err := rows.Scan(&it.ID, &it.At, &it.ActorType, &it.ActorID, &it.APIKeyID, &it.IP, &it.Method, &it.Route, &it.Status,
+			&it.TargetType, &it.TargetID, &it.RequestID, &before, &after); err != nil {
+			s.writeServerError(w, err, "failed to load audit log")
+			return
+		}
+		if before != nil {
+			it.Before = *before
+		}
+		if after != nil {
+			it.After = *after
+		}
+		page.Items = append(page.Items, it)
+	}
+	if err := rows.Err(); err != nil {
+		s.writeServerError(w, err, "failed to load audit log")
+		return
+	}
+	if len(page.Items) > limit {
+		page.Items = page.Items[:limit]
+		next := page.Items[limit-1].ID
+		page.NextBefore = &next
+	}
+	writeJSON(w, nethttp.StatusOK, page)
+}
@@ -0,0 +1,93 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	nethttp "net/http"
+	"strings"
+	"time"
+
+	"ratemylifedecision/internal/database"
+)
+
+// Deadline budgets. Every non-streaming request gets a total budget and the
+// individual operations inside it get a smaller slice, so a single slow query
+// fails fast instead of holding the request (and a pool connection) for the
+// whole budget. context.WithTimeout never extends the parent deadline, so an
+// operation budget is always capped by what is left of the request budget.
+const (
+	statsQueryBudget = 2 * time.Second
+	writeQueryBudget = 5 * time.Second
+)
+
+const (
+	errorCodeDeadlineExceeded = "deadline_exceeded"
+	errorCodeUnavailable      = "database_unavailable"
+)
+
+func withBudget(ctx context.Context, budget time.Duration) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, budget)
+}
+
+// requestBudgetMiddleware bounds each request with REQUEST_BUDGET (default
+// 10s). WebSocket and SSE streams are long-lived by design and only get
+// per-operation budgets; pprof CPU profiles and traces run for as long as
+// the operator asks.
+func (s *Server) requestBudgetMiddleware(budget time.Duration) func(nethttp.Handler) nethttp.Handler {
+	return func(next nethttp.Handler) nethttp.Handler {
+		return nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+			if budget <= 0 ||
+				r.Header.Get("Upgrade") != "" ||
+				strings.HasSuffix(r.URL.Path, "/events") ||
+				strings.HasPrefix(r.URL.Path, "/debug/pprof/") {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), budget)
+			defer cancel()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func isDeadlineExceeded(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+// writeServerError reports err as a 500 with message, unless the failure was
+// the request running out of budget, which gets a distinct 504 and code so
+// clients can tell "retry later" apart from a real server fault.
+func (s *Server) writeServerError(w nethttp.ResponseWriter, err error, message string) {
+	requestID := requestIDFromWriter(w)
+	if isDeadlineExceeded(err) {
+		s.metrics.ObserveDeadlineExceeded()
+		slog.Warn("request deadline exceeded", "request_id", requestID, "message", message)
+		writeProblem(w, nethttp.StatusGatewayTimeout, errorCodeDeadlineExceeded, "request timed out")
+		return
+	}
+	// Still transient after the store's retries, e.g. mid-failover: tell
+	// clients to come back rather than reporting a server bug.
+	if database.IsTransient(err) {
+		slog.Warn("database unavailable", "request_id", requestID, "message", message, "error", err)
+		w.Header().Set("Retry-After", "1")
+		writeProblem(w, nethttp.StatusServiceUnavailable, errorCodeUnavailable, "temporarily unavailable, please retry")
+		return
+	}
+	slog.Error("request failed", "request_id", requestID, "message", message, "error", err)
+	writeError(w, nethttp.StatusInternalServerError, message)
+}
+
+// serverErrorStatus is the status-code counterpart of writeServerError for
+// helpers that return (status, error) instead of writing the response.
+func (s *Server) serverErrorStatus(err error) int {
+	if isDeadlineExceeded(err) {
+		s.metrics.ObserveDeadlineExceeded()
+		return nethttp.StatusGatewayTimeout
+	}
+	if database.IsTransient(err) {
+		return nethttp.StatusServiceUnavailable
+	}
+	return nethttp.StatusInternalServerError
+}
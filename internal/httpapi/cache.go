@@ -0,0 +1,148 @@
+package httpapi
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/store"
+)
+
+const (
+	decisionCacheMaxEntries = 1024
+)
+
+// decisionSnapshot is the viewer-independent part of the decision envelope.
+// Viewer state (my_vote, viewer_has_responded) is always read fresh.
+type decisionSnapshot struct {
+	Decision       store.Decision
+	Stats          decisionStats
+	Recommendation recommendationView
+	PostVote       decisionVoteSummary
+	// Responses are the newest cards, up to store.ViewResponseLimit, and
+	// MoreResponses says there are older ones.
+	Responses     []responseCard
+	MoreResponses bool
+}
+
+type decisionCacheEntry struct {
+	snapshot  decisionSnapshot
+	expiresAt time.Time
+}
+
+type decisionCache struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[uuid.UUID]decisionCacheEntry
+	slugs   map[string]uuid.UUID
+}
+
+func newDecisionCache(ttl time.Duration) *decisionCache {
+	return &decisionCache{
+		ttl:     ttl,
+		entries: make(map[uuid.UUID]decisionCacheEntry, 64),
+		slugs:   make(map[string]uuid.UUID, 64),
+	}
+}
+
+func (c *decisionCache) Get(slug string, now time.Time) (decisionSnapshot, bool) {
+	if c.ttl <= 0 {
+		return decisionSnapshot{}, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	id, ok := c.slugs[slug]
+	if !ok {
+		return decisionSnapshot{}, false
+	}
+	entry, ok := c.entries[id]
+	if !ok || !now.Before(entry.expiresAt) {
+		return decisionSnapshot{}, false
+	}
+	return entry.snapshot, true
+}
+
+func (c *decisionCache) Put(snapshot decisionSnapshot, now time.Time) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.entries) >= decisionCacheMaxEntries {
+		c.evictLocked(now)
+	}
+	c.entries[snapshot.Decision.ID] = decisionCacheEntry{
+		snapshot:  snapshot,
+		expiresAt: now.Add(c.ttl),
+	}
+	c.slugs[snapshot.Decision.Slug] = snapshot.Decision.ID
+}
+
+func (c *decisionCache) Invalidate(decisionID uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[decisionID]
+	if !ok {
+		return
+	}
+	delete(c.entries, decisionID)
+	delete(c.slugs, entry.snapshot.Decision.Slug)
+}
+
+// Clear drops every entry. Used when invalidations from other instances
+// may have been missed.
+func (c *decisionCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+	clear(c.slugs)
+}
+
+func (c *decisionCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// Sweep drops expired entries, which Get would ignore anyway, so snapshots
+// of decisions nobody is viewing are not held onto.
+func (c *decisionCache) Sweep(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.sweepLocked(now)
+}
+
+func (c *decisionCache) sweepLocked(now time.Time) {
+	for id, entry := range c.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(c.entries, id)
+			delete(c.slugs, entry.snapshot.Decision.Slug)
+		}
+	}
+}
+
+func (c *decisionCache) evictLocked(now time.Time) {
+	c.sweepLocked(now)
+	// Still full of live entries: drop arbitrary ones rather than grow.
+	for id, entry := range c.entries {
+		if len(c.entries) < decisionCacheMaxEntries {
+			break
+		}
+		delete(c.entries, id)
+		delete(c.slugs, entry.snapshot.Decision.Slug)
+	}
+}
+
+func (s *Server) loadViewerState(ctx context.Context, decisionID uuid.UUID, viewerID *uuid.UUID) (int, bool, error) {
+	if viewerID == nil {
+		return 0, false, nil
+	}
+	return s.decisions.ViewerState(ctx, decisionID, *viewerID)
+}
@@ -0,0 +1,46 @@
+package httpapi
+
+import (
+	"errors"
+	"log/slog"
+	nethttp "net/http"
+	"strings"
+
+	"ratemylifedecision/internal/captcha"
+)
+
+const (
+	errorCodeCaptchaFailed      = "captcha_failed"
+	errorCodeCaptchaUnavailable = "captcha_unavailable"
+)
+
+// requireCaptchaMiddleware guards anonymous writes with the configured
+// Turnstile/hCaptcha challenge, passed in the X-Captcha-Token header. It is
+// a no-op unless CAPTCHA_PROVIDER is set. A broken configuration fails
+// closed so a typo cannot silently drop the bot barrier.
+func (s *Server) requireCaptchaMiddleware(next nethttp.Handler) nethttp.Handler {
+	return nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		if s.captcha == nil && s.captchaConfigErr == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if s.captchaConfigErr != nil {
+			writeProblem(w, nethttp.StatusServiceUnavailable, errorCodeCaptchaUnavailable, "captcha verification unavailable")
+			return
+		}
+
+		token := strings.TrimSpace(r.Header.Get("X-Captcha-Token"))
+		err := s.captcha.Verify(r.Context(), token, s.clientIPFromRequest(r))
+		if errors.Is(err, captcha.ErrRejected) {
+			writeProblem(w, nethttp.StatusForbidden, errorCodeCaptchaFailed, "captcha verification failed")
+			return
+		}
+		if err != nil {
+			slog.Warn("captcha verification unavailable", "request_id", requestIDFromWriter(w), "error", err)
+			writeProblem(w, nethttp.StatusServiceUnavailable, errorCodeCaptchaUnavailable, "captcha verification unavailable")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
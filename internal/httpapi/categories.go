@@ -0,0 +1,164 @@
+package httpapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	nethttp "net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+const errorCodeCategoryNotFound = "category_not_found"
+
+const (
+	defaultCategoryDecisionsLimit = 20
+	maxCategoryDecisionsLimit     = 100
+)
+
+type categoryView struct {
+	Slug      string `json:"slug"`
+	Label     string `json:"label"`
+	Emoji     string `json:"emoji"`
+	SortOrder int    `json:"sort_order"`
+}
+
+type categoryDecisionItem struct {
+	Slug          string     `json:"slug"`
+	Title         string     `json:"title"`
+	CreatedAt     time.Time  `json:"created_at"`
+	ClosesAt      *time.Time `json:"closes_at"`
+	Status        string     `json:"status"`
+	ResponseCount int        `json:"response_count"`
+}
+
+// normalizeCategory lower-cases a category slug and checks it against the
+// categories table. Unknown slugs are an invalidInputError; nil means none.
+func (s *Server) normalizeCategory(ctx context.Context, raw *string) (*string, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	category := strings.ToLower(strings.TrimSpace(*raw))
+	if category == "" {
+		return nil, nil
+	}
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM categories WHERE slug = $1)
+	`, category).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, invalidInputError{errors.New("category is invalid")}
+	}
+	return &category, nil
+}
+
+func (s *Server) handleListCategories(w nethttp.ResponseWriter, r *nethttp.Request) {
+	ctx, cancel := withBudget(r.Context(), statsQueryBudget)
+	defer cancel()
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT slug, label, emoji, sort_order FROM categories ORDER BY sort_order, slug
+	`)
+	if err != nil {
+		s.writeServerError(w, err, "failed to load categories")
+		return
+	}
+	defer rows.Close()
+
+	items := make([]categoryView, 0)
+	for rows.Next() {
+		var c categoryView
+		if err := rows.Scan(&c.Slug, &c.Label, &c.Emoji, &c.SortOrder); err != nil {
+			s.writeServerError(w, err, "failed to load categories")
+			return
+		}
+		items = append(items, c)
+	}
+	if err := rows.Err(); err != nil {
+		s.writeServerError(w, err, "failed to load categories")
+		return
+	}
+	writeJSON(w, nethttp.StatusOK, map[string]any{"items": items})
+}
+
+// handleListCategoryDecisions browses a category's public decisions, newest
+// first, like the feed. ?status=open leaves out closed ones.
+func (s *Server) handleListCategoryDecisions(w nethttp.ResponseWriter, r *nethttp.Request) {
+	limit, err := parseLimitParam(r, defaultCategoryDecisionsLimit, maxCategoryDecisionsLimit)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+	var openOnly bool
+	switch status := r.URL.Query().Get("status"); status {
+	case "", "all":
+	case "open":
+		openOnly = true
+	default:
+		writeError(w, nethttp.StatusBadRequest, "status must be open or all")
+		return
+	}
+
+	ctx, cancel := withBudget(r.Context(), statsQueryBudget)
+	defer cancel()
+
+	slug := strings.ToLower(strings.TrimSpace(chi.URLParam(r, "category")))
+	var category categoryView
+	err = s.db.QueryRowContext(ctx, `
+		SELECT slug, label, emoji, sort_order FROM categories WHERE slug = $1
+	`, slug).Scan(&category.Slug, &category.Label, &category.Emoji, &category.SortOrder)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeProblem(w, nethttp.StatusNotFound, errorCodeCategoryNotFound, "category not found")
+		return
+	}
+	if err != nil {
+		s.writeServerError(w, err, "failed to load category")
+		return
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			d.slug, d.title, d.created_at, d.closes_at,
+			d.closed_at IS NOT NULL OR (d.closes_at IS NOT NULL AND d.closes_at <= now()),
+			COALESCE(st.response_count, 0)::int
+		FROM decisions d
+		LEFT JOIN decision_stats st ON st.decision_id = d.id
+		WHERE d.category = $1
+		  AND d.hidden_at IS NULL
+		  AND NOT d.panel_only
+		  AND d.visibility = 'public'
+		  AND (NOT $2 OR (d.closed_at IS NULL AND (d.closes_at IS NULL OR d.closes_at > now())))
+		ORDER BY d.created_at DESC
+		LIMIT $3
+	`, category.Slug, openOnly, limit)
+	if err != nil {
+		s.writeServerError(w, err, "failed to load decisions")
+		return
+	}
+	defer rows.Close()
+
+	items := make([]categoryDecisionItem, 0, limit)
+	for rows.Next() {
+		var (
+			it     categoryDecisionItem
+			closed bool
+		)
+		if err := rows.Scan(&it.Slug, &it.Title, &it.CreatedAt, &it.ClosesAt, &closed, &it.ResponseCount); err != nil {
+			s.writeServerError(w, err, "failed to load decisions")
+			return
+		}
+		it.Status = "open"
+		if closed {
+			it.Status = "closed"
+		}
+		items = append(items, it)
+	}
+	if err := rows.Err(); err != nil {
+		s.writeServerError(w, err, "failed to load decisions")
+		return
+	}
+	writeJSON(w, nethttp.StatusOK, map[string]any{"category": category, "items": items})
+}
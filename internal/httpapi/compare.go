@@ -0,0 +1,106 @@
+package httpapi
+
+import (
+	"errors"
+	nethttp "net/http"
+	"strings"
+	"time"
+
+	"ratemylifedecision/internal/store"
+)
+
+type comparedDecision struct {
+	State          string             `json:"state"`
+	Decision       decisionView       `json:"decision"`
+	Stats          decisionStats      `json:"stats"`
+	Recommendation recommendationView `json:"recommendation"`
+	PostVoteScore  int                `json:"post_vote_score"`
+}
+
+// comparisonDiff is the first decision minus the second, so a positive
+// delta means the crowd favours the first.
+type comparisonDiff struct {
+	ScoreDelta            float64 `json:"score_delta"`
+	NetSentimentDelta     float64 `json:"net_sentiment_delta"`
+	CommentSentimentDelta float64 `json:"comment_sentiment_delta"`
+	AvgRatingDelta        float64 `json:"avg_rating_delta"`
+	ResponseCountDelta    int     `json:"response_count_delta"`
+}
+
+type decisionComparison struct {
+	Decisions [2]comparedDecision `json:"decisions"`
+	// Diff is null while either decision is collecting responses toward its
+	// quorum: its stats are held back, so there is nothing to compare.
+	Diff *comparisonDiff `json:"diff"`
+}
+
+// handleCompareDecisions puts two decisions side by side for "option A or
+// option B", given as ?slugs=a,b. One access code can't cover two
+// decisions, so private ones are only compared by a request that may see
+// them some other way, like with the creator token.
+func (s *Server) handleCompareDecisions(w nethttp.ResponseWriter, r *nethttp.Request) {
+	slugs := strings.Split(r.URL.Query().Get("slugs"), ",")
+	if len(slugs) != 2 {
+		writeError(w, nethttp.StatusBadRequest, "slugs must name exactly two decisions, comma-separated")
+		return
+	}
+	for i := range slugs {
+		slug, err := normalizeSlugParam(slugs[i])
+		if err != nil {
+			writeError(w, nethttp.StatusBadRequest, err.Error())
+			return
+		}
+		slugs[i] = slug
+	}
+	if slugs[0] == slugs[1] {
+		writeError(w, nethttp.StatusBadRequest, "slugs must name two different decisions")
+		return
+	}
+
+	var (
+		out     decisionComparison
+		pending bool
+		now     = time.Now()
+	)
+	for i, slug := range slugs {
+		snapshot, _, _, err := s.loadDecisionView(r.Context(), slug, nil)
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				writeProblem(w, nethttp.StatusNotFound, errorCodeDecisionNotFound, "decision "+slug+" not found")
+				return
+			}
+			s.writeServerError(w, err, "failed to load decision")
+			return
+		}
+		if requestAccessError(r, snapshot.Decision) != nil {
+			writeProblem(w, nethttp.StatusForbidden, errorCodeDecisionPrivate, "decision "+slug+" is private")
+			return
+		}
+
+		state := decisionStateRevealed
+		if withheld, ok := withholdUntilQuorum(snapshot, now); ok {
+			snapshot, state, pending = withheld, decisionStateCollecting, true
+		}
+		stats := snapshot.Stats
+		stats.Timeline, stats.Languages = nil, nil
+		out.Decisions[i] = comparedDecision{
+			State:          state,
+			Decision:       decisionViewFromStore(snapshot.Decision),
+			Stats:          stats,
+			Recommendation: snapshot.Recommendation,
+			PostVoteScore:  snapshot.PostVote.Score,
+		}
+	}
+
+	if !pending {
+		a, b := out.Decisions[0], out.Decisions[1]
+		out.Diff = &comparisonDiff{
+			ScoreDelta:            a.Recommendation.Score - b.Recommendation.Score,
+			NetSentimentDelta:     a.Stats.NetSentiment - b.Stats.NetSentiment,
+			CommentSentimentDelta: a.Recommendation.CommentSentiment - b.Recommendation.CommentSentiment,
+			AvgRatingDelta:        a.Stats.AvgRating - b.Stats.AvgRating,
+			ResponseCountDelta:    a.Stats.ResponseCount - b.Stats.ResponseCount,
+		}
+	}
+	writeJSON(w, nethttp.StatusOK, out)
+}
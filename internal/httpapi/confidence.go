@@ -0,0 +1,53 @@
+package httpapi
+
+import "math"
+
+// wilsonZ gives 95% Wilson score intervals.
+const wilsonZ = 1.96
+
+// responseLean scores one response as a yes (1), a no (0) or a split (0.5)
+// for the confidence interval.
+func responseLean(score float64) float64 {
+	switch {
+	case score > 0:
+		return 1
+	case score < 0:
+		return 0
+	default:
+		return 0.5
+	}
+}
+
+// recommendationConfidence treats each response and post vote as a vote
+// for or against and puts a Wilson score interval on the share leaning yes.
+// Confidence is the interval's bound on the side of lean: above 0.5 the
+// crowd is more likely than not to agree with the recommendation, and it
+// stays low until there are enough votes to rule out a coin flip. With
+// nothing to go on the interval is [0, 1] and confidence is 0.
+func recommendationConfidence(lean string, yes, n float64) (float64, [2]float64) {
+	if n <= 0 {
+		return 0, [2]float64{0, 1}
+	}
+	p := clamp(yes/n, 0, 1)
+	z2 := wilsonZ * wilsonZ
+	denom := 1 + z2/n
+	center := (p + z2/(2*n)) / denom
+	margin := wilsonZ * math.Sqrt(p*(1-p)/n+z2/(4*n*n)) / denom
+	interval := [2]float64{clamp(center-margin, 0, 1), clamp(center+margin, 0, 1)}
+	if lean == "yes" {
+		return interval[0], interval
+	}
+	return 1 - interval[1], interval
+}
+
+// verdictText words a recommendation decision for messages and emails.
+func verdictText(decision string) string {
+	switch decision {
+	case "yes":
+		return "do it"
+	case "no":
+		return "don't do it"
+	default:
+		return "not enough responses to call"
+	}
+}
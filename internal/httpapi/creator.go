@@ -0,0 +1,56 @@
+package httpapi
+
+import (
+	"errors"
+	nethttp "net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"ratemylifedecision/internal/store"
+)
+
+const (
+	errorCodeCreatorTokenRequired = "creator_token_required"
+	errorCodeCreatorTokenInvalid  = "creator_token_invalid"
+)
+
+// loadCreatorDecision resolves the {slug} route param and checks the
+// X-Creator-Token header against the token issued when the decision was
+// created, or the X-Account-Token header or session of an account the
+// decision is linked to. It writes the error response itself and reports
+// whether the caller may proceed.
+func (s *Server) loadCreatorDecision(w nethttp.ResponseWriter, r *nethttp.Request) (store.Decision, bool) {
+	slug, err := normalizeSlugParam(chi.URLParam(r, "slug"))
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return store.Decision{}, false
+	}
+
+	decision, err := s.decisions.BySlug(r.Context(), slug)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			writeProblem(w, nethttp.StatusNotFound, errorCodeDecisionNotFound, "decision not found")
+			return store.Decision{}, false
+		}
+		s.writeServerError(w, err, "failed to load decision")
+		return store.Decision{}, false
+	}
+
+	token := strings.TrimSpace(r.Header.Get("X-Creator-Token"))
+	session, _ := sessionFromContext(r.Context())
+	if token == "" && (r.Header.Get("X-Account-Token") != "" || session.AccountID != nil) {
+		return decision, s.requireAccountDecision(w, r, decision)
+	}
+	if token == "" {
+		writeProblem(w, nethttp.StatusUnauthorized, errorCodeCreatorTokenRequired, "missing creator token")
+		return store.Decision{}, false
+	}
+	if decision.CreatorTokenHash == nil || !tokenMatchesHash(token, *decision.CreatorTokenHash) {
+		writeProblem(w, nethttp.StatusForbidden, errorCodeCreatorTokenInvalid, "invalid creator token")
+		return store.Decision{}, false
+	}
+
+	auditFromContext(r.Context()).setActor(auditActorCreator, decision.ID.String())
+	return decision, true
+}
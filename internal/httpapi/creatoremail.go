@@ -0,0 +1,269 @@
+package httpapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	nethttp "net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/mailer"
+	"ratemylifedecision/internal/notify"
+)
+
+const (
+	// creatorEmailConfirmTTL is how long a confirmation link works.
+	creatorEmailConfirmTTL   = 7 * 24 * time.Hour
+	maxConfirmEmailBodyBytes = 1024
+	closeDigestBatchSize     = 20
+	maxCloseDigestAttempts   = 5
+	closeDigestTopComments   = 3
+	creatorEmailSendBudget   = 30 * time.Second
+	// closeDigestClaimLease hides a claimed digest from other instances
+	// and doubles as the delay before a failed send is retried.
+	closeDigestClaimLease = 5 * time.Minute
+)
+
+type confirmCreatorEmailRequest struct {
+	Token string `json:"token"`
+}
+
+type confirmCreatorEmailResponse struct {
+	Slug     string `json:"slug"`
+	ShareURL string `json:"share_url"`
+}
+
+func normalizeCreatorEmail(raw *string) (*string, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	email := strings.TrimSpace(*raw)
+	if email == "" {
+		return nil, nil
+	}
+	if err := validateChannelAddress(notify.ChannelEmail, email); err != nil {
+		return nil, errors.New("creator_email is invalid")
+	}
+	return &email, nil
+}
+
+// registerCreatorEmail stores the creator's email unconfirmed and mails the
+// confirmation link in the background. As with webhooks, a failure removes
+// the decision again.
+func (s *Server) registerCreatorEmail(ctx context.Context, decisionID uuid.UUID, title, email string) error {
+	token, err := newSecretToken()
+	if err == nil {
+		_, err = s.db.ExecContext(ctx, `
+			INSERT INTO creator_emails (decision_id, email, confirm_token_hash)
+			VALUES ($1, $2, $3)
+		`, decisionID, email, hashToken(token))
+	}
+	if err != nil {
+		if _, delErr := s.db.ExecContext(ctx, `DELETE FROM decisions WHERE id = $1`, decisionID); delErr != nil {
+			err = errors.Join(err, delErr)
+		}
+		return err
+	}
+
+	link := s.frontendBaseURL + "/confirm-email?token=" + url.QueryEscape(token)
+	msg := mailer.Message{
+		To:      email,
+		Subject: "Confirm your email for " + title,
+		Text: fmt.Sprintf(`You asked to be emailed the results of "%s" when it closes.
+
+Confirm your address to get them: %s
+
+The link works for 7 days. If this wasn't you, ignore this email and nothing more will be sent.`, title, link),
+	}
+	s.workers.Add(1)
+	go func() {
+		defer s.workers.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), creatorEmailSendBudget)
+		defer cancel()
+		if err := s.mailer.Send(ctx, msg); err != nil {
+			slog.Error("creator email confirmation failed", "decision_id", decisionID.String(), "error", err)
+		}
+	}()
+	return nil
+}
+
+func (s *Server) handleConfirmCreatorEmail(w nethttp.ResponseWriter, r *nethttp.Request) {
+	var req confirmCreatorEmailRequest
+	if err := decodeJSON(w, r, maxConfirmEmailBodyBytes, &req); err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+	token := strings.TrimSpace(req.Token)
+	if token == "" {
+		writeError(w, nethttp.StatusBadRequest, "token is required")
+		return
+	}
+
+	ctx, cancel := withBudget(r.Context(), writeQueryBudget)
+	defer cancel()
+	// Following the link again after confirming is harmless, even once it
+	// has expired.
+	var slug string
+	err := s.db.QueryRowContext(ctx, `
+		UPDATE creator_emails ce
+		SET confirmed_at = COALESCE(ce.confirmed_at, now())
+		FROM decisions d
+		WHERE d.id = ce.decision_id
+		  AND ce.confirm_token_hash = $1
+		  AND (ce.confirmed_at IS NOT NULL OR ce.created_at > now() - $2 * interval '1 second')
+		RETURNING d.slug
+	`, hashToken(token), creatorEmailConfirmTTL.Seconds()).Scan(&slug)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, nethttp.StatusNotFound, "confirmation link is invalid or has expired")
+			return
+		}
+		s.writeServerError(w, err, "failed to confirm email")
+		return
+	}
+	writeJSON(w, nethttp.StatusOK, confirmCreatorEmailResponse{Slug: slug, ShareURL: s.shareURL(slug)})
+}
+
+type closeDigest struct {
+	decisionID uuid.UUID
+	email      string
+	slug       string
+}
+
+// sendCloseDigests claims a batch of due digests and sends them. Claims use
+// FOR UPDATE SKIP LOCKED and a lease, so several instances can run this;
+// a digest is given up on after maxCloseDigestAttempts.
+func (s *Server) sendCloseDigests(ctx context.Context) error {
+	claimCtx, cancel := withBudget(ctx, writeQueryBudget)
+	rows, err := s.db.QueryContext(claimCtx, `
+		WITH due AS (
+			SELECT ce.decision_id FROM creator_emails ce
+			JOIN decisions d ON d.id = ce.decision_id
+			WHERE ce.confirmed_at IS NOT NULL
+			  AND ce.digest_sent_at IS NULL
+			  AND ce.digest_attempts < $2
+			  AND (ce.digest_claimed_at IS NULL OR ce.digest_claimed_at <= now() - $3 * interval '1 second')
+			  AND d.closes_at <= now()
+			LIMIT $1
+			FOR UPDATE OF ce SKIP LOCKED
+		)
+		UPDATE creator_emails ce
+		SET digest_attempts = ce.digest_attempts + 1, digest_claimed_at = now()
+		FROM due, decisions d
+		WHERE ce.decision_id = due.decision_id AND d.id = ce.decision_id
+		RETURNING ce.decision_id, ce.email, d.slug
+	`, closeDigestBatchSize, maxCloseDigestAttempts, closeDigestClaimLease.Seconds())
+	if err != nil {
+		cancel()
+		return err
+	}
+	var due []closeDigest
+	for rows.Next() {
+		var d closeDigest
+		if err := rows.Scan(&d.decisionID, &d.email, &d.slug); err != nil {
+			rows.Close()
+			cancel()
+			return err
+		}
+		due = append(due, d)
+	}
+	rows.Close()
+	cancel()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, d := range due {
+		sendErr := s.sendCloseDigest(ctx, d)
+		if sendErr != nil {
+			errs = append(errs, fmt.Errorf("digest for %s: %w", d.decisionID, sendErr))
+		}
+		if err := s.finishCloseDigest(ctx, d.decisionID, sendErr); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Server) sendCloseDigest(ctx context.Context, d closeDigest) error {
+	snapshot, _, _, err := s.loadDecisionView(ctx, d.slug, nil)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, creatorEmailSendBudget)
+	defer cancel()
+	return s.mailer.Send(ctx, mailer.Message{
+		To:      d.email,
+		Subject: "Results are in: " + snapshot.Decision.Title,
+		Text:    s.renderCloseDigest(snapshot),
+	})
+}
+
+func (s *Server) finishCloseDigest(ctx context.Context, decisionID uuid.UUID, sendErr error) error {
+	ctx, cancel := withBudget(ctx, writeQueryBudget)
+	defer cancel()
+	var err error
+	if sendErr == nil {
+		_, err = s.db.ExecContext(ctx, `
+			UPDATE creator_emails SET digest_sent_at = now(), digest_last_error = NULL WHERE decision_id = $1
+		`, decisionID)
+	} else {
+		_, err = s.db.ExecContext(ctx, `
+			UPDATE creator_emails SET digest_last_error = $2 WHERE decision_id = $1
+		`, decisionID, sendErr.Error())
+	}
+	if err != nil {
+		return fmt.Errorf("record close digest: %w", err)
+	}
+	return nil
+}
+
+func (s *Server) renderCloseDigest(snapshot decisionSnapshot) string {
+	decision, st, rec := snapshot.Decision, snapshot.Stats, snapshot.Recommendation
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "\"%s\" has closed", decision.Title)
+	if decision.ClosesAt != nil {
+		fmt.Fprintf(&b, " (%s)", decision.ClosesAt.UTC().Format("Jan 2, 2006 15:04 MST"))
+	}
+	if st.ResponseCount == 0 {
+		b.WriteString(". Nobody responded before it closed.\n")
+	} else {
+		fmt.Fprintf(&b, " with %d responses.\n\n", st.ResponseCount)
+		fmt.Fprintf(&b, "The crowd says: %s (score %+.2f)\n", verdictText(rec.Decision), rec.Score)
+		fmt.Fprintf(&b, "Average rating: %.1f/5", st.AvgRating)
+		if st.TopEmoji != "" {
+			fmt.Fprintf(&b, ", top reaction %s", st.TopEmoji)
+		}
+		fmt.Fprintf(&b, "\nDo it: %d · Don't do it: %d · Mixed: %d\n",
+			st.Categories.DoIt, st.Categories.DontDoIt, st.Categories.Mixed)
+		fmt.Fprintf(&b, "Post votes: %+d\n", snapshot.PostVote.Score)
+
+		if !decision.AggregateOnly {
+			var comments []string
+			for _, card := range snapshot.Responses {
+				if card.Comment == nil || strings.TrimSpace(*card.Comment) == "" {
+					continue
+				}
+				comments = append(comments, fmt.Sprintf("  %s %q", card.Emoji, strings.Join(strings.Fields(*card.Comment), " ")))
+				if len(comments) == closeDigestTopComments {
+					break
+				}
+			}
+			if len(comments) > 0 {
+				b.WriteString("\nTop comments:\n")
+				b.WriteString(strings.Join(comments, "\n"))
+				b.WriteString("\n")
+			}
+		}
+	}
+	fmt.Fprintf(&b, "\nSee the full results: %s\n", s.shareURL(decision.Slug))
+	return b.String()
+}
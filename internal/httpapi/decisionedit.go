@@ -0,0 +1,209 @@
+package httpapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	nethttp "net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/database"
+	"ratemylifedecision/internal/projections"
+)
+
+var errDecisionClosed = errors.New("decision is closed")
+
+// updateDecisionRequest is a partial update: fields left out, or null, are
+// kept as they are.
+type updateDecisionRequest struct {
+	// Description replaces the description; an empty one removes it.
+	Description *string `json:"description"`
+	// ClosesAt moves the closing time, earlier or later. It can't be
+	// removed once set.
+	ClosesAt *time.Time `json:"closes_at"`
+	// Category replaces the category; an empty one removes it.
+	Category *string `json:"category"`
+}
+
+// handleUpdateDecision lets the creator fix up a decision's description,
+// closing time and category while it is open. Values are normalized as on
+// creation. The title and options stay fixed: people have already answered
+// them.
+func (s *Server) handleUpdateDecision(w nethttp.ResponseWriter, r *nethttp.Request) {
+	decision, ok := s.loadCreatorDecision(w, r)
+	if !ok {
+		return
+	}
+
+	var req updateDecisionRequest
+	if err := decodeJSON(w, r, maxCreateDecisionBodyBytes, &req); err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Description == nil && req.ClosesAt == nil && req.Category == nil {
+		writeError(w, nethttp.StatusBadRequest, "nothing to update")
+		return
+	}
+
+	description, err := normalizeOptionalText(req.Description, descriptionMaxLength, "description", true)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+	closesAt, err := normalizeClosesAt(req.ClosesAt)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+	ctx := r.Context()
+	category, err := s.normalizeCategory(ctx, req.Category)
+	if err != nil {
+		var invalid invalidInputError
+		if errors.As(err, &invalid) {
+			writeError(w, nethttp.StatusBadRequest, invalid.Error())
+			return
+		}
+		s.writeServerError(w, err, "failed to load category")
+		return
+	}
+
+	err = s.updateDecision(ctx, decision.ID, req.Description != nil, description, closesAt, req.Category != nil, category)
+	if errors.Is(err, errDecisionClosed) {
+		writeProblem(w, nethttp.StatusConflict, errorCodeDecisionClosed, err.Error())
+		return
+	}
+	if err != nil {
+		s.writeServerError(w, err, "failed to update decision")
+		return
+	}
+	s.cache.Invalidate(decision.ID)
+
+	updated, err := s.decisions.BySlug(ctx, decision.Slug)
+	if err != nil {
+		s.writeServerError(w, err, "failed to load decision")
+		return
+	}
+	audit := auditFromContext(ctx)
+	audit.setBefore(decisionViewFromStore(decision))
+	audit.setAfter(decisionViewFromStore(updated))
+	writeJSON(w, nethttp.StatusOK, decisionViewFromStore(updated))
+
+	s.publishLiveUpdate(ctx, "decision_updated", decision.ID, nil)
+}
+
+type updateClosesAtRequest struct {
+	ClosesAt *time.Time `json:"closes_at"`
+}
+
+// handleUpdateClosesAt pushes back or brings forward an open decision's
+// closing time. It is the same change PATCH makes, for clients that only
+// manage deadlines.
+func (s *Server) handleUpdateClosesAt(w nethttp.ResponseWriter, r *nethttp.Request) {
+	decision, ok := s.loadCreatorDecision(w, r)
+	if !ok {
+		return
+	}
+
+	var req updateClosesAtRequest
+	if err := decodeJSON(w, r, maxCreateDecisionBodyBytes, &req); err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+	if req.ClosesAt == nil {
+		writeError(w, nethttp.StatusBadRequest, "closes_at is required")
+		return
+	}
+	closesAt, err := normalizeClosesAt(req.ClosesAt)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	err = s.updateDecision(ctx, decision.ID, false, nil, closesAt, false, nil)
+	if errors.Is(err, errDecisionClosed) {
+		writeProblem(w, nethttp.StatusConflict, errorCodeDecisionClosed, err.Error())
+		return
+	}
+	if err != nil {
+		s.writeServerError(w, err, "failed to update closing time")
+		return
+	}
+	s.cache.Invalidate(decision.ID)
+
+	updated, err := s.decisions.BySlug(ctx, decision.Slug)
+	if err != nil {
+		s.writeServerError(w, err, "failed to load decision")
+		return
+	}
+	audit := auditFromContext(ctx)
+	audit.setBefore(decisionViewFromStore(decision))
+	audit.setAfter(decisionViewFromStore(updated))
+	writeJSON(w, nethttp.StatusOK, decisionViewFromStore(updated))
+
+	s.publishLiveUpdate(ctx, "decision_updated", decision.ID, nil)
+}
+
+// updateDecision applies an update to an open decision. A new closing time
+// is recorded in decision_deadline_changes and clears any close reminder
+// already sent, so the new deadline gets one of its own; a new category is
+// recorded for the read models.
+func (s *Server) updateDecision(ctx context.Context, id uuid.UUID, setDescription bool, description *string, closesAt *time.Time, setCategory bool, category *string) error {
+	ctx, cancel := withBudget(ctx, writeQueryBudget)
+	defer cancel()
+
+	return database.RetryTx(ctx, s.db, func(tx *sql.Tx) error {
+		var (
+			previousCategory *string
+			previousClosesAt *time.Time
+		)
+		err := tx.QueryRowContext(ctx, `
+			SELECT category, closes_at FROM decisions
+			WHERE id = $1 AND closed_at IS NULL AND (closes_at IS NULL OR closes_at > now())
+			FOR UPDATE
+		`, id).Scan(&previousCategory, &previousClosesAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return errDecisionClosed
+		}
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE decisions SET
+				description = CASE WHEN $2::bool THEN $3 ELSE description END,
+				closes_at = COALESCE($4, closes_at),
+				category = CASE WHEN $5::bool THEN $6 ELSE category END
+			WHERE id = $1
+		`, id, setDescription, description, closesAt, setCategory, category); err != nil {
+			return err
+		}
+
+		if closesAt != nil && (previousClosesAt == nil || !previousClosesAt.Equal(*closesAt)) {
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO decision_deadline_changes (decision_id, previous_closes_at, closes_at)
+				VALUES ($1, $2, $3)
+			`, id, previousClosesAt, closesAt); err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, `
+				DELETE FROM decision_reminders WHERE decision_id = $1
+			`, id); err != nil {
+				return err
+			}
+		}
+		if setCategory && !sameCategory(previousCategory, category) {
+			return projections.Append(ctx, tx, id, projections.KindCategoryChanged, projections.CategoryChanged{Category: category})
+		}
+		return nil
+	})
+}
+
+func sameCategory(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
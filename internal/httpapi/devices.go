@@ -0,0 +1,322 @@
+package httpapi
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/notify"
+)
+
+const (
+	maxDeviceBodyBytes  = 8 * 1024
+	maxPushTokenLength  = 4096
+	minAPNsTokenLength  = 64
+	maxAPNsTokenLength  = 200
+	maxDevicesPerViewer = 20
+)
+
+type registerDeviceRequest struct {
+	ViewerToken string `json:"viewer_token"`
+	// ViewerID is no longer accepted; kept so old clients get a clear 401.
+	ViewerID string   `json:"viewer_id"`
+	Platform string   `json:"platform"`
+	Token    string   `json:"token"`
+	Events   []string `json:"events"`
+}
+
+type updateDeviceRequest struct {
+	Events  []string `json:"events"`
+	Enabled *bool    `json:"enabled"`
+}
+
+type deviceView struct {
+	ID            string     `json:"id"`
+	Platform      string     `json:"platform"`
+	Events        []string   `json:"events"`
+	Enabled       bool       `json:"enabled"`
+	InvalidatedAt *time.Time `json:"invalidated_at"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type registerDeviceResponse struct {
+	deviceView
+	Secret string `json:"secret"`
+}
+
+// handleRegisterDevice binds an APNs or FCM token to a viewer. Registering a
+// token that already exists (e.g. after an app reinstall) rebinds it,
+// rotates its secret, and clears any earlier invalidation. The returned id
+// is what push subscription channels use as their address.
+func (s *Server) handleRegisterDevice(w nethttp.ResponseWriter, r *nethttp.Request) {
+	if !s.notifier.Enabled(notify.ChannelPush) {
+		writeError(w, nethttp.StatusNotFound, "push notifications are not available on this server")
+		return
+	}
+
+	var req registerDeviceRequest
+	if err := decodeJSON(w, r, maxDeviceBodyBytes, &req); err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	viewer, ok := s.requireViewer(w, r, req.ViewerToken, req.ViewerID)
+	if !ok {
+		return
+	}
+	platform, ok := notify.ParsePushPlatform(strings.TrimSpace(req.Platform))
+	if !ok {
+		writeError(w, nethttp.StatusBadRequest, "platform must be apns or fcm")
+		return
+	}
+	token, err := normalizePushToken(platform, req.Token)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+	var events []string
+	if req.Events != nil {
+		if events, err = normalizeNotificationEvents(req.Events); err != nil {
+			writeError(w, nethttp.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	secret, err := newSecretToken()
+	if err != nil {
+		s.writeServerError(w, err, "failed to register device")
+		return
+	}
+
+	ctx := r.Context()
+	var deviceCount int
+	if err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)::int FROM push_devices
+		WHERE viewer_id = $1 AND NOT (platform = $2 AND token = $3)
+	`, viewer.ID, string(platform), token).Scan(&deviceCount); err != nil {
+		s.writeServerError(w, err, "failed to register device")
+		return
+	}
+	if deviceCount >= maxDevicesPerViewer {
+		writeError(w, nethttp.StatusConflict, fmt.Sprintf("at most %d devices can be registered", maxDevicesPerViewer))
+		return
+	}
+
+	row := s.db.QueryRowContext(ctx, `
+		INSERT INTO push_devices (id, viewer_id, platform, token, secret_hash, events)
+		VALUES ($1, $2, $3, $4, $5, COALESCE($6::text[], ARRAY['decision_closed', 'milestone', 'reminder']))
+		ON CONFLICT (platform, token) DO UPDATE SET
+			viewer_id = EXCLUDED.viewer_id,
+			secret_hash = EXCLUDED.secret_hash,
+			events = COALESCE($6::text[], push_devices.events),
+			enabled = true,
+			invalidated_at = NULL,
+			invalid_reason = NULL,
+			updated_at = now()
+		RETURNING `+deviceColumns+`
+	`, uuid.New(), viewer.ID, string(platform), token, hashToken(secret), events)
+	view, err := scanDeviceView(row)
+	if err != nil {
+		s.writeServerError(w, err, "failed to register device")
+		return
+	}
+
+	writeJSON(w, nethttp.StatusCreated, registerDeviceResponse{deviceView: view, Secret: secret})
+}
+
+func (s *Server) handleUpdateDevice(w nethttp.ResponseWriter, r *nethttp.Request) {
+	deviceID, ok := s.authorizeDevice(w, r)
+	if !ok {
+		return
+	}
+
+	var req updateDeviceRequest
+	if err := decodeJSON(w, r, maxDeviceBodyBytes, &req); err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+	if req.Events == nil && req.Enabled == nil {
+		writeError(w, nethttp.StatusBadRequest, "events or enabled is required")
+		return
+	}
+	var events []string
+	if req.Events != nil {
+		var err error
+		if events, err = normalizeNotificationEvents(req.Events); err != nil {
+			writeError(w, nethttp.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	row := s.db.QueryRowContext(r.Context(), `
+		UPDATE push_devices SET
+			events = COALESCE($2::text[], events),
+			enabled = COALESCE($3, enabled),
+			updated_at = now()
+		WHERE id = $1
+		RETURNING `+deviceColumns+`
+	`, deviceID, events, req.Enabled)
+	view, err := scanDeviceView(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, nethttp.StatusNotFound, "device not found")
+			return
+		}
+		s.writeServerError(w, err, "failed to update device")
+		return
+	}
+
+	writeJSON(w, nethttp.StatusOK, view)
+}
+
+func (s *Server) handleDeleteDevice(w nethttp.ResponseWriter, r *nethttp.Request) {
+	deviceID, ok := s.authorizeDevice(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.writeServerError(w, err, "failed to delete device")
+		return
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM notification_subscription_channels
+		WHERE channel = 'push' AND address = $1::text
+	`, deviceID.String()); err != nil {
+		s.writeServerError(w, err, "failed to delete device")
+		return
+	}
+	if _, err := tx.ExecContext(ctx, `
+		DELETE FROM push_devices WHERE id = $1
+	`, deviceID); err != nil {
+		s.writeServerError(w, err, "failed to delete device")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		s.writeServerError(w, err, "failed to delete device")
+		return
+	}
+
+	w.WriteHeader(nethttp.StatusNoContent)
+}
+
+const deviceColumns = `id, platform, to_jsonb(events), enabled, invalidated_at, created_at`
+
+func scanDeviceView(row *sql.Row) (deviceView, error) {
+	var (
+		view       deviceView
+		eventsJSON []byte
+	)
+	if err := row.Scan(&view.ID, &view.Platform, &eventsJSON, &view.Enabled, &view.InvalidatedAt, &view.CreatedAt); err != nil {
+		return deviceView{}, err
+	}
+	if err := json.Unmarshal(eventsJSON, &view.Events); err != nil {
+		return deviceView{}, err
+	}
+	return view, nil
+}
+
+func (s *Server) authorizeDevice(w nethttp.ResponseWriter, r *nethttp.Request) (uuid.UUID, bool) {
+	deviceID, err := uuid.Parse(strings.TrimSpace(chi.URLParam(r, "id")))
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, "device id must be a valid UUID")
+		return uuid.Nil, false
+	}
+
+	secret := strings.TrimSpace(r.Header.Get("X-Device-Secret"))
+	if secret == "" {
+		writeError(w, nethttp.StatusUnauthorized, "missing device secret")
+		return uuid.Nil, false
+	}
+
+	var storedHash string
+	err = s.db.QueryRowContext(r.Context(), `
+		SELECT secret_hash FROM push_devices WHERE id = $1
+	`, deviceID).Scan(&storedHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, nethttp.StatusNotFound, "device not found")
+			return uuid.Nil, false
+		}
+		s.writeServerError(w, err, "failed to load device")
+		return uuid.Nil, false
+	}
+	if !tokenMatchesHash(secret, storedHash) {
+		writeError(w, nethttp.StatusForbidden, "invalid device secret")
+		return uuid.Nil, false
+	}
+	return deviceID, true
+}
+
+// ensurePushDevicesExist rejects push channels that point at a device that
+// was never registered or has since been invalidated.
+func (s *Server) ensurePushDevicesExist(ctx context.Context, channels []subscriptionChannelView) error {
+	for _, c := range channels {
+		if c.Channel != string(notify.ChannelPush) || c.Address == "" {
+			continue
+		}
+		var active bool
+		err := s.db.QueryRowContext(ctx, `
+			SELECT invalidated_at IS NULL FROM push_devices WHERE id = $1
+		`, c.Address).Scan(&active)
+		if errors.Is(err, sql.ErrNoRows) {
+			return errPushDeviceUnknown
+		}
+		if err != nil {
+			return err
+		}
+		if !active {
+			return errPushDeviceInvalidated
+		}
+	}
+	return nil
+}
+
+var (
+	errPushDeviceUnknown     = errors.New("push address must be a registered device id")
+	errPushDeviceInvalidated = errors.New("push device is no longer valid; register it again")
+)
+
+func normalizePushToken(platform notify.PushPlatform, raw string) (string, error) {
+	token := strings.TrimSpace(raw)
+	if token == "" {
+		return "", errors.New("token is required")
+	}
+	if len(token) > maxPushTokenLength {
+		return "", errors.New("token is too long")
+	}
+
+	switch platform {
+	case notify.PlatformAPNs:
+		if len(token) < minAPNsTokenLength || len(token) > maxAPNsTokenLength {
+			return "", errors.New("apns token must be a hex device token")
+		}
+		for _, r := range token {
+			if !(r >= '0' && r <= '9') && !(r >= 'a' && r <= 'f') && !(r >= 'A' && r <= 'F') {
+				return "", errors.New("apns token must be a hex device token")
+			}
+		}
+		return strings.ToLower(token), nil
+	case notify.PlatformFCM:
+		for _, r := range token {
+			if !(r >= '0' && r <= '9') && !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') &&
+				r != '-' && r != '_' && r != ':' {
+				return "", errors.New("fcm token contains invalid characters")
+			}
+		}
+		return token, nil
+	}
+	return "", errors.New("platform must be apns or fcm")
+}
@@ -0,0 +1,49 @@
+package httpapi
+
+import (
+	nethttp "net/http"
+	"time"
+)
+
+type duplicateDecisionRequest struct {
+	// ClosesAt is the copy's closing time; the copy stays open if it is
+	// left out.
+	ClosesAt *time.Time `json:"closes_at"`
+}
+
+// handleDuplicateDecision lets the creator ask the same question again from
+// scratch: a new decision with the same title, description, category and
+// settings but none of the responses, votes, webhooks or creator email. A
+// private original gets a private copy with a new access code.
+func (s *Server) handleDuplicateDecision(w nethttp.ResponseWriter, r *nethttp.Request) {
+	decision, ok := s.loadCreatorDecision(w, r)
+	if !ok {
+		return
+	}
+
+	var req duplicateDecisionRequest
+	if r.ContentLength != 0 {
+		if err := decodeJSON(w, r, maxCreateDecisionBodyBytes, &req); err != nil {
+			writeError(w, nethttp.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	visibility := decision.Visibility
+	out, err := s.createDecision(r.Context(), createDecisionRequest{
+		Title:          decision.Title,
+		Description:    decision.Description,
+		ClosesAt:       req.ClosesAt,
+		Category:       decision.Category,
+		AggregateOnly:  decision.AggregateOnly,
+		Quorum:         decision.Quorum,
+		Visibility:     &visibility,
+		MaxResponses:   decision.MaxResponses,
+		NicknamePolicy: &decision.NicknamePolicy,
+	})
+	if err != nil {
+		s.writeCreateDecisionError(w, err)
+		return
+	}
+	writeJSON(w, nethttp.StatusCreated, out)
+}
@@ -0,0 +1,201 @@
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	nethttp "net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"ratemylifedecision/internal/store"
+)
+
+const (
+	embedDefaultWidth  = 400
+	embedDefaultHeight = 220
+	embedMinWidth      = 200
+	embedMinHeight     = 120
+	oembedCacheAge     = 300
+)
+
+// embedCard is the compact payload behind embedded decision cards. Embeds
+// poll it, so it is served from the decision cache like the full view.
+type embedCard struct {
+	Slug           string     `json:"slug"`
+	Title          string     `json:"title"`
+	ShareURL       string     `json:"share_url"`
+	TopEmoji       string     `json:"top_emoji"`
+	Recommendation string     `json:"recommendation"`
+	Score          float64    `json:"score"`
+	ResponseCount  int        `json:"response_count"`
+	ClosesAt       *time.Time `json:"closes_at"`
+}
+
+type oembedResponse struct {
+	Version      string `json:"version"`
+	Type         string `json:"type"`
+	ProviderName string `json:"provider_name"`
+	ProviderURL  string `json:"provider_url"`
+	Title        string `json:"title"`
+	HTML         string `json:"html"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	CacheAge     int    `json:"cache_age"`
+}
+
+// publicCORSMiddleware lets any site read the response. It is only for
+// public, credential-free GETs such as embeds; third-party pages fetch them
+// as simple requests, so no preflight is involved.
+func publicCORSMiddleware(next nethttp.Handler) nethttp.Handler {
+	return nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Del("Access-Control-Allow-Credentials")
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleDecisionEmbed(w nethttp.ResponseWriter, r *nethttp.Request) {
+	slug, err := normalizeSlugParam(chi.URLParam(r, "slug"))
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	snapshot, _, _, err := s.loadDecisionView(r.Context(), slug, nil)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			writeProblem(w, nethttp.StatusNotFound, errorCodeDecisionNotFound, "decision not found")
+			return
+		}
+		s.writeServerError(w, err, "failed to load decision")
+		return
+	}
+
+	if !requireDecisionAccess(w, r, snapshot.Decision) {
+		return
+	}
+	snapshot, _ = withholdUntilQuorum(snapshot, time.Now())
+	decision := snapshot.Decision
+	if decision.Visibility == visibilityPrivate {
+		w.Header().Set("Cache-Control", "private, max-age=15")
+	} else {
+		w.Header().Set("Cache-Control", "public, max-age=15")
+	}
+	writeJSON(w, nethttp.StatusOK, embedCard{
+		Slug:           decision.Slug,
+		Title:          decision.Title,
+		ShareURL:       s.shareURL(decision.Slug),
+		TopEmoji:       snapshot.Stats.TopEmoji,
+		Recommendation: snapshot.Recommendation.Decision,
+		Score:          snapshot.Recommendation.Score,
+		ResponseCount:  snapshot.Stats.ResponseCount,
+		ClosesAt:       decision.ClosesAt,
+	})
+}
+
+// handleOEmbed implements the oEmbed provider endpoint for decision share
+// links (FRONTEND_BASE_URL/d/{slug}). Only the JSON format is offered.
+func (s *Server) handleOEmbed(w nethttp.ResponseWriter, r *nethttp.Request) {
+	query := r.URL.Query()
+	switch strings.TrimSpace(query.Get("format")) {
+	case "", "json":
+	default:
+		writeError(w, nethttp.StatusNotImplemented, "only the json format is supported")
+		return
+	}
+
+	slug, err := s.slugFromShareURL(query.Get("url"))
+	if err != nil {
+		writeError(w, nethttp.StatusNotFound, err.Error())
+		return
+	}
+	width, err := parseEmbedSize(query.Get("maxwidth"), embedDefaultWidth, embedMinWidth, "maxwidth")
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+	height, err := parseEmbedSize(query.Get("maxheight"), embedDefaultHeight, embedMinHeight, "maxheight")
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	decision, err := s.decisions.BySlug(r.Context(), slug)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			writeProblem(w, nethttp.StatusNotFound, errorCodeDecisionNotFound, "decision not found")
+			return
+		}
+		s.writeServerError(w, err, "failed to load decision")
+		return
+	}
+	// oEmbed consumers fetch on their own, without the access code, so a
+	// private decision is never embeddable; the spec's answer is 401.
+	if decision.Visibility == visibilityPrivate {
+		writeProblem(w, nethttp.StatusUnauthorized, errorCodeDecisionPrivate, "private decisions cannot be embedded")
+		return
+	}
+
+	src := s.frontendBaseURL + "/embed/" + url.PathEscape(decision.Slug)
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", oembedCacheAge))
+	writeJSON(w, nethttp.StatusOK, oembedResponse{
+		Version:      "1.0",
+		Type:         "rich",
+		ProviderName: "RateMyLifeDecision",
+		ProviderURL:  s.frontendBaseURL + "/",
+		Title:        decision.Title,
+		HTML: fmt.Sprintf(`<iframe src="%s" width="%d" height="%d" title="%s" frameborder="0" scrolling="no" loading="lazy"></iframe>`,
+			html.EscapeString(src), width, height, html.EscapeString(decision.Title)),
+		Width:    width,
+		Height:   height,
+		CacheAge: oembedCacheAge,
+	})
+}
+
+// slugFromShareURL accepts the share and embed URLs this deployment hands
+// out and nothing else, as oEmbed providers must.
+func (s *Server) slugFromShareURL(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", errors.New("url is required")
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", errors.New("url is not a decision link")
+	}
+	base, err := url.Parse(s.frontendBaseURL)
+	if err != nil || !strings.EqualFold(u.Host, base.Host) {
+		return "", errors.New("url is not a decision link")
+	}
+
+	path := strings.TrimPrefix(u.Path, strings.TrimRight(base.Path, "/"))
+	for _, prefix := range []string{"/d/", "/embed/"} {
+		if rest, ok := strings.CutPrefix(path, prefix); ok {
+			slug, err := normalizeSlugParam(strings.TrimRight(rest, "/"))
+			if err != nil {
+				return "", errors.New("url is not a decision link")
+			}
+			return slug, nil
+		}
+	}
+	return "", errors.New("url is not a decision link")
+}
+
+// parseEmbedSize honours a consumer's maxwidth/maxheight by shrinking the
+// default size, never growing it.
+func parseEmbedSize(raw string, fallback, minimum int, name string) (int, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < minimum {
+		return 0, fmt.Errorf("%s must be an integer of at least %d", name, minimum)
+	}
+	return min(n, fallback), nil
+}
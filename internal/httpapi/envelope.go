@@ -0,0 +1,87 @@
+package httpapi
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/store"
+)
+
+// loadDecisionView returns the shared snapshot plus the viewer's own state.
+// A cache hit costs one round trip for the viewer state. A miss reads the
+// view, which carries only the newest cards, and then the recommendation
+// inputs of every response for the timeline, languages and recommendation.
+// Closed decisions also read their frozen results on a miss.
+func (s *Server) loadDecisionView(ctx context.Context, slug string, viewerID *uuid.UUID) (decisionSnapshot, int, bool, error) {
+	ctx, cancel := withBudget(ctx, statsQueryBudget)
+	defer cancel()
+
+	if snapshot, ok := s.cache.Get(slug, time.Now()); ok {
+		myVote, responded, err := s.loadViewerState(ctx, snapshot.Decision.ID, viewerID)
+		if err != nil {
+			return decisionSnapshot{}, 0, false, err
+		}
+		return snapshot, myVote, responded, nil
+	}
+
+	view, err := s.decisions.View(ctx, slug, viewerID)
+	if err != nil {
+		return decisionSnapshot{}, 0, false, err
+	}
+
+	in, err := s.responses.RecommendationInputs(ctx, view.Decision.ID)
+	if err != nil {
+		return decisionSnapshot{}, 0, false, err
+	}
+
+	snapshot := decisionSnapshot{
+		Decision:      view.Decision,
+		Responses:     make([]responseCard, 0, len(view.Responses)),
+		MoreResponses: view.MoreResponses,
+	}
+	for _, r := range view.Responses {
+		snapshot.Responses = append(snapshot.Responses, responseCardFromStore(r))
+	}
+	rankResponseFeed(snapshot.Responses)
+
+	row := view.Stats
+	snapshot.Stats = decisionStatsFromRow(row)
+	snapshot.Stats.Timeline = buildResponseTimeline(in.Responses)
+	snapshot.Stats.Languages = buildLanguageBreakdown(in.Responses)
+	snapshot.Recommendation = computeRecommendation(recommendationInputs(in.Responses), row.VoteSum, row.VoteCount, view.Decision.PanelOnly, s.cfg.Recommendation)
+	snapshot.PostVote = decisionVoteSummary{
+		Score:     row.VoteSum,
+		Upvotes:   row.Upvotes,
+		Downvotes: row.Downvotes,
+	}
+	if view.Decision.ClosedAt != nil {
+		if err := s.applyFrozenResults(ctx, &snapshot); err != nil {
+			return decisionSnapshot{}, 0, false, err
+		}
+	}
+
+	s.cache.Put(snapshot, time.Now())
+	return snapshot, view.MyVote, view.Responded, nil
+}
+
+func responseCardFromStore(r store.Response) responseCard {
+	reactions := r.Reactions
+	if reactions == nil {
+		reactions = map[string]int{}
+	}
+	return responseCard{
+		ID:          r.ID.String(),
+		Rating:      r.Rating,
+		Suggestion:  r.Suggestion,
+		Emoji:       r.Emoji,
+		Comment:     r.Comment,
+		Language:    r.Language,
+		CreatedAt:   r.CreatedAt,
+		EditedAt:    r.EditedAt,
+		PanelMember: r.PanelMember,
+		Nickname:    r.Nickname,
+		Reactions:   reactions,
+	}
+}
@@ -0,0 +1,51 @@
+package httpapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/store"
+)
+
+// decisionETag derives a weak validator from the decision's revision
+// counter, which database triggers bump on every response, vote, and panel
+// change. The viewer is folded in because my_vote and viewer_has_responded
+// make the body viewer-specific.
+func decisionETag(revision int64, viewerID *uuid.UUID) string {
+	viewer := "anon"
+	if viewerID != nil {
+		sum := sha256.Sum256(viewerID[:])
+		viewer = hex.EncodeToString(sum[:6])
+	}
+	return fmt.Sprintf(`W/"r%d-%s"`, revision, viewer)
+}
+
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || weakETagValue(candidate) == weakETagValue(etag) {
+			return true
+		}
+	}
+	return false
+}
+
+func weakETagValue(etag string) string {
+	return strings.TrimPrefix(etag, "W/")
+}
+
+// currentDecisionRevision returns the decision with at least its revision
+// and access fields set; see store.DecisionStore.Revision.
+func (s *Server) currentDecisionRevision(ctx context.Context, slug string) (store.Decision, error) {
+	if snapshot, ok := s.cache.Get(slug, time.Now()); ok {
+		return snapshot.Decision, nil
+	}
+
+	return s.decisions.Revision(ctx, slug)
+}
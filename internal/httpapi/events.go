@@ -0,0 +1,185 @@
+package httpapi
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"ratemylifedecision/internal/store"
+)
+
+const (
+	sseHeartbeatInterval = 15 * time.Second
+	sseRetryMillis       = 3000
+)
+
+type sseStream struct {
+	w       nethttp.ResponseWriter
+	flusher nethttp.Flusher
+}
+
+func (st sseStream) send(id, event string, data []byte) error {
+	var b strings.Builder
+	if id != "" {
+		fmt.Fprintf(&b, "id: %s\n", id)
+	}
+	fmt.Fprintf(&b, "event: %s\n", event)
+	fmt.Fprintf(&b, "data: %s\n\n", data)
+	if _, err := st.w.Write([]byte(b.String())); err != nil {
+		return err
+	}
+	st.flusher.Flush()
+	return nil
+}
+
+func (st sseStream) comment(text string) error {
+	if _, err := fmt.Fprintf(st.w, ": %s\n\n", text); err != nil {
+		return err
+	}
+	st.flusher.Flush()
+	return nil
+}
+
+// sseState tracks what a single client has already seen so only deltas are
+// emitted. Its id is a digest of the stats and recommendation, which lets a
+// reconnecting client skip the initial replay when its Last-Event-ID is
+// still current.
+type sseState struct {
+	stats          []byte
+	recommendation []byte
+}
+
+func newSSEState(event liveEvent) (sseState, error) {
+	stats, err := json.Marshal(event.Stats)
+	if err != nil {
+		return sseState{}, err
+	}
+	recommendation, err := json.Marshal(event.Recommendation)
+	if err != nil {
+		return sseState{}, err
+	}
+	return sseState{stats: stats, recommendation: recommendation}, nil
+}
+
+func (st sseState) id() string {
+	sum := sha256.New()
+	sum.Write(st.stats)
+	sum.Write(st.recommendation)
+	return hex.EncodeToString(sum.Sum(nil)[:8])
+}
+
+func (s *Server) handleDecisionEvents(w nethttp.ResponseWriter, r *nethttp.Request) {
+	slug, err := normalizeSlugParam(chi.URLParam(r, "slug"))
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	flusher, ok := w.(nethttp.Flusher)
+	if !ok {
+		writeError(w, nethttp.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	ctx := r.Context()
+	decision, err := s.decisions.BySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			writeProblem(w, nethttp.StatusNotFound, errorCodeDecisionNotFound, "decision not found")
+			return
+		}
+		s.writeServerError(w, err, "failed to load decision")
+		return
+	}
+	if !requireDecisionAccess(w, r, decision) {
+		return
+	}
+
+	sub := s.hub.Subscribe(decision.ID)
+	defer s.hub.Unsubscribe(decision.ID, sub)
+
+	snapshot, err := s.buildLiveEvent(ctx, "snapshot", decision.ID, liveChange{})
+	if err != nil {
+		s.writeServerError(w, err, "failed to load decision stats")
+		return
+	}
+	current, err := newSSEState(snapshot)
+	if err != nil {
+		s.writeServerError(w, err, "failed to encode decision stats")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(nethttp.StatusOK)
+
+	stream := sseStream{w: w, flusher: flusher}
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", sseRetryMillis); err != nil {
+		return
+	}
+
+	var seen sseState
+	if strings.TrimSpace(r.Header.Get("Last-Event-ID")) == current.id() {
+		seen = current
+	}
+	if err := emitSSEDeltas(stream, &seen, current); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.shutdown.Done():
+			// The client reconnects after the retry interval, by then to
+			// another instance or to this one after restart.
+			return
+		case msg, ok := <-sub.events:
+			if !ok {
+				// Dropped for falling behind; the client reconnects and
+				// resumes from its Last-Event-ID.
+				return
+			}
+			next, err := newSSEState(msg.event)
+			if err != nil {
+				continue
+			}
+			if err := emitSSEDeltas(stream, &seen, next); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if err := stream.comment("heartbeat"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func emitSSEDeltas(stream sseStream, seen *sseState, next sseState) error {
+	id := next.id()
+	if !bytes.Equal(seen.stats, next.stats) {
+		if err := stream.send(id, "stats", next.stats); err != nil {
+			return err
+		}
+	}
+	if !bytes.Equal(seen.recommendation, next.recommendation) {
+		if err := stream.send(id, "recommendation", next.recommendation); err != nil {
+			return err
+		}
+	}
+	*seen = next
+	return nil
+}
@@ -0,0 +1,300 @@
+package httpapi
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log/slog"
+	nethttp "net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/store"
+)
+
+const errorCodeAggregateOnly = "aggregate_only"
+
+// decisionArchiveVersion is bumped whenever the archive layout changes in a
+// way an importer would need to know about.
+const decisionArchiveVersion = 1
+
+type decisionArchive struct {
+	ArchiveVersion int                 `json:"archive_version"`
+	ExportedAt     time.Time           `json:"exported_at"`
+	Decision       decisionView        `json:"decision"`
+	Responses      []responseCard      `json:"responses"`
+	Votes          []archivedVote      `json:"votes"`
+	Stats          decisionStats       `json:"stats"`
+	StatsHistory   []statsHistoryPoint `json:"stats_history"`
+	Recommendation recommendationView  `json:"recommendation"`
+	Outcome        *outcomeView        `json:"outcome"`
+}
+
+type archivedVote struct {
+	Value     int       `json:"value"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// statsHistoryPoint is one UTC day of activity. The cumulative fields are
+// the totals as of the end of that day.
+type statsHistoryPoint struct {
+	Day                 string  `json:"day"`
+	Responses           int     `json:"responses"`
+	Upvotes             int     `json:"upvotes"`
+	Downvotes           int     `json:"downvotes"`
+	CumulativeResponses int     `json:"cumulative_responses"`
+	CumulativeAvgRating float64 `json:"cumulative_avg_rating"`
+	CumulativeVoteScore int     `json:"cumulative_vote_score"`
+}
+
+// handleExportResponsesCSV streams a decision's visible responses, oldest
+// first, for the creator to open in a spreadsheet. Aggregate-only decisions
+// promised responders their individual answers stay private, so they have
+// nothing to export.
+func (s *Server) handleExportResponsesCSV(w nethttp.ResponseWriter, r *nethttp.Request) {
+	decision, ok := s.loadCreatorDecision(w, r)
+	if !ok {
+		return
+	}
+	if decision.AggregateOnly {
+		writeProblem(w, nethttp.StatusConflict, errorCodeAggregateOnly, "individual responses are not available for aggregate-only decisions")
+		return
+	}
+
+	ctx := r.Context()
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT rating, suggestion, emoji, comment, created_at
+		FROM responses
+		WHERE decision_id = $1 AND hidden_at IS NULL AND NOT shadowed
+		ORDER BY created_at, id
+	`, decision.ID)
+	if err != nil {
+		s.writeServerError(w, err, "failed to load responses")
+		return
+	}
+	defer rows.Close()
+
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-responses.csv"`, decision.Slug))
+	w.Header().Set("Cache-Control", "no-store")
+
+	// Headers are sent with the first row, so a failure after that can only
+	// cut the file short.
+	out := csv.NewWriter(w)
+	_ = out.Write([]string{"rating", "suggestion", "emoji", "comment", "created_at"})
+	for rows.Next() {
+		var (
+			rating, suggestion int
+			emoji              string
+			comment            *string
+			createdAt          time.Time
+		)
+		if err := rows.Scan(&rating, &suggestion, &emoji, &comment, &createdAt); err != nil {
+			slog.Error("csv export aborted", "decision_id", decision.ID, "error", err)
+			return
+		}
+		text := ""
+		if comment != nil {
+			text = escapeSpreadsheetFormula(*comment)
+		}
+		if err := out.Write([]string{
+			strconv.Itoa(rating),
+			strconv.Itoa(suggestion),
+			emoji,
+			text,
+			createdAt.UTC().Format(time.RFC3339),
+		}); err != nil {
+			return
+		}
+	}
+	if err := rows.Err(); err != nil {
+		slog.Error("csv export aborted", "decision_id", decision.ID, "error", err)
+	}
+	out.Flush()
+}
+
+// escapeSpreadsheetFormula stops a comment such as "=HYPERLINK(...)" from
+// being evaluated when the export is opened in a spreadsheet.
+func escapeSpreadsheetFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// handleExportDecisionArchive returns everything about a decision as one
+// JSON document for the creator to back up or move elsewhere. Voter and
+// responder identities are left out, and aggregate-only decisions export no
+// individual responses, the same as on the decision page.
+func (s *Server) handleExportDecisionArchive(w nethttp.ResponseWriter, r *nethttp.Request) {
+	decision, ok := s.loadCreatorDecision(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	snapshot, _, _, err := s.loadDecisionView(ctx, decision.Slug, nil)
+	if err != nil {
+		s.writeServerError(w, err, "failed to load decision")
+		return
+	}
+
+	responses, err := s.loadArchivedResponses(ctx, decision)
+	if err != nil {
+		s.writeServerError(w, err, "failed to load responses")
+		return
+	}
+
+	votes, err := s.loadArchivedVotes(ctx, decision.ID)
+	if err != nil {
+		s.writeServerError(w, err, "failed to load votes")
+		return
+	}
+	history, err := s.loadStatsHistory(ctx, decision.ID)
+	if err != nil {
+		s.writeServerError(w, err, "failed to load stats history")
+		return
+	}
+	outcome, err := s.loadOutcome(ctx, decision.ID)
+	if err != nil {
+		s.writeServerError(w, err, "failed to load outcome")
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, decision.Slug))
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, nethttp.StatusOK, decisionArchive{
+		ArchiveVersion: decisionArchiveVersion,
+		ExportedAt:     time.Now().UTC(),
+		Decision:       decisionViewFromStore(decision),
+		Responses:      responses,
+		Votes:          votes,
+		Stats:          snapshot.Stats,
+		StatsHistory:   history,
+		Recommendation: snapshot.Recommendation,
+		Outcome:        outcome,
+	})
+}
+
+// loadArchivedResponses returns every visible card, oldest first. The
+// snapshot only carries the newest ones.
+func (s *Server) loadArchivedResponses(ctx context.Context, decision store.Decision) ([]responseCard, error) {
+	if decision.AggregateOnly {
+		return []responseCard{}, nil
+	}
+	ctx, cancel := withBudget(ctx, statsQueryBudget)
+	defer cancel()
+
+	responses, err := s.loadAllResponses(ctx, decision.ID)
+	if err != nil {
+		return nil, err
+	}
+	slices.Reverse(responses)
+	return responses, nil
+}
+
+func (s *Server) loadArchivedVotes(ctx context.Context, decisionID uuid.UUID) ([]archivedVote, error) {
+	ctx, cancel := withBudget(ctx, statsQueryBudget)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT value, created_at
+		FROM decision_votes
+		WHERE decision_id = $1 AND NOT shadowed
+		ORDER BY created_at, id
+	`, decisionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	votes := make([]archivedVote, 0, 16)
+	for rows.Next() {
+		var v archivedVote
+		if err := rows.Scan(&v.Value, &v.CreatedAt); err != nil {
+			return nil, err
+		}
+		votes = append(votes, v)
+	}
+	return votes, rows.Err()
+}
+
+// loadStatsHistory rebuilds the decision's daily activity from the rows
+// themselves; there is no stored history to read.
+func (s *Server) loadStatsHistory(ctx context.Context, decisionID uuid.UUID) ([]statsHistoryPoint, error) {
+	ctx, cancel := withBudget(ctx, statsQueryBudget)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		WITH r AS (
+			SELECT (created_at AT TIME ZONE 'UTC')::date AS day, COUNT(*)::int AS responses, SUM(rating)::bigint AS rating_sum
+			FROM responses
+			WHERE decision_id = $1 AND hidden_at IS NULL AND NOT shadowed
+			GROUP BY 1
+		), v AS (
+			SELECT (created_at AT TIME ZONE 'UTC')::date AS day,
+				COUNT(*) FILTER (WHERE value = 1)::int AS upvotes,
+				COUNT(*) FILTER (WHERE value = -1)::int AS downvotes
+			FROM decision_votes
+			WHERE decision_id = $1 AND NOT shadowed
+			GROUP BY 1
+		)
+		SELECT to_char(day, 'YYYY-MM-DD'), COALESCE(r.responses, 0), COALESCE(r.rating_sum, 0),
+			COALESCE(v.upvotes, 0), COALESCE(v.downvotes, 0)
+		FROM r FULL JOIN v USING (day)
+		ORDER BY day
+	`, decisionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var (
+		history   = make([]statsHistoryPoint, 0, 16)
+		total     int
+		ratingSum int64
+		voteScore int
+	)
+	for rows.Next() {
+		var (
+			p      statsHistoryPoint
+			daySum int64
+		)
+		if err := rows.Scan(&p.Day, &p.Responses, &daySum, &p.Upvotes, &p.Downvotes); err != nil {
+			return nil, err
+		}
+		total += p.Responses
+		ratingSum += daySum
+		voteScore += p.Upvotes - p.Downvotes
+		p.CumulativeResponses = total
+		if total > 0 {
+			p.CumulativeAvgRating = float64(ratingSum) / float64(total)
+		}
+		p.CumulativeVoteScore = voteScore
+		history = append(history, p)
+	}
+	return history, rows.Err()
+}
+
+func (s *Server) loadOutcome(ctx context.Context, decisionID uuid.UUID) (*outcomeView, error) {
+	var out outcomeView
+	err := s.db.QueryRowContext(ctx, `
+		SELECT did_it, satisfaction, recommendation, recommendation_score, recorded_at, updated_at
+		FROM decision_outcomes
+		WHERE decision_id = $1
+	`, decisionID).Scan(&out.DidIt, &out.Satisfaction, &out.Recommendation, &out.RecommendationScore, &out.RecordedAt, &out.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	out.FollowedCrowd = out.DidIt == (out.Recommendation == "yes")
+	return &out, nil
+}
@@ -0,0 +1,156 @@
+package httpapi
+
+import (
+	"encoding/xml"
+	"errors"
+	nethttp "net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultFeedLimit = 30
+	maxFeedLimit     = 100
+)
+
+// atomFeed and atomEntry are the subset of Atom (RFC 4287) the feed uses.
+type atomFeed struct {
+	XMLName  xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID       string      `xml:"id"`
+	Title    string      `xml:"title"`
+	Subtitle string      `xml:"subtitle,omitempty"`
+	Updated  string      `xml:"updated"`
+	Links    []atomLink  `xml:"link"`
+	Entries  []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	ID        string        `xml:"id"`
+	Title     string        `xml:"title"`
+	Link      atomLink      `xml:"link"`
+	Published string        `xml:"published"`
+	Updated   string        `xml:"updated"`
+	Category  *atomCategory `xml:"category,omitempty"`
+	Summary   *atomText     `xml:"summary,omitempty"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+// handleFeed serves an Atom feed of the newest public decisions: hidden and
+// panel-only decisions are left out. ?category= narrows it to one category.
+func (s *Server) handleFeed(w nethttp.ResponseWriter, r *nethttp.Request) {
+	limit, err := parseLimitParam(r, defaultFeedLimit, maxFeedLimit)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+	var category *string
+	if raw := r.URL.Query().Get("category"); raw != "" {
+		category, err = s.normalizeCategory(r.Context(), &raw)
+		if err != nil {
+			var invalid invalidInputError
+			if errors.As(err, &invalid) {
+				writeError(w, nethttp.StatusBadRequest, invalid.Error())
+				return
+			}
+			s.writeServerError(w, err, "failed to load category")
+			return
+		}
+	}
+
+	ctx, cancel := withBudget(r.Context(), statsQueryBudget)
+	defer cancel()
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, slug, title, description, category, created_at
+		FROM decisions
+		WHERE hidden_at IS NULL
+		  AND NOT panel_only
+		  AND visibility = 'public'
+		  AND ($1::text IS NULL OR category = $1)
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, category, limit)
+	if err != nil {
+		s.writeServerError(w, err, "failed to load feed")
+		return
+	}
+	defer rows.Close()
+
+	feed := atomFeed{
+		ID:       s.frontendBaseURL + "/",
+		Title:    "Rate My Life Decision: new decisions",
+		Subtitle: "Recently created decisions waiting for the crowd's verdict",
+		Links:    []atomLink{{Rel: "alternate", Href: s.frontendBaseURL + "/"}},
+	}
+	if category != nil {
+		feed.ID = s.frontendBaseURL + "/?category=" + *category
+		feed.Title += " (" + *category + ")"
+	}
+
+	var updated time.Time
+	for rows.Next() {
+		var (
+			id          uuid.UUID
+			slug, title string
+			description *string
+			cat         *string
+			createdAt   time.Time
+		)
+		if err := rows.Scan(&id, &slug, &title, &description, &cat, &createdAt); err != nil {
+			s.writeServerError(w, err, "failed to load feed")
+			return
+		}
+		if createdAt.After(updated) {
+			updated = createdAt
+		}
+		stamp := createdAt.UTC().Format(time.RFC3339)
+		entry := atomEntry{
+			ID:        "urn:uuid:" + id.String(),
+			Title:     title,
+			Link:      atomLink{Rel: "alternate", Href: s.shareURL(slug)},
+			Published: stamp,
+			Updated:   stamp,
+		}
+		if cat != nil {
+			entry.Category = &atomCategory{Term: *cat}
+		}
+		if description != nil && strings.TrimSpace(*description) != "" {
+			entry.Summary = &atomText{Type: "text", Text: *description}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		s.writeServerError(w, err, "failed to load feed")
+		return
+	}
+	// An empty feed still needs an updated time; the epoch keeps it stable.
+	feed.Updated = updated.UTC().Format(time.RFC3339)
+	if updated.IsZero() {
+		feed.Updated = time.Unix(0, 0).UTC().Format(time.RFC3339)
+	}
+
+	body, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		s.writeServerError(w, err, "failed to load feed")
+		return
+	}
+	w.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.WriteHeader(nethttp.StatusOK)
+	_, _ = w.Write([]byte(xml.Header))
+	_, _ = w.Write(body)
+}
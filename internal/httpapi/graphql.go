@@ -0,0 +1,370 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	nethttp "net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/graphql"
+	"ratemylifedecision/internal/store"
+)
+
+const maxGraphQLBodyBytes = 16 * 1024
+
+type graphqlRequestKey struct{}
+
+// graphqlDecision is the source value behind the Decision type: the shared
+// snapshot plus the asking viewer's own state.
+type graphqlDecision struct {
+	snapshot  decisionSnapshot
+	state     string
+	myVote    int
+	responded bool
+}
+
+type graphqlRespondResult struct {
+	ID   string
+	Slug string
+}
+
+// handleGraphQL serves POST /graphql. Reads are answered from the same
+// cached snapshot as GET /v1/decisions/{slug}. Mutations are replayed as
+// the matching REST call through the router, so they pass the same write
+// API key, rate limit, captcha and validation checks as the REST API and
+// take their credentials from this request's headers.
+func (s *Server) handleGraphQL(w nethttp.ResponseWriter, r *nethttp.Request) {
+	var req graphql.Request
+	if err := decodeJSON(w, r, maxGraphQLBodyBytes, &req); err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), graphqlRequestKey{}, r)
+	writeJSON(w, nethttp.StatusOK, s.graphql.Execute(ctx, req))
+}
+
+func (s *Server) graphqlSchema() *graphql.Schema {
+	emojiCountType := &graphql.Object{Name: "EmojiCount", Fields: map[string]*graphql.Field{
+		"emoji": scalarField(func(c emojiCount) any { return c.Emoji }),
+		"count": scalarField(func(c emojiCount) any { return c.Count }),
+	}}
+	statsType := &graphql.Object{Name: "Stats", Fields: map[string]*graphql.Field{
+		"responseCount": scalarField(func(st decisionStats) any { return st.ResponseCount }),
+		"ratingCounts":  scalarField(func(st decisionStats) any { return st.RatingCounts }),
+		"avgRating":     scalarField(func(st decisionStats) any { return st.AvgRating }),
+		"netSentiment":  scalarField(func(st decisionStats) any { return st.NetSentiment }),
+		"doIt":          scalarField(func(st decisionStats) any { return st.Categories.DoIt }),
+		"dontDoIt":      scalarField(func(st decisionStats) any { return st.Categories.DontDoIt }),
+		"mixed":         scalarField(func(st decisionStats) any { return st.Categories.Mixed }),
+		"topEmoji":      scalarField(func(st decisionStats) any { return st.TopEmoji }),
+		"emojiCounts":   objectField(emojiCountType, func(st decisionStats) any { return st.EmojiCounts }),
+	}}
+	recommendationType := &graphql.Object{Name: "Recommendation", Fields: map[string]*graphql.Field{
+		"decision":         scalarField(func(rec recommendationView) any { return rec.Decision }),
+		"score":            scalarField(func(rec recommendationView) any { return rec.Score }),
+		"suggestionScore":  scalarField(func(rec recommendationView) any { return rec.SuggestionScore }),
+		"ratingScore":      scalarField(func(rec recommendationView) any { return rec.RatingScore }),
+		"commentSentiment": scalarField(func(rec recommendationView) any { return rec.CommentSentiment }),
+		"postVoteScore":    scalarField(func(rec recommendationView) any { return rec.PostVoteScore }),
+		"sampleSize":       scalarField(func(rec recommendationView) any { return rec.SampleSize }),
+		"confidence":       scalarField(func(rec recommendationView) any { return rec.Confidence }),
+	}}
+	postVoteType := &graphql.Object{Name: "PostVote", Fields: map[string]*graphql.Field{
+		"score":     scalarField(func(v decisionVoteSummary) any { return v.Score }),
+		"upvotes":   scalarField(func(v decisionVoteSummary) any { return v.Upvotes }),
+		"downvotes": scalarField(func(v decisionVoteSummary) any { return v.Downvotes }),
+		"myVote":    scalarField(func(v decisionVoteSummary) any { return v.MyVote }),
+	}}
+	responseType := &graphql.Object{Name: "Response", Fields: map[string]*graphql.Field{
+		"id":          scalarField(func(c responseCard) any { return c.ID }),
+		"rating":      scalarField(func(c responseCard) any { return c.Rating }),
+		"suggestion":  scalarField(func(c responseCard) any { return c.Suggestion }),
+		"emoji":       scalarField(func(c responseCard) any { return c.Emoji }),
+		"comment":     scalarField(func(c responseCard) any { return c.Comment }),
+		"createdAt":   scalarField(func(c responseCard) any { return c.CreatedAt }),
+		"editedAt":    scalarField(func(c responseCard) any { return c.EditedAt }),
+		"panelMember": scalarField(func(c responseCard) any { return c.PanelMember }),
+		"nickname":    scalarField(func(c responseCard) any { return c.Nickname }),
+		"reactions":   scalarField(func(c responseCard) any { return c.Reactions }),
+	}}
+	decisionType := &graphql.Object{Name: "Decision", Fields: map[string]*graphql.Field{
+		"id":                 scalarField(func(d *graphqlDecision) any { return d.snapshot.Decision.ID.String() }),
+		"slug":               scalarField(func(d *graphqlDecision) any { return d.snapshot.Decision.Slug }),
+		"title":              scalarField(func(d *graphqlDecision) any { return d.snapshot.Decision.Title }),
+		"description":        scalarField(func(d *graphqlDecision) any { return d.snapshot.Decision.Description }),
+		"closesAt":           scalarField(func(d *graphqlDecision) any { return d.snapshot.Decision.ClosesAt }),
+		"createdAt":          scalarField(func(d *graphqlDecision) any { return d.snapshot.Decision.CreatedAt }),
+		"category":           scalarField(func(d *graphqlDecision) any { return d.snapshot.Decision.Category }),
+		"panelOnly":          scalarField(func(d *graphqlDecision) any { return d.snapshot.Decision.PanelOnly }),
+		"aggregateOnly":      scalarField(func(d *graphqlDecision) any { return d.snapshot.Decision.AggregateOnly }),
+		"quorum":             scalarField(func(d *graphqlDecision) any { return d.snapshot.Decision.Quorum }),
+		"maxResponses":       scalarField(func(d *graphqlDecision) any { return d.snapshot.Decision.MaxResponses }),
+		"nicknamePolicy":     scalarField(func(d *graphqlDecision) any { return d.snapshot.Decision.NicknamePolicy }),
+		"visibility":         scalarField(func(d *graphqlDecision) any { return d.snapshot.Decision.Visibility }),
+		"state":              scalarField(func(d *graphqlDecision) any { return d.state }),
+		"viewerHasResponded": scalarField(func(d *graphqlDecision) any { return d.responded }),
+		"stats":              objectField(statsType, func(d *graphqlDecision) any { return d.snapshot.Stats }),
+		"recommendation":     objectField(recommendationType, func(d *graphqlDecision) any { return d.snapshot.Recommendation }),
+		"postVote": objectField(postVoteType, func(d *graphqlDecision) any {
+			summary := d.snapshot.PostVote
+			summary.MyVote = d.myVote
+			return summary
+		}),
+		// responses are the envelope's cards by default. A larger limit, up
+		// to a page of the responses feed, reads the newest from the feed.
+		"responses": {Type: responseType, Resolve: func(ctx context.Context, source any, args map[string]any) (any, error) {
+			d := source.(*graphqlDecision)
+			cards, more := envelopeResponses(d.snapshot)
+			limit, err := intArg(args, "limit")
+			if err != nil {
+				return nil, err
+			}
+			if limit == nil {
+				return cards, nil
+			}
+			if *limit < 0 || *limit > maxResponsePageLimit {
+				return nil, fmt.Errorf("limit must be between 0 and %d", maxResponsePageLimit)
+			}
+			if *limit <= len(cards) || !more {
+				return cards[:min(*limit, len(cards))], nil
+			}
+			ctx, cancel := withBudget(ctx, statsQueryBudget)
+			defer cancel()
+			page, err := s.loadResponsePage(ctx, d.snapshot.Decision.ID, responseSortNewest, responseFilter{MinRating: 1, MaxRating: 5}, nil, *limit)
+			if err != nil {
+				return nil, err
+			}
+			return page.Responses, nil
+		}},
+	}}
+	createDecisionType := &graphql.Object{Name: "CreateDecisionPayload", Fields: map[string]*graphql.Field{
+		"id":           scalarField(func(c createDecisionResponse) any { return c.ID }),
+		"slug":         scalarField(func(c createDecisionResponse) any { return c.Slug }),
+		"shareUrl":     scalarField(func(c createDecisionResponse) any { return c.ShareURL }),
+		"creatorToken": scalarField(func(c createDecisionResponse) any { return c.CreatorToken }),
+		"accessCode":   scalarField(func(c createDecisionResponse) any { return c.AccessCode }),
+	}}
+	respondType := &graphql.Object{Name: "RespondPayload", Fields: map[string]*graphql.Field{
+		"id": scalarField(func(res graphqlRespondResult) any { return res.ID }),
+		"decision": {Type: decisionType, Resolve: func(ctx context.Context, source any, _ map[string]any) (any, error) {
+			return s.resolveGraphQLDecision(ctx, source.(graphqlRespondResult).Slug, nil)
+		}},
+	}}
+
+	query := &graphql.Object{Name: "Query", Fields: map[string]*graphql.Field{
+		"decision": {Type: decisionType, Resolve: func(ctx context.Context, _ any, args map[string]any) (any, error) {
+			slug, err := stringArg(args, "slug", true)
+			if err != nil {
+				return nil, err
+			}
+			var viewerID *uuid.UUID
+			if raw, err := stringArg(args, "viewerId", false); err != nil {
+				return nil, err
+			} else if raw != "" {
+				id, err := uuid.Parse(raw)
+				if err != nil {
+					return nil, errors.New("viewerId must be a valid UUID")
+				}
+				viewerID = &id
+			}
+			return s.resolveGraphQLDecision(ctx, slug, viewerID)
+		}},
+	}}
+	mutation := &graphql.Object{Name: "Mutation", Fields: map[string]*graphql.Field{
+		"createDecision": {Type: createDecisionType, Resolve: func(ctx context.Context, _ any, args map[string]any) (any, error) {
+			var out createDecisionResponse
+			err := s.forwardGraphQLMutation(ctx, nethttp.MethodPost, "/v1/decisions", args, map[string]string{
+				"title":          "title",
+				"description":    "description",
+				"closesAt":       "closes_at",
+				"category":       "category",
+				"aggregateOnly":  "aggregate_only",
+				"quorum":         "quorum",
+				"maxResponses":   "max_responses",
+				"nicknamePolicy": "nickname_policy",
+				"visibility":     "visibility",
+				"accessCode":     "access_code",
+				"slug":           "slug",
+			}, &out)
+			if err != nil {
+				return nil, err
+			}
+			return out, nil
+		}},
+		"respond": {Type: respondType, Resolve: func(ctx context.Context, _ any, args map[string]any) (any, error) {
+			slug, err := stringArg(args, "slug", true)
+			if err != nil {
+				return nil, err
+			}
+			var out struct {
+				ID string `json:"id"`
+			}
+			err = s.forwardGraphQLMutation(ctx, nethttp.MethodPost, "/v1/decisions/"+url.PathEscape(slug)+"/responses", args, map[string]string{
+				"viewerToken": "viewer_token",
+				"suggestion":  "suggestion",
+				"emoji":       "emoji",
+				"comment":     "comment",
+				"nickname":    "nickname",
+				"panelToken":  "panel_token",
+			}, &out)
+			if err != nil {
+				return nil, err
+			}
+			return graphqlRespondResult{ID: out.ID, Slug: slug}, nil
+		}},
+		"vote": {Type: postVoteType, Resolve: func(ctx context.Context, _ any, args map[string]any) (any, error) {
+			slug, err := stringArg(args, "slug", true)
+			if err != nil {
+				return nil, err
+			}
+			var out decisionVoteSummaryResponse
+			err = s.forwardGraphQLMutation(ctx, nethttp.MethodPost, "/v1/decisions/"+url.PathEscape(slug)+"/votes", args, map[string]string{
+				"viewerToken": "viewer_token",
+				"value":       "value",
+			}, &out)
+			if err != nil {
+				return nil, err
+			}
+			return decisionVoteSummary{Score: out.Score, Upvotes: out.Upvotes, Downvotes: out.Downvotes, MyVote: out.MyVote}, nil
+		}},
+	}}
+
+	return &graphql.Schema{Query: query, Mutation: mutation}
+}
+
+func (s *Server) resolveGraphQLDecision(ctx context.Context, slug string, viewerID *uuid.UUID) (any, error) {
+	slug, err := normalizeSlugParam(slug)
+	if err != nil {
+		return nil, err
+	}
+	snapshot, myVote, responded, err := s.loadDecisionView(ctx, slug, viewerID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, errors.New("failed to load decision")
+	}
+	r, _ := ctx.Value(graphqlRequestKey{}).(*nethttp.Request)
+	if err := requestAccessError(r, snapshot.Decision); err != nil {
+		return nil, err
+	}
+	state := decisionStateRevealed
+	if withheld, pending := withholdUntilQuorum(snapshot, time.Now()); pending {
+		snapshot, state = withheld, decisionStateCollecting
+	}
+	return &graphqlDecision{snapshot: snapshot, state: state, myVote: myVote, responded: responded}, nil
+}
+
+// forwardGraphQLMutation sends args, renamed to the REST field names in
+// fields, to method path and decodes a successful reply into out. Any
+// argument not listed in fields is rejected, and a REST error becomes the
+// field's error.
+func (s *Server) forwardGraphQLMutation(ctx context.Context, method, path string, args map[string]any, fields map[string]string, out any) error {
+	body := make(map[string]any, len(args))
+	for name, value := range args {
+		if name == "slug" {
+			continue
+		}
+		restName, ok := fields[name]
+		if !ok {
+			return fmt.Errorf("unknown argument %q", name)
+		}
+		body[restName] = value
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	original, _ := ctx.Value(graphqlRequestKey{}).(*nethttp.Request)
+	if original == nil {
+		return errors.New("mutation is missing its request")
+	}
+	req, err := nethttp.NewRequestWithContext(ctx, method, path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header = original.Header.Clone()
+	req.Header.Set("Content-Type", "application/json")
+	// The reply is read here, not sent to the client, so keep it plain.
+	req.Header.Del("Accept-Encoding")
+	req.RemoteAddr = original.RemoteAddr
+	req.TLS = original.TLS
+
+	rec := &bufferedResponse{header: make(nethttp.Header), status: nethttp.StatusOK}
+	s.router.ServeHTTP(rec, req)
+
+	if rec.status >= 400 {
+		var failure problem
+		if err := json.Unmarshal(rec.body.Bytes(), &failure); err != nil || failure.Detail == "" {
+			return fmt.Errorf("request failed with status %d", rec.status)
+		}
+		return problemError{failure}
+	}
+	return json.Unmarshal(rec.body.Bytes(), out)
+}
+
+// bufferedResponse collects a response in memory.
+type bufferedResponse struct {
+	header nethttp.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() nethttp.Header { return b.header }
+
+func (b *bufferedResponse) WriteHeader(status int) { b.status = status }
+
+func (b *bufferedResponse) Write(p []byte) (int, error) { return b.body.Write(p) }
+
+// scalarField resolves a scalar from a source of type T.
+func scalarField[T any](get func(T) any) *graphql.Field {
+	return &graphql.Field{Resolve: func(_ context.Context, source any, _ map[string]any) (any, error) {
+		return get(source.(T)), nil
+	}}
+}
+
+// objectField resolves a nested object (or slice of them) of type typ.
+func objectField[T any](typ *graphql.Object, get func(T) any) *graphql.Field {
+	return &graphql.Field{Type: typ, Resolve: func(_ context.Context, source any, _ map[string]any) (any, error) {
+		return get(source.(T)), nil
+	}}
+}
+
+func stringArg(args map[string]any, name string, required bool) (string, error) {
+	switch v := args[name].(type) {
+	case string:
+		return v, nil
+	case nil:
+		if required {
+			return "", fmt.Errorf("argument %q is required", name)
+		}
+		return "", nil
+	}
+	return "", fmt.Errorf("argument %q must be a string", name)
+}
+
+// intArg accepts integers from the document and whole-number floats from
+// JSON variables.
+func intArg(args map[string]any, name string) (*int, error) {
+	switch v := args[name].(type) {
+	case nil:
+		return nil, nil
+	case int:
+		return &v, nil
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) <= math.MaxInt32 {
+			n := int(v)
+			return &n, nil
+		}
+	}
+	return nil, fmt.Errorf("argument %q must be an integer", name)
+}
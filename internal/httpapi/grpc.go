@@ -0,0 +1,276 @@
+package httpapi
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	pb "ratemylifedecision/internal/gen/ratemylifedecision/v1"
+	"ratemylifedecision/internal/store"
+)
+
+// grpcMaxMessageBytes caps request messages; the largest,
+// CreateDecisionRequest, is a few kilobytes at most.
+const grpcMaxMessageBytes = 64 * 1024
+
+// GRPCServer serves DecisionService for internal consumers. It shares the
+// stores, cache and live updates of the JSON API but none of its middleware:
+// callers are trusted services, authenticated by GRPC_API_KEYS when set.
+func (s *Server) GRPCServer() *grpc.Server {
+	srv := grpc.NewServer(
+		grpc.MaxRecvMsgSize(grpcMaxMessageBytes),
+		grpc.ChainUnaryInterceptor(s.grpcErrors, s.grpcAudit, s.grpcAuth),
+	)
+	pb.RegisterDecisionServiceServer(srv, grpcDecisionService{s: s})
+	return srv
+}
+
+// grpcErrors reports any error that is not already a status as Internal,
+// without its text, and logs it.
+func (s *Server) grpcErrors(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+	resp, err := next(ctx, req)
+	if err == nil {
+		return resp, nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return nil, err
+	}
+	slog.Error("grpc call failed", "method", info.FullMethod, "error", err)
+	return nil, status.Error(codes.Internal, "internal error")
+}
+
+// grpcAuditedMethods change something, so they go into audit_log like the
+// JSON API's writes.
+var grpcAuditedMethods = map[string]bool{
+	pb.DecisionService_CreateDecision_FullMethodName: true,
+	pb.DecisionService_DeleteDecision_FullMethodName: true,
+}
+
+// grpcAudit records successful calls to grpcAuditedMethods, with GRPC as
+// the method, the full method name as the route and the status code of
+// the call (always OK). It goes ahead of grpcAuth so the key can be noted.
+func (s *Server) grpcAudit(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+	if !grpcAuditedMethods[info.FullMethod] {
+		return next(ctx, req)
+	}
+	entry := &auditEntry{}
+	resp, err := next(context.WithValue(ctx, auditKey{}, entry), req)
+	if err != nil {
+		return nil, err
+	}
+	var ip string
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		ip, _, _ = net.SplitHostPort(p.Addr.String())
+	}
+	s.insertAuditEntry(ctx, entry, auditRequest{ip: ip, method: "GRPC", route: info.FullMethod, status: int(codes.OK)})
+	return resp, nil
+}
+
+func (s *Server) grpcAuth(ctx context.Context, req any, info *grpc.UnaryServerInfo, next grpc.UnaryHandler) (any, error) {
+	if len(s.grpcAPIKeys) == 0 {
+		return next(ctx, req)
+	}
+	key, ok := strings.CutPrefix(grpcMetadata(ctx, "authorization"), "Bearer ")
+	key = strings.TrimSpace(key)
+	if !ok || key == "" {
+		return nil, status.Error(codes.Unauthenticated, "missing API key")
+	}
+	for valid := range s.grpcAPIKeys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(valid)) == 1 {
+			auditFromContext(ctx).setAPIKeyID("grpc")
+			return next(ctx, req)
+		}
+	}
+	return nil, status.Error(codes.Unauthenticated, "invalid API key")
+}
+
+// grpcMetadata returns the first value of key in the call's metadata.
+func grpcMetadata(ctx context.Context, key string) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(key); len(values) > 0 {
+		return strings.TrimSpace(values[0])
+	}
+	return ""
+}
+
+type grpcDecisionService struct {
+	pb.UnimplementedDecisionServiceServer
+	s *Server
+}
+
+func (g grpcDecisionService) CreateDecision(ctx context.Context, in *pb.CreateDecisionRequest) (*pb.CreateDecisionResponse, error) {
+	req := createDecisionRequest{
+		Title:         in.GetTitle(),
+		Description:   in.Description,
+		Category:      in.Category,
+		AggregateOnly: in.GetAggregateOnly(),
+		Quorum:        int(in.GetQuorum()),
+		Visibility:    in.Visibility,
+		AccessCode:    in.AccessCode,
+		Slug:          in.Slug,
+	}
+	if in.ClosesAt != nil {
+		closesAt := time.Unix(in.GetClosesAt(), 0).UTC()
+		req.ClosesAt = &closesAt
+	}
+
+	out, err := g.s.createDecision(ctx, req)
+	if err != nil {
+		var invalid invalidInputError
+		switch {
+		case errors.As(err, &invalid):
+			return nil, status.Error(codes.InvalidArgument, invalid.Error())
+		case errors.Is(err, errSlugExhausted):
+			return nil, status.Error(codes.AlreadyExists, err.Error())
+		}
+		return nil, err
+	}
+	g.s.auditCreatedDecision(ctx, out)
+	return &pb.CreateDecisionResponse{
+		Id:           out.ID,
+		Slug:         out.Slug,
+		CreatorToken: out.CreatorToken,
+		AccessCode:   out.AccessCode,
+	}, nil
+}
+
+func (g grpcDecisionService) GetDecision(ctx context.Context, in *pb.GetDecisionRequest) (*pb.Decision, error) {
+	snapshot, err := g.s.grpcLoadSnapshot(ctx, in.GetSlug())
+	if err != nil {
+		return nil, err
+	}
+
+	d := snapshot.Decision
+	out := &pb.Decision{
+		Id:            d.ID.String(),
+		Slug:          d.Slug,
+		Title:         d.Title,
+		Description:   d.Description,
+		CreatedAt:     d.CreatedAt.Unix(),
+		Category:      d.Category,
+		PanelOnly:     d.PanelOnly,
+		AggregateOnly: d.AggregateOnly,
+		Quorum:        int32(d.Quorum),
+		Visibility:    d.Visibility,
+	}
+	if d.ClosesAt != nil {
+		closesAt := d.ClosesAt.Unix()
+		out.ClosesAt = &closesAt
+	}
+	return out, nil
+}
+
+func (g grpcDecisionService) GetDecisionStats(ctx context.Context, in *pb.GetDecisionStatsRequest) (*pb.DecisionStats, error) {
+	snapshot, err := g.s.grpcLoadSnapshot(ctx, in.GetSlug())
+	if err != nil {
+		return nil, err
+	}
+
+	st := snapshot.Stats
+	ratingCounts := make([]int32, len(st.RatingCounts))
+	for i, n := range st.RatingCounts {
+		ratingCounts[i] = int32(n)
+	}
+	emojiCounts := make([]*pb.EmojiCount, 0, len(st.EmojiCounts))
+	for _, ec := range st.EmojiCounts {
+		emojiCounts = append(emojiCounts, &pb.EmojiCount{Emoji: ec.Emoji, Count: int32(ec.Count)})
+	}
+	rec := snapshot.Recommendation
+	return &pb.DecisionStats{
+		ResponseCount: int32(st.ResponseCount),
+		RatingCounts:  ratingCounts,
+		AvgRating:     st.AvgRating,
+		NetSentiment:  st.NetSentiment,
+		DoIt:          int32(st.Categories.DoIt),
+		DontDoIt:      int32(st.Categories.DontDoIt),
+		Mixed:         int32(st.Categories.Mixed),
+		EmojiCounts:   emojiCounts,
+		TopEmoji:      st.TopEmoji,
+		Recommendation: &pb.Recommendation{
+			Decision:         rec.Decision,
+			Score:            rec.Score,
+			SuggestionScore:  rec.SuggestionScore,
+			RatingScore:      rec.RatingScore,
+			CommentSentiment: rec.CommentSentiment,
+			PostVoteScore:    rec.PostVoteScore,
+			SampleSize:       int32(rec.SampleSize),
+			Confidence:       rec.Confidence,
+		},
+		VoteScore:           int32(snapshot.PostVote.Score),
+		Upvotes:             int32(snapshot.PostVote.Upvotes),
+		Downvotes:           int32(snapshot.PostVote.Downvotes),
+		CollectingResponses: quorumPending(snapshot.Decision, st.ResponseCount, time.Now()),
+	}, nil
+}
+
+func (g grpcDecisionService) DeleteDecision(ctx context.Context, in *pb.DeleteDecisionRequest) (*pb.DeleteDecisionResponse, error) {
+	slug, err := normalizeSlugParam(in.GetSlug())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	token := strings.TrimSpace(in.GetCreatorToken())
+	if token == "" {
+		return nil, status.Error(codes.Unauthenticated, "missing creator token")
+	}
+
+	decision, err := g.s.decisions.BySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, "decision not found")
+		}
+		return nil, err
+	}
+	if decision.CreatorTokenHash == nil || !tokenMatchesHash(token, *decision.CreatorTokenHash) {
+		return nil, status.Error(codes.PermissionDenied, "invalid creator token")
+	}
+	audit := auditFromContext(ctx)
+	audit.setActor(auditActorCreator, decision.ID.String())
+
+	deleted, err := g.s.deleteDecision(ctx, slug)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Error(codes.NotFound, "decision not found")
+		}
+		return nil, err
+	}
+	audit.setTarget("decision", deleted.ID)
+	audit.setBefore(deleted)
+	return &pb.DeleteDecisionResponse{}, nil
+}
+
+// grpcLoadSnapshot loads the shared snapshot of the decision at slug, as the
+// public sees it. Private decisions need their code in the x-access-code
+// metadata.
+func (s *Server) grpcLoadSnapshot(ctx context.Context, slug string) (decisionSnapshot, error) {
+	slug, err := normalizeSlugParam(slug)
+	if err != nil {
+		return decisionSnapshot{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	snapshot, _, _, err := s.loadDecisionView(ctx, slug, nil)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return decisionSnapshot{}, status.Error(codes.NotFound, "decision not found")
+		}
+		return decisionSnapshot{}, err
+	}
+	switch err := decisionAccessError(snapshot.Decision, grpcMetadata(ctx, "x-access-code"), grpcMetadata(ctx, "x-creator-token")); {
+	case errors.Is(err, errAccessCodeRequired):
+		return decisionSnapshot{}, status.Error(codes.Unauthenticated, err.Error())
+	case err != nil:
+		return decisionSnapshot{}, status.Error(codes.PermissionDenied, err.Error())
+	}
+	snapshot, _ = withholdUntilQuorum(snapshot, time.Now())
+	return snapshot, nil
+}
@@ -0,0 +1,53 @@
+package httpapi
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	pb "ratemylifedecision/internal/gen/ratemylifedecision/v1"
+	"ratemylifedecision/internal/store"
+)
+
+func TestGRPCGetDecision(t *testing.T) {
+	s, stores := newTestServer(t)
+	s.grpcAPIKeys = map[string]struct{}{"internal-key": {}}
+	createTestDecision(t, stores, store.NewDecision{Slug: "move-abroad", Title: "Move abroad?"})
+
+	lis := bufconn.Listen(1 << 20)
+	srv := s.GRPCServer()
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	client := pb.NewDecisionServiceClient(conn)
+	ctx := context.Background()
+	authed := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer internal-key")
+
+	if _, err := client.GetDecision(ctx, &pb.GetDecisionRequest{Slug: "move-abroad"}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("without key: err = %v, want Unauthenticated", err)
+	}
+	if _, err := client.GetDecision(authed, &pb.GetDecisionRequest{Slug: "nope"}); status.Code(err) != codes.NotFound {
+		t.Fatalf("unknown slug: err = %v, want NotFound", err)
+	}
+	got, err := client.GetDecision(authed, &pb.GetDecisionRequest{Slug: "move-abroad"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.GetSlug() != "move-abroad" || got.GetTitle() != "Move abroad?" || got.GetVisibility() != visibilityPublic {
+		t.Fatalf("got %v", got)
+	}
+}
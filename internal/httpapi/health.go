@@ -0,0 +1,98 @@
+package httpapi
+
+import (
+	"context"
+	"log/slog"
+	nethttp "net/http"
+	"time"
+
+	"ratemylifedecision/internal/database"
+	"ratemylifedecision/internal/migrate"
+	"ratemylifedecision/migrations"
+)
+
+const (
+	readinessCheckBudget    = 2 * time.Second
+	healthStatusOK          = "ok"
+	healthStatusUnavailable = "unavailable"
+)
+
+type dependencyCheck struct {
+	Status    string              `json:"status"`
+	LatencyMS int64               `json:"latency_ms"`
+	Error     string              `json:"error,omitempty"`
+	Pending   []string            `json:"pending,omitempty"`
+	Pool      *database.PoolStats `json:"pool,omitempty"`
+}
+
+type readinessReport struct {
+	Status string                     `json:"status"`
+	Checks map[string]dependencyCheck `json:"checks"`
+}
+
+// handleLiveness only reports that the process is up and serving. It never
+// touches dependencies, so a database outage does not get the pod restarted.
+func (s *Server) handleLiveness(w nethttp.ResponseWriter, _ *nethttp.Request) {
+	writeJSON(w, nethttp.StatusOK, map[string]string{"status": healthStatusOK})
+}
+
+// handleReadiness reports whether the server should receive traffic: the
+// database answers within the check budget, every migration on disk has
+// been applied, and the server is not draining for shutdown.
+func (s *Server) handleReadiness(w nethttp.ResponseWriter, r *nethttp.Request) {
+	ctx, cancel := withBudget(r.Context(), readinessCheckBudget)
+	defer cancel()
+
+	report := readinessReport{
+		Status: healthStatusOK,
+		Checks: map[string]dependencyCheck{
+			"database": s.checkDatabase(ctx),
+		},
+	}
+	if report.Checks["database"].Status == healthStatusOK {
+		report.Checks["migrations"] = s.checkMigrations(ctx)
+	}
+	if s.shutdown.Err() != nil {
+		report.Checks["shutdown"] = dependencyCheck{Status: healthStatusUnavailable, Error: "server is draining"}
+	}
+
+	status := nethttp.StatusOK
+	for _, check := range report.Checks {
+		if check.Status != healthStatusOK {
+			report.Status = healthStatusUnavailable
+			status = nethttp.StatusServiceUnavailable
+		}
+	}
+	writeJSON(w, status, report)
+}
+
+func (s *Server) checkDatabase(ctx context.Context) dependencyCheck {
+	start := time.Now()
+	err := s.pool.Ping(ctx)
+	pool := database.Stats(s.pool)
+	check := dependencyCheck{Status: healthStatusOK, LatencyMS: time.Since(start).Milliseconds(), Pool: &pool}
+	if err != nil {
+		// /readyz is unauthenticated; keep driver errors (hosts, users) in the logs.
+		slog.Warn("readiness check failed", "check", "database", "error", err)
+		check.Status = healthStatusUnavailable
+		check.Error = "database unreachable"
+	}
+	return check
+}
+
+func (s *Server) checkMigrations(ctx context.Context) dependencyCheck {
+	start := time.Now()
+	pending, err := migrate.Pending(ctx, s.db, migrations.FS(s.cfg.MigrationsDir))
+	check := dependencyCheck{Status: healthStatusOK, LatencyMS: time.Since(start).Milliseconds()}
+	switch {
+	case err != nil:
+		slog.Warn("readiness check failed", "check", "migrations", "error", err)
+		check.Status = healthStatusUnavailable
+		check.Error = "failed to read migration state"
+	case len(pending) > 0:
+		check.Status = healthStatusUnavailable
+		check.Error = "migrations pending"
+		check.Pending = pending
+	}
+	return check
+}
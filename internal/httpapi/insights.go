@@ -0,0 +1,165 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	nethttp "net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"ratemylifedecision/internal/projections"
+)
+
+const (
+	defaultInsightsLimit = 20
+	maxInsightsLimit     = 100
+)
+
+type activityItem struct {
+	Slug           string    `json:"slug"`
+	Title          string    `json:"title"`
+	Category       *string   `json:"category"`
+	ResponseCount  int       `json:"response_count"`
+	VoteScore      int       `json:"vote_score"`
+	VoteCount      int       `json:"vote_count"`
+	TrendScore     float64   `json:"trend_score"`
+	LastActivityAt time.Time `json:"last_activity_at"`
+}
+
+type categoryInsight struct {
+	Category      string   `json:"category"`
+	DecisionCount int      `json:"decision_count"`
+	ResponseCount int      `json:"response_count"`
+	AverageRating *float64 `json:"average_rating"`
+	VoteCount     int      `json:"vote_count"`
+}
+
+// Trending, leaderboard and category insights read only from the rm_*
+// projections maintained by the projection worker, so they may lag writes
+// by up to PROJECTION_INTERVAL; leaderboards are ranked by a job of their
+// own every LEADERBOARD_INTERVAL on top of that.
+func (s *Server) handleTrendingInsights(w nethttp.ResponseWriter, r *nethttp.Request) {
+	limit, err := parseLimitParam(r, defaultInsightsLimit, maxInsightsLimit)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	items, err := s.queryActivity(r.Context(), "trend_now DESC", limit)
+	if err != nil {
+		s.writeServerError(w, err, "failed to load trending decisions")
+		return
+	}
+	writeJSON(w, nethttp.StatusOK, map[string]any{"items": items})
+}
+
+func (s *Server) handleLeaderboardInsights(w nethttp.ResponseWriter, r *nethttp.Request) {
+	limit, err := parseLimitParam(r, defaultInsightsLimit, maxInsightsLimit)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	by := strings.TrimSpace(r.URL.Query().Get("by"))
+	if by == "" {
+		by = "score"
+	}
+	if _, ok := leaderboardOrders[by]; !ok {
+		writeError(w, nethttp.StatusBadRequest, "by must be score, responses, votes or controversial")
+		return
+	}
+
+	items, err := s.queryLeaderboard(r.Context(), by, limit)
+	if err != nil {
+		s.writeServerError(w, err, "failed to load leaderboard")
+		return
+	}
+	writeJSON(w, nethttp.StatusOK, map[string]any{"items": items})
+}
+
+func (s *Server) handleCategoryInsights(w nethttp.ResponseWriter, r *nethttp.Request) {
+	ctx, cancel := withBudget(r.Context(), statsQueryBudget)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			category,
+			decision_count,
+			response_count,
+			CASE WHEN response_count > 0 THEN rating_sum::float8 / response_count END,
+			vote_count
+		FROM rm_category_insights
+		ORDER BY decision_count DESC, category ASC
+	`)
+	if err != nil {
+		s.writeServerError(w, err, "failed to load category insights")
+		return
+	}
+	defer rows.Close()
+
+	items := make([]categoryInsight, 0)
+	for rows.Next() {
+		var c categoryInsight
+		if err := rows.Scan(&c.Category, &c.DecisionCount, &c.ResponseCount, &c.AverageRating, &c.VoteCount); err != nil {
+			s.writeServerError(w, err, "failed to load category insights")
+			return
+		}
+		items = append(items, c)
+	}
+	if err := rows.Err(); err != nil {
+		s.writeServerError(w, err, "failed to load category insights")
+		return
+	}
+	writeJSON(w, nethttp.StatusOK, map[string]any{"items": items})
+}
+
+func (s *Server) queryActivity(ctx context.Context, orderBy string, limit int) ([]activityItem, error) {
+	ctx, cancel := withBudget(ctx, statsQueryBudget)
+	defer cancel()
+
+	// Trend scores are stored as of trend_at; decay them to now.
+	halfLife := strconv.FormatFloat(projections.TrendHalfLife.Seconds(), 'f', -1, 64)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			d.slug, d.title, a.category,
+			a.response_count, a.vote_score, a.vote_count,
+			a.trend_score * power(0.5, EXTRACT(EPOCH FROM (now() - a.trend_at))::float8 / `+halfLife+`) AS trend_now,
+			a.last_activity_at
+		FROM rm_decision_activity a
+		JOIN decisions d ON d.id = a.decision_id
+		WHERE d.hidden_at IS NULL AND d.visibility = 'public'
+		ORDER BY `+orderBy+`
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]activityItem, 0, limit)
+	for rows.Next() {
+		var it activityItem
+		if err := rows.Scan(
+			&it.Slug, &it.Title, &it.Category,
+			&it.ResponseCount, &it.VoteScore, &it.VoteCount,
+			&it.TrendScore, &it.LastActivityAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
+
+func parseLimitParam(r *nethttp.Request, fallback, max int) (int, error) {
+	raw := strings.TrimSpace(r.URL.Query().Get("limit"))
+	if raw == "" {
+		return fallback, nil
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 || limit > max {
+		return 0, errors.New("limit must be between 1 and " + strconv.Itoa(max))
+	}
+	return limit, nil
+}
@@ -0,0 +1,302 @@
+package httpapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	nethttp "net/http"
+	"net/netip"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	maxIPRuleBodyBytes    = 1024
+	ipRuleReasonMaxLength = 200
+	ipRulesChangedEvent   = "ip_rules_changed"
+
+	errorCodeIPBlocked = "ip_blocked"
+)
+
+// ipFilter decides which client IPs may reach the API. Allow rules win over
+// block rules, so an office range can be carved out of a blocked network.
+// In allowlist-only mode anything not allowed is blocked.
+//
+// Rules come from IP_ALLOWLIST and IP_BLOCKLIST, which are fixed for the
+// life of the process, and from the ip_rules table, which is reloaded every
+// IP_RULES_REFRESH and whenever an instance changes it.
+type ipFilter struct {
+	allowlistOnly bool
+	static        []ipRule
+
+	mu    sync.RWMutex
+	rules []ipRule
+}
+
+type ipRule struct {
+	prefix netip.Prefix
+	allow  bool
+}
+
+type ipRuleView struct {
+	ID        string     `json:"id"`
+	CIDR      string     `json:"cidr"`
+	Action    string     `json:"action"`
+	Reason    *string    `json:"reason"`
+	ExpiresAt *time.Time `json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type createIPRuleRequest struct {
+	CIDR   string  `json:"cidr"`
+	Action string  `json:"action"`
+	Reason *string `json:"reason"`
+	// TTL is a Go duration such as "1h"; empty means the rule never expires.
+	TTL string `json:"ttl"`
+}
+
+func newIPFilterFromEnv(allowlistOnly bool) *ipFilter {
+	f := &ipFilter{allowlistOnly: allowlistOnly}
+	for _, env := range []struct {
+		key   string
+		allow bool
+	}{{"IP_ALLOWLIST", true}, {"IP_BLOCKLIST", false}} {
+		for _, entry := range strings.Split(os.Getenv(env.key), ",") {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+			prefix, err := parseIPPrefix(entry)
+			if err != nil {
+				slog.Warn("ignoring invalid IP rule", "env", env.key, "entry", entry, "error", err)
+				continue
+			}
+			f.static = append(f.static, ipRule{prefix: prefix, allow: env.allow})
+		}
+	}
+	f.rules = f.static
+	return f
+}
+
+// parseIPPrefix accepts a CIDR or a bare address and returns the canonical
+// prefix, with host bits cleared.
+func parseIPPrefix(raw string) (netip.Prefix, error) {
+	raw = strings.TrimSpace(raw)
+	if !strings.Contains(raw, "/") {
+		addr, err := netip.ParseAddr(raw)
+		if err != nil {
+			return netip.Prefix{}, errors.New("must be an IP address or CIDR")
+		}
+		addr = addr.Unmap()
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+	prefix, err := netip.ParsePrefix(raw)
+	if err != nil {
+		return netip.Prefix{}, errors.New("must be an IP address or CIDR")
+	}
+	return netip.PrefixFrom(prefix.Addr().Unmap(), prefix.Bits()).Masked(), nil
+}
+
+// Allowed reports whether ip may pass. An address that cannot be parsed is
+// only let through when no allowlist is being enforced.
+func (f *ipFilter) Allowed(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return !f.allowlistOnly
+	}
+	addr = addr.Unmap()
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	blocked := false
+	for _, rule := range f.rules {
+		if !rule.prefix.Contains(addr) {
+			continue
+		}
+		if rule.allow {
+			return true
+		}
+		blocked = true
+	}
+	return !blocked && !f.allowlistOnly
+}
+
+// Load swaps in the static rules plus the unexpired rows of ip_rules.
+func (f *ipFilter) Load(ctx context.Context, db *sql.DB) error {
+	rows, err := db.QueryContext(ctx, `
+		SELECT cidr, action FROM ip_rules
+		WHERE expires_at IS NULL OR expires_at > now()
+	`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	rules := append([]ipRule(nil), f.static...)
+	for rows.Next() {
+		var cidr, action string
+		if err := rows.Scan(&cidr, &action); err != nil {
+			return err
+		}
+		prefix, err := parseIPPrefix(cidr)
+		if err != nil {
+			slog.Warn("ignoring invalid ip_rules row", "cidr", cidr, "error", err)
+			continue
+		}
+		rules = append(rules, ipRule{prefix: prefix, allow: action == "allow"})
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	f.rules = rules
+	f.mu.Unlock()
+	return nil
+}
+
+// ipFilterMiddleware runs ahead of the rate limiters so blocked clients do
+// not use up anybody's budget.
+func (s *Server) ipFilterMiddleware(next nethttp.Handler) nethttp.Handler {
+	return nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		if !s.ipFilter.Allowed(s.clientIPFromRequest(r)) {
+			writeProblem(w, nethttp.StatusForbidden, errorCodeIPBlocked, "access denied")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) reloadIPRules(ctx context.Context) {
+	ctx, cancel := withBudget(ctx, statsQueryBudget)
+	defer cancel()
+	if err := s.ipFilter.Load(ctx, s.db); err != nil && ctx.Err() == nil {
+		slog.Warn("ip rules reload failed; keeping the previous rules", "error", err)
+	}
+}
+
+func (s *Server) handleListIPRules(w nethttp.ResponseWriter, r *nethttp.Request) {
+	ctx, cancel := withBudget(r.Context(), statsQueryBudget)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, cidr, action, reason, expires_at, created_at
+		FROM ip_rules
+		WHERE expires_at IS NULL OR expires_at > now()
+		ORDER BY created_at DESC
+	`)
+	if err != nil {
+		s.writeServerError(w, err, "failed to load ip rules")
+		return
+	}
+	defer rows.Close()
+
+	items := make([]ipRuleView, 0, 16)
+	for rows.Next() {
+		var (
+			v  ipRuleView
+			id uuid.UUID
+		)
+		if err := rows.Scan(&id, &v.CIDR, &v.Action, &v.Reason, &v.ExpiresAt, &v.CreatedAt); err != nil {
+			s.writeServerError(w, err, "failed to load ip rules")
+			return
+		}
+		v.ID = id.String()
+		items = append(items, v)
+	}
+	if err := rows.Err(); err != nil {
+		s.writeServerError(w, err, "failed to load ip rules")
+		return
+	}
+	writeJSON(w, nethttp.StatusOK, map[string]any{"items": items})
+}
+
+// handleCreateIPRule adds or replaces a rule and applies it on every
+// instance straight away. Adding the same CIDR and action again updates its
+// reason and expiry.
+func (s *Server) handleCreateIPRule(w nethttp.ResponseWriter, r *nethttp.Request) {
+	var req createIPRuleRequest
+	if err := decodeJSON(w, r, maxIPRuleBodyBytes, &req); err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	prefix, err := parseIPPrefix(req.CIDR)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, fmt.Sprintf("cidr %s", err))
+		return
+	}
+	action := strings.ToLower(strings.TrimSpace(req.Action))
+	if action != "allow" && action != "block" {
+		writeError(w, nethttp.StatusBadRequest, "action must be allow or block")
+		return
+	}
+	reason, err := normalizeOptionalText(req.Reason, ipRuleReasonMaxLength, "reason", false)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+	var expiresAt *time.Time
+	if ttl := strings.TrimSpace(req.TTL); ttl != "" {
+		d, err := time.ParseDuration(ttl)
+		if err != nil || d <= 0 {
+			writeError(w, nethttp.StatusBadRequest, "ttl must be a positive duration such as 30m or 24h")
+			return
+		}
+		at := time.Now().Add(d).UTC()
+		expiresAt = &at
+	}
+
+	ctx := r.Context()
+	var v ipRuleView
+	var id uuid.UUID
+	err = s.db.QueryRowContext(ctx, `
+		INSERT INTO ip_rules (id, cidr, action, reason, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (cidr, action) DO UPDATE SET
+			reason = EXCLUDED.reason,
+			expires_at = EXCLUDED.expires_at
+		RETURNING id, cidr, action, reason, expires_at, created_at
+	`, uuid.New(), prefix.String(), action, reason, expiresAt).Scan(&id, &v.CIDR, &v.Action, &v.Reason, &v.ExpiresAt, &v.CreatedAt)
+	if err != nil {
+		s.writeServerError(w, err, "failed to save ip rule")
+		return
+	}
+	v.ID = id.String()
+
+	s.reloadIPRules(ctx)
+	writeJSON(w, nethttp.StatusCreated, v)
+
+	s.announceChange(ctx, ipRulesChangedEvent, uuid.Nil, liveChange{})
+}
+
+func (s *Server) handleDeleteIPRule(w nethttp.ResponseWriter, r *nethttp.Request) {
+	id, err := uuid.Parse(strings.TrimSpace(chi.URLParam(r, "id")))
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, "ip rule id must be a valid UUID")
+		return
+	}
+
+	ctx := r.Context()
+	result, err := s.db.ExecContext(ctx, `DELETE FROM ip_rules WHERE id = $1`, id)
+	if err != nil {
+		s.writeServerError(w, err, "failed to delete ip rule")
+		return
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		writeError(w, nethttp.StatusNotFound, "ip rule not found")
+		return
+	}
+
+	s.reloadIPRules(ctx)
+	w.WriteHeader(nethttp.StatusNoContent)
+
+	s.announceChange(ctx, ipRulesChangedEvent, uuid.Nil, liveChange{})
+}
@@ -0,0 +1,283 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/jobs"
+	"ratemylifedecision/internal/notify"
+	"ratemylifedecision/internal/projections"
+	"ratemylifedecision/internal/store"
+)
+
+// Background job names, as reported by /api/admin/status.
+const (
+	jobCloseDecisions   = "close_decisions"
+	jobCloseDigests     = "close_digests"
+	jobCloseReminders   = "close_reminders"
+	jobWebhooks         = "webhooks"
+	jobProjections      = "projections"
+	jobAdaptiveLimits   = "adaptive_limits"
+	jobIPRulesRefresh   = "ip_rules_refresh"
+	jobAPIKeysRefresh   = "api_keys_refresh"
+	jobCacheSweep       = "decision_cache_sweep"
+	jobRateLimitSweep   = "rate_limit_sweep"
+	jobDeliveryLogPrune = "delivery_log_retention"
+	jobRetention        = "decision_retention"
+	jobLeaderboards     = "leaderboards"
+)
+
+const (
+	closeDecisionsBatchSize = 100
+	sweepInterval           = time.Minute
+	retentionDeleteBatch    = 5000
+)
+
+// registerJobs adds the server's periodic work to s.jobs. Intervals come
+// from s.cfg.Jobs; the feature switches leave a job out entirely.
+func (s *Server) registerJobs() {
+	s.jobs.Add(jobs.Job{
+		Name:     jobCloseDecisions,
+		Interval: s.cfg.Jobs.DecisionCloseInterval,
+		Run:      s.closeDueDecisions,
+	})
+	s.jobs.Add(jobs.Job{
+		Name:     jobCloseReminders,
+		Interval: s.cfg.Jobs.ReminderInterval,
+		Run:      s.sendCloseReminders,
+	})
+	if s.mailer != nil {
+		s.jobs.Add(jobs.Job{
+			Name:     jobCloseDigests,
+			Interval: s.cfg.Jobs.CloseDigestInterval,
+			Run:      s.sendCloseDigests,
+		})
+	}
+	if s.cfg.Features.WebhooksEnabled {
+		s.jobs.Add(jobs.Job{
+			Name:     jobWebhooks,
+			Interval: s.cfg.Jobs.WebhookDeliveryInterval,
+			Timeout:  time.Minute,
+			Run:      s.webhooks.Process,
+		})
+	}
+	if s.cfg.Features.ProjectionsEnabled {
+		projector := projections.NewProjector(s.db)
+		s.jobs.Add(jobs.Job{
+			Name:     jobProjections,
+			Interval: s.cfg.Jobs.ProjectionInterval,
+			Timeout:  time.Minute,
+			Run: func(ctx context.Context) error {
+				_, err := projector.ProcessPending(ctx)
+				return err
+			},
+		})
+	}
+	if s.cfg.Features.AdaptiveRateLimits {
+		s.jobs.Add(jobs.Job{
+			Name:     jobAdaptiveLimits,
+			Interval: adaptiveTickInterval,
+			Run: func(ctx context.Context) error {
+				s.adjustAdaptiveLimits(ctx)
+				return nil
+			},
+		})
+	}
+	s.jobs.Add(jobs.Job{
+		Name:     jobLeaderboards,
+		Interval: s.cfg.Jobs.LeaderboardInterval,
+		Timeout:  time.Minute,
+		Run:      s.refreshLeaderboards,
+	})
+	// Rank the boards now rather than a whole interval after startup.
+	s.jobs.Trigger(jobLeaderboards)
+	s.jobs.Add(jobs.Job{
+		Name:     jobIPRulesRefresh,
+		Interval: s.cfg.Jobs.IPRulesRefresh,
+		Run: func(ctx context.Context) error {
+			s.reloadIPRules(ctx)
+			return nil
+		},
+	})
+	// Load the IP rules now rather than a whole interval after startup.
+	s.jobs.Trigger(jobIPRulesRefresh)
+	s.jobs.Add(jobs.Job{
+		Name:     jobAPIKeysRefresh,
+		Interval: s.cfg.Jobs.APIKeysRefresh,
+		Run: func(ctx context.Context) error {
+			s.reloadAPIKeys(ctx)
+			return nil
+		},
+	})
+	s.jobs.Trigger(jobAPIKeysRefresh)
+
+	s.jobs.Add(jobs.Job{
+		Name:     jobCacheSweep,
+		Interval: sweepInterval,
+		Run: func(context.Context) error {
+			s.cache.Sweep(time.Now())
+			return nil
+		},
+	})
+	s.jobs.Add(jobs.Job{
+		Name:     jobRateLimitSweep,
+		Interval: sweepInterval,
+		Run: func(context.Context) error {
+			now := time.Now()
+			s.viewerLimiter.Sweep(now)
+			for _, rl := range s.rateLimits {
+				rl.limiter.Sweep(now)
+			}
+			return nil
+		},
+	})
+	s.jobs.Add(jobs.Job{
+		Name:     jobDeliveryLogPrune,
+		Interval: s.cfg.Jobs.RetentionInterval,
+		Timeout:  5 * time.Minute,
+		Run:      s.pruneDeliveryLogs,
+	})
+	if s.retention.mode != retentionModeOff {
+		s.jobs.Add(jobs.Job{
+			Name:     jobRetention,
+			Interval: s.cfg.Jobs.RetentionInterval,
+			Timeout:  10 * time.Minute,
+			Run:      s.applyRetention,
+		})
+	}
+}
+
+type closedDecision struct {
+	id    uuid.UUID
+	slug  string
+	title string
+}
+
+// closeDueDecisions marks decisions closed once closes_at has passed, so
+// closing takes effect (and is announced) even if nobody is looking at the
+// decision. Rows are claimed with SKIP LOCKED so instances share the work.
+func (s *Server) closeDueDecisions(ctx context.Context) error {
+	rows, err := s.db.QueryContext(ctx, `
+		UPDATE decisions SET closed_at = closes_at
+		WHERE id IN (
+			SELECT id FROM decisions
+			WHERE closed_at IS NULL AND closes_at <= now()
+			ORDER BY closes_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, slug, title
+	`, closeDecisionsBatchSize)
+	if err != nil {
+		return err
+	}
+	var closed []closedDecision
+	for rows.Next() {
+		var d closedDecision
+		if err := rows.Scan(&d.id, &d.slug, &d.title); err != nil {
+			rows.Close()
+			return err
+		}
+		closed = append(closed, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(closed) == 0 {
+		return nil
+	}
+
+	var errs []error
+	for _, d := range closed {
+		s.cache.Invalidate(d.id)
+		// The first read after closing freezes the results.
+		if _, _, _, err := s.loadDecisionView(ctx, d.slug, nil); err != nil && !errors.Is(err, store.ErrNotFound) {
+			errs = append(errs, fmt.Errorf("freeze results of %s: %w", d.id, err))
+		}
+		s.publishLiveUpdate(ctx, "decision_closed", d.id, nil)
+		if err := s.notifyDecisionClosed(ctx, d); err != nil {
+			errs = append(errs, fmt.Errorf("notify close of %s: %w", d.id, err))
+		}
+	}
+	s.jobs.Trigger(jobWebhooks)
+	s.jobs.Trigger(jobCloseDigests)
+	return errors.Join(errs...)
+}
+
+func (s *Server) notifyDecisionClosed(ctx context.Context, d closedDecision) error {
+	rec, err := s.loadRecommendation(ctx, d.id)
+	if err != nil {
+		return err
+	}
+	st, err := s.loadDecisionStats(ctx, d.id)
+	if err != nil {
+		return err
+	}
+	s.notifier.DispatchAsync(notify.Event{
+		Kind:          notify.KindDecisionClosed,
+		DecisionID:    d.id,
+		DecisionSlug:  d.slug,
+		DecisionTitle: d.title,
+		ShareURL:      s.shareURL(d.slug),
+		DedupeKey:     "closed",
+		Data: map[string]any{
+			"response_count": st.ResponseCount,
+			"recommendation": verdictText(rec.Decision),
+		},
+	})
+	return nil
+}
+
+// pruneDeliveryLogs deletes finished webhook and notification deliveries
+// older than DELIVERY_LOG_RETENTION, and IP rules that have expired. Each
+// table is trimmed a batch at a time to keep transactions short.
+func (s *Server) pruneDeliveryLogs(ctx context.Context) error {
+	retention := s.cfg.Jobs.DeliveryLogRetention
+
+	if err := s.deleteInBatches(ctx, `
+		DELETE FROM webhook_deliveries WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status <> 'pending' AND created_at < now() - $1 * interval '1 second'
+			LIMIT $2
+		)
+	`, retention.Seconds()); err != nil {
+		return fmt.Errorf("prune webhook deliveries: %w", err)
+	}
+	if err := s.deleteInBatches(ctx, `
+		DELETE FROM notification_deliveries WHERE id IN (
+			SELECT id FROM notification_deliveries
+			WHERE status <> 'pending' AND created_at < now() - $1 * interval '1 second'
+			LIMIT $2
+		)
+	`, retention.Seconds()); err != nil {
+		return fmt.Errorf("prune notification deliveries: %w", err)
+	}
+	if err := s.deleteInBatches(ctx, `
+		DELETE FROM ip_rules WHERE id IN (
+			SELECT id FROM ip_rules WHERE expires_at < now() LIMIT $1
+		)
+	`); err != nil {
+		return fmt.Errorf("prune expired ip rules: %w", err)
+	}
+	return nil
+}
+
+// deleteInBatches runs query until it deletes fewer than a full batch. The
+// batch size is passed as the last argument.
+func (s *Server) deleteInBatches(ctx context.Context, query string, args ...any) error {
+	args = append(args, retentionDeleteBatch)
+	for {
+		result, err := s.db.ExecContext(ctx, query, args...)
+		if err != nil {
+			return err
+		}
+		if n, _ := result.RowsAffected(); n < retentionDeleteBatch {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package httpapi
+
+import (
+	"sort"
+
+	"ratemylifedecision/internal/store"
+)
+
+type languageCount struct {
+	Language string `json:"language"`
+	Count    int    `json:"count"`
+}
+
+// buildLanguageBreakdown counts responses by the language of their comment,
+// most common first. Responses without a comment, or in a language that
+// could not be told, are left out.
+func buildLanguageBreakdown(responses []store.Response) []languageCount {
+	counts := make(map[string]int)
+	for _, r := range responses {
+		if r.Language != nil {
+			counts[*r.Language]++
+		}
+	}
+	out := make([]languageCount, 0, len(counts))
+	for lang, n := range counts {
+		out = append(out, languageCount{Language: lang, Count: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Language < out[j].Language
+	})
+	return out
+}
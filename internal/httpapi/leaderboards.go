@@ -0,0 +1,100 @@
+package httpapi
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+
+	"ratemylifedecision/internal/database"
+	"ratemylifedecision/internal/projections"
+)
+
+const (
+	// leaderboardSize is how many decisions each board keeps.
+	leaderboardSize = maxInsightsLimit
+	// controversyMinVotes keeps a 1-1 split off the controversial board.
+	controversyMinVotes = 10
+)
+
+// leaderboardOrders ranks each board, best first, over rm_decision_activity
+// a. A decision's controversy is twice its minority vote count
+// (vote_count - |vote_score|), so it takes both an even split and a lot of
+// votes to rank.
+var leaderboardOrders = map[string]string{
+	"score":         "a.vote_score DESC, a.vote_count DESC",
+	"responses":     "a.response_count DESC",
+	"votes":         "a.vote_count DESC",
+	"controversial": "(a.vote_count - abs(a.vote_score)) DESC, a.vote_count DESC",
+}
+
+var leaderboardFilters = map[string]string{
+	"controversial": "a.vote_count >= " + strconv.Itoa(controversyMinVotes),
+}
+
+// refreshLeaderboards rebuilds rm_leaderboards from the activity read model.
+// Readers see the old boards or the new ones, never a mix.
+func (s *Server) refreshLeaderboards(ctx context.Context) error {
+	return database.RetryTx(ctx, s.db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM rm_leaderboards`); err != nil {
+			return err
+		}
+		for board, orderBy := range leaderboardOrders {
+			filter := "true"
+			if f, ok := leaderboardFilters[board]; ok {
+				filter = f
+			}
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO rm_leaderboards (board, rank, decision_id)
+				SELECT $1, row_number() OVER (ORDER BY `+orderBy+`, a.decision_id), a.decision_id
+				FROM rm_decision_activity a
+				JOIN decisions d ON d.id = a.decision_id
+				WHERE d.hidden_at IS NULL AND d.visibility = 'public' AND `+filter+`
+				ORDER BY `+orderBy+`, a.decision_id
+				LIMIT $2
+			`, board, leaderboardSize); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// queryLeaderboard reads the top of board as of its last refresh. Counts
+// are current; the order is the refresh's.
+func (s *Server) queryLeaderboard(ctx context.Context, board string, limit int) ([]activityItem, error) {
+	ctx, cancel := withBudget(ctx, statsQueryBudget)
+	defer cancel()
+
+	halfLife := strconv.FormatFloat(projections.TrendHalfLife.Seconds(), 'f', -1, 64)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			d.slug, d.title, a.category,
+			a.response_count, a.vote_score, a.vote_count,
+			a.trend_score * power(0.5, EXTRACT(EPOCH FROM (now() - a.trend_at))::float8 / `+halfLife+`) AS trend_now,
+			a.last_activity_at
+		FROM rm_leaderboards lb
+		JOIN rm_decision_activity a ON a.decision_id = lb.decision_id
+		JOIN decisions d ON d.id = lb.decision_id
+		WHERE lb.board = $1 AND d.hidden_at IS NULL AND d.visibility = 'public'
+		ORDER BY lb.rank
+		LIMIT $2
+	`, board, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]activityItem, 0, limit)
+	for rows.Next() {
+		var it activityItem
+		if err := rows.Scan(
+			&it.Slug, &it.Title, &it.Category,
+			&it.ResponseCount, &it.VoteScore, &it.VoteCount,
+			&it.TrendScore, &it.LastActivityAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
@@ -0,0 +1,322 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	nethttp "net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/store"
+)
+
+const (
+	liveSubscriberBuffer = 16
+	liveWriteTimeout     = 5 * time.Second
+	livePingInterval     = 30 * time.Second
+	livePublishTimeout   = 5 * time.Second
+)
+
+type liveHub struct {
+	mu          sync.Mutex
+	subscribers map[uuid.UUID]map[*liveSubscriber]struct{}
+}
+
+type liveSubscriber struct {
+	events chan liveMessage
+}
+
+type liveMessage struct {
+	event   liveEvent
+	payload []byte
+}
+
+type liveEvent struct {
+	Type           string              `json:"type"`
+	DecisionID     string              `json:"decision_id"`
+	Response       *responseCard       `json:"response,omitempty"`
+	Reactions      *liveReactions      `json:"reactions,omitempty"`
+	PostVote       *livePostVote       `json:"post_vote,omitempty"`
+	Stats          *decisionStats      `json:"stats,omitempty"`
+	Recommendation *recommendationView `json:"recommendation,omitempty"`
+}
+
+// liveChange is what an event says changed, beyond the results every event
+// carries: a response, or just a response's reaction counts.
+type liveChange struct {
+	Response  *responseCard
+	Reactions *liveReactions
+}
+
+// liveReactions is the reactions_changed payload. Nothing else about the
+// response changed, so sending a whole card would mean loading one.
+type liveReactions struct {
+	ResponseID string         `json:"response_id"`
+	Reactions  map[string]int `json:"reactions"`
+}
+
+type livePostVote struct {
+	Score     int `json:"score"`
+	Upvotes   int `json:"upvotes"`
+	Downvotes int `json:"downvotes"`
+}
+
+func newLiveHub() *liveHub {
+	return &liveHub{
+		subscribers: make(map[uuid.UUID]map[*liveSubscriber]struct{}, 64),
+	}
+}
+
+func (h *liveHub) Subscribe(decisionID uuid.UUID) *liveSubscriber {
+	sub := &liveSubscriber{events: make(chan liveMessage, liveSubscriberBuffer)}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	subs, ok := h.subscribers[decisionID]
+	if !ok {
+		subs = make(map[*liveSubscriber]struct{}, 4)
+		h.subscribers[decisionID] = subs
+	}
+	subs[sub] = struct{}{}
+	return sub
+}
+
+func (h *liveHub) Unsubscribe(decisionID uuid.UUID, sub *liveSubscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.removeLocked(decisionID, sub)
+}
+
+func (h *liveHub) HasSubscribers(decisionID uuid.UUID) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subscribers[decisionID]) > 0
+}
+
+// Broadcast never blocks the publisher: subscribers whose buffer is full are
+// dropped and their channel closed so the client reconnects with fresh state.
+func (h *liveHub) Broadcast(decisionID uuid.UUID, msg liveMessage) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subscribers[decisionID] {
+		select {
+		case sub.events <- msg:
+		default:
+			h.removeLocked(decisionID, sub)
+		}
+	}
+}
+
+// Counts returns how many decisions have live subscribers and how many
+// subscribers there are in total.
+func (h *liveHub) Counts() (decisions, subscribers int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, subs := range h.subscribers {
+		subscribers += len(subs)
+	}
+	return len(h.subscribers), subscribers
+}
+
+func (h *liveHub) removeLocked(decisionID uuid.UUID, sub *liveSubscriber) {
+	subs, ok := h.subscribers[decisionID]
+	if !ok {
+		return
+	}
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	close(sub.events)
+	if len(subs) == 0 {
+		delete(h.subscribers, decisionID)
+	}
+}
+
+func (s *Server) handleDecisionWebSocket(w nethttp.ResponseWriter, r *nethttp.Request) {
+	slug, err := normalizeSlugParam(chi.URLParam(r, "slug"))
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+	if origin := strings.TrimSpace(r.Header.Get("Origin")); origin != "" && !s.isOriginAllowed(origin) {
+		writeProblem(w, nethttp.StatusForbidden, errorCodeOriginNotAllowed, "origin not allowed")
+		return
+	}
+
+	decision, err := s.decisions.BySlug(r.Context(), slug)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			writeProblem(w, nethttp.StatusNotFound, errorCodeDecisionNotFound, "decision not found")
+			return
+		}
+		s.writeServerError(w, err, "failed to load decision")
+		return
+	}
+	if !requireDecisionAccess(w, r, decision) {
+		return
+	}
+
+	snapshot, err := s.buildLiveEvent(r.Context(), "snapshot", decision.ID, liveChange{})
+	if err != nil {
+		s.writeServerError(w, err, "failed to load decision stats")
+		return
+	}
+	snapshotPayload, err := json.Marshal(snapshot)
+	if err != nil {
+		s.writeServerError(w, err, "failed to encode decision stats")
+		return
+	}
+
+	// The origin has already been checked against CORS_ALLOWED_ORIGINS above.
+	conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{InsecureSkipVerify: true})
+	if err != nil {
+		return
+	}
+	defer conn.CloseNow()
+
+	sub := s.hub.Subscribe(decision.ID)
+	defer s.hub.Unsubscribe(decision.ID, sub)
+
+	ctx := conn.CloseRead(r.Context())
+	if err := writeLiveMessage(ctx, conn, snapshotPayload); err != nil {
+		return
+	}
+
+	ping := time.NewTicker(livePingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.shutdown.Done():
+			_ = conn.Close(websocket.StatusGoingAway, "server shutting down")
+			return
+		case msg, ok := <-sub.events:
+			if !ok {
+				_ = conn.Close(websocket.StatusTryAgainLater, "subscriber fell behind")
+				return
+			}
+			if err := writeLiveMessage(ctx, conn, msg.payload); err != nil {
+				return
+			}
+		case <-ping.C:
+			pingCtx, cancel := context.WithTimeout(ctx, liveWriteTimeout)
+			err := conn.Ping(pingCtx)
+			cancel()
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+func writeLiveMessage(ctx context.Context, conn *websocket.Conn, payload []byte) error {
+	writeCtx, cancel := context.WithTimeout(ctx, liveWriteTimeout)
+	defer cancel()
+	return conn.Write(writeCtx, websocket.MessageText, payload)
+}
+
+// publishLiveUpdate recomputes the aggregate view of a decision and pushes it
+// to connected clients. It is a no-op when nobody is watching the decision.
+// publishLiveUpdate pushes a change to this instance's subscribers and
+// announces it to the other instances, which do the same for theirs.
+func (s *Server) publishLiveUpdate(ctx context.Context, eventType string, decisionID uuid.UUID, response *responseCard) {
+	s.publishLiveChange(ctx, eventType, decisionID, liveChange{Response: response})
+}
+
+func (s *Server) publishLiveChange(ctx context.Context, eventType string, decisionID uuid.UUID, change liveChange) {
+	s.announceChange(ctx, eventType, decisionID, change)
+	s.broadcastLiveUpdate(ctx, eventType, decisionID, change)
+}
+
+func (s *Server) broadcastLiveUpdate(ctx context.Context, eventType string, decisionID uuid.UUID, change liveChange) {
+	if !s.hub.HasSubscribers(decisionID) {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), livePublishTimeout)
+	defer cancel()
+
+	event, err := s.buildLiveEvent(ctx, eventType, decisionID, change)
+	if err != nil {
+		return
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	s.hub.Broadcast(decisionID, liveMessage{event: event, payload: payload})
+}
+
+func (s *Server) buildLiveEvent(ctx context.Context, eventType string, decisionID uuid.UUID, change liveChange) (liveEvent, error) {
+	stats, recommendation, votes, err := s.loadLiveResults(ctx, decisionID)
+	if err != nil {
+		return liveEvent{}, err
+	}
+	pending, err := s.quorumPendingByID(ctx, decisionID, stats.ResponseCount)
+	if err != nil {
+		return liveEvent{}, err
+	}
+	if pending {
+		// Watchers learn a response came in, but not what it said.
+		stats, recommendation = withheldStats(stats.ResponseCount), withheldRecommendation()
+		votes = store.VoteSummary{}
+		if eventType == "response_created" || eventType == "response_updated" {
+			change.Response = nil
+		}
+		change.Reactions = nil
+	}
+
+	return liveEvent{
+		Type:       eventType,
+		DecisionID: decisionID.String(),
+		Response:   change.Response,
+		Reactions:  change.Reactions,
+		PostVote: &livePostVote{
+			Score:     votes.Score,
+			Upvotes:   votes.Upvotes,
+			Downvotes: votes.Downvotes,
+		},
+		Stats:          &stats,
+		Recommendation: &recommendation,
+	}, nil
+}
+
+// loadLiveResults reads the results a live event carries: the frozen ones
+// once the decision has closed, so late changes don't move them.
+func (s *Server) loadLiveResults(ctx context.Context, decisionID uuid.UUID) (decisionStats, recommendationView, store.VoteSummary, error) {
+	frozen, ok, err := s.loadFrozenResults(ctx, decisionID)
+	if err != nil {
+		return decisionStats{}, recommendationView{}, store.VoteSummary{}, err
+	}
+	if ok {
+		stats := frozen.Stats
+		stats.Timeline, stats.Languages = nil, nil
+		votes := store.VoteSummary{Score: frozen.PostVote.Score, Upvotes: frozen.PostVote.Upvotes, Downvotes: frozen.PostVote.Downvotes}
+		return stats, frozen.Recommendation, votes, nil
+	}
+
+	stats, err := s.loadDecisionStats(ctx, decisionID)
+	if err != nil {
+		return decisionStats{}, recommendationView{}, store.VoteSummary{}, err
+	}
+	recommendation, err := s.loadRecommendation(ctx, decisionID)
+	if err != nil {
+		return decisionStats{}, recommendationView{}, store.VoteSummary{}, err
+	}
+	votes, err := s.votes.Summary(ctx, decisionID, nil)
+	if err != nil {
+		return decisionStats{}, recommendationView{}, store.VoteSummary{}, err
+	}
+	return stats, recommendation, votes, nil
+}
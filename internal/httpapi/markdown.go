@@ -0,0 +1,164 @@
+package httpapi
+
+import (
+	"database/sql"
+	"errors"
+	"html"
+	nethttp "net/http"
+	"regexp"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// Comments support a deliberately small markdown subset: **bold**,
+// *italic* / _italic_, and single-level bulleted or numbered lists.
+// Everything else is treated as literal text and HTML-escaped on render.
+var (
+	htmlTagPattern         = regexp.MustCompile(`</?[a-zA-Z][^<>]*>`)
+	boldStarPattern        = regexp.MustCompile(`\*\*([^*\n]+?)\*\*`)
+	boldUnderscorePattern  = regexp.MustCompile(`(^|[^\p{L}\p{N}_])__([^_\n]+?)__($|[^\p{L}\p{N}_])`)
+	italicStarPattern      = regexp.MustCompile(`\*([^*\n]+?)\*`)
+	italicUnderscoreRegexp = regexp.MustCompile(`(^|[^\p{L}\p{N}_])_([^_\n]+?)_($|[^\p{L}\p{N}_])`)
+	bulletItemPattern      = regexp.MustCompile(`^\s*[-*+]\s+(.+)$`)
+	numberedItemPattern    = regexp.MustCompile(`^\s*\d{1,3}[.)]\s+(.+)$`)
+)
+
+// sanitizeCommentMarkdown drops inline HTML so the stored markdown only ever
+// contains the supported subset plus plain text. Stripping repeats until the
+// text is stable because removing one tag can splice a new one together,
+// e.g. "<<b>i>".
+func sanitizeCommentMarkdown(comment string) string {
+	for {
+		stripped := htmlTagPattern.ReplaceAllString(comment, "")
+		if stripped == comment {
+			return strings.TrimSpace(stripped)
+		}
+		comment = stripped
+	}
+}
+
+func renderCommentHTML(comment string) string {
+	var (
+		b         strings.Builder
+		paragraph []string
+		listTag   string
+	)
+
+	flushParagraph := func() {
+		if len(paragraph) == 0 {
+			return
+		}
+		b.WriteString("<p>")
+		b.WriteString(strings.Join(paragraph, "<br>"))
+		b.WriteString("</p>")
+		paragraph = nil
+	}
+	closeList := func() {
+		if listTag == "" {
+			return
+		}
+		b.WriteString("</" + listTag + ">")
+		listTag = ""
+	}
+	openList := func(tag string) {
+		if listTag == tag {
+			return
+		}
+		closeList()
+		b.WriteString("<" + tag + ">")
+		listTag = tag
+	}
+
+	for _, line := range strings.Split(normalizeLineBreaks(comment), "\n") {
+		if strings.TrimSpace(line) == "" {
+			flushParagraph()
+			closeList()
+			continue
+		}
+		if m := bulletItemPattern.FindStringSubmatch(line); m != nil {
+			flushParagraph()
+			openList("ul")
+			b.WriteString("<li>" + renderInlineMarkdown(m[1]) + "</li>")
+			continue
+		}
+		if m := numberedItemPattern.FindStringSubmatch(line); m != nil {
+			flushParagraph()
+			openList("ol")
+			b.WriteString("<li>" + renderInlineMarkdown(m[1]) + "</li>")
+			continue
+		}
+		closeList()
+		paragraph = append(paragraph, renderInlineMarkdown(strings.TrimSpace(line)))
+	}
+	flushParagraph()
+	closeList()
+
+	return b.String()
+}
+
+func renderInlineMarkdown(text string) string {
+	escaped := html.EscapeString(text)
+	escaped = boldStarPattern.ReplaceAllString(escaped, "<strong>$1</strong>")
+	escaped = boldUnderscorePattern.ReplaceAllString(escaped, "$1<strong>$2</strong>$3")
+	escaped = italicStarPattern.ReplaceAllString(escaped, "<em>$1</em>")
+	escaped = italicUnderscoreRegexp.ReplaceAllString(escaped, "$1<em>$2</em>$3")
+	return escaped
+}
+
+// stripCommentMarkdown reduces a comment to plain text for sentiment
+// analysis and plain-text channels such as Slack or SMS.
+func stripCommentMarkdown(comment string) string {
+	lines := strings.Split(normalizeLineBreaks(comment), "\n")
+	for i, line := range lines {
+		if m := bulletItemPattern.FindStringSubmatch(line); m != nil {
+			line = m[1]
+		} else if m := numberedItemPattern.FindStringSubmatch(line); m != nil {
+			line = m[1]
+		}
+		line = boldStarPattern.ReplaceAllString(line, "$1")
+		line = boldUnderscorePattern.ReplaceAllString(line, "$1$2$3")
+		line = italicStarPattern.ReplaceAllString(line, "$1")
+		line = italicUnderscoreRegexp.ReplaceAllString(line, "$1$2$3")
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func (s *Server) handleGetResponseHTML(w nethttp.ResponseWriter, r *nethttp.Request) {
+	responseID, err := uuid.Parse(strings.TrimSpace(chi.URLParam(r, "id")))
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, "response id must be a valid UUID")
+		return
+	}
+
+	// Responses on aggregate-only and private decisions are reported as
+	// missing so the endpoint cannot be used to probe for them. Hidden
+	// content is too.
+	var comment *string
+	err = s.db.QueryRowContext(r.Context(), `
+		SELECT r.comment
+		FROM responses r
+		JOIN decisions d ON d.id = r.decision_id
+		WHERE r.id = $1 AND NOT d.aggregate_only AND d.visibility <> 'private'
+		  AND r.hidden_at IS NULL AND d.hidden_at IS NULL
+	`, responseID).Scan(&comment)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, nethttp.StatusNotFound, "response not found")
+			return
+		}
+		s.writeServerError(w, err, "failed to load response")
+		return
+	}
+
+	if comment == nil {
+		w.WriteHeader(nethttp.StatusNoContent)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(nethttp.StatusOK)
+	_, _ = w.Write([]byte(renderCommentHTML(*comment)))
+}
@@ -0,0 +1,110 @@
+package httpapi
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	nethttp "net/http"
+	"sync/atomic"
+	"time"
+)
+
+// serverMetrics holds process-wide counters. They only ever increase;
+// consumers such as the adaptive limiter work on deltas between reads.
+type serverMetrics struct {
+	startedAt          time.Time
+	requestsTotal      atomic.Int64
+	serverErrorsTotal  atomic.Int64
+	dbProbesTotal      atomic.Int64
+	dbProbeErrorsTotal atomic.Int64
+	dbProbeLatencyNS   atomic.Int64
+	deadlineExceeded   atomic.Int64
+}
+
+type metricsSnapshot struct {
+	UptimeSeconds      int64   `json:"uptime_seconds"`
+	RequestsTotal      int64   `json:"requests_total"`
+	ServerErrorsTotal  int64   `json:"server_errors_total"`
+	DBProbesTotal      int64   `json:"db_probes_total"`
+	DBProbeErrorsTotal int64   `json:"db_probe_errors_total"`
+	DBProbeLatencyMS   float64 `json:"db_probe_latency_ms"`
+	DeadlineExceeded   int64   `json:"deadline_exceeded_total"`
+}
+
+func newServerMetrics() *serverMetrics {
+	return &serverMetrics{startedAt: time.Now()}
+}
+
+func (m *serverMetrics) ObserveRequest(status int) {
+	m.requestsTotal.Add(1)
+	if status >= 500 {
+		m.serverErrorsTotal.Add(1)
+	}
+}
+
+func (m *serverMetrics) ObserveDBProbe(latency time.Duration, err error) {
+	m.dbProbesTotal.Add(1)
+	if err != nil {
+		m.dbProbeErrorsTotal.Add(1)
+		return
+	}
+	m.dbProbeLatencyNS.Store(int64(latency))
+}
+
+func (m *serverMetrics) ObserveDeadlineExceeded() {
+	m.deadlineExceeded.Add(1)
+}
+
+func (m *serverMetrics) Snapshot() metricsSnapshot {
+	return metricsSnapshot{
+		UptimeSeconds:      int64(time.Since(m.startedAt).Seconds()),
+		RequestsTotal:      m.requestsTotal.Load(),
+		ServerErrorsTotal:  m.serverErrorsTotal.Load(),
+		DBProbesTotal:      m.dbProbesTotal.Load(),
+		DBProbeErrorsTotal: m.dbProbeErrorsTotal.Load(),
+		DBProbeLatencyMS:   float64(m.dbProbeLatencyNS.Load()) / float64(time.Millisecond),
+		DeadlineExceeded:   m.deadlineExceeded.Load(),
+	}
+}
+
+// statusRecorder captures the response status while still exposing the
+// Flusher and Hijacker of the underlying writer, which the SSE and
+// WebSocket handlers depend on. It also carries the request ID so that
+// writeError can echo it without needing the request.
+type statusRecorder struct {
+	nethttp.ResponseWriter
+	status      int
+	wroteHeader bool
+	requestID   string
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(nethttp.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := r.ResponseWriter.(nethttp.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	return h.Hijack()
+}
+
+func (r *statusRecorder) Unwrap() nethttp.ResponseWriter {
+	return r.ResponseWriter
+}
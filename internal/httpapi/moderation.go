@@ -0,0 +1,447 @@
+package httpapi
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/database"
+	"ratemylifedecision/internal/stats"
+)
+
+const (
+	defaultReportListLimit = 50
+	maxReportListLimit     = 200
+	maxBanBodyBytes        = 1024
+	banReasonMaxLength     = 500
+)
+
+type reportedItem struct {
+	TargetKind     string     `json:"target_kind"`
+	TargetID       string     `json:"target_id"`
+	Reports        int        `json:"reports"`
+	Reasons        []string   `json:"reasons"`
+	LastReportedAt time.Time  `json:"last_reported_at"`
+	DecisionSlug   *string    `json:"decision_slug"`
+	DecisionTitle  *string    `json:"decision_title"`
+	Comment        *string    `json:"comment,omitempty"`
+	HiddenAt       *time.Time `json:"hidden_at"`
+}
+
+type banViewerRequest struct {
+	Reason *string `json:"reason"`
+	// Shadow lets the viewer keep writing while nobody else sees the result.
+	Shadow bool `json:"shadow"`
+}
+
+// handleListReports groups reports by the content they target, most
+// reported first. ?status= picks pending (the default), actioned or
+// dismissed reports.
+func (s *Server) handleListReports(w nethttp.ResponseWriter, r *nethttp.Request) {
+	status := strings.TrimSpace(r.URL.Query().Get("status"))
+	switch status {
+	case "":
+		status = "pending"
+	case "pending", "actioned", "dismissed":
+	default:
+		writeError(w, nethttp.StatusBadRequest, "status must be pending, actioned or dismissed")
+		return
+	}
+	limit, err := parseLimitParam(r, defaultReportListLimit, maxReportListLimit)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := withBudget(r.Context(), statsQueryBudget)
+	defer cancel()
+
+	// Content deleted since it was reported still lists, with no slug.
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			rp.target_kind,
+			rp.target_id,
+			COUNT(*)::int AS reports,
+			to_jsonb(array_agg(DISTINCT rp.reason)) AS reasons,
+			MAX(rp.created_at) AS last_reported_at,
+			COALESCE(d.slug, rd.slug) AS decision_slug,
+			COALESCE(d.title, rd.title) AS decision_title,
+			r.comment,
+			COALESCE(d.hidden_at, r.hidden_at) AS hidden_at
+		FROM reports rp
+		LEFT JOIN decisions d ON rp.target_kind = 'decision' AND d.id = rp.target_id
+		LEFT JOIN responses r ON rp.target_kind = 'response' AND r.id = rp.target_id
+		LEFT JOIN decisions rd ON rd.id = r.decision_id
+		WHERE rp.status = $1
+		GROUP BY rp.target_kind, rp.target_id, d.id, r.id, rd.id
+		ORDER BY COUNT(*) DESC, MAX(rp.created_at) DESC
+		LIMIT $2
+	`, status, limit)
+	if err != nil {
+		s.writeServerError(w, err, "failed to load reports")
+		return
+	}
+	defer rows.Close()
+
+	items := make([]reportedItem, 0, limit)
+	for rows.Next() {
+		var (
+			it          reportedItem
+			targetID    uuid.UUID
+			reasonsJSON []byte
+		)
+		if err := rows.Scan(
+			&it.TargetKind, &targetID, &it.Reports, &reasonsJSON, &it.LastReportedAt,
+			&it.DecisionSlug, &it.DecisionTitle, &it.Comment, &it.HiddenAt,
+		); err != nil {
+			s.writeServerError(w, err, "failed to load reports")
+			return
+		}
+		if err := json.Unmarshal(reasonsJSON, &it.Reasons); err != nil {
+			s.writeServerError(w, err, "failed to load reports")
+			return
+		}
+		it.TargetID = targetID.String()
+		items = append(items, it)
+	}
+	if err := rows.Err(); err != nil {
+		s.writeServerError(w, err, "failed to load reports")
+		return
+	}
+	writeJSON(w, nethttp.StatusOK, map[string]any{"items": items})
+}
+
+func (s *Server) handleHideResponse(w nethttp.ResponseWriter, r *nethttp.Request) {
+	s.setResponseHidden(w, r, true)
+}
+
+func (s *Server) handleUnhideResponse(w nethttp.ResponseWriter, r *nethttp.Request) {
+	s.setResponseHidden(w, r, false)
+}
+
+func (s *Server) setResponseHidden(w nethttp.ResponseWriter, r *nethttp.Request, hidden bool) {
+	responseID, err := uuid.Parse(strings.TrimSpace(chi.URLParam(r, "id")))
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, "response id must be a valid UUID")
+		return
+	}
+
+	ctx := r.Context()
+	var decisionID uuid.UUID
+	err = s.moderate(ctx, reportTargetResponse, hidden, func(ctx context.Context, tx *sql.Tx) (uuid.UUID, error) {
+		err := tx.QueryRowContext(ctx, `
+			UPDATE responses
+			SET hidden_at = CASE WHEN $2::bool THEN COALESCE(hidden_at, now()) END
+			WHERE id = $1
+			RETURNING decision_id
+		`, responseID, hidden).Scan(&decisionID)
+		return responseID, err
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, nethttp.StatusNotFound, "response not found")
+			return
+		}
+		s.writeServerError(w, err, "failed to update response")
+		return
+	}
+
+	s.cache.Invalidate(decisionID)
+	audit := auditFromContext(ctx)
+	audit.setTarget("response", responseID.String())
+	audit.setAfter(map[string]any{"hidden": hidden})
+	writeJSON(w, nethttp.StatusOK, map[string]any{"hidden": hidden})
+
+	if hidden {
+		s.publishLiveUpdate(ctx, "response_hidden", decisionID, &responseCard{ID: responseID.String()})
+	} else {
+		s.publishLiveUpdate(ctx, "response_restored", decisionID, nil)
+	}
+}
+
+func (s *Server) handleHideDecision(w nethttp.ResponseWriter, r *nethttp.Request) {
+	s.setDecisionHidden(w, r, true)
+}
+
+func (s *Server) handleUnhideDecision(w nethttp.ResponseWriter, r *nethttp.Request) {
+	s.setDecisionHidden(w, r, false)
+}
+
+// setDecisionHidden looks the decision up by slug directly: the store
+// treats hidden decisions as missing.
+func (s *Server) setDecisionHidden(w nethttp.ResponseWriter, r *nethttp.Request, hidden bool) {
+	slug, err := normalizeSlugParam(chi.URLParam(r, "slug"))
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	var decisionID uuid.UUID
+	err = s.moderate(ctx, reportTargetDecision, hidden, func(ctx context.Context, tx *sql.Tx) (uuid.UUID, error) {
+		err := tx.QueryRowContext(ctx, `
+			UPDATE decisions
+			SET hidden_at = CASE WHEN $2::bool THEN COALESCE(hidden_at, now()) END
+			WHERE slug = $1
+			RETURNING id
+		`, slug, hidden).Scan(&decisionID)
+		return decisionID, err
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeProblem(w, nethttp.StatusNotFound, errorCodeDecisionNotFound, "decision not found")
+			return
+		}
+		s.writeServerError(w, err, "failed to update decision")
+		return
+	}
+
+	s.cache.Invalidate(decisionID)
+	audit := auditFromContext(ctx)
+	audit.setTarget("decision", decisionID.String())
+	audit.setAfter(map[string]any{"hidden": hidden})
+	writeJSON(w, nethttp.StatusOK, map[string]any{"hidden": hidden})
+
+	if hidden {
+		s.publishLiveUpdate(ctx, "decision_hidden", decisionID, nil)
+	} else {
+		s.publishLiveUpdate(ctx, "decision_restored", decisionID, nil)
+	}
+}
+
+// moderate runs update, which changes the target and returns its ID, and
+// closes the target's pending reports in the same transaction: actioned
+// when it was hidden, dismissed when it was restored, so restored content
+// is not hidden again by the reports that already counted against it.
+func (s *Server) moderate(ctx context.Context, target reportTarget, hidden bool, update func(ctx context.Context, tx *sql.Tx) (uuid.UUID, error)) error {
+	ctx, cancel := withBudget(ctx, writeQueryBudget)
+	defer cancel()
+
+	status := "dismissed"
+	if hidden {
+		status = "actioned"
+	}
+	return database.RetryTx(ctx, s.db, func(tx *sql.Tx) error {
+		targetID, err := update(ctx, tx)
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `
+			UPDATE reports SET status = $3, reviewed_at = now()
+			WHERE target_kind = $1 AND target_id = $2 AND status = 'pending'
+		`, target.kind, targetID, status)
+		return err
+	})
+}
+
+// handleDeleteDecision removes a decision and everything hanging off it.
+// Reports against it and its responses go too; category insights keep
+// counting it until projections are replayed.
+func (s *Server) handleDeleteDecision(w nethttp.ResponseWriter, r *nethttp.Request) {
+	slug, err := normalizeSlugParam(chi.URLParam(r, "slug"))
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	deleted, err := s.deleteDecision(r.Context(), slug)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeProblem(w, nethttp.StatusNotFound, errorCodeDecisionNotFound, "decision not found")
+			return
+		}
+		s.writeServerError(w, err, "failed to delete decision")
+		return
+	}
+	audit := auditFromContext(r.Context())
+	audit.setTarget("decision", deleted.ID)
+	audit.setBefore(deleted)
+	w.WriteHeader(nethttp.StatusNoContent)
+}
+
+// deleteDecision deletes the decision at slug with the reports against it
+// and its responses, then tells caches and live clients. It returns the
+// decision as it was, hidden or not, or sql.ErrNoRows when there is no such
+// decision.
+func (s *Server) deleteDecision(ctx context.Context, slug string) (decisionView, error) {
+	ctx, cancel := withBudget(ctx, writeQueryBudget)
+	defer cancel()
+	var (
+		decisionID uuid.UUID
+		deleted    decisionView
+	)
+	err := database.RetryTx(ctx, s.db, func(tx *sql.Tx) error {
+		if err := tx.QueryRowContext(ctx, `
+			SELECT id FROM decisions WHERE slug = $1 FOR UPDATE
+		`, slug).Scan(&decisionID); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			DELETE FROM reports
+			WHERE (target_kind = 'decision' AND target_id = $1)
+				OR (target_kind = 'response' AND target_id IN (SELECT id FROM responses WHERE decision_id = $1))
+		`, decisionID); err != nil {
+			return err
+		}
+		return tx.QueryRowContext(ctx, `
+			DELETE FROM decisions WHERE id = $1
+			RETURNING slug, title, description, closes_at, closed_at, archived_at, created_at, panel_only,
+				category, aggregate_only, quorum, visibility, max_responses, nickname_policy
+		`, decisionID).Scan(&deleted.Slug, &deleted.Title, &deleted.Description, &deleted.ClosesAt, &deleted.ClosedAt,
+			&deleted.ArchivedAt, &deleted.CreatedAt, &deleted.PanelOnly, &deleted.Category, &deleted.AggregateOnly,
+			&deleted.Quorum, &deleted.Visibility, &deleted.MaxResponses, &deleted.NicknamePolicy)
+	})
+	if err != nil {
+		return decisionView{}, err
+	}
+	deleted.ID = decisionID.String()
+
+	s.cache.Invalidate(decisionID)
+	s.publishLiveUpdate(ctx, "decision_deleted", decisionID, nil)
+	return deleted, nil
+}
+
+// handleBanViewer stops a viewer ID from writing, or with "shadow": true
+// keeps accepting its writes but leaves them out of everything others see.
+// Banning is idempotent; banning again replaces the reason and mode.
+func (s *Server) handleBanViewer(w nethttp.ResponseWriter, r *nethttp.Request) {
+	viewerID, err := uuid.Parse(strings.TrimSpace(chi.URLParam(r, "id")))
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, "viewer id must be a valid UUID")
+		return
+	}
+
+	var req banViewerRequest
+	if r.ContentLength != 0 {
+		if err := decodeJSON(w, r, maxBanBodyBytes, &req); err != nil {
+			writeError(w, nethttp.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	reason, err := normalizeOptionalText(req.Reason, banReasonMaxLength, "reason", true)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	decisionIDs, err := s.applyViewerBan(ctx, viewerID, req.Shadow, func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO banned_viewers (viewer_id, reason, shadow)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (viewer_id) DO UPDATE SET reason = EXCLUDED.reason, shadow = EXCLUDED.shadow
+		`, viewerID, reason, req.Shadow)
+		return err
+	})
+	if err != nil {
+		s.writeServerError(w, err, "failed to ban viewer")
+		return
+	}
+	audit := auditFromContext(ctx)
+	audit.setTarget("viewer", viewerID.String())
+	audit.setAfter(map[string]any{"shadow": req.Shadow, "reason": reason})
+	writeJSON(w, nethttp.StatusOK, map[string]any{"viewer_id": viewerID.String(), "banned": true, "shadow": req.Shadow})
+
+	s.announceModeratedDecisions(ctx, decisionIDs)
+}
+
+func (s *Server) handleUnbanViewer(w nethttp.ResponseWriter, r *nethttp.Request) {
+	viewerID, err := uuid.Parse(strings.TrimSpace(chi.URLParam(r, "id")))
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, "viewer id must be a valid UUID")
+		return
+	}
+
+	ctx := r.Context()
+	errNotBanned := errors.New("viewer is not banned")
+	decisionIDs, err := s.applyViewerBan(ctx, viewerID, false, func(ctx context.Context, tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, `DELETE FROM banned_viewers WHERE viewer_id = $1`, viewerID)
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err == nil && n == 0 {
+			return errNotBanned
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, errNotBanned) {
+			writeError(w, nethttp.StatusNotFound, err.Error())
+			return
+		}
+		s.writeServerError(w, err, "failed to unban viewer")
+		return
+	}
+	w.WriteHeader(nethttp.StatusNoContent)
+
+	s.announceModeratedDecisions(ctx, decisionIDs)
+}
+
+// applyViewerBan runs change, which writes the ban itself, then re-marks the
+// viewer's existing responses and votes as shadowed or not so that a
+// shadowban also covers what they wrote before it and lifting one restores
+// it. Stats of every decision touched are recomputed in the same
+// transaction; their IDs are returned.
+func (s *Server) applyViewerBan(ctx context.Context, viewerID uuid.UUID, shadowed bool, change func(ctx context.Context, tx *sql.Tx) error) ([]uuid.UUID, error) {
+	ctx, cancel := withBudget(ctx, writeQueryBudget)
+	defer cancel()
+
+	var decisionIDs []uuid.UUID
+	err := database.RetryTx(ctx, s.db, func(tx *sql.Tx) error {
+		decisionIDs = decisionIDs[:0]
+		if err := change(ctx, tx); err != nil {
+			return err
+		}
+
+		rows, err := tx.QueryContext(ctx, `
+			WITH r AS (
+				UPDATE responses SET shadowed = $2
+				WHERE viewer_id = $1 AND shadowed <> $2
+				RETURNING decision_id
+			), v AS (
+				UPDATE decision_votes SET shadowed = $2
+				WHERE voter_viewer_id = $1 AND shadowed <> $2
+				RETURNING decision_id
+			)
+			SELECT decision_id FROM r UNION SELECT decision_id FROM v
+		`, viewerID, shadowed)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var id uuid.UUID
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return err
+			}
+			decisionIDs = append(decisionIDs, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, id := range decisionIDs {
+			if _, err := stats.Repair(ctx, tx, &id); err != nil {
+				return fmt.Errorf("repair decision stats: %w", err)
+			}
+		}
+		return nil
+	})
+	return decisionIDs, err
+}
+
+func (s *Server) announceModeratedDecisions(ctx context.Context, decisionIDs []uuid.UUID) {
+	for _, id := range decisionIDs {
+		s.cache.Invalidate(id)
+		s.publishLiveUpdate(ctx, "viewer_moderated", id, nil)
+	}
+}
@@ -0,0 +1,124 @@
+package httpapi
+
+import (
+	"context"
+	"fmt"
+	nethttp "net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"ratemylifedecision/internal/projections"
+)
+
+// maxCreatorTokens caps how many decisions one dashboard request covers.
+const maxCreatorTokens = 100
+
+type myDecisionItem struct {
+	Slug      string     `json:"slug"`
+	Title     string     `json:"title"`
+	Category  *string    `json:"category"`
+	CreatedAt time.Time  `json:"created_at"`
+	ClosesAt  *time.Time `json:"closes_at"`
+	ClosedAt  *time.Time `json:"closed_at"`
+	// Status is "open" or "closed".
+	Status        string   `json:"status"`
+	ResponseCount int      `json:"response_count"`
+	AvgRating     *float64 `json:"avg_rating"`
+	VoteScore     int      `json:"vote_score"`
+	// ResponsesLastWeek and AvgRatingChangeLastWeek show where the decision
+	// is heading: how many responses came in over the past seven days and
+	// how far they moved the average rating, null if there was nothing to
+	// move.
+	ResponsesLastWeek       int      `json:"responses_last_week"`
+	AvgRatingChangeLastWeek *float64 `json:"avg_rating_change_last_week"`
+	// TrendScore is the decision's activity score, as on the trending
+	// insights.
+	TrendScore float64 `json:"trend_score"`
+}
+
+// handleListMyDecisions is the creator's dashboard. Decisions have no owner
+// beyond whoever holds the creator token, so the request lists its tokens,
+// comma-separated, in X-Creator-Token; tokens that match nothing are left
+// out rather than rejected, since a decision may have been removed.
+func (s *Server) handleListMyDecisions(w nethttp.ResponseWriter, r *nethttp.Request) {
+	var hashes []string
+	for _, token := range strings.Split(r.Header.Get("X-Creator-Token"), ",") {
+		if token = strings.TrimSpace(token); token != "" {
+			hashes = append(hashes, hashToken(token))
+		}
+	}
+	if len(hashes) == 0 {
+		writeProblem(w, nethttp.StatusUnauthorized, errorCodeCreatorTokenRequired, "missing creator token")
+		return
+	}
+	if len(hashes) > maxCreatorTokens {
+		writeError(w, nethttp.StatusBadRequest, fmt.Sprintf("at most %d creator tokens are accepted", maxCreatorTokens))
+		return
+	}
+
+	items, err := s.queryMyDecisions(r.Context(), hashes)
+	if err != nil {
+		s.writeServerError(w, err, "failed to load decisions")
+		return
+	}
+	writeJSON(w, nethttp.StatusOK, map[string]any{"items": items})
+}
+
+func (s *Server) queryMyDecisions(ctx context.Context, hashes []string) ([]myDecisionItem, error) {
+	ctx, cancel := withBudget(ctx, statsQueryBudget)
+	defer cancel()
+
+	halfLife := strconv.FormatFloat(projections.TrendHalfLife.Seconds(), 'f', -1, 64)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			d.slug, d.title, d.category, d.created_at, d.closes_at, d.closed_at,
+			d.closed_at IS NOT NULL OR (d.closes_at IS NOT NULL AND d.closes_at <= now()),
+			COALESCE(st.response_count, 0)::int,
+			CASE WHEN st.response_count > 0 THEN st.rating_sum::float8 / st.response_count END,
+			COALESCE(st.vote_sum, 0)::int,
+			recent.responses,
+			CASE WHEN recent.responses > 0 AND recent.earlier > 0
+				THEN recent.avg_rating - recent.earlier_avg_rating END,
+			COALESCE(a.trend_score * power(0.5, EXTRACT(EPOCH FROM (now() - a.trend_at))::float8 / `+halfLife+`), 0)
+		FROM decisions d
+		LEFT JOIN decision_stats st ON st.decision_id = d.id
+		LEFT JOIN rm_decision_activity a ON a.decision_id = d.id
+		CROSS JOIN LATERAL (
+			SELECT
+				COUNT(*) FILTER (WHERE r.created_at > now() - interval '7 days')::int AS responses,
+				COUNT(*) FILTER (WHERE r.created_at <= now() - interval '7 days')::int AS earlier,
+				AVG(r.rating)::float8 AS avg_rating,
+				(AVG(r.rating) FILTER (WHERE r.created_at <= now() - interval '7 days'))::float8 AS earlier_avg_rating
+			FROM responses r
+			WHERE r.decision_id = d.id AND r.hidden_at IS NULL AND NOT r.shadowed
+		) recent
+		WHERE d.creator_token_hash = ANY(string_to_array($1, ',')) AND d.hidden_at IS NULL
+		ORDER BY d.created_at DESC
+	`, strings.Join(hashes, ","))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]myDecisionItem, 0, len(hashes))
+	for rows.Next() {
+		var (
+			it     myDecisionItem
+			closed bool
+		)
+		if err := rows.Scan(
+			&it.Slug, &it.Title, &it.Category, &it.CreatedAt, &it.ClosesAt, &it.ClosedAt,
+			&closed, &it.ResponseCount, &it.AvgRating, &it.VoteScore,
+			&it.ResponsesLastWeek, &it.AvgRatingChangeLastWeek, &it.TrendScore,
+		); err != nil {
+			return nil, err
+		}
+		it.Status = "open"
+		if closed {
+			it.Status = "closed"
+		}
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
@@ -0,0 +1,165 @@
+package httpapi
+
+import (
+	"errors"
+	nethttp "net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"ratemylifedecision/internal/store"
+)
+
+const errorCodeNoResponse = "viewer_has_not_responded"
+
+type editResponseRequest struct {
+	ViewerToken string  `json:"viewer_token"`
+	Suggestion  int     `json:"suggestion"`
+	Emoji       string  `json:"emoji"`
+	Comment     *string `json:"comment"`
+}
+
+// handleUpdateMyResponse replaces the viewer's own response, checked the
+// same way as a new one, for as long as the decision is open.
+func (s *Server) handleUpdateMyResponse(w nethttp.ResponseWriter, r *nethttp.Request) {
+	var req editResponseRequest
+	if err := decodeJSON(w, r, maxResponseBodyBytes, &req); err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+	viewer, ok := s.requireViewer(w, r, req.ViewerToken, "")
+	if !ok {
+		return
+	}
+	emoji, rating, err := normalizeResponseChoice(req.Suggestion, req.Emoji)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	decision, ok := s.loadOpenDecision(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	comment, commentFlagged, err := normalizeComment(req.Comment, s.contentFilter)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+	if comment != nil {
+		duplicate, err := s.findDuplicateComment(ctx, decision.ID, *comment, &viewer.ID)
+		if err != nil {
+			s.writeServerError(w, err, "failed to check comment")
+			return
+		}
+		if duplicate {
+			writeProblem(w, nethttp.StatusConflict, errorCodeDuplicateComment, "comment repeats one already left on this decision")
+			return
+		}
+	}
+
+	ctx, cancel := withBudget(ctx, writeQueryBudget)
+	defer cancel()
+	response, err := s.responses.Update(ctx, store.ResponseEdit{
+		DecisionID: decision.ID,
+		ViewerID:   viewer.ID,
+		Rating:     rating,
+		Suggestion: req.Suggestion,
+		Emoji:      emoji,
+		Comment:    comment,
+		Language:   detectCommentLanguage(comment),
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			writeProblem(w, nethttp.StatusNotFound, errorCodeNoResponse, "viewer has not responded to this decision")
+			return
+		}
+		s.writeServerError(w, err, "failed to update response")
+		return
+	}
+
+	if commentFlagged {
+		s.flagForReview(ctx, reportTargetResponse, response.ID, "comment")
+	}
+	card := responseCardFromStore(response)
+	audit := auditFromContext(ctx)
+	audit.setTarget("response", response.ID.String())
+	audit.setAfter(card)
+	writeJSON(w, nethttp.StatusOK, card)
+	if response.Shadowed {
+		return
+	}
+	s.cache.Invalidate(decision.ID)
+	if decision.AggregateOnly {
+		s.publishLiveUpdate(ctx, "response_updated", decision.ID, nil)
+		return
+	}
+	s.publishLiveUpdate(ctx, "response_updated", decision.ID, &card)
+}
+
+// handleDeleteMyResponse takes back the viewer's own response while the
+// decision is open, so they can answer again from scratch. The viewer
+// token comes in X-Viewer-Token.
+func (s *Server) handleDeleteMyResponse(w nethttp.ResponseWriter, r *nethttp.Request) {
+	viewer, ok := s.requireViewer(w, r, strings.TrimSpace(r.Header.Get("X-Viewer-Token")), "")
+	if !ok {
+		return
+	}
+	decision, ok := s.loadOpenDecision(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := withBudget(r.Context(), writeQueryBudget)
+	defer cancel()
+	response, err := s.responses.Delete(ctx, decision.ID, viewer.ID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			writeProblem(w, nethttp.StatusNotFound, errorCodeNoResponse, "viewer has not responded to this decision")
+			return
+		}
+		s.writeServerError(w, err, "failed to delete response")
+		return
+	}
+
+	audit := auditFromContext(ctx)
+	audit.setTarget("response", response.ID.String())
+	audit.setBefore(responseCardFromStore(response))
+	w.WriteHeader(nethttp.StatusNoContent)
+	if response.Shadowed {
+		return
+	}
+	s.cache.Invalidate(decision.ID)
+	s.publishLiveUpdate(r.Context(), "response_deleted", decision.ID, &responseCard{ID: response.ID.String()})
+}
+
+// loadOpenDecision resolves the {slug} route param to a decision the request
+// may access and that still takes responses. It writes the error response
+// itself and reports whether the caller may proceed.
+func (s *Server) loadOpenDecision(w nethttp.ResponseWriter, r *nethttp.Request) (store.Decision, bool) {
+	slug, err := normalizeSlugParam(chi.URLParam(r, "slug"))
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return store.Decision{}, false
+	}
+	decision, err := s.decisions.BySlug(r.Context(), slug)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			writeProblem(w, nethttp.StatusNotFound, errorCodeDecisionNotFound, "decision not found")
+			return store.Decision{}, false
+		}
+		s.writeServerError(w, err, "failed to load decision")
+		return store.Decision{}, false
+	}
+	if !requireDecisionAccess(w, r, decision) {
+		return store.Decision{}, false
+	}
+	if decision.ClosesAt != nil && time.Now().After(decision.ClosesAt.UTC()) {
+		writeProblem(w, nethttp.StatusConflict, errorCodeDecisionClosed, "decision is closed")
+		return store.Decision{}, false
+	}
+	return decision, true
+}
@@ -0,0 +1,80 @@
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"strings"
+	"unicode/utf8"
+
+	"ratemylifedecision/internal/contentfilter"
+	"ratemylifedecision/internal/store"
+)
+
+const (
+	nicknameMinLength = 2
+	nicknameMaxLength = 32
+)
+
+const (
+	nicknamePolicyOptional  = "optional"
+	nicknamePolicyRequired  = "required"
+	nicknamePolicyForbidden = "forbidden"
+)
+
+const (
+	errorCodeNicknameRequired   = "nickname_required"
+	errorCodeNicknameNotAllowed = "nickname_not_allowed"
+)
+
+func normalizeNicknamePolicy(raw *string) (string, error) {
+	if raw == nil {
+		return nicknamePolicyOptional, nil
+	}
+	switch policy := strings.ToLower(strings.TrimSpace(*raw)); policy {
+	case "":
+		return nicknamePolicyOptional, nil
+	case nicknamePolicyOptional, nicknamePolicyRequired, nicknamePolicyForbidden:
+		return policy, nil
+	default:
+		return "", errors.New("nickname_policy must be optional, required, or forbidden")
+	}
+}
+
+// normalizeNickname trims a nickname and collapses its inner whitespace.
+// Nicknames are shown beside every response and need not be unique, so
+// unlike comments one that trips the content filter is refused outright in
+// every filter mode rather than masked or flagged.
+func normalizeNickname(raw *string, filter *contentfilter.Filter) (*string, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	nickname := strings.Join(strings.Fields(*raw), " ")
+	if nickname == "" {
+		return nil, nil
+	}
+	if containsDisallowedControlChars(nickname, false) {
+		return nil, errors.New("nickname contains unsupported control characters")
+	}
+	if length := utf8.RuneCountInString(nickname); length < nicknameMinLength || length > nicknameMaxLength {
+		return nil, fmt.Errorf("nickname must be between %d and %d characters", nicknameMinLength, nicknameMaxLength)
+	}
+	if _, matched, err := filter.Apply(nickname); err != nil || matched {
+		return nil, errors.New("nickname is not allowed")
+	}
+	return &nickname, nil
+}
+
+// checkNicknamePolicy holds a response's nickname to its decision's
+// nickname_policy, writing the problem if it doesn't comply.
+func checkNicknamePolicy(w nethttp.ResponseWriter, decision store.Decision, nickname *string) bool {
+	switch {
+	case decision.NicknamePolicy == nicknamePolicyRequired && nickname == nil:
+		writeProblem(w, nethttp.StatusBadRequest, errorCodeNicknameRequired, "this decision asks responders for a nickname")
+		return false
+	case decision.NicknamePolicy == nicknamePolicyForbidden && nickname != nil:
+		writeProblem(w, nethttp.StatusBadRequest, errorCodeNicknameNotAllowed, "responses to this decision are anonymous")
+		return false
+	}
+	return true
+}
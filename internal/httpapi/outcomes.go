@@ -0,0 +1,168 @@
+package httpapi
+
+import (
+	"context"
+	"fmt"
+	nethttp "net/http"
+	"time"
+)
+
+const (
+	maxOutcomeBodyBytes = 1024
+
+	errorCodeDecisionOpen = "decision_open"
+)
+
+type recordOutcomeRequest struct {
+	DidIt        *bool `json:"did_it"`
+	Satisfaction *int  `json:"satisfaction"`
+}
+
+type outcomeView struct {
+	DidIt               bool      `json:"did_it"`
+	Satisfaction        *int      `json:"satisfaction"`
+	Recommendation      string    `json:"recommendation"`
+	RecommendationScore float64   `json:"recommendation_score"`
+	FollowedCrowd       bool      `json:"followed_crowd"`
+	RecordedAt          time.Time `json:"recorded_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+type accuracyBucket struct {
+	Category                    *string  `json:"category,omitempty"`
+	Outcomes                    int      `json:"outcomes"`
+	FollowedCrowd               int      `json:"followed_crowd"`
+	Accuracy                    float64  `json:"accuracy"`
+	AvgSatisfactionWhenFollowed *float64 `json:"avg_satisfaction_when_followed"`
+	AvgSatisfactionWhenIgnored  *float64 `json:"avg_satisfaction_when_ignored"`
+}
+
+type accuracyInsightsResponse struct {
+	Overall    accuracyBucket   `json:"overall"`
+	ByCategory []accuracyBucket `json:"by_category"`
+}
+
+// handleRecordOutcome lets the creator report what they actually did, once
+// the decision has closed. The outcome is measured against the
+// recommendation frozen at close, the one the creator was shown, and is
+// copied in the first time an outcome is recorded so later satisfaction
+// updates cannot move the goalposts.
+func (s *Server) handleRecordOutcome(w nethttp.ResponseWriter, r *nethttp.Request) {
+	decision, ok := s.loadCreatorDecision(w, r)
+	if !ok {
+		return
+	}
+	if decision.ClosedAt == nil {
+		writeProblem(w, nethttp.StatusConflict, errorCodeDecisionOpen, "an outcome can only be recorded once the decision has closed")
+		return
+	}
+
+	var req recordOutcomeRequest
+	if err := decodeJSON(w, r, maxOutcomeBodyBytes, &req); err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+	if req.DidIt == nil {
+		writeError(w, nethttp.StatusBadRequest, "did_it is required")
+		return
+	}
+	if req.Satisfaction != nil && (*req.Satisfaction < 1 || *req.Satisfaction > 5) {
+		writeError(w, nethttp.StatusBadRequest, "satisfaction must be between 1 and 5")
+		return
+	}
+
+	ctx := r.Context()
+	recommendation, err := s.frozenRecommendation(ctx, decision)
+	if err != nil {
+		s.writeServerError(w, err, "failed to load decision recommendation")
+		return
+	}
+
+	// The score's lean is recorded even when there were too few responses
+	// to call it, so those outcomes still count towards accuracy.
+	var out outcomeView
+	err = s.db.QueryRowContext(ctx, `
+		INSERT INTO decision_outcomes (decision_id, did_it, satisfaction, recommendation, recommendation_score)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (decision_id) DO UPDATE SET
+			did_it = EXCLUDED.did_it,
+			satisfaction = COALESCE(EXCLUDED.satisfaction, decision_outcomes.satisfaction),
+			updated_at = now()
+		RETURNING did_it, satisfaction, recommendation, recommendation_score, recorded_at, updated_at
+	`, decision.ID, *req.DidIt, req.Satisfaction, recommendation.lean(), recommendation.Score).Scan(
+		&out.DidIt,
+		&out.Satisfaction,
+		&out.Recommendation,
+		&out.RecommendationScore,
+		&out.RecordedAt,
+		&out.UpdatedAt,
+	)
+	if err != nil {
+		s.writeServerError(w, err, "failed to record outcome")
+		return
+	}
+	out.FollowedCrowd = out.DidIt == (out.Recommendation == "yes")
+
+	writeJSON(w, nethttp.StatusOK, out)
+}
+
+func (s *Server) handleAccuracyInsights(w nethttp.ResponseWriter, r *nethttp.Request) {
+	ctx := r.Context()
+
+	overall, err := s.queryAccuracy(ctx, false)
+	if err != nil || len(overall) != 1 {
+		s.writeServerError(w, err, "failed to compute accuracy")
+		return
+	}
+	byCategory, err := s.queryAccuracy(ctx, true)
+	if err != nil {
+		s.writeServerError(w, err, "failed to compute accuracy")
+		return
+	}
+
+	writeJSON(w, nethttp.StatusOK, accuracyInsightsResponse{
+		Overall:    overall[0],
+		ByCategory: byCategory,
+	})
+}
+
+func (s *Server) queryAccuracy(ctx context.Context, byCategory bool) ([]accuracyBucket, error) {
+	ctx, cancel := withBudget(ctx, statsQueryBudget)
+	defer cancel()
+
+	groupBy := ""
+	categoryExpr := "NULL::text"
+	if byCategory {
+		groupBy = "GROUP BY d.category ORDER BY COUNT(*) DESC, d.category ASC"
+		categoryExpr = "COALESCE(d.category, 'uncategorized')"
+	}
+
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT
+			%s AS category,
+			COUNT(o.decision_id)::int AS outcomes,
+			COUNT(o.decision_id) FILTER (WHERE o.did_it = (o.recommendation = 'yes'))::int AS followed,
+			AVG(o.satisfaction) FILTER (WHERE o.did_it = (o.recommendation = 'yes'))::float8 AS sat_followed,
+			AVG(o.satisfaction) FILTER (WHERE o.did_it <> (o.recommendation = 'yes'))::float8 AS sat_ignored
+		FROM decision_outcomes o
+		JOIN decisions d ON d.id = o.decision_id
+		%s
+	`, categoryExpr, groupBy))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	buckets := make([]accuracyBucket, 0)
+	for rows.Next() {
+		var b accuracyBucket
+		if err := rows.Scan(&b.Category, &b.Outcomes, &b.FollowedCrowd, &b.AvgSatisfactionWhenFollowed, &b.AvgSatisfactionWhenIgnored); err != nil {
+			return nil, err
+		}
+		if b.Outcomes > 0 {
+			b.Accuracy = float64(b.FollowedCrowd) / float64(b.Outcomes)
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets, rows.Err()
+}
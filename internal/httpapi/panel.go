@@ -0,0 +1,280 @@
+package httpapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	nethttp "net/http"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/store"
+)
+
+const (
+	maxPanelBodyBytes = 2 * 1024
+	maxPanelMembers   = 200
+
+	errorCodePanelTokenInvalid = "panel_token_invalid"
+	errorCodePanelFull         = "panel_full"
+)
+
+var (
+	errInvalidPanelToken = errors.New("panel_token is invalid for this decision")
+	errPanelFull         = errors.New("advisor panel is full")
+)
+
+type panelSettingsRequest struct {
+	PanelOnly bool `json:"panel_only"`
+}
+
+type addPanelMemberRequest struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+type panelMemberView struct {
+	ID        string    `json:"id"`
+	Kind      string    `json:"kind"`
+	Value     string    `json:"value"`
+	Responded bool      `json:"responded"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type panelView struct {
+	PanelOnly bool              `json:"panel_only"`
+	Members   []panelMemberView `json:"members"`
+}
+
+type addPanelMemberResponse struct {
+	panelMemberView
+	InviteToken *string `json:"invite_token,omitempty"`
+}
+
+func (s *Server) handleGetPanel(w nethttp.ResponseWriter, r *nethttp.Request) {
+	decision, ok := s.loadCreatorDecision(w, r)
+	if !ok {
+		return
+	}
+
+	view, err := s.loadPanelView(r.Context(), decision)
+	if err != nil {
+		s.writeServerError(w, err, "failed to load advisor panel")
+		return
+	}
+	writeJSON(w, nethttp.StatusOK, view)
+}
+
+func (s *Server) handleUpdatePanel(w nethttp.ResponseWriter, r *nethttp.Request) {
+	decision, ok := s.loadCreatorDecision(w, r)
+	if !ok {
+		return
+	}
+
+	var req panelSettingsRequest
+	if err := decodeJSON(w, r, maxPanelBodyBytes, &req); err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	if _, err := s.db.ExecContext(ctx, `
+		UPDATE decisions SET panel_only = $2 WHERE id = $1
+	`, decision.ID, req.PanelOnly); err != nil {
+		s.writeServerError(w, err, "failed to update advisor panel")
+		return
+	}
+	s.cache.Invalidate(decision.ID)
+
+	decision.PanelOnly = req.PanelOnly
+	view, err := s.loadPanelView(ctx, decision)
+	if err != nil {
+		s.writeServerError(w, err, "failed to load advisor panel")
+		return
+	}
+	writeJSON(w, nethttp.StatusOK, view)
+
+	s.publishLiveUpdate(ctx, "panel_changed", decision.ID, nil)
+}
+
+func (s *Server) handleAddPanelMember(w nethttp.ResponseWriter, r *nethttp.Request) {
+	decision, ok := s.loadCreatorDecision(w, r)
+	if !ok {
+		return
+	}
+
+	var req addPanelMemberRequest
+	if err := decodeJSON(w, r, maxPanelBodyBytes, &req); err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	kind, value, err := normalizePanelMember(req.Kind, req.Value)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Email members have no viewer ID to match on, so they prove membership
+	// with an invite token the creator forwards to them.
+	var (
+		inviteToken     *string
+		inviteTokenHash *string
+	)
+	if kind == "email" {
+		token, err := newSecretToken()
+		if err != nil {
+			s.writeServerError(w, err, "failed to add panel member")
+			return
+		}
+		hash := hashToken(token)
+		inviteToken = &token
+		inviteTokenHash = &hash
+	}
+
+	ctx := r.Context()
+	member := panelMemberView{ID: uuid.New().String(), Kind: kind, Value: value}
+	err = s.db.QueryRowContext(ctx, `
+		INSERT INTO decision_panel_members (id, decision_id, kind, value, invite_token_hash)
+		SELECT $1, $2, $3, $4, $5
+		WHERE (SELECT COUNT(*) FROM decision_panel_members WHERE decision_id = $2) < $6
+		RETURNING created_at
+	`, member.ID, decision.ID, kind, value, inviteTokenHash, maxPanelMembers).Scan(&member.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeProblem(w, nethttp.StatusConflict, errorCodePanelFull, errPanelFull.Error())
+			return
+		}
+		if isUniqueViolation(err) {
+			writeError(w, nethttp.StatusConflict, "panel member already exists")
+			return
+		}
+		s.writeServerError(w, err, "failed to add panel member")
+		return
+	}
+
+	writeJSON(w, nethttp.StatusCreated, addPanelMemberResponse{
+		panelMemberView: member,
+		InviteToken:     inviteToken,
+	})
+}
+
+func (s *Server) handleRemovePanelMember(w nethttp.ResponseWriter, r *nethttp.Request) {
+	decision, ok := s.loadCreatorDecision(w, r)
+	if !ok {
+		return
+	}
+
+	memberID, err := uuid.Parse(strings.TrimSpace(chi.URLParam(r, "memberID")))
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, "member id must be a valid UUID")
+		return
+	}
+
+	ctx := r.Context()
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM decision_panel_members WHERE id = $1 AND decision_id = $2
+	`, memberID, decision.ID)
+	if err != nil {
+		s.writeServerError(w, err, "failed to remove panel member")
+		return
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		writeError(w, nethttp.StatusNotFound, "panel member not found")
+		return
+	}
+	s.cache.Invalidate(decision.ID)
+
+	w.WriteHeader(nethttp.StatusNoContent)
+
+	s.publishLiveUpdate(ctx, "panel_changed", decision.ID, nil)
+}
+
+func (s *Server) loadPanelView(ctx context.Context, decision store.Decision) (panelView, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			m.id,
+			m.kind,
+			m.value,
+			EXISTS(SELECT 1 FROM responses r WHERE r.panel_member_id = m.id),
+			m.created_at
+		FROM decision_panel_members m
+		WHERE m.decision_id = $1
+		ORDER BY m.created_at ASC
+	`, decision.ID)
+	if err != nil {
+		return panelView{}, err
+	}
+	defer rows.Close()
+
+	view := panelView{PanelOnly: decision.PanelOnly, Members: make([]panelMemberView, 0, 8)}
+	for rows.Next() {
+		var (
+			m  panelMemberView
+			id uuid.UUID
+		)
+		if err := rows.Scan(&id, &m.Kind, &m.Value, &m.Responded, &m.CreatedAt); err != nil {
+			return panelView{}, err
+		}
+		m.ID = id.String()
+		view.Members = append(view.Members, m)
+	}
+	return view, rows.Err()
+}
+
+// resolvePanelMember works out whether a responder belongs to the decision's
+// advisor panel, either through an explicit invite token or because their
+// viewer ID was added as a member.
+func (s *Server) resolvePanelMember(ctx context.Context, decisionID, viewerID uuid.UUID, panelToken *string) (*uuid.UUID, error) {
+	var memberID uuid.UUID
+
+	if panelToken != nil && strings.TrimSpace(*panelToken) != "" {
+		err := s.db.QueryRowContext(ctx, `
+			SELECT id FROM decision_panel_members
+			WHERE decision_id = $1 AND invite_token_hash = $2
+		`, decisionID, hashToken(strings.TrimSpace(*panelToken))).Scan(&memberID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, errInvalidPanelToken
+		}
+		if err != nil {
+			return nil, err
+		}
+		return &memberID, nil
+	}
+
+	err := s.db.QueryRowContext(ctx, `
+		SELECT id FROM decision_panel_members
+		WHERE decision_id = $1 AND kind = 'viewer' AND value = $2
+	`, decisionID, viewerID.String()).Scan(&memberID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &memberID, nil
+}
+
+func normalizePanelMember(rawKind, rawValue string) (string, string, error) {
+	value := strings.TrimSpace(rawValue)
+	switch strings.TrimSpace(rawKind) {
+	case "email":
+		parsed, err := mail.ParseAddress(value)
+		if err != nil || parsed.Address != value {
+			return "", "", errors.New("value must be a valid email address")
+		}
+		return "email", strings.ToLower(value), nil
+	case "viewer":
+		viewerID, err := uuid.Parse(value)
+		if err != nil {
+			return "", "", errors.New("value must be a valid viewer UUID")
+		}
+		return "viewer", viewerID.String(), nil
+	default:
+		return "", "", errors.New("kind must be email or viewer")
+	}
+}
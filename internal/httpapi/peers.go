@@ -0,0 +1,123 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Replicas keep their decision caches and live hubs in step over Postgres
+// LISTEN/NOTIFY. Every write that invalidates the cache or publishes a live
+// update also NOTIFYs changeChannel; every instance LISTENs and replays the
+// notice locally, skipping its own.
+const (
+	changeChannel          = "decision_changes"
+	changeNotifyTimeout    = 2 * time.Second
+	changeListenMinBackoff = time.Second
+	changeListenMaxBackoff = 30 * time.Second
+)
+
+type changeNotice struct {
+	Origin     string         `json:"origin"`
+	Type       string         `json:"type"`
+	DecisionID uuid.UUID      `json:"decision_id"`
+	Response   *responseCard  `json:"response,omitempty"`
+	Reactions  *liveReactions `json:"reactions,omitempty"`
+}
+
+// announceChange is best effort: a lost notice leaves peers serving a
+// cached snapshot until its TTL runs out.
+func (s *Server) announceChange(ctx context.Context, eventType string, decisionID uuid.UUID, change liveChange) {
+	if !s.peerNotify {
+		return
+	}
+	payload, err := json.Marshal(changeNotice{
+		Origin:     s.instanceID,
+		Type:       eventType,
+		DecisionID: decisionID,
+		Response:   change.Response,
+		Reactions:  change.Reactions,
+	})
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), changeNotifyTimeout)
+	defer cancel()
+	if _, err := s.db.ExecContext(ctx, `SELECT pg_notify($1, $2)`, changeChannel, string(payload)); err != nil {
+		slog.Warn("change notify failed", "decision_id", decisionID, "type", eventType, "error", err)
+	}
+}
+
+// runChangeListener holds one pool connection for LISTEN until ctx is done,
+// reconnecting with backoff when it drops.
+func (s *Server) runChangeListener(ctx context.Context) {
+	backoff := changeListenMinBackoff
+	for {
+		started := time.Now()
+		err := s.listenForChanges(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if time.Since(started) > changeListenMaxBackoff {
+			backoff = changeListenMinBackoff
+		}
+		slog.Warn("change listener disconnected", "error", err, "retry_in", backoff.String())
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, changeListenMaxBackoff)
+	}
+}
+
+func (s *Server) listenForChanges(ctx context.Context) error {
+	conn, err := s.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// The connection is still subscribed when we stop; close it rather than
+	// hand a LISTENing session back to the pool.
+	defer func() {
+		closeCtx, cancel := context.WithTimeout(context.Background(), changeNotifyTimeout)
+		defer cancel()
+		conn.Hijack().Close(closeCtx)
+	}()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+changeChannel); err != nil {
+		return err
+	}
+	// Anything published while we were not listening is lost.
+	s.cache.Clear()
+
+	for {
+		n, err := conn.Conn().WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var notice changeNotice
+		if err := json.Unmarshal([]byte(n.Payload), &notice); err != nil {
+			slog.Warn("ignoring malformed change notice", "error", err)
+			continue
+		}
+		if notice.Origin == s.instanceID {
+			continue
+		}
+		if notice.Type == ipRulesChangedEvent {
+			s.reloadIPRules(ctx)
+			continue
+		}
+		if notice.Type == apiKeysChangedEvent {
+			s.reloadAPIKeys(ctx)
+			continue
+		}
+		s.cache.Invalidate(notice.DecisionID)
+		s.broadcastLiveUpdate(ctx, notice.Type, notice.DecisionID, liveChange{Response: notice.Response, Reactions: notice.Reactions})
+	}
+}
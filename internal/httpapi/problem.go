@@ -0,0 +1,94 @@
+package httpapi
+
+import (
+	"encoding/json"
+	nethttp "net/http"
+	"strings"
+)
+
+// Errors are reported as RFC 7807 problem details. type is always
+// about:blank, so title is just the status text; what clients branch on is
+// code, a stable snake_case extension member. detail is for people and may
+// change wording at any time.
+const problemContentType = "application/problem+json"
+
+const (
+	errorCodeDecisionNotFound       = "decision_not_found"
+	errorCodeDecisionClosed         = "decision_closed"
+	errorCodeViewerAlreadyResponded = "viewer_already_responded"
+	errorCodeDuplicateComment       = "duplicate_comment"
+	errorCodeSlugUnavailable        = "slug_unavailable"
+	errorCodeAPIKeyRequired         = "api_key_required"
+	errorCodeAPIKeyInvalid          = "api_key_invalid"
+	errorCodeOriginNotAllowed       = "origin_not_allowed"
+	errorCodeRateLimited            = "rate_limited"
+)
+
+type problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	Code   string `json:"code"`
+	// RequestID matches the X-Request-Id header and the server's log lines
+	// for the request.
+	RequestID         string `json:"request_id,omitempty"`
+	RetryAfterSeconds *int   `json:"retry_after_seconds,omitempty"`
+}
+
+func newProblem(w nethttp.ResponseWriter, status int, code, detail string) problem {
+	if code == "" {
+		code = statusErrorCode(status)
+	}
+	return problem{
+		Type:      "about:blank",
+		Title:     nethttp.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Code:      code,
+		RequestID: requestIDFromWriter(w),
+	}
+}
+
+// problemError is a problem read back from a handler, e.g. by a forwarded
+// GraphQL mutation.
+type problemError struct {
+	problem
+}
+
+func (e problemError) Error() string     { return e.Detail }
+func (e problemError) ErrorCode() string { return e.Code }
+
+// writeProblem writes an error with a code clients can rely on.
+func writeProblem(w nethttp.ResponseWriter, status int, code, detail string) {
+	writeProblemBody(w, newProblem(w, status, code, detail))
+}
+
+func writeProblemBody(w nethttp.ResponseWriter, p problem) {
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+// writeError writes an error whose code is the generic one for status. Use
+// writeProblem where clients need to tell one failure apart from another
+// with the same status.
+func writeError(w nethttp.ResponseWriter, status int, message string) {
+	writeProblem(w, status, "", message)
+}
+
+// statusErrorCode is the generic code for status: the status text in snake
+// case, e.g. bad_request or not_found.
+func statusErrorCode(status int) string {
+	switch status {
+	case nethttp.StatusBadRequest:
+		return "invalid_request"
+	case nethttp.StatusTooManyRequests:
+		return errorCodeRateLimited
+	}
+	text := nethttp.StatusText(status)
+	if text == "" {
+		return "error"
+	}
+	return strings.ReplaceAll(strings.ToLower(text), " ", "_")
+}
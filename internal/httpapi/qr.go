@@ -0,0 +1,94 @@
+package httpapi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"ratemylifedecision/internal/qrcode"
+	"ratemylifedecision/internal/store"
+)
+
+const (
+	qrDefaultSize = 256
+	qrMinSize     = 64
+	qrMaxSize     = 1024
+	qrCacheAge    = 86400
+)
+
+// handleDecisionQRCode renders the decision's share link as a QR code PNG,
+// size×size pixels. The link never changes, so the image is cached for a
+// day. For a private decision the code the request was allowed in with is
+// put in the link, so whoever scans it can open the decision too.
+func (s *Server) handleDecisionQRCode(w nethttp.ResponseWriter, r *nethttp.Request) {
+	slug, err := normalizeSlugParam(chi.URLParam(r, "slug"))
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+	size, err := parseQRSize(r.URL.Query().Get("size"))
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	decision, err := s.decisions.BySlug(r.Context(), slug)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			writeProblem(w, nethttp.StatusNotFound, errorCodeDecisionNotFound, "decision not found")
+			return
+		}
+		s.writeServerError(w, err, "failed to load decision")
+		return
+	}
+	if !requireDecisionAccess(w, r, decision) {
+		return
+	}
+
+	link := s.shareURL(decision.Slug)
+	cacheControl := fmt.Sprintf("public, max-age=%d", qrCacheAge)
+	if decision.Visibility == visibilityPrivate {
+		if code := requestAccessCode(r); code != "" {
+			link += "?access_code=" + url.QueryEscape(code)
+		}
+		cacheControl = fmt.Sprintf("private, max-age=%d", qrCacheAge)
+	}
+
+	code, err := qrcode.Encode([]byte(link))
+	if err != nil {
+		s.writeServerError(w, err, "failed to encode QR code")
+		return
+	}
+	if size < code.MinImageSize() {
+		writeError(w, nethttp.StatusBadRequest, fmt.Sprintf("size must be at least %d for this decision's link", code.MinImageSize()))
+		return
+	}
+	var buf bytes.Buffer
+	if err := code.PNG(&buf, size); err != nil {
+		s.writeServerError(w, err, "failed to render QR code")
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/png")
+	w.Header().Set("Cache-Control", cacheControl)
+	w.WriteHeader(nethttp.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+}
+
+func parseQRSize(raw string) (int, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return qrDefaultSize, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < qrMinSize || n > qrMaxSize {
+		return 0, fmt.Errorf("size must be an integer between %d and %d", qrMinSize, qrMaxSize)
+	}
+	return n, nil
+}
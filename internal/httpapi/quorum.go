@@ -0,0 +1,88 @@
+package httpapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/store"
+)
+
+// maxQuorum caps how many responses a creator can ask for before results
+// are shown.
+const maxQuorum = 100
+
+const (
+	decisionStateCollecting = "collecting_responses"
+	decisionStateRevealed   = "revealed"
+)
+
+func normalizeQuorum(quorum int) (int, error) {
+	if quorum < 0 || quorum > maxQuorum {
+		return 0, fmt.Errorf("quorum must be between 0 and %d", maxQuorum)
+	}
+	return quorum, nil
+}
+
+// quorumPending reports whether decision is still collecting responses
+// toward its quorum. Once the decision closes its results are shown however
+// many responses it got: there is no one left to anchor.
+func quorumPending(decision store.Decision, responseCount int, now time.Time) bool {
+	if decision.Quorum <= 0 || responseCount >= decision.Quorum {
+		return false
+	}
+	if decision.ClosedAt != nil || (decision.ClosesAt != nil && !decision.ClosesAt.After(now)) {
+		return false
+	}
+	return true
+}
+
+// withheldStats is what is shown of the stats before the quorum is met:
+// only how many responses are in, so the page can show progress.
+func withheldStats(responseCount int) decisionStats {
+	return decisionStats{
+		ResponseCount: responseCount,
+		RatingCounts:  make([]int, 5),
+		EmojiCounts:   []emojiCount{},
+	}
+}
+
+func withheldRecommendation() recommendationView {
+	return recommendationView{Decision: "undecided", ConfidenceInterval: [2]float64{0, 1}}
+}
+
+// withholdUntilQuorum returns snapshot as the public should see it: while
+// the decision is collecting responses, the stats, recommendation, post
+// vote totals and individual responses are all left out. The snapshot is
+// shared with the cache, so it is copied rather than changed.
+func withholdUntilQuorum(snapshot decisionSnapshot, now time.Time) (decisionSnapshot, bool) {
+	if !quorumPending(snapshot.Decision, snapshot.Stats.ResponseCount, now) {
+		return snapshot, false
+	}
+	return decisionSnapshot{
+		Decision:       snapshot.Decision,
+		Stats:          withheldStats(snapshot.Stats.ResponseCount),
+		Recommendation: withheldRecommendation(),
+		Responses:      []responseCard{},
+	}, true
+}
+
+// quorumPendingByID is quorumPending for callers that only have the
+// decision's ID, like live updates.
+func (s *Server) quorumPendingByID(ctx context.Context, decisionID uuid.UUID, responseCount int) (bool, error) {
+	var decision store.Decision
+	err := s.db.QueryRowContext(ctx, `
+		SELECT quorum, closes_at, closed_at FROM decisions WHERE id = $1
+	`, decisionID).Scan(&decision.Quorum, &decision.ClosesAt, &decision.ClosedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return quorumPending(decision, responseCount, time.Now()), nil
+}
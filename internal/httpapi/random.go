@@ -0,0 +1,71 @@
+package httpapi
+
+import (
+	"database/sql"
+	"errors"
+	nethttp "net/http"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/store"
+)
+
+const errorCodeNothingToRate = "nothing_to_rate"
+
+// handleRandomDecision picks an open public decision at random for a "rate a
+// stranger's decision" mode. With the viewer's token in X-Viewer-Token, or
+// a session for a viewer, it skips decisions that viewer has already
+// responded to. Raw viewer IDs are not accepted here any more than
+// elsewhere, so ?exclude_responded_by= is answered with the same pointer to
+// POST /v1/viewers.
+func (s *Server) handleRandomDecision(w nethttp.ResponseWriter, r *nethttp.Request) {
+	token := strings.TrimSpace(r.Header.Get("X-Viewer-Token"))
+	legacyID := r.URL.Query().Get("exclude_responded_by")
+	var viewerID *uuid.UUID
+	session, _ := sessionFromContext(r.Context())
+	if token != "" || strings.TrimSpace(legacyID) != "" || session.ViewerID != nil {
+		viewer, ok := s.requireViewer(w, r, token, legacyID)
+		if !ok {
+			return
+		}
+		viewerID = &viewer.ID
+	}
+
+	ctx, cancel := withBudget(r.Context(), statsQueryBudget)
+	defer cancel()
+	var slug string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT d.slug
+		FROM decisions d
+		WHERE d.hidden_at IS NULL
+		  AND NOT d.panel_only
+		  AND d.visibility = 'public'
+		  AND d.closed_at IS NULL
+		  AND (d.closes_at IS NULL OR d.closes_at > now())
+		  AND ($1::uuid IS NULL OR NOT EXISTS (
+			SELECT 1 FROM responses r WHERE r.decision_id = d.id AND r.viewer_id = $1
+		  ))
+		ORDER BY random()
+		LIMIT 1
+	`, viewerID).Scan(&slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeProblem(w, nethttp.StatusNotFound, errorCodeNothingToRate, "no open decisions left to rate")
+		return
+	}
+	if err != nil {
+		s.writeServerError(w, err, "failed to pick a decision")
+		return
+	}
+
+	decision, err := s.decisions.BySlug(r.Context(), slug)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			writeProblem(w, nethttp.StatusNotFound, errorCodeNothingToRate, "no open decisions left to rate")
+			return
+		}
+		s.writeServerError(w, err, "failed to load decision")
+		return
+	}
+	writeJSON(w, nethttp.StatusOK, decisionViewFromStore(decision))
+}
@@ -0,0 +1,200 @@
+package httpapi
+
+import (
+	"log/slog"
+	nethttp "net/http"
+	"sync"
+	"time"
+
+	"ratemylifedecision/internal/config"
+)
+
+// rateLimiter is implemented by fixedWindowLimiter and tokenBucketLimiter.
+// Limits are counts per the limiter's window, which the adaptive limiter
+// scales.
+type rateLimiter interface {
+	Allow(key string, now time.Time) (bool, time.Duration)
+	SetLimit(limit int)
+	Limit() int
+	Buckets() int
+	// Sweep drops buckets that no longer affect any decision, so idle
+	// clients do not pile up. The scheduler calls it periodically.
+	Sweep(now time.Time)
+}
+
+func newRateLimiter(cfg config.Limits, limit int, window time.Duration) rateLimiter {
+	if cfg.RateLimiter == "fixed_window" {
+		return newFixedWindowLimiter(limit, window)
+	}
+	return newTokenBucketLimiter(limit, window, cfg.RateLimitBurst)
+}
+
+// routeRateLimit is a per-IP limit shared by one group of routes. base is
+// the configured limit; the adaptive controller scales the live one from it.
+type routeRateLimit struct {
+	name    string
+	base    int
+	window  time.Duration
+	limiter rateLimiter
+}
+
+type routeRateLimitView struct {
+	Name      string `json:"name"`
+	Limit     int    `json:"limit"`
+	BaseLimit int    `json:"base_limit"`
+	Window    string `json:"window"`
+}
+
+var defaultRouteRateLimits = []struct {
+	name   string
+	limit  int
+	window time.Duration
+}{
+	{"read", 300, time.Minute},
+	{"write", 120, time.Minute},
+	{"create_decision", 10, time.Hour},
+	{"create_viewer", 20, time.Hour},
+}
+
+// loadRouteRateLimits applies the RATE_LIMITS overrides in cfg to the
+// default route limits. A name that matches no route is logged and ignored.
+func loadRouteRateLimits(cfg config.Limits) map[string]*routeRateLimit {
+	limits := make(map[string]*routeRateLimit, len(defaultRouteRateLimits))
+	for _, d := range defaultRouteRateLimits {
+		limits[d.name] = &routeRateLimit{name: d.name, base: d.limit, window: d.window}
+	}
+
+	for name, override := range cfg.RateLimits {
+		rl, ok := limits[name]
+		if !ok {
+			slog.Warn("ignoring unknown RATE_LIMITS entry", "name", name)
+			continue
+		}
+		rl.base, rl.window = override.Count, override.Window
+	}
+
+	for _, rl := range limits {
+		rl.limiter = newRateLimiter(cfg, rl.base, rl.window)
+	}
+	return limits
+}
+
+// rateLimitMiddleware applies the named route limit per client IP. A limit
+// of 0 disables it.
+func (s *Server) rateLimitMiddleware(name string) func(nethttp.Handler) nethttp.Handler {
+	rl := s.rateLimits[name]
+	return func(next nethttp.Handler) nethttp.Handler {
+		return nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+			if r.Method == nethttp.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			allowed, retryAfter := rl.limiter.Allow("ip:"+s.clientIPFromRequest(r), time.Now())
+			if !allowed {
+				writeRateLimitExceeded(w, retryAfter)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// tokenBucketLimiter refills limit tokens per window continuously and lets a
+// key hold up to burst of them (never more than limit), so a page load
+// firing a few requests at once is fine while the sustained rate stays at
+// limit per window. Unlike the fixed window it has no edge where 2x limit
+// can pass in a moment.
+type tokenBucketLimiter struct {
+	mu      sync.Mutex
+	window  time.Duration
+	limit   int
+	burst   int
+	buckets map[string]tokenBucket
+}
+
+func newTokenBucketLimiter(limit int, window time.Duration, burst int) *tokenBucketLimiter {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucketLimiter{
+		window:  window,
+		limit:   limit,
+		burst:   burst,
+		buckets: make(map[string]tokenBucket, 2048),
+	}
+}
+
+func (l *tokenBucketLimiter) Allow(key string, now time.Time) (bool, time.Duration) {
+	if key == "" {
+		key = "unknown"
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.limit <= 0 {
+		return true, 0
+	}
+	perToken := l.window / time.Duration(l.limit)
+	// Never allow more at once than the whole window's worth.
+	capacity := min(l.burst, l.limit)
+
+	bucket, exists := l.buckets[key]
+	if !exists {
+		bucket = tokenBucket{tokens: float64(capacity), last: now}
+	} else if elapsed := now.Sub(bucket.last); elapsed > 0 {
+		bucket.tokens = min(float64(capacity), bucket.tokens+float64(elapsed)/float64(perToken))
+		bucket.last = now
+	}
+
+	if bucket.tokens < 1 {
+		l.buckets[key] = bucket
+		return false, time.Duration((1 - bucket.tokens) * float64(perToken))
+	}
+
+	bucket.tokens--
+	l.buckets[key] = bucket
+	return true, 0
+}
+
+// Sweep drops buckets idle long enough to have refilled completely; they
+// are indistinguishable from missing ones.
+func (l *tokenBucketLimiter) Sweep(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.limit <= 0 {
+		clear(l.buckets)
+		return
+	}
+	full := l.window / time.Duration(l.limit) * time.Duration(min(l.burst, l.limit))
+	for k, bucket := range l.buckets {
+		if now.Sub(bucket.last) >= full {
+			delete(l.buckets, k)
+		}
+	}
+}
+
+func (l *tokenBucketLimiter) SetLimit(limit int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = limit
+}
+
+func (l *tokenBucketLimiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+func (l *tokenBucketLimiter) Buckets() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buckets)
+}
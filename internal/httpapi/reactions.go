@@ -0,0 +1,168 @@
+package httpapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	nethttp "net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/store"
+)
+
+const maxReactionBodyBytes = 1024
+
+// reactionEmojis are the reactions a response can get. The set is small on
+// purpose: reactions are counted and shown per emoji.
+var reactionEmojis = map[string]struct{}{
+	"👍":  {},
+	"❤️": {},
+	"😂":  {},
+	"😮":  {},
+	"🤔":  {},
+	"🙏":  {},
+}
+
+type reactionRequest struct {
+	ViewerToken string `json:"viewer_token"`
+	Emoji       string `json:"emoji"`
+}
+
+type reactionSummary struct {
+	ResponseID  string         `json:"response_id"`
+	Reactions   map[string]int `json:"reactions"`
+	MyReactions []string       `json:"my_reactions"`
+}
+
+// handleReactToResponse toggles the viewer's emoji reaction on someone's
+// response: the first time adds it, the second takes it back. Only
+// responses people can see take reactions. A shadowbanned viewer's
+// reactions count for them alone.
+func (s *Server) handleReactToResponse(w nethttp.ResponseWriter, r *nethttp.Request) {
+	responseID, err := uuid.Parse(strings.TrimSpace(chi.URLParam(r, "id")))
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, "response id must be a valid UUID")
+		return
+	}
+	auditFromContext(r.Context()).setTarget("response", responseID.String())
+
+	var req reactionRequest
+	if err := decodeJSON(w, r, maxReactionBodyBytes, &req); err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+	viewer, ok := s.requireViewer(w, r, req.ViewerToken, "")
+	if !ok {
+		return
+	}
+	emoji := strings.TrimSpace(req.Emoji)
+	if _, ok := reactionEmojis[emoji]; !ok {
+		writeError(w, nethttp.StatusBadRequest, "emoji is not a supported reaction")
+		return
+	}
+
+	ctx := r.Context()
+	var slug string
+	err = s.db.QueryRowContext(ctx, `
+		SELECT d.slug
+		FROM responses r
+		JOIN decisions d ON d.id = r.decision_id
+		WHERE r.id = $1 AND NOT d.aggregate_only AND r.hidden_at IS NULL AND d.hidden_at IS NULL
+			AND NOT r.shadowed
+	`, responseID).Scan(&slug)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, nethttp.StatusNotFound, "response not found")
+			return
+		}
+		s.writeServerError(w, err, "failed to load response")
+		return
+	}
+	decision, err := s.decisions.BySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			writeError(w, nethttp.StatusNotFound, "response not found")
+			return
+		}
+		s.writeServerError(w, err, "failed to load decision")
+		return
+	}
+	if !requireDecisionAccess(w, r, decision) {
+		return
+	}
+
+	ctx, cancel := withBudget(ctx, writeQueryBudget)
+	defer cancel()
+	if _, err := s.db.ExecContext(ctx, `
+		WITH removed AS (
+			DELETE FROM response_reactions
+			WHERE response_id = $1 AND viewer_id = $2 AND emoji = $3
+			RETURNING 1
+		)
+		INSERT INTO response_reactions (response_id, viewer_id, emoji, shadowed)
+		SELECT $1, $2, $3, $4
+		WHERE NOT EXISTS (SELECT 1 FROM removed)
+		ON CONFLICT DO NOTHING
+	`, responseID, viewer.ID, emoji, viewer.Shadowbanned); err != nil {
+		s.writeServerError(w, err, "failed to record reaction")
+		return
+	}
+
+	summary, err := s.loadReactionSummary(ctx, responseID, viewer.ID)
+	if err != nil {
+		s.writeServerError(w, err, "failed to load reactions")
+		return
+	}
+	if viewer.Shadowbanned {
+		writeJSON(w, nethttp.StatusOK, summary)
+		return
+	}
+	s.cache.Invalidate(decision.ID)
+	writeJSON(w, nethttp.StatusOK, summary)
+
+	s.publishLiveChange(ctx, "reactions_changed", decision.ID, liveChange{Reactions: &liveReactions{
+		ResponseID: summary.ResponseID,
+		Reactions:  summary.Reactions,
+	}})
+}
+
+// loadReactionSummary counts the reactions on a response as viewerID sees
+// them: their own count even if they are shadowbanned.
+func (s *Server) loadReactionSummary(ctx context.Context, responseID, viewerID uuid.UUID) (reactionSummary, error) {
+	summary := reactionSummary{
+		ResponseID:  responseID.String(),
+		Reactions:   map[string]int{},
+		MyReactions: []string{},
+	}
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT emoji, COUNT(*) FILTER (WHERE NOT shadowed OR viewer_id = $2)::int, bool_or(viewer_id = $2)
+		FROM response_reactions
+		WHERE response_id = $1
+		GROUP BY emoji
+		ORDER BY emoji
+	`, responseID, viewerID)
+	if err != nil {
+		return reactionSummary{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			emoji string
+			count int
+			mine  bool
+		)
+		if err := rows.Scan(&emoji, &count, &mine); err != nil {
+			return reactionSummary{}, err
+		}
+		if count > 0 {
+			summary.Reactions[emoji] = count
+		}
+		if mine {
+			summary.MyReactions = append(summary.MyReactions, emoji)
+		}
+	}
+	return summary, rows.Err()
+}
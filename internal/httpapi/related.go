@@ -0,0 +1,98 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	nethttp "net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	"ratemylifedecision/internal/projections"
+	"ratemylifedecision/internal/store"
+)
+
+const (
+	defaultRelatedLimit = 5
+	maxRelatedLimit     = 20
+)
+
+// handleListRelatedDecisions suggests what to look at next after responding:
+// other open public decisions in the same category, the most active first.
+// The trend score already decays with age, so fresh decisions with a little
+// activity rank alongside older busy ones; brand new decisions with none
+// follow, newest first.
+func (s *Server) handleListRelatedDecisions(w nethttp.ResponseWriter, r *nethttp.Request) {
+	limit, err := parseLimitParam(r, defaultRelatedLimit, maxRelatedLimit)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+	slug, err := normalizeSlugParam(chi.URLParam(r, "slug"))
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+	decision, err := s.decisions.BySlug(r.Context(), slug)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			writeProblem(w, nethttp.StatusNotFound, errorCodeDecisionNotFound, "decision not found")
+			return
+		}
+		s.writeServerError(w, err, "failed to load decision")
+		return
+	}
+	if !requireDecisionAccess(w, r, decision) {
+		return
+	}
+
+	items, err := s.queryRelated(r.Context(), decision, limit)
+	if err != nil {
+		s.writeServerError(w, err, "failed to load related decisions")
+		return
+	}
+	writeJSON(w, nethttp.StatusOK, map[string]any{"items": items})
+}
+
+func (s *Server) queryRelated(ctx context.Context, decision store.Decision, limit int) ([]activityItem, error) {
+	ctx, cancel := withBudget(ctx, statsQueryBudget)
+	defer cancel()
+
+	halfLife := strconv.FormatFloat(projections.TrendHalfLife.Seconds(), 'f', -1, 64)
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT
+			d.slug, d.title, d.category,
+			COALESCE(a.response_count, 0), COALESCE(a.vote_score, 0), COALESCE(a.vote_count, 0),
+			COALESCE(a.trend_score * power(0.5, EXTRACT(EPOCH FROM (now() - a.trend_at))::float8 / `+halfLife+`), 0) AS trend_now,
+			COALESCE(a.last_activity_at, d.created_at)
+		FROM decisions d
+		LEFT JOIN rm_decision_activity a ON a.decision_id = d.id
+		WHERE d.id <> $1
+		  AND d.category IS NOT DISTINCT FROM $2
+		  AND d.hidden_at IS NULL
+		  AND NOT d.panel_only
+		  AND d.visibility = 'public'
+		  AND d.closed_at IS NULL
+		  AND (d.closes_at IS NULL OR d.closes_at > now())
+		ORDER BY trend_now DESC, d.created_at DESC
+		LIMIT $3
+	`, decision.ID, decision.Category, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]activityItem, 0, limit)
+	for rows.Next() {
+		var it activityItem
+		if err := rows.Scan(
+			&it.Slug, &it.Title, &it.Category,
+			&it.ResponseCount, &it.VoteScore, &it.VoteCount,
+			&it.TrendScore, &it.LastActivityAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
@@ -0,0 +1,154 @@
+package httpapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/mailer"
+	"ratemylifedecision/internal/notify"
+	"ratemylifedecision/internal/store"
+	"ratemylifedecision/internal/webhooks"
+)
+
+const (
+	reminderBatchSize = 50
+)
+
+type closeReminder struct {
+	decision      store.Decision
+	closesAt      time.Time
+	responseCount int
+}
+
+// sendCloseReminders tells creators when a decision is about to close with
+// fewer than REMINDER_MIN_RESPONSES responses, so they can share it again.
+// It goes to the decision's notification subscriptions, its close_reminder
+// webhooks and the confirmed creator email.
+//
+// A decision qualifies within REMINDER_LEAD of closes_at, and only once it
+// has been open for half its lifetime, so short-lived decisions are not
+// nagged the moment they are created. The decision_reminders row is claimed
+// before anything is sent: a reminder is sent at most once, even across
+// restarts, and one interrupted mid-send is not retried.
+func (s *Server) sendCloseReminders(ctx context.Context) error {
+	lead := s.cfg.Jobs.ReminderLead
+	minResponses := s.cfg.Jobs.ReminderMinResponses
+
+	rows, err := s.db.QueryContext(ctx, `
+		WITH claimed AS (
+			INSERT INTO decision_reminders (decision_id, response_count)
+			SELECT d.id, COALESCE(st.response_count, 0)
+			FROM decisions d
+			LEFT JOIN decision_stats st ON st.decision_id = d.id
+			WHERE d.closed_at IS NULL
+			  AND d.hidden_at IS NULL
+			  AND d.closes_at > now()
+			  AND d.closes_at <= now() + $1 * interval '1 second'
+			  AND now() >= d.created_at + (d.closes_at - d.created_at) / 2
+			  AND COALESCE(st.response_count, 0) < $2
+			  AND NOT EXISTS (SELECT 1 FROM decision_reminders r WHERE r.decision_id = d.id)
+			ORDER BY d.closes_at
+			LIMIT $3
+			ON CONFLICT (decision_id) DO NOTHING
+			RETURNING decision_id, response_count
+		)
+		SELECT d.id, d.slug, d.title, d.closes_at, d.aggregate_only, c.response_count
+		FROM claimed c
+		JOIN decisions d ON d.id = c.decision_id
+	`, lead.Seconds(), minResponses, reminderBatchSize)
+	if err != nil {
+		return err
+	}
+	var due []closeReminder
+	for rows.Next() {
+		var rem closeReminder
+		d := &rem.decision
+		if err := rows.Scan(&d.ID, &d.Slug, &d.Title, &rem.closesAt, &d.AggregateOnly, &rem.responseCount); err != nil {
+			rows.Close()
+			return err
+		}
+		due = append(due, rem)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, rem := range due {
+		if err := s.sendCloseReminder(ctx, rem); err != nil {
+			errs = append(errs, fmt.Errorf("reminder for %s: %w", rem.decision.ID, err))
+		}
+	}
+	if len(due) > 0 {
+		s.jobs.Trigger(jobWebhooks)
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Server) sendCloseReminder(ctx context.Context, rem closeReminder) error {
+	d := rem.decision
+	closesIn := formatClosesIn(time.Until(rem.closesAt))
+	event := notify.Event{
+		Kind:          notify.KindReminder,
+		DecisionID:    d.ID,
+		DecisionSlug:  d.Slug,
+		DecisionTitle: d.Title,
+		ShareURL:      s.shareURL(d.Slug),
+		DedupeKey:     "reminder",
+		Data: map[string]any{
+			"closes_in":      closesIn,
+			"response_count": rem.responseCount,
+		},
+	}
+	s.notifier.DispatchAsync(event)
+	s.enqueueWebhook(ctx, webhooks.EventCloseReminder, d, "reminder", map[string]any{
+		"closes_at":      rem.closesAt.UTC(),
+		"closes_in":      closesIn,
+		"response_count": rem.responseCount,
+	})
+	return s.emailCloseReminder(ctx, d.ID, event)
+}
+
+// emailCloseReminder sends the reminder to the creator's email, if one was
+// given and confirmed, using the same wording as reminder notifications.
+func (s *Server) emailCloseReminder(ctx context.Context, decisionID uuid.UUID, event notify.Event) error {
+	if s.mailer == nil {
+		return nil
+	}
+	var email string
+	err := s.db.QueryRowContext(ctx, `
+		SELECT email FROM creator_emails WHERE decision_id = $1 AND confirmed_at IS NOT NULL
+	`, decisionID).Scan(&email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	msg, err := notify.DefaultTemplates().Render(event)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, creatorEmailSendBudget)
+	defer cancel()
+	return s.mailer.Send(ctx, mailer.Message{To: email, Subject: msg.Subject, Text: msg.Body})
+}
+
+// formatClosesIn renders d for "closes in 5 hours"-style copy.
+func formatClosesIn(d time.Duration) string {
+	switch {
+	case d < 2*time.Minute:
+		return "in a moment"
+	case d < 2*time.Hour:
+		return fmt.Sprintf("in %d minutes", int(d.Round(time.Minute)/time.Minute))
+	default:
+		return fmt.Sprintf("in %d hours", int(d.Round(time.Hour)/time.Hour))
+	}
+}
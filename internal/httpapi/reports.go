@@ -0,0 +1,259 @@
+package httpapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	nethttp "net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/database"
+	"ratemylifedecision/internal/store"
+)
+
+const (
+	maxReportBodyBytes     = 2 * 1024
+	reportDetailsMaxLength = 500
+
+	errorCodeAlreadyReported = "already_reported"
+)
+
+var reportReasons = map[string]struct{}{
+	"spam":          {},
+	"harassment":    {},
+	"hate":          {},
+	"sexual":        {},
+	"self_harm":     {},
+	"personal_info": {},
+	"other":         {},
+}
+
+var errAlreadyReported = errors.New("already reported")
+
+// reportTarget names something that can be reported: the reports.target_kind
+// value and the table whose hidden_at column it sets.
+type reportTarget struct {
+	kind  string
+	table string
+}
+
+var (
+	reportTargetDecision = reportTarget{kind: "decision", table: "decisions"}
+	reportTargetResponse = reportTarget{kind: "response", table: "responses"}
+)
+
+type reportRequest struct {
+	ViewerToken string  `json:"viewer_token"`
+	Reason      string  `json:"reason"`
+	Details     *string `json:"details"`
+}
+
+func (s *Server) handleReportResponse(w nethttp.ResponseWriter, r *nethttp.Request) {
+	responseID, err := uuid.Parse(strings.TrimSpace(chi.URLParam(r, "id")))
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, "response id must be a valid UUID")
+		return
+	}
+	auditFromContext(r.Context()).setTarget("response", responseID.String())
+	viewerID, reason, details, ok := s.decodeReport(w, r)
+	if !ok {
+		return
+	}
+
+	// Only responses someone could have seen can be reported.
+	ctx := r.Context()
+	var slug string
+	err = s.db.QueryRowContext(ctx, `
+		SELECT d.slug
+		FROM responses r
+		JOIN decisions d ON d.id = r.decision_id
+		WHERE r.id = $1 AND NOT d.aggregate_only AND r.hidden_at IS NULL AND d.hidden_at IS NULL
+	`, responseID).Scan(&slug)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, nethttp.StatusNotFound, "response not found")
+			return
+		}
+		s.writeServerError(w, err, "failed to load response")
+		return
+	}
+	decision, err := s.decisions.BySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			writeError(w, nethttp.StatusNotFound, "response not found")
+			return
+		}
+		s.writeServerError(w, err, "failed to load decision")
+		return
+	}
+	if !requireDecisionAccess(w, r, decision) {
+		return
+	}
+	decisionID := decision.ID
+
+	reportID, hidden, err := s.fileReport(ctx, reportTargetResponse, responseID, viewerID, reason, details)
+	if err != nil {
+		if errors.Is(err, errAlreadyReported) {
+			writeProblem(w, nethttp.StatusConflict, errorCodeAlreadyReported, "viewer already reported this response")
+			return
+		}
+		s.writeServerError(w, err, "failed to record report")
+		return
+	}
+
+	if hidden {
+		s.cache.Invalidate(decisionID)
+	}
+	writeJSON(w, nethttp.StatusCreated, map[string]string{"id": reportID.String()})
+
+	if hidden {
+		s.publishLiveUpdate(ctx, "response_hidden", decisionID, &responseCard{ID: responseID.String()})
+	}
+}
+
+func (s *Server) handleReportDecision(w nethttp.ResponseWriter, r *nethttp.Request) {
+	slug, err := normalizeSlugParam(chi.URLParam(r, "slug"))
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+	viewerID, reason, details, ok := s.decodeReport(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	decision, err := s.decisions.BySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			writeProblem(w, nethttp.StatusNotFound, errorCodeDecisionNotFound, "decision not found")
+			return
+		}
+		s.writeServerError(w, err, "failed to load decision")
+		return
+	}
+	if !requireDecisionAccess(w, r, decision) {
+		return
+	}
+
+	reportID, hidden, err := s.fileReport(ctx, reportTargetDecision, decision.ID, viewerID, reason, details)
+	if err != nil {
+		if errors.Is(err, errAlreadyReported) {
+			writeProblem(w, nethttp.StatusConflict, errorCodeAlreadyReported, "viewer already reported this decision")
+			return
+		}
+		s.writeServerError(w, err, "failed to record report")
+		return
+	}
+
+	if hidden {
+		s.cache.Invalidate(decision.ID)
+	}
+	writeJSON(w, nethttp.StatusCreated, map[string]string{"id": reportID.String()})
+
+	if hidden {
+		s.publishLiveUpdate(ctx, "decision_hidden", decision.ID, nil)
+	}
+}
+
+// flagForReview files a pending report on behalf of the content filter,
+// which reports as uuid.Nil. Like any reporter it counts once towards the
+// hide threshold. Failures are logged: the content itself was accepted.
+func (s *Server) flagForReview(ctx context.Context, target reportTarget, targetID uuid.UUID, field string) {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO reports (id, target_kind, target_id, reporter_viewer_id, reason, details)
+		VALUES ($1, $2, $3, $4, 'other', $5)
+		ON CONFLICT DO NOTHING
+	`, uuid.New(), target.kind, targetID, uuid.Nil, "content filter matched the "+field)
+	if err != nil {
+		slog.Warn("content filter flag failed", "target_kind", target.kind, "target_id", targetID, "error", err)
+	}
+}
+
+// decodeReport reads and validates a report body and resolves the reporting
+// viewer. It writes the error response itself.
+func (s *Server) decodeReport(w nethttp.ResponseWriter, r *nethttp.Request) (uuid.UUID, string, *string, bool) {
+	var req reportRequest
+	if err := decodeJSON(w, r, maxReportBodyBytes, &req); err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return uuid.Nil, "", nil, false
+	}
+
+	viewer, ok := s.requireViewer(w, r, req.ViewerToken, "")
+	if !ok {
+		return uuid.Nil, "", nil, false
+	}
+	reason := strings.ToLower(strings.TrimSpace(req.Reason))
+	if _, ok := reportReasons[reason]; !ok {
+		writeError(w, nethttp.StatusBadRequest, "reason must be one of spam, harassment, hate, sexual, self_harm, personal_info, other")
+		return uuid.Nil, "", nil, false
+	}
+	details, err := normalizeOptionalText(req.Details, reportDetailsMaxLength, "details", true)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return uuid.Nil, "", nil, false
+	}
+	return viewer.ID, reason, details, true
+}
+
+// fileReport records one viewer's report and hides the target once
+// reportThreshold distinct viewers, not counting shadowbanned ones, have
+// pending reports against it. The target row is locked first so concurrent
+// reports cannot both miss the threshold. hidden is true only for the
+// report that crossed it.
+func (s *Server) fileReport(ctx context.Context, target reportTarget, targetID, viewerID uuid.UUID, reason string, details *string) (reportID uuid.UUID, hidden bool, err error) {
+	ctx, cancel := withBudget(ctx, writeQueryBudget)
+	defer cancel()
+
+	reportID = uuid.New()
+	err = database.RetryTx(ctx, s.db, func(tx *sql.Tx) error {
+		hidden = false
+
+		var alreadyHidden bool
+		if err := tx.QueryRowContext(ctx, fmt.Sprintf(`
+			SELECT hidden_at IS NOT NULL FROM %s WHERE id = $1 FOR UPDATE
+		`, target.table), targetID).Scan(&alreadyHidden); err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO reports (id, target_kind, target_id, reporter_viewer_id, reason, details)
+			VALUES ($1, $2, $3, $4, $5, $6)
+		`, reportID, target.kind, targetID, viewerID, reason, details); err != nil {
+			if isUniqueViolation(err) {
+				return errAlreadyReported
+			}
+			return err
+		}
+		if alreadyHidden {
+			return nil
+		}
+
+		var pending int
+		if err := tx.QueryRowContext(ctx, `
+			SELECT COUNT(DISTINCT reporter_viewer_id)::int
+			FROM reports
+			WHERE target_kind = $1 AND target_id = $2 AND status = 'pending'
+				AND reporter_viewer_id NOT IN (SELECT viewer_id FROM banned_viewers WHERE shadow)
+		`, target.kind, targetID).Scan(&pending); err != nil {
+			return err
+		}
+		if pending < s.cfg.Limits.ReportHideThreshold {
+			return nil
+		}
+
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`
+			UPDATE %s SET hidden_at = now() WHERE id = $1
+		`, target.table), targetID); err != nil {
+			return err
+		}
+		hidden = true
+		return nil
+	})
+	return reportID, hidden, err
+}
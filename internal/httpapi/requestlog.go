@@ -0,0 +1,78 @@
+package httpapi
+
+import (
+	"log/slog"
+	nethttp "net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/logging"
+)
+
+const (
+	requestIDHeader    = logging.RequestIDHeader
+	maxRequestIDLength = 128
+)
+
+// requestLogMiddleware is the outermost middleware. It assigns the request
+// ID (honoring a well-formed incoming X-Request-Id), records metrics, and
+// writes one access log line per request.
+func (s *Server) requestLogMiddleware(next nethttp.Handler) nethttp.Handler {
+	return nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		started := time.Now()
+
+		requestID := r.Header.Get(requestIDHeader)
+		if !isValidRequestID(requestID) {
+			requestID = uuid.NewString()
+		}
+		w.Header().Set(requestIDHeader, requestID)
+		ctx := logging.WithRequestID(r.Context(), requestID)
+
+		rec := &statusRecorder{ResponseWriter: w, status: nethttp.StatusOK, requestID: requestID}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+		s.metrics.ObserveRequest(rec.status)
+
+		level := slog.LevelInfo
+		if rec.status >= 500 {
+			level = slog.LevelError
+		}
+		slog.LogAttrs(ctx, level, "request",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", rec.status),
+			slog.Float64("duration_ms", float64(time.Since(started).Microseconds())/1000),
+			slog.String("client_ip", s.clientIPFromRequest(r)),
+		)
+	})
+}
+
+// isValidRequestID accepts caller-supplied IDs that are safe to echo in
+// headers and logs: short, printable ASCII without spaces.
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// requestIDFromWriter finds the ID assigned by requestLogMiddleware by
+// walking the wrapped writers down to the statusRecorder.
+func requestIDFromWriter(w nethttp.ResponseWriter) string {
+	for w != nil {
+		if rec, ok := w.(*statusRecorder); ok {
+			return rec.requestID
+		}
+		u, ok := w.(interface{ Unwrap() nethttp.ResponseWriter })
+		if !ok {
+			return ""
+		}
+		w = u.Unwrap()
+	}
+	return ""
+}
@@ -0,0 +1,316 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	nethttp "net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/store"
+)
+
+const (
+	defaultResponsePageLimit = 20
+	maxResponsePageLimit     = 100
+)
+
+const (
+	responseSortNewest      = "newest"
+	responseSortTopRated    = "top_rated"
+	responseSortMostReacted = "most_reacted"
+)
+
+// responseSortKeys is the leading sort key of each order, after which ties
+// are broken newest first. newest has none of its own.
+var responseSortKeys = map[string]string{
+	responseSortNewest:      "0",
+	responseSortTopRated:    "rating",
+	responseSortMostReacted: "reaction_count",
+}
+
+// responseSuggestions names the suggestion values for filtering.
+var responseSuggestions = map[string]int{
+	"dont_do_it": 1,
+	"mixed":      2,
+	"do_it":      3,
+}
+
+// responseFilter narrows the feed. It is applied in the query, so pages stay
+// full however few responses match.
+type responseFilter struct {
+	// Suggestion is 1-3, or 0 for any.
+	Suggestion   int
+	MinRating    int
+	MaxRating    int
+	WithComments bool
+}
+
+// parseResponseFilter reads suggestion (do_it, dont_do_it or mixed),
+// min_rating, max_rating and with_comments from the query string.
+func parseResponseFilter(r *nethttp.Request) (responseFilter, error) {
+	query := r.URL.Query()
+	filter := responseFilter{MinRating: 1, MaxRating: 5}
+	if raw := strings.TrimSpace(query.Get("suggestion")); raw != "" {
+		suggestion, ok := responseSuggestions[raw]
+		if !ok {
+			return responseFilter{}, errors.New("suggestion must be one of do_it, dont_do_it, mixed")
+		}
+		filter.Suggestion = suggestion
+	}
+	for _, bound := range []struct {
+		name  string
+		value *int
+	}{
+		{"min_rating", &filter.MinRating},
+		{"max_rating", &filter.MaxRating},
+	} {
+		raw := strings.TrimSpace(query.Get(bound.name))
+		if raw == "" {
+			continue
+		}
+		rating, err := strconv.Atoi(raw)
+		if err != nil || rating < 1 || rating > 5 {
+			return responseFilter{}, errors.New(bound.name + " must be between 1 and 5")
+		}
+		*bound.value = rating
+	}
+	if filter.MinRating > filter.MaxRating {
+		return responseFilter{}, errors.New("min_rating must not be above max_rating")
+	}
+	if raw := strings.TrimSpace(query.Get("with_comments")); raw != "" {
+		withComments, err := strconv.ParseBool(raw)
+		if err != nil {
+			return responseFilter{}, errors.New("with_comments must be true or false")
+		}
+		filter.WithComments = withComments
+	}
+	return filter, nil
+}
+
+type responsePage struct {
+	Responses []responseCard `json:"responses"`
+	// NextCursor fetches the page after this one; null on the last page.
+	NextCursor *string `json:"next_cursor"`
+}
+
+// responseCursor is where a page ended, in the page's sort order. It is
+// handed out base64-encoded and clients treat it as opaque.
+type responseCursor struct {
+	Sort      string    `json:"s"`
+	Key       int       `json:"k"`
+	CreatedAt time.Time `json:"t"`
+	ID        uuid.UUID `json:"id"`
+}
+
+func (c responseCursor) encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func parseResponseCursor(raw, sort string) (*responseCursor, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	decoded, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, errors.New("cursor is not valid")
+	}
+	var cursor responseCursor
+	if err := json.Unmarshal(decoded, &cursor); err != nil || cursor.ID == uuid.Nil {
+		return nil, errors.New("cursor is not valid")
+	}
+	if cursor.Sort != sort {
+		return nil, errors.New("cursor belongs to a different sort")
+	}
+	return &cursor, nil
+}
+
+// handleListResponses pages through a decision's response cards, newest
+// first or by sort=top_rated or sort=most_reacted, optionally filtered (see
+// parseResponseFilter). The same responses are left out as on the decision
+// page: hidden and shadowed ones, all of them on aggregate-only decisions
+// and all of them until the quorum is met. Ordering by reactions follows
+// live counts, so a card whose count changes between pages may be skipped
+// or repeated.
+func (s *Server) handleListResponses(w nethttp.ResponseWriter, r *nethttp.Request) {
+	slug, err := normalizeSlugParam(chi.URLParam(r, "slug"))
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+	sort := strings.TrimSpace(r.URL.Query().Get("sort"))
+	if sort == "" {
+		sort = responseSortNewest
+	}
+	if _, ok := responseSortKeys[sort]; !ok {
+		writeError(w, nethttp.StatusBadRequest, "sort must be one of newest, top_rated, most_reacted")
+		return
+	}
+	limit, err := parseLimitParam(r, defaultResponsePageLimit, maxResponsePageLimit)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+	cursor, err := parseResponseCursor(r.URL.Query().Get("cursor"), sort)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+	filter, err := parseResponseFilter(r)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx := r.Context()
+	decision, err := s.decisions.BySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			writeProblem(w, nethttp.StatusNotFound, errorCodeDecisionNotFound, "decision not found")
+			return
+		}
+		s.writeServerError(w, err, "failed to load decision")
+		return
+	}
+	if !requireDecisionAccess(w, r, decision) {
+		return
+	}
+	if decision.AggregateOnly {
+		writeProblem(w, nethttp.StatusConflict, errorCodeAggregateOnly, "individual responses are not available for aggregate-only decisions")
+		return
+	}
+
+	ctx, cancel := withBudget(ctx, statsQueryBudget)
+	defer cancel()
+	var responseCount int
+	if err := s.db.QueryRowContext(ctx, `
+		SELECT COALESCE((SELECT response_count FROM decision_stats WHERE decision_id = $1), 0)
+	`, decision.ID).Scan(&responseCount); err != nil {
+		s.writeServerError(w, err, "failed to load responses")
+		return
+	}
+	if quorumPending(decision, responseCount, time.Now()) {
+		writeJSON(w, nethttp.StatusOK, responsePage{Responses: []responseCard{}})
+		return
+	}
+
+	page, err := s.loadResponsePage(ctx, decision.ID, sort, filter, cursor, limit)
+	if err != nil {
+		s.writeServerError(w, err, "failed to load responses")
+		return
+	}
+	writeJSON(w, nethttp.StatusOK, page)
+}
+
+// loadResponsePage reads up to limit visible cards matching filter after
+// cursor. Every order is descending on (sort key, created_at, id), so one
+// row comparison against the cursor finds where the page starts.
+func (s *Server) loadResponsePage(ctx context.Context, decisionID uuid.UUID, sort string, filter responseFilter, cursor *responseCursor, limit int) (responsePage, error) {
+	sortKey := responseSortKeys[sort]
+	var (
+		after          bool
+		afterKey       int
+		afterCreatedAt time.Time
+		afterID        uuid.UUID
+	)
+	if cursor != nil {
+		after, afterKey, afterCreatedAt, afterID = true, cursor.Key, cursor.CreatedAt, cursor.ID
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		WITH feed AS (
+			SELECT
+				r.id, r.rating, r.suggestion, r.emoji, r.comment, r.language,
+				r.created_at, r.edited_at, r.panel_member_id IS NOT NULL AS panel_member, r.nickname,
+				COALESCE(reactions.counts, '{}'::jsonb) AS reactions,
+				COALESCE(reactions.total, 0)::int AS reaction_count
+			FROM responses r
+			LEFT JOIN LATERAL (
+				SELECT jsonb_object_agg(emoji, count) AS counts, SUM(count) AS total
+				FROM (
+					SELECT emoji, COUNT(*) AS count
+					FROM response_reactions
+					WHERE response_id = r.id AND NOT shadowed
+					GROUP BY emoji
+				) grouped
+			) reactions ON true
+			WHERE r.decision_id = $1 AND r.hidden_at IS NULL AND NOT r.shadowed
+				AND ($7::int = 0 OR r.suggestion = $7)
+				AND r.rating BETWEEN $8 AND $9
+				AND (NOT $10::bool OR r.comment IS NOT NULL)
+		)
+		SELECT id, rating, suggestion, emoji, comment, language, created_at, edited_at, panel_member, nickname, reactions, `+sortKey+`
+		FROM feed
+		WHERE NOT $2::bool OR (`+sortKey+`, created_at, id) < ($3::int, $4::timestamptz, $5::uuid)
+		ORDER BY `+sortKey+` DESC, created_at DESC, id DESC
+		LIMIT $6
+	`, decisionID, after, afterKey, afterCreatedAt, afterID, limit+1,
+		filter.Suggestion, filter.MinRating, filter.MaxRating, filter.WithComments)
+	if err != nil {
+		return responsePage{}, err
+	}
+	defer rows.Close()
+
+	page := responsePage{Responses: make([]responseCard, 0, limit)}
+	var last responseCursor
+	for rows.Next() {
+		if len(page.Responses) == limit {
+			next := last.encode()
+			page.NextCursor = &next
+			break
+		}
+		var (
+			resp      store.Response
+			reactions []byte
+			key       int
+		)
+		if err := rows.Scan(
+			&resp.ID, &resp.Rating, &resp.Suggestion, &resp.Emoji, &resp.Comment, &resp.Language,
+			&resp.CreatedAt, &resp.EditedAt, &resp.PanelMember, &resp.Nickname, &reactions, &key,
+		); err != nil {
+			return responsePage{}, err
+		}
+		if err := json.Unmarshal(reactions, &resp.Reactions); err != nil {
+			return responsePage{}, err
+		}
+		page.Responses = append(page.Responses, responseCardFromStore(resp))
+		last = responseCursor{Sort: sort, Key: key, CreatedAt: resp.CreatedAt, ID: resp.ID}
+	}
+	return page, rows.Err()
+}
+
+// envelopeResponses returns the cards carried in the decision envelope and
+// whether any were left for the responses endpoint.
+func envelopeResponses(snapshot decisionSnapshot) ([]responseCard, bool) {
+	cards := visibleResponses(snapshot.Decision, snapshot.Responses)
+	return cards, snapshot.MoreResponses && !snapshot.Decision.AggregateOnly
+}
+
+// loadAllResponses pages through every visible card of a decision, newest
+// first, for callers that need more than the snapshot carries.
+func (s *Server) loadAllResponses(ctx context.Context, decisionID uuid.UUID) ([]responseCard, error) {
+	filter := responseFilter{MinRating: 1, MaxRating: 5}
+	cards := []responseCard{}
+	var cursor *responseCursor
+	for {
+		page, err := s.loadResponsePage(ctx, decisionID, responseSortNewest, filter, cursor, maxResponsePageLimit)
+		if err != nil {
+			return nil, err
+		}
+		cards = append(cards, page.Responses...)
+		if page.NextCursor == nil {
+			return cards, nil
+		}
+		if cursor, err = parseResponseCursor(*page.NextCursor, responseSortNewest); err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package httpapi
+
+import (
+	"context"
+	"fmt"
+
+	"ratemylifedecision/internal/store"
+)
+
+// maxMaxResponses caps the response limit a creator can set.
+const maxMaxResponses = 100000
+
+const errorCodeResponseLimitReached = "response_limit_reached"
+
+func normalizeMaxResponses(maxResponses int) (int, error) {
+	if maxResponses < 0 || maxResponses > maxMaxResponses {
+		return 0, fmt.Errorf("max_responses must be between 0 and %d", maxMaxResponses)
+	}
+	return maxResponses, nil
+}
+
+// responseLimitReached reports whether decision closed because it took its
+// max_responses, rather than at its closing time.
+func (s *Server) responseLimitReached(ctx context.Context, decision store.Decision) (bool, error) {
+	if decision.MaxResponses <= 0 {
+		return false, nil
+	}
+	st, err := s.loadDecisionStats(ctx, decision.ID)
+	if err != nil {
+		return false, err
+	}
+	return st.ResponseCount >= decision.MaxResponses, nil
+}
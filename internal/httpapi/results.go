@@ -0,0 +1,109 @@
+package httpapi
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/store"
+)
+
+// A closed decision's stats, recommendation and post vote totals are frozen
+// in decision_results the first time it is read after closing, which the
+// close job does straight away. From then on they are served from there,
+// so late votes, moderation and retention can change what is stored but
+// not the result people saw at close.
+
+type frozenResults struct {
+	Stats          decisionStats
+	Recommendation recommendationView
+	PostVote       decisionVoteSummary
+}
+
+// loadFrozenResults reads a decision's frozen results. It reports false if
+// the decision has none, because it is open or not read since closing.
+func (s *Server) loadFrozenResults(ctx context.Context, decisionID uuid.UUID) (frozenResults, bool, error) {
+	var stats, recommendation, postVote []byte
+	err := s.db.QueryRowContext(ctx, `
+		SELECT stats, recommendation, post_vote FROM decision_results WHERE decision_id = $1
+	`, decisionID).Scan(&stats, &recommendation, &postVote)
+	if errors.Is(err, sql.ErrNoRows) {
+		return frozenResults{}, false, nil
+	}
+	if err != nil {
+		return frozenResults{}, false, err
+	}
+
+	var out frozenResults
+	if err := json.Unmarshal(stats, &out.Stats); err != nil {
+		return frozenResults{}, false, err
+	}
+	if err := json.Unmarshal(recommendation, &out.Recommendation); err != nil {
+		return frozenResults{}, false, err
+	}
+	if err := json.Unmarshal(postVote, &out.PostVote); err != nil {
+		return frozenResults{}, false, err
+	}
+	return out, true, nil
+}
+
+// applyFrozenResults replaces the stats, recommendation and post vote
+// totals of a closed decision's snapshot with the frozen ones, freezing the
+// snapshot's own if there are none yet.
+func (s *Server) applyFrozenResults(ctx context.Context, snapshot *decisionSnapshot) error {
+	frozen, ok, err := s.loadFrozenResults(ctx, snapshot.Decision.ID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return s.freezeResults(ctx, *snapshot)
+	}
+	snapshot.Stats, snapshot.Recommendation, snapshot.PostVote = frozen.Stats, frozen.Recommendation, frozen.PostVote
+	return nil
+}
+
+// frozenRecommendation returns the recommendation frozen when the closed
+// decision closed, freezing its results first if nothing has read it since.
+// An open decision has nothing frozen yet and gets the live one.
+func (s *Server) frozenRecommendation(ctx context.Context, decision store.Decision) (recommendationView, error) {
+	frozen, ok, err := s.loadFrozenResults(ctx, decision.ID)
+	if err != nil {
+		return recommendationView{}, err
+	}
+	if ok {
+		return frozen.Recommendation, nil
+	}
+	// A cached snapshot may predate the close; a fresh read freezes.
+	s.cache.Invalidate(decision.ID)
+	snapshot, _, _, err := s.loadDecisionView(ctx, decision.Slug, nil)
+	if err != nil {
+		return recommendationView{}, err
+	}
+	return snapshot.Recommendation, nil
+}
+
+// freezeResults stores snapshot's results for its decision. The first
+// freeze wins; later ones change nothing.
+func (s *Server) freezeResults(ctx context.Context, snapshot decisionSnapshot) error {
+	stats, err := json.Marshal(snapshot.Stats)
+	if err != nil {
+		return err
+	}
+	recommendation, err := json.Marshal(snapshot.Recommendation)
+	if err != nil {
+		return err
+	}
+	postVote, err := json.Marshal(snapshot.PostVote)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO decision_results (decision_id, stats, recommendation, post_vote)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (decision_id) DO NOTHING
+	`, snapshot.Decision.ID, stats, recommendation, postVote)
+	return err
+}
@@ -0,0 +1,206 @@
+package httpapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	nethttp "net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/database"
+)
+
+const (
+	retentionModeOff       = "off"
+	retentionModeAnonymize = "anonymize"
+	retentionModePurge     = "purge"
+
+	retentionBatchSize = 100
+)
+
+// retentionPolicy says what happens to decisions once they have been closed
+// for longer than after. Decisions without closes_at never close and so are
+// kept indefinitely.
+type retentionPolicy struct {
+	mode   string
+	after  time.Duration
+	dryRun bool
+}
+
+type retentionReport struct {
+	Mode           string     `json:"mode"`
+	DryRun         bool       `json:"dry_run"`
+	RetainFor      string     `json:"retain_for"`
+	Cutoff         time.Time  `json:"cutoff"`
+	Decisions      int        `json:"decisions"`
+	Responses      int        `json:"responses"`
+	OldestClosedAt *time.Time `json:"oldest_closed_at"`
+}
+
+// handleRetentionReport shows what the retention policy would do if it ran
+// now, without changing anything. With the policy off it counts the
+// decisions that anonymizing would touch, to help pick a policy.
+func (s *Server) handleRetentionReport(w nethttp.ResponseWriter, r *nethttp.Request) {
+	ctx, cancel := withBudget(r.Context(), statsQueryBudget)
+	defer cancel()
+	report, err := s.retentionReport(ctx)
+	if err != nil {
+		s.writeServerError(w, err, "failed to build retention report")
+		return
+	}
+	report.DryRun = true
+	writeJSON(w, nethttp.StatusOK, report)
+}
+
+func (s *Server) retentionReport(ctx context.Context) (retentionReport, error) {
+	p := s.retention
+	report := retentionReport{
+		Mode:      p.mode,
+		DryRun:    p.dryRun,
+		RetainFor: p.after.String(),
+		Cutoff:    time.Now().Add(-p.after).UTC(),
+	}
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*), COALESCE(SUM(st.response_count), 0), MIN(d.closed_at)
+		FROM decisions d
+		LEFT JOIN decision_stats st ON st.decision_id = d.id
+		WHERE d.closed_at < $1 AND ($2 OR d.archived_at IS NULL)
+	`, report.Cutoff, p.mode == retentionModePurge).Scan(&report.Decisions, &report.Responses, &report.OldestClosedAt)
+	return report, err
+}
+
+// applyRetention runs the retention policy. In dry-run mode it only logs
+// the report, so a policy can be checked against production data before
+// it is allowed to delete anything.
+func (s *Server) applyRetention(ctx context.Context) error {
+	p := s.retention
+	if p.dryRun {
+		report, err := s.retentionReport(ctx)
+		if err != nil {
+			return err
+		}
+		slog.Info("decision retention dry run",
+			"mode", report.Mode,
+			"retain_for", report.RetainFor,
+			"decisions", report.Decisions,
+			"responses", report.Responses,
+		)
+		return nil
+	}
+
+	var done int
+	for {
+		due, err := s.dueForRetention(ctx, p)
+		if err != nil {
+			return err
+		}
+		var errs []error
+		for _, d := range due {
+			var err error
+			if p.mode == retentionModePurge {
+				err = s.purgeDecision(ctx, d)
+			} else {
+				err = s.anonymizeDecision(ctx, d.id)
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s decision %s: %w", p.mode, d.id, err))
+				continue
+			}
+			done++
+		}
+		// A failed decision would be picked again straight away, so stop
+		// and leave it for the next run.
+		if len(errs) > 0 || len(due) < retentionBatchSize {
+			if done > 0 {
+				slog.Info("decision retention applied", "mode", p.mode, "decisions", done)
+			}
+			return errors.Join(errs...)
+		}
+	}
+}
+
+// purgeDecision deletes a decision for good, scrubbing its audit log rows
+// first since they outlive it.
+func (s *Server) purgeDecision(ctx context.Context, d closedDecision) error {
+	scrubCtx, cancel := withBudget(ctx, writeQueryBudget)
+	_, err := s.db.ExecContext(scrubCtx, scrubDecisionAuditLog, d.id)
+	cancel()
+	if err != nil {
+		return err
+	}
+	_, err = s.deleteDecision(ctx, d.slug)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	return err
+}
+
+func (s *Server) dueForRetention(ctx context.Context, p retentionPolicy) ([]closedDecision, error) {
+	ctx, cancel := withBudget(ctx, writeQueryBudget)
+	defer cancel()
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, slug, title FROM decisions
+		WHERE closed_at < now() - $1 * interval '1 second' AND ($2 OR archived_at IS NULL)
+		ORDER BY closed_at
+		LIMIT $3
+	`, p.after.Seconds(), p.mode == retentionModePurge, retentionBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var due []closedDecision
+	for rows.Next() {
+		var d closedDecision
+		if err := rows.Scan(&d.id, &d.slug, &d.title); err != nil {
+			return nil, err
+		}
+		due = append(due, d)
+	}
+	return due, rows.Err()
+}
+
+// anonymizeDecision keeps a closed decision's title, category and aggregate
+// results but removes what was written about or could identify people: the
+// description, comments, viewer IDs on responses, votes and reactions,
+// reports, panel invitations, subscriptions, webhooks, the creator's email
+// and the IPs and payloads of its audit log rows. The creator token stops
+// working too.
+func (s *Server) anonymizeDecision(ctx context.Context, decisionID uuid.UUID) error {
+	ctx, cancel := withBudget(ctx, writeQueryBudget)
+	defer cancel()
+	err := database.RetryTx(ctx, s.db, func(tx *sql.Tx) error {
+		for _, stmt := range []string{
+			scrubDecisionAuditLog,
+			`DELETE FROM reports
+			WHERE (target_kind = 'decision' AND target_id = $1)
+				OR (target_kind = 'response' AND target_id IN (SELECT id FROM responses WHERE decision_id = $1))`,
+			`UPDATE responses SET comment = NULL, viewer_id = gen_random_uuid(), panel_member_id = NULL
+			WHERE decision_id = $1`,
+			`UPDATE votes SET voter_viewer_id = gen_random_uuid()
+			WHERE response_id IN (SELECT id FROM responses WHERE decision_id = $1)`,
+			`UPDATE decision_votes SET voter_viewer_id = gen_random_uuid() WHERE decision_id = $1`,
+			`UPDATE response_reactions SET viewer_id = gen_random_uuid()
+			WHERE response_id IN (SELECT id FROM responses WHERE decision_id = $1)`,
+			`DELETE FROM decision_panel_members WHERE decision_id = $1`,
+			`DELETE FROM notification_subscriptions WHERE decision_id = $1`,
+			`DELETE FROM decision_webhooks WHERE decision_id = $1`,
+			`DELETE FROM creator_emails WHERE decision_id = $1`,
+			`UPDATE decisions SET description = NULL, creator_token_hash = NULL, archived_at = now()
+			WHERE id = $1`,
+		} {
+			if _, err := tx.ExecContext(ctx, stmt, decisionID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.cache.Invalidate(decisionID)
+	return nil
+}
@@ -0,0 +1,242 @@
+package httpapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/store"
+)
+
+// regretWorthItMax is the highest worth-it score counted as regret.
+const regretWorthItMax = 2
+
+const (
+	errorCodeOutcomeNotRecorded    = "outcome_not_recorded"
+	errorCodeRetrospectiveTooEarly = "retrospective_too_early"
+)
+
+type retrospectiveRequest struct {
+	// ViewerToken is only used by responders.
+	ViewerToken string `json:"viewer_token"`
+	// WorthIt is 1 (wish I hadn't) to 5 (glad I did).
+	WorthIt *int `json:"worth_it"`
+}
+
+type retrospectiveView struct {
+	WorthIt    int       `json:"worth_it"`
+	RecordedAt time.Time `json:"recorded_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type regretBucket struct {
+	Category *string `json:"category,omitempty"`
+	// Retrospectives are the creators' own.
+	Retrospectives int      `json:"retrospectives"`
+	AvgWorthIt     *float64 `json:"avg_worth_it"`
+	// RegretRate is the share of retrospectives scoring regretWorthItMax
+	// or lower, split by whether the creator went with the crowd.
+	RegretRate             *float64 `json:"regret_rate"`
+	RegretRateWhenFollowed *float64 `json:"regret_rate_when_followed"`
+	RegretRateWhenIgnored  *float64 `json:"regret_rate_when_ignored"`
+	// ResponderRetrospectives are responders looking back on the same
+	// decisions.
+	ResponderRetrospectives int      `json:"responder_retrospectives"`
+	ResponderAvgWorthIt     *float64 `json:"responder_avg_worth_it"`
+}
+
+type regretInsightsResponse struct {
+	Overall    regretBucket   `json:"overall"`
+	ByCategory []regretBucket `json:"by_category"`
+}
+
+// handleRecordRetrospective lets the creator say, well after the fact,
+// whether what they did was worth it. It can be changed later.
+func (s *Server) handleRecordRetrospective(w nethttp.ResponseWriter, r *nethttp.Request) {
+	decision, ok := s.loadCreatorDecision(w, r)
+	if !ok {
+		return
+	}
+	req, ok := decodeRetrospective(w, r)
+	if !ok {
+		return
+	}
+	s.recordRetrospective(w, r, decision, nil, *req.WorthIt)
+}
+
+// handleRecordMyRetrospective is the same for someone who responded to the
+// decision, looking back on how it turned out.
+func (s *Server) handleRecordMyRetrospective(w nethttp.ResponseWriter, r *nethttp.Request) {
+	req, ok := decodeRetrospective(w, r)
+	if !ok {
+		return
+	}
+	viewer, ok := s.requireViewer(w, r, req.ViewerToken, "")
+	if !ok {
+		return
+	}
+	slug, err := normalizeSlugParam(chi.URLParam(r, "slug"))
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+	decision, err := s.decisions.BySlug(r.Context(), slug)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			writeProblem(w, nethttp.StatusNotFound, errorCodeDecisionNotFound, "decision not found")
+			return
+		}
+		s.writeServerError(w, err, "failed to load decision")
+		return
+	}
+	if !requireDecisionAccess(w, r, decision) {
+		return
+	}
+
+	var responded bool
+	if err := s.db.QueryRowContext(r.Context(), `
+		SELECT EXISTS (SELECT 1 FROM responses WHERE decision_id = $1 AND viewer_id = $2 AND NOT shadowed)
+	`, decision.ID, viewer.ID).Scan(&responded); err != nil {
+		s.writeServerError(w, err, "failed to load response")
+		return
+	}
+	if !responded {
+		writeProblem(w, nethttp.StatusForbidden, errorCodeNoResponse, "only people who responded can look back on this decision")
+		return
+	}
+	s.recordRetrospective(w, r, decision, &viewer.ID, *req.WorthIt)
+}
+
+func decodeRetrospective(w nethttp.ResponseWriter, r *nethttp.Request) (retrospectiveRequest, bool) {
+	var req retrospectiveRequest
+	if err := decodeJSON(w, r, maxOutcomeBodyBytes, &req); err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return retrospectiveRequest{}, false
+	}
+	if req.WorthIt == nil {
+		writeError(w, nethttp.StatusBadRequest, "worth_it is required")
+		return retrospectiveRequest{}, false
+	}
+	if *req.WorthIt < 1 || *req.WorthIt > 5 {
+		writeError(w, nethttp.StatusBadRequest, "worth_it must be between 1 and 5")
+		return retrospectiveRequest{}, false
+	}
+	return req, true
+}
+
+// recordRetrospective stores the creator's retrospective (viewerID nil) or
+// a responder's, once the outcome has been on record for
+// RETROSPECTIVE_MIN_AGE.
+func (s *Server) recordRetrospective(w nethttp.ResponseWriter, r *nethttp.Request, decision store.Decision, viewerID *uuid.UUID, worthIt int) {
+	ctx := r.Context()
+	var outcomeRecordedAt time.Time
+	err := s.db.QueryRowContext(ctx, `
+		SELECT recorded_at FROM decision_outcomes WHERE decision_id = $1
+	`, decision.ID).Scan(&outcomeRecordedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeProblem(w, nethttp.StatusConflict, errorCodeOutcomeNotRecorded, "the creator has not recorded what they did yet")
+		return
+	}
+	if err != nil {
+		s.writeServerError(w, err, "failed to load outcome")
+		return
+	}
+	minAge := s.cfg.Limits.RetrospectiveMinAge
+	if opensAt := outcomeRecordedAt.Add(minAge); time.Now().Before(opensAt) {
+		writeProblem(w, nethttp.StatusConflict, errorCodeRetrospectiveTooEarly,
+			fmt.Sprintf("retrospectives open %s, a while after the outcome", opensAt.UTC().Format(time.DateOnly)))
+		return
+	}
+
+	conflict := "(decision_id) WHERE viewer_id IS NULL"
+	if viewerID != nil {
+		conflict = "(decision_id, viewer_id)"
+	}
+	var out retrospectiveView
+	err = s.db.QueryRowContext(ctx, `
+		INSERT INTO decision_retrospectives (decision_id, viewer_id, worth_it)
+		VALUES ($1, $2, $3)
+		ON CONFLICT `+conflict+` DO UPDATE SET
+			worth_it = EXCLUDED.worth_it,
+			updated_at = now()
+		RETURNING worth_it, recorded_at, updated_at
+	`, decision.ID, viewerID, worthIt).Scan(&out.WorthIt, &out.RecordedAt, &out.UpdatedAt)
+	if err != nil {
+		s.writeServerError(w, err, "failed to record retrospective")
+		return
+	}
+	writeJSON(w, nethttp.StatusOK, out)
+}
+
+func (s *Server) handleRegretInsights(w nethttp.ResponseWriter, r *nethttp.Request) {
+	ctx := r.Context()
+
+	overall, err := s.queryRegret(ctx, false)
+	if err != nil || len(overall) != 1 {
+		s.writeServerError(w, err, "failed to compute regret")
+		return
+	}
+	byCategory, err := s.queryRegret(ctx, true)
+	if err != nil {
+		s.writeServerError(w, err, "failed to compute regret")
+		return
+	}
+
+	writeJSON(w, nethttp.StatusOK, regretInsightsResponse{
+		Overall:    overall[0],
+		ByCategory: byCategory,
+	})
+}
+
+func (s *Server) queryRegret(ctx context.Context, byCategory bool) ([]regretBucket, error) {
+	ctx, cancel := withBudget(ctx, statsQueryBudget)
+	defer cancel()
+
+	groupBy := ""
+	categoryExpr := "NULL::text"
+	if byCategory {
+		groupBy = "GROUP BY d.category ORDER BY COUNT(*) FILTER (WHERE rt.viewer_id IS NULL) DESC, d.category ASC"
+		categoryExpr = "COALESCE(d.category, 'uncategorized')"
+	}
+
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT
+			%[1]s AS category,
+			COUNT(*) FILTER (WHERE rt.viewer_id IS NULL)::int,
+			AVG(rt.worth_it) FILTER (WHERE rt.viewer_id IS NULL)::float8,
+			AVG((rt.worth_it <= %[3]d)::int) FILTER (WHERE rt.viewer_id IS NULL)::float8,
+			AVG((rt.worth_it <= %[3]d)::int) FILTER (WHERE rt.viewer_id IS NULL AND o.did_it = (o.recommendation = 'yes'))::float8,
+			AVG((rt.worth_it <= %[3]d)::int) FILTER (WHERE rt.viewer_id IS NULL AND o.did_it <> (o.recommendation = 'yes'))::float8,
+			COUNT(*) FILTER (WHERE rt.viewer_id IS NOT NULL)::int,
+			AVG(rt.worth_it) FILTER (WHERE rt.viewer_id IS NOT NULL)::float8
+		FROM decision_retrospectives rt
+		JOIN decision_outcomes o ON o.decision_id = rt.decision_id
+		JOIN decisions d ON d.id = rt.decision_id
+		WHERE d.hidden_at IS NULL
+		%[2]s
+	`, categoryExpr, groupBy, regretWorthItMax))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	buckets := make([]regretBucket, 0)
+	for rows.Next() {
+		var b regretBucket
+		if err := rows.Scan(
+			&b.Category, &b.Retrospectives, &b.AvgWorthIt,
+			&b.RegretRate, &b.RegretRateWhenFollowed, &b.RegretRateWhenIgnored,
+			&b.ResponderRetrospectives, &b.ResponderAvgWorthIt,
+		); err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets, rows.Err()
+}
@@ -1,6 +1,7 @@
 package httpapi
 
 import (
+	"compress/gzip"
 	"context"
 	"crypto/rand"
 	"database/sql"
@@ -8,10 +9,11 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"math/big"
 	"net"
 	nethttp "net/http"
-	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -22,26 +24,38 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"ratemylifedecision/internal/auth"
+	"ratemylifedecision/internal/captcha"
+	"ratemylifedecision/internal/config"
+	"ratemylifedecision/internal/contentfilter"
+	"ratemylifedecision/internal/database"
+	"ratemylifedecision/internal/graphql"
+	"ratemylifedecision/internal/jobs"
+	"ratemylifedecision/internal/mailer"
+	"ratemylifedecision/internal/notify"
+	"ratemylifedecision/internal/sentiment"
+	"ratemylifedecision/internal/stats"
+	"ratemylifedecision/internal/store"
+	"ratemylifedecision/internal/webhooks"
 )
 
 const (
 	slugMaxAttempts            = 8
 	slugMaxLength              = 128
+	vanitySlugMinLength        = 3
 	titleMinLength             = 4
 	titleMaxLength             = 100
 	descriptionMaxLength       = 500
 	maxCommentLength           = 180
-	maxCreateDecisionBodyBytes = 4 * 1024
+	maxCreateDecisionBodyBytes = 16 * 1024
 	maxResponseBodyBytes       = 4 * 1024
 	maxVoteBodyBytes           = 2 * 1024
-	suggestionWeight           = 0.35
-	ratingWeight               = 0.30
-	commentSentimentWeight     = 0.20
-	postVoteWeight             = 0.15
 	recommendationYesThreshold = 0.0
-	ipRateLimitPerMinute       = 120
 	viewerRateLimitPerMinute   = 60
 	rateLimitWindow            = time.Minute
+	compressionMinBytes        = 1024
 )
 
 var emojiRatings = map[string]int{
@@ -52,58 +66,52 @@ var emojiRatings = map[string]int{
 	"🫡": 5,
 }
 
-var positiveSentimentWords = map[string]struct{}{
-	"amazing":     {},
-	"better":      {},
-	"benefit":     {},
-	"best":        {},
-	"excellent":   {},
-	"good":        {},
-	"great":       {},
-	"growth":      {},
-	"happy":       {},
-	"love":        {},
-	"opportunity": {},
-	"positive":    {},
-	"safe":        {},
-	"smart":       {},
-	"strong":      {},
-	"support":     {},
-	"upside":      {},
-	"worth":       {},
-	"yes":         {},
-	"win":         {},
-}
-
-var negativeSentimentWords = map[string]struct{}{
-	"bad":       {},
-	"concern":   {},
-	"costly":    {},
-	"difficult": {},
-	"downside":  {},
-	"expensive": {},
-	"hard":      {},
-	"hate":      {},
-	"loss":      {},
-	"negative":  {},
-	"no":        {},
-	"problem":   {},
-	"risk":      {},
-	"risky":     {},
-	"stress":    {},
-	"unsafe":    {},
-	"worse":     {},
-	"worst":     {},
-}
-
 type Server struct {
+	cfg               config.Config
+	pool              *pgxpool.Pool
 	db                *sql.DB
-	ipLimiter         *fixedWindowLimiter
-	viewerLimiter     *fixedWindowLimiter
+	rateLimits        map[string]*routeRateLimit
+	viewerLimiter     rateLimiter
 	allowedOrigins    map[string]struct{}
 	allowAnyOrigin    bool
 	trustProxyHeaders bool
-	writeAPIKeys      map[string]struct{}
+	apiKeys           *apiKeyRing
+	requireReadAPIKey bool
+	grpcAPIKeys       map[string]struct{}
+	hub               *liveHub
+	cache             *decisionCache
+	notifier          *notify.Dispatcher
+	webhooks          *webhooks.Dispatcher
+	mailer            mailer.Mailer
+	jobs              *jobs.Scheduler
+	retention         retentionPolicy
+	frontendBaseURL   string
+	metrics           *serverMetrics
+	adaptive          *adaptiveLimits
+	adminAPIKey       string
+	slack             slackConfig
+	viewerTokens      *viewerTokenSigner
+	// oauthProviders are the configured sign-in providers by name; accounts
+	// are optional and empty disables them.
+	oauthProviders       map[string]*auth.Provider
+	oauthRedirectBaseURL string
+	authTokens           *auth.Signer
+	contentFilter        *contentfilter.Filter
+	ipFilter             *ipFilter
+	captcha              *captcha.Verifier
+	captchaConfigErr     error
+	instanceID           string
+	peerNotify           bool
+	decisions            store.DecisionStore
+	responses            store.ResponseStore
+	votes                store.VoteStore
+	router               nethttp.Handler
+	graphql              *graphql.Schema
+	// shutdown is cancelled when the server starts draining. Long-lived
+	// streams and background loops select on it.
+	shutdown     context.Context
+	stopShutdown context.CancelFunc
+	workers      sync.WaitGroup
 }
 
 type rateWindowCounter struct {
@@ -112,58 +120,260 @@ type rateWindowCounter struct {
 }
 
 type fixedWindowLimiter struct {
-	mu          sync.Mutex
-	window      time.Duration
-	limit       int
-	buckets     map[string]rateWindowCounter
-	lastCleanup time.Time
-}
-
-func New(db *sql.DB) nethttp.Handler {
-	allowedOrigins, allowAnyOrigin := loadAllowedOriginsFromEnv()
+	mu      sync.Mutex
+	window  time.Duration
+	limit   int
+	buckets map[string]rateWindowCounter
+}
+
+// New builds the server from cfg, which config.Load has already checked.
+// It fails when a secret the server cannot run without is missing, or when
+// API keys or the content filter are malformed rather than run without
+// them.
+func New(pool *pgxpool.Pool, cfg config.Config) (*Server, error) {
+	authTokens, err := auth.SignerFromEnv(cfg.Environment == config.EnvDevelopment)
+	if err != nil {
+		return nil, fmt.Errorf("%w; it is only optional with APP_ENV=development", err)
+	}
+	viewerTokens, err := newViewerTokenSigner(cfg.Keys.ViewerTokenSecret, cfg.Environment == config.EnvDevelopment)
+	if err != nil {
+		return nil, fmt.Errorf("%w; it is only optional with APP_ENV=development", err)
+	}
+	apiKeys, err := newAPIKeyRing(cfg.Keys.WriteAPIKeys)
+	if err != nil {
+		return nil, fmt.Errorf("WRITE_API_KEYS: %w", err)
+	}
+	contentFilter, err := contentfilter.FromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("content filter: %w", err)
+	}
+	grpcAPIKeys := make(map[string]struct{}, len(cfg.Keys.GRPCAPIKeys))
+	for _, key := range cfg.Keys.GRPCAPIKeys {
+		grpcAPIKeys[key] = struct{}{}
+	}
+	db := database.SQL(pool)
+	allowedOrigins := make(map[string]struct{}, len(cfg.CORS.AllowedOrigins))
+	for _, origin := range cfg.CORS.AllowedOrigins {
+		allowedOrigins[origin] = struct{}{}
+	}
+	shutdown, stopShutdown := context.WithCancel(context.Background())
+	stores := store.NewPostgres(db)
 	s := &Server{
-		db:                db,
-		ipLimiter:         newFixedWindowLimiter(ipRateLimitPerMinute, rateLimitWindow),
-		viewerLimiter:     newFixedWindowLimiter(viewerRateLimitPerMinute, rateLimitWindow),
-		allowedOrigins:    allowedOrigins,
-		allowAnyOrigin:    allowAnyOrigin,
-		trustProxyHeaders: parseBoolEnv("TRUST_PROXY_HEADERS", false),
-		writeAPIKeys:      loadAPIKeysFromEnv("WRITE_API_KEYS"),
+		cfg:                  cfg,
+		pool:                 pool,
+		db:                   db,
+		rateLimits:           loadRouteRateLimits(cfg.Limits),
+		viewerLimiter:        newRateLimiter(cfg.Limits, viewerRateLimitPerMinute, rateLimitWindow),
+		allowedOrigins:       allowedOrigins,
+		allowAnyOrigin:       cfg.CORS.AllowAnyOrigin,
+		trustProxyHeaders:    cfg.Features.TrustProxyHeaders,
+		requireReadAPIKey:    cfg.Features.RequireReadAPIKey,
+		grpcAPIKeys:          grpcAPIKeys,
+		hub:                  newLiveHub(),
+		cache:                newDecisionCache(cfg.HTTP.DecisionCacheTTL),
+		notifier:             notify.NewDispatcher(db, notify.NotifiersFromEnv()...),
+		frontendBaseURL:      cfg.HTTP.FrontendBaseURL,
+		metrics:              newServerMetrics(),
+		adaptive:             newAdaptiveLimits(viewerRateLimitPerMinute),
+		adminAPIKey:          cfg.Keys.AdminAPIKey,
+		slack:                loadSlackConfigFromEnv(),
+		viewerTokens:         viewerTokens,
+		oauthRedirectBaseURL: loadOAuthRedirectBaseURL(),
+		authTokens:           authTokens,
+		apiKeys:              apiKeys,
+		contentFilter:        contentFilter,
+		ipFilter:             newIPFilterFromEnv(cfg.Features.IPAllowlistOnly),
+		instanceID:           uuid.NewString(),
+		peerNotify:           cfg.Features.PeerNotify,
+		decisions:            stores.Decisions,
+		responses:            stores.Responses,
+		votes:                stores.Votes,
+		shutdown:             shutdown,
+		stopShutdown:         stopShutdown,
+	}
+	s.webhooks = webhooks.NewDispatcher(db, s.frontendBaseURL, s.webhookClosedData)
+	s.mailer, err = mailer.FromEnv()
+	if err != nil {
+		slog.Error("mail provider misconfigured; creator emails are disabled", "error", err)
+	}
+	s.retention = retentionPolicy{mode: cfg.Retention.Mode, after: cfg.Retention.After, dryRun: cfg.Retention.DryRun}
+	s.oauthProviders, err = auth.ProvidersFromEnv()
+	if err != nil {
+		slog.Error("oauth misconfigured; sign-in is disabled", "error", err)
+	}
+	if len(s.oauthProviders) > 0 && s.oauthRedirectBaseURL == "" {
+		slog.Error("OAUTH_REDIRECT_BASE_URL is not set; sign-in is disabled")
+	}
+	s.captcha, s.captchaConfigErr = captcha.FromEnv()
+	if s.captchaConfigErr != nil {
+		slog.Error("captcha misconfigured; rejecting protected writes", "error", s.captchaConfigErr)
 	}
+	s.jobs = jobs.New()
+	s.registerJobs()
+	s.workers.Add(1)
+	go func() {
+		defer s.workers.Done()
+		s.jobs.Run(s.shutdown)
+	}()
+
+	if s.peerNotify {
+		s.workers.Add(1)
+		go func() {
+			defer s.workers.Done()
+			s.runChangeListener(s.shutdown)
+		}()
+	}
+
 	r := chi.NewRouter()
+	r.Use(s.requestLogMiddleware)
+	r.Use(compressionMiddleware)
 	r.Use(s.securityHeadersMiddleware)
 	r.Use(s.corsMiddleware)
-	r.Use(s.rateLimitMiddleware)
+	r.Use(s.requestBudgetMiddleware(cfg.HTTP.RequestBudget))
+
+	// Probes sit outside the rate-limited and IP-filtered groups so the
+	// orchestrator can never be throttled or locked out into marking the
+	// instance unhealthy.
+	r.Get("/healthz", s.handleLiveness)
+	r.Get("/readyz", s.handleReadiness)
+	// /health predates the liveness/readiness split; kept for old probes.
+	r.Get("/health", s.handleLiveness)
+	r.Group(func(r chi.Router) {
+		r.Use(s.ipFilterMiddleware)
+		r.Use(s.rateLimitMiddleware("read"))
+		r.Post("/graphql", s.handleGraphQL)
+		r.Get("/feed.xml", s.handleFeed)
+		r.Get("/d/{slug}", s.handleShareLink)
+	})
+	r.Route("/v1", s.mountAPIV1)
+	// The unversioned /api paths predate /v1 and serve the same thing until
+	// LEGACY_API_SUNSET.
+	r.With(legacyAPIMiddleware("/api", "/v1", cfg.HTTP.LegacyAPISunset)).Route("/api", s.mountAPIV1)
 
-	r.Get("/health", s.handleHealth)
-	r.Get("/api/decisions/{slug}", s.handleGetDecision)
 	r.Group(func(r chi.Router) {
-		// Optional API key auth for write routes supports key rotation:
-		// provide one or more comma-separated keys via WRITE_API_KEYS.
-		r.Use(s.requireWriteAPIKeyMiddleware)
-		r.Post("/api/decisions", s.handleCreateDecision)
-		r.Post("/api/decisions/{slug}/responses", s.handleCreateResponse)
-		r.Post("/api/decisions/{slug}/vote", s.handleDecisionVote)
-		r.Post("/api/decisions/{slug}/votes", s.handleDecisionVote)
+		r.Use(s.ipFilterMiddleware)
+		r.Use(s.rateLimitMiddleware("write"))
+		r.Use(s.requireSlackSignatureMiddleware)
+		r.With(s.auditMiddleware).Post("/api/slack/commands", s.handleSlackCommand)
+		r.Post("/api/slack/events", s.handleSlackEvents)
+	})
+	r.Route("/api/admin", func(r chi.Router) {
+		r.Use(s.ipFilterMiddleware)
+		r.Use(s.rateLimitMiddleware("read"))
+		r.Use(s.auditMiddleware)
+		r.Use(s.requireAdminKeyMiddleware)
+		r.Get("/status", s.handleAdminStatus)
+		r.Get("/audit-log", s.handleListAuditLog)
+		r.Get("/reports", s.handleListReports)
+		r.Get("/retention", s.handleRetentionReport)
+		r.Put("/responses/{id}/hidden", s.handleHideResponse)
+		r.Delete("/responses/{id}/hidden", s.handleUnhideResponse)
+		r.Put("/decisions/{slug}/hidden", s.handleHideDecision)
+		r.Delete("/decisions/{slug}/hidden", s.handleUnhideDecision)
+		r.Delete("/decisions/{slug}", s.handleDeleteDecision)
+		r.Put("/viewers/{id}/ban", s.handleBanViewer)
+		r.Delete("/viewers/{id}/ban", s.handleUnbanViewer)
+		r.Get("/ip-rules", s.handleListIPRules)
+		r.Post("/ip-rules", s.handleCreateIPRule)
+		r.Delete("/ip-rules/{id}", s.handleDeleteIPRule)
+		r.Get("/api-keys", s.handleListAPIKeys)
+		r.Post("/api-keys", s.handleCreateAPIKey)
+		r.Post("/api-keys/{id}/rotate", s.handleRotateAPIKey)
+		r.Delete("/api-keys/{id}", s.handleRevokeAPIKey)
 	})
+	r.Route("/debug", func(r chi.Router) {
+		r.Use(s.ipFilterMiddleware)
+		r.Use(s.rateLimitMiddleware("read"))
+		r.Use(s.requireAdminKeyMiddleware)
+		mountDebugRoutes(r, s)
+	})
+
+	s.router = r
+	s.graphql = s.graphqlSchema()
+	return s, nil
+}
 
-	return r
+func (s *Server) ServeHTTP(w nethttp.ResponseWriter, r *nethttp.Request) {
+	s.router.ServeHTTP(w, r)
 }
 
-func (s *Server) handleHealth(w nethttp.ResponseWriter, _ *nethttp.Request) {
-	writeJSON(w, nethttp.StatusOK, map[string]bool{"ok": true})
+// BeginShutdown ends WebSocket and SSE streams and stops background loops.
+// Register it with http.Server.RegisterOnShutdown: streams never finish on
+// their own, so http.Server.Shutdown would otherwise wait out its deadline.
+func (s *Server) BeginShutdown() {
+	s.stopShutdown()
+}
+
+// Shutdown waits for background loops and queued notification dispatches to
+// finish. Call it after http.Server.Shutdown so no new work is started.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.BeginShutdown()
+
+	done := make(chan struct{})
+	go func() {
+		s.workers.Wait()
+		s.notifier.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
 
 type createDecisionRequest struct {
 	Title       string     `json:"title"`
 	Description *string    `json:"description"`
 	ClosesAt    *time.Time `json:"closes_at"`
+	Category    *string    `json:"category"`
+	// AggregateOnly hides individual responses (comments, emoji) from every
+	// API; only stats and the recommendation are ever returned.
+	AggregateOnly bool `json:"aggregate_only"`
+	// Quorum holds back stats and the recommendation until this many
+	// responses are in, so the first few don't anchor everyone else.
+	Quorum int `json:"quorum"`
+	// MaxResponses closes the decision once it has this many responses; 0
+	// (the default) means no limit.
+	MaxResponses int `json:"max_responses"`
+	// NicknamePolicy is optional (the default), required or forbidden:
+	// whether responders may, must or must not sign with a nickname.
+	NicknamePolicy *string `json:"nickname_policy"`
+	// Visibility is public (the default), unlisted (left out of the feed
+	// and insights) or private (also needs AccessCode to read or answer).
+	Visibility *string `json:"visibility"`
+	// AccessCode is made up when a private decision is created without
+	// one.
+	AccessCode *string `json:"access_code"`
+	// Slug asks for a vanity slug instead of one generated from the title.
+	// If it is taken the generated one is used instead, so check the slug
+	// in the response.
+	Slug *string `json:"slug"`
+	// Webhooks are registered along with the decision; more can be added
+	// later with the creator token.
+	Webhooks []webhookRequest `json:"webhooks"`
+	// CreatorEmail, once confirmed, gets the results when the decision
+	// closes. Only accepted when the server can send email.
+	CreatorEmail *string `json:"creator_email"`
+	// TemplateID starts from one of the templates listed at GET /templates:
+	// its title, description and category are used for whichever of those
+	// the request leaves out.
+	TemplateID *string `json:"template_id"`
 }
 
 type createDecisionResponse struct {
-	ID       string `json:"id"`
-	Slug     string `json:"slug"`
-	ShareURL string `json:"share_url"`
+	ID           string               `json:"id"`
+	Slug         string               `json:"slug"`
+	ShareURL     string               `json:"share_url"`
+	CreatorToken string               `json:"creator_token"`
+	Webhooks     []createdWebhookView `json:"webhooks,omitempty"`
+	// CreatorEmailPending means a confirmation link was sent to
+	// creator_email.
+	CreatorEmailPending bool `json:"creator_email_pending,omitempty"`
+	// AccessCode is returned once, for private decisions only.
+	AccessCode string `json:"access_code,omitempty"`
 }
 
 func (s *Server) handleCreateDecision(w nethttp.ResponseWriter, r *nethttp.Request) {
@@ -173,20 +383,103 @@ func (s *Server) handleCreateDecision(w nethttp.ResponseWriter, r *nethttp.Reque
 		return
 	}
 
-	title, err := normalizeRequiredText(req.Title, titleMinLength, titleMaxLength, "title", false)
+	out, err := s.createDecision(r.Context(), req)
 	if err != nil {
-		writeError(w, nethttp.StatusBadRequest, err.Error())
+		s.writeCreateDecisionError(w, err)
 		return
 	}
+	s.auditCreatedDecision(r.Context(), out)
+	writeJSON(w, nethttp.StatusCreated, out)
+}
+
+func (s *Server) writeCreateDecisionError(w nethttp.ResponseWriter, err error) {
+	var invalid invalidInputError
+	switch {
+	case errors.As(err, &invalid):
+		writeError(w, nethttp.StatusBadRequest, invalid.Error())
+	case errors.Is(err, errSlugExhausted):
+		writeProblem(w, nethttp.StatusConflict, errorCodeSlugUnavailable, err.Error())
+	default:
+		s.writeServerError(w, err, "failed to create decision")
+	}
+}
+
+// invalidInputError marks an error caused by the caller's input, so each API
+// can report it as its own kind of bad request.
+type invalidInputError struct {
+	error
+}
+
+var errSlugExhausted = errors.New("failed to generate a unique slug")
+
+// createDecision validates req and stores the new decision. Validation
+// failures are returned as invalidInputError.
+func (s *Server) createDecision(ctx context.Context, req createDecisionRequest) (createDecisionResponse, error) {
+	req, err := applyDecisionTemplate(req)
+	if err != nil {
+		return createDecisionResponse{}, invalidInputError{err}
+	}
+	title, titleFlagged, err := normalizeRequiredText(req.Title, titleMinLength, titleMaxLength, "title", false, s.contentFilter)
+	if err != nil {
+		return createDecisionResponse{}, invalidInputError{err}
+	}
 	description, err := normalizeOptionalText(req.Description, descriptionMaxLength, "description", true)
 	if err != nil {
-		writeError(w, nethttp.StatusBadRequest, err.Error())
-		return
+		return createDecisionResponse{}, invalidInputError{err}
 	}
 	closesAt, err := normalizeClosesAt(req.ClosesAt)
 	if err != nil {
-		writeError(w, nethttp.StatusBadRequest, err.Error())
-		return
+		return createDecisionResponse{}, invalidInputError{err}
+	}
+	category, err := s.normalizeCategory(ctx, req.Category)
+	if err != nil {
+		return createDecisionResponse{}, err
+	}
+	quorum, err := normalizeQuorum(req.Quorum)
+	if err != nil {
+		return createDecisionResponse{}, invalidInputError{err}
+	}
+	maxResponses, err := normalizeMaxResponses(req.MaxResponses)
+	if err != nil {
+		return createDecisionResponse{}, invalidInputError{err}
+	}
+	nicknamePolicy, err := normalizeNicknamePolicy(req.NicknamePolicy)
+	if err != nil {
+		return createDecisionResponse{}, invalidInputError{err}
+	}
+	visibility, err := normalizeVisibility(req.Visibility)
+	if err != nil {
+		return createDecisionResponse{}, invalidInputError{err}
+	}
+	accessCode, err := normalizeAccessCode(req.AccessCode, visibility)
+	if err != nil {
+		return createDecisionResponse{}, invalidInputError{err}
+	}
+	var accessCodeHash *string
+	if accessCode != "" {
+		hash := hashToken(accessCode)
+		accessCodeHash = &hash
+	}
+	hooks, err := normalizeWebhookRequests(req.Webhooks)
+	if err != nil {
+		return createDecisionResponse{}, invalidInputError{err}
+	}
+	creatorEmail, err := normalizeCreatorEmail(req.CreatorEmail)
+	if err != nil {
+		return createDecisionResponse{}, invalidInputError{err}
+	}
+	if creatorEmail != nil && s.mailer == nil {
+		return createDecisionResponse{}, invalidInputError{errors.New("creator_email is not available on this server")}
+	}
+
+	creatorToken, err := newSecretToken()
+	if err != nil {
+		return createDecisionResponse{}, err
+	}
+
+	vanitySlug, err := normalizeVanitySlug(req.Slug)
+	if err != nil {
+		return createDecisionResponse{}, invalidInputError{err}
 	}
 
 	decisionID := uuid.New()
@@ -195,45 +488,68 @@ func (s *Server) handleCreateDecision(w nethttp.ResponseWriter, r *nethttp.Reque
 		baseSlug = "decision"
 	}
 
-	ctx := r.Context()
-	var slug string
+	ctx, cancel := withBudget(ctx, writeQueryBudget)
+	defer cancel()
 	for i := 0; i < slugMaxAttempts; i++ {
-		slug = fmt.Sprintf("%s-%s", baseSlug, randSuffix(5))
-		_, err := s.db.ExecContext(
-			ctx,
-			`INSERT INTO decisions (id, slug, title, description, closes_at) VALUES ($1, $2, $3, $4, $5)`,
-			decisionID,
-			slug,
-			title,
-			description,
-			closesAt,
-		)
+		slug := fmt.Sprintf("%s-%s", baseSlug, randSuffix(5))
+		if i == 0 && vanitySlug != "" {
+			slug = vanitySlug
+		}
+		err := s.decisions.Create(ctx, store.NewDecision{
+			ID:               decisionID,
+			Slug:             slug,
+			Title:            title,
+			Description:      description,
+			ClosesAt:         closesAt,
+			CreatorTokenHash: hashToken(creatorToken),
+			Category:         category,
+			AggregateOnly:    req.AggregateOnly,
+			Quorum:           quorum,
+			Visibility:       visibility,
+			AccessCodeHash:   accessCodeHash,
+			MaxResponses:     maxResponses,
+			NicknamePolicy:   nicknamePolicy,
+		})
 		if err == nil {
-			writeJSON(w, nethttp.StatusCreated, createDecisionResponse{
-				ID:       decisionID.String(),
-				Slug:     slug,
-				ShareURL: "/d/" + slug,
-			})
-			return
+			if titleFlagged {
+				s.flagForReview(ctx, reportTargetDecision, decisionID, "title")
+			}
+			created, err := s.createDecisionWebhooks(ctx, decisionID, hooks)
+			if err != nil {
+				return createDecisionResponse{}, err
+			}
+			if creatorEmail != nil {
+				if err := s.registerCreatorEmail(ctx, decisionID, title, *creatorEmail); err != nil {
+					return createDecisionResponse{}, err
+				}
+			}
+			return createDecisionResponse{
+				ID:                  decisionID.String(),
+				Slug:                slug,
+				ShareURL:            "/d/" + slug,
+				CreatorToken:        creatorToken,
+				Webhooks:            created,
+				CreatorEmailPending: creatorEmail != nil,
+				AccessCode:          accessCode,
+			}, nil
 		}
-
-		if isUniqueViolation(err) {
-			continue
+		if !errors.Is(err, store.ErrConflict) {
+			return createDecisionResponse{}, err
 		}
-
-		writeError(w, nethttp.StatusInternalServerError, "failed to create decision")
-		return
 	}
-
-	writeError(w, nethttp.StatusConflict, "failed to generate a unique slug")
+	return createDecisionResponse{}, errSlugExhausted
 }
 
 type decisionResponsePayload struct {
+	ViewerToken string `json:"viewer_token"`
+	// ViewerID is no longer accepted; kept so old clients get a clear 401.
 	ViewerID   string  `json:"viewer_id"`
 	Rating     int     `json:"rating"`
 	Suggestion int     `json:"suggestion"`
 	Emoji      string  `json:"emoji"`
 	Comment    *string `json:"comment"`
+	Nickname   *string `json:"nickname"`
+	PanelToken *string `json:"panel_token"`
 }
 
 func (s *Server) handleCreateResponse(w nethttp.ResponseWriter, r *nethttp.Request) {
@@ -249,77 +565,158 @@ func (s *Server) handleCreateResponse(w nethttp.ResponseWriter, r *nethttp.Reque
 		return
 	}
 
-	viewerID, err := uuid.Parse(strings.TrimSpace(req.ViewerID))
-	if err != nil {
-		writeError(w, nethttp.StatusBadRequest, "viewer_id must be a valid UUID")
+	viewer, ok := s.requireViewer(w, r, req.ViewerToken, req.ViewerID)
+	if !ok {
 		return
 	}
-	if !s.allowViewerRequest(w, viewerID.String()) {
+	emoji, rating, err := normalizeResponseChoice(req.Suggestion, req.Emoji)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
 		return
 	}
-	if req.Suggestion < 1 || req.Suggestion > 3 {
-		writeError(w, nethttp.StatusBadRequest, "suggestion must be 1 (don't do it), 2 (mixed), or 3 (do it)")
+
+	ctx := r.Context()
+	decision, err := s.decisions.BySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			writeProblem(w, nethttp.StatusNotFound, errorCodeDecisionNotFound, "decision not found")
+			return
+		}
+		s.writeServerError(w, err, "failed to load decision")
 		return
 	}
-	emoji := strings.TrimSpace(req.Emoji)
-	rating, ok := emojiRatings[emoji]
-	if !ok {
-		writeError(w, nethttp.StatusBadRequest, "emoji is invalid")
+	if !requireDecisionAccess(w, r, decision) {
 		return
 	}
 
-	ctx := r.Context()
-	decision, err := s.findDecisionBySlug(ctx, slug)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			writeError(w, nethttp.StatusNotFound, "decision not found")
+	if decision.ClosesAt != nil && time.Now().After(decision.ClosesAt.UTC()) {
+		full, err := s.responseLimitReached(ctx, decision)
+		if err != nil {
+			s.writeServerError(w, err, "failed to load decision")
 			return
 		}
-		writeError(w, nethttp.StatusInternalServerError, "failed to load decision")
+		if full {
+			writeProblem(w, nethttp.StatusConflict, errorCodeResponseLimitReached, "decision has all the responses it allows")
+			return
+		}
+		writeProblem(w, nethttp.StatusConflict, errorCodeDecisionClosed, "decision is closed")
 		return
 	}
 
-	if decision.ClosesAt != nil && time.Now().After(decision.ClosesAt.UTC()) {
-		writeError(w, nethttp.StatusConflict, "decision is closed")
+	comment, commentFlagged, err := normalizeComment(req.Comment, s.contentFilter)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
 		return
 	}
+	if comment != nil {
+		duplicate, err := s.findDuplicateComment(ctx, decision.ID, *comment, nil)
+		if err != nil {
+			s.writeServerError(w, err, "failed to check comment")
+			return
+		}
+		if duplicate {
+			writeProblem(w, nethttp.StatusConflict, errorCodeDuplicateComment, "comment repeats one already left on this decision")
+			return
+		}
+	}
 
-	comment, err := normalizeComment(req.Comment)
+	nickname, err := normalizeNickname(req.Nickname, s.contentFilter)
 	if err != nil {
 		writeError(w, nethttp.StatusBadRequest, err.Error())
 		return
 	}
+	if !checkNicknamePolicy(w, decision, nickname) {
+		return
+	}
 
-	responseID := uuid.New()
-	_, err = s.db.ExecContext(ctx, `
-		INSERT INTO responses (id, decision_id, viewer_id, rating, suggestion, emoji, comment)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-	`,
-		responseID,
-		decision.ID,
-		viewerID,
-		rating,
-		req.Suggestion,
-		emoji,
-		comment,
-	)
+	panelMemberID, err := s.resolvePanelMember(ctx, decision.ID, viewer.ID, req.PanelToken)
 	if err != nil {
-		if isUniqueViolation(err) {
-			writeError(w, nethttp.StatusConflict, "viewer already submitted a response for this decision")
+		if errors.Is(err, errInvalidPanelToken) {
+			writeProblem(w, nethttp.StatusForbidden, errorCodePanelTokenInvalid, err.Error())
+			return
+		}
+		s.writeServerError(w, err, "failed to load advisor panel")
+		return
+	}
+
+	ctx, cancel := withBudget(ctx, writeQueryBudget)
+	defer cancel()
+	response, err := s.responses.Create(ctx, store.NewResponse{
+		ID:            uuid.New(),
+		DecisionID:    decision.ID,
+		ViewerID:      viewer.ID,
+		Rating:        rating,
+		Suggestion:    req.Suggestion,
+		Emoji:         emoji,
+		Comment:       comment,
+		Language:      detectCommentLanguage(comment),
+		PanelMemberID: panelMemberID,
+		Nickname:      nickname,
+		Shadowed:      viewer.Shadowbanned,
+	})
+	if err != nil {
+		if errors.Is(err, store.ErrConflict) {
+			writeProblem(w, nethttp.StatusConflict, errorCodeViewerAlreadyResponded, "viewer already submitted a response for this decision")
+			return
+		}
+		if errors.Is(err, store.ErrLimitReached) {
+			writeProblem(w, nethttp.StatusConflict, errorCodeResponseLimitReached, "decision has all the responses it allows")
 			return
 		}
 		if isUndefinedColumn(err) {
 			writeError(w, nethttp.StatusInternalServerError, "database schema is out of date. Run migrations and restart the server")
 			return
 		}
-		writeError(w, nethttp.StatusInternalServerError, "failed to create response")
+		s.writeServerError(w, err, "failed to create response")
+		return
+	}
+
+	if commentFlagged {
+		s.flagForReview(ctx, reportTargetResponse, response.ID, "comment")
+	}
+	audit := auditFromContext(ctx)
+	audit.setTarget("response", response.ID.String())
+	audit.setAfter(responseCardFromStore(response))
+	// A shadowbanned response changes nothing anyone else sees, so there is
+	// nothing to invalidate or announce.
+	if viewer.Shadowbanned {
+		writeJSON(w, nethttp.StatusCreated, map[string]string{"id": response.ID.String()})
 		return
 	}
+	s.cache.Invalidate(decision.ID)
+	writeJSON(w, nethttp.StatusCreated, map[string]string{"id": response.ID.String()})
+	if response.FilledLimit {
+		// The decision closes now rather than on the close job's next tick.
+		s.jobs.Trigger(jobCloseDecisions)
+	}
+
+	var card *responseCard
+	if !decision.AggregateOnly {
+		c := responseCardFromStore(response)
+		card = &c
+	}
+	s.publishLiveUpdate(ctx, "response_created", decision.ID, card)
+	s.notifyResponseMilestone(ctx, decision)
+	s.notifyWebhooksNewResponse(ctx, decision, response)
+}
 
-	writeJSON(w, nethttp.StatusCreated, map[string]string{"id": responseID.String()})
+// normalizeResponseChoice checks a response's suggestion and emoji and
+// returns the trimmed emoji with the rating it stands for.
+func normalizeResponseChoice(suggestion int, rawEmoji string) (string, int, error) {
+	if suggestion < 1 || suggestion > 3 {
+		return "", 0, errors.New("suggestion must be 1 (don't do it), 2 (mixed), or 3 (do it)")
+	}
+	emoji := strings.TrimSpace(rawEmoji)
+	rating, ok := emojiRatings[emoji]
+	if !ok {
+		return "", 0, errors.New("emoji is invalid")
+	}
+	return emoji, rating, nil
 }
 
 type voteRequest struct {
+	ViewerToken string `json:"viewer_token"`
+	// ViewerID is no longer accepted; kept so old clients get a clear 401.
 	ViewerID string `json:"viewer_id"`
 	Value    int    `json:"value"`
 }
@@ -352,12 +749,8 @@ func (s *Server) handleDecisionVote(w nethttp.ResponseWriter, r *nethttp.Request
 		return
 	}
 
-	viewerID, err := uuid.Parse(strings.TrimSpace(req.ViewerID))
-	if err != nil {
-		writeError(w, nethttp.StatusBadRequest, "viewer_id must be a valid UUID")
-		return
-	}
-	if !s.allowViewerRequest(w, viewerID.String()) {
+	viewer, ok := s.requireViewer(w, r, req.ViewerToken, req.ViewerID)
+	if !ok {
 		return
 	}
 	if req.Value != -1 && req.Value != 1 {
@@ -365,22 +758,31 @@ func (s *Server) handleDecisionVote(w nethttp.ResponseWriter, r *nethttp.Request
 		return
 	}
 
-	decision, err := s.findDecisionBySlug(r.Context(), slug)
+	decision, err := s.decisions.BySlug(r.Context(), slug)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			writeError(w, nethttp.StatusNotFound, "decision not found")
+		if errors.Is(err, store.ErrNotFound) {
+			writeProblem(w, nethttp.StatusNotFound, errorCodeDecisionNotFound, "decision not found")
 			return
 		}
-		writeError(w, nethttp.StatusInternalServerError, "failed to load decision")
+		s.writeServerError(w, err, "failed to load decision")
+		return
+	}
+	if !requireDecisionAccess(w, r, decision) {
 		return
 	}
 
-	summary, status, err := s.toggleDecisionVote(r.Context(), decision.ID, viewerID, req.Value)
+	ctx, cancel := withBudget(r.Context(), writeQueryBudget)
+	defer cancel()
+	summary, err := s.votes.Toggle(ctx, decision.ID, viewer.ID, req.Value, viewer.Shadowbanned)
 	if err != nil {
-		writeError(w, status, err.Error())
+		s.writeServerError(w, err, "failed to record vote")
 		return
 	}
+	auditFromContext(ctx).setAfter(map[string]int{"value": req.Value, "my_vote": summary.MyVote})
 
+	if !viewer.Shadowbanned {
+		s.cache.Invalidate(decision.ID)
+	}
 	writeJSON(w, nethttp.StatusOK, decisionVoteSummaryResponse{
 		DecisionID: decision.ID.String(),
 		Score:      summary.Score,
@@ -388,24 +790,45 @@ func (s *Server) handleDecisionVote(w nethttp.ResponseWriter, r *nethttp.Request
 		Downvotes:  summary.Downvotes,
 		MyVote:     summary.MyVote,
 	})
+
+	if !viewer.Shadowbanned {
+		s.publishLiveUpdate(r.Context(), "vote_changed", decision.ID, nil)
+		s.notifyWebhooksVoteMilestone(r.Context(), decision, summary)
+	}
 }
 
 type decisionEnvelope struct {
+	// State is "collecting_responses" while the decision's quorum has not
+	// been met, with stats, recommendation and responses held back, and
+	// "revealed" otherwise.
+	State              string              `json:"state"`
 	Decision           decisionView        `json:"decision"`
 	Stats              decisionStats       `json:"stats"`
 	Recommendation     recommendationView  `json:"recommendation"`
 	PostVote           decisionVoteSummary `json:"post_vote"`
 	ViewerHasResponded bool                `json:"viewer_has_responded"`
-	Responses          []responseCard      `json:"responses"`
+	// Responses are the first cards of the feed. MoreResponses says there
+	// are others, paged through GET /decisions/{slug}/responses.
+	Responses     []responseCard `json:"responses"`
+	MoreResponses bool           `json:"more_responses"`
 }
 
 type decisionView struct {
-	ID          string     `json:"id"`
-	Slug        string     `json:"slug"`
-	Title       string     `json:"title"`
-	Description *string    `json:"description"`
-	ClosesAt    *time.Time `json:"closes_at"`
-	CreatedAt   time.Time  `json:"created_at"`
+	ID             string     `json:"id"`
+	Slug           string     `json:"slug"`
+	Title          string     `json:"title"`
+	Description    *string    `json:"description"`
+	ClosesAt       *time.Time `json:"closes_at"`
+	ClosedAt       *time.Time `json:"closed_at"`
+	ArchivedAt     *time.Time `json:"archived_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	PanelOnly      bool       `json:"panel_only"`
+	Category       *string    `json:"category"`
+	AggregateOnly  bool       `json:"aggregate_only"`
+	Quorum         int        `json:"quorum"`
+	Visibility     string     `json:"visibility"`
+	MaxResponses   int        `json:"max_responses"`
+	NicknamePolicy string     `json:"nickname_policy"`
 }
 
 type decisionStats struct {
@@ -416,15 +839,28 @@ type decisionStats struct {
 	Categories    voteBuckets  `json:"categories"`
 	EmojiCounts   []emojiCount `json:"emoji_counts"`
 	TopEmoji      string       `json:"top_emoji"`
+	// Timeline and Languages are only filled in on the decision view; live
+	// updates and other stats-only reads leave them out.
+	Timeline  *responseTimeline `json:"timeline,omitempty"`
+	Languages []languageCount   `json:"languages,omitempty"`
 }
 
 type recommendationView struct {
+	// Decision is "yes", "no", or "undecided" while there are too few
+	// responses to call it.
 	Decision         string  `json:"decision"`
 	Score            float64 `json:"score"`
 	SuggestionScore  float64 `json:"suggestion_score"`
 	RatingScore      float64 `json:"rating_score"`
 	CommentSentiment float64 `json:"comment_sentiment"`
 	PostVoteScore    float64 `json:"post_vote_score"`
+	// SampleSize is the number of responses counted; post votes are not.
+	SampleSize int `json:"sample_size"`
+	// Confidence is how sure the recommendation's lean is; see
+	// recommendationConfidence.
+	Confidence float64 `json:"confidence"`
+	// ConfidenceInterval bounds the share of the crowd leaning yes.
+	ConfidenceInterval [2]float64 `json:"confidence_interval"`
 }
 
 type voteBuckets struct {
@@ -439,21 +875,19 @@ type emojiCount struct {
 }
 
 type responseCard struct {
-	ID         string    `json:"id"`
-	Rating     int       `json:"rating"`
-	Suggestion int       `json:"suggestion"`
-	Emoji      string    `json:"emoji"`
-	Comment    *string   `json:"comment"`
-	CreatedAt  time.Time `json:"created_at"`
-}
-
-type decisionRecord struct {
-	ID          uuid.UUID
-	Slug        string
-	Title       string
-	Description *string
-	ClosesAt    *time.Time
-	CreatedAt   time.Time
+	ID          string     `json:"id"`
+	Rating      int        `json:"rating"`
+	Suggestion  int        `json:"suggestion"`
+	Emoji       string     `json:"emoji"`
+	Comment     *string    `json:"comment"`
+	Language    *string    `json:"language"`
+	CreatedAt   time.Time  `json:"created_at"`
+	EditedAt    *time.Time `json:"edited_at"`
+	PanelMember bool       `json:"panel_member"`
+	// Nickname is nil for anonymous responses.
+	Nickname *string `json:"nickname"`
+	// Reactions counts emoji reactions by emoji.
+	Reactions map[string]int `json:"reactions"`
 }
 
 func (s *Server) handleGetDecision(w nethttp.ResponseWriter, r *nethttp.Request) {
@@ -477,85 +911,81 @@ func (s *Server) handleGetDecision(w nethttp.ResponseWriter, r *nethttp.Request)
 		return
 	}
 
-	decision, err := s.findDecisionBySlug(ctx, slug)
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			writeError(w, nethttp.StatusNotFound, "decision not found")
+	// A private decision's 304 would confirm that the caller's copy is
+	// current, so access is checked before the ETag is compared.
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" {
+		current, err := s.currentDecisionRevision(ctx, slug)
+		if err == nil && !requireDecisionAccess(w, r, current) {
 			return
 		}
-		writeError(w, nethttp.StatusInternalServerError, "failed to load decision")
-		return
-	}
-
-	stats, err := s.loadDecisionStats(ctx, decision.ID)
-	if err != nil {
-		if isUndefinedColumn(err) {
-			writeError(w, nethttp.StatusInternalServerError, "database schema is out of date. Run migrations and restart the server")
+		if err == nil && etagMatches(ifNoneMatch, decisionETag(current.Revision, viewerID)) {
+			w.Header().Set("ETag", decisionETag(current.Revision, viewerID))
+			w.Header().Set("Cache-Control", "no-cache")
+			w.WriteHeader(nethttp.StatusNotModified)
 			return
 		}
-		writeError(w, nethttp.StatusInternalServerError, "failed to load decision stats")
-		return
 	}
 
-	recommendation, err := s.loadRecommendation(ctx, decision.ID)
+	snapshot, myVote, viewerHasResponded, err := s.loadDecisionView(ctx, slug, viewerID)
 	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			writeProblem(w, nethttp.StatusNotFound, errorCodeDecisionNotFound, "decision not found")
+			return
+		}
 		if isUndefinedColumn(err) {
 			writeError(w, nethttp.StatusInternalServerError, "database schema is out of date. Run migrations and restart the server")
 			return
 		}
-		writeError(w, nethttp.StatusInternalServerError, "failed to compute decision recommendation")
+		s.writeServerError(w, err, "failed to load decision")
 		return
 	}
 
-	postVote, err := s.queryDecisionVoteSummary(ctx, s.db, decision.ID, viewerID)
-	if err != nil {
-		writeError(w, nethttp.StatusInternalServerError, "failed to load post votes")
+	decision := snapshot.Decision
+	if !requireDecisionAccess(w, r, decision) {
 		return
 	}
-
-	viewerHasResponded, err := s.viewerHasResponded(ctx, decision.ID, viewerID)
-	if err != nil {
-		writeError(w, nethttp.StatusInternalServerError, "failed to load viewer response state")
-		return
-	}
-
-	responses, err := s.loadResponseCards(ctx, decision.ID)
-	if err != nil {
-		if isUndefinedColumn(err) {
-			writeError(w, nethttp.StatusInternalServerError, "database schema is out of date. Run migrations and restart the server")
-			return
-		}
-		writeError(w, nethttp.StatusInternalServerError, "failed to load responses")
-		return
+	state := decisionStateRevealed
+	if withheld, pending := withholdUntilQuorum(snapshot, time.Now()); pending {
+		snapshot, state = withheld, decisionStateCollecting
 	}
+	postVote := snapshot.PostVote
+	postVote.MyVote = myVote
+	responses, moreResponses := envelopeResponses(snapshot)
 
 	out := decisionEnvelope{
-		Decision: decisionView{
-			ID:          decision.ID.String(),
-			Slug:        decision.Slug,
-			Title:       decision.Title,
-			Description: decision.Description,
-			ClosesAt:    decision.ClosesAt,
-			CreatedAt:   decision.CreatedAt,
-		},
-		Stats:              stats,
-		Recommendation:     recommendation,
+		State:              state,
+		Decision:           decisionViewFromStore(decision),
+		Stats:              snapshot.Stats,
+		Recommendation:     snapshot.Recommendation,
 		PostVote:           postVote,
 		ViewerHasResponded: viewerHasResponded,
 		Responses:          responses,
+		MoreResponses:      moreResponses,
 	}
 
+	w.Header().Set("ETag", decisionETag(decision.Revision, viewerID))
+	w.Header().Set("Cache-Control", "no-cache")
 	writeJSON(w, nethttp.StatusOK, out)
 }
 
-func (s *Server) findDecisionBySlug(ctx context.Context, slug string) (decisionRecord, error) {
-	var d decisionRecord
-	err := s.db.QueryRowContext(ctx, `
-		SELECT id, slug, title, description, closes_at, created_at
-		FROM decisions
-		WHERE slug = $1
-	`, slug).Scan(&d.ID, &d.Slug, &d.Title, &d.Description, &d.ClosesAt, &d.CreatedAt)
-	return d, err
+func decisionViewFromStore(decision store.Decision) decisionView {
+	return decisionView{
+		ID:             decision.ID.String(),
+		Slug:           decision.Slug,
+		Title:          decision.Title,
+		Description:    decision.Description,
+		ClosesAt:       decision.ClosesAt,
+		ClosedAt:       decision.ClosedAt,
+		ArchivedAt:     decision.ArchivedAt,
+		CreatedAt:      decision.CreatedAt,
+		PanelOnly:      decision.PanelOnly,
+		Category:       decision.Category,
+		AggregateOnly:  decision.AggregateOnly,
+		Quorum:         decision.Quorum,
+		Visibility:     decision.Visibility,
+		MaxResponses:   decision.MaxResponses,
+		NicknamePolicy: decision.NicknamePolicy,
+	}
 }
 
 func parseViewerIDQuery(r *nethttp.Request) (*uuid.UUID, error) {
@@ -580,140 +1010,130 @@ func parseViewerIDQuery(r *nethttp.Request) (*uuid.UUID, error) {
 }
 
 func (s *Server) loadDecisionStats(ctx context.Context, decisionID uuid.UUID) (decisionStats, error) {
-	var (
-		responseCount int
-		r1            int
-		r2            int
-		r3            int
-		r4            int
-		r5            int
-		s1            int
-		s2            int
-		s3            int
-		avgRating     float64
-	)
-
-	err := s.db.QueryRowContext(ctx, `
-		SELECT
-			COUNT(*)::int AS response_count,
-			COUNT(*) FILTER (WHERE rating = 1)::int AS r1,
-			COUNT(*) FILTER (WHERE rating = 2)::int AS r2,
-			COUNT(*) FILTER (WHERE rating = 3)::int AS r3,
-			COUNT(*) FILTER (WHERE rating = 4)::int AS r4,
-			COUNT(*) FILTER (WHERE rating = 5)::int AS r5,
-			COUNT(*) FILTER (WHERE suggestion = 1)::int AS s1,
-			COUNT(*) FILTER (WHERE suggestion = 2)::int AS s2,
-			COUNT(*) FILTER (WHERE suggestion = 3)::int AS s3,
-			COALESCE(AVG(rating), 0)::float8 AS avg_rating
-		FROM responses
-		WHERE decision_id = $1
-	`, decisionID).Scan(&responseCount, &r1, &r2, &r3, &r4, &r5, &s1, &s2, &s3, &avgRating)
-	if err != nil {
-		return decisionStats{}, err
-	}
+	ctx, cancel := withBudget(ctx, statsQueryBudget)
+	defer cancel()
 
-	emojiCounts := make([]emojiCount, 0, 8)
-	rows, err := s.db.QueryContext(ctx, `
-		SELECT emoji, COUNT(*)::int AS count
-		FROM responses
-		WHERE decision_id = $1
-		GROUP BY emoji
-		ORDER BY count DESC, emoji ASC
-	`, decisionID)
+	row, err := stats.Load(ctx, s.db, decisionID)
 	if err != nil {
 		return decisionStats{}, err
 	}
-	defer rows.Close()
+	return decisionStatsFromRow(row), nil
+}
 
-	for rows.Next() {
-		var item emojiCount
-		if err := rows.Scan(&item.Emoji, &item.Count); err != nil {
-			return decisionStats{}, err
+func decisionStatsFromRow(row stats.Row) decisionStats {
+	emojiCounts := make([]emojiCount, 0, len(row.EmojiCounts))
+	for emoji, count := range row.EmojiCounts {
+		if count <= 0 {
+			continue
 		}
-		emojiCounts = append(emojiCounts, item)
-	}
-	if err := rows.Err(); err != nil {
-		return decisionStats{}, err
+		emojiCounts = append(emojiCounts, emojiCount{Emoji: emoji, Count: count})
 	}
+	sort.Slice(emojiCounts, func(i, j int) bool {
+		if emojiCounts[i].Count != emojiCounts[j].Count {
+			return emojiCounts[i].Count > emojiCounts[j].Count
+		}
+		return emojiCounts[i].Emoji < emojiCounts[j].Emoji
+	})
 
 	topEmoji := ""
 	if len(emojiCounts) > 0 {
 		topEmoji = emojiCounts[0].Emoji
 	}
 
+	avgRating := 0.0
+	if row.ResponseCount > 0 {
+		avgRating = float64(row.RatingSum) / float64(row.ResponseCount)
+	}
+
 	netSentiment := clamp((avgRating-3.0)/2.0, -1.0, 1.0)
-	stats := decisionStats{
-		ResponseCount: responseCount,
-		RatingCounts:  []int{r1, r2, r3, r4, r5},
+	return decisionStats{
+		ResponseCount: row.ResponseCount,
+		RatingCounts:  row.RatingCounts[:],
 		AvgRating:     avgRating,
 		NetSentiment:  netSentiment,
 		Categories: voteBuckets{
-			DoIt:     s3,
-			DontDoIt: s1,
-			Mixed:    s2,
+			DoIt:     row.SuggestionCounts[2],
+			DontDoIt: row.SuggestionCounts[0],
+			Mixed:    row.SuggestionCounts[1],
 		},
 		EmojiCounts: emojiCounts,
 		TopEmoji:    topEmoji,
 	}
-	return stats, nil
+}
+
+type recommendationInput struct {
+	Suggestion int
+	Rating     int
+	Comment    *string
+	Language   *string
+	Panel      bool
 }
 
 func (s *Server) loadRecommendation(ctx context.Context, decisionID uuid.UUID) (recommendationView, error) {
-	var (
-		voteSum   int
-		voteCount int
-	)
-	err := s.db.QueryRowContext(ctx, `
-		SELECT
-			COALESCE(SUM(value), 0)::int AS vote_sum,
-			COUNT(*)::int AS vote_count
-		FROM decision_votes
-		WHERE decision_id = $1
-	`, decisionID).Scan(&voteSum, &voteCount)
+	ctx, cancel := withBudget(ctx, statsQueryBudget)
+	defer cancel()
+
+	in, err := s.responses.RecommendationInputs(ctx, decisionID)
 	if err != nil {
 		return recommendationView{}, err
 	}
 
-	rows, err := s.db.QueryContext(ctx, `
-		SELECT suggestion, rating, comment
-		FROM responses
-		WHERE decision_id = $1
-	`, decisionID)
-	if err != nil {
-		return recommendationView{}, err
+	return computeRecommendation(recommendationInputs(in.Responses), in.VoteSum, in.VoteCount, in.PanelOnly, s.cfg.Recommendation), nil
+}
+
+func recommendationInputs(responses []store.Response) []recommendationInput {
+	inputs := make([]recommendationInput, 0, len(responses))
+	for _, r := range responses {
+		inputs = append(inputs, recommendationInput{
+			Suggestion: r.Suggestion,
+			Rating:     r.Rating,
+			Comment:    r.Comment,
+			Language:   r.Language,
+			Panel:      r.PanelMember,
+		})
 	}
-	defer rows.Close()
+	return inputs
+}
 
+// computeRecommendation blends the response signals with post votes. For
+// panel-only decisions, responses from outside the advisor panel are left
+// out while post votes still count. Copies of a comment add nothing to the
+// comment sentiment beyond the first. With fewer than minResponses counted
+// responses the decision is "undecided", however the score leans.
+func computeRecommendation(inputs []recommendationInput, voteSum, voteCount int, panelOnly bool, rc config.Recommendation) recommendationView {
+	w := rc.Weights
 	var (
 		responseCount         int
 		commentCount          int
 		suggestionScoreTotal  float64
 		ratingScoreTotal      float64
 		commentSentimentTotal float64
+		leaningYes            float64
 	)
+	seenComments := make(map[string]struct{})
 
-	for rows.Next() {
-		var (
-			suggestion int
-			rating     int
-			comment    *string
-		)
-		if err := rows.Scan(&suggestion, &rating, &comment); err != nil {
-			return recommendationView{}, err
+	for _, in := range inputs {
+		if panelOnly && !in.Panel {
+			continue
 		}
-
 		responseCount++
-		suggestionScoreTotal += suggestionToScore(suggestion)
-		ratingScoreTotal += clamp((float64(rating)-3.0)/2.0, -1.0, 1.0)
-
-		if comment != nil {
-			commentSentimentTotal += analyzeCommentSentiment(*comment)
+		suggestion := suggestionToScore(in.Suggestion)
+		rating := clamp((float64(in.Rating)-3.0)/2.0, -1.0, 1.0)
+		suggestionScoreTotal += suggestion
+		ratingScoreTotal += rating
+		leaningYes += responseLean(w.Suggestion*suggestion + w.Rating*rating)
+
+		if in.Comment != nil {
+			if fingerprint := commentFingerprint(*in.Comment); fingerprint != "" {
+				if _, ok := seenComments[fingerprint]; ok {
+					continue
+				}
+				seenComments[fingerprint] = struct{}{}
+			}
+			commentSentimentTotal += analyzeCommentSentiment(*in.Comment, in.Language)
 			commentCount++
 		}
 	}
-	if err := rows.Err(); err != nil {
-		return recommendationView{}, err
-	}
 
 	suggestionScore := 0.0
 	ratingScore := 0.0
@@ -732,27 +1152,38 @@ func (s *Server) loadRecommendation(ctx context.Context, decisionID uuid.UUID) (
 	}
 
 	score := clamp(
-		(suggestionWeight*suggestionScore)+
-			(ratingWeight*ratingScore)+
-			(commentSentimentWeight*commentSentiment)+
-			(postVoteWeight*postVoteScore),
+		(w.Suggestion*suggestionScore)+
+			(w.Rating*ratingScore)+
+			(w.CommentSentiment*commentSentiment)+
+			(w.PostVote*postVoteScore),
 		-1.0,
 		1.0,
 	)
 
-	decision := "no"
-	if score >= recommendationYesThreshold {
-		decision = "yes"
-	}
-
-	return recommendationView{
-		Decision:         decision,
+	rec := recommendationView{
 		Score:            score,
 		SuggestionScore:  suggestionScore,
 		RatingScore:      ratingScore,
 		CommentSentiment: commentSentiment,
 		PostVoteScore:    postVoteScore,
-	}, nil
+		SampleSize:       responseCount,
+	}
+	rec.Decision = rec.lean()
+	if responseCount < rc.MinResponses {
+		rec.Decision = "undecided"
+	}
+	upvotes := float64(voteCount+voteSum) / 2
+	rec.Confidence, rec.ConfidenceInterval = recommendationConfidence(rec.lean(), leaningYes+upvotes, float64(responseCount+voteCount))
+	return rec
+}
+
+// lean is the side the score falls on, whether or not there are enough
+// responses to call it.
+func (rec recommendationView) lean() string {
+	if rec.Score >= recommendationYesThreshold {
+		return "yes"
+	}
+	return "no"
 }
 
 func suggestionToScore(suggestion int) float64 {
@@ -768,191 +1199,60 @@ func suggestionToScore(suggestion int) float64 {
 	}
 }
 
-func analyzeCommentSentiment(comment string) float64 {
-	words := strings.FieldsFunc(strings.ToLower(comment), func(r rune) bool {
-		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '\''
-	})
-	if len(words) == 0 {
-		return 0.0
+// analyzeCommentSentiment scores a comment from -1 to 1 with the wordlist
+// for its language (see package sentiment), detecting the language when it
+// was not stored. Comments that look like spam score at a fraction of their
+// weight.
+func analyzeCommentSentiment(comment string, language *string) float64 {
+	lang := ""
+	if language != nil {
+		lang = *language
 	}
-
-	positiveCount := 0
-	negativeCount := 0
-	for _, word := range words {
-		normalized := strings.ReplaceAll(word, "'", "")
-		if _, ok := positiveSentimentWords[normalized]; ok {
-			positiveCount++
-		}
-		if _, ok := negativeSentimentWords[normalized]; ok {
-			negativeCount++
-		}
-	}
-
-	totalHits := positiveCount + negativeCount
-	if totalHits == 0 {
-		return 0.0
-	}
-
-	return clamp(float64(positiveCount-negativeCount)/float64(totalHits), -1.0, 1.0)
-}
-
-func (s *Server) loadResponseCards(ctx context.Context, decisionID uuid.UUID) ([]responseCard, error) {
-	rows, err := s.db.QueryContext(ctx, `
-		SELECT
-			r.id,
-			r.rating,
-			r.suggestion,
-			r.emoji,
-			r.comment,
-			r.created_at
-		FROM responses r
-		WHERE r.decision_id = $1
-		ORDER BY r.created_at DESC
-	`, decisionID)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	responses := make([]responseCard, 0, 16)
-	for rows.Next() {
-		var item responseCard
-		var id uuid.UUID
-		if err := rows.Scan(
-			&id,
-			&item.Rating,
-			&item.Suggestion,
-			&item.Emoji,
-			&item.Comment,
-			&item.CreatedAt,
-		); err != nil {
-			return nil, err
-		}
-		item.ID = id.String()
-		responses = append(responses, item)
+	score := sentiment.Score(stripCommentMarkdown(comment), lang)
+	if isSpammyComment(comment) {
+		score *= spamCommentWeight
 	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return responses, nil
-}
-
-func (s *Server) viewerHasResponded(ctx context.Context, decisionID uuid.UUID, viewerID *uuid.UUID) (bool, error) {
-	if viewerID == nil {
-		return false, nil
-	}
-
-	var exists bool
-	err := s.db.QueryRowContext(
-		ctx,
-		"SELECT EXISTS(SELECT 1 FROM responses WHERE decision_id = $1 AND viewer_id = $2)",
-		decisionID,
-		*viewerID,
-	).Scan(&exists)
-	return exists, err
+	return score
 }
 
-func (s *Server) toggleDecisionVote(ctx context.Context, decisionID uuid.UUID, viewerID uuid.UUID, value int) (decisionVoteSummary, int, error) {
-	tx, err := s.db.BeginTx(ctx, nil)
-	if err != nil {
-		return decisionVoteSummary{}, nethttp.StatusInternalServerError, errors.New("failed to start vote transaction")
-	}
-	defer tx.Rollback()
-
-	var (
-		voteID        uuid.UUID
-		existingValue int
-	)
-	err = tx.QueryRowContext(ctx, `
-		SELECT id, value
-		FROM decision_votes
-		WHERE decision_id = $1 AND voter_viewer_id = $2
-		FOR UPDATE
-	`, decisionID, viewerID).Scan(&voteID, &existingValue)
-	if err != nil && !errors.Is(err, sql.ErrNoRows) {
-		return decisionVoteSummary{}, nethttp.StatusInternalServerError, errors.New("failed to load vote")
-	}
-
-	switch {
-	case errors.Is(err, sql.ErrNoRows):
-		_, err = tx.ExecContext(ctx, `
-			INSERT INTO decision_votes (id, decision_id, voter_viewer_id, value)
-			VALUES ($1, $2, $3, $4)
-		`, uuid.New(), decisionID, viewerID, value)
-		if err != nil {
-			return decisionVoteSummary{}, nethttp.StatusInternalServerError, errors.New("failed to insert vote")
-		}
-	case existingValue == value:
-		_, err = tx.ExecContext(ctx, `
-			DELETE FROM decision_votes
-			WHERE id = $1
-		`, voteID)
-		if err != nil {
-			return decisionVoteSummary{}, nethttp.StatusInternalServerError, errors.New("failed to remove vote")
-		}
-	default:
-		_, err = tx.ExecContext(ctx, `
-			UPDATE decision_votes
-			SET value = $1, created_at = now()
-			WHERE id = $2
-		`, value, voteID)
-		if err != nil {
-			return decisionVoteSummary{}, nethttp.StatusInternalServerError, errors.New("failed to update vote")
-		}
-	}
-
-	summary, err := s.queryDecisionVoteSummary(ctx, tx, decisionID, &viewerID)
-	if err != nil {
-		return decisionVoteSummary{}, nethttp.StatusInternalServerError, errors.New("failed to summarize vote")
-	}
-	if err := tx.Commit(); err != nil {
-		return decisionVoteSummary{}, nethttp.StatusInternalServerError, errors.New("failed to commit vote")
+// detectCommentLanguage returns the language stored with a new response, or
+// nil when the comment's language cannot be told.
+func detectCommentLanguage(comment *string) *string {
+	if comment == nil {
+		return nil
 	}
-
-	return summary, nethttp.StatusOK, nil
-}
-
-func (s *Server) queryDecisionVoteSummary(ctx context.Context, querier interface {
-	QueryRowContext(context.Context, string, ...any) *sql.Row
-}, decisionID uuid.UUID, viewerID *uuid.UUID) (decisionVoteSummary, error) {
-	var out decisionVoteSummary
-	var viewerParam any
-	if viewerID != nil {
-		viewerParam = *viewerID
-	} else {
-		viewerParam = nil
+	if lang := sentiment.Detect(stripCommentMarkdown(*comment)); lang != "" {
+		return &lang
 	}
-
-	err := querier.QueryRowContext(ctx, `
-		SELECT
-			COALESCE(SUM(value), 0)::int AS score,
-			COALESCE(COUNT(*) FILTER (WHERE value = 1), 0)::int AS upvotes,
-			COALESCE(COUNT(*) FILTER (WHERE value = -1), 0)::int AS downvotes,
-			COALESCE(MAX(CASE WHEN $2::uuid IS NOT NULL AND voter_viewer_id = $2::uuid THEN value END), 0)::int AS my_vote
-		FROM decision_votes
-		WHERE decision_id = $1
-	`, decisionID, viewerParam).Scan(&out.Score, &out.Upvotes, &out.Downvotes, &out.MyVote)
-	return out, err
+	return nil
 }
 
-func normalizeComment(comment *string) (*string, error) {
+// normalizeComment cleans up a comment and screens it with filter. flagged
+// reports a blocklist match that the caller should file for review.
+func normalizeComment(comment *string, filter *contentfilter.Filter) (normalized *string, flagged bool, err error) {
 	if comment == nil {
-		return nil, nil
+		return nil, false, nil
 	}
 
-	trimmed := strings.TrimSpace(normalizeLineBreaks(*comment))
+	trimmed := sanitizeCommentMarkdown(normalizeLineBreaks(*comment))
 	if trimmed == "" {
-		return nil, nil
+		return nil, false, nil
 	}
 	if containsDisallowedControlChars(trimmed, true) {
-		return nil, errors.New("comment contains unsupported control characters")
+		return nil, false, errors.New("comment contains unsupported control characters")
 	}
 	if utf8.RuneCountInString(trimmed) > maxCommentLength {
-		return nil, fmt.Errorf("comment must be %d characters or fewer", maxCommentLength)
+		return nil, false, fmt.Errorf("comment must be %d characters or fewer", maxCommentLength)
+	}
+	if err := checkCommentLinks(trimmed); err != nil {
+		return nil, false, err
+	}
+	trimmed, flagged, err = screenText(filter, trimmed, "comment")
+	if err != nil {
+		return nil, false, err
 	}
 
-	return &trimmed, nil
+	return &trimmed, flagged, nil
 }
 
 func clamp(value, minValue, maxValue float64) float64 {
@@ -989,14 +1289,15 @@ func (s *Server) corsMiddleware(next nethttp.Handler) nethttp.Handler {
 				w.Header().Set("Access-Control-Allow-Origin", origin)
 				w.Header().Add("Vary", "Origin")
 			}
-			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-API-Key")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, If-None-Match, X-API-Key, X-Admin-Key, X-Creator-Token, X-Account-Token, X-Access-Code, X-Subscription-Token, X-Device-Secret, X-Viewer-Token, X-Captcha-Token, X-Request-Id, Last-Event-ID, Authorization")
+			w.Header().Set("Access-Control-Expose-Headers", "ETag, X-Request-Id, Deprecation, Sunset, Link")
 			w.Header().Set("Access-Control-Max-Age", "300")
 		}
 
 		if r.Method == nethttp.MethodOptions {
 			if origin != "" && !s.isOriginAllowed(origin) {
-				writeError(w, nethttp.StatusForbidden, "origin not allowed")
+				writeProblem(w, nethttp.StatusForbidden, errorCodeOriginNotAllowed, "origin not allowed")
 				return
 			}
 			w.WriteHeader(nethttp.StatusNoContent)
@@ -1007,43 +1308,159 @@ func (s *Server) corsMiddleware(next nethttp.Handler) nethttp.Handler {
 	})
 }
 
-func (s *Server) rateLimitMiddleware(next nethttp.Handler) nethttp.Handler {
+// compressionMiddleware gzips JSON and HTML bodies for clients that accept it.
+// Bodies are buffered until compressionMinBytes so small error payloads go
+// out uncompressed. WebSocket upgrades and SSE streams are never wrapped.
+func compressionMiddleware(next nethttp.Handler) nethttp.Handler {
 	return nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
-		if r.Method == nethttp.MethodOptions {
+		if r.Method == nethttp.MethodHead ||
+			r.Header.Get("Upgrade") != "" ||
+			strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
 			next.ServeHTTP(w, r)
 			return
 		}
 
-		clientIP := s.clientIPFromRequest(r)
-		allowed, retryAfter := s.ipLimiter.Allow("ip:"+clientIP, time.Now())
-		if !allowed {
-			writeRateLimitExceeded(w, retryAfter)
+		w.Header().Add("Vary", "Accept-Encoding")
+		if !acceptsGzip(r.Header.Get("Accept-Encoding")) {
+			next.ServeHTTP(w, r)
 			return
 		}
 
-		next.ServeHTTP(w, r)
+		gw := &gzipResponseWriter{ResponseWriter: w, status: nethttp.StatusOK}
+		defer gw.Close()
+		next.ServeHTTP(gw, r)
 	})
 }
 
-func (s *Server) requireWriteAPIKeyMiddleware(next nethttp.Handler) nethttp.Handler {
-	return nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
-		if len(s.writeAPIKeys) == 0 {
-			next.ServeHTTP(w, r)
-			return
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if coding != "gzip" && coding != "*" {
+			continue
+		}
+		q := strings.ReplaceAll(strings.ToLower(params), " ", "")
+		if strings.HasPrefix(q, "q=") {
+			if v, err := strconv.ParseFloat(strings.TrimPrefix(q, "q="), 64); err == nil && v <= 0 {
+				continue
+			}
 		}
+		return true
+	}
+	return false
+}
 
-		apiKey := strings.TrimSpace(r.Header.Get("X-API-Key"))
-		if apiKey == "" {
-			writeError(w, nethttp.StatusUnauthorized, "missing API key")
-			return
+func isCompressibleContentType(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	switch strings.ToLower(strings.TrimSpace(mediaType)) {
+	case "application/json", "text/html", "text/plain":
+		return true
+	default:
+		return false
+	}
+}
+
+var gzipWriterPool = sync.Pool{
+	New: func() any {
+		return gzip.NewWriter(io.Discard)
+	},
+}
+
+type gzipResponseWriter struct {
+	nethttp.ResponseWriter
+	status      int
+	wroteHeader bool
+	decided     bool
+	buf         []byte
+	gz          *gzip.Writer
+}
+
+func (g *gzipResponseWriter) WriteHeader(status int) {
+	if g.wroteHeader {
+		return
+	}
+	g.wroteHeader = true
+	g.status = status
+
+	h := g.Header()
+	if status == nethttp.StatusNoContent ||
+		status == nethttp.StatusNotModified ||
+		h.Get("Content-Encoding") != "" ||
+		!isCompressibleContentType(h.Get("Content-Type")) {
+		g.decided = true
+		g.ResponseWriter.WriteHeader(status)
+	}
+}
+
+func (g *gzipResponseWriter) Write(b []byte) (int, error) {
+	if !g.wroteHeader {
+		g.WriteHeader(nethttp.StatusOK)
+	}
+	if g.decided {
+		if g.gz != nil {
+			return g.gz.Write(b)
 		}
-		if _, ok := s.writeAPIKeys[apiKey]; !ok {
-			writeError(w, nethttp.StatusUnauthorized, "invalid API key")
-			return
+		return g.ResponseWriter.Write(b)
+	}
+
+	g.buf = append(g.buf, b...)
+	if len(g.buf) >= compressionMinBytes {
+		if err := g.startGzip(); err != nil {
+			return 0, err
 		}
+	}
+	return len(b), nil
+}
 
-		next.ServeHTTP(w, r)
-	})
+func (g *gzipResponseWriter) startGzip() error {
+	h := g.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Encoding", "gzip")
+	g.ResponseWriter.WriteHeader(g.status)
+
+	g.gz = gzipWriterPool.Get().(*gzip.Writer)
+	g.gz.Reset(g.ResponseWriter)
+	g.decided = true
+
+	buffered := g.buf
+	g.buf = nil
+	_, err := g.gz.Write(buffered)
+	return err
+}
+
+func (g *gzipResponseWriter) Flush() {
+	if g.wroteHeader && !g.decided {
+		_ = g.startGzip()
+	}
+	if g.gz != nil {
+		_ = g.gz.Flush()
+	}
+	if f, ok := g.ResponseWriter.(nethttp.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Close writes whatever is still buffered. Bodies that never reached the
+// threshold are sent as-is.
+func (g *gzipResponseWriter) Close() {
+	if g.wroteHeader && !g.decided {
+		g.decided = true
+		g.ResponseWriter.WriteHeader(g.status)
+		if len(g.buf) > 0 {
+			_, _ = g.ResponseWriter.Write(g.buf)
+		}
+		g.buf = nil
+	}
+	if g.gz != nil {
+		_ = g.gz.Close()
+		g.gz.Reset(io.Discard)
+		gzipWriterPool.Put(g.gz)
+		g.gz = nil
+	}
+}
+
+func (g *gzipResponseWriter) Unwrap() nethttp.ResponseWriter {
+	return g.ResponseWriter
 }
 
 func (s *Server) allowViewerRequest(w nethttp.ResponseWriter, viewerID string) bool {
@@ -1065,25 +1482,20 @@ func writeRateLimitExceeded(w nethttp.ResponseWriter, retryAfter time.Duration)
 	}
 
 	w.Header().Set("Retry-After", strconv.Itoa(seconds))
-	writeJSON(w, nethttp.StatusTooManyRequests, map[string]any{
-		"error":               "rate limit exceeded",
-		"retry_after_seconds": seconds,
-	})
+	p := newProblem(w, nethttp.StatusTooManyRequests, errorCodeRateLimited, "rate limit exceeded")
+	p.RetryAfterSeconds = &seconds
+	writeProblemBody(w, p)
 }
 
 func newFixedWindowLimiter(limit int, window time.Duration) *fixedWindowLimiter {
 	return &fixedWindowLimiter{
-		window:      window,
-		limit:       limit,
-		buckets:     make(map[string]rateWindowCounter, 2048),
-		lastCleanup: time.Now(),
+		window:  window,
+		limit:   limit,
+		buckets: make(map[string]rateWindowCounter, 2048),
 	}
 }
 
 func (l *fixedWindowLimiter) Allow(key string, now time.Time) (bool, time.Duration) {
-	if l.limit <= 0 {
-		return true, 0
-	}
 	if key == "" {
 		key = "unknown"
 	}
@@ -1091,13 +1503,8 @@ func (l *fixedWindowLimiter) Allow(key string, now time.Time) (bool, time.Durati
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	if now.Sub(l.lastCleanup) >= l.window {
-		for k, bucket := range l.buckets {
-			if !now.Before(bucket.resetAt) {
-				delete(l.buckets, k)
-			}
-		}
-		l.lastCleanup = now
+	if l.limit <= 0 {
+		return true, 0
 	}
 
 	bucket, exists := l.buckets[key]
@@ -1117,6 +1524,35 @@ func (l *fixedWindowLimiter) Allow(key string, now time.Time) (bool, time.Durati
 	return true, 0
 }
 
+// Sweep drops buckets whose window has ended.
+func (l *fixedWindowLimiter) Sweep(now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for k, bucket := range l.buckets {
+		if !now.Before(bucket.resetAt) {
+			delete(l.buckets, k)
+		}
+	}
+}
+
+func (l *fixedWindowLimiter) SetLimit(limit int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = limit
+}
+
+func (l *fixedWindowLimiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.limit
+}
+
+func (l *fixedWindowLimiter) Buckets() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buckets)
+}
+
 func (s *Server) isOriginAllowed(origin string) bool {
 	if s.allowAnyOrigin {
 		return true
@@ -1161,72 +1597,33 @@ func parseIPCandidate(raw string) string {
 	return ip.String()
 }
 
-func loadAllowedOriginsFromEnv() (map[string]struct{}, bool) {
-	raw := strings.TrimSpace(os.Getenv("CORS_ALLOWED_ORIGINS"))
-	if raw == "" {
-		raw = "http://localhost:3000,http://127.0.0.1:3000"
-	}
-
-	if raw == "*" {
-		return nil, true
-	}
-
-	origins := make(map[string]struct{}, 8)
-	for _, part := range strings.Split(raw, ",") {
-		origin := strings.TrimSpace(part)
-		if origin == "" {
-			continue
-		}
-		origins[origin] = struct{}{}
-	}
-	return origins, false
-}
-
-func loadAPIKeysFromEnv(key string) map[string]struct{} {
-	raw := strings.TrimSpace(os.Getenv(key))
-	if raw == "" {
-		return nil
-	}
-
-	keys := make(map[string]struct{}, 8)
-	for _, part := range strings.Split(raw, ",") {
-		parsed := strings.TrimSpace(part)
-		if parsed == "" {
-			continue
-		}
-		keys[parsed] = struct{}{}
-	}
-	return keys
-}
-
-func parseBoolEnv(key string, fallback bool) bool {
-	value := strings.TrimSpace(os.Getenv(key))
-	if value == "" {
-		return fallback
-	}
-	switch strings.ToLower(value) {
-	case "1", "true", "yes", "on":
-		return true
-	case "0", "false", "no", "off":
-		return false
-	default:
-		return fallback
-	}
-}
-
-func normalizeRequiredText(raw string, minLen, maxLen int, field string, allowNewLines bool) (string, error) {
-	normalized := strings.TrimSpace(normalizeLineBreaks(raw))
+// normalizeRequiredText trims and validates raw and screens it with
+// filter. flagged reports a blocklist match that the caller should file for
+// review.
+func normalizeRequiredText(raw string, minLen, maxLen int, field string, allowNewLines bool, filter *contentfilter.Filter) (normalized string, flagged bool, err error) {
+	normalized = strings.TrimSpace(normalizeLineBreaks(raw))
 	if normalized == "" {
-		return "", fmt.Errorf("%s is required", field)
+		return "", false, fmt.Errorf("%s is required", field)
 	}
 	if containsDisallowedControlChars(normalized, allowNewLines) {
-		return "", fmt.Errorf("%s contains unsupported control characters", field)
+		return "", false, fmt.Errorf("%s contains unsupported control characters", field)
 	}
 	length := utf8.RuneCountInString(normalized)
 	if length < minLen || length > maxLen {
-		return "", fmt.Errorf("%s must be between %d and %d characters", field, minLen, maxLen)
+		return "", false, fmt.Errorf("%s must be between %d and %d characters", field, minLen, maxLen)
+	}
+	return screenText(filter, normalized, field)
+}
+
+// screenText runs the content filter over already-validated text. Only
+// flag mode reports flagged; reject mode returns an error and mask mode
+// fixes the text itself.
+func screenText(filter *contentfilter.Filter, text, field string) (string, bool, error) {
+	screened, matched, err := filter.Apply(text)
+	if err != nil {
+		return "", false, fmt.Errorf("%s %w", field, err)
 	}
-	return normalized, nil
+	return screened, matched && filter.Mode == contentfilter.ModeFlag, nil
 }
 
 func normalizeOptionalText(raw *string, maxLen int, field string, allowNewLines bool) (*string, error) {
@@ -1290,6 +1687,31 @@ func normalizeSlugParam(raw string) (string, error) {
 	return slug, nil
 }
 
+// reservedSlugs are routes under /decisions/ that would otherwise shadow a
+// decision with that slug.
+var reservedSlugs = map[string]bool{
+	"random": true,
+}
+
+// normalizeVanitySlug lower-cases a requested slug and checks it against
+// the same rules as slugs in URLs. It returns "" when none was requested.
+func normalizeVanitySlug(raw *string) (string, error) {
+	if raw == nil || strings.TrimSpace(*raw) == "" {
+		return "", nil
+	}
+	slug, err := normalizeSlugParam(strings.ToLower(*raw))
+	if err != nil {
+		return "", err
+	}
+	if len(slug) < vanitySlugMinLength {
+		return "", fmt.Errorf("slug must be at least %d characters", vanitySlugMinLength)
+	}
+	if reservedSlugs[slug] {
+		return "", fmt.Errorf("slug %q is reserved", slug)
+	}
+	return slug, nil
+}
+
 func isValidSlug(slug string) bool {
 	if strings.HasPrefix(slug, "-") || strings.HasSuffix(slug, "-") {
 		return false
@@ -1340,10 +1762,6 @@ func writeJSON(w nethttp.ResponseWriter, status int, payload any) {
 	_ = json.NewEncoder(w).Encode(payload)
 }
 
-func writeError(w nethttp.ResponseWriter, status int, message string) {
-	writeJSON(w, status, map[string]string{"error": message})
-}
-
 func isUniqueViolation(err error) bool {
 	var pgErr *pgconn.PgError
 	return errors.As(err, &pgErr) && pgErr.Code == "23505"
@@ -1359,18 +1777,21 @@ func isUndefinedColumn(err error) bool {
 	return errors.As(err, &pgErr) && pgErr.Code == "42703"
 }
 
+// slugify must only ever produce slugs that isValidSlug accepts, otherwise
+// the decision is created under a URL nobody can load. Non-ASCII letters and
+// digits therefore act as separators rather than being copied through.
 func slugify(input string) string {
 	var b strings.Builder
 	b.Grow(len(input))
 
 	lastHyphen := false
 	for _, r := range strings.ToLower(strings.TrimSpace(input)) {
-		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
 			b.WriteRune(r)
 			lastHyphen = false
 			continue
 		}
-		if unicode.IsSpace(r) || r == '-' || r == '_' {
+		if unicode.IsSpace(r) || unicode.IsLetter(r) || unicode.IsNumber(r) || r == '-' || r == '_' {
 			if !lastHyphen && b.Len() > 0 {
 				b.WriteRune('-')
 				lastHyphen = true
@@ -1378,7 +1799,12 @@ func slugify(input string) string {
 		}
 	}
 
-	return strings.Trim(b.String(), "-")
+	slug := b.String()
+	// Leave room for the "-xxxxx" suffix appended on creation.
+	if maxBase := slugMaxLength - 6; len(slug) > maxBase {
+		slug = slug[:maxBase]
+	}
+	return strings.Trim(slug, "-")
 }
 
 func randSuffix(length int) string {
@@ -0,0 +1,158 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/json"
+	nethttp "net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/config"
+	"ratemylifedecision/internal/store"
+)
+
+// newTestServer returns a server backed by in-memory stores, with just
+// enough set up for the handlers that only go through them.
+func newTestServer(t *testing.T) (*Server, store.Memory) {
+	t.Helper()
+	stores := store.NewMemory()
+	s := &Server{
+		cfg:       config.Config{Recommendation: config.Recommendation{MinResponses: 3}},
+		cache:     newDecisionCache(0),
+		metrics:   newServerMetrics(),
+		decisions: stores.Decisions,
+		responses: stores.Responses,
+		votes:     stores.Votes,
+	}
+	return s, stores
+}
+
+func createTestDecision(t *testing.T, stores store.Memory, d store.NewDecision) store.NewDecision {
+	t.Helper()
+	d.ID = uuid.New()
+	if d.Title == "" {
+		d.Title = "Should I move?"
+	}
+	if d.Visibility == "" {
+		d.Visibility = visibilityPublic
+	}
+	d.NicknamePolicy = "optional"
+	d.CreatorTokenHash = hashToken("creator-token")
+	if err := stores.Decisions.Create(context.Background(), d); err != nil {
+		t.Fatalf("create decision: %v", err)
+	}
+	return d
+}
+
+func TestHandleGetDecision(t *testing.T) {
+	s, stores := newTestServer(t)
+	public := createTestDecision(t, stores, store.NewDecision{Slug: "move-abroad"})
+	accessCodeHash := hashToken("letmein")
+	createTestDecision(t, stores, store.NewDecision{Slug: "secret", Visibility: visibilityPrivate, AccessCodeHash: &accessCodeHash})
+	ctx := context.Background()
+	for _, rating := range []int{4, 5} {
+		if _, err := stores.Responses.Create(ctx, store.NewResponse{
+			ID: uuid.New(), DecisionID: public.ID, ViewerID: uuid.New(),
+			Rating: rating, Suggestion: 1, Emoji: "👍",
+		}); err != nil {
+			t.Fatalf("create response: %v", err)
+		}
+	}
+
+	router := chi.NewRouter()
+	router.Get("/decisions/{slug}", s.handleGetDecision)
+	get := func(path string, header nethttp.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(nethttp.MethodGet, path, nil)
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("unknown slug is 404", func(t *testing.T) {
+		rec := get("/decisions/nope", nil)
+		if rec.Code != nethttp.StatusNotFound {
+			t.Fatalf("status = %d, want 404: %s", rec.Code, rec.Body)
+		}
+		var p problem
+		if err := json.Unmarshal(rec.Body.Bytes(), &p); err != nil || p.Code != errorCodeDecisionNotFound {
+			t.Fatalf("body = %s, want code %s", rec.Body, errorCodeDecisionNotFound)
+		}
+	})
+
+	t.Run("public decision is 200 with its responses", func(t *testing.T) {
+		rec := get("/decisions/move-abroad", nil)
+		if rec.Code != nethttp.StatusOK {
+			t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+		}
+		var out decisionEnvelope
+		if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+			t.Fatal(err)
+		}
+		if out.Decision.Slug != "move-abroad" || out.Stats.ResponseCount != 2 || len(out.Responses) != 2 {
+			t.Fatalf("got slug %q, %d responses counted, %d listed; want move-abroad, 2, 2",
+				out.Decision.Slug, out.Stats.ResponseCount, len(out.Responses))
+		}
+		if rec.Header().Get("ETag") == "" {
+			t.Fatal("no ETag")
+		}
+	})
+
+	t.Run("matching ETag is 304", func(t *testing.T) {
+		etag := get("/decisions/move-abroad", nil).Header().Get("ETag")
+		rec := get("/decisions/move-abroad", nethttp.Header{"If-None-Match": {etag}})
+		if rec.Code != nethttp.StatusNotModified {
+			t.Fatalf("status = %d, want 304", rec.Code)
+		}
+	})
+
+	t.Run("private decision needs its access code", func(t *testing.T) {
+		if rec := get("/decisions/secret", nil); rec.Code != nethttp.StatusUnauthorized {
+			t.Fatalf("without code: status = %d, want 401", rec.Code)
+		}
+		if rec := get("/decisions/secret", nethttp.Header{"If-None-Match": {"*"}}); rec.Code != nethttp.StatusUnauthorized {
+			t.Fatalf("conditional without code: status = %d, want 401", rec.Code)
+		}
+		if rec := get("/decisions/secret", nethttp.Header{"X-Access-Code": {"letmein"}}); rec.Code != nethttp.StatusOK {
+			t.Fatalf("with code: status = %d, want 200: %s", rec.Code, rec.Body)
+		}
+	})
+}
+
+func TestHandleGetDecisionCapsResponses(t *testing.T) {
+	s, stores := newTestServer(t)
+	d := createTestDecision(t, stores, store.NewDecision{Slug: "busy"})
+	ctx := context.Background()
+	total := store.ViewResponseLimit + 1
+	for range total {
+		if _, err := stores.Responses.Create(ctx, store.NewResponse{
+			ID: uuid.New(), DecisionID: d.ID, ViewerID: uuid.New(),
+			Rating: 5, Suggestion: 3, Emoji: "👍",
+		}); err != nil {
+			t.Fatalf("create response: %v", err)
+		}
+	}
+
+	router := chi.NewRouter()
+	router.Get("/decisions/{slug}", s.handleGetDecision)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(nethttp.MethodGet, "/decisions/busy", nil))
+	if rec.Code != nethttp.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", rec.Code, rec.Body)
+	}
+	var out decisionEnvelope
+	if err := json.Unmarshal(rec.Body.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Responses) != store.ViewResponseLimit || !out.MoreResponses {
+		t.Fatalf("listed %d, more_responses %v; want %d, true", len(out.Responses), out.MoreResponses, store.ViewResponseLimit)
+	}
+	if out.Stats.ResponseCount != total || out.Recommendation.SampleSize != total {
+		t.Fatalf("response_count %d, sample_size %d; want both %d", out.Stats.ResponseCount, out.Recommendation.SampleSize, total)
+	}
+}
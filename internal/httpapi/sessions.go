@@ -0,0 +1,118 @@
+package httpapi
+
+import (
+	"context"
+	nethttp "net/http"
+	"strings"
+	"time"
+
+	"ratemylifedecision/internal/auth"
+)
+
+const errorCodeSessionInvalid = "session_invalid"
+
+type sessionKey struct{}
+
+type createSessionTokenRequest struct {
+	ViewerToken string `json:"viewer_token"`
+}
+
+type sessionTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// handleCreateSessionToken trades a viewer token in the body, an account
+// token in X-Account-Token, or both, for a short-lived session JWT that
+// speaks for them as "Authorization: Bearer". A live session may be
+// refreshed by presenting it with neither token, but only until
+// SessionMaxAge after the tokens were last presented; the refreshed token
+// never outlives that.
+func (s *Server) handleCreateSessionToken(w nethttp.ResponseWriter, r *nethttp.Request) {
+	var req createSessionTokenRequest
+	if r.ContentLength != 0 {
+		if err := decodeJSON(w, r, maxResponseBodyBytes, &req); err != nil {
+			writeError(w, nethttp.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	current, hasSession := sessionFromContext(r.Context())
+	accountToken := strings.TrimSpace(r.Header.Get("X-Account-Token"))
+
+	var session auth.Session
+	if strings.TrimSpace(req.ViewerToken) != "" || (accountToken == "" && hasSession && current.ViewerID != nil) {
+		viewer, ok := s.requireViewer(w, r, req.ViewerToken, "")
+		if !ok {
+			return
+		}
+		session.ViewerID = &viewer.ID
+	}
+	if accountToken != "" || (strings.TrimSpace(req.ViewerToken) == "" && hasSession && current.AccountID != nil) {
+		accountID, ok := s.requireAccount(w, r)
+		if !ok {
+			return
+		}
+		session.AccountID = &accountID
+	}
+	if session.ViewerID == nil && session.AccountID == nil {
+		writeProblem(w, nethttp.StatusUnauthorized, errorCodeViewerTokenRequired, "viewer_token or X-Account-Token is required")
+		return
+	}
+
+	ttl := s.cfg.HTTP.SessionTokenTTL
+	if strings.TrimSpace(req.ViewerToken) == "" && accountToken == "" {
+		// A refresh: the session speaks for whatever it already did.
+		session.AuthTime = current.AuthTime
+		remaining := time.Until(current.AuthTime.Add(s.cfg.HTTP.SessionMaxAge))
+		if remaining < time.Second {
+			writeProblem(w, nethttp.StatusUnauthorized, errorCodeSessionInvalid, "session can no longer be refreshed; present the viewer or account token")
+			return
+		}
+		ttl = min(ttl, remaining)
+	}
+
+	token, expiresAt, err := s.authTokens.SignSession(session, ttl)
+	if err != nil {
+		s.writeServerError(w, err, "failed to issue session token")
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, nethttp.StatusOK, sessionTokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(time.Until(expiresAt).Seconds()),
+	})
+}
+
+// sessionMiddleware validates an "Authorization: Bearer" session token and
+// puts the session in the request context for requireViewer and
+// requireAccount. Requests without one pass through untouched; a bad or
+// expired one is refused rather than quietly ignored, so a client learns
+// to refresh it.
+func (s *Server) sessionMiddleware(next nethttp.Handler) nethttp.Handler {
+	return nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		header := strings.TrimSpace(r.Header.Get("Authorization"))
+		if header == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		token, ok := strings.CutPrefix(header, "Bearer ")
+		if !ok {
+			writeProblem(w, nethttp.StatusUnauthorized, errorCodeSessionInvalid, "Authorization must be a Bearer token")
+			return
+		}
+		session, err := s.authTokens.VerifySession(token)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			writeProblem(w, nethttp.StatusUnauthorized, errorCodeSessionInvalid, err.Error())
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), sessionKey{}, session)))
+	})
+}
+
+func sessionFromContext(ctx context.Context) (auth.Session, bool) {
+	session, ok := ctx.Value(sessionKey{}).(auth.Session)
+	return session, ok
+}
@@ -0,0 +1,146 @@
+package httpapi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	nethttp "net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+
+	"ratemylifedecision/internal/store"
+)
+
+const shareLinkCacheAge = 300
+
+// linkPreviewAgents are User-Agent fragments of the crawlers that build
+// link previews. They read the Open Graph tags on the share page instead of
+// following the redirect to the frontend.
+var linkPreviewAgents = []string{
+	"facebookexternalhit", "facebot", "twitterbot", "slackbot", "discordbot", "linkedinbot",
+	"whatsapp", "telegrambot", "skypeuripreview", "redditbot", "embedly", "applebot", "googlebot",
+	"bingbot",
+}
+
+var sharePageTemplate = template.Must(template.New("share").Parse(`<!doctype html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<meta name="description" content="{{.Description}}">
+<meta property="og:type" content="website">
+<meta property="og:site_name" content="RateMyLifeDecision">
+<meta property="og:title" content="{{.Title}}">
+<meta property="og:description" content="{{.Description}}">
+<meta property="og:url" content="{{.URL}}">
+<meta name="twitter:card" content="summary">
+<meta name="twitter:title" content="{{.Title}}">
+<meta name="twitter:description" content="{{.Description}}">
+<meta http-equiv="refresh" content="0; url={{.Target}}">
+<link rel="canonical" href="{{.URL}}">
+</head>
+<body>
+<p><a href="{{.Target}}">{{.Title}}</a></p>
+</body>
+</html>
+`))
+
+type sharePage struct {
+	Title       string
+	Description string
+	// URL is the canonical share link; Target is where this visit goes,
+	// with the access code kept for private decisions.
+	URL    string
+	Target string
+}
+
+// handleShareLink serves the share links handed out at creation, /d/{slug}.
+// People are redirected to the decision on the frontend; link preview
+// crawlers get a page of Open Graph tags describing it instead, and the
+// same page is the redirect's body for clients that don't follow it.
+// Private decisions are previewed as private, with nothing about them.
+func (s *Server) handleShareLink(w nethttp.ResponseWriter, r *nethttp.Request) {
+	slug, err := normalizeSlugParam(chi.URLParam(r, "slug"))
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	page := sharePage{URL: s.shareURL(slug)}
+	page.Target = page.URL
+	if code := strings.TrimSpace(r.URL.Query().Get("access_code")); code != "" {
+		page.Target += "?access_code=" + url.QueryEscape(code)
+	}
+
+	cacheControl := fmt.Sprintf("public, max-age=%d", shareLinkCacheAge)
+	snapshot, _, _, err := s.loadDecisionView(r.Context(), slug, nil)
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		// The frontend has the not-found page; send people there too.
+		page.Title = "Decision not found"
+		page.Description = "This decision doesn't exist or was removed."
+		cacheControl = "no-cache"
+	case err != nil:
+		s.writeServerError(w, err, "failed to load decision")
+		return
+	case snapshot.Decision.Visibility == visibilityPrivate:
+		page.Title = "A private decision"
+		page.Description = "Someone wants a few friends' take on a decision. You'll need the access code to weigh in."
+		cacheControl = "private, no-cache"
+	default:
+		page.Title = snapshot.Decision.Title
+		page.Description = shareLinkSummary(snapshot)
+	}
+
+	var buf bytes.Buffer
+	if err := sharePageTemplate.Execute(&buf, page); err != nil {
+		s.writeServerError(w, err, "failed to render share page")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", cacheControl)
+	w.Header().Add("Vary", "User-Agent")
+	status := nethttp.StatusOK
+	if !isLinkPreviewAgent(r.UserAgent()) {
+		w.Header().Set("Location", page.Target)
+		status = nethttp.StatusFound
+	}
+	w.WriteHeader(status)
+	_, _ = w.Write(buf.Bytes())
+}
+
+// shareLinkSummary is the preview description: the decision's own when it
+// has one, then where the crowd stands, held back until any quorum is met.
+func shareLinkSummary(snapshot decisionSnapshot) string {
+	var parts []string
+	if d := snapshot.Decision.Description; d != nil && *d != "" {
+		parts = append(parts, *d)
+	}
+	snapshot, pending := withholdUntilQuorum(snapshot, time.Now())
+	st := snapshot.Stats
+	switch {
+	case pending:
+		parts = append(parts, fmt.Sprintf("Collecting responses: %d of %d in.", st.ResponseCount, snapshot.Decision.Quorum))
+	case st.ResponseCount == 0:
+		parts = append(parts, "No responses yet. Be the first to weigh in.")
+	default:
+		parts = append(parts, fmt.Sprintf("%d responses so far; the crowd says %s.",
+			st.ResponseCount, verdictText(snapshot.Recommendation.Decision)))
+	}
+	return strings.Join(parts, " ")
+}
+
+func isLinkPreviewAgent(userAgent string) bool {
+	userAgent = strings.ToLower(userAgent)
+	for _, agent := range linkPreviewAgents {
+		if strings.Contains(userAgent, agent) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,323 @@
+package httpapi
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	nethttp "net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"ratemylifedecision/internal/logging"
+	"ratemylifedecision/internal/notify"
+)
+
+const (
+	maxSlackBodyBytes = 64 * 1024
+	// slackSignatureMaxAge rejects replayed requests, as Slack recommends.
+	slackSignatureMaxAge = 5 * time.Minute
+	// slackFollowUpBudget bounds the calls made after Slack has been
+	// acknowledged: response_url follow-ups and chat.unfurl.
+	slackFollowUpBudget = 15 * time.Second
+	slackUsage          = "Usage: `/ratemydecision Should I quit my job? | optional description`"
+)
+
+// slackConfig enables the Slack command and events endpoints. Without a bot
+// token they still work, but shared links are not unfurled.
+type slackConfig struct {
+	signingSecret string
+	botToken      string
+}
+
+func loadSlackConfigFromEnv() slackConfig {
+	return slackConfig{
+		signingSecret: strings.TrimSpace(os.Getenv("SLACK_SIGNING_SECRET")),
+		botToken:      strings.TrimSpace(os.Getenv("SLACK_BOT_TOKEN")),
+	}
+}
+
+type slackMessage struct {
+	ResponseType string       `json:"response_type,omitempty"`
+	Text         string       `json:"text"`
+	Blocks       []slackBlock `json:"blocks,omitempty"`
+}
+
+type slackBlock struct {
+	Type     string       `json:"type"`
+	Text     *slackText   `json:"text,omitempty"`
+	Elements []*slackText `json:"elements,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type slackEnvelope struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Event     struct {
+		Type      string `json:"type"`
+		Channel   string `json:"channel"`
+		MessageTS string `json:"message_ts"`
+		Links     []struct {
+			URL string `json:"url"`
+		} `json:"links"`
+	} `json:"event"`
+}
+
+// requireSlackSignatureMiddleware checks X-Slack-Signature against
+// SLACK_SIGNING_SECRET and leaves the verified body for the handler. The
+// Slack routes do not exist when no secret is configured.
+func (s *Server) requireSlackSignatureMiddleware(next nethttp.Handler) nethttp.Handler {
+	return nethttp.HandlerFunc(func(w nethttp.ResponseWriter, r *nethttp.Request) {
+		if s.slack.signingSecret == "" {
+			writeError(w, nethttp.StatusNotFound, "not found")
+			return
+		}
+
+		body, err := io.ReadAll(nethttp.MaxBytesReader(w, r.Body, maxSlackBodyBytes))
+		if err != nil {
+			writeError(w, nethttp.StatusRequestEntityTooLarge, "request body too large")
+			return
+		}
+		if !verifySlackSignature(s.slack.signingSecret, r.Header, body, time.Now()) {
+			writeError(w, nethttp.StatusUnauthorized, "invalid slack signature")
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, r)
+	})
+}
+
+func verifySlackSignature(secret string, header nethttp.Header, body []byte, now time.Time) bool {
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	if age := now.Sub(time.Unix(sent, 0)); age > slackSignatureMaxAge || age < -slackSignatureMaxAge {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(header.Get("X-Slack-Signature")))
+}
+
+// handleSlackCommand creates a decision from "/ratemydecision title |
+// description" and posts its share link to the channel. The creator token
+// goes to the caller alone, in an ephemeral follow-up. Slack shows whatever
+// this returns, so every outcome is a 200 with a message.
+func (s *Server) handleSlackCommand(w nethttp.ResponseWriter, r *nethttp.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, nethttp.StatusBadRequest, "invalid form body")
+		return
+	}
+
+	// Every answer is a 200, so only a created decision is audited.
+	audit := auditFromContext(r.Context())
+	audit.setActor(auditActorSlack, r.PostForm.Get("team_id")+"/"+r.PostForm.Get("user_id"))
+
+	text := strings.TrimSpace(r.PostForm.Get("text"))
+	if text == "" || strings.EqualFold(text, "help") {
+		audit.discard()
+		writeJSON(w, nethttp.StatusOK, slackMessage{ResponseType: "ephemeral", Text: slackUsage})
+		return
+	}
+	req := createDecisionRequest{Title: text}
+	if title, description, ok := strings.Cut(text, "|"); ok {
+		req.Title = strings.TrimSpace(title)
+		if description = strings.TrimSpace(description); description != "" {
+			req.Description = &description
+		}
+	}
+
+	out, err := s.createDecision(r.Context(), req)
+	if err != nil {
+		audit.discard()
+		var invalid invalidInputError
+		if errors.As(err, &invalid) {
+			writeJSON(w, nethttp.StatusOK, slackMessage{
+				ResponseType: "ephemeral",
+				Text:         "Couldn't create that decision: " + invalid.Error() + "\n" + slackUsage,
+			})
+			return
+		}
+		slog.Error("slack command failed", "error", err)
+		writeJSON(w, nethttp.StatusOK, slackMessage{ResponseType: "ephemeral", Text: "Something went wrong creating the decision. Please try again."})
+		return
+	}
+
+	s.auditCreatedDecision(r.Context(), out)
+
+	shareURL := s.shareURL(out.Slug)
+	userID := r.PostForm.Get("user_id")
+	announce := fmt.Sprintf("<@%s> can't decide: *%s*\nRate it: %s", userID, slackEscape(req.Title), shareURL)
+	if userID == "" {
+		announce = fmt.Sprintf("New decision: *%s*\nRate it: %s", slackEscape(req.Title), shareURL)
+	}
+	writeJSON(w, nethttp.StatusOK, slackMessage{ResponseType: "in_channel", Text: announce})
+
+	responseURL := r.PostForm.Get("response_url")
+	if !isSlackResponseURL(responseURL) {
+		return
+	}
+	s.slackFollowUp(r.Context(), func(ctx context.Context) error {
+		return postSlackResponse(ctx, responseURL, slackMessage{
+			ResponseType: "ephemeral",
+			Text: fmt.Sprintf("Only you can see this. Your creator token is `%s`; keep it to manage the decision. Creator view: %s?creator=1",
+				out.CreatorToken, shareURL),
+		})
+	})
+}
+
+// handleSlackEvents answers the Events API URL check and unfurls decision
+// links shared in Slack with live stats. Slack expects an answer within
+// three seconds, so unfurls are posted after acknowledging.
+func (s *Server) handleSlackEvents(w nethttp.ResponseWriter, r *nethttp.Request) {
+	var env slackEnvelope
+	if err := json.NewDecoder(r.Body).Decode(&env); err != nil {
+		writeError(w, nethttp.StatusBadRequest, "invalid JSON body")
+		return
+	}
+
+	switch env.Type {
+	case "url_verification":
+		writeJSON(w, nethttp.StatusOK, map[string]string{"challenge": env.Challenge})
+		return
+	case "event_callback":
+	default:
+		w.WriteHeader(nethttp.StatusOK)
+		return
+	}
+	w.WriteHeader(nethttp.StatusOK)
+
+	// Slack retries events it thinks timed out; the first attempt already
+	// posted the unfurl.
+	if env.Event.Type != "link_shared" || r.Header.Get("X-Slack-Retry-Num") != "" || s.slack.botToken == "" {
+		return
+	}
+	links := make([]string, 0, len(env.Event.Links))
+	for _, link := range env.Event.Links {
+		links = append(links, link.URL)
+	}
+	channel, ts := env.Event.Channel, env.Event.MessageTS
+	s.slackFollowUp(r.Context(), func(ctx context.Context) error {
+		unfurls := make(map[string]slackMessage, len(links))
+		for _, link := range links {
+			if msg, ok := s.slackUnfurl(ctx, link); ok {
+				unfurls[link] = msg
+			}
+		}
+		if len(unfurls) == 0 {
+			return nil
+		}
+		return notify.CallSlack(ctx, nil, s.slack.botToken, "chat.unfurl", map[string]any{
+			"channel": channel,
+			"ts":      ts,
+			"unfurls": unfurls,
+		})
+	})
+}
+
+func (s *Server) slackUnfurl(ctx context.Context, link string) (slackMessage, bool) {
+	slug, err := s.slugFromShareURL(link)
+	if err != nil {
+		return slackMessage{}, false
+	}
+	snapshot, _, _, err := s.loadDecisionView(ctx, slug, nil)
+	if err != nil {
+		return slackMessage{}, false
+	}
+	// Everyone in the channel would see the unfurl, code or not.
+	if snapshot.Decision.Visibility == visibilityPrivate {
+		return slackMessage{}, false
+	}
+
+	snapshot, pending := withholdUntilQuorum(snapshot, time.Now())
+	st := snapshot.Stats
+	summary := "No responses yet. Be the first to weigh in."
+	switch {
+	case pending:
+		summary = fmt.Sprintf("Collecting responses: %d of %d in. Results show once the quorum is reached.",
+			st.ResponseCount, snapshot.Decision.Quorum)
+	case st.ResponseCount > 0:
+		summary = fmt.Sprintf("%s %d responses · avg rating %.1f/5 · crowd says *%s*",
+			st.TopEmoji, st.ResponseCount, st.AvgRating, verdictText(snapshot.Recommendation.Decision))
+	}
+	title := slackEscape(snapshot.Decision.Title)
+	return slackMessage{
+		Text: title,
+		Blocks: []slackBlock{
+			{Type: "section", Text: &slackText{Type: "mrkdwn", Text: fmt.Sprintf("*<%s|%s>*\n%s", link, title, summary)}},
+			{Type: "context", Elements: []*slackText{{Type: "mrkdwn", Text: fmt.Sprintf("Post score %+d · RateMyLifeDecision", snapshot.PostVote.Score)}}},
+		},
+	}, true
+}
+
+// slackFollowUp runs fn after the response to Slack has been sent, tracked
+// like other background work so shutdown waits for it. fn keeps parent's
+// values, such as the request ID, but not its cancellation.
+func (s *Server) slackFollowUp(parent context.Context, fn func(ctx context.Context) error) {
+	s.workers.Add(1)
+	go func() {
+		defer s.workers.Done()
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(parent), slackFollowUpBudget)
+		defer cancel()
+		if err := fn(ctx); err != nil {
+			slog.WarnContext(ctx, "slack follow-up failed", "error", err)
+		}
+	}()
+}
+
+// isSlackResponseURL keeps follow-ups pointed at Slack even though the
+// request was signed: response_url is the one URL we POST to on its say-so.
+func isSlackResponseURL(raw string) bool {
+	u, err := url.Parse(raw)
+	return err == nil && u.Scheme == "https" && u.Host == "hooks.slack.com"
+}
+
+// slackResponseClient is bounded by the follow-up's context, not a timeout
+// of its own.
+var slackResponseClient = &nethttp.Client{Transport: logging.Transport{}}
+
+func postSlackResponse(ctx context.Context, responseURL string, msg slackMessage) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	req, err := nethttp.NewRequestWithContext(ctx, nethttp.MethodPost, responseURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := slackResponseClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("slack response_url returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// slackEscape escapes the three characters Slack's mrkdwn treats as markup.
+func slackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
@@ -0,0 +1,127 @@
+package httpapi
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Comment spam heuristics. Comments with several links, or that repeat a
+// longer comment already left on the same decision, are refused. Milder
+// signs (one link, a long run of a single character) are let through but
+// count for less in the comment sentiment and sink to the bottom of the
+// response feed.
+const (
+	maxCommentLinks = 1
+	// repeatedRunLength is how many times in a row one character has to
+	// appear before it reads as keyboard mashing rather than emphasis.
+	repeatedRunLength = 8
+	// minDuplicateCommentLength keeps short comments such as "do it!" from
+	// counting as copies of each other.
+	minDuplicateCommentLength = 24
+	spamCommentWeight         = 0.25
+)
+
+var commentLinkPattern = regexp.MustCompile(`(?i)(?:\bhttps?://|\bwww\.)\S+|\b[a-z0-9-]+(?:\.[a-z0-9-]+)*\.(?:com|net|org|io|co|ru|xyz|info|biz|ly|me|app|site|online|shop|top|link|click)\b(?:/\S*)?`)
+
+func countCommentLinks(comment string) int {
+	return len(commentLinkPattern.FindAllStringIndex(comment, -1))
+}
+
+func hasRepeatedRun(comment string) bool {
+	var (
+		prev rune
+		run  int
+	)
+	for _, r := range comment {
+		if r == prev && r != ' ' && r != '\n' {
+			run++
+			if run >= repeatedRunLength {
+				return true
+			}
+			continue
+		}
+		prev, run = r, 1
+	}
+	return false
+}
+
+// isSpammyComment reports the signs that down-weight a comment without
+// refusing it.
+func isSpammyComment(comment string) bool {
+	return countCommentLinks(comment) > 0 || hasRepeatedRun(comment)
+}
+
+// commentFingerprint is what two comments have to share to count as copies:
+// the same text ignoring case and whitespace. It returns "" for comments too
+// short to be treated as copy-pasted. findDuplicateComment normalizes the
+// stored comments the same way in SQL.
+func commentFingerprint(comment string) string {
+	if utf8.RuneCountInString(comment) < minDuplicateCommentLength {
+		return ""
+	}
+	return strings.ToLower(strings.Join(strings.Fields(comment), " "))
+}
+
+func checkCommentLinks(comment string) error {
+	if countCommentLinks(comment) > maxCommentLinks {
+		return fmt.Errorf("comment may contain at most %d link", maxCommentLinks)
+	}
+	return nil
+}
+
+// findDuplicateComment reports whether comment repeats one already left on
+// the decision, hidden and shadowed responses included so a removed copy
+// cannot simply be posted again. exceptViewer, when set, leaves out that
+// viewer's own response, for edits.
+func (s *Server) findDuplicateComment(ctx context.Context, decisionID uuid.UUID, comment string, exceptViewer *uuid.UUID) (bool, error) {
+	fingerprint := commentFingerprint(comment)
+	if fingerprint == "" {
+		return false, nil
+	}
+
+	var duplicate bool
+	err := s.db.QueryRowContext(ctx, `
+		SELECT EXISTS(
+			SELECT 1 FROM responses
+			WHERE decision_id = $1 AND comment IS NOT NULL
+				AND lower(btrim(regexp_replace(comment, '\s+', ' ', 'g'))) = $2
+				AND ($3::uuid IS NULL OR viewer_id <> $3::uuid)
+		)
+	`, decisionID, fingerprint, exceptViewer).Scan(&duplicate)
+	return duplicate, err
+}
+
+// rankResponseFeed moves spammy and copy-pasted comments below the rest,
+// keeping the newest-first order within each group. cards must be newest
+// first; the oldest copy of a comment keeps its place.
+func rankResponseFeed(cards []responseCard) {
+	demoted := make(map[string]bool)
+	seen := make(map[string]struct{})
+	for i := len(cards) - 1; i >= 0; i-- {
+		if cards[i].Comment == nil {
+			continue
+		}
+		comment := *cards[i].Comment
+		if isSpammyComment(comment) {
+			demoted[cards[i].ID] = true
+		}
+		if fingerprint := commentFingerprint(comment); fingerprint != "" {
+			if _, ok := seen[fingerprint]; ok {
+				demoted[cards[i].ID] = true
+			}
+			seen[fingerprint] = struct{}{}
+		}
+	}
+	if len(demoted) == 0 {
+		return
+	}
+	sort.SliceStable(cards, func(i, j int) bool {
+		return !demoted[cards[i].ID] && demoted[cards[j].ID]
+	})
+}
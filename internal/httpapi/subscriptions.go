@@ -0,0 +1,500 @@
+package httpapi
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	nethttp "net/http"
+	"net/mail"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/notify"
+	"ratemylifedecision/internal/safehttp"
+	"ratemylifedecision/internal/store"
+)
+
+const (
+	maxSubscriptionBodyBytes   = 4 * 1024
+	maxSubscriptionChannels    = 4
+	maxSubscriptionAddressSize = 2048
+)
+
+var responseMilestones = map[int]struct{}{
+	5: {}, 10: {}, 25: {}, 50: {}, 100: {}, 250: {}, 500: {}, 1000: {},
+}
+
+type subscriptionChannelPayload struct {
+	Channel string `json:"channel"`
+	Address string `json:"address"`
+	Enabled *bool  `json:"enabled"`
+}
+
+type createSubscriptionRequest struct {
+	Events   []string                     `json:"events"`
+	Channels []subscriptionChannelPayload `json:"channels"`
+}
+
+type updateSubscriptionRequest struct {
+	Events   []string                     `json:"events"`
+	Channels []subscriptionChannelPayload `json:"channels"`
+}
+
+type subscriptionChannelView struct {
+	Channel string `json:"channel"`
+	Address string `json:"address"`
+	Enabled bool   `json:"enabled"`
+}
+
+type subscriptionView struct {
+	ID       string                    `json:"id"`
+	Events   []string                  `json:"events"`
+	Channels []subscriptionChannelView `json:"channels"`
+}
+
+type createSubscriptionResponse struct {
+	subscriptionView
+	Token string `json:"token"`
+}
+
+func (s *Server) handleCreateSubscription(w nethttp.ResponseWriter, r *nethttp.Request) {
+	slug, err := normalizeSlugParam(chi.URLParam(r, "slug"))
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	var req createSubscriptionRequest
+	if err := decodeJSON(w, r, maxSubscriptionBodyBytes, &req); err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	events, err := normalizeNotificationEvents(req.Events)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+	if len(req.Channels) == 0 {
+		writeError(w, nethttp.StatusBadRequest, "at least one channel is required")
+		return
+	}
+	channels, err := s.normalizeSubscriptionChannels(req.Channels)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.ensurePushDevicesExist(r.Context(), channels); err != nil {
+		if errors.Is(err, errPushDeviceUnknown) || errors.Is(err, errPushDeviceInvalidated) {
+			writeError(w, nethttp.StatusBadRequest, err.Error())
+			return
+		}
+		s.writeServerError(w, err, "failed to load push device")
+		return
+	}
+	for _, c := range channels {
+		if c.Address == "" {
+			writeError(w, nethttp.StatusBadRequest, fmt.Sprintf("%s address is required", c.Channel))
+			return
+		}
+	}
+
+	ctx := r.Context()
+	decision, err := s.decisions.BySlug(ctx, slug)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			writeProblem(w, nethttp.StatusNotFound, errorCodeDecisionNotFound, "decision not found")
+			return
+		}
+		s.writeServerError(w, err, "failed to load decision")
+		return
+	}
+	if !requireDecisionAccess(w, r, decision) {
+		return
+	}
+
+	token, err := newSecretToken()
+	if err != nil {
+		s.writeServerError(w, err, "failed to create subscription")
+		return
+	}
+
+	subscriptionID := uuid.New()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.writeServerError(w, err, "failed to create subscription")
+		return
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO notification_subscriptions (id, decision_id, token_hash, events)
+		VALUES ($1, $2, $3, $4)
+	`, subscriptionID, decision.ID, hashToken(token), events); err != nil {
+		s.writeServerError(w, err, "failed to create subscription")
+		return
+	}
+	for _, c := range channels {
+		if err := upsertSubscriptionChannel(ctx, tx, subscriptionID, c); err != nil {
+			s.writeServerError(w, err, "failed to create subscription")
+			return
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		s.writeServerError(w, err, "failed to create subscription")
+		return
+	}
+
+	writeJSON(w, nethttp.StatusCreated, createSubscriptionResponse{
+		subscriptionView: subscriptionView{
+			ID:       subscriptionID.String(),
+			Events:   events,
+			Channels: channels,
+		},
+		Token: token,
+	})
+}
+
+func (s *Server) handleUpdateSubscription(w nethttp.ResponseWriter, r *nethttp.Request) {
+	subscriptionID, ok := s.authorizeSubscription(w, r)
+	if !ok {
+		return
+	}
+
+	var req updateSubscriptionRequest
+	if err := decodeJSON(w, r, maxSubscriptionBodyBytes, &req); err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	var events []string
+	if req.Events != nil {
+		var err error
+		events, err = normalizeNotificationEvents(req.Events)
+		if err != nil {
+			writeError(w, nethttp.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	channels, err := s.normalizeSubscriptionChannels(req.Channels)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+	if err := s.ensurePushDevicesExist(r.Context(), channels); err != nil {
+		if errors.Is(err, errPushDeviceUnknown) || errors.Is(err, errPushDeviceInvalidated) {
+			writeError(w, nethttp.StatusBadRequest, err.Error())
+			return
+		}
+		s.writeServerError(w, err, "failed to load push device")
+		return
+	}
+
+	ctx := r.Context()
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		s.writeServerError(w, err, "failed to update subscription")
+		return
+	}
+	defer tx.Rollback()
+
+	if events != nil {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE notification_subscriptions SET events = $2 WHERE id = $1
+		`, subscriptionID, events); err != nil {
+			s.writeServerError(w, err, "failed to update subscription")
+			return
+		}
+	}
+	for _, c := range channels {
+		if err := upsertSubscriptionChannel(ctx, tx, subscriptionID, c); err != nil {
+			if errors.Is(err, errSubscriptionChannelMissing) {
+				writeError(w, nethttp.StatusBadRequest, fmt.Sprintf("%s address is required", c.Channel))
+				return
+			}
+			s.writeServerError(w, err, "failed to update subscription")
+			return
+		}
+	}
+
+	view, err := loadSubscriptionView(ctx, tx, subscriptionID)
+	if err != nil {
+		s.writeServerError(w, err, "failed to load subscription")
+		return
+	}
+	if err := tx.Commit(); err != nil {
+		s.writeServerError(w, err, "failed to update subscription")
+		return
+	}
+
+	writeJSON(w, nethttp.StatusOK, view)
+}
+
+func (s *Server) handleDeleteSubscription(w nethttp.ResponseWriter, r *nethttp.Request) {
+	subscriptionID, ok := s.authorizeSubscription(w, r)
+	if !ok {
+		return
+	}
+
+	if _, err := s.db.ExecContext(r.Context(), `
+		DELETE FROM notification_subscriptions WHERE id = $1
+	`, subscriptionID); err != nil {
+		s.writeServerError(w, err, "failed to delete subscription")
+		return
+	}
+
+	w.WriteHeader(nethttp.StatusNoContent)
+}
+
+func (s *Server) authorizeSubscription(w nethttp.ResponseWriter, r *nethttp.Request) (uuid.UUID, bool) {
+	subscriptionID, err := uuid.Parse(strings.TrimSpace(chi.URLParam(r, "id")))
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, "subscription id must be a valid UUID")
+		return uuid.Nil, false
+	}
+
+	token := strings.TrimSpace(r.Header.Get("X-Subscription-Token"))
+	if token == "" {
+		writeError(w, nethttp.StatusUnauthorized, "missing subscription token")
+		return uuid.Nil, false
+	}
+
+	var storedHash string
+	err = s.db.QueryRowContext(r.Context(), `
+		SELECT token_hash FROM notification_subscriptions WHERE id = $1
+	`, subscriptionID).Scan(&storedHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			writeError(w, nethttp.StatusNotFound, "subscription not found")
+			return uuid.Nil, false
+		}
+		s.writeServerError(w, err, "failed to load subscription")
+		return uuid.Nil, false
+	}
+	if !tokenMatchesHash(token, storedHash) {
+		writeError(w, nethttp.StatusForbidden, "invalid subscription token")
+		return uuid.Nil, false
+	}
+	return subscriptionID, true
+}
+
+var errSubscriptionChannelMissing = errors.New("subscription channel does not exist")
+
+func upsertSubscriptionChannel(ctx context.Context, tx *sql.Tx, subscriptionID uuid.UUID, c subscriptionChannelView) error {
+	if c.Address == "" {
+		result, err := tx.ExecContext(ctx, `
+			UPDATE notification_subscription_channels
+			SET enabled = $3
+			WHERE subscription_id = $1 AND channel = $2
+		`, subscriptionID, c.Channel, c.Enabled)
+		if err != nil {
+			return err
+		}
+		if n, err := result.RowsAffected(); err == nil && n == 0 {
+			return errSubscriptionChannelMissing
+		}
+		return nil
+	}
+
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO notification_subscription_channels (subscription_id, channel, address, enabled)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (subscription_id, channel) DO UPDATE
+		SET address = EXCLUDED.address, enabled = EXCLUDED.enabled
+	`, subscriptionID, c.Channel, c.Address, c.Enabled)
+	return err
+}
+
+func loadSubscriptionView(ctx context.Context, tx *sql.Tx, subscriptionID uuid.UUID) (subscriptionView, error) {
+	view := subscriptionView{ID: subscriptionID.String()}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT unnest(events) FROM notification_subscriptions WHERE id = $1
+	`, subscriptionID)
+	if err != nil {
+		return subscriptionView{}, err
+	}
+	for rows.Next() {
+		var event string
+		if err := rows.Scan(&event); err != nil {
+			rows.Close()
+			return subscriptionView{}, err
+		}
+		view.Events = append(view.Events, event)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return subscriptionView{}, err
+	}
+
+	rows, err = tx.QueryContext(ctx, `
+		SELECT channel, address, enabled
+		FROM notification_subscription_channels
+		WHERE subscription_id = $1
+		ORDER BY channel
+	`, subscriptionID)
+	if err != nil {
+		return subscriptionView{}, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var c subscriptionChannelView
+		if err := rows.Scan(&c.Channel, &c.Address, &c.Enabled); err != nil {
+			return subscriptionView{}, err
+		}
+		view.Channels = append(view.Channels, c)
+	}
+	return view, rows.Err()
+}
+
+func normalizeNotificationEvents(raw []string) ([]string, error) {
+	if len(raw) == 0 {
+		return nil, errors.New("at least one event is required")
+	}
+	seen := make(map[notify.Kind]struct{}, len(raw))
+	events := make([]string, 0, len(raw))
+	for _, item := range raw {
+		kind, ok := notify.ParseKind(strings.TrimSpace(item))
+		if !ok {
+			return nil, fmt.Errorf("event %q is not supported", item)
+		}
+		if _, dup := seen[kind]; dup {
+			continue
+		}
+		seen[kind] = struct{}{}
+		events = append(events, string(kind))
+	}
+	return events, nil
+}
+
+// normalizeSubscriptionChannels validates each channel address. An update may
+// omit the address to only toggle a channel that already exists.
+func (s *Server) normalizeSubscriptionChannels(raw []subscriptionChannelPayload) ([]subscriptionChannelView, error) {
+	if len(raw) > maxSubscriptionChannels {
+		return nil, fmt.Errorf("at most %d channels are allowed", maxSubscriptionChannels)
+	}
+
+	seen := make(map[notify.Channel]struct{}, len(raw))
+	out := make([]subscriptionChannelView, 0, len(raw))
+	for _, item := range raw {
+		channel, ok := notify.ParseChannel(strings.TrimSpace(item.Channel))
+		if !ok {
+			return nil, fmt.Errorf("channel %q is not supported", item.Channel)
+		}
+		if _, dup := seen[channel]; dup {
+			return nil, fmt.Errorf("channel %s appears more than once", channel)
+		}
+		seen[channel] = struct{}{}
+		if !s.notifier.Enabled(channel) {
+			return nil, fmt.Errorf("channel %s is not available on this server", channel)
+		}
+
+		address := strings.TrimSpace(item.Address)
+		if address != "" {
+			if err := validateChannelAddress(channel, address); err != nil {
+				return nil, err
+			}
+		}
+
+		enabled := true
+		if item.Enabled != nil {
+			enabled = *item.Enabled
+		}
+		out = append(out, subscriptionChannelView{
+			Channel: string(channel),
+			Address: address,
+			Enabled: enabled,
+		})
+	}
+	return out, nil
+}
+
+func validateChannelAddress(channel notify.Channel, address string) error {
+	if utf8.RuneCountInString(address) > maxSubscriptionAddressSize || containsDisallowedControlChars(address, false) {
+		return fmt.Errorf("%s address is invalid", channel)
+	}
+
+	switch channel {
+	case notify.ChannelEmail:
+		parsed, err := mail.ParseAddress(address)
+		if err != nil || parsed.Address != address {
+			return errors.New("email address is invalid")
+		}
+	case notify.ChannelWebhook:
+		if err := safehttp.CheckURL(address); err != nil {
+			return errors.New("webhook address must be a public https URL")
+		}
+	case notify.ChannelSlack:
+		if !isSlackMemberID(address) {
+			return errors.New("slack address must be a Slack member ID")
+		}
+	case notify.ChannelPush:
+		if _, err := uuid.Parse(address); err != nil {
+			return errPushDeviceUnknown
+		}
+	}
+	return nil
+}
+
+func isSlackMemberID(id string) bool {
+	if len(id) < 2 || (id[0] != 'U' && id[0] != 'W') {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'A' && r <= 'Z') && !(r >= '0' && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *Server) notifyResponseMilestone(ctx context.Context, decision store.Decision) {
+	var count int
+	if err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)::int FROM responses WHERE decision_id = $1
+	`, decision.ID).Scan(&count); err != nil {
+		return
+	}
+	if _, ok := responseMilestones[count]; !ok {
+		return
+	}
+
+	s.notifier.DispatchAsync(notify.Event{
+		Kind:          notify.KindMilestone,
+		DecisionID:    decision.ID,
+		DecisionSlug:  decision.Slug,
+		DecisionTitle: decision.Title,
+		ShareURL:      s.shareURL(decision.Slug),
+		DedupeKey:     fmt.Sprintf("responses:%d", count),
+		Data:          map[string]any{"response_count": count},
+	})
+}
+
+func (s *Server) shareURL(slug string) string {
+	return s.frontendBaseURL + "/d/" + slug
+}
+
+func newSecretToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func tokenMatchesHash(token, storedHash string) bool {
+	return subtle.ConstantTimeCompare([]byte(hashToken(token)), []byte(storedHash)) == 1
+}
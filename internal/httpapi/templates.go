@@ -0,0 +1,98 @@
+package httpapi
+
+import (
+	"errors"
+	nethttp "net/http"
+	"strings"
+)
+
+// decisionTemplate is a curated starting point for a new decision. Creating
+// a decision with template_id fills in whatever the request leaves out.
+type decisionTemplate struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Category    string `json:"category"`
+}
+
+// decisionTemplates are listed in this order. IDs are part of the API: keep
+// them stable once shipped.
+var decisionTemplates = []decisionTemplate{
+	{
+		ID:          "quit-job",
+		Title:       "Should I quit my job?",
+		Description: "What I do now, what I'd do instead, and how long my savings would last.",
+		Category:    "career",
+	},
+	{
+		ID:          "adopt-pet",
+		Title:       "Should I adopt a pet?",
+		Description: "Which animal, how much time I have for it, and who looks after it when I travel.",
+		Category:    "lifestyle",
+	},
+	{
+		ID:          "move-city",
+		Title:       "Should I move to a new city?",
+		Description: "Where I live now, where I'd go, and what is pulling me there.",
+		Category:    "housing",
+	},
+	{
+		ID:          "go-back-to-school",
+		Title:       "Should I go back to school?",
+		Description: "The program, what it costs, and what I hope it leads to.",
+		Category:    "education",
+	},
+	{
+		ID:          "buy-or-rent",
+		Title:       "Should I buy a home instead of renting?",
+		Description: "The place, the numbers, and how long I expect to stay.",
+		Category:    "money",
+	},
+	{
+		ID:          "take-the-trip",
+		Title:       "Should I take the trip?",
+		Description: "Where, for how long, and what I'd be putting off to go.",
+		Category:    "travel",
+	},
+	{
+		ID:          "end-relationship",
+		Title:       "Should I end my relationship?",
+		Description: "How long we've been together, what's going well and what isn't.",
+		Category:    "relationships",
+	},
+}
+
+func findDecisionTemplate(id string) (decisionTemplate, bool) {
+	for _, t := range decisionTemplates {
+		if t.ID == id {
+			return t, true
+		}
+	}
+	return decisionTemplate{}, false
+}
+
+// applyDecisionTemplate fills the title, description and category req leaves
+// out from the template it names, if any.
+func applyDecisionTemplate(req createDecisionRequest) (createDecisionRequest, error) {
+	if req.TemplateID == nil || strings.TrimSpace(*req.TemplateID) == "" {
+		return req, nil
+	}
+	template, ok := findDecisionTemplate(strings.TrimSpace(*req.TemplateID))
+	if !ok {
+		return req, errors.New("template_id is not a known template")
+	}
+	if strings.TrimSpace(req.Title) == "" {
+		req.Title = template.Title
+	}
+	if req.Description == nil {
+		req.Description = &template.Description
+	}
+	if req.Category == nil {
+		req.Category = &template.Category
+	}
+	return req, nil
+}
+
+func (s *Server) handleListTemplates(w nethttp.ResponseWriter, r *nethttp.Request) {
+	writeJSON(w, nethttp.StatusOK, map[string]any{"items": decisionTemplates})
+}
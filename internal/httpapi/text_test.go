@@ -0,0 +1,98 @@
+package httpapi
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"testing/quick"
+	"unicode/utf8"
+)
+
+func FuzzNormalizeComment(f *testing.F) {
+	for _, seed := range []string{
+		"",
+		"  plain comment  ",
+		"line one\r\nline two\rline three",
+		"**bold** _and_ [a link](https://example.com)",
+		"<script>alert(1)</script>",
+		"tab\there\x00nul",
+		"émoji 👍🏽 and ünïcode",
+		strings.Repeat("a", maxCommentLength+1),
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		// Comments arrive through encoding/json, which has already replaced
+		// invalid UTF-8 with U+FFFD.
+		if !utf8.ValidString(input) {
+			t.Skip()
+		}
+		once, _, err := normalizeComment(&input, nil)
+		if err != nil || once == nil {
+			return
+		}
+		if !utf8.ValidString(*once) {
+			t.Fatalf("normalizeComment(%q) = %q, not valid UTF-8", input, *once)
+		}
+		if n := utf8.RuneCountInString(*once); n > maxCommentLength {
+			t.Fatalf("normalizeComment(%q) is %d characters, over %d", input, n, maxCommentLength)
+		}
+		twice, _, err := normalizeComment(once, nil)
+		if err != nil {
+			t.Fatalf("normalizeComment(%q) rejected its own output %q: %v", input, *once, err)
+		}
+		if twice == nil || *twice != *once {
+			t.Fatalf("normalizeComment is not idempotent: %q -> %q -> %v", input, *once, twice)
+		}
+	})
+}
+
+func FuzzSlugify(f *testing.F) {
+	for _, seed := range []string{
+		"",
+		"Should I move to Lisbon?",
+		"  --leading and trailing--  ",
+		"under_scores and   spaces",
+		"Ünïcode Straße 東京",
+		strings.Repeat("word ", 60),
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, input string) {
+		slug := slugify(input)
+		if !isValidSlug(slug) {
+			t.Fatalf("slugify(%q) = %q, which isValidSlug rejects", input, slug)
+		}
+		if strings.Contains(slug, "--") {
+			t.Fatalf("slugify(%q) = %q has repeated hyphens", input, slug)
+		}
+		if len(slug)+6 > slugMaxLength {
+			t.Fatalf("slugify(%q) = %q leaves no room for the suffix", input, slug)
+		}
+		if again := slugify(slug); again != slug {
+			t.Fatalf("slugify is not idempotent: %q -> %q -> %q", input, slug, again)
+		}
+	})
+}
+
+func TestCommentSentimentStaysInBounds(t *testing.T) {
+	languages := []string{"", "en", "es", "fr", "de", "xx"}
+	inBounds := func(comment string, pick uint8) bool {
+		language := languages[int(pick)%len(languages)]
+		score := analyzeCommentSentiment(comment, &language)
+		return !math.IsNaN(score) && score >= -1 && score <= 1
+	}
+	if err := quick.Check(inBounds, &quick.Config{MaxCount: 2000}); err != nil {
+		t.Fatal(err)
+	}
+	for _, comment := range []string{
+		"great great great 👍👍👍",
+		"terrible awful 👎👎 not good not good",
+		"very very very extremely good",
+		"buy now http://spam.example http://spam.example",
+	} {
+		if !inBounds(comment, 0) {
+			t.Errorf("analyzeCommentSentiment(%q) is out of [-1, 1]", comment)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package httpapi
+
+import (
+	"time"
+
+	"ratemylifedecision/internal/store"
+)
+
+// hourlyTimelineSpan is the longest span of responses charted by the hour;
+// anything longer is bucketed by day.
+const hourlyTimelineSpan = 72 * time.Hour
+
+// responseTimeline is how responses arrived and how the average rating moved
+// over time, for the "how opinion evolved" chart. Buckets run from the first
+// response to the latest, in UTC, with empty buckets included so the chart
+// has no gaps.
+type responseTimeline struct {
+	Bucket string          `json:"bucket"`
+	Points []timelinePoint `json:"points"`
+}
+
+type timelinePoint struct {
+	Start     time.Time `json:"start"`
+	Responses int       `json:"responses"`
+	// AvgRating is the average of the bucket's own responses, 0 when it
+	// has none.
+	AvgRating float64 `json:"avg_rating"`
+	// The cumulative fields are the totals as they stood at the end of the
+	// bucket.
+	CumulativeResponses int     `json:"cumulative_responses"`
+	CumulativeAvgRating float64 `json:"cumulative_avg_rating"`
+}
+
+// buildResponseTimeline buckets responses the way a date_trunc aggregation
+// would. It works from the responses already loaded for the decision view,
+// so the chart costs no extra query; they arrive newest first.
+func buildResponseTimeline(responses []store.Response) *responseTimeline {
+	if len(responses) == 0 {
+		return nil
+	}
+	first, last := responses[0].CreatedAt, responses[0].CreatedAt
+	for _, r := range responses[1:] {
+		if r.CreatedAt.Before(first) {
+			first = r.CreatedAt
+		}
+		if r.CreatedAt.After(last) {
+			last = r.CreatedAt
+		}
+	}
+
+	timeline := &responseTimeline{Bucket: "hour"}
+	truncate := func(t time.Time) time.Time { return t.UTC().Truncate(time.Hour) }
+	next := func(t time.Time) time.Time { return t.Add(time.Hour) }
+	if last.Sub(first) > hourlyTimelineSpan {
+		timeline.Bucket = "day"
+		truncate = func(t time.Time) time.Time {
+			t = t.UTC()
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		}
+		next = func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+	}
+
+	type bucket struct{ count, ratingSum int }
+	buckets := make(map[time.Time]bucket)
+	for _, r := range responses {
+		start := truncate(r.CreatedAt)
+		b := buckets[start]
+		b.count++
+		b.ratingSum += r.Rating
+		buckets[start] = b
+	}
+
+	var totalCount, totalSum int
+	for start, end := truncate(first), truncate(last); !start.After(end); start = next(start) {
+		b := buckets[start]
+		point := timelinePoint{Start: start, Responses: b.count}
+		if b.count > 0 {
+			point.AvgRating = float64(b.ratingSum) / float64(b.count)
+		}
+		totalCount += b.count
+		totalSum += b.ratingSum
+		point.CumulativeResponses = totalCount
+		if totalCount > 0 {
+			point.CumulativeAvgRating = float64(totalSum) / float64(totalCount)
+		}
+		timeline.Points = append(timeline.Points, point)
+	}
+	return timeline
+}
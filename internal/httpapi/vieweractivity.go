@@ -0,0 +1,179 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	nethttp "net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/store"
+)
+
+const (
+	defaultViewerActivityLimit = 20
+	maxViewerActivityLimit     = 100
+)
+
+type viewerActivityItem struct {
+	Slug     string  `json:"slug"`
+	Title    string  `json:"title"`
+	Category *string `json:"category"`
+	// Status is "open" or "closed".
+	Status string `json:"status"`
+	// LastActivityAt is when the viewer last responded, edited their
+	// response or voted on the decision.
+	LastActivityAt time.Time `json:"last_activity_at"`
+	// MyResponse is nil if the viewer only voted.
+	MyResponse *viewerActivityResponse `json:"my_response"`
+	MyVote     int                     `json:"my_vote"`
+	// State and Recommendation are as on the decision itself:
+	// Recommendation is null while the decision is collecting responses.
+	State          string              `json:"state"`
+	Recommendation *recommendationView `json:"recommendation"`
+}
+
+type viewerActivityResponse struct {
+	Rating     int        `json:"rating"`
+	Suggestion int        `json:"suggestion"`
+	Emoji      string     `json:"emoji"`
+	Comment    *string    `json:"comment"`
+	Nickname   *string    `json:"nickname"`
+	CreatedAt  time.Time  `json:"created_at"`
+	EditedAt   *time.Time `json:"edited_at"`
+}
+
+type viewerActivityPage struct {
+	Items []viewerActivityItem `json:"items"`
+	// NextBefore pages back through older activity as ?before=; null on
+	// the last page.
+	NextBefore *time.Time `json:"next_before"`
+}
+
+// handleViewerActivity lists the decisions a viewer responded to or voted
+// on, most recent activity first, with what they said and where the crowd
+// stands now. Like erasing a viewer's data it takes the viewer's token in
+// X-Viewer-Token. The viewer's own response is listed even if it was
+// hidden by moderation, since it is theirs.
+func (s *Server) handleViewerActivity(w nethttp.ResponseWriter, r *nethttp.Request) {
+	limit, err := parseLimitParam(r, defaultViewerActivityLimit, maxViewerActivityLimit)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+	var before *time.Time
+	if raw := r.URL.Query().Get("before"); raw != "" {
+		t, err := time.Parse(time.RFC3339Nano, raw)
+		if err != nil {
+			writeError(w, nethttp.StatusBadRequest, "before must be an RFC 3339 timestamp")
+			return
+		}
+		before = &t
+	}
+	viewerID, ok := s.requireViewerOwner(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	items, err := s.queryViewerActivity(ctx, viewerID, before, limit+1)
+	if err != nil {
+		s.writeServerError(w, err, "failed to load activity")
+		return
+	}
+	page := viewerActivityPage{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		next := page.Items[limit-1].LastActivityAt
+		page.NextBefore = &next
+	}
+
+	now := time.Now()
+	visible := page.Items[:0]
+	for _, it := range page.Items {
+		snapshot, _, _, err := s.loadDecisionView(ctx, it.Slug, nil)
+		if err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				// Hidden since the list was read.
+				continue
+			}
+			s.writeServerError(w, err, "failed to load activity")
+			return
+		}
+		it.State = decisionStateRevealed
+		if _, pending := withholdUntilQuorum(snapshot, now); pending {
+			it.State = decisionStateCollecting
+		} else {
+			recommendation := snapshot.Recommendation
+			it.Recommendation = &recommendation
+		}
+		visible = append(visible, it)
+	}
+	page.Items = visible
+	writeJSON(w, nethttp.StatusOK, page)
+}
+
+func (s *Server) queryViewerActivity(ctx context.Context, viewerID uuid.UUID, before *time.Time, limit int) ([]viewerActivityItem, error) {
+	ctx, cancel := withBudget(ctx, statsQueryBudget)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, `
+		WITH activity AS (
+			SELECT decision_id, MAX(at) AS at
+			FROM (
+				SELECT decision_id, COALESCE(edited_at, created_at) AS at FROM responses WHERE viewer_id = $1
+				UNION ALL
+				SELECT decision_id, created_at FROM decision_votes WHERE voter_viewer_id = $1
+			) a
+			GROUP BY decision_id
+		)
+		SELECT
+			d.slug, d.title, d.category,
+			d.closed_at IS NOT NULL OR (d.closes_at IS NOT NULL AND d.closes_at <= now()),
+			act.at,
+			r.rating, r.suggestion, r.emoji, r.comment, r.nickname, r.created_at, r.edited_at,
+			COALESCE(v.value, 0)
+		FROM activity act
+		JOIN decisions d ON d.id = act.decision_id
+		LEFT JOIN responses r ON r.decision_id = d.id AND r.viewer_id = $1
+		LEFT JOIN decision_votes v ON v.decision_id = d.id AND v.voter_viewer_id = $1
+		WHERE d.hidden_at IS NULL AND ($2::timestamptz IS NULL OR act.at < $2)
+		ORDER BY act.at DESC
+		LIMIT $3
+	`, viewerID, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := make([]viewerActivityItem, 0, limit)
+	for rows.Next() {
+		var (
+			it         viewerActivityItem
+			closed     bool
+			rating     *int
+			suggestion *int
+			emoji      *string
+			resp       viewerActivityResponse
+			createdAt  *time.Time
+		)
+		if err := rows.Scan(
+			&it.Slug, &it.Title, &it.Category, &closed, &it.LastActivityAt,
+			&rating, &suggestion, &emoji, &resp.Comment, &resp.Nickname, &createdAt, &resp.EditedAt,
+			&it.MyVote,
+		); err != nil {
+			return nil, err
+		}
+		it.Status = "open"
+		if closed {
+			it.Status = "closed"
+		}
+		if rating != nil {
+			resp.Rating, resp.Suggestion, resp.Emoji, resp.CreatedAt = *rating, *suggestion, *emoji, *createdAt
+			it.MyResponse = &resp
+		}
+		items = append(items, it)
+	}
+	return items, rows.Err()
+}
@@ -0,0 +1,239 @@
+package httpapi
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	nethttp "net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/database"
+	"ratemylifedecision/internal/projections"
+	"ratemylifedecision/internal/stats"
+)
+
+type viewerDataDeletion struct {
+	ResponsesDeleted  int `json:"responses_deleted"`
+	VotesDeleted      int `json:"votes_deleted"`
+	DecisionsAffected int `json:"decisions_affected"`
+}
+
+// handleDeleteViewerData erases everything tied to a viewer: responses,
+// votes, reactions, reports they filed, push devices, advisor panel seats,
+// the link to an account and the IPs and payloads of their audit log rows.
+// The caller proves ownership with the viewer's token in X-Viewer-Token.
+// Banned viewers may erase their data too; the ban record itself is kept so
+// the ban still applies.
+func (s *Server) handleDeleteViewerData(w nethttp.ResponseWriter, r *nethttp.Request) {
+	viewerID, ok := s.requireViewerOwner(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	out, decisionIDs, err := s.deleteViewerData(ctx, viewerID)
+	if err != nil {
+		s.writeServerError(w, err, "failed to delete viewer data")
+		return
+	}
+
+	for _, id := range decisionIDs {
+		s.cache.Invalidate(id)
+	}
+	writeJSON(w, nethttp.StatusOK, out)
+
+	for _, id := range decisionIDs {
+		s.publishLiveUpdate(ctx, "viewer_data_deleted", id, nil)
+	}
+}
+
+// requireViewerOwner checks that the X-Viewer-Token header, or failing that
+// the session, belongs to the {viewer_id} in the path, for routes about a
+// viewer rather than by one.
+func (s *Server) requireViewerOwner(w nethttp.ResponseWriter, r *nethttp.Request) (uuid.UUID, bool) {
+	viewerID, err := uuid.Parse(strings.TrimSpace(chi.URLParam(r, "viewer_id")))
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, "viewer_id must be a valid UUID")
+		return uuid.Nil, false
+	}
+
+	var tokenViewerID uuid.UUID
+	if token := strings.TrimSpace(r.Header.Get("X-Viewer-Token")); token != "" {
+		tokenViewerID, err = s.viewerTokens.Verify(token)
+		if err != nil {
+			writeProblem(w, nethttp.StatusUnauthorized, errorCodeViewerTokenRequired, err.Error())
+			return uuid.Nil, false
+		}
+	} else if session, ok := sessionFromContext(r.Context()); ok && session.ViewerID != nil {
+		tokenViewerID = *session.ViewerID
+	} else {
+		writeProblem(w, nethttp.StatusUnauthorized, errorCodeViewerTokenRequired, "missing viewer token")
+		return uuid.Nil, false
+	}
+	if tokenViewerID != viewerID {
+		writeError(w, nethttp.StatusForbidden, "viewer token does not belong to this viewer")
+		return uuid.Nil, false
+	}
+	if !s.allowViewerRequest(w, viewerID.String()) {
+		return uuid.Nil, false
+	}
+	auditFromContext(r.Context()).setActor(auditActorViewer, viewerID.String())
+	return viewerID, true
+}
+
+// deleteViewerData removes the viewer's rows in one transaction and brings
+// decision_stats and the projections back in line for every decision they
+// had responded to or voted on. Shadowed rows were never counted, so only
+// visible ones are taken back out of the projections.
+func (s *Server) deleteViewerData(ctx context.Context, viewerID uuid.UUID) (viewerDataDeletion, []uuid.UUID, error) {
+	ctx, cancel := withBudget(ctx, writeQueryBudget)
+	defer cancel()
+
+	var (
+		out         viewerDataDeletion
+		decisionIDs []uuid.UUID
+	)
+	err := database.RetryTx(ctx, s.db, func(tx *sql.Tx) error {
+		out = viewerDataDeletion{}
+		decisionIDs = decisionIDs[:0]
+		affected := make(map[uuid.UUID]struct{})
+		votesChanged := make(map[uuid.UUID]struct{})
+
+		if _, err := tx.ExecContext(ctx, scrubViewerAuditLog, viewerID); err != nil {
+			return err
+		}
+		rows, err := tx.QueryContext(ctx, `
+			WITH gone AS (
+				DELETE FROM responses WHERE viewer_id = $1
+				RETURNING id, decision_id, rating, shadowed
+			), unreported AS (
+				DELETE FROM reports
+				WHERE target_kind = 'response' AND target_id IN (SELECT id FROM gone)
+			)
+			SELECT decision_id, rating, shadowed FROM gone
+		`, viewerID)
+		if err != nil {
+			return err
+		}
+		type deletedResponse struct {
+			decisionID uuid.UUID
+			rating     int
+			shadowed   bool
+		}
+		var responses []deletedResponse
+		for rows.Next() {
+			var d deletedResponse
+			if err := rows.Scan(&d.decisionID, &d.rating, &d.shadowed); err != nil {
+				rows.Close()
+				return err
+			}
+			responses = append(responses, d)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		for _, d := range responses {
+			affected[d.decisionID] = struct{}{}
+			if d.shadowed {
+				continue
+			}
+			if err := projections.Append(ctx, tx, d.decisionID, projections.KindResponseDeleted, projections.ResponseDeleted{
+				Rating: d.rating,
+			}); err != nil {
+				return fmt.Errorf("record response deletion: %w", err)
+			}
+		}
+		out.ResponsesDeleted = len(responses)
+
+		rows, err = tx.QueryContext(ctx, `
+			DELETE FROM decision_votes WHERE voter_viewer_id = $1
+			RETURNING decision_id, shadowed
+		`, viewerID)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var (
+				decisionID uuid.UUID
+				shadowed   bool
+			)
+			if err := rows.Scan(&decisionID, &shadowed); err != nil {
+				rows.Close()
+				return err
+			}
+			affected[decisionID] = struct{}{}
+			if !shadowed {
+				votesChanged[decisionID] = struct{}{}
+			}
+			out.VotesDeleted++
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		// Reactions left on other people's responses change those
+		// decisions' cards.
+		rows, err = tx.QueryContext(ctx, `
+			DELETE FROM response_reactions rr
+			USING responses r
+			WHERE rr.viewer_id = $1 AND r.id = rr.response_id
+			RETURNING r.decision_id
+		`, viewerID)
+		if err != nil {
+			return err
+		}
+		for rows.Next() {
+			var decisionID uuid.UUID
+			if err := rows.Scan(&decisionID); err != nil {
+				rows.Close()
+				return err
+			}
+			affected[decisionID] = struct{}{}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, stmt := range []string{
+			`DELETE FROM votes WHERE voter_viewer_id = $1`,
+			`DELETE FROM reports WHERE reporter_viewer_id = $1`,
+			`DELETE FROM notification_subscription_channels
+			WHERE channel = 'push' AND address IN (SELECT id::text FROM push_devices WHERE viewer_id = $1)`,
+			`DELETE FROM push_devices WHERE viewer_id = $1`,
+			`DELETE FROM decision_panel_members WHERE kind = 'viewer' AND value = $1::text`,
+			`DELETE FROM decision_retrospectives WHERE viewer_id = $1`,
+			`DELETE FROM account_viewers WHERE viewer_id = $1`,
+		} {
+			if _, err := tx.ExecContext(ctx, stmt, viewerID); err != nil {
+				return err
+			}
+		}
+
+		for id := range affected {
+			decisionIDs = append(decisionIDs, id)
+			if _, err := stats.Repair(ctx, tx, &id); err != nil {
+				return fmt.Errorf("repair decision stats: %w", err)
+			}
+		}
+		for id := range votesChanged {
+			var change projections.VoteChanged
+			if err := tx.QueryRowContext(ctx, `
+				SELECT vote_sum, vote_count FROM decision_stats WHERE decision_id = $1
+			`, id).Scan(&change.VoteSum, &change.VoteCount); err != nil {
+				return err
+			}
+			if err := projections.Append(ctx, tx, id, projections.KindVoteChanged, change); err != nil {
+				return fmt.Errorf("record vote deletion: %w", err)
+			}
+		}
+		out.DecisionsAffected = len(decisionIDs)
+		return nil
+	})
+	return out, decisionIDs, err
+}
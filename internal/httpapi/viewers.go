@@ -0,0 +1,150 @@
+package httpapi
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	nethttp "net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const (
+	errorCodeViewerTokenRequired = "viewer_token_required"
+	errorCodeViewerBanned        = "viewer_banned"
+)
+
+var errInvalidViewerToken = errors.New("viewer_token is invalid")
+
+// viewerTokenSigner issues and checks viewer tokens of the form
+// "<viewer uuid>.<base64url HMAC-SHA256 of the uuid>". They are stateless:
+// any instance holding the same key can verify them, and rotating the key
+// invalidates every issued token.
+type viewerTokenSigner struct {
+	key []byte
+}
+
+var errNoViewerTokenSecret = errors.New("VIEWER_TOKEN_SECRET is not set")
+
+// newViewerTokenSigner signs with secret, VIEWER_TOKEN_SECRET. Without it,
+// and only if allowRandomKey is set, a random per-process key is used,
+// which only works for a single instance and logs every viewer out on
+// restart; that is fine for development and nowhere else.
+func newViewerTokenSigner(secret string, allowRandomKey bool) (*viewerTokenSigner, error) {
+	if secret != "" {
+		return &viewerTokenSigner{key: []byte(secret)}, nil
+	}
+	if !allowRandomKey {
+		return nil, errNoViewerTokenSecret
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	slog.Warn("VIEWER_TOKEN_SECRET is not set; viewer tokens will not survive a restart or work across instances")
+	return &viewerTokenSigner{key: key}, nil
+}
+
+func (v *viewerTokenSigner) Sign(viewerID uuid.UUID) string {
+	return viewerID.String() + "." + base64.RawURLEncoding.EncodeToString(v.mac(viewerID))
+}
+
+func (v *viewerTokenSigner) Verify(token string) (uuid.UUID, error) {
+	idText, sigText, ok := strings.Cut(strings.TrimSpace(token), ".")
+	if !ok {
+		return uuid.Nil, errInvalidViewerToken
+	}
+	viewerID, err := uuid.Parse(idText)
+	if err != nil {
+		return uuid.Nil, errInvalidViewerToken
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(sigText)
+	if err != nil || !hmac.Equal(sig, v.mac(viewerID)) {
+		return uuid.Nil, errInvalidViewerToken
+	}
+	return viewerID, nil
+}
+
+func (v *viewerTokenSigner) mac(viewerID uuid.UUID) []byte {
+	h := hmac.New(sha256.New, v.key)
+	h.Write([]byte("viewer:"))
+	h.Write(viewerID[:])
+	return h.Sum(nil)
+}
+
+type createViewerResponse struct {
+	ViewerID    string `json:"viewer_id"`
+	ViewerToken string `json:"viewer_token"`
+}
+
+// handleCreateViewer mints a new viewer identity. The token is what
+// viewer-scoped writes must present; viewer_id is only for reads.
+func (s *Server) handleCreateViewer(w nethttp.ResponseWriter, _ *nethttp.Request) {
+	viewerID := uuid.New()
+	writeJSON(w, nethttp.StatusCreated, createViewerResponse{
+		ViewerID:    viewerID.String(),
+		ViewerToken: s.viewerTokens.Sign(viewerID),
+	})
+}
+
+// viewer is the verified author of a viewer-scoped write.
+type viewer struct {
+	ID uuid.UUID
+	// Shadowbanned writes succeed as usual but must be kept out of stats,
+	// recommendations and response lists.
+	Shadowbanned bool
+}
+
+// requireViewer resolves the viewer of a write from its signed token, or
+// from the session bearer token when no viewer token is given, applies the
+// per-viewer rate limit and turns away banned viewers. Shadowbanned viewers
+// are let through and marked. legacyID is the old client-chosen viewer_id
+// field, answered with a pointer to POST /v1/viewers.
+func (s *Server) requireViewer(w nethttp.ResponseWriter, r *nethttp.Request, token, legacyID string) (viewer, bool) {
+	var viewerID uuid.UUID
+	if strings.TrimSpace(token) == "" {
+		session, ok := sessionFromContext(r.Context())
+		if !ok || session.ViewerID == nil {
+			message := "viewer_token is required"
+			if strings.TrimSpace(legacyID) != "" {
+				message = "viewer_id is no longer accepted; get a viewer_token from POST /v1/viewers"
+			}
+			writeProblem(w, nethttp.StatusUnauthorized, errorCodeViewerTokenRequired, message)
+			return viewer{}, false
+		}
+		viewerID = *session.ViewerID
+	} else {
+		var err error
+		viewerID, err = s.viewerTokens.Verify(token)
+		if err != nil {
+			writeProblem(w, nethttp.StatusUnauthorized, errorCodeViewerTokenRequired, err.Error())
+			return viewer{}, false
+		}
+	}
+	if !s.allowViewerRequest(w, viewerID.String()) {
+		return viewer{}, false
+	}
+
+	auditFromContext(r.Context()).setActor(auditActorViewer, viewerID.String())
+
+	var shadow bool
+	err := s.db.QueryRowContext(r.Context(), `
+		SELECT shadow FROM banned_viewers WHERE viewer_id = $1
+	`, viewerID).Scan(&shadow)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return viewer{ID: viewerID}, true
+	case err != nil:
+		s.writeServerError(w, err, "failed to load viewer")
+		return viewer{}, false
+	case !shadow:
+		writeProblem(w, nethttp.StatusForbidden, errorCodeViewerBanned, "viewer is banned")
+		return viewer{}, false
+	}
+	return viewer{ID: viewerID, Shadowbanned: true}, true
+}
@@ -0,0 +1,440 @@
+package httpapi
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	nethttp "net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/notify"
+	"ratemylifedecision/internal/store"
+	"ratemylifedecision/internal/webhooks"
+)
+
+const (
+	maxDecisionWebhooks         = 5
+	maxWebhookBodyBytes         = 4 * 1024
+	defaultWebhookDeliveryLimit = 50
+	maxWebhookDeliveryListLimit = 200
+)
+
+// voteMilestones are the post vote counts that fire vote_milestone.
+var voteMilestones = map[int]struct{}{
+	10: {}, 25: {}, 50: {}, 100: {}, 250: {}, 500: {}, 1000: {},
+}
+
+// discordEvents are what a Discord webhook gets when none are named: each
+// response and the final verdict.
+var discordEvents = []string{string(webhooks.EventNewResponse), string(webhooks.EventDecisionClosed)}
+
+type webhookRequest struct {
+	URL string `json:"url"`
+	// Kind is "generic" (signed JSON, the default) or "discord".
+	Kind string `json:"kind"`
+	// Events defaults to every event when empty, or to discordEvents for
+	// Discord webhooks.
+	Events []string `json:"events"`
+}
+
+type webhookView struct {
+	ID        string    `json:"id"`
+	Kind      string    `json:"kind"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// createdWebhookView carries the signing secret, which is only ever shown
+// in the response that created the webhook.
+type createdWebhookView struct {
+	webhookView
+	Secret string `json:"secret"`
+}
+
+type webhookDeliveryView struct {
+	ID             string     `json:"id"`
+	Event          string     `json:"event"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	LastStatusCode *int       `json:"last_status_code"`
+	LastError      *string    `json:"last_error"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at"`
+	// RequestID is the API request that raised the event, also sent to the
+	// receiver as X-Request-Id.
+	RequestID *string `json:"request_id"`
+}
+
+func normalizeWebhookRequest(req webhookRequest) (webhookRequest, error) {
+	rawURL := strings.TrimSpace(req.URL)
+	if rawURL == "" {
+		return webhookRequest{}, errors.New("webhook url is required")
+	}
+	kind := webhooks.Kind(strings.ToLower(strings.TrimSpace(req.Kind)))
+	switch kind {
+	case "", webhooks.KindGeneric:
+		kind = webhooks.KindGeneric
+		if err := validateChannelAddress(notify.ChannelWebhook, rawURL); err != nil {
+			return webhookRequest{}, errors.New("webhook url must be a public https URL")
+		}
+	case webhooks.KindDiscord:
+		if !isDiscordWebhookURL(rawURL) {
+			return webhookRequest{}, errors.New("discord webhook url must be an https://discord.com/api/webhooks/ URL")
+		}
+	default:
+		return webhookRequest{}, fmt.Errorf("unknown webhook kind %q", req.Kind)
+	}
+
+	if len(req.Events) == 0 {
+		if kind == webhooks.KindDiscord {
+			return webhookRequest{URL: rawURL, Kind: string(kind), Events: discordEvents}, nil
+		}
+		events := make([]string, 0, len(webhooks.AllEvents))
+		for _, e := range webhooks.AllEvents {
+			events = append(events, string(e))
+		}
+		return webhookRequest{URL: rawURL, Kind: string(kind), Events: events}, nil
+	}
+	seen := make(map[webhooks.Event]struct{}, len(req.Events))
+	events := make([]string, 0, len(req.Events))
+	for _, raw := range req.Events {
+		event, ok := webhooks.ParseEvent(strings.TrimSpace(raw))
+		if !ok {
+			return webhookRequest{}, fmt.Errorf("unknown webhook event %q", raw)
+		}
+		if _, dup := seen[event]; dup {
+			continue
+		}
+		seen[event] = struct{}{}
+		events = append(events, string(event))
+	}
+	return webhookRequest{URL: rawURL, Kind: string(kind), Events: events}, nil
+}
+
+// isDiscordWebhookURL accepts only Discord's own webhook endpoints, so a
+// "discord" webhook cannot be used to post chat messages anywhere else.
+func isDiscordWebhookURL(raw string) bool {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.User != nil || u.Port() != "" {
+		return false
+	}
+	switch strings.ToLower(u.Hostname()) {
+	case "discord.com", "discordapp.com", "ptb.discord.com", "canary.discord.com":
+	default:
+		return false
+	}
+	return strings.HasPrefix(u.Path, "/api/webhooks/")
+}
+
+// normalizeWebhookRequests validates the webhooks sent with a new decision.
+func normalizeWebhookRequests(reqs []webhookRequest) ([]webhookRequest, error) {
+	if len(reqs) > maxDecisionWebhooks {
+		return nil, fmt.Errorf("at most %d webhooks are allowed per decision", maxDecisionWebhooks)
+	}
+	out := make([]webhookRequest, 0, len(reqs))
+	for _, req := range reqs {
+		normalized, err := normalizeWebhookRequest(req)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, normalized)
+	}
+	return out, nil
+}
+
+func (s *Server) insertWebhook(ctx context.Context, decisionID uuid.UUID, req webhookRequest) (createdWebhookView, error) {
+	secret, err := newSecretToken()
+	if err != nil {
+		return createdWebhookView{}, err
+	}
+	view := createdWebhookView{
+		webhookView: webhookView{ID: uuid.NewString(), Kind: req.Kind, URL: req.URL, Events: req.Events},
+		Secret:      secret,
+	}
+	err = s.db.QueryRowContext(ctx, `
+		INSERT INTO decision_webhooks (id, decision_id, kind, url, secret, events)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at
+	`, view.ID, decisionID, req.Kind, req.URL, secret, req.Events).Scan(&view.CreatedAt)
+	return view, err
+}
+
+// createDecisionWebhooks registers the webhooks sent with a new decision. If
+// one fails the decision is removed again, since the caller never learns its
+// creator token.
+func (s *Server) createDecisionWebhooks(ctx context.Context, decisionID uuid.UUID, reqs []webhookRequest) ([]createdWebhookView, error) {
+	var created []createdWebhookView
+	for _, req := range reqs {
+		view, err := s.insertWebhook(ctx, decisionID, req)
+		if err != nil {
+			if _, delErr := s.db.ExecContext(ctx, `DELETE FROM decisions WHERE id = $1`, decisionID); delErr != nil {
+				err = errors.Join(err, delErr)
+			}
+			return nil, err
+		}
+		created = append(created, view)
+	}
+	return created, nil
+}
+
+func (s *Server) handleListWebhooks(w nethttp.ResponseWriter, r *nethttp.Request) {
+	decision, ok := s.loadCreatorDecision(w, r)
+	if !ok {
+		return
+	}
+
+	ctx, cancel := withBudget(r.Context(), statsQueryBudget)
+	defer cancel()
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, kind, url, to_jsonb(events), created_at
+		FROM decision_webhooks
+		WHERE decision_id = $1
+		ORDER BY created_at
+	`, decision.ID)
+	if err != nil {
+		s.writeServerError(w, err, "failed to load webhooks")
+		return
+	}
+	defer rows.Close()
+
+	out := make([]webhookView, 0, maxDecisionWebhooks)
+	for rows.Next() {
+		var (
+			v          webhookView
+			id         uuid.UUID
+			eventsJSON []byte
+		)
+		if err := rows.Scan(&id, &v.Kind, &v.URL, &eventsJSON, &v.CreatedAt); err != nil {
+			s.writeServerError(w, err, "failed to load webhooks")
+			return
+		}
+		if err := json.Unmarshal(eventsJSON, &v.Events); err != nil {
+			s.writeServerError(w, err, "failed to load webhooks")
+			return
+		}
+		v.ID = id.String()
+		out = append(out, v)
+	}
+	if err := rows.Err(); err != nil {
+		s.writeServerError(w, err, "failed to load webhooks")
+		return
+	}
+	writeJSON(w, nethttp.StatusOK, map[string]any{"webhooks": out})
+}
+
+func (s *Server) handleCreateWebhook(w nethttp.ResponseWriter, r *nethttp.Request) {
+	decision, ok := s.loadCreatorDecision(w, r)
+	if !ok {
+		return
+	}
+
+	var req webhookRequest
+	if err := decodeJSON(w, r, maxWebhookBodyBytes, &req); err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+	req, err := normalizeWebhookRequest(req)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := withBudget(r.Context(), writeQueryBudget)
+	defer cancel()
+	var count int
+	if err := s.db.QueryRowContext(ctx, `
+		SELECT COUNT(*)::int FROM decision_webhooks WHERE decision_id = $1
+	`, decision.ID).Scan(&count); err != nil {
+		s.writeServerError(w, err, "failed to create webhook")
+		return
+	}
+	if count >= maxDecisionWebhooks {
+		writeError(w, nethttp.StatusConflict, fmt.Sprintf("at most %d webhooks are allowed per decision", maxDecisionWebhooks))
+		return
+	}
+
+	view, err := s.insertWebhook(ctx, decision.ID, req)
+	if err != nil {
+		s.writeServerError(w, err, "failed to create webhook")
+		return
+	}
+	writeJSON(w, nethttp.StatusCreated, view)
+}
+
+func (s *Server) handleDeleteWebhook(w nethttp.ResponseWriter, r *nethttp.Request) {
+	decision, ok := s.loadCreatorDecision(w, r)
+	if !ok {
+		return
+	}
+	webhookID, err := uuid.Parse(strings.TrimSpace(chi.URLParam(r, "id")))
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, "webhook id must be a valid UUID")
+		return
+	}
+
+	ctx, cancel := withBudget(r.Context(), writeQueryBudget)
+	defer cancel()
+	result, err := s.db.ExecContext(ctx, `
+		DELETE FROM decision_webhooks WHERE id = $1 AND decision_id = $2
+	`, webhookID, decision.ID)
+	if err != nil {
+		s.writeServerError(w, err, "failed to delete webhook")
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		writeError(w, nethttp.StatusNotFound, "webhook not found")
+		return
+	}
+	w.WriteHeader(nethttp.StatusNoContent)
+}
+
+// handleListWebhookDeliveries returns a webhook's delivery log, newest
+// first, so creators can see why their endpoint is not receiving events.
+func (s *Server) handleListWebhookDeliveries(w nethttp.ResponseWriter, r *nethttp.Request) {
+	decision, ok := s.loadCreatorDecision(w, r)
+	if !ok {
+		return
+	}
+	webhookID, err := uuid.Parse(strings.TrimSpace(chi.URLParam(r, "id")))
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, "webhook id must be a valid UUID")
+		return
+	}
+	limit, err := parseLimitParam(r, defaultWebhookDeliveryLimit, maxWebhookDeliveryListLimit)
+	if err != nil {
+		writeError(w, nethttp.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := withBudget(r.Context(), statsQueryBudget)
+	defer cancel()
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, `
+		SELECT EXISTS (SELECT 1 FROM decision_webhooks WHERE id = $1 AND decision_id = $2)
+	`, webhookID, decision.ID).Scan(&exists); err != nil {
+		s.writeServerError(w, err, "failed to load webhook deliveries")
+		return
+	}
+	if !exists {
+		writeError(w, nethttp.StatusNotFound, "webhook not found")
+		return
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, event, status, attempts, last_status_code, last_error, next_attempt_at, created_at, delivered_at, request_id
+		FROM webhook_deliveries
+		WHERE webhook_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, webhookID, limit)
+	if err != nil {
+		s.writeServerError(w, err, "failed to load webhook deliveries")
+		return
+	}
+	defer rows.Close()
+
+	out := make([]webhookDeliveryView, 0, limit)
+	for rows.Next() {
+		var (
+			v          webhookDeliveryView
+			id         uuid.UUID
+			statusCode sql.NullInt32
+			nextAt     time.Time
+		)
+		if err := rows.Scan(&id, &v.Event, &v.Status, &v.Attempts, &statusCode, &v.LastError, &nextAt, &v.CreatedAt, &v.DeliveredAt, &v.RequestID); err != nil {
+			s.writeServerError(w, err, "failed to load webhook deliveries")
+			return
+		}
+		v.ID = id.String()
+		if statusCode.Valid {
+			code := int(statusCode.Int32)
+			v.LastStatusCode = &code
+		}
+		if v.Status == "pending" {
+			v.NextAttemptAt = &nextAt
+		}
+		out = append(out, v)
+	}
+	if err := rows.Err(); err != nil {
+		s.writeServerError(w, err, "failed to load webhook deliveries")
+		return
+	}
+	writeJSON(w, nethttp.StatusOK, map[string]any{"deliveries": out})
+}
+
+func (s *Server) webhookDecision(decision store.Decision) webhooks.Decision {
+	return webhooks.Decision{
+		ID:       decision.ID,
+		Slug:     decision.Slug,
+		Title:    decision.Title,
+		ShareURL: s.shareURL(decision.Slug),
+	}
+}
+
+// enqueueWebhook records event for the decision's webhooks. Failures are
+// logged rather than returned: the write that caused the event has already
+// succeeded.
+func (s *Server) enqueueWebhook(ctx context.Context, event webhooks.Event, decision store.Decision, dedupeKey string, data map[string]any) {
+	if err := s.webhooks.Enqueue(ctx, event, s.webhookDecision(decision), dedupeKey, data); err != nil {
+		slog.Error("webhook enqueue failed",
+			"event", string(event),
+			"decision_id", decision.ID.String(),
+			"error", err,
+		)
+	}
+}
+
+// webhookClosedData is the decision_closed data: the final recommendation
+// as of the close.
+func (s *Server) webhookClosedData(ctx context.Context, decisionID uuid.UUID) (map[string]any, error) {
+	rec, err := s.loadRecommendation(ctx, decisionID)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.loadDecisionStats(ctx, decisionID)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"recommendation": rec.Decision,
+		"score":          rec.Score,
+		"response_count": stats.ResponseCount,
+	}, nil
+}
+
+func (s *Server) notifyWebhooksNewResponse(ctx context.Context, decision store.Decision, response store.Response) {
+	data := map[string]any{
+		"response_id": response.ID.String(),
+		"rating":      response.Rating,
+		"suggestion":  response.Suggestion,
+		"created_at":  response.CreatedAt,
+	}
+	if !decision.AggregateOnly {
+		data["emoji"] = response.Emoji
+		data["comment"] = response.Comment
+	}
+	s.enqueueWebhook(ctx, webhooks.EventNewResponse, decision, response.ID.String(), data)
+}
+
+func (s *Server) notifyWebhooksVoteMilestone(ctx context.Context, decision store.Decision, summary store.VoteSummary) {
+	count := summary.Upvotes + summary.Downvotes
+	if _, ok := voteMilestones[count]; !ok {
+		return
+	}
+	s.enqueueWebhook(ctx, webhooks.EventVoteMilestone, decision, fmt.Sprintf("votes:%d", count), map[string]any{
+		"vote_count": count,
+		"score":      summary.Score,
+		"upvotes":    summary.Upvotes,
+		"downvotes":  summary.Downvotes,
+	})
+}
@@ -0,0 +1,157 @@
+// Package jobs runs the server's periodic background work. Each job runs on
+// its own ticker, never overlapping itself; Trigger queues an extra run so
+// work that was just made due does not wait for the next tick.
+package jobs
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+type Job struct {
+	Name     string
+	Interval time.Duration
+	// Timeout bounds a single run. Zero means Interval.
+	Timeout time.Duration
+	Run     func(ctx context.Context) error
+}
+
+// Status is what the scheduler knows about a job, for the admin API.
+type Status struct {
+	Name         string     `json:"name"`
+	Interval     string     `json:"interval"`
+	Runs         int64      `json:"runs"`
+	Failures     int64      `json:"failures"`
+	LastRunAt    *time.Time `json:"last_run_at"`
+	LastDuration string     `json:"last_duration,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
+}
+
+type entry struct {
+	job     Job
+	trigger chan struct{}
+	status  Status
+}
+
+type Scheduler struct {
+	mu      sync.Mutex
+	entries map[string]*entry
+	started bool
+}
+
+func New() *Scheduler {
+	return &Scheduler{entries: make(map[string]*entry)}
+}
+
+// Add registers job. Jobs must be added before Run; a job with a
+// non-positive interval is skipped, which is how a job is turned off.
+func (s *Scheduler) Add(job Job) {
+	if job.Interval <= 0 {
+		slog.Info("background job disabled", "job", job.Name)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		panic("jobs: Add called after Run")
+	}
+	s.entries[job.Name] = &entry{
+		job:     job,
+		trigger: make(chan struct{}, 1),
+		status:  Status{Name: job.Name, Interval: job.Interval.String()},
+	}
+}
+
+// Trigger queues a run of the named job as soon as it is idle. Triggers
+// that arrive while one is already queued are merged.
+func (s *Scheduler) Trigger(name string) {
+	s.mu.Lock()
+	e, ok := s.entries[name]
+	s.mu.Unlock()
+	if !ok {
+		return
+	}
+	select {
+	case e.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// Run starts every job and blocks until ctx is cancelled and the runs in
+// progress have returned.
+func (s *Scheduler) Run(ctx context.Context) {
+	s.mu.Lock()
+	s.started = true
+	entries := make([]*entry, 0, len(s.entries))
+	for _, e := range s.entries {
+		entries = append(entries, e)
+	}
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, e := range entries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.loop(ctx, e)
+		}()
+	}
+	wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, e *entry) {
+	ticker := time.NewTicker(e.job.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-e.trigger:
+		}
+		s.runOnce(ctx, e)
+	}
+}
+
+func (s *Scheduler) runOnce(ctx context.Context, e *entry) {
+	timeout := e.job.Timeout
+	if timeout <= 0 {
+		timeout = e.job.Interval
+	}
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	started := time.Now()
+	err := e.job.Run(runCtx)
+	cancel()
+	elapsed := time.Since(started)
+
+	if err != nil && ctx.Err() == nil {
+		slog.Error("background job failed", "job", e.job.Name, "duration_ms", elapsed.Milliseconds(), "error", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e.status.Runs++
+	e.status.LastRunAt = &started
+	e.status.LastDuration = elapsed.Round(time.Millisecond).String()
+	e.status.LastError = ""
+	if err != nil {
+		e.status.Failures++
+		e.status.LastError = err.Error()
+	}
+}
+
+// Statuses reports every registered job, sorted by name.
+func (s *Scheduler) Statuses() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Status, 0, len(s.entries))
+	for _, e := range s.entries {
+		out = append(out, e.status)
+	}
+	slices.SortFunc(out, func(a, b Status) int { return strings.Compare(a.Name, b.Name) })
+	return out
+}
@@ -0,0 +1,26 @@
+package logging
+
+import "net/http"
+
+// RequestIDHeader carries the request ID in and out of the service.
+const RequestIDHeader = "X-Request-Id"
+
+// Transport forwards the request ID in an outbound request's context as
+// X-Request-Id, so a call made on behalf of an API request can be matched
+// up with it in the other service's logs. Base defaults to
+// http.DefaultTransport.
+type Transport struct {
+	Base http.RoundTripper
+}
+
+func (t Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if id := RequestID(req.Context()); id != "" && req.Header.Get(RequestIDHeader) == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set(RequestIDHeader, id)
+	}
+	return base.RoundTrip(req)
+}
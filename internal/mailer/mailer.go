@@ -0,0 +1,85 @@
+// Package mailer sends plain-text email through SMTP or the SendGrid API.
+// It is shared by the email notification channel and the creator emails
+// sent by the API (address confirmation and the results digest).
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"ratemylifedecision/internal/logging"
+)
+
+const sendTimeout = 10 * time.Second
+
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// FromEnv returns nil when no provider is configured, which disables email.
+// MAIL_PROVIDER picks "smtp" or "sendgrid"; when unset, SMTP is used if
+// SMTP_HOST is set.
+func FromEnv() (Mailer, error) {
+	provider := strings.ToLower(env("MAIL_PROVIDER"))
+	if provider == "" && env("SMTP_HOST") != "" {
+		provider = "smtp"
+	}
+	from := env("MAIL_FROM")
+	if from == "" {
+		from = env("SMTP_FROM")
+	}
+
+	switch provider {
+	case "":
+		return nil, nil
+	case "smtp":
+		host := env("SMTP_HOST")
+		if host == "" {
+			return nil, errors.New("SMTP_HOST is required when MAIL_PROVIDER is smtp")
+		}
+		port := env("SMTP_PORT")
+		if port == "" {
+			port = "587"
+		}
+		return &SMTP{
+			Addr:     host + ":" + port,
+			Username: env("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     from,
+		}, nil
+	case "sendgrid":
+		key := env("SENDGRID_API_KEY")
+		if key == "" {
+			return nil, errors.New("SENDGRID_API_KEY is required when MAIL_PROVIDER is sendgrid")
+		}
+		if from == "" {
+			return nil, errors.New("MAIL_FROM is required when MAIL_PROVIDER is sendgrid")
+		}
+		return &SendGrid{
+			APIKey: key,
+			From:   from,
+			Client: &http.Client{Timeout: sendTimeout, Transport: logging.Transport{}},
+		}, nil
+	default:
+		return nil, fmt.Errorf("MAIL_PROVIDER must be smtp or sendgrid, got %q", provider)
+	}
+}
+
+func env(key string) string {
+	return strings.TrimSpace(os.Getenv(key))
+}
+
+func sanitizeHeader(value string) string {
+	return strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+}
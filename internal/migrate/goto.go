@@ -0,0 +1,106 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+)
+
+// GotoPlan is what Goto would do to reach a target version: roll back every
+// applied migration above it (newest first), then apply every pending one
+// at or below it. Both halves can be non-empty when versions were applied
+// out of order, e.g. after merging a branch with an older timestamp.
+type GotoPlan struct {
+	Down []PlannedMigration
+	Up   []PlannedMigration
+}
+
+// PlanGoto returns the plan Goto would run without changing anything.
+func PlanGoto(ctx context.Context, db *sql.DB, fsys fs.FS, target int64) (GotoPlan, error) {
+	down, up, err := planGoto(ctx, db, fsys, target)
+	if err != nil {
+		return GotoPlan{}, err
+	}
+
+	var plan GotoPlan
+	if plan.Down, err = readPlanned(fsys, down); err != nil {
+		return GotoPlan{}, err
+	}
+	if plan.Up, err = readPlanned(fsys, up); err != nil {
+		return GotoPlan{}, err
+	}
+	return plan, nil
+}
+
+// Goto migrates to exactly target: afterwards the applied versions are the
+// on-disk versions up to and including target. Target 0 rolls everything
+// back. Drift is handled as in Up. It returns the names of the down and up
+// migrations it ran, in order.
+func Goto(ctx context.Context, db *sql.DB, fsys fs.FS, target int64, opts Options) (rolledBack, applied []string, err error) {
+	release, err := acquireLock(ctx, db, opts.LockTimeout)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer release()
+
+	if err := ensureSchemaMigrations(ctx, db); err != nil {
+		return nil, nil, err
+	}
+	if err := checkDrift(ctx, db, fsys, opts.Force); err != nil {
+		return nil, nil, err
+	}
+
+	down, up, err := planGoto(ctx, db, fsys, target)
+	if err != nil {
+		return nil, nil, err
+	}
+	if rolledBack, err = applyDown(ctx, db, fsys, down); err != nil {
+		return rolledBack, nil, err
+	}
+	applied, err = applyUp(ctx, db, fsys, up)
+	return rolledBack, applied, err
+}
+
+func planGoto(ctx context.Context, db *sql.DB, fsys fs.FS, target int64) (down, up []migrationFile, err error) {
+	if target < 0 {
+		return nil, nil, fmt.Errorf("target version must not be negative")
+	}
+
+	files, err := collectUpMigrations(fsys)
+	if err != nil {
+		return nil, nil, err
+	}
+	if target != 0 {
+		known := false
+		for _, f := range files {
+			if f.version == target {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, nil, fmt.Errorf("no migration with version %d", target)
+		}
+	}
+
+	down, err = planDown(ctx, db, fsys, 0, target)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	statuses, err := Status(ctx, db, fsys)
+	if err != nil {
+		return nil, nil, err
+	}
+	pending := make(map[int64]bool, len(statuses))
+	for _, st := range statuses {
+		pending[st.Version] = st.AppliedAt == nil
+	}
+	for _, f := range files {
+		if f.version <= target && pending[f.version] {
+			up = append(up, f)
+		}
+	}
+	return down, up, nil
+}
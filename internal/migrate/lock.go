@@ -0,0 +1,77 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// DefaultLockTimeout is how long Up and Down wait for another migrator when
+// Options.LockTimeout is zero.
+const DefaultLockTimeout = time.Minute
+
+// lockKey identifies the migration lock among the application's advisory
+// locks. It is arbitrary but must never change.
+const lockKey int64 = 7_146_205_318
+
+const lockPollInterval = 250 * time.Millisecond
+
+// acquireLock takes the session-level migration advisory lock on a
+// dedicated connection, so two replicas (or CI and an operator) never apply
+// or roll back migrations at the same time. It polls pg_try_advisory_lock
+// rather than blocking in pg_advisory_lock so the wait is bounded by
+// timeout without also bounding the migrations themselves.
+func acquireLock(ctx context.Context, db *sql.DB, timeout time.Duration) (release func(), err error) {
+	if timeout <= 0 {
+		timeout = DefaultLockTimeout
+	}
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	waiting := false
+	for {
+		var locked bool
+		if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", lockKey).Scan(&locked); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("acquire migration lock: %w", err)
+		}
+		if locked {
+			break
+		}
+		if !waiting {
+			slog.Info("waiting for another migrator to release the migration lock", "timeout", timeout.String())
+			waiting = true
+		}
+		if time.Now().After(deadline) {
+			_ = conn.Close()
+			return nil, fmt.Errorf("timed out after %s waiting for the migration lock", timeout)
+		}
+
+		select {
+		case <-ctx.Done():
+			_ = conn.Close()
+			return nil, ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+
+	return func() {
+		// Use a fresh context: the caller's may already be cancelled.
+		unlockCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if _, err := conn.ExecContext(unlockCtx, "SELECT pg_advisory_unlock($1)", lockKey); err != nil {
+			slog.Warn("failed to release migration lock", "error", err)
+			// Discard the connection instead of returning it to the pool;
+			// ending the session releases the lock.
+			_ = conn.Raw(func(any) error { return driver.ErrBadConn })
+		}
+		_ = conn.Close()
+	}, nil
+}
@@ -2,13 +2,20 @@ package migrate
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io/fs"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type migrationFile struct {
@@ -17,66 +24,321 @@ type migrationFile struct {
 	path    string
 }
 
-func Up(ctx context.Context, db *sql.DB, migrationsDir string) error {
+// DriftError is returned by Up when applied migrations were edited after
+// they ran, so the files no longer describe the schema the database has.
+type DriftError struct {
+	Names []string
+}
+
+func (e *DriftError) Error() string {
+	return fmt.Sprintf("applied migrations changed on disk: %s (restore them, or re-run with force to ignore)", strings.Join(e.Names, ", "))
+}
+
+// Options tunes Up and Down. The zero value is strict about drift and waits
+// DefaultLockTimeout for the migration lock.
+type Options struct {
+	// Force applies pending migrations even when applied ones drifted.
+	Force bool
+	// LockTimeout bounds how long to wait for another migrator to finish.
+	LockTimeout time.Duration
+}
+
+// Up applies every up migration in fsys that is not recorded in
+// schema_migrations, in version order, while holding the migration lock.
+// It first compares applied files with their recorded checksums and fails
+// with *DriftError on a mismatch unless opts.Force is set, in which case
+// the drift is only logged.
+func Up(ctx context.Context, db *sql.DB, fsys fs.FS, opts Options) error {
+	release, err := acquireLock(ctx, db, opts.LockTimeout)
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	if err := ensureSchemaMigrations(ctx, db); err != nil {
 		return err
 	}
+	if err := checkDrift(ctx, db, fsys, opts.Force); err != nil {
+		return err
+	}
 
-	files, err := collectUpMigrations(migrationsDir)
+	files, err := collectUpMigrations(fsys)
 	if err != nil {
 		return err
 	}
+	if _, err := applyUp(ctx, db, fsys, files); err != nil {
+		return err
+	}
+	return backfillChecksums(ctx, db, fsys, files)
+}
+
+func checkDrift(ctx context.Context, db *sql.DB, fsys fs.FS, force bool) error {
+	drifted, err := Drifted(ctx, db, fsys)
+	if err != nil {
+		return err
+	}
+	if len(drifted) > 0 {
+		if !force {
+			return &DriftError{Names: drifted}
+		}
+		for _, name := range drifted {
+			slog.Warn("applied migration changed on disk", "migration", name)
+		}
+	}
+	return nil
+}
 
+// applyUp applies the files that are not applied yet, in order, each in its
+// own transaction together with its schema_migrations row.
+func applyUp(ctx context.Context, db *sql.DB, fsys fs.FS, files []migrationFile) ([]string, error) {
+	var done []string
 	for _, f := range files {
 		applied, err := isApplied(ctx, db, f.version)
 		if err != nil {
-			return err
+			return done, err
 		}
 		if applied {
 			continue
 		}
 
-		sqlBytes, err := os.ReadFile(f.path)
+		sqlBytes, err := fs.ReadFile(fsys, f.path)
 		if err != nil {
-			return fmt.Errorf("read migration %s: %w", f.name, err)
+			return done, fmt.Errorf("read migration %s: %w", f.name, err)
 		}
 
 		tx, err := db.BeginTx(ctx, nil)
 		if err != nil {
-			return err
+			return done, err
 		}
 
 		if _, err := tx.ExecContext(ctx, string(sqlBytes)); err != nil {
 			_ = tx.Rollback()
-			return fmt.Errorf("apply migration %s: %w", f.name, err)
+			return done, fmt.Errorf("apply migration %s: %w", f.name, err)
 		}
 
 		if _, err := tx.ExecContext(ctx,
-			"INSERT INTO schema_migrations (version, name) VALUES ($1, $2)",
+			"INSERT INTO schema_migrations (version, name, checksum) VALUES ($1, $2, $3)",
 			f.version,
 			f.name,
+			checksum(sqlBytes),
 		); err != nil {
 			_ = tx.Rollback()
-			return fmt.Errorf("record migration %s: %w", f.name, err)
+			return done, fmt.Errorf("record migration %s: %w", f.name, err)
 		}
 
 		if err := tx.Commit(); err != nil {
-			return err
+			return done, err
 		}
+		done = append(done, f.name)
 	}
+	return done, nil
+}
 
+// backfillChecksums records checksums for migrations applied before
+// checksums were tracked, trusting the files as they are now.
+func backfillChecksums(ctx context.Context, db *sql.DB, fsys fs.FS, files []migrationFile) error {
+	for _, f := range files {
+		sqlBytes, err := fs.ReadFile(fsys, f.path)
+		if err != nil {
+			return fmt.Errorf("read migration %s: %w", f.name, err)
+		}
+		if _, err := db.ExecContext(ctx,
+			"UPDATE schema_migrations SET checksum = $2 WHERE version = $1 AND checksum IS NULL",
+			f.version,
+			checksum(sqlBytes),
+		); err != nil {
+			return fmt.Errorf("record checksum for %s: %w", f.name, err)
+		}
+	}
 	return nil
 }
 
+func checksum(sqlBytes []byte) string {
+	sum := sha256.Sum256(sqlBytes)
+	return hex.EncodeToString(sum[:])
+}
+
+// PlannedMigration is a migration that Up or Down would apply, with the SQL
+// it would run.
+type PlannedMigration struct {
+	Version int64
+	Name    string
+	SQL     string
+}
+
+// PlanUp returns the migrations Up would apply, in order, without taking the
+// lock or changing anything. Drift is reported separately by Drifted.
+func PlanUp(ctx context.Context, db *sql.DB, fsys fs.FS) ([]PlannedMigration, error) {
+	statuses, err := Status(ctx, db, fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := collectUpMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+	pending := make(map[int64]bool, len(statuses))
+	for _, st := range statuses {
+		pending[st.Version] = st.AppliedAt == nil
+	}
+
+	var planned []migrationFile
+	for _, f := range files {
+		if pending[f.version] {
+			planned = append(planned, f)
+		}
+	}
+	return readPlanned(fsys, planned)
+}
+
+// PlanDown returns the down migrations Down would apply for the n most
+// recently applied versions, newest first.
+func PlanDown(ctx context.Context, db *sql.DB, fsys fs.FS, n int) ([]PlannedMigration, error) {
+	if n < 1 {
+		return nil, errRollbackCount
+	}
+	files, err := planDown(ctx, db, fsys, n, 0)
+	if err != nil {
+		return nil, err
+	}
+	return readPlanned(fsys, files)
+}
+
+func readPlanned(fsys fs.FS, files []migrationFile) ([]PlannedMigration, error) {
+	planned := make([]PlannedMigration, 0, len(files))
+	for _, f := range files {
+		sqlBytes, err := fs.ReadFile(fsys, f.path)
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s: %w", f.name, err)
+		}
+		planned = append(planned, PlannedMigration{Version: f.version, Name: f.name, SQL: string(sqlBytes)})
+	}
+	return planned, nil
+}
+
+var errRollbackCount = errors.New("rollback count must be at least 1")
+
+// Down rolls back the n most recently applied migrations, newest first, each
+// in its own transaction together with removing its schema_migrations row.
+// It returns the names of the down migrations that were applied.
+func Down(ctx context.Context, db *sql.DB, fsys fs.FS, n int, opts Options) ([]string, error) {
+	if n < 1 {
+		return nil, errRollbackCount
+	}
+
+	release, err := acquireLock(ctx, db, opts.LockTimeout)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	if err := ensureSchemaMigrations(ctx, db); err != nil {
+		return nil, err
+	}
+	files, err := planDown(ctx, db, fsys, n, 0)
+	if err != nil {
+		return nil, err
+	}
+	return applyDown(ctx, db, fsys, files)
+}
+
+func applyDown(ctx context.Context, db *sql.DB, fsys fs.FS, files []migrationFile) ([]string, error) {
+	var done []string
+	for _, f := range files {
+		sqlBytes, err := fs.ReadFile(fsys, f.path)
+		if err != nil {
+			return done, fmt.Errorf("read migration %s: %w", f.name, err)
+		}
+
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			return done, err
+		}
+
+		if _, err := tx.ExecContext(ctx, string(sqlBytes)); err != nil {
+			_ = tx.Rollback()
+			return done, fmt.Errorf("apply migration %s: %w", f.name, err)
+		}
+
+		if _, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = $1", f.version); err != nil {
+			_ = tx.Rollback()
+			return done, fmt.Errorf("unrecord migration %s: %w", f.name, err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return done, err
+		}
+		done = append(done, f.name)
+	}
+	return done, nil
+}
+
+// planDown returns the down files for applied versions above `above`, newest
+// first, at most n of them (n <= 0 means all).
+func planDown(ctx context.Context, db *sql.DB, fsys fs.FS, n int, above int64) ([]migrationFile, error) {
+	var tracked bool
+	if err := db.QueryRowContext(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&tracked); err != nil || !tracked {
+		return nil, err
+	}
+
+	downFiles, err := collectMigrations(fsys, ".down.sql")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int64]migrationFile, len(downFiles))
+	for _, f := range downFiles {
+		byVersion[f.version] = f
+	}
+
+	// LIMIT NULL is LIMIT ALL.
+	limit := sql.NullInt64{Int64: int64(n), Valid: n > 0}
+	rows, err := db.QueryContext(ctx, "SELECT version, name FROM schema_migrations WHERE version > $2 ORDER BY version DESC LIMIT $1", limit, above)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var files []migrationFile
+	for rows.Next() {
+		var (
+			version int64
+			name    string
+		)
+		if err := rows.Scan(&version, &name); err != nil {
+			return nil, err
+		}
+		f, ok := byVersion[version]
+		if !ok {
+			return nil, fmt.Errorf("no down migration for %s", name)
+		}
+		files = append(files, f)
+	}
+	return files, rows.Err()
+}
+
+// ensureSchemaMigrations must run under the migration lock: CREATE TABLE IF
+// NOT EXISTS is not safe against a concurrent create.
 func ensureSchemaMigrations(ctx context.Context, db *sql.DB) error {
-	_, err := db.ExecContext(ctx, `
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `
 		CREATE TABLE IF NOT EXISTS schema_migrations (
 			version BIGINT PRIMARY KEY,
 			name TEXT NOT NULL,
 			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
 		)
-	`)
-	return err
+	`); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "ALTER TABLE schema_migrations ADD COLUMN IF NOT EXISTS checksum TEXT NULL"); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func isApplied(ctx context.Context, db *sql.DB, version int64) (bool, error) {
@@ -85,8 +347,12 @@ func isApplied(ctx context.Context, db *sql.DB, version int64) (bool, error) {
 	return exists, err
 }
 
-func collectUpMigrations(migrationsDir string) ([]migrationFile, error) {
-	entries, err := os.ReadDir(migrationsDir)
+func collectUpMigrations(fsys fs.FS) ([]migrationFile, error) {
+	return collectMigrations(fsys, ".up.sql")
+}
+
+func collectMigrations(fsys fs.FS, suffix string) ([]migrationFile, error) {
+	entries, err := fs.ReadDir(fsys, ".")
 	if err != nil {
 		return nil, err
 	}
@@ -97,7 +363,7 @@ func collectUpMigrations(migrationsDir string) ([]migrationFile, error) {
 			continue
 		}
 		name := e.Name()
-		if !strings.HasSuffix(name, ".up.sql") {
+		if !strings.HasSuffix(name, suffix) {
 			continue
 		}
 		version, err := parseVersion(name)
@@ -107,7 +373,7 @@ func collectUpMigrations(migrationsDir string) ([]migrationFile, error) {
 		files = append(files, migrationFile{
 			version: version,
 			name:    name,
-			path:    filepath.Join(migrationsDir, name),
+			path:    name,
 		})
 	}
 
@@ -129,3 +395,172 @@ func parseVersion(filename string) (int64, error) {
 	}
 	return version, nil
 }
+
+// MigrationStatus describes one migration version as seen on disk and in
+// schema_migrations. AppliedAt is nil for pending migrations; OnDisk is false
+// for applied versions whose .up.sql file no longer exists. Drifted is set
+// when an applied file no longer matches the checksum recorded for it.
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	AppliedAt *time.Time
+	OnDisk    bool
+	Drifted   bool
+}
+
+// Status merges the migrations on disk with the rows in schema_migrations,
+// ordered by version. It never creates schema_migrations, so it is safe to
+// run against a database that has not been migrated yet.
+func Status(ctx context.Context, db *sql.DB, fsys fs.FS) ([]MigrationStatus, error) {
+	files, err := collectUpMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*MigrationStatus, len(files))
+	sums := make(map[int64]string, len(files))
+	statuses := make([]*MigrationStatus, 0, len(files))
+	for _, f := range files {
+		sqlBytes, err := fs.ReadFile(fsys, f.path)
+		if err != nil {
+			return nil, fmt.Errorf("read migration %s: %w", f.name, err)
+		}
+		sums[f.version] = checksum(sqlBytes)
+
+		st := &MigrationStatus{Version: f.version, Name: f.name, OnDisk: true}
+		byVersion[f.version] = st
+		statuses = append(statuses, st)
+	}
+
+	var tracked bool
+	if err := db.QueryRowContext(ctx, "SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&tracked); err != nil {
+		return nil, err
+	}
+	if tracked {
+		// to_jsonb tolerates a schema_migrations that predates the checksum
+		// column, e.g. when a new binary checks a not-yet-migrated database.
+		rows, err := db.QueryContext(ctx, `
+			SELECT version, name, applied_at, to_jsonb(m)->>'checksum'
+			FROM schema_migrations m
+		`)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+		for rows.Next() {
+			var (
+				version   int64
+				name      string
+				appliedAt time.Time
+				recorded  sql.NullString
+			)
+			if err := rows.Scan(&version, &name, &appliedAt, &recorded); err != nil {
+				return nil, err
+			}
+			st, ok := byVersion[version]
+			if !ok {
+				st = &MigrationStatus{Version: version, Name: name}
+				byVersion[version] = st
+				statuses = append(statuses, st)
+			}
+			st.AppliedAt = &appliedAt
+			st.Drifted = st.OnDisk && recorded.Valid && recorded.String != sums[version]
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+	out := make([]MigrationStatus, 0, len(statuses))
+	for _, st := range statuses {
+		out = append(out, *st)
+	}
+	return out, nil
+}
+
+// Drifted returns the names of applied migrations whose files changed after
+// they were applied.
+func Drifted(ctx context.Context, db *sql.DB, fsys fs.FS) ([]string, error) {
+	statuses, err := Status(ctx, db, fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	var drifted []string
+	for _, st := range statuses {
+		if st.Drifted {
+			drifted = append(drifted, st.Name)
+		}
+	}
+	return drifted, nil
+}
+
+// Pending returns the names of up migrations in fsys that have not been
+// applied yet, in the order Up would apply them.
+func Pending(ctx context.Context, db *sql.DB, fsys fs.FS) ([]string, error) {
+	statuses, err := Status(ctx, db, fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []string
+	for _, st := range statuses {
+		if st.AppliedAt == nil {
+			pending = append(pending, st.Name)
+		}
+	}
+	return pending, nil
+}
+
+var migrationNamePattern = regexp.MustCompile(`^[a-z0-9]+(_[a-z0-9]+)*$`)
+
+// Create scaffolds an empty up/down pair named <unix seconds>_<name>. Unix
+// timestamps keep versions from colliding across branches while still
+// sorting after the older zero-padded sequence numbers.
+func Create(migrationsDir, name string, now time.Time) (upPath, downPath string, err error) {
+	if !migrationNamePattern.MatchString(name) {
+		return "", "", fmt.Errorf("migration name must be lowercase snake_case, got %q", name)
+	}
+
+	version := now.Unix()
+	for _, suffix := range []string{".up.sql", ".down.sql"} {
+		existing, err := collectMigrations(os.DirFS(migrationsDir), suffix)
+		if err != nil {
+			return "", "", err
+		}
+		for _, f := range existing {
+			if f.version == version {
+				return "", "", fmt.Errorf("migration version %d already exists: %s", version, f.name)
+			}
+		}
+	}
+
+	base := fmt.Sprintf("%d_%s", version, name)
+	upPath = filepath.Join(migrationsDir, base+".up.sql")
+	downPath = filepath.Join(migrationsDir, base+".down.sql")
+	created := now.UTC().Format(time.RFC3339)
+
+	if err := writeNewFile(upPath, fmt.Sprintf("-- %s (up)\n-- Created %s.\n\n", name, created)); err != nil {
+		return "", "", err
+	}
+	if err := writeNewFile(downPath, fmt.Sprintf("-- %s (down)\n-- Created %s. Undo everything the up migration does.\n\n", name, created)); err != nil {
+		_ = os.Remove(upPath)
+		return "", "", err
+	}
+	return upPath, downPath, nil
+}
+
+func writeNewFile(path, contents string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(contents); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,23 @@
+package notify
+
+import (
+	"context"
+
+	"ratemylifedecision/internal/mailer"
+)
+
+// EmailNotifier delivers notifications through whichever mail provider is
+// configured.
+type EmailNotifier struct {
+	Mailer mailer.Mailer
+}
+
+func (n *EmailNotifier) Channel() Channel { return ChannelEmail }
+
+func (n *EmailNotifier) Send(ctx context.Context, address string, msg Message) error {
+	return n.Mailer.Send(ctx, mailer.Message{
+		To:      address,
+		Subject: msg.Subject,
+		Text:    msg.Body,
+	})
+}
@@ -0,0 +1,42 @@
+package notify
+
+import (
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+
+	"ratemylifedecision/internal/logging"
+	"ratemylifedecision/internal/mailer"
+	"ratemylifedecision/internal/safehttp"
+)
+
+// NotifiersFromEnv builds the channels that have enough configuration to
+// work. Webhooks need no configuration and are always available.
+func NotifiersFromEnv() []Notifier {
+	client := &http.Client{Timeout: httpSendTimeout, Transport: logging.Transport{}}
+	// Webhook addresses come from subscribers, so they get the client that
+	// only reaches public https receivers.
+	notifiers := []Notifier{&WebhookNotifier{Client: safehttp.Client(httpSendTimeout)}}
+
+	if m, err := mailer.FromEnv(); err != nil {
+		slog.Error("mail provider misconfigured; email notifications are disabled", "error", err)
+	} else if m != nil {
+		notifiers = append(notifiers, &EmailNotifier{Mailer: m})
+	}
+	if token := env("SLACK_BOT_TOKEN"); token != "" {
+		notifiers = append(notifiers, &SlackNotifier{BotToken: token, Client: client})
+	}
+	if gateway := env("PUSH_GATEWAY_URL"); gateway != "" {
+		notifiers = append(notifiers, &PushNotifier{
+			GatewayURL: gateway,
+			AuthToken:  env("PUSH_GATEWAY_TOKEN"),
+			Client:     client,
+		})
+	}
+	return notifiers
+}
+
+func env(key string) string {
+	return strings.TrimSpace(os.Getenv(key))
+}
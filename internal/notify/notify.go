@@ -0,0 +1,292 @@
+package notify
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	maxDeliveryAttempts = 5
+	asyncDispatchBudget = 30 * time.Second
+)
+
+type Kind string
+
+const (
+	KindDecisionClosed Kind = "decision_closed"
+	KindMilestone      Kind = "milestone"
+	KindReminder       Kind = "reminder"
+)
+
+var allKinds = []Kind{KindDecisionClosed, KindMilestone, KindReminder}
+
+func ParseKind(raw string) (Kind, bool) {
+	for _, k := range allKinds {
+		if string(k) == raw {
+			return k, true
+		}
+	}
+	return "", false
+}
+
+type Channel string
+
+const (
+	ChannelEmail   Channel = "email"
+	ChannelPush    Channel = "push"
+	ChannelWebhook Channel = "webhook"
+	ChannelSlack   Channel = "slack"
+)
+
+var allChannels = []Channel{ChannelEmail, ChannelPush, ChannelWebhook, ChannelSlack}
+
+func ParseChannel(raw string) (Channel, bool) {
+	for _, c := range allChannels {
+		if string(c) == raw {
+			return c, true
+		}
+	}
+	return "", false
+}
+
+// Event is something that happened to a decision and may be worth telling
+// subscribers about. DedupeKey distinguishes repeated events of the same
+// kind (e.g. each response milestone) so every one is delivered at most once.
+type Event struct {
+	Kind          Kind
+	DecisionID    uuid.UUID
+	DecisionSlug  string
+	DecisionTitle string
+	ShareURL      string
+	DedupeKey     string
+	Data          map[string]any
+}
+
+type Message struct {
+	Subject string
+	Body    string
+	Event   Event
+}
+
+// ErrAddressInvalid is returned by a Notifier when the provider says the
+// address will never accept deliveries again (e.g. an unregistered device
+// token). The dispatcher stops targeting it instead of retrying.
+var ErrAddressInvalid = errors.New("notification address is no longer valid")
+
+type Notifier interface {
+	Channel() Channel
+	Send(ctx context.Context, address string, msg Message) error
+}
+
+type Dispatcher struct {
+	db        *sql.DB
+	notifiers map[Channel]Notifier
+	templates *Templates
+	wg        sync.WaitGroup
+}
+
+type target struct {
+	subscriptionID uuid.UUID
+	channel        Channel
+	address        string
+	pushDeviceID   *uuid.UUID
+}
+
+func NewDispatcher(db *sql.DB, notifiers ...Notifier) *Dispatcher {
+	d := &Dispatcher{
+		db:        db,
+		notifiers: make(map[Channel]Notifier, len(notifiers)),
+		templates: DefaultTemplates(),
+	}
+	for _, n := range notifiers {
+		if n != nil {
+			d.notifiers[n.Channel()] = n
+		}
+	}
+	return d
+}
+
+func (d *Dispatcher) Enabled(channel Channel) bool {
+	_, ok := d.notifiers[channel]
+	return ok
+}
+
+// Dispatch renders the event once and delivers it to every enabled channel
+// of every subscription that opted into the event's kind. Delivery rows are
+// claimed before sending so concurrent dispatchers never double-send.
+func (d *Dispatcher) Dispatch(ctx context.Context, event Event) error {
+	msg, err := d.templates.Render(event)
+	if err != nil {
+		return err
+	}
+
+	targets, err := d.loadTargets(ctx, event)
+	if err != nil {
+		return fmt.Errorf("load notification targets: %w", err)
+	}
+
+	var errs []error
+	for _, t := range targets {
+		notifier, ok := d.notifiers[t.channel]
+		if !ok {
+			continue
+		}
+
+		deliveryID, claimed, err := d.claimDelivery(ctx, t, event)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		sendErr := notifier.Send(ctx, t.address, msg)
+		if err := d.finishDelivery(ctx, deliveryID, sendErr); err != nil {
+			errs = append(errs, err)
+		}
+		if errors.Is(sendErr, ErrAddressInvalid) {
+			if err := d.retireTarget(ctx, t, sendErr); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		if sendErr != nil {
+			errs = append(errs, fmt.Errorf("%s delivery: %w", t.channel, sendErr))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// DispatchAsync runs Dispatch in the background so request handlers are
+// not held up by slow providers. Wait blocks until all of them finish.
+func (d *Dispatcher) DispatchAsync(event Event) {
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		ctx, cancel := context.WithTimeout(context.Background(), asyncDispatchBudget)
+		defer cancel()
+		if err := d.Dispatch(ctx, event); err != nil {
+			slog.Error("notification dispatch failed",
+				"kind", string(event.Kind),
+				"decision_id", event.DecisionID.String(),
+				"error", err,
+			)
+		}
+	}()
+}
+
+func (d *Dispatcher) Wait() {
+	d.wg.Wait()
+}
+
+func (d *Dispatcher) loadTargets(ctx context.Context, event Event) ([]target, error) {
+	// Push channels address a registered device by id; the device's own
+	// preferences and validity apply on top of the subscription's.
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT
+			c.subscription_id,
+			c.channel,
+			CASE WHEN c.channel = 'push' THEN p.platform || ':' || p.token ELSE c.address END,
+			p.id
+		FROM notification_subscription_channels c
+		JOIN notification_subscriptions s ON s.id = c.subscription_id
+		LEFT JOIN push_devices p ON c.channel = 'push' AND p.id::text = c.address
+		WHERE s.decision_id = $1
+		  AND $2 = ANY(s.events)
+		  AND c.enabled
+		  AND (
+			c.channel <> 'push'
+			OR (p.id IS NOT NULL AND p.enabled AND p.invalidated_at IS NULL AND $2 = ANY(p.events))
+		  )
+	`, event.DecisionID, string(event.Kind))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var targets []target
+	for rows.Next() {
+		var (
+			t       target
+			channel string
+		)
+		if err := rows.Scan(&t.subscriptionID, &channel, &t.address, &t.pushDeviceID); err != nil {
+			return nil, err
+		}
+		t.channel = Channel(channel)
+		targets = append(targets, t)
+	}
+	return targets, rows.Err()
+}
+
+func (d *Dispatcher) claimDelivery(ctx context.Context, t target, event Event) (uuid.UUID, bool, error) {
+	var id uuid.UUID
+	err := d.db.QueryRowContext(ctx, `
+		INSERT INTO notification_deliveries (id, subscription_id, channel, kind, dedupe_key, status, attempts)
+		VALUES ($1, $2, $3, $4, $5, 'pending', 1)
+		ON CONFLICT (subscription_id, channel, kind, dedupe_key) DO UPDATE
+		SET status = 'pending', attempts = notification_deliveries.attempts + 1
+		WHERE notification_deliveries.status = 'failed'
+		  AND notification_deliveries.attempts < $6
+		RETURNING id
+	`, uuid.New(), t.subscriptionID, string(t.channel), string(event.Kind), event.DedupeKey, maxDeliveryAttempts).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return uuid.Nil, false, nil
+	}
+	if err != nil {
+		return uuid.Nil, false, fmt.Errorf("claim delivery: %w", err)
+	}
+	return id, true, nil
+}
+
+// retireTarget stops delivering to an address the provider rejected for
+// good. A push device is invalidated everywhere it is subscribed; any other
+// channel is disabled on that subscription only.
+func (d *Dispatcher) retireTarget(ctx context.Context, t target, reason error) error {
+	var err error
+	if t.pushDeviceID != nil {
+		_, err = d.db.ExecContext(ctx, `
+			UPDATE push_devices
+			SET invalidated_at = now(), invalid_reason = $2, updated_at = now()
+			WHERE id = $1 AND invalidated_at IS NULL
+		`, *t.pushDeviceID, reason.Error())
+	} else {
+		_, err = d.db.ExecContext(ctx, `
+			UPDATE notification_subscription_channels
+			SET enabled = false
+			WHERE subscription_id = $1 AND channel = $2
+		`, t.subscriptionID, string(t.channel))
+	}
+	if err != nil {
+		return fmt.Errorf("retire %s target: %w", t.channel, err)
+	}
+	return nil
+}
+
+func (d *Dispatcher) finishDelivery(ctx context.Context, deliveryID uuid.UUID, sendErr error) error {
+	var err error
+	if sendErr == nil {
+		_, err = d.db.ExecContext(ctx, `
+			UPDATE notification_deliveries
+			SET status = 'sent', last_error = NULL, delivered_at = now()
+			WHERE id = $1
+		`, deliveryID)
+	} else {
+		_, err = d.db.ExecContext(ctx, `
+			UPDATE notification_deliveries
+			SET status = 'failed', last_error = $2
+			WHERE id = $1
+		`, deliveryID, sendErr.Error())
+	}
+	if err != nil {
+		return fmt.Errorf("record delivery: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+type PushPlatform string
+
+const (
+	PlatformAPNs PushPlatform = "apns"
+	PlatformFCM  PushPlatform = "fcm"
+)
+
+func ParsePushPlatform(raw string) (PushPlatform, bool) {
+	switch PushPlatform(raw) {
+	case PlatformAPNs, PlatformFCM:
+		return PushPlatform(raw), true
+	}
+	return "", false
+}
+
+// PushAddress is how a registered device is addressed on the push channel:
+// the gateway needs the platform to pick the provider.
+func PushAddress(platform PushPlatform, token string) string {
+	return string(platform) + ":" + token
+}
+
+// PushNotifier relays messages to a push gateway that fans out to APNs and
+// FCM. The gateway answers 404 or 410 for tokens the provider reported as
+// unregistered, which surfaces as ErrAddressInvalid.
+type PushNotifier struct {
+	GatewayURL string
+	AuthToken  string
+	Client     *http.Client
+}
+
+func (n *PushNotifier) Channel() Channel { return ChannelPush }
+
+func (n *PushNotifier) Send(ctx context.Context, address string, msg Message) error {
+	platform, token, ok := strings.Cut(address, ":")
+	if !ok {
+		return fmt.Errorf("%w: push address must be platform:token", ErrAddressInvalid)
+	}
+
+	var headers map[string]string
+	if n.AuthToken != "" {
+		headers = map[string]string{"Authorization": "Bearer " + n.AuthToken}
+	}
+	err := postJSON(ctx, n.Client, n.GatewayURL, headers, map[string]any{
+		"platform": platform,
+		"to":       token,
+		"title":    msg.Subject,
+		"body":     msg.Body,
+		"data": map[string]string{
+			"kind":          string(msg.Event.Kind),
+			"decision_slug": msg.Event.DecisionSlug,
+			"share_url":     msg.Event.ShareURL,
+		},
+	})
+
+	var statusErr *statusError
+	if errors.As(err, &statusErr) && (statusErr.Code == http.StatusNotFound || statusErr.Code == http.StatusGone) {
+		return fmt.Errorf("%w: %v", ErrAddressInvalid, err)
+	}
+	return err
+}
@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const slackAPIBaseURL = "https://slack.com/api/"
+
+// SlackNotifier sends direct messages through a bot token. The subscription
+// address is the Slack member ID, which chat.postMessage accepts as a DM
+// channel.
+type SlackNotifier struct {
+	BotToken string
+	Client   *http.Client
+}
+
+func (n *SlackNotifier) Channel() Channel { return ChannelSlack }
+
+func (n *SlackNotifier) Send(ctx context.Context, address string, msg Message) error {
+	return CallSlack(ctx, n.Client, n.BotToken, "chat.postMessage", map[string]any{
+		"channel": address,
+		"text":    "*" + msg.Subject + "*\n" + msg.Body,
+	})
+}
+
+// CallSlack invokes a Slack Web API method such as chat.postMessage with a
+// JSON payload and a bot token.
+func CallSlack(ctx context.Context, client *http.Client, botToken, method string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, httpSendTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, slackAPIBaseURL+method, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+botToken)
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Slack reports most failures with a 200 and ok=false.
+	var out struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&out); err != nil {
+		return fmt.Errorf("decode slack response (status %d): %w", resp.StatusCode, err)
+	}
+	if !out.OK {
+		if out.Error == "" {
+			return fmt.Errorf("slack rejected %s", method)
+		}
+		return fmt.Errorf("slack: %s", out.Error)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package notify
+
+import (
+	"bytes"
+	"fmt"
+	"text/template"
+)
+
+type messageTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+type Templates struct {
+	byKind map[Kind]messageTemplate
+}
+
+var defaultTemplateSources = map[Kind][2]string{
+	KindDecisionClosed: {
+		`Results are in: {{.DecisionTitle}}`,
+		`"{{.DecisionTitle}}" has closed with {{index .Data "response_count"}} responses.
+The crowd says: {{index .Data "recommendation"}}.
+
+See the full results: {{.ShareURL}}`,
+	},
+	KindMilestone: {
+		`{{index .Data "response_count"}} people weighed in on {{.DecisionTitle}}`,
+		`"{{.DecisionTitle}}" just reached {{index .Data "response_count"}} responses.
+
+Check in on how it's trending: {{.ShareURL}}`,
+	},
+	KindReminder: {
+		`Closing soon: {{.DecisionTitle}}`,
+		`"{{.DecisionTitle}}" closes {{index .Data "closes_in"}} and has {{index .Data "response_count"}} responses so far.
+
+Share it again to get more input: {{.ShareURL}}`,
+	},
+}
+
+func DefaultTemplates() *Templates {
+	t := &Templates{byKind: make(map[Kind]messageTemplate, len(defaultTemplateSources))}
+	for kind, src := range defaultTemplateSources {
+		t.byKind[kind] = messageTemplate{
+			subject: template.Must(template.New(string(kind) + ".subject").Parse(src[0])),
+			body:    template.Must(template.New(string(kind) + ".body").Parse(src[1])),
+		}
+	}
+	return t
+}
+
+func (t *Templates) Render(event Event) (Message, error) {
+	tmpl, ok := t.byKind[event.Kind]
+	if !ok {
+		return Message{}, fmt.Errorf("no template for notification kind %q", event.Kind)
+	}
+
+	var subject, body bytes.Buffer
+	if err := tmpl.subject.Execute(&subject, event); err != nil {
+		return Message{}, fmt.Errorf("render %s subject: %w", event.Kind, err)
+	}
+	if err := tmpl.body.Execute(&body, event); err != nil {
+		return Message{}, fmt.Errorf("render %s body: %w", event.Kind, err)
+	}
+
+	return Message{
+		Subject: subject.String(),
+		Body:    body.String(),
+		Event:   event,
+	}, nil
+}
@@ -0,0 +1,93 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const httpSendTimeout = 10 * time.Second
+
+type webhookPayload struct {
+	Kind     Kind            `json:"kind"`
+	Subject  string          `json:"subject"`
+	Body     string          `json:"body"`
+	Decision webhookDecision `json:"decision"`
+	Data     map[string]any  `json:"data,omitempty"`
+}
+
+type webhookDecision struct {
+	ID       string `json:"id"`
+	Slug     string `json:"slug"`
+	Title    string `json:"title"`
+	ShareURL string `json:"share_url"`
+}
+
+type WebhookNotifier struct {
+	Client *http.Client
+}
+
+func (n *WebhookNotifier) Channel() Channel { return ChannelWebhook }
+
+func (n *WebhookNotifier) Send(ctx context.Context, address string, msg Message) error {
+	return postJSON(ctx, n.Client, address, nil, webhookPayload{
+		Kind:    msg.Event.Kind,
+		Subject: msg.Subject,
+		Body:    msg.Body,
+		Decision: webhookDecision{
+			ID:       msg.Event.DecisionID.String(),
+			Slug:     msg.Event.DecisionSlug,
+			Title:    msg.Event.DecisionTitle,
+			ShareURL: msg.Event.ShareURL,
+		},
+		Data: msg.Event.Data,
+	})
+}
+
+type statusError struct {
+	Code int
+	Body string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("unexpected status %d: %s", e.Code, e.Body)
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, httpSendTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return &statusError{Code: resp.StatusCode, Body: string(bytes.TrimSpace(snippet))}
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
@@ -0,0 +1,64 @@
+package projections
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of events written to the decision_events outbox. Writers append in
+// the same transaction as the change they describe, so the read models can
+// always be rebuilt by replaying the outbox from the start.
+const (
+	KindDecisionCreated = "decision_created"
+	KindResponseCreated = "response_created"
+	KindVoteChanged     = "vote_changed"
+	KindResponseDeleted = "response_deleted"
+	KindCategoryChanged = "category_changed"
+)
+
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+type DecisionCreated struct {
+	Category *string `json:"category"`
+}
+
+// CategoryChanged moves a decision, and everything counted for it, to a
+// new category.
+type CategoryChanged struct {
+	Category *string `json:"category"`
+}
+
+type ResponseCreated struct {
+	Rating     int `json:"rating"`
+	Suggestion int `json:"suggestion"`
+}
+
+// ResponseDeleted undoes an earlier ResponseCreated, e.g. when a viewer
+// asks for their data to be erased.
+type ResponseDeleted struct {
+	Rating int `json:"rating"`
+}
+
+// VoteChanged carries the totals after the change rather than the delta,
+// so applying an older event after a newer one can be detected and skipped.
+type VoteChanged struct {
+	VoteSum   int `json:"vote_sum"`
+	VoteCount int `json:"vote_count"`
+}
+
+func Append(ctx context.Context, q Execer, decisionID uuid.UUID, kind string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	_, err = q.ExecContext(ctx, `
+		INSERT INTO decision_events (decision_id, kind, payload)
+		VALUES ($1, $2, $3::jsonb)
+	`, decisionID, kind, string(body))
+	return err
+}
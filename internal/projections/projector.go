@@ -0,0 +1,336 @@
+package projections
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	defaultBatchSize = 500
+	// TrendHalfLife is how quickly activity stops counting towards trending.
+	TrendHalfLife = 24 * time.Hour
+
+	responseTrendWeight = 1.0
+	voteTrendWeight     = 0.5
+)
+
+// Projector consumes unprojected outbox events and folds them into the
+// rm_* read-model tables. Batches are claimed with FOR UPDATE SKIP LOCKED so
+// several server instances can run it side by side.
+type Projector struct {
+	db *sql.DB
+}
+
+func NewProjector(db *sql.DB) *Projector {
+	return &Projector{db: db}
+}
+
+type event struct {
+	id         int64
+	decisionID uuid.UUID
+	kind       string
+	payload    []byte
+	createdAt  time.Time
+}
+
+// ProcessPending projects batches until no pending events remain and returns
+// how many events were applied.
+func (p *Projector) ProcessPending(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		n, err := p.processBatch(ctx, defaultBatchSize)
+		total += n
+		if err != nil || n < defaultBatchSize {
+			return total, err
+		}
+	}
+}
+
+// Rebuild wipes the read models and replays the whole outbox into them.
+func (p *Projector) Rebuild(ctx context.Context) (int, error) {
+	if _, err := p.db.ExecContext(ctx, `
+		TRUNCATE rm_decision_activity, rm_category_insights;
+		UPDATE decision_events SET projected_at = NULL WHERE projected_at IS NOT NULL;
+	`); err != nil {
+		return 0, fmt.Errorf("reset read models: %w", err)
+	}
+	return p.ProcessPending(ctx)
+}
+
+func (p *Projector) processBatch(ctx context.Context, limit int) (int, error) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id, decision_id, kind, payload, created_at
+		FROM decision_events
+		WHERE projected_at IS NULL
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED
+	`, limit)
+	if err != nil {
+		return 0, err
+	}
+	var events []event
+	for rows.Next() {
+		var e event
+		if err := rows.Scan(&e.id, &e.decisionID, &e.kind, &e.payload, &e.createdAt); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		events = append(events, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(events) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]int64, 0, len(events))
+	for _, e := range events {
+		if err := apply(ctx, tx, e); err != nil {
+			return 0, fmt.Errorf("apply event %d (%s): %w", e.id, e.kind, err)
+		}
+		ids = append(ids, e.id)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE decision_events SET projected_at = now() WHERE id = ANY($1)
+	`, ids); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return len(events), nil
+}
+
+func apply(ctx context.Context, tx *sql.Tx, e event) error {
+	switch e.kind {
+	case KindDecisionCreated:
+		var payload DecisionCreated
+		if err := json.Unmarshal(e.payload, &payload); err != nil {
+			return err
+		}
+		return applyDecisionCreated(ctx, tx, e, payload)
+	case KindResponseCreated:
+		var payload ResponseCreated
+		if err := json.Unmarshal(e.payload, &payload); err != nil {
+			return err
+		}
+		return applyResponseCreated(ctx, tx, e, payload)
+	case KindVoteChanged:
+		var payload VoteChanged
+		if err := json.Unmarshal(e.payload, &payload); err != nil {
+			return err
+		}
+		return applyVoteChanged(ctx, tx, e, payload)
+	case KindResponseDeleted:
+		var payload ResponseDeleted
+		if err := json.Unmarshal(e.payload, &payload); err != nil {
+			return err
+		}
+		return applyResponseDeleted(ctx, tx, e, payload)
+	case KindCategoryChanged:
+		var payload CategoryChanged
+		if err := json.Unmarshal(e.payload, &payload); err != nil {
+			return err
+		}
+		return applyCategoryChanged(ctx, tx, e, payload)
+	default:
+		// Unknown kinds come from newer writers; skip rather than wedge
+		// the projector.
+		return nil
+	}
+}
+
+func applyDecisionCreated(ctx context.Context, tx *sql.Tx, e event, payload DecisionCreated) error {
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO rm_decision_activity (decision_id, category, created_at, trend_at, last_activity_at)
+		VALUES ($1, $2, $3, $3, $3)
+		ON CONFLICT (decision_id) DO NOTHING
+	`, e.decisionID, payload.Category, e.createdAt)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil
+	}
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO rm_category_insights (category, decision_count)
+		VALUES (COALESCE($1, 'uncategorized'), 1)
+		ON CONFLICT (category) DO UPDATE SET
+			decision_count = rm_category_insights.decision_count + 1,
+			updated_at = now()
+	`, payload.Category)
+	return err
+}
+
+func applyResponseCreated(ctx context.Context, tx *sql.Tx, e event, payload ResponseCreated) error {
+	if err := bumpActivity(ctx, tx, e, responseTrendWeight, `
+		response_count = a.response_count + 1,
+		rating_sum = a.rating_sum + $4,
+	`, payload.Rating); err != nil {
+		return err
+	}
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO rm_category_insights (category, response_count, rating_sum)
+		SELECT COALESCE(category, 'uncategorized'), 1, $2
+		FROM rm_decision_activity WHERE decision_id = $1
+		ON CONFLICT (category) DO UPDATE SET
+			response_count = rm_category_insights.response_count + 1,
+			rating_sum = rm_category_insights.rating_sum + EXCLUDED.rating_sum,
+			updated_at = now()
+	`, e.decisionID, payload.Rating)
+	return err
+}
+
+// applyResponseDeleted takes a response back out of the counts. It does not
+// touch the trend score: a deletion is not activity.
+func applyResponseDeleted(ctx context.Context, tx *sql.Tx, e event, payload ResponseDeleted) error {
+	result, err := tx.ExecContext(ctx, `
+		UPDATE rm_decision_activity SET
+			response_count = GREATEST(response_count - 1, 0),
+			rating_sum = rating_sum - $2,
+			updated_at = now()
+		WHERE decision_id = $1
+	`, e.decisionID, payload.Rating)
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE rm_category_insights c SET
+			response_count = GREATEST(c.response_count - 1, 0),
+			rating_sum = c.rating_sum - $2,
+			updated_at = now()
+		FROM rm_decision_activity a
+		WHERE a.decision_id = $1 AND c.category = COALESCE(a.category, 'uncategorized')
+	`, e.decisionID, payload.Rating)
+	return err
+}
+
+// applyCategoryChanged takes the decision's counts out of its old category's
+// insights and adds them to the new one's.
+func applyCategoryChanged(ctx context.Context, tx *sql.Tx, e event, payload CategoryChanged) error {
+	var (
+		from                            string
+		responseCount, ratingSum, votes int
+	)
+	err := tx.QueryRowContext(ctx, `
+		SELECT COALESCE(category, 'uncategorized'), response_count, rating_sum, vote_count
+		FROM rm_decision_activity
+		WHERE decision_id = $1
+		FOR UPDATE
+	`, e.decisionID).Scan(&from, &responseCount, &ratingSum, &votes)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	to := "uncategorized"
+	if payload.Category != nil {
+		to = *payload.Category
+	}
+	if from == to {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE rm_category_insights SET
+			decision_count = GREATEST(decision_count - 1, 0),
+			response_count = GREATEST(response_count - $2, 0),
+			rating_sum = rating_sum - $3,
+			vote_count = vote_count - $4,
+			updated_at = now()
+		WHERE category = $1
+	`, from, responseCount, ratingSum, votes); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO rm_category_insights (category, decision_count, response_count, rating_sum, vote_count)
+		VALUES ($1, 1, $2, $3, $4)
+		ON CONFLICT (category) DO UPDATE SET
+			decision_count = rm_category_insights.decision_count + 1,
+			response_count = rm_category_insights.response_count + EXCLUDED.response_count,
+			rating_sum = rm_category_insights.rating_sum + EXCLUDED.rating_sum,
+			vote_count = rm_category_insights.vote_count + EXCLUDED.vote_count,
+			updated_at = now()
+	`, to, responseCount, ratingSum, votes); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE rm_decision_activity SET category = $2, updated_at = now() WHERE decision_id = $1
+	`, e.decisionID, payload.Category)
+	return err
+}
+
+// applyVoteChanged stores the absolute totals from the newest vote event
+// seen for the decision; the category total is adjusted by the difference.
+func applyVoteChanged(ctx context.Context, tx *sql.Tx, e event, payload VoteChanged) error {
+	var previousCount int
+	err := tx.QueryRowContext(ctx, `
+		SELECT vote_count FROM rm_decision_activity
+		WHERE decision_id = $1 AND votes_event_id < $2
+		FOR UPDATE
+	`, e.decisionID, e.id).Scan(&previousCount)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := bumpActivity(ctx, tx, e, voteTrendWeight, `
+		vote_score = $4,
+		vote_count = $5,
+		votes_event_id = $6,
+	`, payload.VoteSum, payload.VoteCount, e.id); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE rm_category_insights c
+		SET vote_count = c.vote_count + $2, updated_at = now()
+		FROM rm_decision_activity a
+		WHERE a.decision_id = $1 AND c.category = COALESCE(a.category, 'uncategorized')
+	`, e.decisionID, payload.VoteCount-previousCount)
+	return err
+}
+
+// bumpActivity applies set (which must end with a comma and may use $4
+// onwards) together with the trend update. The trend score decays with
+// TrendHalfLife; events older than the current trend timestamp are decayed
+// on the way in so replay order does not change the result.
+func bumpActivity(ctx context.Context, tx *sql.Tx, e event, weight float64, set string, args ...any) error {
+	halfLife := strconv.FormatFloat(TrendHalfLife.Seconds(), 'f', -1, 64)
+	_, err := tx.ExecContext(ctx, `
+		UPDATE rm_decision_activity a SET
+			`+set+`
+			trend_score = CASE
+				WHEN $2 >= a.trend_at
+					THEN a.trend_score * power(0.5, EXTRACT(EPOCH FROM ($2 - a.trend_at))::float8 / `+halfLife+`) + $3::float8
+				ELSE a.trend_score + $3::float8 * power(0.5, EXTRACT(EPOCH FROM (a.trend_at - $2))::float8 / `+halfLife+`)
+			END,
+			trend_at = GREATEST(a.trend_at, $2),
+			last_activity_at = GREATEST(a.last_activity_at, $2),
+			updated_at = now()
+		WHERE a.decision_id = $1
+	`, append([]any{e.decisionID, e.createdAt, weight}, args...)...)
+	return err
+}
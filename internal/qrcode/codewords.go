@@ -0,0 +1,152 @@
+package qrcode
+
+// blockLayout is how a version's codewords are split into Reed-Solomon
+// blocks at level M: shortBlocks blocks of shortData data codewords, then
+// longBlocks blocks of one more, each followed by ecPerBlock error
+// correction codewords.
+type blockLayout struct {
+	ecPerBlock  int
+	shortBlocks int
+	shortData   int
+	longBlocks  int
+}
+
+func (l blockLayout) dataCodewords() int {
+	return l.shortBlocks*l.shortData + l.longBlocks*(l.shortData+1)
+}
+
+// blockLayouts is indexed by version; level M only.
+var blockLayouts = [maxVersion + 1]blockLayout{
+	1:  {10, 1, 16, 0},
+	2:  {16, 1, 28, 0},
+	3:  {26, 1, 44, 0},
+	4:  {18, 2, 32, 0},
+	5:  {24, 2, 43, 0},
+	6:  {16, 4, 27, 0},
+	7:  {18, 4, 31, 0},
+	8:  {22, 2, 38, 2},
+	9:  {22, 3, 36, 2},
+	10: {26, 4, 43, 1},
+	11: {30, 1, 50, 4},
+	12: {22, 6, 36, 2},
+	13: {22, 8, 37, 1},
+	14: {24, 4, 40, 5},
+	15: {24, 5, 41, 5},
+	16: {28, 7, 45, 3},
+	17: {28, 10, 46, 1},
+	18: {26, 9, 43, 4},
+	19: {26, 3, 44, 11},
+	20: {26, 3, 41, 13},
+}
+
+// codewords builds the final codeword sequence for data: the byte mode
+// segment padded to the version's capacity, split into blocks, with error
+// correction appended and everything interleaved.
+func codewords(version int, data []byte) []byte {
+	layout := blockLayouts[version]
+
+	var bits bitBuffer
+	bits.append(0b0100, 4) // byte mode
+	bits.append(len(data), countBits(version))
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+	capacity := 8 * layout.dataCodewords()
+	bits.append(0, min(4, capacity-len(bits))) // terminator
+	bits.append(0, (8-len(bits)%8)%8)
+	for pad := 0xEC; len(bits) < capacity; pad ^= 0xEC ^ 0x11 {
+		bits.append(pad, 8)
+	}
+	dataWords := bits.bytes()
+
+	blocks := make([][]byte, 0, layout.shortBlocks+layout.longBlocks)
+	ecBlocks := make([][]byte, 0, cap(blocks))
+	divisor := rsDivisor(layout.ecPerBlock)
+	for i := 0; i < layout.shortBlocks+layout.longBlocks; i++ {
+		n := layout.shortData
+		if i >= layout.shortBlocks {
+			n++
+		}
+		block := dataWords[:n]
+		dataWords = dataWords[n:]
+		blocks = append(blocks, block)
+		ecBlocks = append(ecBlocks, rsRemainder(block, divisor))
+	}
+
+	out := make([]byte, 0, layout.dataCodewords()+len(blocks)*layout.ecPerBlock)
+	for i := 0; i <= layout.shortData; i++ {
+		for _, block := range blocks {
+			if i < len(block) {
+				out = append(out, block[i])
+			}
+		}
+	}
+	for i := 0; i < layout.ecPerBlock; i++ {
+		for _, ec := range ecBlocks {
+			out = append(out, ec[i])
+		}
+	}
+	return out
+}
+
+// bitBuffer is a sequence of bits, most significant first.
+type bitBuffer []bool
+
+func (b *bitBuffer) append(value, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, value>>i&1 == 1)
+	}
+}
+
+func (b bitBuffer) bytes() []byte {
+	out := make([]byte, len(b)/8)
+	for i, bit := range b {
+		if bit {
+			out[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+	return out
+}
+
+// rsDivisor returns the coefficients of the Reed-Solomon generator
+// polynomial of the given degree, highest power first with the leading 1
+// left out.
+func rsDivisor(degree int) []byte {
+	divisor := make([]byte, degree)
+	divisor[degree-1] = 1
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range divisor {
+			divisor[j] = gfMul(divisor[j], root)
+			if j+1 < degree {
+				divisor[j] ^= divisor[j+1]
+			}
+		}
+		root = gfMul(root, 0x02)
+	}
+	return divisor
+}
+
+// rsRemainder returns the error correction codewords for data.
+func rsRemainder(data, divisor []byte) []byte {
+	remainder := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ remainder[0]
+		copy(remainder, remainder[1:])
+		remainder[len(remainder)-1] = 0
+		for i, coef := range divisor {
+			remainder[i] ^= gfMul(coef, factor)
+		}
+	}
+	return remainder
+}
+
+// gfMul multiplies in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1.
+func gfMul(x, y byte) byte {
+	var z int
+	for i := 7; i >= 0; i-- {
+		z = z<<1 ^ (z>>7)*0x11D
+		z ^= int(y>>i&1) * int(x)
+	}
+	return byte(z)
+}
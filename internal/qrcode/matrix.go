@@ -0,0 +1,205 @@
+package qrcode
+
+// matrix is a code being drawn. function marks the modules that belong to
+// finder, timing, alignment, format and version patterns, which data and
+// masks leave alone.
+type matrix struct {
+	version  int
+	size     int
+	dark     []bool
+	function []bool
+}
+
+func newMatrix(version int) *matrix {
+	size := 17 + 4*version
+	return &matrix{
+		version:  version,
+		size:     size,
+		dark:     make([]bool, size*size),
+		function: make([]bool, size*size),
+	}
+}
+
+func (m *matrix) setFunction(x, y int, dark bool) {
+	m.dark[y*m.size+x] = dark
+	m.function[y*m.size+x] = true
+}
+
+func (m *matrix) drawFunctionPatterns() {
+	for i := 0; i < m.size; i++ {
+		m.setFunction(6, i, i%2 == 0)
+		m.setFunction(i, 6, i%2 == 0)
+	}
+
+	m.drawFinder(3, 3)
+	m.drawFinder(m.size-4, 3)
+	m.drawFinder(3, m.size-4)
+
+	positions := alignmentPositions(m.version)
+	last := len(positions) - 1
+	for i, x := range positions {
+		for j, y := range positions {
+			// Skip the three corners the finders occupy.
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			m.drawAlignment(x, y)
+		}
+	}
+
+	// Reserve the format areas; drawFormatBits fills them in per mask.
+	m.drawFormatBits(0)
+	m.drawVersionBits()
+}
+
+// drawFinder draws a finder pattern centred on x, y, with its separator.
+func (m *matrix) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || xx >= m.size || yy < 0 || yy >= m.size {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			m.setFunction(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+func (m *matrix) drawAlignment(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			m.setFunction(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// alignmentPositions returns the row and column centres of the version's
+// alignment patterns, evenly spaced back from the far edge.
+func alignmentPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+	size := 17 + 4*version
+	count := version/7 + 2
+	step := (size - 13 + 2*count - 3) / (2*count - 2) * 2
+	positions := make([]int, count)
+	positions[0] = 6
+	for i, pos := count-1, size-7; i >= 1; i, pos = i-1, pos-step {
+		positions[i] = pos
+	}
+	return positions
+}
+
+// drawFormatBits draws both copies of the format information: level M
+// and mask, BCH protected.
+func (m *matrix) drawFormatBits(mask int) {
+	data := 0b00<<3 | mask // 0b00 is level M
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+	bit := func(i int) bool { return bits>>i&1 == 1 }
+
+	for i := 0; i <= 5; i++ {
+		m.setFunction(8, i, bit(i))
+	}
+	m.setFunction(8, 7, bit(6))
+	m.setFunction(8, 8, bit(7))
+	m.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		m.setFunction(14-i, 8, bit(i))
+	}
+
+	for i := 0; i < 8; i++ {
+		m.setFunction(m.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		m.setFunction(8, m.size-15+i, bit(i))
+	}
+	m.setFunction(8, m.size-8, true) // the dark module
+}
+
+// drawVersionBits draws both copies of the version information, which
+// versions 7 and up carry.
+func (m *matrix) drawVersionBits() {
+	if m.version < 7 {
+		return
+	}
+	rem := m.version
+	for i := 0; i < 12; i++ {
+		rem = rem<<1 ^ (rem>>11)*0x1F25
+	}
+	bits := m.version<<12 | rem
+	for i := 0; i < 18; i++ {
+		dark := bits>>i&1 == 1
+		a, b := m.size-11+i%3, i/3
+		m.setFunction(a, b, dark)
+		m.setFunction(b, a, dark)
+	}
+}
+
+// drawCodewords places data in the zigzag order: pairs of columns from the
+// right, alternately upwards and downwards, skipping the vertical timing
+// pattern. Any remainder bits are left light.
+func (m *matrix) drawCodewords(data []byte) {
+	i := 0
+	for right := m.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < m.size; vert++ {
+			y := vert
+			if upward {
+				y = m.size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if m.function[y*m.size+x] || i >= len(data)*8 {
+					continue
+				}
+				m.dark[y*m.size+x] = data[i/8]>>(7-i%8)&1 == 1
+				i++
+			}
+		}
+	}
+}
+
+// applyMask flips every data module the mask pattern selects.
+func (m *matrix) applyMask(mask int) {
+	for y := 0; y < m.size; y++ {
+		for x := 0; x < m.size; x++ {
+			var flip bool
+			switch mask {
+			case 0:
+				flip = (x+y)%2 == 0
+			case 1:
+				flip = y%2 == 0
+			case 2:
+				flip = x%3 == 0
+			case 3:
+				flip = (x+y)%3 == 0
+			case 4:
+				flip = (x/3+y/2)%2 == 0
+			case 5:
+				flip = x*y%2+x*y%3 == 0
+			case 6:
+				flip = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				flip = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if flip && !m.function[y*m.size+x] {
+				m.dark[y*m.size+x] = !m.dark[y*m.size+x]
+			}
+		}
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,100 @@
+package qrcode
+
+// Penalty weights from the spec's mask evaluation.
+const (
+	penaltyRun     = 3
+	penaltyBlock   = 3
+	penaltyFinder  = 40
+	penaltyBalance = 10
+)
+
+// penalty scores the current mask the way the spec does: long runs of one
+// colour, 2×2 blocks, finder-like patterns and an uneven dark/light balance
+// all make a code harder to scan. Lower is better.
+func (m *matrix) penalty() int {
+	at := func(x, y int) bool { return m.dark[y*m.size+x] }
+	total := 0
+
+	for _, horizontal := range []bool{true, false} {
+		for a := 0; a < m.size; a++ {
+			line := make([]bool, m.size)
+			for b := range line {
+				if horizontal {
+					line[b] = at(b, a)
+				} else {
+					line[b] = at(a, b)
+				}
+			}
+			total += runPenalty(line) + finderPenalty(line)
+		}
+	}
+
+	dark := 0
+	for y := 0; y < m.size; y++ {
+		for x := 0; x < m.size; x++ {
+			if at(x, y) {
+				dark++
+			}
+			if x+1 < m.size && y+1 < m.size {
+				c := at(x, y)
+				if c == at(x+1, y) && c == at(x, y+1) && c == at(x+1, y+1) {
+					total += penaltyBlock
+				}
+			}
+		}
+	}
+
+	// Each full 5% the dark share strays from 50% costs penaltyBalance.
+	cells := m.size * m.size
+	k := (abs(dark*20-cells*10)+cells-1)/cells - 1
+	return total + k*penaltyBalance
+}
+
+// runPenalty scores runs of five or more modules of one colour.
+func runPenalty(line []bool) int {
+	total, run := 0, 0
+	for i, c := range line {
+		if i > 0 && c == line[i-1] {
+			run++
+		} else {
+			run = 1
+		}
+		switch {
+		case run == 5:
+			total += penaltyRun
+		case run > 5:
+			total++
+		}
+	}
+	return total
+}
+
+// finderPenalty scores dark-light-dark-dark-dark-light-dark sequences in
+// 1:1:3:1:1 proportion with four light modules on either side, the edge of
+// the code counting as light.
+func finderPenalty(line []bool) int {
+	pattern := []bool{true, false, true, true, true, false, true}
+	light := func(i int) bool { return i < 0 || i >= len(line) || !line[i] }
+	total := 0
+	for start := 0; start+len(pattern) <= len(line); start++ {
+		match := true
+		for i, want := range pattern {
+			if line[start+i] != want {
+				match = false
+				break
+			}
+		}
+		if !match {
+			continue
+		}
+		before, after := true, true
+		for i := 1; i <= 4; i++ {
+			before = before && light(start-i)
+			after = after && light(start+len(pattern)-1+i)
+		}
+		if before || after {
+			total += penaltyFinder
+		}
+	}
+	return total
+}
@@ -0,0 +1,108 @@
+// Package qrcode encodes short strings, like share links, as QR codes and
+// renders them to PNG. It implements just enough of ISO/IEC 18004 for that:
+// byte mode at error correction level M, versions 1 through 20 (up to 666
+// bytes). Other modes, levels and ECI are not supported.
+package qrcode
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+// QuietZone is the light border, in modules, that scanners need around a
+// code.
+const QuietZone = 4
+
+const maxVersion = 20
+
+// ErrTooLong is returned for data that does not fit the largest supported
+// version.
+var ErrTooLong = errors.New("qrcode: data too long")
+
+// Code is an encoded QR code: Size×Size modules, not counting the quiet
+// zone.
+type Code struct {
+	Size    int
+	modules []bool
+}
+
+// Dark reports whether the module at column x, row y is dark.
+func (c *Code) Dark(x, y int) bool {
+	return c.modules[y*c.Size+x]
+}
+
+// Encode encodes data in the smallest version it fits.
+func Encode(data []byte) (*Code, error) {
+	version := 0
+	for v := 1; v <= maxVersion; v++ {
+		if 4+countBits(v)+8*len(data) <= 8*blockLayouts[v].dataCodewords() {
+			version = v
+			break
+		}
+	}
+	if version == 0 {
+		return nil, ErrTooLong
+	}
+
+	m := newMatrix(version)
+	m.drawFunctionPatterns()
+	m.drawCodewords(codewords(version, data))
+
+	best, bestPenalty := -1, 0
+	for mask := 0; mask < 8; mask++ {
+		m.applyMask(mask)
+		m.drawFormatBits(mask)
+		if penalty := m.penalty(); best < 0 || penalty < bestPenalty {
+			best, bestPenalty = mask, penalty
+		}
+		m.applyMask(mask) // masks are their own inverse
+	}
+	m.applyMask(best)
+	m.drawFormatBits(best)
+	return &Code{Size: m.size, modules: m.dark}, nil
+}
+
+// MinImageSize is the smallest PNG size that gives every module, quiet
+// zone included, a pixel.
+func (c *Code) MinImageSize() int {
+	return c.Size + 2*QuietZone
+}
+
+// PNG writes the code as a black on white PNG of exactly size×size pixels,
+// quiet zone included. Modules are scaled by a whole number of pixels so
+// they stay crisp; what is left over widens the border. It fails if size is
+// below MinImageSize.
+func (c *Code) PNG(w io.Writer, size int) error {
+	scale := size / c.MinImageSize()
+	if scale < 1 {
+		return errors.New("qrcode: image too small for code")
+	}
+	offset := (size - scale*c.Size) / 2
+
+	img := image.NewPaletted(image.Rect(0, 0, size, size), color.Palette{color.White, color.Black})
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if !c.Dark(x, y) {
+				continue
+			}
+			for dy := 0; dy < scale; dy++ {
+				row := img.Pix[(offset+y*scale+dy)*img.Stride:]
+				for dx := 0; dx < scale; dx++ {
+					row[offset+x*scale+dx] = 1
+				}
+			}
+		}
+	}
+	return png.Encode(w, img)
+}
+
+// countBits is the width of the byte mode character count for version.
+func countBits(version int) int {
+	if version < 10 {
+		return 8
+	}
+	return 16
+}
@@ -0,0 +1,441 @@
+package qrcode
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image/png"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// The tables below are copied from ISO/IEC 18004 rather than computed, so
+// they check the encoder instead of repeating it.
+
+// formatInfoM is the format information for level M, indexed by mask.
+var formatInfoM = [8]int{
+	0b101010000010010,
+	0b101000100100101,
+	0b101111001111100,
+	0b101101101001011,
+	0b100010111111001,
+	0b100000011001110,
+	0b100111110010111,
+	0b100101010100000,
+}
+
+// versionInfo is the version information for versions 7 and up.
+var versionInfo = map[int]int{
+	7:  0b000111110010010100,
+	8:  0b001000010110111100,
+	9:  0b001001101010011001,
+	10: 0b001010010011010011,
+	11: 0b001011101111110110,
+	12: 0b001100011101100010,
+	13: 0b001101100001000111,
+	14: 0b001110011000001101,
+	15: 0b001111100100101000,
+	16: 0b010000101101111000,
+	17: 0b010001010001011101,
+	18: 0b010010101000010111,
+	19: 0b010011010100110010,
+	20: 0b010100100110100110,
+}
+
+// totalCodewords is the number of codewords each version holds, and
+// remainderBits the bits left over after them.
+var (
+	totalCodewords = [maxVersion + 1]int{0, 26, 44, 70, 100, 134, 172, 196, 242, 292, 346, 404, 466, 532, 581, 655, 733, 815, 901, 991, 1085}
+	remainderBits  = [maxVersion + 1]int{0, 0, 7, 7, 7, 7, 7, 0, 0, 0, 0, 0, 0, 0, 3, 3, 3, 3, 3, 3, 3}
+)
+
+var alignmentTable = map[int][]int{
+	1: nil, 2: {6, 18}, 3: {6, 22}, 4: {6, 26}, 5: {6, 30}, 6: {6, 34},
+	7: {6, 22, 38}, 8: {6, 24, 42}, 9: {6, 26, 46}, 10: {6, 28, 50},
+	11: {6, 30, 54}, 12: {6, 32, 58}, 13: {6, 34, 62},
+	14: {6, 26, 46, 66}, 15: {6, 26, 48, 70}, 16: {6, 26, 50, 74},
+	17: {6, 30, 54, 78}, 18: {6, 30, 56, 82}, 19: {6, 30, 58, 86}, 20: {6, 34, 62, 90},
+}
+
+func TestAlignmentPositions(t *testing.T) {
+	for version := 1; version <= maxVersion; version++ {
+		if got, want := alignmentPositions(version), alignmentTable[version]; !slices.Equal(got, want) {
+			t.Errorf("version %d: got %v, want %v", version, got, want)
+		}
+	}
+}
+
+func TestBlockLayoutsFillTheSymbol(t *testing.T) {
+	for version := 1; version <= maxVersion; version++ {
+		l := blockLayouts[version]
+		total := l.dataCodewords() + (l.shortBlocks+l.longBlocks)*l.ecPerBlock
+		if total != totalCodewords[version] {
+			t.Errorf("version %d: layout holds %d codewords, want %d", version, total, totalCodewords[version])
+		}
+	}
+}
+
+// The 1-M example from the standard's walkthrough of "HELLO WORLD" in
+// alphanumeric mode.
+func TestReedSolomon(t *testing.T) {
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+	if got := rsRemainder(data, rsDivisor(len(want))); !bytes.Equal(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+// golden is "rmld.app/d/abc" at version 1-M, as # for dark and . for
+// light. It pins the mask choice as well as the layout.
+const golden = `
+#######.#.#...#######
+#.....#..###..#.....#
+#.###.#.#..##.#.###.#
+#.###.#.......#.###.#
+#.###.#..#.##.#.###.#
+#.....#.##....#.....#
+#######.#.#.#.#######
+.........#.##........
+#.#...##...##..#..#.#
+####.#..###.#..####.#
+.##.#####...###.#.#.#
+....#..####.#....#..#
+.#.#..#.#.####.###.##
+........#...#########
+#######.###.#...###.#
+#.....#....#.#.#.#..#
+#.###.#...####.###...
+#.###.#.....##..##...
+#.###.#.#.#.###.#####
+#.....#...###...#....
+#######.##...#...#..#`
+
+func TestEncodeVersion1(t *testing.T) {
+	code, err := Encode([]byte("rmld.app/d/abc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := render(code), strings.TrimPrefix(golden, "\n"); got != want {
+		t.Fatalf("got\n%s\nwant\n%s", got, want)
+	}
+	if got := decode(t, code); got != "rmld.app/d/abc" {
+		t.Fatalf("decoded %q", got)
+	}
+}
+
+func TestEncodeVersionInformation(t *testing.T) {
+	// 200 bytes need version 10, the first with a 16-bit count.
+	code, err := Encode(bytes.Repeat([]byte("rmld"), 50))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code.Size != 57 {
+		t.Fatalf("size = %d, want 57 (version 10)", code.Size)
+	}
+	if got := decode(t, code); got != strings.Repeat("rmld", 50) {
+		t.Fatalf("decoded %q", got)
+	}
+
+	// Every version's function patterns carry its own information.
+	for version := 7; version <= maxVersion; version++ {
+		m := newMatrix(version)
+		m.drawFunctionPatterns()
+		c := &Code{Size: m.size, modules: m.dark}
+		if a, b := versionBits(c); a != versionInfo[version] || b != versionInfo[version] {
+			t.Errorf("version %d: read %018b and %018b, want %018b", version, a, b, versionInfo[version])
+		}
+	}
+}
+
+func TestEncodeRoundTrip(t *testing.T) {
+	// Lengths on either side of several version boundaries, up to the
+	// largest that fits.
+	for _, n := range []int{0, 1, 14, 15, 26, 27, 84, 85, 106, 107, 180, 213, 214, 450, 666} {
+		data := make([]byte, n)
+		for i := range data {
+			data[i] = byte(i*37 + n)
+		}
+		code, err := Encode(data)
+		if err != nil {
+			t.Fatalf("%d bytes: %v", n, err)
+		}
+		if got := decode(t, code); got != string(data) {
+			t.Fatalf("%d bytes: decoded %d bytes that differ", n, len(got))
+		}
+	}
+}
+
+func TestEncodeTooLong(t *testing.T) {
+	if _, err := Encode(make([]byte, 667)); !errors.Is(err, ErrTooLong) {
+		t.Fatalf("err = %v, want ErrTooLong", err)
+	}
+}
+
+func TestPNG(t *testing.T) {
+	code, err := Encode([]byte("rmld.app/d/abc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := code.PNG(new(bytes.Buffer), code.MinImageSize()-1); err == nil {
+		t.Fatal("PNG below MinImageSize succeeded")
+	}
+	var buf bytes.Buffer
+	if err := code.PNG(&buf, 100); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 100 || b.Dy() != 100 {
+		t.Fatalf("image is %v, want 100×100", b)
+	}
+	// 21 modules at 3 pixels each leave 37 over, so the code starts 18
+	// pixels in.
+	if r, _, _, _ := img.At(18, 18).RGBA(); r != 0 {
+		t.Fatal("finder corner is not black")
+	}
+	if r, _, _, _ := img.At(17, 17).RGBA(); r == 0 {
+		t.Fatal("quiet zone is not white")
+	}
+}
+
+func render(c *Code) string {
+	var b strings.Builder
+	for y := 0; y < c.Size; y++ {
+		if y > 0 {
+			b.WriteByte('\n')
+		}
+		for x := 0; x < c.Size; x++ {
+			if c.Dark(x, y) {
+				b.WriteByte('#')
+			} else {
+				b.WriteByte('.')
+			}
+		}
+	}
+	return b.String()
+}
+
+// decode reads c back the way a scanner would, failing t if anything in it
+// is not as the standard lays out.
+func decode(t *testing.T, c *Code) string {
+	t.Helper()
+	version := (c.Size - 17) / 4
+	if c.Size != 17+4*version || version < 1 || version > maxVersion {
+		t.Fatalf("size %d is not a version", c.Size)
+	}
+	for _, corner := range [][2]int{{0, 0}, {c.Size - 7, 0}, {0, c.Size - 7}} {
+		for dy := 0; dy < 7; dy++ {
+			for dx := 0; dx < 7; dx++ {
+				ring := max(abs(dx-3), abs(dy-3))
+				if c.Dark(corner[0]+dx, corner[1]+dy) != (ring != 2) {
+					t.Fatalf("finder at %v is wrong at %d,%d", corner, dx, dy)
+				}
+			}
+		}
+	}
+	for i := 8; i < c.Size-8; i++ {
+		if c.Dark(i, 6) != (i%2 == 0) || c.Dark(6, i) != (i%2 == 0) {
+			t.Fatalf("timing pattern is wrong at %d", i)
+		}
+	}
+	if !c.Dark(8, c.Size-8) {
+		t.Fatal("dark module is light")
+	}
+
+	first, second := formatBits(c)
+	if first != second {
+		t.Fatalf("format copies differ: %015b and %015b", first, second)
+	}
+	mask := slices.Index(formatInfoM[:], first)
+	if mask < 0 {
+		t.Fatalf("format %015b is not level M", first)
+	}
+	if version >= 7 {
+		if a, b := versionBits(c); a != versionInfo[version] || b != versionInfo[version] {
+			t.Fatalf("version information %018b and %018b, want %018b", a, b, versionInfo[version])
+		}
+	}
+
+	function := newMatrix(version)
+	function.drawFunctionPatterns()
+	var bits []bool
+	upward := true
+	for right := c.Size - 1; right > 0; right, upward = right-2, !upward {
+		if right == 6 {
+			right--
+		}
+		for i := 0; i < c.Size; i++ {
+			y := i
+			if upward {
+				y = c.Size - 1 - i
+			}
+			for _, x := range []int{right, right - 1} {
+				if !function.function[y*c.Size+x] {
+					bits = append(bits, c.Dark(x, y) != masked(mask, y, x))
+				}
+			}
+		}
+	}
+	layout := blockLayouts[version]
+	if got := len(bits) - 8*totalCodewords[version]; got != remainderBits[version] {
+		t.Fatalf("%d remainder bits, want %d", got, remainderBits[version])
+	}
+	words := make([]byte, totalCodewords[version])
+	for i := range words {
+		for _, bit := range bits[8*i : 8*i+8] {
+			words[i] <<= 1
+			if bit {
+				words[i] |= 1
+			}
+		}
+	}
+
+	// Undo the interleaving and check each block's error correction.
+	blocks := layout.shortBlocks + layout.longBlocks
+	var data []byte
+	for b := 0; b < blocks; b++ {
+		n := layout.shortData
+		if b >= layout.shortBlocks {
+			n++
+		}
+		var block []byte
+		for i := 0; i < n; i++ {
+			index := i * blocks
+			if i == layout.shortData {
+				index = layout.shortData*blocks + b - layout.shortBlocks
+			} else {
+				index += b
+			}
+			block = append(block, words[index])
+		}
+		data = append(data, block...)
+		for i := 0; i < layout.ecPerBlock; i++ {
+			block = append(block, words[layout.dataCodewords()+i*blocks+b])
+		}
+		if s := syndromes(block, layout.ecPerBlock); s != nil {
+			t.Fatalf("block %d has syndromes %v", b, s)
+		}
+	}
+
+	// A byte mode segment, a terminator, then alternating pad codewords.
+	if data[0]>>4 != 0b0100 {
+		t.Fatalf("mode %04b, want byte mode", data[0]>>4)
+	}
+	read := func(offset, n int) int {
+		v := 0
+		for i := offset; i < offset+n; i++ {
+			v = v<<1 | int(data[i/8]>>(7-i%8)&1)
+		}
+		return v
+	}
+	count := read(4, countBits(version))
+	start := 4 + countBits(version)
+	out := make([]byte, count)
+	for i := range out {
+		out[i] = byte(read(start+8*i, 8))
+	}
+	end := start + 8*count
+	if rest := 8*len(data) - end; read(end, min(4, rest)) != 0 {
+		t.Fatal("missing terminator")
+	}
+	for i, pad := (end+4+7)/8, byte(0xEC); i < len(data); i, pad = i+1, pad^0xEC^0x11 {
+		if data[i] != pad {
+			t.Fatalf("codeword %d is %#x, want pad %#x", i, data[i], pad)
+		}
+	}
+	return string(out)
+}
+
+// formatBits reads the two copies of the format information, most
+// significant bit first.
+func formatBits(c *Code) (first, second int) {
+	var firstAt, secondAt [][2]int
+	for y := 0; y <= 8; y++ {
+		if y != 6 {
+			firstAt = append(firstAt, [2]int{8, y})
+		}
+	}
+	for x := 7; x >= 0; x-- {
+		if x != 6 {
+			firstAt = append(firstAt, [2]int{x, 8})
+		}
+	}
+	for i := 0; i < 8; i++ {
+		secondAt = append(secondAt, [2]int{c.Size - 1 - i, 8})
+	}
+	for y := c.Size - 7; y < c.Size; y++ {
+		secondAt = append(secondAt, [2]int{8, y})
+	}
+	return readBits(c, firstAt), readBits(c, secondAt)
+}
+
+// versionBits reads the version information above the bottom left finder
+// and left of the top right one.
+func versionBits(c *Code) (topRight, bottomLeft int) {
+	var topRightAt, bottomLeftAt [][2]int
+	for i := 0; i < 18; i++ {
+		topRightAt = append(topRightAt, [2]int{c.Size - 11 + i%3, i / 3})
+		bottomLeftAt = append(bottomLeftAt, [2]int{i / 3, c.Size - 11 + i%3})
+	}
+	return readBits(c, topRightAt), readBits(c, bottomLeftAt)
+}
+
+// readBits reads modules as bits, least significant first.
+func readBits(c *Code, at [][2]int) int {
+	v := 0
+	for i, p := range at {
+		if c.Dark(p[0], p[1]) {
+			v |= 1 << i
+		}
+	}
+	return v
+}
+
+// masked is the standard's mask condition for row i, column j.
+func masked(mask, i, j int) bool {
+	switch mask {
+	case 0:
+		return (i+j)%2 == 0
+	case 1:
+		return i%2 == 0
+	case 2:
+		return j%3 == 0
+	case 3:
+		return (i+j)%3 == 0
+	case 4:
+		return (i/2+j/3)%2 == 0
+	case 5:
+		return i*j%2+i*j%3 == 0
+	case 6:
+		return (i*j%2+i*j%3)%2 == 0
+	default:
+		return ((i+j)%2+i*j%3)%2 == 0
+	}
+}
+
+// syndromes evaluates block at the generator's roots, 2^0 to 2^(n-1). All
+// of them are zero for a valid block, in which case it returns nil.
+func syndromes(block []byte, n int) []string {
+	var exp [255]byte
+	x := 1
+	for i := range exp {
+		exp[i] = byte(x)
+		if x <<= 1; x >= 0x100 {
+			x ^= 0x11D
+		}
+	}
+	var nonzero []string
+	for i := 0; i < n; i++ {
+		var s byte
+		for _, b := range block {
+			s = gfMul(s, exp[i]) ^ b
+		}
+		if s != 0 {
+			nonzero = append(nonzero, fmt.Sprintf("S%d=%d", i, s))
+		}
+	}
+	return nonzero
+}
@@ -0,0 +1,90 @@
+-- Reaching max_responses closes a decision: its closing time is brought
+-- forward to now and the close job takes it from there.
+-- name: CloseDecisionNow :exec
+UPDATE decisions SET closes_at = now()
+WHERE id = $1 AND (closes_at IS NULL OR closes_at > now());
+
+-- name: CreateDecision :exec
+INSERT INTO decisions (id, slug, title, description, closes_at, creator_token_hash, category, aggregate_only, quorum, visibility, access_code_hash, max_responses, nickname_policy)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13);
+
+-- Hidden decisions are reported as missing everywhere outside moderation.
+-- name: GetDecisionBySlug :one
+SELECT * FROM decisions
+WHERE slug = $1 AND hidden_at IS NULL;
+
+-- GetDecisionRevision reads what a conditional GET needs: the revision and
+-- the fields an access check reads.
+-- name: GetDecisionRevision :one
+SELECT revision, visibility, access_code_hash, creator_token_hash FROM decisions
+WHERE slug = $1 AND hidden_at IS NULL;
+
+-- GetDecisionView reads everything the decision page needs in one round
+-- trip. Responses are aggregated to JSON, newest first, at most
+-- response_limit of them.
+-- name: GetDecisionView :one
+SELECT
+    sqlc.embed(d),
+    COALESCE(st.response_count, 0)::int AS response_count,
+    COALESCE(st.rating_1, 0)::int AS rating_1,
+    COALESCE(st.rating_2, 0)::int AS rating_2,
+    COALESCE(st.rating_3, 0)::int AS rating_3,
+    COALESCE(st.rating_4, 0)::int AS rating_4,
+    COALESCE(st.rating_5, 0)::int AS rating_5,
+    COALESCE(st.rating_sum, 0)::bigint AS rating_sum,
+    COALESCE(st.suggestion_1, 0)::int AS suggestion_1,
+    COALESCE(st.suggestion_2, 0)::int AS suggestion_2,
+    COALESCE(st.suggestion_3, 0)::int AS suggestion_3,
+    COALESCE(st.emoji_counts, '{}'::jsonb)::jsonb AS emoji_counts,
+    COALESCE(st.vote_sum, 0)::int AS vote_sum,
+    COALESCE(st.vote_count, 0)::int AS vote_count,
+    COALESCE(st.upvotes, 0)::int AS upvotes,
+    COALESCE(st.downvotes, 0)::int AS downvotes,
+    COALESCE((
+        SELECT value FROM decision_votes
+        WHERE decision_id = d.id AND voter_viewer_id = sqlc.narg(viewer_id)::uuid
+    ), 0)::int AS my_vote,
+    EXISTS(SELECT 1 FROM responses WHERE decision_id = d.id AND viewer_id = sqlc.narg(viewer_id)::uuid)::bool AS responded,
+    -- Only the newest cards the envelope carries, plus one to tell whether
+    -- there are more; the rest are paged through the responses feed.
+    COALESCE((
+        SELECT json_agg(json_build_object(
+            'id', r.id,
+            'rating', r.rating,
+            'suggestion', r.suggestion,
+            'emoji', r.emoji,
+            'comment', r.comment,
+            'language', r.language,
+            'created_at', r.created_at,
+            'edited_at', r.edited_at,
+            'panel_member', r.panel_member_id IS NOT NULL,
+            'nickname', r.nickname,
+            'reactions', COALESCE((
+                SELECT jsonb_object_agg(emoji, count)
+                FROM (
+                    SELECT emoji, COUNT(*) AS count
+                    FROM response_reactions
+                    WHERE response_id = r.id AND NOT shadowed
+                    GROUP BY emoji
+                ) grouped
+            ), '{}'::jsonb)
+        ) ORDER BY r.created_at DESC, r.id DESC)
+        FROM (
+            SELECT *
+            FROM responses
+            WHERE decision_id = d.id AND hidden_at IS NULL AND NOT shadowed
+            ORDER BY created_at DESC, id DESC
+            LIMIT @response_limit::int
+        ) r
+    ), '[]'::json)::json AS responses
+FROM decisions d
+LEFT JOIN decision_stats st ON st.decision_id = d.id
+WHERE d.slug = @slug AND d.hidden_at IS NULL;
+
+-- name: GetViewerState :one
+SELECT
+    COALESCE((
+        SELECT value FROM decision_votes
+        WHERE decision_id = @decision_id::uuid AND voter_viewer_id = @viewer_id::uuid
+    ), 0)::int AS my_vote,
+    EXISTS(SELECT 1 FROM responses WHERE decision_id = @decision_id::uuid AND viewer_id = @viewer_id::uuid)::bool AS responded;
@@ -0,0 +1,52 @@
+-- name: CreateResponse :one
+INSERT INTO responses (id, decision_id, viewer_id, rating, suggestion, emoji, comment, language, panel_member_id, shadowed, nickname)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING created_at;
+
+-- name: GetRecommendationTotals :one
+SELECT
+    COALESCE(SUM(v.value), 0)::int AS vote_sum,
+    COUNT(v.id)::int AS vote_count,
+    d.panel_only
+FROM decisions d
+LEFT JOIN decision_votes v ON v.decision_id = d.id AND NOT v.shadowed
+WHERE d.id = $1
+GROUP BY d.id;
+
+-- name: ListRecommendationResponses :many
+SELECT suggestion, rating, comment, language, (panel_member_id IS NOT NULL)::bool AS panel_member, created_at
+FROM responses
+WHERE decision_id = $1 AND hidden_at IS NULL AND NOT shadowed;
+
+-- name: LockResponseLimit :one
+-- Capped decisions take responses one at a time so the cap is exact.
+-- Uncapped ones return no row and take no lock.
+SELECT d.max_responses, COALESCE(st.response_count, 0)::int AS response_count
+FROM decisions d
+LEFT JOIN decision_stats st ON st.decision_id = d.id
+WHERE d.id = $1 AND d.max_responses > 0
+FOR UPDATE OF d;
+
+-- name: LockViewerResponse :one
+SELECT id, rating, shadowed
+FROM responses
+WHERE decision_id = $1 AND viewer_id = $2
+FOR UPDATE;
+
+-- name: UpdateResponse :one
+UPDATE responses
+SET rating = $2, suggestion = $3, emoji = $4, comment = $5, language = $6, edited_at = now()
+WHERE id = $1
+RETURNING created_at, edited_at, (panel_member_id IS NOT NULL)::bool AS panel_member, nickname;
+
+-- name: DeleteViewerResponse :one
+-- Reports against the response go with it.
+WITH gone AS (
+    DELETE FROM responses
+    WHERE decision_id = $1 AND viewer_id = $2
+    RETURNING id, rating, shadowed
+), unreported AS (
+    DELETE FROM reports
+    WHERE target_kind = 'response' AND target_id IN (SELECT id FROM gone)
+)
+SELECT id, rating, shadowed FROM gone;
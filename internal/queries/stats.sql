@@ -0,0 +1,3 @@
+-- name: GetDecisionStats :one
+SELECT * FROM decision_stats
+WHERE decision_id = $1;
@@ -0,0 +1,31 @@
+-- LockViewerVote holds one viewer's vote on a decision until the transaction
+-- ends, so their toggles apply one after another. Without it two identical
+-- toggles can both miss the other's row and both leave a vote.
+-- name: LockViewerVote :exec
+SELECT pg_advisory_xact_lock(hashtextextended(CAST(@decision_id::uuid AS text) || '/' || CAST(@viewer_id::uuid AS text), 0));
+
+-- ToggleDecisionVote repeating the current value deletes it; anything else
+-- upserts against the (decision_id, voter_viewer_id) unique constraint. Take
+-- LockViewerVote first.
+-- name: ToggleDecisionVote :exec
+WITH removed AS (
+    DELETE FROM decision_votes
+    WHERE decision_id = @decision_id::uuid AND voter_viewer_id = @viewer_id::uuid AND value = @value::int
+    RETURNING id
+)
+INSERT INTO decision_votes (id, decision_id, voter_viewer_id, value, shadowed)
+SELECT @id::uuid, @decision_id::uuid, @viewer_id::uuid, @value::int, @shadowed::bool
+WHERE NOT EXISTS (SELECT 1 FROM removed)
+ON CONFLICT ON CONSTRAINT decision_votes_decision_id_voter_viewer_id_key
+DO UPDATE SET value = EXCLUDED.value, shadowed = EXCLUDED.shadowed, created_at = now();
+
+-- Shadowed votes are left out of the totals but still count as the viewer's
+-- own vote.
+-- name: GetDecisionVoteSummary :one
+SELECT
+    COALESCE(SUM(value) FILTER (WHERE NOT shadowed), 0)::int AS score,
+    COALESCE(COUNT(*) FILTER (WHERE value = 1 AND NOT shadowed), 0)::int AS upvotes,
+    COALESCE(COUNT(*) FILTER (WHERE value = -1 AND NOT shadowed), 0)::int AS downvotes,
+    COALESCE(MAX(CASE WHEN sqlc.narg(viewer_id)::uuid IS NOT NULL AND voter_viewer_id = sqlc.narg(viewer_id)::uuid THEN value END), 0)::int AS my_vote
+FROM decision_votes
+WHERE decision_id = @decision_id;
@@ -0,0 +1,105 @@
+// Package safehttp makes requests to URLs that users supply, such as
+// webhook receivers, without letting them reach the server's own network.
+// URLs must be https, every address is checked when it is dialed (after DNS
+// resolution, so a public name that resolves to a private address is
+// caught too), and redirects are not followed.
+package safehttp
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"ratemylifedecision/internal/logging"
+)
+
+// ErrBlockedAddress is returned for a URL or dialed address that is not on
+// the public internet.
+var ErrBlockedAddress = errors.New("address is not publicly routable")
+
+// sharedAddressSpace is 100.64.0.0/10, used for carrier-grade NAT and by
+// some cloud providers for internal services.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// CheckURL reports whether raw is an https URL whose host is not obviously
+// internal. Hostnames are only resolved when dialed, so passing CheckURL
+// does not mean the request will be allowed.
+func CheckURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" || u.User != nil {
+		return errors.New("must be an https URL")
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrBlockedAddress
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !allowed(addr) {
+		return ErrBlockedAddress
+	}
+	return nil
+}
+
+// Client returns a client for user-supplied URLs. It refuses anything but
+// https, dials only public addresses, ignores proxy settings (which would
+// dial on its behalf) and returns redirects as they are instead of
+// following them.
+func Client(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: control}
+	transport := &http.Transport{
+		Proxy:               nil,
+		DialContext:         dialer.DialContext,
+		ForceAttemptHTTP2:   true,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: checkedTransport{logging.Transport{Base: transport}},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// checkedTransport refuses requests whose URL fails CheckURL, so a receiver
+// stored before the rules tightened is not called either.
+type checkedTransport struct {
+	base http.RoundTripper
+}
+
+func (t checkedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := CheckURL(req.URL.String()); err != nil {
+		return nil, fmt.Errorf("%s: %w", req.URL.Redacted(), err)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// control runs just before each connection is made, with the resolved
+// address.
+func control(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%s: %w", address, ErrBlockedAddress)
+	}
+	if !allowed(addrPort.Addr()) {
+		return fmt.Errorf("%s: %w", address, ErrBlockedAddress)
+	}
+	return nil
+}
+
+func allowed(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() &&
+		!addr.IsPrivate() &&
+		!addr.IsLoopback() &&
+		!addr.IsLinkLocalUnicast() &&
+		!addr.IsUnspecified() &&
+		!sharedAddressSpace.Contains(addr)
+}
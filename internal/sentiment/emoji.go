@@ -0,0 +1,60 @@
+package sentiment
+
+// emojiSentiment scores emoji from -1 to 1. Emoji read the same in every
+// language, so they are scored whatever the comment's language is. Skin
+// tone modifiers and variation selectors are skipped, so "👍🏽" and "❤️"
+// count as "👍" and "❤".
+var emojiSentiment = map[rune]float64{
+	'😀': 0.7, '😃': 0.7, '😄': 0.7, '😁': 0.7, '😆': 0.6, '🙂': 0.4, '😊': 0.7, '😌': 0.4,
+	'😍': 1.0, '🥰': 1.0, '🤩': 1.0, '😎': 0.7, '🥳': 1.0, '🤗': 0.6, '🫡': 0.8,
+	'👍': 0.8, '👏': 0.8, '🙌': 0.9, '👌': 0.7, '💪': 0.8, '🔥': 0.7, '✨': 0.5, '🎉': 1.0,
+	'🚀': 0.8, '💯': 0.9, '✅': 0.8, '❤': 0.9, '💖': 0.9, '💚': 0.8,
+
+	'😬': -0.5, '😕': -0.4, '🙁': -0.5, '😟': -0.6, '😒': -0.5, '🙄': -0.5, '😞': -0.6,
+	'😔': -0.6, '😰': -0.7, '😨': -0.7, '😱': -0.8, '😢': -0.7, '😭': -0.8, '😩': -0.7,
+	'😫': -0.7, '🫠': -0.6, '😡': -0.9, '😠': -0.8, '🤬': -1.0, '🤦': -0.6, '👎': -0.8,
+	'💀': -0.6, '☠': -0.8, '❌': -0.8, '🚩': -0.9, '⚠': -0.5, '💔': -0.8,
+}
+
+// emojiRunBoost is the weight of a run of the same emoji, like "😬😬😬",
+// relative to a single one: emphatic, but not three separate opinions.
+const emojiRunBoost = 1.5
+
+// emojiWeights returns the weight of positive and negative emoji in text.
+func emojiWeights(text string) (positive, negative float64) {
+	var (
+		prev rune
+		run  int
+	)
+	flush := func() {
+		value, ok := emojiSentiment[prev]
+		if !ok || run == 0 {
+			return
+		}
+		if run > 1 {
+			value *= emojiRunBoost
+		}
+		if value > 0 {
+			positive += value
+		} else {
+			negative -= value
+		}
+	}
+	for _, r := range text {
+		if isEmojiModifier(r) {
+			continue
+		}
+		if r == prev {
+			run++
+			continue
+		}
+		flush()
+		prev, run = r, 1
+	}
+	flush()
+	return positive, negative
+}
+
+func isEmojiModifier(r rune) bool {
+	return r == '\uFE0F' || r == '\u200D' || (r >= 0x1F3FB && r <= 0x1F3FF)
+}
@@ -0,0 +1,161 @@
+package sentiment
+
+// lexicon is one language's wordlists. Words are lower case with
+// apostrophes removed. Stopwords are common function words, used only to
+// tell languages apart.
+type lexicon struct {
+	positive     map[string]struct{}
+	negative     map[string]struct{}
+	negators     map[string]struct{}
+	intensifiers map[string]float64
+	stopwords    map[string]struct{}
+}
+
+func (lex *lexicon) has(word string) bool {
+	for _, set := range []map[string]struct{}{lex.stopwords, lex.positive, lex.negative, lex.negators} {
+		if _, ok := set[word]; ok {
+			return true
+		}
+	}
+	_, ok := lex.intensifiers[word]
+	return ok
+}
+
+func set(words ...string) map[string]struct{} {
+	out := make(map[string]struct{}, len(words))
+	for _, word := range words {
+		out[word] = struct{}{}
+	}
+	return out
+}
+
+// lexiconOrder fixes the order languages are compared in, so detection
+// does not depend on map iteration.
+var lexiconOrder = []string{"en", "es", "fr", "de", "pt", "it"}
+
+var lexicons = map[string]*lexicon{
+	"en": {
+		positive: set(
+			"amazing", "better", "benefit", "best", "excellent", "good", "great", "growth", "happy", "love",
+			"opportunity", "positive", "recommend", "safe", "smart", "strong", "support", "upside", "worth",
+			"yes", "win",
+		),
+		negative: set(
+			"bad", "concern", "costly", "difficult", "downside", "expensive", "hard", "hate", "loss", "negative",
+			"no", "problem", "risk", "risky", "stress", "unsafe", "worse", "worst",
+		),
+		negators: set(
+			"arent", "cant", "cannot", "didnt", "doesnt", "dont", "isnt", "never", "no", "not", "nothing",
+			"shouldnt", "wasnt", "without", "wont", "wouldnt",
+		),
+		intensifiers: map[string]float64{
+			"absolutely": 1.5, "extremely": 2.0, "incredibly": 2.0, "really": 1.5, "so": 1.3, "super": 1.5,
+			"totally": 1.5, "very": 1.5,
+			"bit": 0.6, "kinda": 0.6, "little": 0.6, "slightly": 0.5, "somewhat": 0.6,
+		},
+		stopwords: set(
+			"the", "and", "is", "it", "its", "to", "of", "you", "your", "this", "that", "would", "should",
+			"i", "im", "with", "for", "but", "are", "be", "do", "go", "if", "just",
+		),
+	},
+	"es": {
+		positive: set(
+			"bueno", "buena", "buenos", "buenas", "bien", "genial", "excelente", "mejor", "feliz", "encanta",
+			"oportunidad", "positivo", "recomiendo", "seguro", "segura", "vale", "sí", "increíble", "éxito",
+			"adelante", "hazlo",
+		),
+		negative: set(
+			"malo", "mala", "malos", "malas", "mal", "peor", "riesgo", "riesgoso", "arriesgado", "caro", "cara",
+			"difícil", "problema", "estrés", "pérdida", "negativo", "terrible", "odio", "error",
+		),
+		negators: set("no", "nunca", "jamás", "sin", "ni", "tampoco", "nada"),
+		intensifiers: map[string]float64{
+			"muy": 1.5, "súper": 1.5, "super": 1.5, "realmente": 1.5, "totalmente": 1.5, "tan": 1.3,
+			"extremadamente": 2.0, "poco": 0.6, "algo": 0.6, "ligeramente": 0.5,
+		},
+		stopwords: set(
+			"el", "la", "los", "las", "de", "del", "que", "y", "es", "en", "un", "una", "por", "con", "para",
+			"pero", "lo", "se", "tu", "te", "yo", "eso", "esto", "como", "más", "si", "está",
+		),
+	},
+	"fr": {
+		positive: set(
+			"bon", "bonne", "bien", "génial", "excellent", "excellente", "meilleur", "meilleure", "heureux",
+			"heureuse", "adore", "opportunité", "positif", "recommande", "sûr", "vaut", "oui", "super",
+			"parfait", "fonce",
+		),
+		negative: set(
+			"mauvais", "mauvaise", "mal", "pire", "risque", "risqué", "cher", "chère", "difficile", "problème",
+			"stress", "perte", "négatif", "terrible", "déteste", "erreur", "dangereux",
+		),
+		negators: set("pas", "jamais", "sans", "ni", "rien", "aucun", "aucune", "non"),
+		intensifiers: map[string]float64{
+			"très": 1.5, "vraiment": 1.5, "trop": 1.3, "tellement": 1.5, "totalement": 1.5,
+			"extrêmement": 2.0, "peu": 0.6, "légèrement": 0.5, "assez": 0.8,
+		},
+		stopwords: set(
+			"le", "la", "les", "de", "des", "du", "et", "est", "un", "une", "que", "pour", "avec", "mais",
+			"ce", "cest", "je", "tu", "vous", "il", "elle", "dans", "sur", "ne", "nest", "si",
+		),
+	},
+	"de": {
+		positive: set(
+			"gut", "gute", "guter", "gutes", "toll", "super", "großartig", "ausgezeichnet", "besser", "beste",
+			"glücklich", "liebe", "chance", "positiv", "empfehle", "sicher", "lohnt", "ja", "perfekt", "mach",
+		),
+		negative: set(
+			"schlecht", "schlechte", "schlimm", "schlimmer", "risiko", "riskant", "teuer", "schwer",
+			"schwierig", "problem", "stress", "verlust", "negativ", "hasse", "fehler", "gefährlich",
+		),
+		negators: set("nicht", "kein", "keine", "keinen", "nie", "niemals", "ohne", "nichts", "nein"),
+		intensifiers: map[string]float64{
+			"sehr": 1.5, "wirklich": 1.5, "echt": 1.5, "total": 1.5, "so": 1.3, "extrem": 2.0,
+			"etwas": 0.6, "bisschen": 0.6, "leicht": 0.5, "ziemlich": 0.8,
+		},
+		stopwords: set(
+			"der", "die", "das", "und", "ist", "ein", "eine", "zu", "mit", "für", "aber", "ich", "du", "es",
+			"den", "dem", "auf", "wenn", "dass", "sich", "auch", "nur", "wird", "würde",
+		),
+	},
+	"pt": {
+		positive: set(
+			"bom", "boa", "bons", "boas", "bem", "ótimo", "ótima", "excelente", "melhor", "feliz", "adoro",
+			"oportunidade", "positivo", "recomendo", "seguro", "segura", "vale", "sim", "incrível", "sucesso",
+			"vai", "faça",
+		),
+		negative: set(
+			"mau", "má", "ruim", "pior", "risco", "arriscado", "caro", "cara", "difícil", "problema",
+			"estresse", "perda", "negativo", "terrível", "odeio", "erro", "perigoso",
+		),
+		negators: set("não", "nunca", "jamais", "sem", "nem", "nada", "nenhum", "nenhuma"),
+		intensifiers: map[string]float64{
+			"muito": 1.5, "super": 1.5, "realmente": 1.5, "totalmente": 1.5, "tão": 1.3,
+			"extremamente": 2.0, "pouco": 0.6, "meio": 0.6, "ligeiramente": 0.5,
+		},
+		stopwords: set(
+			"o", "a", "os", "as", "de", "do", "da", "que", "e", "é", "um", "uma", "com", "para", "mas",
+			"isso", "isto", "eu", "você", "se", "no", "na", "em", "por", "mais", "está",
+		),
+	},
+	"it": {
+		positive: set(
+			"buono", "buona", "bene", "ottimo", "ottima", "eccellente", "meglio", "migliore", "felice",
+			"adoro", "opportunità", "positivo", "consiglio", "sicuro", "sicura", "vale", "sì", "fantastico",
+			"perfetto", "fallo",
+		),
+		negative: set(
+			"cattivo", "cattiva", "male", "peggio", "peggiore", "rischio", "rischioso", "caro", "cara",
+			"difficile", "problema", "stress", "perdita", "negativo", "terribile", "odio", "errore",
+			"pericoloso",
+		),
+		negators: set("non", "mai", "senza", "né", "niente", "nessun", "nessuno", "nessuna"),
+		intensifiers: map[string]float64{
+			"molto": 1.5, "davvero": 1.5, "veramente": 1.5, "super": 1.5, "così": 1.3, "troppo": 1.3,
+			"estremamente": 2.0, "poco": 0.6, "leggermente": 0.5, "abbastanza": 0.8,
+		},
+		stopwords: set(
+			"il", "lo", "la", "i", "gli", "le", "di", "che", "e", "è", "un", "una", "per", "con", "ma",
+			"questo", "questa", "io", "tu", "si", "del", "della", "in", "più", "se", "sono",
+		),
+	},
+}
@@ -0,0 +1,167 @@
+// Package sentiment scores short comments from -1 (against) to 1 (for)
+// using per-language wordlists and emoji, and guesses which supported
+// language a comment is written in.
+package sentiment
+
+import (
+	"strings"
+	"unicode"
+)
+
+// negationWindow is how many words after a negator are flipped.
+const negationWindow = 3
+
+// English is the language comments are scored in when theirs is unknown.
+const English = "en"
+
+// Detect guesses the language of text from common function words and
+// sentiment words. It returns "" when text has no clear winner, which
+// short comments often don't.
+func Detect(text string) string {
+	hits := make(map[string]int, len(lexicons))
+	for _, clause := range clauses(text) {
+		for _, word := range clause {
+			for code, lex := range lexicons {
+				if lex.has(word) {
+					hits[code]++
+				}
+			}
+		}
+	}
+
+	best, bestHits, tied := "", 0, false
+	for _, code := range lexiconOrder {
+		switch n := hits[code]; {
+		case n > bestHits:
+			best, bestHits, tied = code, n, false
+		case n == bestHits && n > 0:
+			tied = true
+		}
+	}
+	if tied {
+		return ""
+	}
+	return best
+}
+
+// Score rates text with the wordlist for language, detecting the language
+// when it is empty and falling back to English. Within a clause, a negator
+// flips the words shortly after it ("not a good idea" is negative) and an
+// intensifier scales the word it precedes ("very risky" outweighs "slightly
+// better"). Emoji count alongside the words.
+func Score(text, language string) float64 {
+	if language == "" {
+		language = Detect(text)
+	}
+	lex, ok := lexicons[language]
+	if !ok {
+		lex = lexicons[English]
+	}
+
+	positive, negative := emojiWeights(text)
+	for _, clause := range clauses(text) {
+		p, n := lex.clauseSentiment(clause)
+		positive += p
+		negative += n
+	}
+	total := positive + negative
+	if total == 0 {
+		return 0.0
+	}
+	return clamp((positive-negative)/total, -1.0, 1.0)
+}
+
+// clauses splits text into lower-cased words, grouped by clause so negation
+// does not run past punctuation. Apostrophes are dropped, so "wouldn't" is
+// "wouldnt".
+func clauses(text string) [][]string {
+	var out [][]string
+	for _, clause := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return strings.ContainsRune(".,;:!?¿¡\n", r)
+	}) {
+		words := strings.FieldsFunc(clause, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '\'' && r != '’'
+		})
+		for i, word := range words {
+			words[i] = strings.NewReplacer("'", "", "’", "").Replace(word)
+		}
+		if len(words) > 0 {
+			out = append(out, words)
+		}
+	}
+	return out
+}
+
+// clauseSentiment returns the weight of positive and negative words in one
+// clause. A negator that flips nothing, like a bare "no", counts as the
+// sentiment word it is, if any.
+func (lex *lexicon) clauseSentiment(words []string) (positive, negative float64) {
+	add := func(word string, negated bool, weight float64) {
+		polarity := 0
+		if _, ok := lex.positive[word]; ok {
+			polarity = 1
+		} else if _, ok := lex.negative[word]; ok {
+			polarity = -1
+		}
+		if negated {
+			polarity = -polarity
+		}
+		switch polarity {
+		case 1:
+			positive += weight
+		case -1:
+			negative += weight
+		}
+	}
+
+	var (
+		negator      string
+		negatedUntil = -1
+		intensity    = 1.0
+	)
+	for i, word := range words {
+		if negator != "" && i > negatedUntil {
+			add(negator, false, 1)
+			negator = ""
+		}
+		if _, ok := lex.negators[word]; ok {
+			if negator != "" {
+				add(negator, false, 1)
+			}
+			negator, negatedUntil, intensity = word, i+negationWindow, 1.0
+			continue
+		}
+		if scale, ok := lex.intensifiers[word]; ok {
+			intensity = scale
+			continue
+		}
+
+		_, positiveWord := lex.positive[word]
+		_, negativeWord := lex.negative[word]
+		switch {
+		case !positiveWord && !negativeWord:
+		case i <= negatedUntil:
+			// A negated intensifier softens rather than strengthens:
+			// "not very good" is only mildly negative.
+			add(word, true, 1/intensity)
+			negator = ""
+		default:
+			add(word, false, intensity)
+		}
+		intensity = 1.0
+	}
+	if negator != "" {
+		add(negator, false, 1)
+	}
+	return positive, negative
+}
+
+func clamp(value, minValue, maxValue float64) float64 {
+	if value < minValue {
+		return minValue
+	}
+	if value > maxValue {
+		return maxValue
+	}
+	return value
+}
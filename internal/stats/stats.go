@@ -0,0 +1,213 @@
+package stats
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/queries"
+)
+
+// Querier is satisfied by *sql.DB and *sql.Tx so the incremental updates can
+// run inside the same transaction as the write they account for.
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+type Row struct {
+	ResponseCount    int
+	RatingCounts     [5]int
+	RatingSum        int64
+	SuggestionCounts [3]int
+	EmojiCounts      map[string]int
+	VoteSum          int
+	VoteCount        int
+	Upvotes          int
+	Downvotes        int
+}
+
+func ApplyResponse(ctx context.Context, q Querier, decisionID uuid.UUID, rating, suggestion int, emoji string) error {
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO decision_stats AS s (
+			decision_id,
+			response_count,
+			rating_1, rating_2, rating_3, rating_4, rating_5,
+			rating_sum,
+			suggestion_1, suggestion_2, suggestion_3,
+			emoji_counts
+		)
+		VALUES (
+			$1,
+			1,
+			($2::int = 1)::int, ($2::int = 2)::int, ($2::int = 3)::int, ($2::int = 4)::int, ($2::int = 5)::int,
+			$2::int,
+			($3::int = 1)::int, ($3::int = 2)::int, ($3::int = 3)::int,
+			jsonb_build_object($4::text, 1)
+		)
+		ON CONFLICT (decision_id) DO UPDATE SET
+			response_count = s.response_count + 1,
+			rating_1 = s.rating_1 + EXCLUDED.rating_1,
+			rating_2 = s.rating_2 + EXCLUDED.rating_2,
+			rating_3 = s.rating_3 + EXCLUDED.rating_3,
+			rating_4 = s.rating_4 + EXCLUDED.rating_4,
+			rating_5 = s.rating_5 + EXCLUDED.rating_5,
+			rating_sum = s.rating_sum + EXCLUDED.rating_sum,
+			suggestion_1 = s.suggestion_1 + EXCLUDED.suggestion_1,
+			suggestion_2 = s.suggestion_2 + EXCLUDED.suggestion_2,
+			suggestion_3 = s.suggestion_3 + EXCLUDED.suggestion_3,
+			emoji_counts = jsonb_set(
+				s.emoji_counts,
+				ARRAY[$4::text],
+				to_jsonb(COALESCE((s.emoji_counts ->> $4::text)::int, 0) + 1)
+			),
+			updated_at = now()
+	`, decisionID, rating, suggestion, emoji)
+	return err
+}
+
+// RefreshVotes re-derives the vote columns from decision_votes. Votes can be
+// toggled off or flipped, so recounting the indexed rows is simpler and no
+// more expensive than tracking each transition. Shadowed votes never count.
+func RefreshVotes(ctx context.Context, q Querier, decisionID uuid.UUID) error {
+	_, err := q.ExecContext(ctx, `
+		INSERT INTO decision_stats AS s (decision_id, vote_sum, vote_count, upvotes, downvotes)
+		SELECT
+			$1,
+			COALESCE(SUM(value), 0)::int,
+			COUNT(*)::int,
+			COUNT(*) FILTER (WHERE value = 1)::int,
+			COUNT(*) FILTER (WHERE value = -1)::int
+		FROM decision_votes
+		WHERE decision_id = $1 AND NOT shadowed
+		ON CONFLICT (decision_id) DO UPDATE SET
+			vote_sum = EXCLUDED.vote_sum,
+			vote_count = EXCLUDED.vote_count,
+			upvotes = EXCLUDED.upvotes,
+			downvotes = EXCLUDED.downvotes,
+			updated_at = now()
+	`, decisionID)
+	return err
+}
+
+// Load returns the precomputed row. Decisions that have never received a
+// response or vote have no row yet and read as all zeros.
+func Load(ctx context.Context, q queries.DBTX, decisionID uuid.UUID) (Row, error) {
+	st, err := queries.New(q).GetDecisionStats(ctx, decisionID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Row{EmojiCounts: map[string]int{}}, nil
+	}
+	if err != nil {
+		return Row{}, err
+	}
+
+	row := Row{
+		ResponseCount:    st.ResponseCount,
+		RatingCounts:     [5]int{st.Rating1, st.Rating2, st.Rating3, st.Rating4, st.Rating5},
+		RatingSum:        st.RatingSum,
+		SuggestionCounts: [3]int{st.Suggestion1, st.Suggestion2, st.Suggestion3},
+		EmojiCounts:      make(map[string]int, 8),
+		VoteSum:          st.VoteSum,
+		VoteCount:        st.VoteCount,
+		Upvotes:          st.Upvotes,
+		Downvotes:        st.Downvotes,
+	}
+	if err := json.Unmarshal(st.EmojiCounts, &row.EmojiCounts); err != nil {
+		return Row{}, err
+	}
+	return row, nil
+}
+
+// Repair recomputes rows from the source tables with the original
+// COUNT FILTER aggregation, leaving out shadowed rows. A nil decisionID
+// repairs every decision.
+func Repair(ctx context.Context, q Querier, decisionID *uuid.UUID) (int64, error) {
+	var param any
+	if decisionID != nil {
+		param = *decisionID
+	}
+
+	result, err := q.ExecContext(ctx, `
+		INSERT INTO decision_stats (
+			decision_id,
+			response_count,
+			rating_1, rating_2, rating_3, rating_4, rating_5,
+			rating_sum,
+			suggestion_1, suggestion_2, suggestion_3,
+			emoji_counts,
+			vote_sum, vote_count, upvotes, downvotes
+		)
+		SELECT
+			d.id,
+			COALESCE(r.response_count, 0),
+			COALESCE(r.rating_1, 0), COALESCE(r.rating_2, 0), COALESCE(r.rating_3, 0), COALESCE(r.rating_4, 0), COALESCE(r.rating_5, 0),
+			COALESCE(r.rating_sum, 0),
+			COALESCE(r.suggestion_1, 0), COALESCE(r.suggestion_2, 0), COALESCE(r.suggestion_3, 0),
+			COALESCE(e.emoji_counts, '{}'::jsonb),
+			COALESCE(v.vote_sum, 0), COALESCE(v.vote_count, 0), COALESCE(v.upvotes, 0), COALESCE(v.downvotes, 0)
+		FROM decisions d
+		LEFT JOIN (
+			SELECT
+				decision_id,
+				COUNT(*)::int AS response_count,
+				COUNT(*) FILTER (WHERE rating = 1)::int AS rating_1,
+				COUNT(*) FILTER (WHERE rating = 2)::int AS rating_2,
+				COUNT(*) FILTER (WHERE rating = 3)::int AS rating_3,
+				COUNT(*) FILTER (WHERE rating = 4)::int AS rating_4,
+				COUNT(*) FILTER (WHERE rating = 5)::int AS rating_5,
+				SUM(rating) AS rating_sum,
+				COUNT(*) FILTER (WHERE suggestion = 1)::int AS suggestion_1,
+				COUNT(*) FILTER (WHERE suggestion = 2)::int AS suggestion_2,
+				COUNT(*) FILTER (WHERE suggestion = 3)::int AS suggestion_3
+			FROM responses
+			WHERE NOT shadowed
+			GROUP BY decision_id
+		) r ON r.decision_id = d.id
+		LEFT JOIN (
+			SELECT decision_id, jsonb_object_agg(emoji, count) AS emoji_counts
+			FROM (
+				SELECT decision_id, emoji, COUNT(*) AS count
+				FROM responses
+				WHERE NOT shadowed
+				GROUP BY decision_id, emoji
+			) grouped
+			GROUP BY decision_id
+		) e ON e.decision_id = d.id
+		LEFT JOIN (
+			SELECT
+				decision_id,
+				SUM(value)::int AS vote_sum,
+				COUNT(*)::int AS vote_count,
+				COUNT(*) FILTER (WHERE value = 1)::int AS upvotes,
+				COUNT(*) FILTER (WHERE value = -1)::int AS downvotes
+			FROM decision_votes
+			WHERE NOT shadowed
+			GROUP BY decision_id
+		) v ON v.decision_id = d.id
+		WHERE $1::uuid IS NULL OR d.id = $1::uuid
+		ON CONFLICT (decision_id) DO UPDATE SET
+			response_count = EXCLUDED.response_count,
+			rating_1 = EXCLUDED.rating_1,
+			rating_2 = EXCLUDED.rating_2,
+			rating_3 = EXCLUDED.rating_3,
+			rating_4 = EXCLUDED.rating_4,
+			rating_5 = EXCLUDED.rating_5,
+			rating_sum = EXCLUDED.rating_sum,
+			suggestion_1 = EXCLUDED.suggestion_1,
+			suggestion_2 = EXCLUDED.suggestion_2,
+			suggestion_3 = EXCLUDED.suggestion_3,
+			emoji_counts = EXCLUDED.emoji_counts,
+			vote_sum = EXCLUDED.vote_sum,
+			vote_count = EXCLUDED.vote_count,
+			upvotes = EXCLUDED.upvotes,
+			downvotes = EXCLUDED.downvotes,
+			updated_at = now()
+	`, param)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,365 @@
+package store
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/stats"
+)
+
+// Memory bundles in-memory implementations of every store, for tests that
+// exercise handlers without a database. They keep the rules the Postgres
+// stores enforce (unique slugs, one response per viewer, max_responses,
+// shadowed rows left out of every total) but nothing the database does on
+// the side: no outbox events, projections or moderation.
+type Memory struct {
+	Decisions DecisionStore
+	Responses ResponseStore
+	Votes     VoteStore
+}
+
+func NewMemory() Memory {
+	m := &memory{
+		decisions: make(map[uuid.UUID]*Decision),
+		votes:     make(map[memoryVoteKey]memoryVote),
+	}
+	return Memory{
+		Decisions: &memDecisions{m},
+		Responses: &memResponses{m},
+		Votes:     &memVotes{m},
+	}
+}
+
+type memory struct {
+	mu        sync.Mutex
+	decisions map[uuid.UUID]*Decision
+	// responses are kept oldest first.
+	responses []memoryResponse
+	votes     map[memoryVoteKey]memoryVote
+}
+
+type memoryResponse struct {
+	decisionID uuid.UUID
+	viewerID   uuid.UUID
+	response   Response
+}
+
+type memoryVoteKey struct {
+	decisionID uuid.UUID
+	viewerID   uuid.UUID
+}
+
+type memoryVote struct {
+	value    int
+	shadowed bool
+}
+
+func (m *memory) bySlug(slug string) (*Decision, bool) {
+	for _, d := range m.decisions {
+		if d.Slug == slug {
+			return d, true
+		}
+	}
+	return nil, false
+}
+
+// touch bumps the decision's revision, as the database triggers do for
+// every response and vote change.
+func (m *memory) touch(decisionID uuid.UUID) {
+	if d, ok := m.decisions[decisionID]; ok {
+		d.Revision++
+	}
+}
+
+// visibleResponses returns the decision's unshadowed responses, newest
+// first.
+func (m *memory) visibleResponses(decisionID uuid.UUID) []Response {
+	var out []Response
+	for i := len(m.responses) - 1; i >= 0; i-- {
+		r := m.responses[i]
+		if r.decisionID == decisionID && !r.response.Shadowed {
+			out = append(out, r.response)
+		}
+	}
+	return out
+}
+
+func (m *memory) viewerResponse(decisionID, viewerID uuid.UUID) int {
+	return slices.IndexFunc(m.responses, func(r memoryResponse) bool {
+		return r.decisionID == decisionID && r.viewerID == viewerID
+	})
+}
+
+func (m *memory) voteSummary(decisionID uuid.UUID, viewerID *uuid.UUID) VoteSummary {
+	var summary VoteSummary
+	for key, vote := range m.votes {
+		if key.decisionID != decisionID {
+			continue
+		}
+		if viewerID != nil && key.viewerID == *viewerID {
+			summary.MyVote = vote.value
+		}
+		if vote.shadowed {
+			continue
+		}
+		summary.Score += vote.value
+		if vote.value > 0 {
+			summary.Upvotes++
+		} else {
+			summary.Downvotes++
+		}
+	}
+	return summary
+}
+
+func (m *memory) stats(decisionID uuid.UUID) stats.Row {
+	row := stats.Row{EmojiCounts: map[string]int{}}
+	for _, r := range m.visibleResponses(decisionID) {
+		row.ResponseCount++
+		if r.Rating >= 1 && r.Rating <= 5 {
+			row.RatingCounts[r.Rating-1]++
+		}
+		row.RatingSum += int64(r.Rating)
+		if r.Suggestion >= 1 && r.Suggestion <= 3 {
+			row.SuggestionCounts[r.Suggestion-1]++
+		}
+		row.EmojiCounts[r.Emoji]++
+	}
+	votes := m.voteSummary(decisionID, nil)
+	row.VoteSum = votes.Score
+	row.VoteCount = votes.Upvotes + votes.Downvotes
+	row.Upvotes = votes.Upvotes
+	row.Downvotes = votes.Downvotes
+	return row
+}
+
+type memDecisions struct {
+	m *memory
+}
+
+func (s *memDecisions) Create(ctx context.Context, d NewDecision) error {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	if _, taken := s.m.decisions[d.ID]; taken {
+		return ErrConflict
+	}
+	if _, taken := s.m.bySlug(d.Slug); taken {
+		return ErrConflict
+	}
+	creatorTokenHash := d.CreatorTokenHash
+	s.m.decisions[d.ID] = &Decision{
+		ID:               d.ID,
+		Slug:             d.Slug,
+		Title:            d.Title,
+		Description:      d.Description,
+		ClosesAt:         d.ClosesAt,
+		CreatedAt:        time.Now(),
+		CreatorTokenHash: &creatorTokenHash,
+		Category:         d.Category,
+		AggregateOnly:    d.AggregateOnly,
+		Quorum:           d.Quorum,
+		Visibility:       d.Visibility,
+		AccessCodeHash:   d.AccessCodeHash,
+		MaxResponses:     d.MaxResponses,
+		NicknamePolicy:   d.NicknamePolicy,
+	}
+	return nil
+}
+
+func (s *memDecisions) BySlug(ctx context.Context, slug string) (Decision, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	d, ok := s.m.bySlug(slug)
+	if !ok {
+		return Decision{}, ErrNotFound
+	}
+	return *d, nil
+}
+
+func (s *memDecisions) Revision(ctx context.Context, slug string) (Decision, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	d, ok := s.m.bySlug(slug)
+	if !ok {
+		return Decision{}, ErrNotFound
+	}
+	return Decision{
+		Slug:             d.Slug,
+		Revision:         d.Revision,
+		Visibility:       d.Visibility,
+		AccessCodeHash:   d.AccessCodeHash,
+		CreatorTokenHash: d.CreatorTokenHash,
+	}, nil
+}
+
+func (s *memDecisions) View(ctx context.Context, slug string, viewerID *uuid.UUID) (DecisionView, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	d, ok := s.m.bySlug(slug)
+	if !ok {
+		return DecisionView{}, ErrNotFound
+	}
+	view := DecisionView{
+		Decision:  *d,
+		Stats:     s.m.stats(d.ID),
+		Responses: s.m.visibleResponses(d.ID),
+	}
+	if view.Responses == nil {
+		view.Responses = []Response{}
+	}
+	if len(view.Responses) > ViewResponseLimit {
+		view.Responses, view.MoreResponses = view.Responses[:ViewResponseLimit], true
+	}
+	if viewerID != nil {
+		view.MyVote = s.m.voteSummary(d.ID, viewerID).MyVote
+		view.Responded = s.m.viewerResponse(d.ID, *viewerID) >= 0
+	}
+	return view, nil
+}
+
+func (s *memDecisions) ViewerState(ctx context.Context, decisionID, viewerID uuid.UUID) (int, bool, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	return s.m.voteSummary(decisionID, &viewerID).MyVote, s.m.viewerResponse(decisionID, viewerID) >= 0, nil
+}
+
+type memResponses struct {
+	m *memory
+}
+
+func (s *memResponses) Create(ctx context.Context, r NewResponse) (Response, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	d, ok := s.m.decisions[r.DecisionID]
+	if !ok {
+		return Response{}, ErrNotFound
+	}
+	if s.m.viewerResponse(r.DecisionID, r.ViewerID) >= 0 {
+		return Response{}, ErrConflict
+	}
+	count := len(s.m.visibleResponses(r.DecisionID))
+	capped := d.MaxResponses > 0
+	if capped && count >= d.MaxResponses {
+		return Response{}, ErrLimitReached
+	}
+
+	out := Response{
+		ID:          r.ID,
+		Rating:      r.Rating,
+		Suggestion:  r.Suggestion,
+		Emoji:       r.Emoji,
+		Comment:     r.Comment,
+		Language:    r.Language,
+		CreatedAt:   time.Now(),
+		PanelMember: r.PanelMemberID != nil,
+		Nickname:    r.Nickname,
+	}
+	stored := out
+	stored.Shadowed = r.Shadowed
+	s.m.responses = append(s.m.responses, memoryResponse{decisionID: r.DecisionID, viewerID: r.ViewerID, response: stored})
+	s.m.touch(r.DecisionID)
+	if !r.Shadowed && capped && count+1 >= d.MaxResponses {
+		now := time.Now()
+		if d.ClosesAt == nil || d.ClosesAt.After(now) {
+			d.ClosesAt = &now
+		}
+		out.FilledLimit = true
+	}
+	return out, nil
+}
+
+func (s *memResponses) Update(ctx context.Context, e ResponseEdit) (Response, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	i := s.m.viewerResponse(e.DecisionID, e.ViewerID)
+	if i < 0 {
+		return Response{}, ErrNotFound
+	}
+	now := time.Now()
+	r := &s.m.responses[i].response
+	r.Rating, r.Suggestion, r.Emoji = e.Rating, e.Suggestion, e.Emoji
+	r.Comment, r.Language, r.EditedAt = e.Comment, e.Language, &now
+	s.m.touch(e.DecisionID)
+	return *r, nil
+}
+
+func (s *memResponses) Delete(ctx context.Context, decisionID, viewerID uuid.UUID) (Response, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	i := s.m.viewerResponse(decisionID, viewerID)
+	if i < 0 {
+		return Response{}, ErrNotFound
+	}
+	r := s.m.responses[i].response
+	s.m.responses = slices.Delete(s.m.responses, i, i+1)
+	s.m.touch(decisionID)
+	return Response{ID: r.ID, Rating: r.Rating, Shadowed: r.Shadowed}, nil
+}
+
+func (s *memResponses) RecommendationInputs(ctx context.Context, decisionID uuid.UUID) (RecommendationInputs, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	d, ok := s.m.decisions[decisionID]
+	if !ok {
+		return RecommendationInputs{}, ErrNotFound
+	}
+	row := s.m.stats(decisionID)
+	in := RecommendationInputs{
+		Responses: []Response{},
+		VoteSum:   row.VoteSum,
+		VoteCount: row.VoteCount,
+		PanelOnly: d.PanelOnly,
+	}
+	for _, r := range s.m.visibleResponses(decisionID) {
+		in.Responses = append(in.Responses, Response{
+			Rating:      r.Rating,
+			Suggestion:  r.Suggestion,
+			Comment:     r.Comment,
+			Language:    r.Language,
+			CreatedAt:   r.CreatedAt,
+			PanelMember: r.PanelMember,
+		})
+	}
+	return in, nil
+}
+
+type memVotes struct {
+	m *memory
+}
+
+func (s *memVotes) Toggle(ctx context.Context, decisionID, viewerID uuid.UUID, value int, shadowed bool) (VoteSummary, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	if _, ok := s.m.decisions[decisionID]; !ok {
+		return VoteSummary{}, ErrNotFound
+	}
+	key := memoryVoteKey{decisionID: decisionID, viewerID: viewerID}
+	if current, ok := s.m.votes[key]; ok && current.value == value {
+		delete(s.m.votes, key)
+	} else {
+		s.m.votes[key] = memoryVote{value: value, shadowed: shadowed}
+	}
+	s.m.touch(decisionID)
+	return s.m.voteSummary(decisionID, &viewerID), nil
+}
+
+func (s *memVotes) Summary(ctx context.Context, decisionID uuid.UUID, viewerID *uuid.UUID) (VoteSummary, error) {
+	s.m.mu.Lock()
+	defer s.m.mu.Unlock()
+
+	return s.m.voteSummary(decisionID, viewerID), nil
+}
@@ -0,0 +1,437 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"ratemylifedecision/internal/database"
+	"ratemylifedecision/internal/projections"
+	"ratemylifedecision/internal/queries"
+	"ratemylifedecision/internal/stats"
+)
+
+// Postgres bundles the Postgres implementations of every store.
+type Postgres struct {
+	Decisions DecisionStore
+	Responses ResponseStore
+	Votes     VoteStore
+}
+
+func NewPostgres(db *sql.DB) Postgres {
+	return Postgres{
+		Decisions: &pgDecisions{db: db},
+		Responses: &pgResponses{db: db},
+		Votes:     &pgVotes{db: db},
+	}
+}
+
+func decisionFromRow(d queries.Decision) Decision {
+	return Decision{
+		ID:               d.ID,
+		Slug:             d.Slug,
+		Title:            d.Title,
+		Description:      d.Description,
+		ClosesAt:         d.ClosesAt,
+		CreatedAt:        d.CreatedAt,
+		PanelOnly:        d.PanelOnly,
+		CreatorTokenHash: d.CreatorTokenHash,
+		Revision:         d.Revision,
+		Category:         d.Category,
+		AggregateOnly:    d.AggregateOnly,
+		ClosedAt:         d.ClosedAt,
+		ArchivedAt:       d.ArchivedAt,
+		Quorum:           d.Quorum,
+		Visibility:       d.Visibility,
+		AccessCodeHash:   d.AccessCodeHash,
+		MaxResponses:     d.MaxResponses,
+		NicknamePolicy:   d.NicknamePolicy,
+	}
+}
+
+// notFound maps sql.ErrNoRows to ErrNotFound and leaves other errors alone.
+func notFound(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
+
+type pgDecisions struct {
+	db *sql.DB
+}
+
+func (p *pgDecisions) Create(ctx context.Context, d NewDecision) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := queries.New(tx).CreateDecision(ctx, queries.CreateDecisionParams{
+		ID:               d.ID,
+		Slug:             d.Slug,
+		Title:            d.Title,
+		Description:      d.Description,
+		ClosesAt:         d.ClosesAt,
+		CreatorTokenHash: &d.CreatorTokenHash,
+		Category:         d.Category,
+		AggregateOnly:    d.AggregateOnly,
+		Quorum:           d.Quorum,
+		Visibility:       d.Visibility,
+		AccessCodeHash:   d.AccessCodeHash,
+		MaxResponses:     d.MaxResponses,
+		NicknamePolicy:   d.NicknamePolicy,
+	}); err != nil {
+		if isUniqueViolation(err) {
+			return fmt.Errorf("%w: %w", ErrConflict, err)
+		}
+		return err
+	}
+	if err := projections.Append(ctx, tx, d.ID, projections.KindDecisionCreated, projections.DecisionCreated{Category: d.Category}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (p *pgDecisions) BySlug(ctx context.Context, slug string) (Decision, error) {
+	d, err := queries.New(p.db).GetDecisionBySlug(ctx, slug)
+	if err != nil {
+		return Decision{}, notFound(err)
+	}
+	return decisionFromRow(d), nil
+}
+
+func (p *pgDecisions) Revision(ctx context.Context, slug string) (Decision, error) {
+	r, err := queries.New(p.db).GetDecisionRevision(ctx, slug)
+	if err != nil {
+		return Decision{}, notFound(err)
+	}
+	return Decision{
+		Slug:             slug,
+		Revision:         r.Revision,
+		Visibility:       r.Visibility,
+		AccessCodeHash:   r.AccessCodeHash,
+		CreatorTokenHash: r.CreatorTokenHash,
+	}, nil
+}
+
+func (p *pgDecisions) View(ctx context.Context, slug string, viewerID *uuid.UUID) (DecisionView, error) {
+	// One response past the limit says whether there are more.
+	r, err := queries.New(p.db).GetDecisionView(ctx, queries.GetDecisionViewParams{
+		ViewerID:      viewerID,
+		ResponseLimit: ViewResponseLimit + 1,
+		Slug:          slug,
+	})
+	if err != nil {
+		return DecisionView{}, notFound(err)
+	}
+
+	view := DecisionView{
+		Decision: decisionFromRow(r.Decision),
+		Stats: stats.Row{
+			ResponseCount:    r.ResponseCount,
+			RatingCounts:     [5]int{r.Rating1, r.Rating2, r.Rating3, r.Rating4, r.Rating5},
+			RatingSum:        r.RatingSum,
+			SuggestionCounts: [3]int{r.Suggestion1, r.Suggestion2, r.Suggestion3},
+			VoteSum:          r.VoteSum,
+			VoteCount:        r.VoteCount,
+			Upvotes:          r.Upvotes,
+			Downvotes:        r.Downvotes,
+		},
+		MyVote:    r.MyVote,
+		Responded: r.Responded,
+	}
+	if err := json.Unmarshal(r.EmojiCounts, &view.Stats.EmojiCounts); err != nil {
+		return DecisionView{}, err
+	}
+	if err := json.Unmarshal(r.Responses, &view.Responses); err != nil {
+		return DecisionView{}, err
+	}
+	if len(view.Responses) > ViewResponseLimit {
+		view.Responses, view.MoreResponses = view.Responses[:ViewResponseLimit], true
+	}
+	return view, nil
+}
+
+func (p *pgDecisions) ViewerState(ctx context.Context, decisionID, viewerID uuid.UUID) (int, bool, error) {
+	state, err := queries.New(p.db).GetViewerState(ctx, queries.GetViewerStateParams{
+		DecisionID: decisionID,
+		ViewerID:   viewerID,
+	})
+	return state.MyVote, state.Responded, err
+}
+
+type pgResponses struct {
+	db *sql.DB
+}
+
+func (p *pgResponses) Create(ctx context.Context, r NewResponse) (Response, error) {
+	var (
+		createdAt   time.Time
+		filledLimit bool
+	)
+	err := database.RetryTx(ctx, p.db, func(tx *sql.Tx) error {
+		q := queries.New(tx)
+		filledLimit = false
+		limit, err := q.LockResponseLimit(ctx, r.DecisionID)
+		capped := err == nil
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return err
+		}
+		if capped && limit.ResponseCount >= limit.MaxResponses {
+			return ErrLimitReached
+		}
+
+		createdAt, err = q.CreateResponse(ctx, queries.CreateResponseParams{
+			ID:            r.ID,
+			DecisionID:    r.DecisionID,
+			ViewerID:      r.ViewerID,
+			Rating:        r.Rating,
+			Suggestion:    r.Suggestion,
+			Emoji:         r.Emoji,
+			Comment:       r.Comment,
+			Language:      r.Language,
+			PanelMemberID: r.PanelMemberID,
+			Shadowed:      r.Shadowed,
+			Nickname:      r.Nickname,
+		})
+		if err != nil {
+			if isUniqueViolation(err) {
+				return fmt.Errorf("%w: %w", ErrConflict, err)
+			}
+			return err
+		}
+		if r.Shadowed {
+			return nil
+		}
+		if err := stats.ApplyResponse(ctx, tx, r.DecisionID, r.Rating, r.Suggestion, r.Emoji); err != nil {
+			return fmt.Errorf("update decision stats: %w", err)
+		}
+		if err := projections.Append(ctx, tx, r.DecisionID, projections.KindResponseCreated, projections.ResponseCreated{
+			Rating:     r.Rating,
+			Suggestion: r.Suggestion,
+		}); err != nil {
+			return err
+		}
+		if capped && limit.ResponseCount+1 >= limit.MaxResponses {
+			filledLimit = true
+			return q.CloseDecisionNow(ctx, r.DecisionID)
+		}
+		return nil
+	})
+	if err != nil {
+		return Response{}, err
+	}
+
+	return Response{
+		ID:          r.ID,
+		Rating:      r.Rating,
+		Suggestion:  r.Suggestion,
+		Emoji:       r.Emoji,
+		Comment:     r.Comment,
+		Language:    r.Language,
+		CreatedAt:   createdAt,
+		PanelMember: r.PanelMemberID != nil,
+		Nickname:    r.Nickname,
+		FilledLimit: filledLimit,
+	}, nil
+}
+
+func (p *pgResponses) Update(ctx context.Context, e ResponseEdit) (Response, error) {
+	var out Response
+	err := database.RetryTx(ctx, p.db, func(tx *sql.Tx) error {
+		q := queries.New(tx)
+		previous, err := q.LockViewerResponse(ctx, queries.LockViewerResponseParams{
+			DecisionID: e.DecisionID,
+			ViewerID:   e.ViewerID,
+		})
+		if err != nil {
+			return notFound(err)
+		}
+		row, err := q.UpdateResponse(ctx, queries.UpdateResponseParams{
+			ID:         previous.ID,
+			Rating:     e.Rating,
+			Suggestion: e.Suggestion,
+			Emoji:      e.Emoji,
+			Comment:    e.Comment,
+			Language:   e.Language,
+		})
+		if err != nil {
+			return err
+		}
+		out = Response{
+			ID:          previous.ID,
+			Rating:      e.Rating,
+			Suggestion:  e.Suggestion,
+			Emoji:       e.Emoji,
+			Comment:     e.Comment,
+			Language:    e.Language,
+			CreatedAt:   row.CreatedAt,
+			EditedAt:    row.EditedAt,
+			PanelMember: row.PanelMember,
+			Nickname:    row.Nickname,
+			Shadowed:    previous.Shadowed,
+		}
+		if previous.Shadowed {
+			return nil
+		}
+		if _, err := stats.Repair(ctx, tx, &e.DecisionID); err != nil {
+			return fmt.Errorf("repair decision stats: %w", err)
+		}
+		if previous.Rating == e.Rating {
+			return nil
+		}
+		// The read models only count ratings: take the old one out and put
+		// the new one in.
+		if err := projections.Append(ctx, tx, e.DecisionID, projections.KindResponseDeleted, projections.ResponseDeleted{
+			Rating: previous.Rating,
+		}); err != nil {
+			return err
+		}
+		return projections.Append(ctx, tx, e.DecisionID, projections.KindResponseCreated, projections.ResponseCreated{
+			Rating:     e.Rating,
+			Suggestion: e.Suggestion,
+		})
+	})
+	if err != nil {
+		return Response{}, err
+	}
+	return out, nil
+}
+
+func (p *pgResponses) Delete(ctx context.Context, decisionID, viewerID uuid.UUID) (Response, error) {
+	var out Response
+	err := database.RetryTx(ctx, p.db, func(tx *sql.Tx) error {
+		row, err := queries.New(tx).DeleteViewerResponse(ctx, queries.DeleteViewerResponseParams{
+			DecisionID: decisionID,
+			ViewerID:   viewerID,
+		})
+		if err != nil {
+			return notFound(err)
+		}
+		out = Response{ID: row.ID, Rating: row.Rating, Shadowed: row.Shadowed}
+		if row.Shadowed {
+			return nil
+		}
+		if _, err := stats.Repair(ctx, tx, &decisionID); err != nil {
+			return fmt.Errorf("repair decision stats: %w", err)
+		}
+		return projections.Append(ctx, tx, decisionID, projections.KindResponseDeleted, projections.ResponseDeleted{
+			Rating: row.Rating,
+		})
+	})
+	if err != nil {
+		return Response{}, err
+	}
+	return out, nil
+}
+
+func (p *pgResponses) RecommendationInputs(ctx context.Context, decisionID uuid.UUID) (RecommendationInputs, error) {
+	q := queries.New(p.db)
+	totals, err := q.GetRecommendationTotals(ctx, decisionID)
+	if err != nil {
+		return RecommendationInputs{}, notFound(err)
+	}
+	rows, err := q.ListRecommendationResponses(ctx, decisionID)
+	if err != nil {
+		return RecommendationInputs{}, err
+	}
+
+	in := RecommendationInputs{
+		Responses: make([]Response, 0, len(rows)),
+		VoteSum:   totals.VoteSum,
+		VoteCount: totals.VoteCount,
+		PanelOnly: totals.PanelOnly,
+	}
+	for _, r := range rows {
+		in.Responses = append(in.Responses, Response{
+			Rating:      r.Rating,
+			Suggestion:  r.Suggestion,
+			Comment:     r.Comment,
+			Language:    r.Language,
+			CreatedAt:   r.CreatedAt,
+			PanelMember: r.PanelMember,
+		})
+	}
+	return in, nil
+}
+
+type pgVotes struct {
+	db *sql.DB
+}
+
+func (p *pgVotes) Toggle(ctx context.Context, decisionID, viewerID uuid.UUID, value int, shadowed bool) (VoteSummary, error) {
+	// The vote ID is fixed outside the retry loop so a replayed transaction
+	// inserts the same row.
+	voteID := uuid.New()
+	var summary VoteSummary
+	err := database.RetryTx(ctx, p.db, func(tx *sql.Tx) error {
+		q := queries.New(tx)
+		if err := q.LockViewerVote(ctx, queries.LockViewerVoteParams{
+			DecisionID: decisionID,
+			ViewerID:   viewerID,
+		}); err != nil {
+			return fmt.Errorf("lock vote: %w", err)
+		}
+		if err := q.ToggleDecisionVote(ctx, queries.ToggleDecisionVoteParams{
+			DecisionID: decisionID,
+			ViewerID:   viewerID,
+			Value:      value,
+			ID:         voteID,
+			Shadowed:   shadowed,
+		}); err != nil {
+			return fmt.Errorf("record vote: %w", err)
+		}
+
+		if err := stats.RefreshVotes(ctx, tx, decisionID); err != nil {
+			return fmt.Errorf("update decision stats: %w", err)
+		}
+
+		var err error
+		summary, err = voteSummary(ctx, q, decisionID, &viewerID)
+		if err != nil {
+			return fmt.Errorf("summarize vote: %w", err)
+		}
+		if shadowed {
+			return nil
+		}
+		if err := projections.Append(ctx, tx, decisionID, projections.KindVoteChanged, projections.VoteChanged{
+			VoteSum:   summary.Score,
+			VoteCount: summary.Upvotes + summary.Downvotes,
+		}); err != nil {
+			return fmt.Errorf("record vote: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return VoteSummary{}, err
+	}
+	return summary, nil
+}
+
+func (p *pgVotes) Summary(ctx context.Context, decisionID uuid.UUID, viewerID *uuid.UUID) (VoteSummary, error) {
+	return voteSummary(ctx, queries.New(p.db), decisionID, viewerID)
+}
+
+func voteSummary(ctx context.Context, q *queries.Queries, decisionID uuid.UUID, viewerID *uuid.UUID) (VoteSummary, error) {
+	r, err := q.GetDecisionVoteSummary(ctx, queries.GetDecisionVoteSummaryParams{
+		ViewerID:   viewerID,
+		DecisionID: decisionID,
+	})
+	if err != nil {
+		return VoteSummary{}, err
+	}
+	return VoteSummary{Score: r.Score, Upvotes: r.Upvotes, Downvotes: r.Downvotes, MyVote: r.MyVote}, nil
+}
@@ -0,0 +1,99 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/database"
+	"ratemylifedecision/internal/migrate"
+	"ratemylifedecision/migrations"
+)
+
+// testDB connects to DATABASE_URL and migrates it, or skips the test when
+// no database is configured.
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+	url := os.Getenv("DATABASE_URL")
+	if url == "" {
+		t.Skip("DATABASE_URL is not set")
+	}
+	ctx := context.Background()
+	pool, err := database.Connect(ctx, url)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	db := database.SQL(pool)
+	if err := migrate.Up(ctx, db, migrations.FS(""), migrate.Options{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+// TestToggleConcurrent toggles the same vote from many goroutines at once.
+// Toggles apply one after another, so an even number of them cancels out
+// and an odd number leaves the vote in place.
+func TestToggleConcurrent(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+	pg := NewPostgres(db)
+
+	decisionID := uuid.New()
+	if err := pg.Decisions.Create(ctx, NewDecision{
+		ID:               decisionID,
+		Slug:             "toggle-" + decisionID.String()[:8],
+		Title:            "Concurrent toggles",
+		CreatorTokenHash: "test",
+		Visibility:       "public",
+		NicknamePolicy:   "optional",
+	}); err != nil {
+		t.Fatalf("create decision: %v", err)
+	}
+	t.Cleanup(func() {
+		db.ExecContext(context.Background(), `DELETE FROM decisions WHERE id = $1`, decisionID)
+	})
+
+	for _, n := range []int{16, 15} {
+		viewerID := uuid.New()
+		var wg sync.WaitGroup
+		errs := make(chan error, n)
+		for range n {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := pg.Votes.Toggle(ctx, decisionID, viewerID, 1, false); err != nil {
+					errs <- err
+				}
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			t.Fatalf("toggle: %v", err)
+		}
+
+		summary, err := pg.Votes.Summary(ctx, decisionID, &viewerID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := n % 2
+		if summary.MyVote != want {
+			t.Errorf("after %d toggles: my vote = %d, want %d", n, summary.MyVote, want)
+		}
+		var count int
+		if err := db.QueryRowContext(ctx,
+			`SELECT COUNT(*) FROM decision_votes WHERE decision_id = $1 AND voter_viewer_id = $2`,
+			decisionID, viewerID,
+		).Scan(&count); err != nil {
+			t.Fatal(err)
+		}
+		if count != want {
+			t.Errorf("after %d toggles: %d vote rows, want %d", n, count, want)
+		}
+	}
+}
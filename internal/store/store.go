@@ -0,0 +1,195 @@
+// Package store owns the SQL for decisions, responses and votes. Handlers
+// depend on the interfaces so they can be exercised with fakes: Postgres is
+// the real implementation and Memory the one tests use.
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/stats"
+)
+
+var (
+	// ErrNotFound is returned when the requested row does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is returned when a write collides with a uniqueness rule,
+	// e.g. a taken slug or a viewer responding twice.
+	ErrConflict = errors.New("conflict")
+	// ErrLimitReached is returned when a decision has already taken its
+	// max_responses.
+	ErrLimitReached = errors.New("limit reached")
+)
+
+type Decision struct {
+	ID               uuid.UUID
+	Slug             string
+	Title            string
+	Description      *string
+	ClosesAt         *time.Time
+	CreatedAt        time.Time
+	PanelOnly        bool
+	CreatorTokenHash *string
+	Revision         int64
+	Category         *string
+	AggregateOnly    bool
+	// ClosedAt is set by the close job once ClosesAt has passed.
+	ClosedAt *time.Time
+	// ArchivedAt is set when the retention job anonymizes the decision.
+	ArchivedAt *time.Time
+	// Quorum is how many responses are needed before results are shown; 0
+	// means none.
+	Quorum int
+	// Visibility is "public", "unlisted" or "private"; private decisions
+	// can only be read or answered with their access code.
+	Visibility     string
+	AccessCodeHash *string
+	// MaxResponses closes the decision once it has this many responses; 0
+	// means no limit.
+	MaxResponses int
+	// NicknamePolicy is "optional", "required" or "forbidden": whether
+	// responses may, must or must not carry a nickname.
+	NicknamePolicy string
+}
+
+type NewDecision struct {
+	ID               uuid.UUID
+	Slug             string
+	Title            string
+	Description      *string
+	ClosesAt         *time.Time
+	CreatorTokenHash string
+	Category         *string
+	AggregateOnly    bool
+	Quorum           int
+	Visibility       string
+	AccessCodeHash   *string
+	MaxResponses     int
+	NicknamePolicy   string
+}
+
+// ViewResponseLimit caps the responses a DecisionView carries.
+const ViewResponseLimit = 50
+
+// DecisionView is everything the decision page needs, read in one round
+// trip: the decision, its precomputed stats, its newest responses (up to
+// ViewResponseLimit, newest first) and the viewer's own vote and response
+// state. MoreResponses says there are older ones left out.
+type DecisionView struct {
+	Decision      Decision
+	Stats         stats.Row
+	Responses     []Response
+	MoreResponses bool
+	MyVote        int
+	Responded     bool
+}
+
+type Response struct {
+	ID         uuid.UUID `json:"id"`
+	Rating     int       `json:"rating"`
+	Suggestion int       `json:"suggestion"`
+	Emoji      string    `json:"emoji"`
+	Comment    *string   `json:"comment"`
+	Language   *string   `json:"language"`
+	CreatedAt  time.Time `json:"created_at"`
+	// EditedAt is when the viewer last changed the response, nil if never.
+	EditedAt    *time.Time `json:"edited_at"`
+	PanelMember bool       `json:"panel_member"`
+	// Nickname is what the responder chose to sign with, nil if anonymous.
+	Nickname *string `json:"nickname"`
+	// Reactions counts the emoji reactions left on the response. Only
+	// filled in for response lists.
+	Reactions map[string]int `json:"reactions"`
+	// Shadowed is only filled in by Update and Delete; response lists
+	// never include shadowed rows.
+	Shadowed bool `json:"-"`
+	// FilledLimit is only filled in by Create: the response was the last
+	// one the decision's max_responses allowed, and closed it.
+	FilledLimit bool `json:"-"`
+}
+
+type NewResponse struct {
+	ID         uuid.UUID
+	DecisionID uuid.UUID
+	ViewerID   uuid.UUID
+	Rating     int
+	Suggestion int
+	Emoji      string
+	Comment    *string
+	// Language is the detected language of Comment, nil when unknown.
+	Language      *string
+	PanelMemberID *uuid.UUID
+	Nickname      *string
+	// Shadowed responses are stored but leave stats, the outbox and every
+	// response list alone.
+	Shadowed bool
+}
+
+// ResponseEdit replaces what a viewer said in their response. The panel
+// membership it was given with stays.
+type ResponseEdit struct {
+	DecisionID uuid.UUID
+	ViewerID   uuid.UUID
+	Rating     int
+	Suggestion int
+	Emoji      string
+	Comment    *string
+	Language   *string
+}
+
+// RecommendationInputs are the raw signals the recommendation is computed
+// from, with every visible response in no particular order.
+type RecommendationInputs struct {
+	Responses []Response
+	VoteSum   int
+	VoteCount int
+	PanelOnly bool
+}
+
+type VoteSummary struct {
+	Score     int
+	Upvotes   int
+	Downvotes int
+	MyVote    int
+}
+
+type DecisionStore interface {
+	// Create inserts the decision and its decision_created outbox event.
+	// A taken slug returns ErrConflict.
+	Create(ctx context.Context, d NewDecision) error
+	BySlug(ctx context.Context, slug string) (Decision, error)
+	// Revision reads just enough of the decision to answer a conditional
+	// GET: its slug, revision, visibility and the hashes an access check
+	// compares against. The other fields are left zero.
+	Revision(ctx context.Context, slug string) (Decision, error)
+	View(ctx context.Context, slug string, viewerID *uuid.UUID) (DecisionView, error)
+	// ViewerState returns the viewer's vote (0 if none) and whether they
+	// have responded.
+	ViewerState(ctx context.Context, decisionID, viewerID uuid.UUID) (myVote int, responded bool, err error)
+}
+
+type ResponseStore interface {
+	// Create inserts the response and applies it to decision_stats and the
+	// outbox in one transaction. A second response from the same viewer
+	// returns ErrConflict, and one past the decision's max_responses
+	// ErrLimitReached.
+	Create(ctx context.Context, r NewResponse) (Response, error)
+	// Update applies the edit to the viewer's response on the decision,
+	// and Delete removes it; both bring decision_stats and the outbox in
+	// line in the same transaction. A viewer with no response gets
+	// ErrNotFound.
+	Update(ctx context.Context, e ResponseEdit) (Response, error)
+	Delete(ctx context.Context, decisionID, viewerID uuid.UUID) (Response, error)
+	RecommendationInputs(ctx context.Context, decisionID uuid.UUID) (RecommendationInputs, error)
+}
+
+type VoteStore interface {
+	// Toggle records value for the viewer, or removes their vote when it
+	// already has that value, and returns the summary after the change.
+	// A shadowed vote is kept out of every total.
+	Toggle(ctx context.Context, decisionID, viewerID uuid.UUID, value int, shadowed bool) (VoteSummary, error)
+	Summary(ctx context.Context, decisionID uuid.UUID, viewerID *uuid.UUID) (VoteSummary, error)
+}
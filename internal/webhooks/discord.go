@@ -0,0 +1,131 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// discordMaxContent is Discord's limit on a message's content.
+	discordMaxContent = 2000
+	// discordMaxResponseLines caps how many new responses one message
+	// lists; the rest are summarised as a count.
+	discordMaxResponseLines = 10
+	discordUsername         = "RateMyLifeDecision"
+)
+
+type discordMessage struct {
+	Username        string                 `json:"username"`
+	Content         string                 `json:"content"`
+	AllowedMentions discordAllowedMentions `json:"allowed_mentions"`
+}
+
+// discordAllowedMentions with an empty Parse keeps text from decisions and
+// comments from pinging anyone in the channel.
+type discordAllowedMentions struct {
+	Parse []string `json:"parse"`
+}
+
+// sendDiscord posts every delivery in group, all for the same webhook, as
+// one message.
+func (d *Dispatcher) sendDiscord(ctx context.Context, group []delivery) (int, error) {
+	body, err := json.Marshal(discordMessage{
+		Username:        discordUsername,
+		Content:         renderDiscord(group),
+		AllowedMentions: discordAllowedMentions{Parse: []string{}},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return d.post(ctx, group[0].url, map[string]string{"User-Agent": "ratemylifedecision-webhooks"}, body)
+}
+
+func renderDiscord(group []delivery) string {
+	var (
+		decision  Decision
+		responses []string
+		extra     int
+		other     []string
+	)
+	for _, dl := range group {
+		var p payload
+		if err := json.Unmarshal(dl.payload, &p); err != nil {
+			continue
+		}
+		decision = p.Decision
+		switch p.Event {
+		case EventNewResponse:
+			if len(responses) == discordMaxResponseLines {
+				extra++
+				continue
+			}
+			responses = append(responses, discordResponseLine(p.Data))
+		case EventVoteMilestone:
+			other = append(other, fmt.Sprintf("🎉 The post reached %v votes.", p.Data["vote_count"]))
+		case EventDecisionClosed:
+			other = append(other, discordClosedLine(p.Data))
+		case EventCloseReminder:
+			other = append(other, fmt.Sprintf("⏰ Closes %v with only %v responses. Share it again to get more input:",
+				p.Data["closes_in"], p.Data["response_count"]))
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s**\n", discordEscape(decision.Title))
+	if len(responses) > 0 {
+		noun := "responses"
+		if len(responses)+extra == 1 {
+			noun = "response"
+		}
+		fmt.Fprintf(&b, "%d new %s:\n", len(responses)+extra, noun)
+		for _, line := range responses {
+			b.WriteString("• " + line + "\n")
+		}
+		if extra > 0 {
+			fmt.Fprintf(&b, "…and %d more\n", extra)
+		}
+	}
+	for _, line := range other {
+		b.WriteString(line + "\n")
+	}
+	link := "<" + decision.ShareURL + ">"
+	content := strings.TrimRight(b.String(), "\n")
+	if room := discordMaxContent - utf8.RuneCountInString(link) - 2; utf8.RuneCountInString(content) > room {
+		content = string([]rune(content)[:room-1]) + "…"
+	}
+	return content + "\n" + link
+}
+
+func discordResponseLine(data map[string]any) string {
+	line := fmt.Sprintf("%v/5", data["rating"])
+	if emoji, ok := data["emoji"].(string); ok && emoji != "" {
+		line = emoji + " " + line
+	}
+	if comment, ok := data["comment"].(string); ok && strings.TrimSpace(comment) != "" {
+		line += ": " + discordEscape(strings.Join(strings.Fields(comment), " "))
+	}
+	return line
+}
+
+func discordClosedLine(data map[string]any) string {
+	verdict, _ := data["recommendation"].(string)
+	switch verdict {
+	case "yes":
+		verdict = "do it"
+	case "no":
+		verdict = "don't do it"
+	default:
+		return "🔒 Voting has closed."
+	}
+	return fmt.Sprintf("🔒 Voting has closed. Final verdict from %v responses: **%s**.", data["response_count"], verdict)
+}
+
+// discordEscape neutralises Discord markdown in user-supplied text.
+func discordEscape(text string) string {
+	return strings.NewReplacer(
+		`\`, `\\`, "*", `\*`, "_", `\_`, "~", `\~`, "`", "\\`", "|", `\|`, ">", `\>`,
+	).Replace(text)
+}
@@ -0,0 +1,418 @@
+// Package webhooks delivers the webhooks creators register on their
+// decisions. Events are written to webhook_deliveries when they happen; a
+// background loop posts them, signed with the webhook's secret, and retries
+// failures with exponential backoff. The rows double as the delivery log.
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"ratemylifedecision/internal/logging"
+	"ratemylifedecision/internal/safehttp"
+)
+
+type Event string
+
+const (
+	EventNewResponse    Event = "new_response"
+	EventVoteMilestone  Event = "vote_milestone"
+	EventDecisionClosed Event = "decision_closed"
+	// EventCloseReminder fires once, shortly before closes_at, for
+	// decisions that have few responses.
+	EventCloseReminder Event = "close_reminder"
+)
+
+var AllEvents = []Event{EventNewResponse, EventVoteMilestone, EventDecisionClosed, EventCloseReminder}
+
+func ParseEvent(raw string) (Event, bool) {
+	for _, e := range AllEvents {
+		if string(e) == raw {
+			return e, true
+		}
+	}
+	return "", false
+}
+
+// Request headers sent with every delivery. The signature is
+// "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">" keyed by the
+// webhook's secret; receivers should also reject stale timestamps.
+// Deliveries raised by an API request also carry its X-Request-Id.
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+const (
+	// MaxAttempts is how many times a delivery is tried before it is
+	// marked failed for good.
+	MaxAttempts    = 8
+	baseRetryDelay = 30 * time.Second
+	maxRetryDelay  = 6 * time.Hour
+	// claimLease is how long a claimed delivery is hidden from other
+	// instances; a crash mid-send makes it due again after this.
+	claimLease  = 2 * time.Minute
+	batchSize   = 50
+	sendTimeout = 10 * time.Second
+	maxErrorLen = 512
+	// maxDrainBytes is how much of a reply is read, and thrown away, so
+	// the connection can be reused.
+	maxDrainBytes = 64 * 1024
+	// discordMinInterval spaces out posts to one Discord webhook; events
+	// that arrive in between are sent together in the next message.
+	discordMinInterval = 30 * time.Second
+)
+
+// Decision identifies the decision an event is about.
+type Decision struct {
+	ID       uuid.UUID `json:"id"`
+	Slug     string    `json:"slug"`
+	Title    string    `json:"title"`
+	ShareURL string    `json:"share_url"`
+}
+
+type payload struct {
+	Event      Event          `json:"event"`
+	OccurredAt time.Time      `json:"occurred_at"`
+	Decision   Decision       `json:"decision"`
+	Data       map[string]any `json:"data,omitempty"`
+}
+
+// Kind selects the payload format. Generic webhooks get the signed JSON
+// payload; Discord webhooks get chat messages, batched per webhook.
+type Kind string
+
+const (
+	KindGeneric Kind = "generic"
+	KindDiscord Kind = "discord"
+)
+
+// ClosedDataFunc supplies the data sent with decision_closed, such as the
+// final recommendation.
+type ClosedDataFunc func(ctx context.Context, decisionID uuid.UUID) (map[string]any, error)
+
+type Dispatcher struct {
+	db     *sql.DB
+	client *http.Client
+	// shareBaseURL prefixes /d/{slug} in decision_closed payloads, which are
+	// built here rather than by a request handler.
+	shareBaseURL string
+	closedData   ClosedDataFunc
+}
+
+func NewDispatcher(db *sql.DB, shareBaseURL string, closedData ClosedDataFunc) *Dispatcher {
+	return &Dispatcher{
+		db:           db,
+		client:       safehttp.Client(sendTimeout),
+		shareBaseURL: shareBaseURL,
+		closedData:   closedData,
+	}
+}
+
+// Enqueue records event for every webhook on the decision that subscribed
+// to it. dedupeKey distinguishes repeated events of the same kind, e.g.
+// each vote milestone, so each is delivered at most once per webhook. The
+// request ID in ctx, if any, is sent with the delivery.
+func (d *Dispatcher) Enqueue(ctx context.Context, event Event, decision Decision, dedupeKey string, data map[string]any) error {
+	body, err := json.Marshal(payload{
+		Event:      event,
+		OccurredAt: time.Now().UTC(),
+		Decision:   decision,
+		Data:       data,
+	})
+	if err != nil {
+		return err
+	}
+	var requestID *string
+	if id := logging.RequestID(ctx); id != "" {
+		requestID = &id
+	}
+	_, err = d.db.ExecContext(ctx, `
+		INSERT INTO webhook_deliveries (id, webhook_id, event, dedupe_key, payload, status, request_id)
+		SELECT gen_random_uuid(), w.id, $2, $3, $4, 'pending', $5
+		FROM decision_webhooks w
+		WHERE w.decision_id = $1 AND $2 = ANY(w.events)
+		ON CONFLICT (webhook_id, event, dedupe_key) DO NOTHING
+	`, decision.ID, string(event), dedupeKey, body, requestID)
+	if err != nil {
+		return fmt.Errorf("enqueue %s webhooks: %w", event, err)
+	}
+	return nil
+}
+
+// Process enqueues decision_closed events and sends one batch of due
+// deliveries. The server's job scheduler calls it periodically.
+func (d *Dispatcher) Process(ctx context.Context) error {
+	closeErr := d.enqueueClosed(ctx)
+	_, deliverErr := d.DeliverDue(ctx)
+	return errors.Join(closeErr, deliverErr)
+}
+
+// enqueueClosed records decision_closed for decisions whose closes_at has
+// passed. Webhooks registered after the close are not told about it.
+func (d *Dispatcher) enqueueClosed(ctx context.Context) error {
+	rows, err := d.db.QueryContext(ctx, `
+		SELECT d.id, d.slug, d.title, d.closes_at
+		FROM decisions d
+		WHERE d.closes_at <= now()
+		  AND EXISTS (
+			SELECT 1 FROM decision_webhooks w
+			WHERE w.decision_id = d.id
+			  AND 'decision_closed' = ANY(w.events)
+			  AND d.closes_at > w.created_at
+			  AND NOT EXISTS (
+				SELECT 1 FROM webhook_deliveries wd
+				WHERE wd.webhook_id = w.id AND wd.event = 'decision_closed'
+			  )
+		  )
+		LIMIT $1
+	`, batchSize)
+	if err != nil {
+		return err
+	}
+	type closed struct {
+		decision Decision
+		closesAt time.Time
+	}
+	var due []closed
+	for rows.Next() {
+		var c closed
+		if err := rows.Scan(&c.decision.ID, &c.decision.Slug, &c.decision.Title, &c.closesAt); err != nil {
+			rows.Close()
+			return err
+		}
+		c.decision.ShareURL = d.shareBaseURL + "/d/" + c.decision.Slug
+		due = append(due, c)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, c := range due {
+		var data map[string]any
+		if d.closedData != nil {
+			if data, err = d.closedData(ctx, c.decision.ID); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+		body, err := json.Marshal(payload{
+			Event:      EventDecisionClosed,
+			OccurredAt: c.closesAt.UTC(),
+			Decision:   c.decision,
+			Data:       data,
+		})
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if _, err := d.db.ExecContext(ctx, `
+			INSERT INTO webhook_deliveries (id, webhook_id, event, dedupe_key, payload, status)
+			SELECT gen_random_uuid(), w.id, 'decision_closed', 'closed', $2, 'pending'
+			FROM decision_webhooks w
+			WHERE w.decision_id = $1
+			  AND 'decision_closed' = ANY(w.events)
+			  AND w.created_at < $3
+			ON CONFLICT (webhook_id, event, dedupe_key) DO NOTHING
+		`, c.decision.ID, body, c.closesAt); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type delivery struct {
+	id        uuid.UUID
+	webhookID uuid.UUID
+	kind      Kind
+	event     string
+	payload   []byte
+	attempts  int
+	url       string
+	secret    string
+	requestID *string
+}
+
+// DeliverDue sends one batch of due deliveries and returns how many were
+// attempted. Rows are claimed with FOR UPDATE SKIP LOCKED and a lease, so
+// several server instances can run it side by side. A Discord webhook is
+// only claimed once discordMinInterval has passed since its last post, and
+// then all its due deliveries go out as one message.
+func (d *Dispatcher) DeliverDue(ctx context.Context) (int, error) {
+	rows, err := d.db.QueryContext(ctx, `
+		WITH due AS (
+			SELECT wd.id FROM webhook_deliveries wd
+			JOIN decision_webhooks w ON w.id = wd.webhook_id
+			WHERE wd.status = 'pending' AND wd.next_attempt_at <= now()
+			  AND (w.kind <> 'discord' OR w.last_sent_at IS NULL OR w.last_sent_at <= now() - $3 * interval '1 second')
+			ORDER BY wd.next_attempt_at
+			LIMIT $1
+			FOR UPDATE OF wd SKIP LOCKED
+		)
+		UPDATE webhook_deliveries wd
+		SET attempts = wd.attempts + 1, next_attempt_at = now() + $2 * interval '1 second'
+		FROM due, decision_webhooks w
+		WHERE wd.id = due.id AND w.id = wd.webhook_id
+		RETURNING wd.id, w.id, w.kind, wd.event, wd.payload, wd.attempts, w.url, w.secret, wd.request_id
+	`, batchSize, claimLease.Seconds(), discordMinInterval.Seconds())
+	if err != nil {
+		return 0, err
+	}
+	var (
+		batch   []delivery
+		discord = make(map[uuid.UUID][]delivery)
+	)
+	for rows.Next() {
+		var dl delivery
+		if err := rows.Scan(&dl.id, &dl.webhookID, &dl.kind, &dl.event, &dl.payload, &dl.attempts, &dl.url, &dl.secret, &dl.requestID); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if dl.kind == KindDiscord {
+			discord[dl.webhookID] = append(discord[dl.webhookID], dl)
+			continue
+		}
+		batch = append(batch, dl)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	var errs []error
+	for _, dl := range batch {
+		statusCode, sendErr := d.send(ctx, dl)
+		if err := d.finish(ctx, dl, statusCode, sendErr); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	attempted := len(batch)
+	for webhookID, group := range discord {
+		attempted += len(group)
+		statusCode, sendErr := d.sendDiscord(ctx, group)
+		if _, err := d.db.ExecContext(ctx, `
+			UPDATE decision_webhooks SET last_sent_at = now() WHERE id = $1
+		`, webhookID); err != nil {
+			errs = append(errs, err)
+		}
+		for _, dl := range group {
+			if err := d.finish(ctx, dl, statusCode, sendErr); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return attempted, errors.Join(errs...)
+}
+
+func (d *Dispatcher) send(ctx context.Context, dl delivery) (int, error) {
+	if dl.requestID != nil {
+		ctx = logging.WithRequestID(ctx, *dl.requestID)
+	}
+	headers := map[string]string{
+		"User-Agent":    "ratemylifedecision-webhooks",
+		EventHeader:     dl.event,
+		DeliveryHeader:  dl.id.String(),
+		SignatureHeader: Sign(dl.secret, time.Now().Unix(), dl.payload),
+	}
+	return d.post(ctx, dl.url, headers, dl.payload)
+}
+
+func (d *Dispatcher) post(ctx context.Context, url string, headers map[string]string, body []byte) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	// The receiver's reply is not kept: last_error is shown to the
+	// creator, and the receiver may not be theirs.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainBytes))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+func (d *Dispatcher) finish(ctx context.Context, dl delivery, statusCode int, sendErr error) error {
+	code := sql.NullInt32{Int32: int32(statusCode), Valid: statusCode != 0}
+	var err error
+	switch {
+	case sendErr == nil:
+		_, err = d.db.ExecContext(ctx, `
+			UPDATE webhook_deliveries
+			SET status = 'sent', last_status_code = $2, last_error = NULL, delivered_at = now()
+			WHERE id = $1
+		`, dl.id, code)
+	case dl.attempts >= MaxAttempts:
+		_, err = d.db.ExecContext(ctx, `
+			UPDATE webhook_deliveries
+			SET status = 'failed', last_status_code = $2, last_error = $3
+			WHERE id = $1
+		`, dl.id, code, truncate(sendErr.Error()))
+	default:
+		_, err = d.db.ExecContext(ctx, `
+			UPDATE webhook_deliveries
+			SET last_status_code = $2, last_error = $3, next_attempt_at = now() + $4 * interval '1 second'
+			WHERE id = $1
+		`, dl.id, code, truncate(sendErr.Error()), retryDelay(dl.attempts).Seconds())
+	}
+	if err != nil {
+		return fmt.Errorf("record webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// retryDelay doubles from baseRetryDelay after each failed attempt.
+func retryDelay(attempts int) time.Duration {
+	delay := baseRetryDelay << (attempts - 1)
+	if delay <= 0 || delay > maxRetryDelay {
+		return maxRetryDelay
+	}
+	return delay
+}
+
+// Sign returns the SignatureHeader value for body sent at timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	ts := strconv.FormatInt(timestamp, 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func truncate(s string) string {
+	if len(s) > maxErrorLen {
+		return strings.ToValidUTF8(s[:maxErrorLen], "")
+	}
+	return s
+}
@@ -0,0 +1 @@
+-- The unique constraint is owned by 000002_decision_votes; nothing to undo here.
@@ -0,0 +1,20 @@
+DELETE FROM decision_votes dv
+USING decision_votes newer
+WHERE dv.decision_id = newer.decision_id
+  AND dv.voter_viewer_id = newer.voter_viewer_id
+  AND (dv.created_at, dv.id) < (newer.created_at, newer.id);
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_constraint
+        WHERE conname = 'decision_votes_decision_id_voter_viewer_id_key'
+          AND conrelid = 'decision_votes'::regclass
+    ) THEN
+        ALTER TABLE decision_votes
+        ADD CONSTRAINT decision_votes_decision_id_voter_viewer_id_key
+        UNIQUE (decision_id, voter_viewer_id);
+    END IF;
+END
+$$;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_notification_deliveries_status;
+DROP TABLE IF EXISTS notification_deliveries;
+DROP TABLE IF EXISTS notification_subscription_channels;
+DROP INDEX IF EXISTS idx_notification_subscriptions_decision_id;
+DROP TABLE IF EXISTS notification_subscriptions;
@@ -0,0 +1,34 @@
+CREATE TABLE notification_subscriptions (
+    id UUID PRIMARY KEY,
+    decision_id UUID NOT NULL REFERENCES decisions(id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL,
+    events TEXT[] NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_notification_subscriptions_decision_id ON notification_subscriptions (decision_id);
+
+CREATE TABLE notification_subscription_channels (
+    subscription_id UUID NOT NULL REFERENCES notification_subscriptions(id) ON DELETE CASCADE,
+    channel TEXT NOT NULL CHECK (channel IN ('email', 'push', 'webhook', 'slack')),
+    address TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (subscription_id, channel)
+);
+
+CREATE TABLE notification_deliveries (
+    id UUID PRIMARY KEY,
+    subscription_id UUID NOT NULL REFERENCES notification_subscriptions(id) ON DELETE CASCADE,
+    channel TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    dedupe_key TEXT NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('pending', 'sent', 'failed')),
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    delivered_at TIMESTAMPTZ NULL,
+    UNIQUE (subscription_id, channel, kind, dedupe_key)
+);
+
+CREATE INDEX idx_notification_deliveries_status ON notification_deliveries (status, created_at);
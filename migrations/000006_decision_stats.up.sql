@@ -0,0 +1,73 @@
+CREATE TABLE decision_stats (
+    decision_id UUID PRIMARY KEY REFERENCES decisions(id) ON DELETE CASCADE,
+    response_count INT NOT NULL DEFAULT 0,
+    rating_1 INT NOT NULL DEFAULT 0,
+    rating_2 INT NOT NULL DEFAULT 0,
+    rating_3 INT NOT NULL DEFAULT 0,
+    rating_4 INT NOT NULL DEFAULT 0,
+    rating_5 INT NOT NULL DEFAULT 0,
+    rating_sum BIGINT NOT NULL DEFAULT 0,
+    suggestion_1 INT NOT NULL DEFAULT 0,
+    suggestion_2 INT NOT NULL DEFAULT 0,
+    suggestion_3 INT NOT NULL DEFAULT 0,
+    emoji_counts JSONB NOT NULL DEFAULT '{}'::jsonb,
+    vote_sum INT NOT NULL DEFAULT 0,
+    vote_count INT NOT NULL DEFAULT 0,
+    upvotes INT NOT NULL DEFAULT 0,
+    downvotes INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+INSERT INTO decision_stats (
+    decision_id,
+    response_count,
+    rating_1, rating_2, rating_3, rating_4, rating_5,
+    rating_sum,
+    suggestion_1, suggestion_2, suggestion_3,
+    emoji_counts,
+    vote_sum, vote_count, upvotes, downvotes
+)
+SELECT
+    d.id,
+    COALESCE(r.response_count, 0),
+    COALESCE(r.rating_1, 0), COALESCE(r.rating_2, 0), COALESCE(r.rating_3, 0), COALESCE(r.rating_4, 0), COALESCE(r.rating_5, 0),
+    COALESCE(r.rating_sum, 0),
+    COALESCE(r.suggestion_1, 0), COALESCE(r.suggestion_2, 0), COALESCE(r.suggestion_3, 0),
+    COALESCE(e.emoji_counts, '{}'::jsonb),
+    COALESCE(v.vote_sum, 0), COALESCE(v.vote_count, 0), COALESCE(v.upvotes, 0), COALESCE(v.downvotes, 0)
+FROM decisions d
+LEFT JOIN (
+    SELECT
+        decision_id,
+        COUNT(*) AS response_count,
+        COUNT(*) FILTER (WHERE rating = 1) AS rating_1,
+        COUNT(*) FILTER (WHERE rating = 2) AS rating_2,
+        COUNT(*) FILTER (WHERE rating = 3) AS rating_3,
+        COUNT(*) FILTER (WHERE rating = 4) AS rating_4,
+        COUNT(*) FILTER (WHERE rating = 5) AS rating_5,
+        SUM(rating) AS rating_sum,
+        COUNT(*) FILTER (WHERE suggestion = 1) AS suggestion_1,
+        COUNT(*) FILTER (WHERE suggestion = 2) AS suggestion_2,
+        COUNT(*) FILTER (WHERE suggestion = 3) AS suggestion_3
+    FROM responses
+    GROUP BY decision_id
+) r ON r.decision_id = d.id
+LEFT JOIN (
+    SELECT decision_id, jsonb_object_agg(emoji, count) AS emoji_counts
+    FROM (
+        SELECT decision_id, emoji, COUNT(*) AS count
+        FROM responses
+        GROUP BY decision_id, emoji
+    ) grouped
+    GROUP BY decision_id
+) e ON e.decision_id = d.id
+LEFT JOIN (
+    SELECT
+        decision_id,
+        SUM(value) AS vote_sum,
+        COUNT(*) AS vote_count,
+        COUNT(*) FILTER (WHERE value = 1) AS upvotes,
+        COUNT(*) FILTER (WHERE value = -1) AS downvotes
+    FROM decision_votes
+    GROUP BY decision_id
+) v ON v.decision_id = d.id;
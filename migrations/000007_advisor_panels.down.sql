@@ -0,0 +1,9 @@
+ALTER TABLE responses
+DROP COLUMN IF EXISTS panel_member_id;
+
+DROP INDEX IF EXISTS idx_decision_panel_members_invite;
+DROP TABLE IF EXISTS decision_panel_members;
+
+ALTER TABLE decisions
+DROP COLUMN IF EXISTS panel_only,
+DROP COLUMN IF EXISTS creator_token_hash;
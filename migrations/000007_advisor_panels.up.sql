@@ -0,0 +1,20 @@
+ALTER TABLE decisions
+ADD COLUMN creator_token_hash TEXT NULL,
+ADD COLUMN panel_only BOOLEAN NOT NULL DEFAULT false;
+
+CREATE TABLE decision_panel_members (
+    id UUID PRIMARY KEY,
+    decision_id UUID NOT NULL REFERENCES decisions(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('email', 'viewer')),
+    value TEXT NOT NULL,
+    invite_token_hash TEXT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (decision_id, kind, value)
+);
+
+CREATE UNIQUE INDEX idx_decision_panel_members_invite
+ON decision_panel_members (decision_id, invite_token_hash)
+WHERE invite_token_hash IS NOT NULL;
+
+ALTER TABLE responses
+ADD COLUMN panel_member_id UUID NULL REFERENCES decision_panel_members(id) ON DELETE SET NULL;
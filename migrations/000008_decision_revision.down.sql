@@ -0,0 +1,10 @@
+DROP TRIGGER IF EXISTS decision_panel_members_touch_decision_revision ON decision_panel_members;
+DROP TRIGGER IF EXISTS decision_votes_touch_decision_revision ON decision_votes;
+DROP TRIGGER IF EXISTS responses_touch_decision_revision ON responses;
+DROP FUNCTION IF EXISTS touch_decision_revision();
+
+DROP TRIGGER IF EXISTS decisions_bump_revision ON decisions;
+DROP FUNCTION IF EXISTS bump_decision_revision();
+
+ALTER TABLE decisions
+DROP COLUMN IF EXISTS revision;
@@ -0,0 +1,41 @@
+ALTER TABLE decisions
+ADD COLUMN revision BIGINT NOT NULL DEFAULT 0;
+
+CREATE FUNCTION bump_decision_revision() RETURNS trigger AS $$
+BEGIN
+    IF NEW.revision = OLD.revision THEN
+        NEW.revision := OLD.revision + 1;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER decisions_bump_revision
+BEFORE UPDATE ON decisions
+FOR EACH ROW EXECUTE FUNCTION bump_decision_revision();
+
+CREATE FUNCTION touch_decision_revision() RETURNS trigger AS $$
+DECLARE
+    target UUID;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        target := OLD.decision_id;
+    ELSE
+        target := NEW.decision_id;
+    END IF;
+    UPDATE decisions SET revision = revision + 1 WHERE id = target;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER responses_touch_decision_revision
+AFTER INSERT OR UPDATE OR DELETE ON responses
+FOR EACH ROW EXECUTE FUNCTION touch_decision_revision();
+
+CREATE TRIGGER decision_votes_touch_decision_revision
+AFTER INSERT OR UPDATE OR DELETE ON decision_votes
+FOR EACH ROW EXECUTE FUNCTION touch_decision_revision();
+
+CREATE TRIGGER decision_panel_members_touch_decision_revision
+AFTER INSERT OR UPDATE OR DELETE ON decision_panel_members
+FOR EACH ROW EXECUTE FUNCTION touch_decision_revision();
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS decision_outcomes;
+
+DROP INDEX IF EXISTS idx_decisions_category;
+
+ALTER TABLE decisions
+DROP COLUMN IF EXISTS category;
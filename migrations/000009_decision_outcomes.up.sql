@@ -0,0 +1,14 @@
+ALTER TABLE decisions
+ADD COLUMN category TEXT NULL;
+
+CREATE INDEX idx_decisions_category ON decisions (category);
+
+CREATE TABLE decision_outcomes (
+    decision_id UUID PRIMARY KEY REFERENCES decisions(id) ON DELETE CASCADE,
+    did_it BOOLEAN NOT NULL,
+    satisfaction INT NULL CHECK (satisfaction BETWEEN 1 AND 5),
+    recommendation TEXT NOT NULL CHECK (recommendation IN ('yes', 'no')),
+    recommendation_score DOUBLE PRECISION NOT NULL,
+    recorded_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
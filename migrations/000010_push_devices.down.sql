@@ -0,0 +1,4 @@
+DELETE FROM notification_subscription_channels WHERE channel = 'push';
+
+DROP INDEX IF EXISTS idx_push_devices_viewer_id;
+DROP TABLE IF EXISTS push_devices;
@@ -0,0 +1,20 @@
+CREATE TABLE push_devices (
+    id UUID PRIMARY KEY,
+    viewer_id UUID NOT NULL,
+    platform TEXT NOT NULL CHECK (platform IN ('apns', 'fcm')),
+    token TEXT NOT NULL,
+    secret_hash TEXT NOT NULL,
+    events TEXT[] NOT NULL DEFAULT ARRAY['decision_closed', 'milestone', 'reminder'],
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    invalidated_at TIMESTAMPTZ NULL,
+    invalid_reason TEXT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (platform, token)
+);
+
+CREATE INDEX idx_push_devices_viewer_id ON push_devices (viewer_id);
+
+-- Push subscription channels used to carry a raw device token. They now
+-- reference a registered device, so the old rows can no longer be delivered.
+DELETE FROM notification_subscription_channels WHERE channel = 'push';
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS rm_category_insights;
+DROP INDEX IF EXISTS idx_rm_decision_activity_trend;
+DROP INDEX IF EXISTS idx_rm_decision_activity_response_count;
+DROP INDEX IF EXISTS idx_rm_decision_activity_vote_score;
+DROP TABLE IF EXISTS rm_decision_activity;
+DROP INDEX IF EXISTS idx_decision_events_decision_id;
+DROP INDEX IF EXISTS idx_decision_events_pending;
+DROP TABLE IF EXISTS decision_events;
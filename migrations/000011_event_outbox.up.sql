@@ -0,0 +1,61 @@
+CREATE TABLE decision_events (
+    id BIGSERIAL PRIMARY KEY,
+    decision_id UUID NOT NULL REFERENCES decisions(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    projected_at TIMESTAMPTZ NULL
+);
+
+CREATE INDEX idx_decision_events_pending ON decision_events (id) WHERE projected_at IS NULL;
+CREATE INDEX idx_decision_events_decision_id ON decision_events (decision_id, id);
+
+CREATE TABLE rm_decision_activity (
+    decision_id UUID PRIMARY KEY REFERENCES decisions(id) ON DELETE CASCADE,
+    category TEXT NULL,
+    response_count INT NOT NULL DEFAULT 0,
+    rating_sum BIGINT NOT NULL DEFAULT 0,
+    vote_score INT NOT NULL DEFAULT 0,
+    vote_count INT NOT NULL DEFAULT 0,
+    votes_event_id BIGINT NOT NULL DEFAULT 0,
+    trend_score DOUBLE PRECISION NOT NULL DEFAULT 0,
+    trend_at TIMESTAMPTZ NOT NULL,
+    last_activity_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_rm_decision_activity_vote_score ON rm_decision_activity (vote_score DESC);
+CREATE INDEX idx_rm_decision_activity_response_count ON rm_decision_activity (response_count DESC);
+CREATE INDEX idx_rm_decision_activity_trend ON rm_decision_activity (trend_at DESC);
+
+CREATE TABLE rm_category_insights (
+    category TEXT PRIMARY KEY,
+    decision_count INT NOT NULL DEFAULT 0,
+    response_count INT NOT NULL DEFAULT 0,
+    rating_sum BIGINT NOT NULL DEFAULT 0,
+    vote_count INT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Seed the outbox from existing data so the read models can be built (and
+-- later rebuilt) purely by replay.
+INSERT INTO decision_events (decision_id, kind, payload, created_at)
+SELECT id, 'decision_created', jsonb_build_object('category', category), created_at
+FROM decisions
+ORDER BY created_at, id;
+
+INSERT INTO decision_events (decision_id, kind, payload, created_at)
+SELECT decision_id, 'response_created', jsonb_build_object('rating', rating, 'suggestion', suggestion), created_at
+FROM responses
+ORDER BY created_at, id;
+
+INSERT INTO decision_events (decision_id, kind, payload, created_at)
+SELECT
+    decision_id,
+    'vote_changed',
+    jsonb_build_object('vote_sum', SUM(value), 'vote_count', COUNT(*)),
+    MAX(created_at)
+FROM decision_votes
+GROUP BY decision_id
+ORDER BY MAX(created_at);
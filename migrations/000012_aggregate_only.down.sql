@@ -0,0 +1,2 @@
+ALTER TABLE decisions
+DROP COLUMN IF EXISTS aggregate_only;
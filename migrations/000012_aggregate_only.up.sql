@@ -0,0 +1,2 @@
+ALTER TABLE decisions
+ADD COLUMN aggregate_only BOOLEAN NOT NULL DEFAULT false;
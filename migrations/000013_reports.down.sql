@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS reports;
+
+ALTER TABLE responses
+DROP COLUMN IF EXISTS hidden_at;
+
+ALTER TABLE decisions
+DROP COLUMN IF EXISTS hidden_at;
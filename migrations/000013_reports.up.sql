@@ -0,0 +1,20 @@
+ALTER TABLE decisions
+ADD COLUMN hidden_at TIMESTAMPTZ NULL;
+
+ALTER TABLE responses
+ADD COLUMN hidden_at TIMESTAMPTZ NULL;
+
+CREATE TABLE reports (
+    id UUID PRIMARY KEY,
+    target_kind TEXT NOT NULL CHECK (target_kind IN ('decision', 'response')),
+    target_id UUID NOT NULL,
+    reporter_viewer_id UUID NOT NULL,
+    reason TEXT NOT NULL CHECK (reason IN ('spam', 'harassment', 'hate', 'sexual', 'self_harm', 'personal_info', 'other')),
+    details TEXT NULL,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'dismissed', 'actioned')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    reviewed_at TIMESTAMPTZ NULL,
+    UNIQUE (target_kind, target_id, reporter_viewer_id)
+);
+
+CREATE INDEX idx_reports_pending ON reports (target_kind, target_id) WHERE status = 'pending';
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS banned_viewers;
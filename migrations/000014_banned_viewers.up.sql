@@ -0,0 +1,5 @@
+CREATE TABLE banned_viewers (
+    viewer_id UUID PRIMARY KEY,
+    reason TEXT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,8 @@
+ALTER TABLE decision_votes
+DROP COLUMN IF EXISTS shadowed;
+
+ALTER TABLE responses
+DROP COLUMN IF EXISTS shadowed;
+
+ALTER TABLE banned_viewers
+DROP COLUMN IF EXISTS shadow;
@@ -0,0 +1,10 @@
+ALTER TABLE banned_viewers
+ADD COLUMN shadow BOOLEAN NOT NULL DEFAULT false;
+
+-- Rows written by a shadowbanned viewer are kept but left out of stats,
+-- recommendations and response lists.
+ALTER TABLE responses
+ADD COLUMN shadowed BOOLEAN NOT NULL DEFAULT false;
+
+ALTER TABLE decision_votes
+ADD COLUMN shadowed BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,11 @@
+-- cidr holds a canonical prefix such as 203.0.113.0/24; the server parses
+-- and normalizes it, so plain TEXT keeps the generated models dependency-free.
+CREATE TABLE ip_rules (
+    id UUID PRIMARY KEY,
+    cidr TEXT NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('allow', 'block')),
+    reason TEXT NULL,
+    expires_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (cidr, action)
+);
@@ -0,0 +1,33 @@
+-- Creator-registered webhooks. secret signs every delivery, so unlike the
+-- other tokens it is stored as issued rather than hashed.
+CREATE TABLE decision_webhooks (
+    id UUID PRIMARY KEY,
+    decision_id UUID NOT NULL REFERENCES decisions(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    events TEXT[] NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_decision_webhooks_decision_id ON decision_webhooks (decision_id);
+
+-- One row per event per webhook, doubling as the delivery log. payload is
+-- fixed when the event happens so every retry sends the same body.
+CREATE TABLE webhook_deliveries (
+    id UUID PRIMARY KEY,
+    webhook_id UUID NOT NULL REFERENCES decision_webhooks(id) ON DELETE CASCADE,
+    event TEXT NOT NULL,
+    dedupe_key TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status TEXT NOT NULL CHECK (status IN ('pending', 'sent', 'failed')),
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    last_status_code INT NULL,
+    last_error TEXT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    delivered_at TIMESTAMPTZ NULL,
+    UNIQUE (webhook_id, event, dedupe_key)
+);
+
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX idx_webhook_deliveries_webhook_id ON webhook_deliveries (webhook_id, created_at DESC);
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_decisions_public_created_at;
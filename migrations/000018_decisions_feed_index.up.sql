@@ -0,0 +1,3 @@
+-- Serves /feed.xml: the newest public decisions first.
+CREATE INDEX idx_decisions_public_created_at ON decisions (created_at DESC)
+WHERE hidden_at IS NULL AND NOT panel_only;
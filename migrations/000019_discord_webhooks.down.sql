@@ -0,0 +1,3 @@
+ALTER TABLE decision_webhooks
+    DROP COLUMN last_sent_at,
+    DROP COLUMN kind;
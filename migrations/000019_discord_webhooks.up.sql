@@ -0,0 +1,5 @@
+-- kind picks the payload format. Discord webhooks are posted to at most
+-- every 30 seconds; last_sent_at is when the last batch went out.
+ALTER TABLE decision_webhooks
+    ADD COLUMN kind TEXT NOT NULL DEFAULT 'generic' CHECK (kind IN ('generic', 'discord')),
+    ADD COLUMN last_sent_at TIMESTAMPTZ NULL;
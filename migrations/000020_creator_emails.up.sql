@@ -0,0 +1,17 @@
+-- Optional creator email, used for the results digest once the decision
+-- closes. Nothing is sent to it until the confirmation link is followed;
+-- only the confirmation token's hash is kept.
+CREATE TABLE creator_emails (
+    decision_id UUID PRIMARY KEY REFERENCES decisions(id) ON DELETE CASCADE,
+    email TEXT NOT NULL,
+    confirm_token_hash TEXT NOT NULL UNIQUE,
+    confirmed_at TIMESTAMPTZ NULL,
+    digest_attempts INT NOT NULL DEFAULT 0,
+    digest_claimed_at TIMESTAMPTZ NULL,
+    digest_sent_at TIMESTAMPTZ NULL,
+    digest_last_error TEXT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_creator_emails_digest_due ON creator_emails (decision_id)
+WHERE confirmed_at IS NOT NULL AND digest_sent_at IS NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_decisions_due_close;
+ALTER TABLE decisions DROP COLUMN closed_at;
@@ -0,0 +1,9 @@
+-- closed_at is set by the close job once closes_at passes; it is what
+-- triggers close notifications. Decisions that closed before the job
+-- existed are backfilled so they are not announced now.
+ALTER TABLE decisions ADD COLUMN closed_at TIMESTAMPTZ NULL;
+
+UPDATE decisions SET closed_at = closes_at WHERE closes_at <= now();
+
+CREATE INDEX idx_decisions_due_close ON decisions (closes_at)
+WHERE closed_at IS NULL AND closes_at IS NOT NULL;
@@ -0,0 +1,8 @@
+-- One row per decision whose creator has been sent a closing-soon
+-- reminder. Inserting the row claims the reminder, so it goes out at most
+-- once however many instances run the job or restart.
+CREATE TABLE decision_reminders (
+    decision_id UUID PRIMARY KEY REFERENCES decisions(id) ON DELETE CASCADE,
+    response_count INT NOT NULL,
+    sent_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_decisions_retention;
+ALTER TABLE decisions DROP COLUMN archived_at;
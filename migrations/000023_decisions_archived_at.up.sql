@@ -0,0 +1,7 @@
+-- archived_at is set when the retention job anonymizes a closed decision:
+-- its description, comments and everything tying it to people are removed,
+-- leaving the title and aggregate results.
+ALTER TABLE decisions ADD COLUMN archived_at TIMESTAMPTZ NULL;
+
+CREATE INDEX idx_decisions_retention ON decisions (closed_at)
+WHERE closed_at IS NOT NULL;
@@ -0,0 +1,4 @@
+-- language is the detected language of the comment ("en", "es", ...), used
+-- to pick sentiment wordlists. It is NULL when there is no comment or its
+-- language could not be told; those are scored after detecting again.
+ALTER TABLE responses ADD COLUMN language TEXT NULL;
@@ -0,0 +1,3 @@
+-- quorum is how many responses a decision needs before its stats and
+-- recommendation are shown; 0 shows them from the first response.
+ALTER TABLE decisions ADD COLUMN quorum INT NOT NULL DEFAULT 0 CHECK (quorum >= 0);
@@ -0,0 +1,4 @@
+ALTER TABLE decisions
+DROP CONSTRAINT IF EXISTS decisions_private_access_code,
+DROP COLUMN IF EXISTS access_code_hash,
+DROP COLUMN IF EXISTS visibility;
@@ -0,0 +1,8 @@
+-- Unlisted decisions are left out of the feed and insights; private ones
+-- also need their access code, stored hashed like creator tokens.
+ALTER TABLE decisions
+ADD COLUMN visibility TEXT NOT NULL DEFAULT 'public'
+    CHECK (visibility IN ('public', 'unlisted', 'private')),
+ADD COLUMN access_code_hash TEXT NULL,
+ADD CONSTRAINT decisions_private_access_code
+    CHECK (visibility <> 'private' OR access_code_hash IS NOT NULL);
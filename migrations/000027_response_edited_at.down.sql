@@ -0,0 +1 @@
+ALTER TABLE responses DROP COLUMN edited_at;
@@ -0,0 +1,3 @@
+-- edited_at is set when a viewer changes their response after submitting
+-- it, so cards can say so.
+ALTER TABLE responses ADD COLUMN edited_at TIMESTAMPTZ NULL;
@@ -0,0 +1,13 @@
+-- Viewers react to each other's responses with emoji. A viewer can leave
+-- each emoji once per response; reacting again takes it back. Rows from
+-- shadowbanned viewers are kept but left out of the counts.
+CREATE TABLE response_reactions (
+    response_id UUID NOT NULL REFERENCES responses(id) ON DELETE CASCADE,
+    viewer_id UUID NOT NULL,
+    emoji TEXT NOT NULL,
+    shadowed BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (response_id, viewer_id, emoji)
+);
+
+CREATE INDEX idx_response_reactions_viewer ON response_reactions (viewer_id);
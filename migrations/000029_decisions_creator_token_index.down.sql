@@ -0,0 +1 @@
+DROP INDEX idx_decisions_creator_token_hash;
@@ -0,0 +1,3 @@
+-- The creator dashboard looks decisions up by creator token.
+CREATE INDEX idx_decisions_creator_token_hash ON decisions (creator_token_hash)
+WHERE creator_token_hash IS NOT NULL;
@@ -0,0 +1,3 @@
+-- A decision can stop taking responses after max_responses of them; 0
+-- means no limit. Reaching it closes the decision.
+ALTER TABLE decisions ADD COLUMN max_responses INTEGER NOT NULL DEFAULT 0 CHECK (max_responses >= 0);
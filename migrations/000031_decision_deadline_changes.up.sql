@@ -0,0 +1,11 @@
+-- Every change to an open decision's closing time, oldest first, so a
+-- moved deadline can be accounted for later.
+CREATE TABLE decision_deadline_changes (
+    id BIGSERIAL PRIMARY KEY,
+    decision_id UUID NOT NULL REFERENCES decisions(id) ON DELETE CASCADE,
+    previous_closes_at TIMESTAMPTZ NULL,
+    closes_at TIMESTAMPTZ NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_decision_deadline_changes_decision ON decision_deadline_changes (decision_id, changed_at);
@@ -0,0 +1 @@
+DROP TABLE decision_results;
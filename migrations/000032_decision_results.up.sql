@@ -0,0 +1,10 @@
+-- The results of a closed decision as they stood when it closed: stats,
+-- recommendation and post vote totals, as served in the decision envelope.
+-- Written once and never updated.
+CREATE TABLE decision_results (
+    decision_id UUID PRIMARY KEY REFERENCES decisions(id) ON DELETE CASCADE,
+    stats JSONB NOT NULL,
+    recommendation JSONB NOT NULL,
+    post_vote JSONB NOT NULL,
+    frozen_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,18 @@
+-- Looking back on a decision well after its outcome was recorded: was it
+-- worth it, 1 to 5. The creator's has no viewer_id; responders may log
+-- their own.
+CREATE TABLE decision_retrospectives (
+    id BIGSERIAL PRIMARY KEY,
+    decision_id UUID NOT NULL REFERENCES decisions(id) ON DELETE CASCADE,
+    viewer_id UUID NULL,
+    worth_it INT NOT NULL CHECK (worth_it BETWEEN 1 AND 5),
+    recorded_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (decision_id, viewer_id)
+);
+
+CREATE UNIQUE INDEX idx_decision_retrospectives_creator ON decision_retrospectives (decision_id)
+WHERE viewer_id IS NULL;
+
+CREATE INDEX idx_decision_retrospectives_viewer ON decision_retrospectives (viewer_id)
+WHERE viewer_id IS NOT NULL;
@@ -0,0 +1 @@
+DROP TABLE rm_leaderboards;
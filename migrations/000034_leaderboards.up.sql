@@ -0,0 +1,8 @@
+-- Leaderboards, ranked periodically from rm_decision_activity: board is
+-- score, responses, votes or controversial.
+CREATE TABLE rm_leaderboards (
+    board TEXT NOT NULL,
+    rank INT NOT NULL,
+    decision_id UUID NOT NULL REFERENCES decisions(id) ON DELETE CASCADE,
+    PRIMARY KEY (board, rank)
+);
@@ -0,0 +1,3 @@
+ALTER TABLE decisions DROP CONSTRAINT decisions_category_fkey;
+
+DROP TABLE categories;
@@ -0,0 +1,29 @@
+CREATE TABLE categories (
+    slug TEXT PRIMARY KEY,
+    label TEXT NOT NULL,
+    emoji TEXT NOT NULL,
+    sort_order INT NOT NULL
+);
+
+INSERT INTO categories (slug, label, emoji, sort_order) VALUES
+    ('career', 'Career', '💼', 10),
+    ('education', 'Education', '🎓', 20),
+    ('health', 'Health', '🩺', 30),
+    ('housing', 'Housing', '🏠', 40),
+    ('lifestyle', 'Lifestyle', '🌱', 50),
+    ('money', 'Money', '💰', 60),
+    ('relationships', 'Relationships', '❤️', 70),
+    ('travel', 'Travel', '✈️', 80),
+    ('other', 'Other', '🤷', 1000);
+
+-- Categories were only ever checked by the API, so anything else in the
+-- column predates that check. Each such value becomes a category of its own,
+-- labelled with its slug and sorted last, so no decision loses its
+-- category.
+INSERT INTO categories (slug, label, emoji, sort_order)
+SELECT DISTINCT category, category, '🏷️', 2000
+FROM decisions
+WHERE category IS NOT NULL AND category NOT IN (SELECT slug FROM categories);
+
+ALTER TABLE decisions
+ADD CONSTRAINT decisions_category_fkey FOREIGN KEY (category) REFERENCES categories(slug) ON UPDATE CASCADE;
@@ -0,0 +1,3 @@
+ALTER TABLE decisions DROP COLUMN nickname_policy;
+
+ALTER TABLE responses DROP COLUMN nickname;
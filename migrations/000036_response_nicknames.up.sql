@@ -0,0 +1,7 @@
+-- Responders may sign a response with a nickname. The decision's creator
+-- can leave that up to them (optional), insist on one (required) or keep
+-- every response anonymous (forbidden).
+ALTER TABLE responses ADD COLUMN nickname TEXT NULL;
+
+ALTER TABLE decisions
+ADD COLUMN nickname_policy TEXT NOT NULL DEFAULT 'optional' CHECK (nickname_policy IN ('optional', 'required', 'forbidden'));
@@ -0,0 +1,2 @@
+DROP INDEX idx_decision_votes_voter_viewer_id;
+DROP INDEX idx_responses_viewer_id;
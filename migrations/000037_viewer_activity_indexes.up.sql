@@ -0,0 +1,4 @@
+-- The viewer activity history looks responses and decision votes up by
+-- viewer.
+CREATE INDEX idx_responses_viewer_id ON responses (viewer_id);
+CREATE INDEX idx_decision_votes_voter_viewer_id ON decision_votes (voter_viewer_id);
@@ -0,0 +1,4 @@
+DROP TABLE account_decisions;
+DROP TABLE account_viewers;
+DROP TABLE account_identities;
+DROP TABLE accounts;
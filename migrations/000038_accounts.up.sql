@@ -0,0 +1,38 @@
+-- Optional accounts, signed in with an OAuth provider. An account holds
+-- viewer identities and decisions so they can be picked up on any device;
+-- nothing else requires one.
+CREATE TABLE accounts (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- subject is the provider's stable user ID.
+CREATE TABLE account_identities (
+    provider TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    email TEXT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (provider, subject)
+);
+
+CREATE INDEX idx_account_identities_account_id ON account_identities (account_id);
+
+-- A viewer belongs to at most one account.
+CREATE TABLE account_viewers (
+    viewer_id UUID PRIMARY KEY,
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    linked_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_account_viewers_account_id ON account_viewers (account_id);
+
+-- Linked decisions can be managed with the account token in place of the
+-- creator token.
+CREATE TABLE account_decisions (
+    decision_id UUID PRIMARY KEY REFERENCES decisions(id) ON DELETE CASCADE,
+    account_id UUID NOT NULL REFERENCES accounts(id) ON DELETE CASCADE,
+    linked_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_account_decisions_account_id ON account_decisions (account_id);
@@ -0,0 +1,18 @@
+-- API keys managed at runtime through /api/admin/api-keys, alongside the
+-- fixed keys in WRITE_API_KEYS. Only a SHA-256 of each secret is stored;
+-- secret_prefix is the start of it, shown so operators can tell keys apart.
+-- A rotated key's old secret keeps working until previous_valid_until.
+CREATE TABLE api_keys (
+    id UUID PRIMARY KEY,
+    name TEXT NOT NULL,
+    scope TEXT NOT NULL CHECK (scope IN ('read', 'write', 'admin')),
+    secret_hash TEXT NOT NULL UNIQUE,
+    secret_prefix TEXT NOT NULL,
+    previous_secret_hash TEXT NULL,
+    previous_valid_until TIMESTAMPTZ NULL,
+    expires_at TIMESTAMPTZ NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    rotated_at TIMESTAMPTZ NULL,
+    revoked_at TIMESTAMPTZ NULL,
+    last_used_at TIMESTAMPTZ NULL
+);
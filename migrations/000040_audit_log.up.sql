@@ -0,0 +1,25 @@
+-- One row per successful mutating API request, for abuse investigations.
+-- actor_type is who the request proved it was; api_key_id is the key it
+-- came in with, if any ('env' for keys from WRITE_API_KEYS). before and
+-- after are the target as the handler saw it, when it records them.
+CREATE TABLE audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    actor_type TEXT NOT NULL CHECK (actor_type IN ('anonymous', 'viewer', 'account', 'creator', 'api_key', 'admin')),
+    actor_id TEXT NULL,
+    api_key_id TEXT NULL,
+    ip TEXT NULL,
+    method TEXT NOT NULL,
+    route TEXT NOT NULL,
+    status INT NOT NULL,
+    target_type TEXT NULL,
+    target_id TEXT NULL,
+    request_id TEXT NULL,
+    before JSONB NULL,
+    after JSONB NULL
+);
+
+CREATE INDEX audit_log_at_idx ON audit_log (at DESC);
+CREATE INDEX audit_log_actor_idx ON audit_log (actor_type, actor_id, id DESC);
+CREATE INDEX audit_log_target_idx ON audit_log (target_type, target_id, id DESC);
+CREATE INDEX audit_log_ip_idx ON audit_log (ip, id DESC);
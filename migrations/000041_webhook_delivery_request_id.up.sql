@@ -0,0 +1,4 @@
+-- The API request that caused a delivery, sent on as X-Request-Id so a
+-- receiver's logs can be matched with ours. NULL for events raised by
+-- background jobs, such as decision_closed.
+ALTER TABLE webhook_deliveries ADD COLUMN request_id TEXT NULL;
@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS response_reactions_touch_decision_revision ON response_reactions;
+DROP FUNCTION IF EXISTS touch_decision_revision_via_response();
@@ -0,0 +1,22 @@
+-- Reaction counts are part of the decision page, so reacting has to bump
+-- the decision's revision like responses and votes do, or a conditional GET
+-- keeps answering 304 with stale counts. response_reactions has no
+-- decision_id of its own; it is looked up through the response.
+CREATE FUNCTION touch_decision_revision_via_response() RETURNS trigger AS $$
+DECLARE
+    target UUID;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        target := OLD.response_id;
+    ELSE
+        target := NEW.response_id;
+    END IF;
+    UPDATE decisions SET revision = revision + 1
+    WHERE id = (SELECT decision_id FROM responses WHERE id = target);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER response_reactions_touch_decision_revision
+AFTER INSERT OR UPDATE OR DELETE ON response_reactions
+FOR EACH ROW EXECUTE FUNCTION touch_decision_revision_via_response();
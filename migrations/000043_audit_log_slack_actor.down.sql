@@ -0,0 +1,5 @@
+DELETE FROM audit_log WHERE actor_type = 'slack';
+ALTER TABLE audit_log
+DROP CONSTRAINT audit_log_actor_type_check,
+ADD CONSTRAINT audit_log_actor_type_check
+    CHECK (actor_type IN ('anonymous', 'viewer', 'account', 'creator', 'api_key', 'admin'));
@@ -0,0 +1,6 @@
+-- Slack slash commands are audited too, with the Slack team and user as
+-- the actor.
+ALTER TABLE audit_log
+DROP CONSTRAINT audit_log_actor_type_check,
+ADD CONSTRAINT audit_log_actor_type_check
+    CHECK (actor_type IN ('anonymous', 'viewer', 'account', 'creator', 'api_key', 'admin', 'slack'));
@@ -0,0 +1,21 @@
+// Package migrations embeds the SQL migrations so the server binary can
+// apply and check them without the directory shipped alongside it.
+package migrations
+
+import (
+	"embed"
+	"io/fs"
+	"os"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// FS returns the migrations in dir when it is set, for iterating on
+// migrations without rebuilding, and the embedded copy otherwise.
+func FS(dir string) fs.FS {
+	if dir != "" {
+		return os.DirFS(dir)
+	}
+	return files
+}
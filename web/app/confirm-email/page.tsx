@@ -0,0 +1,62 @@
+"use client";
+
+import { useSearchParams } from "next/navigation";
+import { Suspense, useEffect, useState } from "react";
+import { confirmCreatorEmail } from "../../lib/api";
+import type { ConfirmCreatorEmailResponse } from "../../lib/types";
+
+function ConfirmEmail() {
+  const token = useSearchParams().get("token") ?? "";
+  const [confirmed, setConfirmed] = useState<ConfirmCreatorEmailResponse | null>(null);
+  const [error, setError] = useState<string | null>(null);
+
+  useEffect(() => {
+    if (!token) {
+      setError("This confirmation link is missing its token.");
+      return;
+    }
+    let cancelled = false;
+    confirmCreatorEmail(token)
+      .then((result) => {
+        if (!cancelled) {
+          setConfirmed(result);
+        }
+      })
+      .catch((err) => {
+        if (!cancelled) {
+          setError(err instanceof Error ? err.message : "Could not confirm your email");
+        }
+      });
+    return () => {
+      cancelled = true;
+    };
+  }, [token]);
+
+  if (error) {
+    return <p className="error">{error}</p>;
+  }
+  if (!confirmed) {
+    return <p className="muted">Confirming…</p>;
+  }
+  return (
+    <>
+      <p className="success">Email confirmed. We&apos;ll send you the results when your decision closes.</p>
+      <a className="btn btn-primary" href={`/d/${encodeURIComponent(confirmed.slug)}`}>
+        Open Decision
+      </a>
+    </>
+  );
+}
+
+export default function ConfirmEmailPage() {
+  return (
+    <main className="shell">
+      <article className="card">
+        <h2>Confirm Email</h2>
+        <Suspense fallback={<p className="muted">Confirming…</p>}>
+          <ConfirmEmail />
+        </Suspense>
+      </article>
+    </main>
+  );
+}
@@ -3,15 +3,17 @@
 import { useParams, useSearchParams } from "next/navigation";
 import { FormEvent, useEffect, useMemo, useState } from "react";
 import {
+  ApiError,
   getDecision,
   submitDecisionResponse,
   voteOnDecision,
 } from "../../../lib/api";
 import {
-  getOrCreateViewerId,
+  getOrCreateViewer,
   hasDecisionResponded,
   markDecisionResponded,
 } from "../../../lib/viewer";
+import type { Viewer } from "../../../lib/viewer";
 import type { DecisionEnvelope } from "../../../lib/types";
 
 const ratingOptions = [
@@ -40,8 +42,10 @@ export default function DecisionPage() {
   const searchParams = useSearchParams();
   const slug = params.slug;
   const isCreatorView = searchParams.get("creator") === "1";
+  const accessCode = searchParams.get("access_code") ?? undefined;
 
-  const [viewerId, setViewerId] = useState<string | null>(null);
+  const [viewer, setViewer] = useState<Viewer | null>(null);
+  const viewerId = viewer?.id ?? null;
   const [data, setData] = useState<DecisionEnvelope | null>(null);
   const [loading, setLoading] = useState(true);
   const [error, setError] = useState<string | null>(null);
@@ -58,7 +62,11 @@ export default function DecisionPage() {
   const [comment, setComment] = useState("");
 
   useEffect(() => {
-    setViewerId(getOrCreateViewerId());
+    getOrCreateViewer()
+      .then(setViewer)
+      .catch((err) => {
+        setError(err instanceof Error ? err.message : "Failed to load viewer");
+      });
   }, []);
 
   useEffect(() => {
@@ -72,7 +80,7 @@ export default function DecisionPage() {
     setLoading(true);
     setError(null);
     try {
-      const response = await getDecision(slug, currentViewerId);
+      const response = await getDecision(slug, currentViewerId, accessCode);
       setData(response);
     } catch (err) {
       setError(err instanceof Error ? err.message : "Failed to load decision");
@@ -86,7 +94,7 @@ export default function DecisionPage() {
       return;
     }
     void refreshDecision(viewerId ?? undefined);
-  }, [slug, viewerId]);
+  }, [slug, viewerId, accessCode]);
 
   const sortedResponses = useMemo(() => {
     const responses = [...(data?.responses ?? [])];
@@ -110,7 +118,11 @@ export default function DecisionPage() {
     rating_score: 0,
     comment_sentiment: 0,
     post_vote_score: 0,
+    sample_size: 0,
+    confidence: 0,
+    confidence_interval: [0, 1] as [number, number],
   };
+  const collectingResponses = data?.state === "collecting_responses";
   const supportsPostVote = Boolean(
     (data as { post_vote?: unknown } | null)?.post_vote,
   );
@@ -131,7 +143,7 @@ export default function DecisionPage() {
 
   async function onSubmitResponse(event: FormEvent<HTMLFormElement>) {
     event.preventDefault();
-    if (!viewerId) {
+    if (!viewer) {
       return;
     }
 
@@ -147,13 +159,17 @@ export default function DecisionPage() {
 
     setIsSubmitting(true);
     try {
-      await submitDecisionResponse(slug, {
-        viewer_id: viewerId,
-        rating: 0,
-        suggestion: decisionOption,
-        emoji,
-        comment: comment.trim() || null,
-      });
+      await submitDecisionResponse(
+        slug,
+        {
+          viewer_token: viewer.token,
+          rating: 0,
+          suggestion: decisionOption,
+          emoji,
+          comment: comment.trim() || null,
+        },
+        accessCode,
+      );
 
       setDecisionOption(null);
       setEmoji("");
@@ -166,7 +182,7 @@ export default function DecisionPage() {
       const message =
         err instanceof Error ? err.message : "Failed to submit response";
       setSubmitError(message);
-      if (message.includes("already submitted")) {
+      if (err instanceof ApiError && err.code === "viewer_already_responded") {
         setSubmittedThisSession(true);
         markDecisionResponded(slug);
         setPersistedResponded(true);
@@ -178,13 +194,17 @@ export default function DecisionPage() {
   }
 
   async function onVotePost(value: 1 | -1) {
-    if (!viewerId) {
+    if (!viewer) {
       return;
     }
 
     setIsVotingPost(true);
     try {
-      await voteOnDecision(slug, { viewer_id: viewerId, value });
+      await voteOnDecision(
+        slug,
+        { viewer_token: viewer.token, value },
+        accessCode,
+      );
       await refreshDecision(viewerId);
     } catch (err) {
       const message = err instanceof Error ? err.message : "Failed to vote";
@@ -384,17 +404,28 @@ export default function DecisionPage() {
               <article className="card">
                 <h2>Model Recommendation</h2>
                 {supportsRecommendation ? (
-                  <>
+                  recommendation.decision === "undecided" ? (
                     <p>
-                      According to our analysis, our model recommends{" "}
-                      <strong>
-                        {recommendation.decision === "yes"
-                          ? "pursuing"
-                          : "not pursuing"}
-                      </strong>{" "}
-                      this decision.
+                      Not enough responses yet for our model to recommend
+                      either way ({recommendation.sample_size} so far).
                     </p>
-                  </>
+                  ) : (
+                    <>
+                      <p>
+                        According to our analysis, our model recommends{" "}
+                        <strong>
+                          {recommendation.decision === "yes"
+                            ? "pursuing"
+                            : "not pursuing"}
+                        </strong>{" "}
+                        this decision.
+                      </p>
+                      <p className="muted">
+                        Confidence: {Math.round(recommendation.confidence * 100)}%
+                        {" "}from {recommendation.sample_size} responses.
+                      </p>
+                    </>
+                  )
                 ) : (
                   <p className="muted">
                     Model recommendation unavailable on current backend version.
@@ -405,7 +436,13 @@ export default function DecisionPage() {
 
             <article className="card">
               <h2>Responses</h2>
-              {sortedResponses.length === 0 ? (
+              {collectingResponses ? (
+                <p className="muted">
+                  Collecting responses: {data.stats.response_count} of{" "}
+                  {data.decision.quorum} in. Results stay hidden until the
+                  quorum is reached, so early takes don't sway anyone.
+                </p>
+              ) : sortedResponses.length === 0 ? (
                 <p className="muted">No responses yet.</p>
               ) : null}
               <div className="response-list">
@@ -0,0 +1,72 @@
+"use client";
+
+import { useParams } from "next/navigation";
+import { useEffect, useState } from "react";
+import { getDecisionEmbed } from "../../../lib/api";
+import type { DecisionEmbed } from "../../../lib/types";
+
+// Embedded cards refresh on this interval; the API caches for 15s anyway.
+const refreshMs = 15_000;
+
+export default function DecisionEmbedPage() {
+  const params = useParams<{ slug: string }>();
+  const slug = params.slug;
+  const [card, setCard] = useState<DecisionEmbed | null>(null);
+  const [error, setError] = useState<string | null>(null);
+
+  useEffect(() => {
+    let cancelled = false;
+    const load = async () => {
+      try {
+        const next = await getDecisionEmbed(slug);
+        if (!cancelled) {
+          setCard(next);
+          setError(null);
+        }
+      } catch (err) {
+        if (!cancelled) {
+          setError(err instanceof Error ? err.message : "Could not load decision");
+        }
+      }
+    };
+    void load();
+    const timer = window.setInterval(load, refreshMs);
+    return () => {
+      cancelled = true;
+      window.clearInterval(timer);
+    };
+  }, [slug]);
+
+  if (error && !card) {
+    return <p className="error embed-card">{error}</p>;
+  }
+  if (!card) {
+    return <p className="muted embed-card">Loading…</p>;
+  }
+
+  return (
+    <a className="embed-card" href={card.share_url} target="_blank" rel="noopener noreferrer">
+      <p className="embed-title">{card.title}</p>
+      <p className="embed-stats">
+        <span className="embed-emoji">{card.top_emoji || "🤔"}</span>
+        <span>
+          {card.response_count} {card.response_count === 1 ? "response" : "responses"}
+        </span>
+        {card.response_count > 0 ? (
+          <span>
+            Crowd says{" "}
+            {card.recommendation === "undecided"
+              ? "too early to call"
+              : card.recommendation === "yes"
+                ? "do it"
+                : "don't"}{" "}
+            (score{" "}
+            {card.score >= 0 ? "+" : ""}
+            {card.score.toFixed(2)})
+          </span>
+        ) : null}
+      </p>
+      <p className="muted">Weigh in on RateMyLifeDecision →</p>
+    </a>
+  );
+}
@@ -1,8 +1,27 @@
 import type {
+  Account,
+  Category,
+  CategoryDecision,
+  ConfirmCreatorEmailResponse,
   CreateDecisionRequest,
   CreateDecisionResponse,
+  CreateViewerResponse,
+  DecisionEmbed,
+  DecisionComparison,
   DecisionEnvelope,
+  DecisionTemplate,
+  MyDecision,
+  Problem,
+  RelatedDecision,
+  ReactionSummary,
+  ResponseCard,
+  ResponsePage,
+  ResponseSort,
+  ResponseSuggestion,
+  SessionToken,
   SubmitResponseRequest,
+  UpdateResponseRequest,
+  ViewerActivityPage,
   VoteRequest,
   VoteSummary
 } from "./types";
@@ -20,6 +39,19 @@ function resolveApiBaseUrl() {
   return "http://localhost:8080";
 }
 
+// ApiError is thrown for error responses; code is the server's stable error
+// code (e.g. "decision_closed"), so callers can branch on it.
+export class ApiError extends Error {
+  constructor(
+    message: string,
+    readonly status: number,
+    readonly code: string
+  ) {
+    super(message);
+    this.name = "ApiError";
+  }
+}
+
 async function request<T>(path: string, init?: RequestInit): Promise<T> {
   const response = await fetch(`${resolveApiBaseUrl()}${path}`, {
     ...init,
@@ -31,44 +63,274 @@ async function request<T>(path: string, init?: RequestInit): Promise<T> {
 
   if (!response.ok) {
     let message = `Request failed with status ${response.status}`;
+    let code = "error";
     try {
-      const errBody = (await response.json()) as { error?: string };
-      if (errBody.error) {
-        message = errBody.error;
+      const problem = (await response.json()) as Partial<Problem>;
+      if (problem.detail) {
+        message = problem.detail;
+      }
+      if (problem.code) {
+        code = problem.code;
       }
     } catch {
       // Use default message when body is not JSON.
     }
-    throw new Error(message);
+    throw new ApiError(message, response.status, code);
   }
 
+  if (response.status === 204) {
+    return undefined as T;
+  }
   return (await response.json()) as T;
 }
 
+export function createViewer() {
+  return request<CreateViewerResponse>("/v1/viewers", {
+    method: "POST"
+  });
+}
+
 export function createDecision(payload: CreateDecisionRequest) {
-  return request<CreateDecisionResponse>("/api/decisions", {
+  return request<CreateDecisionResponse>("/v1/decisions", {
     method: "POST",
     body: JSON.stringify(payload)
   });
 }
 
-export function getDecision(slug: string, viewerId?: string) {
+export function listTemplates() {
+  return request<{ items: DecisionTemplate[] }>("/v1/templates");
+}
+
+// Private decisions need their access code on every read and response.
+function accessCodeHeaders(accessCode?: string): Record<string, string> {
+  return accessCode ? { "X-Access-Code": accessCode } : {};
+}
+
+export function getDecision(slug: string, viewerId?: string, accessCode?: string) {
   const query = viewerId ? `?viewer_id=${encodeURIComponent(viewerId)}` : "";
-  return request<DecisionEnvelope>(`/api/decisions/${encodeURIComponent(slug)}${query}`, {
-    cache: "no-store"
+  return request<DecisionEnvelope>(`/v1/decisions/${encodeURIComponent(slug)}${query}`, {
+    cache: "no-cache",
+    headers: accessCodeHeaders(accessCode)
+  });
+}
+
+// listResponses pages through a decision's responses, optionally filtered;
+// pass the previous page's next_cursor to get the one after it.
+export function listResponses(
+  slug: string,
+  options: {
+    sort?: ResponseSort;
+    suggestion?: ResponseSuggestion;
+    minRating?: number;
+    maxRating?: number;
+    withComments?: boolean;
+    cursor?: string;
+    limit?: number;
+  } = {},
+  accessCode?: string
+) {
+  const params = new URLSearchParams();
+  if (options.sort) {
+    params.set("sort", options.sort);
+  }
+  if (options.suggestion) {
+    params.set("suggestion", options.suggestion);
+  }
+  if (options.minRating) {
+    params.set("min_rating", String(options.minRating));
+  }
+  if (options.maxRating) {
+    params.set("max_rating", String(options.maxRating));
+  }
+  if (options.withComments) {
+    params.set("with_comments", "true");
+  }
+  if (options.cursor) {
+    params.set("cursor", options.cursor);
+  }
+  if (options.limit) {
+    params.set("limit", String(options.limit));
+  }
+  const query = params.toString() ? `?${params}` : "";
+  return request<ResponsePage>(`/v1/decisions/${encodeURIComponent(slug)}/responses${query}`, {
+    cache: "no-cache",
+    headers: accessCodeHeaders(accessCode)
   });
 }
 
-export function submitDecisionResponse(slug: string, payload: SubmitResponseRequest) {
-  return request<{ id: string }>(`/api/decisions/${encodeURIComponent(slug)}/responses`, {
+export function compareDecisions(first: string, second: string) {
+  const slugs = [first, second].map(encodeURIComponent).join(",");
+  return request<DecisionComparison>(`/v1/compare?slugs=${slugs}`, { cache: "no-cache" });
+}
+
+export function listCategories() {
+  return request<{ items: Category[] }>("/v1/categories");
+}
+
+export function listCategoryDecisions(category: string, openOnly = false) {
+  const query = openOnly ? "?status=open" : "";
+  return request<{ category: Category; items: CategoryDecision[] }>(
+    `/v1/categories/${encodeURIComponent(category)}/decisions${query}`,
+    { cache: "no-cache" }
+  );
+}
+
+// listRelatedDecisions suggests other open decisions in the same category.
+export function listRelatedDecisions(slug: string, accessCode?: string) {
+  return request<{ items: RelatedDecision[] }>(`/v1/decisions/${encodeURIComponent(slug)}/related`, {
+    cache: "no-cache",
+    headers: accessCodeHeaders(accessCode)
+  });
+}
+
+// getRandomDecision picks an open decision to rate, skipping ones the viewer
+// has already responded to when their token is given.
+export function getRandomDecision(viewerToken?: string) {
+  return request<DecisionEnvelope["decision"]>("/v1/decisions/random", {
+    cache: "no-store",
+    headers: viewerToken ? { "X-Viewer-Token": viewerToken } : undefined
+  });
+}
+
+// listMyDecisions is the creator dashboard for every decision whose creator
+// token is given.
+export function listMyDecisions(creatorTokens: string[]) {
+  return request<{ items: MyDecision[] }>("/v1/me/decisions", {
+    cache: "no-cache",
+    headers: { "X-Creator-Token": creatorTokens.join(",") }
+  });
+}
+
+// listViewerActivity is what the viewer responded to or voted on, newest
+// first; pass next_before back as before for the next page.
+export function listViewerActivity(viewerId: string, viewerToken: string, before?: string) {
+  const query = before ? `?before=${encodeURIComponent(before)}` : "";
+  return request<ViewerActivityPage>(`/v1/viewers/${encodeURIComponent(viewerId)}/activity${query}`, {
+    cache: "no-store",
+    headers: { "X-Viewer-Token": viewerToken }
+  });
+}
+
+// signInURL starts an OAuth sign-in; the API sends the browser back to
+// /auth/complete with #account_token= or #error=.
+export function signInURL(provider: "google" | "github") {
+  return `${resolveApiBaseUrl()}/v1/auth/${provider}/login`;
+}
+
+export function getAccount(accountToken: string) {
+  return request<Account>("/v1/account", {
+    cache: "no-store",
+    headers: { "X-Account-Token": accountToken }
+  });
+}
+
+export function linkAccountViewer(accountToken: string, viewerToken: string) {
+  return request<void>("/v1/account/viewers", {
     method: "POST",
-    body: JSON.stringify(payload)
+    headers: { "X-Account-Token": accountToken },
+    body: JSON.stringify({ viewer_token: viewerToken })
   });
 }
 
-export function voteOnDecision(slug: string, payload: VoteRequest) {
-  return request<VoteSummary>(`/api/decisions/${encodeURIComponent(slug)}/vote`, {
+export function linkAccountDecision(accountToken: string, slug: string, creatorToken: string) {
+  return request<void>(`/v1/account/decisions/${encodeURIComponent(slug)}`, {
     method: "POST",
-    body: JSON.stringify(payload)
+    headers: { "X-Account-Token": accountToken, "X-Creator-Token": creatorToken }
+  });
+}
+
+// Either token may be omitted; the session speaks for whichever are given.
+export function createSessionToken(tokens: { viewerToken?: string; accountToken?: string }) {
+  return request<SessionToken>("/v1/auth/token", {
+    method: "POST",
+    cache: "no-store",
+    headers: tokens.accountToken ? { "X-Account-Token": tokens.accountToken } : undefined,
+    body: JSON.stringify(tokens.viewerToken ? { viewer_token: tokens.viewerToken } : {})
+  });
+}
+
+export function confirmCreatorEmail(token: string) {
+  return request<ConfirmCreatorEmailResponse>("/v1/creator-email/confirm", {
+    method: "POST",
+    body: JSON.stringify({ token })
+  });
+}
+
+export function getDecisionEmbed(slug: string) {
+  return request<DecisionEmbed>(`/v1/decisions/${encodeURIComponent(slug)}/embed`);
+}
+
+// decisionQrCodeUrl is an <img> src for the decision's share link as a QR
+// code, size pixels square.
+export function decisionQrCodeUrl(slug: string, size = 256) {
+  return `${resolveApiBaseUrl()}/v1/decisions/${encodeURIComponent(slug)}/qr.png?size=${size}`;
+}
+
+export function submitDecisionResponse(
+  slug: string,
+  payload: SubmitResponseRequest,
+  accessCode?: string
+) {
+  return request<{ id: string }>(`/v1/decisions/${encodeURIComponent(slug)}/responses`, {
+    method: "POST",
+    body: JSON.stringify(payload),
+    headers: accessCodeHeaders(accessCode)
   });
 }
+
+export function updateMyResponse(
+  slug: string,
+  payload: UpdateResponseRequest,
+  accessCode?: string
+) {
+  return request<ResponseCard>(`/v1/decisions/${encodeURIComponent(slug)}/responses/mine`, {
+    method: "PUT",
+    body: JSON.stringify(payload),
+    headers: accessCodeHeaders(accessCode)
+  });
+}
+
+export function deleteMyResponse(slug: string, viewerToken: string, accessCode?: string) {
+  return request<void>(`/v1/decisions/${encodeURIComponent(slug)}/responses/mine`, {
+    method: "DELETE",
+    headers: { "X-Viewer-Token": viewerToken, ...accessCodeHeaders(accessCode) }
+  });
+}
+
+// reactToResponse toggles the viewer's emoji reaction on a response.
+export function reactToResponse(
+  responseId: string,
+  viewerToken: string,
+  emoji: string,
+  accessCode?: string
+) {
+  return request<ReactionSummary>(`/v1/responses/${encodeURIComponent(responseId)}/reactions`, {
+    method: "POST",
+    body: JSON.stringify({ viewer_token: viewerToken, emoji }),
+    headers: accessCodeHeaders(accessCode)
+  });
+}
+
+export function voteOnDecision(slug: string, payload: VoteRequest, accessCode?: string) {
+  return request<VoteSummary>(`/v1/decisions/${encodeURIComponent(slug)}/vote`, {
+    method: "POST",
+    body: JSON.stringify(payload),
+    headers: accessCodeHeaders(accessCode)
+  });
+}
+
+type GraphQLResult<T> = {
+  data?: T;
+  errors?: { message: string; path?: (string | number)[] }[];
+};
+
+export async function graphql<T>(query: string, variables?: Record<string, unknown>) {
+  const result = await request<GraphQLResult<T>>("/graphql", {
+    method: "POST",
+    body: JSON.stringify({ query, variables })
+  });
+  if (result.errors?.length) {
+    throw new Error(result.errors[0].message);
+  }
+  return result.data as T;
+}
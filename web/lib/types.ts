@@ -2,22 +2,91 @@ export type CreateDecisionRequest = {
   title: string;
   description: string | null;
   closes_at: string | null;
+  category?: string | null;
+  aggregate_only?: boolean;
+  quorum?: number;
+  max_responses?: number;
+  nickname_policy?: NicknamePolicy;
+  visibility?: "public" | "unlisted" | "private";
+  access_code?: string | null;
+  slug?: string | null;
+  webhooks?: WebhookRequest[];
+  creator_email?: string | null;
+  template_id?: string | null;
+};
+
+export type NicknamePolicy = "optional" | "required" | "forbidden";
+
+export type DecisionTemplate = {
+  id: string;
+  title: string;
+  description: string;
+  category: string;
+};
+
+export type WebhookEvent = "new_response" | "vote_milestone" | "decision_closed" | "close_reminder";
+
+export type WebhookKind = "generic" | "discord";
+
+export type WebhookRequest = {
+  url: string;
+  kind?: WebhookKind;
+  events?: WebhookEvent[];
+};
+
+export type CreatedWebhook = {
+  id: string;
+  kind: WebhookKind;
+  url: string;
+  events: WebhookEvent[];
+  created_at: string;
+  secret: string;
 };
 
 export type CreateDecisionResponse = {
   id: string;
   slug: string;
   share_url: string;
+  creator_token: string;
+  webhooks?: CreatedWebhook[];
+  creator_email_pending?: boolean;
+  access_code?: string;
+};
+
+export type ConfirmCreatorEmailResponse = {
+  slug: string;
+  share_url: string;
+};
+
+export type DecisionEmbed = {
+  slug: string;
+  title: string;
+  share_url: string;
+  top_emoji: string;
+  recommendation: "yes" | "no" | "undecided";
+  score: number;
+  response_count: number;
+  closes_at: string | null;
 };
 
 export type DecisionEnvelope = {
+  state: "collecting_responses" | "revealed";
   decision: {
     id: string;
     slug: string;
     title: string;
     description: string | null;
     closes_at: string | null;
+    closed_at: string | null;
+    archived_at: string | null;
     created_at: string;
+    panel_only: boolean;
+    category: string | null;
+    aggregate_only: boolean;
+    quorum: number;
+    max_responses: number;
+    nickname_policy: NicknamePolicy;
+    visibility: "public" | "unlisted" | "private";
   };
   post_vote: {
     score: number;
@@ -26,12 +95,15 @@ export type DecisionEnvelope = {
     my_vote: number;
   };
   recommendation: {
-    decision: "yes" | "no";
+    decision: "yes" | "no" | "undecided";
     score: number;
     suggestion_score: number;
     rating_score: number;
     comment_sentiment: number;
     post_vote_score: number;
+    sample_size: number;
+    confidence: number;
+    confidence_interval: [number, number];
   };
   viewer_has_responded: boolean;
   stats: {
@@ -49,27 +121,187 @@ export type DecisionEnvelope = {
       count: number;
     }>;
     top_emoji: string;
+    timeline?: {
+      bucket: "hour" | "day";
+      points: Array<{
+        start: string;
+        responses: number;
+        avg_rating: number;
+        cumulative_responses: number;
+        cumulative_avg_rating: number;
+      }>;
+    };
+    languages?: Array<{
+      language: string;
+      count: number;
+    }>;
   };
-  responses: Array<{
-    id: string;
+  responses: ResponseCard[];
+  more_responses: boolean;
+};
+
+export type ComparedDecision = {
+  state: DecisionEnvelope["state"];
+  decision: DecisionEnvelope["decision"];
+  stats: DecisionEnvelope["stats"];
+  recommendation: DecisionEnvelope["recommendation"];
+  post_vote_score: number;
+};
+
+// DecisionComparison's diff is the first decision minus the second; null
+// while either is still collecting responses.
+export type DecisionComparison = {
+  decisions: [ComparedDecision, ComparedDecision];
+  diff: {
+    score_delta: number;
+    net_sentiment_delta: number;
+    comment_sentiment_delta: number;
+    avg_rating_delta: number;
+    response_count_delta: number;
+  } | null;
+};
+
+export type MyDecision = {
+  slug: string;
+  title: string;
+  category: string | null;
+  created_at: string;
+  closes_at: string | null;
+  closed_at: string | null;
+  status: "open" | "closed";
+  response_count: number;
+  avg_rating: number | null;
+  vote_score: number;
+  responses_last_week: number;
+  avg_rating_change_last_week: number | null;
+  trend_score: number;
+};
+
+export type Category = {
+  slug: string;
+  label: string;
+  emoji: string;
+  sort_order: number;
+};
+
+export type CategoryDecision = {
+  slug: string;
+  title: string;
+  created_at: string;
+  closes_at: string | null;
+  status: "open" | "closed";
+  response_count: number;
+};
+
+export type ViewerActivityItem = {
+  slug: string;
+  title: string;
+  category: string | null;
+  status: "open" | "closed";
+  last_activity_at: string;
+  my_response: {
     rating: number;
     suggestion: 1 | 2 | 3;
     emoji: string;
     comment: string | null;
+    nickname: string | null;
+    created_at: string;
+    edited_at: string | null;
+  } | null;
+  my_vote: number;
+  state: DecisionEnvelope["state"];
+  recommendation: DecisionEnvelope["recommendation"] | null;
+};
+
+export type ViewerActivityPage = {
+  items: ViewerActivityItem[];
+  next_before: string | null;
+};
+
+export type Account = {
+  account_id: string;
+  identities: Array<{
+    provider: "google" | "github";
+    email: string | null;
+  }>;
+  viewers: CreateViewerResponse[];
+  decisions: Array<{
+    slug: string;
+    title: string;
     created_at: string;
   }>;
 };
 
-export type SubmitResponseRequest = {
+export type SessionToken = {
+  access_token: string;
+  token_type: "Bearer";
+  expires_in: number;
+};
+
+export type RelatedDecision = {
+  slug: string;
+  title: string;
+  category: string | null;
+  response_count: number;
+  vote_score: number;
+  vote_count: number;
+  trend_score: number;
+  last_activity_at: string;
+};
+
+export type ResponseSort = "newest" | "top_rated" | "most_reacted";
+
+export type ResponseSuggestion = "do_it" | "dont_do_it" | "mixed";
+
+export type ResponsePage = {
+  responses: ResponseCard[];
+  next_cursor: string | null;
+};
+
+export type ResponseCard = {
+  id: string;
+  rating: number;
+  suggestion: 1 | 2 | 3;
+  emoji: string;
+  comment: string | null;
+  language: string | null;
+  created_at: string;
+  edited_at: string | null;
+  panel_member: boolean;
+  nickname: string | null;
+  reactions: Record<string, number>;
+};
+
+export type ReactionSummary = {
+  response_id: string;
+  reactions: Record<string, number>;
+  my_reactions: string[];
+};
+
+export type CreateViewerResponse = {
   viewer_id: string;
+  viewer_token: string;
+};
+
+export type SubmitResponseRequest = {
+  viewer_token: string;
   rating: number;
   suggestion: 1 | 2 | 3;
   emoji: string;
   comment: string | null;
+  nickname?: string | null;
+  panel_token?: string;
+};
+
+export type UpdateResponseRequest = {
+  viewer_token: string;
+  suggestion: 1 | 2 | 3;
+  emoji: string;
+  comment: string | null;
 };
 
 export type VoteRequest = {
-  viewer_id: string;
+  viewer_token: string;
   value: 1 | -1;
 };
 
@@ -80,3 +312,15 @@ export type VoteSummary = {
   downvotes: number;
   my_vote: number;
 };
+
+// Problem is an RFC 7807 error response. Branch on code; detail is for
+// people and its wording may change.
+export type Problem = {
+  type: string;
+  title: string;
+  status: number;
+  detail?: string;
+  code: string;
+  request_id?: string;
+  retry_after_seconds?: number;
+};
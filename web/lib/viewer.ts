@@ -1,7 +1,16 @@
-const VIEWER_STORAGE_KEY = "rml_viewer_id";
+import { createViewer } from "./api";
+
+const VIEWER_STORAGE_KEY = "rml_viewer_token";
 const COOKIE_MAX_AGE_SECONDS = 60 * 60 * 24 * 365;
 
-export function getOrCreateViewerId(): string {
+export type Viewer = {
+  id: string;
+  token: string;
+};
+
+// Viewer tokens are issued by the API as "<viewer uuid>.<signature>", so the
+// id is recovered from the stored token instead of being kept separately.
+export async function getOrCreateViewer(): Promise<Viewer> {
   const existingLocal = window.localStorage.getItem(VIEWER_STORAGE_KEY);
   const existingCookie = getCookie(VIEWER_STORAGE_KEY);
 
@@ -9,18 +18,22 @@ export function getOrCreateViewerId(): string {
     if (!existingCookie) {
       setCookie(VIEWER_STORAGE_KEY, existingLocal);
     }
-    return existingLocal;
+    return viewerFromToken(existingLocal);
   }
 
   if (existingCookie) {
     window.localStorage.setItem(VIEWER_STORAGE_KEY, existingCookie);
-    return existingCookie;
+    return viewerFromToken(existingCookie);
   }
 
-  const nextId = crypto.randomUUID();
-  window.localStorage.setItem(VIEWER_STORAGE_KEY, nextId);
-  setCookie(VIEWER_STORAGE_KEY, nextId);
-  return nextId;
+  const created = await createViewer();
+  window.localStorage.setItem(VIEWER_STORAGE_KEY, created.viewer_token);
+  setCookie(VIEWER_STORAGE_KEY, created.viewer_token);
+  return { id: created.viewer_id, token: created.viewer_token };
+}
+
+function viewerFromToken(token: string): Viewer {
+  return { id: token.split(".")[0], token };
 }
 
 export function markDecisionResponded(slug: string): void {